@@ -0,0 +1,186 @@
+// Package account 用户账号：注册、登录、第三方登录、验证码找回密码、个人资料及登录日志
+//
+// 密码使用 security.Password 哈希，登录成功后按需自动升级哈希算法或成本；
+// 连续登录失败会锁定一段时间，可选接入图形验证码；邮箱、短信验证码通过 notify 的通道发送。
+// 登录失败计数及发送频率限制保存在进程内存中，多实例部署时每个实例单独计数。
+package account
+
+import (
+	"context"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/notify"
+	"github.com/jcbowen/jcbaseGo/component/security"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// 账号状态
+const (
+	StatusDisabled = 0
+	StatusEnabled  = 1
+)
+
+// 验证码用途
+const (
+	PurposeRegister = "register" // 注册
+	PurposeReset    = "reset"    // 找回密码
+	PurposeBind     = "bind"     // 绑定邮箱、手机号
+)
+
+// 错误
+var (
+	ErrAccountExists      = errcode.New(errcode.ConflictWithExisting, "账号已存在")
+	ErrInvalidCredentials = errcode.New(errcode.IncorrectUsernameOrPassword, "账号或密码错误")
+	ErrDisabled           = errcode.New(errcode.DISABLE, "账号已被禁用")
+	ErrNotFound           = errcode.New(errcode.NotExist, "账号不存在")
+	ErrTooManyAttempts    = errcode.New(http.StatusTooManyRequests, "操作过于频繁，请稍后再试")
+	ErrInvalidCaptcha     = errcode.New(errcode.ParamInvalid, "图形验证码错误")
+	ErrInvalidCode        = errcode.New(errcode.IllegalCertificate, "验证码错误或已过期")
+	ErrPasswordTooWeak    = errcode.New(errcode.IllegalFormat, "密码强度不足")
+	ErrInvalidUsername    = errcode.New(errcode.IllegalFormat, "用户名不能是邮箱或手机号")
+)
+
+// User 用户
+type User struct {
+	ID          uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Username    string     `gorm:"column:username;size:64;not null;uniqueIndex;comment:用户名" json:"username"`
+	Email       string     `gorm:"column:email;size:128;default:'';index;comment:邮箱" json:"email"`
+	Phone       string     `gorm:"column:phone;size:32;default:'';index;comment:手机号" json:"phone"`
+	Password    string     `gorm:"column:password;size:255;not null;comment:密码哈希" json:"-"`
+	Nickname    string     `gorm:"column:nickname;size:64;default:'';comment:昵称" json:"nickname"`
+	Avatar      string     `gorm:"column:avatar;size:500;default:'';comment:头像" json:"avatar"`
+	Status      int        `gorm:"column:status;default:1;comment:状态 0禁用 1启用" json:"status"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at;comment:最后登录时间" json:"last_login_at"`
+	LastLoginIP string     `gorm:"column:last_login_ip;size:64;default:'';comment:最后登录IP" json:"last_login_ip"`
+	CreatedAt   time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// LoginLog 登录日志
+type LoginLog struct {
+	ID        uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"column:user_id;default:0;index;comment:用户ID，账号不存在时为0" json:"user_id"`
+	Account   string    `gorm:"column:account;size:128;default:'';comment:登录时输入的账号" json:"account"`
+	Method    string    `gorm:"column:method;size:32;default:'';comment:登录方式" json:"method"`
+	IP        string    `gorm:"column:ip;size:64;default:'';comment:IP" json:"ip"`
+	UserAgent string    `gorm:"column:user_agent;size:500;default:'';comment:UA" json:"user_agent"`
+	Success   bool      `gorm:"column:success;default:false;comment:是否成功" json:"success"`
+	Reason    string    `gorm:"column:reason;size:255;default:'';comment:失败原因" json:"reason"`
+	CreatedAt time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+// VerifyCode 邮箱、短信验证码
+type VerifyCode struct {
+	ID        uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Target    string     `gorm:"column:target;size:128;not null;index:idx_target_purpose,priority:1;comment:邮箱或手机号" json:"target"`
+	Purpose   string     `gorm:"column:purpose;size:32;not null;index:idx_target_purpose,priority:2;comment:用途" json:"purpose"`
+	CodeHash  string     `gorm:"column:code_hash;size:64;not null;comment:验证码哈希" json:"-"`
+	Attempts  int        `gorm:"column:attempts;default:0;comment:已校验次数" json:"attempts"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;comment:过期时间" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at;comment:使用时间" json:"used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at" json:"created_at"`
+}
+
+// Captcha 图形验证码校验，由项目接入具体的验证码实现
+type Captcha interface {
+	Verify(ctx context.Context, id, answer string) bool
+}
+
+// Options 配置
+type Options struct {
+	UserTable     string `default:"account_user"`        // 用户表名（含前缀）
+	LoginLogTable string `default:"account_login_log"`   // 登录日志表名（含前缀）
+	CodeTable     string `default:"account_verify_code"` // 验证码表名（含前缀）
+	OAuthTable    string `default:"account_oauth"`       // 第三方账号绑定表名（含前缀）
+	AutoMigrate   bool   // 是否自动创建数据表
+
+	Password security.Password // 密码哈希配置，MinStrength 可限制密码强度
+
+	MaxFailures  int           `default:"5"` // 同一账号或IP连续登录失败的次数上限，超过后锁定
+	LockDuration time.Duration // 锁定时长，默认15分钟
+
+	Captcha      Captcha // 图形验证码，为空时不校验
+	CaptchaAfter int     // 登录失败多少次后要求图形验证码，0表示始终要求；注册、发送验证码始终要求
+
+	EmailChannel   notify.Channel // 邮件验证码发送通道，如 notify.NewMail(conf.Mailer)
+	SMSChannel     notify.Channel // 短信验证码发送通道，如 notify.NewSMS(sender)
+	CodeLength     int            `default:"6"` // 验证码位数
+	CodeTTL        time.Duration  // 验证码有效期，默认10分钟
+	CodeInterval   time.Duration  // 同一邮箱、手机号发送验证码的最小间隔，默认60秒
+	CodeMaxAttempt int            `default:"5"` // 同一验证码最多校验次数
+	CodeTemplate   string         // 验证码内容模板，{code}、{minutes} 会被替换，默认“您的验证码为{code}，{minutes}分钟内有效。”
+
+	VerifyOnRegister bool // 注册时填写的手机号、邮箱是否需要验证码，两者都填写时分别验证
+
+	OAuthAutoRegister bool // 第三方账号未绑定时是否自动注册，见 LoginOAuth
+}
+
+// Account 用户账号
+//
+// 示例:
+//
+//	acc := account.New(db.GetDb(), account.Options{
+//		AutoMigrate:  true,
+//		Password:     security.Password{Algorithm: security.PasswordArgon2id, MinStrength: 2},
+//		EmailChannel: notify.NewMail(conf.Mailer),
+//	})
+//	user, err := acc.Register(ctx, account.RegisterForm{Username: "alice", Password: "s3cret!Pass"})
+//	user, err = acc.Login(ctx, account.LoginForm{Account: "alice", Password: "s3cret!Pass", IP: ip})
+type Account struct {
+	db        *gorm.DB
+	opt       Options
+	limiter   *limiter
+	dummyHash string // 账号不存在时用于校验的哈希，使其与密码错误的耗时一致
+}
+
+// New 创建用户账号
+func New(db *gorm.DB, opts ...Options) *Account {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.LockDuration <= 0 {
+		opt.LockDuration = 15 * time.Minute
+	}
+	if opt.CodeTTL <= 0 {
+		opt.CodeTTL = 10 * time.Minute
+	}
+	if opt.CodeInterval <= 0 {
+		opt.CodeInterval = time.Minute
+	}
+	if opt.CodeTemplate == "" {
+		opt.CodeTemplate = "您的验证码为{code}，{minutes}分钟内有效。"
+	}
+
+	a := &Account{db: db, opt: opt, limiter: newLimiter()}
+	dummy := opt.Password
+	dummy.MinStrength = 0
+	a.dummyHash, _ = dummy.Hash("account:dummy-password")
+	if opt.AutoMigrate {
+		ctx := context.Background()
+		_ = a.users(ctx).AutoMigrate(&User{})
+		_ = a.loginLogs(ctx).AutoMigrate(&LoginLog{})
+		_ = a.codes(ctx).AutoMigrate(&VerifyCode{})
+		_ = a.oauthBindings(ctx).AutoMigrate(&OAuthBinding{})
+	}
+	return a
+}
+
+func (a *Account) users(ctx context.Context) *gorm.DB {
+	return a.db.WithContext(ctx).Table(a.opt.UserTable)
+}
+
+func (a *Account) loginLogs(ctx context.Context) *gorm.DB {
+	return a.db.WithContext(ctx).Table(a.opt.LoginLogTable)
+}
+
+func (a *Account) codes(ctx context.Context) *gorm.DB {
+	return a.db.WithContext(ctx).Table(a.opt.CodeTable)
+}
+
+func (a *Account) oauthBindings(ctx context.Context) *gorm.DB {
+	return a.db.WithContext(ctx).Table(a.opt.OAuthTable)
+}
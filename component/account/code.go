@@ -0,0 +1,161 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/notify"
+	"github.com/jcbowen/jcbaseGo/component/validator"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"gorm.io/gorm"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SendCode 向邮箱或手机号发送验证码，配置了 Captcha 时需先通过图形验证码；
+// 同一目标在 CodeInterval 内只能发送一次。用途为 PurposeReset 且账号不存在时不发送也不报错，避免探测账号
+func (a *Account) SendCode(ctx context.Context, target, purpose, captchaID, captcha string) error {
+	target = strings.TrimSpace(target)
+	if purpose != PurposeRegister && purpose != PurposeReset && purpose != PurposeBind {
+		return errcode.New(errcode.ParamInvalid, "验证码用途错误")
+	}
+	channel, email, phone, err := a.channelFor(target)
+	if err != nil {
+		return err
+	}
+	if err = a.verifyCaptcha(ctx, captchaID, captcha); err != nil {
+		return err
+	}
+	if ok, _ := a.limiter.allow("code:"+target, a.opt.CodeInterval); !ok {
+		return ErrTooManyAttempts
+	}
+
+	switch purpose {
+	case PurposeRegister, PurposeBind:
+		if err = a.checkUnique(ctx, 0, "", email, phone); err != nil {
+			return err
+		}
+	case PurposeReset:
+		if _, err = a.findByAccount(ctx, target); errors.Is(err, ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	code, err := randomDigits(a.opt.CodeLength)
+	if err != nil {
+		return err
+	}
+	record := &VerifyCode{
+		Target:    target,
+		Purpose:   purpose,
+		CodeHash:  hashCode(target, purpose, code),
+		ExpiresAt: time.Now().Add(a.opt.CodeTTL),
+	}
+	if err = a.codes(ctx).Create(record).Error; err != nil {
+		return err
+	}
+
+	content := strings.NewReplacer(
+		"{code}", code,
+		"{minutes}", strconv.Itoa(int(a.opt.CodeTTL.Minutes())),
+	).Replace(a.opt.CodeTemplate)
+	err = channel.Send(ctx, notify.Message{
+		Event:   "account.code." + purpose,
+		Title:   "验证码",
+		Content: content,
+		To:      []string{target},
+		Payload: map[string]any{"code": code, "purpose": purpose, "ttl": a.opt.CodeTTL.String()},
+	})
+	if err != nil {
+		a.limiter.reset("code:" + target) // 发送失败允许立即重试
+		return errcode.Wrap(err, errcode.Unknown, "验证码发送失败")
+	}
+	return nil
+}
+
+// VerifyCode 校验验证码，校验通过后验证码失效；同一验证码最多校验 CodeMaxAttempt 次
+func (a *Account) VerifyCode(ctx context.Context, target, purpose, code string) error {
+	target, code = strings.TrimSpace(target), strings.TrimSpace(code)
+	if target == "" || code == "" {
+		return ErrInvalidCode
+	}
+	var record VerifyCode
+	err := a.codes(ctx).
+		Where("target = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", target, purpose, time.Now()).
+		Order("id DESC").Take(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+	// 先原子地占用一次校验次数再比对，并发猜测时同样受 CodeMaxAttempt 限制
+	claim := a.codes(ctx).Where("id = ? AND attempts < ?", record.ID, a.opt.CodeMaxAttempt).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if claim.Error != nil {
+		return claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		return ErrInvalidCode
+	}
+
+	expected := hashCode(target, purpose, code)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(record.CodeHash)) != 1 {
+		return ErrInvalidCode
+	}
+	// 以 used_at IS NULL 为条件更新，防止并发请求重复使用同一验证码
+	result := a.codes(ctx).Where("id = ? AND used_at IS NULL", record.ID).Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// channelFor 根据目标格式选择发送通道
+func (a *Account) channelFor(target string) (channel notify.Channel, email, phone string, err error) {
+	switch {
+	case validator.IsEmail(target):
+		if a.opt.EmailChannel == nil {
+			return nil, "", "", errcode.New(errcode.NotSupported, "未配置邮件发送通道")
+		}
+		return a.opt.EmailChannel, target, "", nil
+	case validator.IsMobile(target):
+		if a.opt.SMSChannel == nil {
+			return nil, "", "", errcode.New(errcode.NotSupported, "未配置短信发送通道")
+		}
+		return a.opt.SMSChannel, "", target, nil
+	default:
+		return nil, "", "", errcode.New(errcode.IllegalFormat, "请输入正确的邮箱或手机号")
+	}
+}
+
+// hashCode 验证码只保存哈希，目标及用途参与计算，避免跨用途使用
+func hashCode(target, purpose, code string) string {
+	sum := sha256.Sum256([]byte(purpose + "|" + target + "|" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomDigits(n int) (string, error) {
+	if n <= 0 {
+		n = 6
+	}
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(byte('0' + d.Int64()))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,94 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestAccount(t *testing.T) *Account {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	return New(db, Options{AutoMigrate: true, CodeMaxAttempt: 3})
+}
+
+func createTestCode(t *testing.T, a *Account, target, purpose, code string) {
+	t.Helper()
+	record := &VerifyCode{
+		Target:    target,
+		Purpose:   purpose,
+		CodeHash:  hashCode(target, purpose, code),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	if err := a.codes(context.Background()).Create(record).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyCode(t *testing.T) {
+	a := newTestAccount(t)
+	ctx := context.Background()
+	createTestCode(t, a, "a@example.com", PurposeReset, "123456")
+
+	if err := a.VerifyCode(ctx, "a@example.com", PurposeRegister, "123456"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("code of another purpose: err = %v", err)
+	}
+	if err := a.VerifyCode(ctx, "a@example.com", PurposeReset, "123456"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.VerifyCode(ctx, "a@example.com", PurposeReset, "123456"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("used code: err = %v", err)
+	}
+}
+
+func TestVerifyCodeMaxAttempt(t *testing.T) {
+	a := newTestAccount(t)
+	ctx := context.Background()
+	createTestCode(t, a, "b@example.com", PurposeReset, "123456")
+
+	for i := 0; i < 3; i++ {
+		_ = a.VerifyCode(ctx, "b@example.com", PurposeReset, "000000")
+	}
+	if err := a.VerifyCode(ctx, "b@example.com", PurposeReset, "123456"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("correct code after CodeMaxAttempt wrong guesses: err = %v", err)
+	}
+}
+
+func TestVerifyCodeConcurrentGuesses(t *testing.T) {
+	a := newTestAccount(t)
+	ctx := context.Background()
+	createTestCode(t, a, "c@example.com", PurposeReset, "999999")
+
+	// 并发提交100个错误的验证码，最多只能校验 CodeMaxAttempt 次
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = a.VerifyCode(ctx, "c@example.com", PurposeReset, fmt.Sprintf("%06d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	var record VerifyCode
+	if err := a.codes(ctx).Where("target = ?", "c@example.com").Take(&record).Error; err != nil {
+		t.Fatal(err)
+	}
+	if record.Attempts != 3 {
+		t.Fatalf("attempts = %d, want CodeMaxAttempt", record.Attempts)
+	}
+	if err := a.VerifyCode(ctx, "c@example.com", PurposeReset, "999999"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("correct code after the attempts are used up: err = %v", err)
+	}
+}
@@ -0,0 +1,401 @@
+package account
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
+	"github.com/jcbowen/jcbaseGo/component/oauth"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"github.com/jcbowen/jcbaseGo/middleware"
+	"strconv"
+)
+
+// HandlerOptions 接口配置
+type HandlerOptions struct {
+	// CurrentUser 从请求中获取当前登录用户的ID，个人资料等需要登录的接口使用，为空时这些接口不注册
+	CurrentUser func(c *gin.Context) (uint, bool)
+	// OnLogin 登录成功后签发凭证（token、session等），返回值作为 /login 接口的 data 输出；为空时输出用户信息
+	OnLogin func(c *gin.Context, user *User) (any, error)
+	// OAuth 第三方登录，不为空时注册 /oauth 相关接口；OnLogin 中可通过 c.Get(OAuthProfileKey) 获取 *oauth.Profile（含登录后跳转地址）
+	OAuth *oauth.Manager
+}
+
+// OAuthProfileKey 第三方登录回调时 *oauth.Profile 在gin上下文中的键名
+const OAuthProfileKey = "AccountOAuthProfile"
+
+// Handler 账号接口
+type Handler struct {
+	account *Account
+	opt     HandlerOptions
+}
+
+// Handler 获取账号接口
+//
+// 示例:
+//
+//	h := acc.Handler(account.HandlerOptions{
+//		CurrentUser: func(c *gin.Context) (uint, bool) { return c.GetUint("uid"), c.GetUint("uid") > 0 },
+//		OnLogin:     func(c *gin.Context, u *account.User) (any, error) { return issueToken(u.ID) },
+//	})
+//	h.RegisterRoutes(r.Group("/account"))
+func (a *Account) Handler(opts ...HandlerOptions) *Handler {
+	h := &Handler{account: a}
+	if len(opts) > 0 {
+		h.opt = opts[0]
+	}
+	return h
+}
+
+// RegisterRoutes 注册路由：
+//   - POST /register        注册
+//   - POST /login           登录
+//   - POST /send-code       发送邮箱或短信验证码，参数 target、purpose
+//   - POST /reset-password  通过验证码重置密码
+//
+// 配置了 OAuth 时还会注册：
+//   - GET  /oauth/:provider           跳转到第三方授权页面，参数 redirect（登录后跳转地址）、bind（为1时绑定到当前登录用户）
+//   - GET  /oauth/:provider/callback  授权回调，绑定流程绑定到发起授权的用户，否则登录（或自动注册）
+//
+// 配置了 CurrentUser 时还会注册（需登录）：
+//   - GET  /profile          个人资料
+//   - POST /profile          修改个人资料
+//   - POST /change-password  修改密码
+//   - POST /bind             绑定邮箱、手机号
+//   - GET  /login-logs       登录日志，参数 page、page_size
+//   - GET  /oauth-bindings   已绑定的第三方账号（配置了 OAuth 时）
+//   - POST /oauth-unbind     解除第三方账号绑定，参数 provider（配置了 OAuth 时）
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.POST("/register", h.Register)
+	r.POST("/login", h.Login)
+	r.POST("/send-code", h.SendCode)
+	r.POST("/reset-password", h.ResetPassword)
+	if h.opt.OAuth != nil {
+		r.GET("/oauth/:provider", h.OAuthRedirect)
+		r.GET("/oauth/:provider/callback", h.OAuthCallback)
+	}
+	if h.opt.CurrentUser == nil {
+		return
+	}
+	r.GET("/profile", h.Profile)
+	r.POST("/profile", h.UpdateProfile)
+	r.POST("/change-password", h.ChangePassword)
+	r.POST("/bind", h.Bind)
+	r.GET("/login-logs", h.LoginLogs)
+	if h.opt.OAuth != nil {
+		r.GET("/oauth-bindings", h.OAuthBindings)
+		r.POST("/oauth-unbind", h.OAuthUnbind)
+	}
+}
+
+// Register 注册
+func (h *Handler) Register(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		Username  string `json:"username" form:"username" binding:"required,min=3,max=64"`
+		Password  string `json:"password" form:"password" binding:"required,min=6,max=64"`
+		Email     string `json:"email" form:"email" binding:"omitempty,email,max=128"`
+		Phone     string `json:"phone" form:"phone" binding:"omitempty,max=32"`
+		Nickname  string `json:"nickname" form:"nickname" binding:"max=64"`
+		Code      string `json:"code" form:"code"`
+		EmailCode string `json:"email_code" form:"email_code"`
+		CaptchaID string `json:"captcha_id" form:"captcha_id"`
+		Captcha   string `json:"captcha" form:"captcha"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	user, err := h.account.Register(c.Request.Context(), RegisterForm(form))
+	if err != nil {
+		base.Error(err)
+		return
+	}
+	base.Result(errcode.SuccessChange, "success", user)
+}
+
+// Login 登录
+func (h *Handler) Login(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		Account   string `json:"account" form:"account" binding:"required,max=128"`
+		Password  string `json:"password" form:"password" binding:"required,max=64"`
+		CaptchaID string `json:"captcha_id" form:"captcha_id"`
+		Captcha   string `json:"captcha" form:"captcha"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	user, err := h.account.Login(c.Request.Context(), LoginForm{
+		Account:   form.Account,
+		Password:  form.Password,
+		CaptchaID: form.CaptchaID,
+		Captcha:   form.Captcha,
+		IP:        middleware.GetRealIP(c),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		base.Error(err)
+		return
+	}
+	h.loginSuccess(c, user)
+}
+
+// OAuthRedirect 跳转到第三方授权页面，bind 为1时发起绑定流程，需已登录，发起授权的用户记录在授权参数中
+func (h *Handler) OAuthRedirect(c *gin.Context) {
+	req := oauth.AuthRequest{Redirect: c.Query("redirect")}
+	if c.Query("bind") == "1" {
+		userID, ok := h.currentUser(c)
+		if !ok {
+			return
+		}
+		req.BindUser = strconv.FormatUint(uint64(userID), 10)
+	}
+	h.opt.OAuth.RedirectWith(c, req)
+}
+
+// OAuthCallback 第三方授权回调，绑定流程（OAuthRedirect 传入 bind=1）绑定到发起授权的用户，需与当前登录用户一致；
+// 登录流程使用第三方账号登录，不会绑定到当前登录用户
+func (h *Handler) OAuthCallback(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	if errMsg := c.Query("error"); errMsg != "" {
+		base.Error(errcode.New(oauth.ErrAuthorization.Code, oauth.ErrAuthorization.Message).WithMeta("error", errMsg))
+		return
+	}
+	profile, err := h.opt.OAuth.HandleCallback(c)
+	if err != nil {
+		base.Error(err)
+		return
+	}
+	c.Set(OAuthProfileKey, profile)
+
+	if profile.BindUser != "" {
+		userID, ok := h.currentUser(c)
+		if !ok {
+			return
+		}
+		if strconv.FormatUint(uint64(userID), 10) != profile.BindUser {
+			base.Error(oauth.ErrInvalidState)
+			return
+		}
+		if err = h.account.BindOAuth(c.Request.Context(), userID, profile); err != nil {
+			base.Error(err)
+			return
+		}
+		base.Result(errcode.SuccessChange, "success", map[string]any{"provider": profile.Provider, "redirect": profile.Redirect})
+		return
+	}
+
+	user, err := h.account.LoginOAuth(c.Request.Context(), profile, middleware.GetRealIP(c), c.Request.UserAgent())
+	if err != nil {
+		base.Error(err)
+		return
+	}
+	h.loginSuccess(c, user)
+}
+
+// SendCode 发送验证码
+func (h *Handler) SendCode(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		Target    string `json:"target" form:"target" binding:"required,max=128"`
+		Purpose   string `json:"purpose" form:"purpose" binding:"required,oneof=register reset bind"`
+		CaptchaID string `json:"captcha_id" form:"captcha_id"`
+		Captcha   string `json:"captcha" form:"captcha"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.account.SendCode(c.Request.Context(), form.Target, form.Purpose, form.CaptchaID, form.Captcha); err != nil {
+		base.Error(err)
+		return
+	}
+	base.Success()
+}
+
+// ResetPassword 通过验证码重置密码
+func (h *Handler) ResetPassword(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		Target   string `json:"target" form:"target" binding:"required,max=128"`
+		Code     string `json:"code" form:"code" binding:"required"`
+		Password string `json:"password" form:"password" binding:"required,min=6,max=64"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.account.ResetPassword(c.Request.Context(), form.Target, form.Code, form.Password); err != nil {
+		base.Error(err)
+		return
+	}
+	base.Result(errcode.SuccessChange, "success")
+}
+
+// Profile 个人资料
+func (h *Handler) Profile(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	user, err := h.account.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		controller.Base{GinContext: c}.Error(err)
+		return
+	}
+	controller.Base{GinContext: c}.Success(user)
+}
+
+// UpdateProfile 修改个人资料
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var form Profile
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.account.UpdateProfile(c.Request.Context(), userID, form); err != nil {
+		base.Error(err)
+		return
+	}
+	base.Result(errcode.SuccessChange, "success")
+}
+
+// ChangePassword 修改密码
+func (h *Handler) ChangePassword(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var form struct {
+		OldPassword string `json:"old_password" form:"old_password" binding:"required"`
+		Password    string `json:"password" form:"password" binding:"required,min=6,max=64"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.account.ChangePassword(c.Request.Context(), userID, form.OldPassword, form.Password); err != nil {
+		base.Error(err)
+		return
+	}
+	base.Result(errcode.SuccessChange, "success")
+}
+
+// Bind 绑定邮箱、手机号
+func (h *Handler) Bind(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var form struct {
+		Target string `json:"target" form:"target" binding:"required,max=128"`
+		Code   string `json:"code" form:"code" binding:"required"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.account.Bind(c.Request.Context(), userID, form.Target, form.Code); err != nil {
+		base.Error(err)
+		return
+	}
+	base.Result(errcode.SuccessChange, "success")
+}
+
+// LoginLogs 当前用户的登录日志
+func (h *Handler) LoginLogs(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var form struct {
+		Page     int `form:"page"`
+		PageSize int `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if form.PageSize < 1 {
+		form.PageSize = 10
+	}
+	data, err := h.account.LoginLogs(c.Request.Context(), userID, form.Page, form.PageSize)
+	if err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Success(data)
+}
+
+// OAuthBindings 当前用户已绑定的第三方账号
+func (h *Handler) OAuthBindings(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	list, err := h.account.OAuthBindings(c.Request.Context(), userID)
+	if err != nil {
+		controller.Base{GinContext: c}.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	controller.Base{GinContext: c}.Success(list)
+}
+
+// OAuthUnbind 解除第三方账号绑定
+func (h *Handler) OAuthUnbind(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	var form struct {
+		Provider string `json:"provider" form:"provider" binding:"required,max=32"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.account.UnbindOAuth(c.Request.Context(), userID, form.Provider); err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessDelete, "success")
+}
+
+// loginSuccess 登录成功后签发凭证并输出
+func (h *Handler) loginSuccess(c *gin.Context, user *User) {
+	base := controller.Base{GinContext: c}
+	if h.opt.OnLogin == nil {
+		base.Success(user)
+		return
+	}
+	data, err := h.opt.OnLogin(c, user)
+	if err != nil {
+		base.Error(err)
+		return
+	}
+	base.Success(data)
+}
+
+// currentUser 获取当前登录用户，未登录时输出 errcode.LoginInvalid
+func (h *Handler) currentUser(c *gin.Context) (uint, bool) {
+	var userID uint
+	ok := false
+	if h.opt.CurrentUser != nil {
+		userID, ok = h.opt.CurrentUser(c)
+	}
+	if !ok || userID == 0 {
+		controller.Base{GinContext: c}.Error(errcode.New(errcode.LoginInvalid, ""))
+		return 0, false
+	}
+	return userID, true
+}
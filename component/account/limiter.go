@@ -0,0 +1,81 @@
+package account
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter 进程内的计数器，用于登录失败计数及发送频率限制，过期的记录在写入时顺带清理
+type limiter struct {
+	mu      sync.Mutex
+	entries map[string]*limiterEntry
+	sweepAt time.Time
+}
+
+type limiterEntry struct {
+	count   int
+	expires time.Time
+}
+
+func newLimiter() *limiter {
+	return &limiter{entries: make(map[string]*limiterEntry)}
+}
+
+// hit 计数加1，window 为从第一次计数起的有效期，返回当前计数
+func (l *limiter) hit(key string, window time.Duration) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.sweep(now)
+	e, ok := l.entries[key]
+	if !ok || !now.Before(e.expires) {
+		e = &limiterEntry{expires: now.Add(window)}
+		l.entries[key] = e
+	}
+	e.count++
+	return e.count
+}
+
+// count 当前计数，已过期时为0
+func (l *limiter) count(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[key]
+	if !ok || !time.Now().Before(e.expires) {
+		return 0
+	}
+	return e.count
+}
+
+// allow 距上次允许超过 interval 时返回true并重新计时，否则返回false及剩余等待时间
+func (l *limiter) allow(key string, interval time.Duration) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.sweep(now)
+	if e, ok := l.entries[key]; ok && now.Before(e.expires) {
+		return false, e.expires.Sub(now)
+	}
+	l.entries[key] = &limiterEntry{count: 1, expires: now.Add(interval)}
+	return true, 0
+}
+
+// reset 清除计数
+func (l *limiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+// sweep 每分钟最多清理一次过期记录，调用方需持有锁
+func (l *limiter) sweep(now time.Time) {
+	if now.Before(l.sweepAt) {
+		return
+	}
+	l.sweepAt = now.Add(time.Minute)
+	for key, e := range l.entries {
+		if !now.Before(e.expires) {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/oauth"
+	"testing"
+)
+
+func TestLoginOAuthUnionIDScopedToProvider(t *testing.T) {
+	a := newTestAccount(t)
+	ctx := context.Background()
+	user, err := a.Register(ctx, RegisterForm{Username: "alice", Password: "secret1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = a.BindOAuth(ctx, user.ID, &oauth.Profile{Provider: "wechat", OpenID: "o1", UnionID: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他开放平台的 UnionID 即使相同也不能关联
+	if _, err = a.LoginOAuth(ctx, &oauth.Profile{Provider: "qq", OpenID: "q1", UnionID: "u1"}, "", ""); !errors.Is(err, ErrOAuthNotBound) {
+		t.Fatalf("union id of another provider: err = %v, want ErrOAuthNotBound", err)
+	}
+
+	// 同一开放平台下的其他应用按 UnionID 关联
+	got, err := a.LoginOAuth(ctx, &oauth.Profile{Provider: "wechat_mp", OpenID: "o2", UnionID: "u1"}, "", "")
+	if err != nil || got.ID != user.ID {
+		t.Fatalf("union id of the same platform: user = %+v, err = %v", got, err)
+	}
+}
@@ -0,0 +1,387 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/security"
+	"github.com/jcbowen/jcbaseGo/component/validator"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// 登录方式
+const (
+	MethodPassword = "password"
+	MethodOAuth    = "oauth"
+)
+
+// RegisterForm 注册信息
+type RegisterForm struct {
+	Username  string
+	Password  string
+	Email     string
+	Phone     string
+	Nickname  string
+	Code      string // 验证码，开启 VerifyOnRegister 时需要；同时填写手机号和邮箱时为短信验证码
+	EmailCode string // 同时填写手机号和邮箱时的邮箱验证码
+	CaptchaID string // 图形验证码ID，配置了 Captcha 时需要
+	Captcha   string // 图形验证码
+}
+
+// LoginForm 登录信息
+type LoginForm struct {
+	Account   string // 用户名、邮箱或手机号
+	Password  string
+	CaptchaID string
+	Captcha   string
+	IP        string // 客户端IP，用于失败计数及登录日志
+	UserAgent string
+}
+
+// Profile 可修改的个人资料，为 nil 的字段不修改
+type Profile struct {
+	Nickname *string `json:"nickname" form:"nickname" binding:"omitempty,max=64"`
+	Avatar   *string `json:"avatar" form:"avatar" binding:"omitempty,max=500"`
+}
+
+// Register 注册，用户名、邮箱、手机号均不能与已有账号重复
+func (a *Account) Register(ctx context.Context, form RegisterForm) (*User, error) {
+	form.Username = strings.TrimSpace(form.Username)
+	form.Email = strings.TrimSpace(form.Email)
+	form.Phone = strings.TrimSpace(form.Phone)
+	if err := a.verifyCaptcha(ctx, form.CaptchaID, form.Captcha); err != nil {
+		return nil, err
+	}
+	if a.opt.VerifyOnRegister {
+		// 填写的手机号、邮箱都要验证，未验证的邮箱、手机号同样可以登录及找回密码
+		emailCode := form.Code
+		if form.Phone != "" {
+			if err := a.VerifyCode(ctx, form.Phone, PurposeRegister, form.Code); err != nil {
+				return nil, err
+			}
+			emailCode = form.EmailCode
+		}
+		if form.Email != "" {
+			if err := a.VerifyCode(ctx, form.Email, PurposeRegister, emailCode); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := checkUsername(form.Username); err != nil {
+		return nil, err
+	}
+	if err := a.checkUnique(ctx, 0, form.Username, form.Email, form.Phone); err != nil {
+		return nil, err
+	}
+	hash, err := a.hashPassword(form.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{
+		Username: form.Username,
+		Email:    form.Email,
+		Phone:    form.Phone,
+		Password: hash,
+		Nickname: form.Nickname,
+		Status:   StatusEnabled,
+	}
+	if err = a.users(ctx).Create(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login 使用用户名、邮箱或手机号及密码登录，成功与失败均记录登录日志；
+// 同一账号或IP连续失败超过 MaxFailures 次后锁定 LockDuration
+func (a *Account) Login(ctx context.Context, form LoginForm) (*User, error) {
+	form.Account = strings.TrimSpace(form.Account)
+	accountKey, ipKey := "login:account:"+form.Account, "login:ip:"+form.IP
+	failures := max(a.limiter.count(accountKey), a.limiter.count(ipKey))
+	if failures >= a.opt.MaxFailures {
+		a.audit(ctx, 0, form.Account, MethodPassword, form.IP, form.UserAgent, ErrTooManyAttempts)
+		return nil, ErrTooManyAttempts
+	}
+	if failures >= a.opt.CaptchaAfter {
+		if err := a.verifyCaptcha(ctx, form.CaptchaID, form.Captcha); err != nil {
+			a.audit(ctx, 0, form.Account, MethodPassword, form.IP, form.UserAgent, err)
+			return nil, err
+		}
+	}
+
+	user, err := a.findByAccount(ctx, form.Account)
+	if err == nil && !a.opt.Password.Verify(form.Password, user.Password) {
+		err = ErrInvalidCredentials
+	}
+	if errors.Is(err, ErrNotFound) {
+		// 账号不存在时同样校验一次密码，避免通过响应耗时判断账号是否存在
+		a.opt.Password.Verify(form.Password, a.dummyHash)
+	}
+	if err != nil {
+		var userID uint
+		if errors.Is(err, ErrNotFound) {
+			err = ErrInvalidCredentials // 不区分账号不存在与密码错误
+		} else if user != nil {
+			userID = user.ID
+		}
+		if errors.Is(err, ErrInvalidCredentials) {
+			a.limiter.hit(accountKey, a.opt.LockDuration)
+			a.limiter.hit(ipKey, a.opt.LockDuration)
+		}
+		a.audit(ctx, userID, form.Account, MethodPassword, form.IP, form.UserAgent, err)
+		return nil, err
+	}
+
+	a.limiter.reset(accountKey)
+	a.limiter.reset(ipKey)
+	if a.opt.Password.NeedsRehash(user.Password) {
+		if hash, err := a.opt.Password.Hash(form.Password); err == nil {
+			_ = a.users(ctx).Where("id = ?", user.ID).Update("password", hash).Error
+			user.Password = hash
+		}
+	}
+	if err = a.LoginSucceeded(ctx, user, MethodPassword, form.IP, form.UserAgent); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// LoginSucceeded 以其他方式（如第三方登录）完成认证后调用：检查账号状态，更新最后登录信息并记录登录日志
+func (a *Account) LoginSucceeded(ctx context.Context, user *User, method, ip, userAgent string) error {
+	if user.Status != StatusEnabled {
+		a.audit(ctx, user.ID, user.Username, method, ip, userAgent, ErrDisabled)
+		return ErrDisabled
+	}
+	now := time.Now()
+	user.LastLoginAt, user.LastLoginIP = &now, ip
+	err := a.users(ctx).Where("id = ?", user.ID).Updates(map[string]any{
+		"last_login_at": now,
+		"last_login_ip": ip,
+		"updated_at":    now,
+	}).Error
+	if err != nil {
+		return err
+	}
+	a.audit(ctx, user.ID, user.Username, method, ip, userAgent, nil)
+	return nil
+}
+
+// ChangePassword 验证原密码后修改密码
+func (a *Account) ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error {
+	user, err := a.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !a.opt.Password.Verify(oldPassword, user.Password) {
+		return ErrInvalidCredentials
+	}
+	return a.SetPassword(ctx, userID, newPassword)
+}
+
+// ResetPassword 通过发送到邮箱或手机号的验证码重置密码
+func (a *Account) ResetPassword(ctx context.Context, target, code, newPassword string) error {
+	target = strings.TrimSpace(target)
+	if err := a.VerifyCode(ctx, target, PurposeReset, code); err != nil {
+		return err
+	}
+	user, err := a.findByAccount(ctx, target)
+	if err != nil {
+		return err
+	}
+	return a.SetPassword(ctx, user.ID, newPassword)
+}
+
+// SetPassword 直接设置密码，用于管理员重置等场景
+func (a *Account) SetPassword(ctx context.Context, userID uint, password string) error {
+	hash, err := a.hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return a.update(ctx, userID, map[string]any{"password": hash})
+}
+
+// GetUser 根据ID获取用户
+func (a *Account) GetUser(ctx context.Context, id uint) (*User, error) {
+	var user User
+	if err := a.users(ctx).Where("id = ?", id).Take(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile 修改个人资料
+func (a *Account) UpdateProfile(ctx context.Context, userID uint, profile Profile) error {
+	values := make(map[string]any)
+	if profile.Nickname != nil {
+		values["nickname"] = strings.TrimSpace(*profile.Nickname)
+	}
+	if profile.Avatar != nil {
+		values["avatar"] = *profile.Avatar
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return a.update(ctx, userID, values)
+}
+
+// Bind 通过验证码绑定或更换邮箱、手机号，target 包含 @ 时视为邮箱
+func (a *Account) Bind(ctx context.Context, userID uint, target, code string) error {
+	target = strings.TrimSpace(target)
+	if err := a.VerifyCode(ctx, target, PurposeBind, code); err != nil {
+		return err
+	}
+	field, email, phone := "phone", "", target
+	if strings.Contains(target, "@") {
+		field, email, phone = "email", target, ""
+	}
+	if err := a.checkUnique(ctx, userID, "", email, phone); err != nil {
+		return err
+	}
+	return a.update(ctx, userID, map[string]any{field: target})
+}
+
+// SetStatus 启用或禁用账号
+func (a *Account) SetStatus(ctx context.Context, userID uint, status int) error {
+	return a.update(ctx, userID, map[string]any{"status": status})
+}
+
+// LoginLogs 登录日志分页列表，userID 为0时查询全部
+func (a *Account) LoginLogs(ctx context.Context, userID uint, page, pageSize int) (jcbaseGo.ListData, error) {
+	page, pageSize = max(page, 1), min(max(pageSize, 1), 1000)
+	query := a.loginLogs(ctx)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return jcbaseGo.ListData{}, err
+	}
+	list := make([]LoginLog, 0)
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error
+	return jcbaseGo.ListData{List: list, Total: int(total), Page: page, PageSize: pageSize}, err
+}
+
+// ----- 内部方法 ----- /
+
+// findByAccount 根据用户名、邮箱或手机号查找用户
+func (a *Account) findByAccount(ctx context.Context, account string) (*User, error) {
+	if account == "" {
+		return nil, ErrNotFound
+	}
+	var user User
+	err := a.users(ctx).Where("username = ? OR email = ? OR phone = ?", account, account, account).
+		Order("id").Take(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// checkUnique 检查用户名、邮箱、手机号是否已被其他账号使用，空值不检查；
+// 登录时三者任一均可作为账号，因此每个值都要与三列比较
+func (a *Account) checkUnique(ctx context.Context, exceptID uint, username, email, phone string) error {
+	conds := make([]string, 0, 3)
+	args := make([]any, 0, 9)
+	for _, value := range []string{username, email, phone} {
+		if value != "" {
+			conds = append(conds, "username = ? OR email = ? OR phone = ?")
+			args = append(args, value, value, value)
+		}
+	}
+	if len(conds) == 0 {
+		return nil
+	}
+	var count int64
+	query := a.users(ctx).Where(strings.Join(conds, " OR "), args...)
+	if exceptID > 0 {
+		query = query.Where("id <> ?", exceptID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAccountExists
+	}
+	return nil
+}
+
+// checkUsername 用户名不能是邮箱或手机号的格式，避免与其他账号的邮箱、手机号混淆
+func checkUsername(username string) error {
+	if strings.Contains(username, "@") || validator.IsEmail(username) || validator.IsMobile(username) {
+		return ErrInvalidUsername
+	}
+	return nil
+}
+
+func (a *Account) hashPassword(password string) (string, error) {
+	hash, err := a.opt.Password.Hash(password)
+	if errors.Is(err, security.ErrPasswordTooWeak) {
+		return "", ErrPasswordTooWeak
+	}
+	return hash, err
+}
+
+func (a *Account) update(ctx context.Context, userID uint, values map[string]any) error {
+	values["updated_at"] = time.Now()
+	result := a.users(ctx).Where("id = ?", userID).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// verifyCaptcha 配置了图形验证码时校验
+func (a *Account) verifyCaptcha(ctx context.Context, id, answer string) error {
+	if a.opt.Captcha == nil {
+		return nil
+	}
+	if id == "" || answer == "" || !a.opt.Captcha.Verify(ctx, id, answer) {
+		return ErrInvalidCaptcha
+	}
+	return nil
+}
+
+// audit 记录登录日志，写入失败不影响登录
+func (a *Account) audit(ctx context.Context, userID uint, account, method, ip, userAgent string, err error) {
+	entry := &LoginLog{
+		UserID:    userID,
+		Account:   truncate(account, 128),
+		Method:    method,
+		IP:        ip,
+		UserAgent: truncate(userAgent, 500),
+		Success:   err == nil,
+	}
+	if err != nil {
+		entry.Reason = truncate(errorMessage(err), 255)
+	}
+	_ = a.loginLogs(ctx).Create(entry).Error
+}
+
+// errorMessage 业务错误只记录提示信息
+func errorMessage(err error) string {
+	var appErr *errcode.AppError
+	if errors.As(err, &appErr) && appErr.Cause == nil {
+		return appErr.Message
+	}
+	return err.Error()
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,72 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegisterIdentifiersUnique(t *testing.T) {
+	a := newTestAccount(t)
+	ctx := context.Background()
+	if _, err := a.Register(ctx, RegisterForm{Username: "alice", Password: "secret1", Email: "bob@example.com", Phone: "13800138000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, username := range []string{"bob@example.com", "13800138000", "a@b"} {
+		if _, err := a.Register(ctx, RegisterForm{Username: username, Password: "secret1"}); !errors.Is(err, ErrInvalidUsername) {
+			t.Errorf("username %q: err = %v, want ErrInvalidUsername", username, err)
+		}
+	}
+	// 邮箱、手机号与其他账号的用户名相同
+	for _, form := range []RegisterForm{
+		{Username: "carol", Password: "secret1", Email: "alice"},
+		{Username: "carol", Password: "secret1", Phone: "alice"},
+		{Username: "carol", Password: "secret1", Phone: "bob@example.com"},
+	} {
+		if _, err := a.Register(ctx, form); !errors.Is(err, ErrAccountExists) {
+			t.Errorf("%+v: err = %v, want ErrAccountExists", form, err)
+		}
+	}
+}
+
+func TestRegisterVerifiesEveryIdentifier(t *testing.T) {
+	a := newTestAccount(t)
+	a.opt.VerifyOnRegister = true
+	ctx := context.Background()
+	createTestCode(t, a, "13800138000", PurposeRegister, "111111")
+	createTestCode(t, a, "alice@example.com", PurposeRegister, "222222")
+
+	// 只有短信验证码时邮箱未经验证，不能注册
+	form := RegisterForm{Username: "alice", Password: "secret1", Phone: "13800138000", Email: "alice@example.com", Code: "111111"}
+	if _, err := a.Register(ctx, form); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("unverified email: err = %v, want ErrInvalidCode", err)
+	}
+
+	createTestCode(t, a, "13800138000", PurposeRegister, "333333")
+	form.Code, form.EmailCode = "333333", "222222"
+	user, err := a.Register(ctx, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Phone != form.Phone || user.Email != form.Email {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
+
+func TestLoginUnknownAccount(t *testing.T) {
+	a := newTestAccount(t)
+	ctx := context.Background()
+	if _, err := a.Register(ctx, RegisterForm{Username: "alice", Password: "secret1"}); err != nil {
+		t.Fatal(err)
+	}
+	if a.dummyHash == "" || !a.opt.Password.Verify("account:dummy-password", a.dummyHash) {
+		t.Fatal("dummy hash should be created with the configured algorithm")
+	}
+
+	_, wrongPassword := a.Login(ctx, LoginForm{Account: "alice", Password: "wrong"})
+	_, unknown := a.Login(ctx, LoginForm{Account: "nobody", Password: "wrong"})
+	if !errors.Is(wrongPassword, ErrInvalidCredentials) || !errors.Is(unknown, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: %v, unknown account: %v; want ErrInvalidCredentials", wrongPassword, unknown)
+	}
+}
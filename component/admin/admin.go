@@ -0,0 +1,223 @@
+// Package admin 管理后台脚手架
+//
+// 将认证、RBAC权限、CRUD资源及字典、行政区划接口挂载到同一个路由分组，提供最小可用的JSON接口。
+// 认证方式由项目决定：Options.Auth 中间件校验登录状态后调用 SetUser 写入当前用户，
+// 之后的权限校验、/me 等接口均基于该用户的角色。
+package admin
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/dict"
+	"github.com/jcbowen/jcbaseGo/component/region"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"strconv"
+)
+
+// UserKey 当前用户在gin上下文中的键名
+const UserKey = "AdminUser"
+
+// User 当前登录的管理员
+type User struct {
+	ID    any            `json:"id"`
+	Name  string         `json:"name"`
+	Roles []string       `json:"roles"`
+	Extra map[string]any `json:"extra,omitempty"` // 头像、部门等附加信息，原样输出到 /me
+}
+
+// SetUser 写入当前用户，由认证中间件在认证通过后调用
+func SetUser(c *gin.Context, user User) {
+	c.Set(UserKey, user)
+}
+
+// GetUser 获取当前用户
+func GetUser(c *gin.Context) (User, bool) {
+	user, ok := c.Get(UserKey)
+	if !ok {
+		return User{}, false
+	}
+	u, ok := user.(User)
+	return u, ok
+}
+
+// Resource CRUD资源的控制器，*crud.Trait 已实现；ActionAll、ActionSetValue 为可选方法，实现后自动注册
+type Resource interface {
+	ActionList(c *gin.Context)
+	ActionDetail(c *gin.Context)
+	ActionCreate(c *gin.Context)
+	ActionUpdate(c *gin.Context)
+	ActionDelete(c *gin.Context)
+}
+
+// Options 管理后台配置
+type Options struct {
+	Auth        gin.HandlerFunc   // 认证中间件，必填，认证通过后需调用 SetUser
+	RBAC        *RBAC             // 权限控制，为空时只校验登录状态
+	Dict        *dict.Dict        // 字典，不为空时挂载 /dict 接口（权限 dict:view、dict:edit）
+	Region      *region.Data      // 行政区划，不为空时挂载 /region 接口（登录即可访问）
+	Middlewares []gin.HandlerFunc // 在认证之后执行的中间件，如操作日志
+}
+
+// Admin 管理后台
+type Admin struct {
+	opts      Options
+	resources []resource
+}
+
+type resource struct {
+	Name       string `json:"name"`
+	Title      string `json:"title"`
+	controller Resource
+}
+
+// New 创建管理后台
+//
+// 示例:
+//
+//	a := admin.New(admin.Options{
+//		Auth:   authMiddleware, // 校验token后调用 admin.SetUser(c, admin.User{ID: uid, Name: name, Roles: roles})
+//		RBAC:   admin.NewRBAC().Grant("super", "*").Grant("editor", "article:*"),
+//		Dict:   dict.New(db),
+//		Region: region.Default(),
+//	})
+//	a.Resource("article", "文章", articleController.New())
+//	_, err := a.Mount(r, "/admin/api")
+func New(opts Options) *Admin {
+	return &Admin{opts: opts}
+}
+
+// Resource 注册CRUD资源，路由为 /{name}/list、detail、all（权限 name:view）、create（name:create）、
+// update、set-value（name:update）及 delete（name:delete），同名资源后注册的覆盖先注册的
+func (a *Admin) Resource(name, title string, ctrl Resource) *Admin {
+	for i, r := range a.resources {
+		if r.Name == name {
+			a.resources[i] = resource{Name: name, Title: title, controller: ctrl}
+			return a
+		}
+	}
+	a.resources = append(a.resources, resource{Name: name, Title: title, controller: ctrl})
+	return a
+}
+
+// Mount 将管理后台挂载到 path 路由分组，注册的接口：
+//   - GET /me    当前用户及其拥有的权限
+//   - GET /menu  当前用户有权查看的资源
+//   - /dict/*    字典管理，见 dict.Handler
+//   - GET /region/children 下级行政区划，参数 code（为空时返回省级）
+//   - GET /region/tree     行政区划树，参数 level
+//   - /{resource}/*        CRUD资源，见 Resource
+func (a *Admin) Mount(r gin.IRouter, path string) (*gin.RouterGroup, error) {
+	if a.opts.Auth == nil {
+		return nil, errors.New("admin: 未配置认证中间件")
+	}
+	g := r.Group(path, a.opts.Auth, requireUser)
+	g.Use(a.opts.Middlewares...)
+
+	g.GET("/me", a.me)
+	g.GET("/menu", a.menu)
+
+	if a.opts.Dict != nil {
+		h := a.opts.Dict.Handler()
+		dg := g.Group("/dict")
+		dg.GET("/list", a.require("dict:view"), h.List)
+		dg.GET("/groups", a.require("dict:view"), h.Groups)
+		dg.GET("/options", a.require("dict:view"), h.Options)
+		dg.POST("/save", a.require("dict:edit"), h.Save)
+		dg.POST("/delete", a.require("dict:edit"), h.Delete)
+	}
+
+	if a.opts.Region != nil {
+		rg := g.Group("/region")
+		rg.GET("/children", a.regionChildren)
+		rg.GET("/tree", a.regionTree)
+	}
+
+	for _, res := range a.resources {
+		rg := g.Group("/" + res.Name)
+		ctrl := res.controller
+		rg.GET("/list", a.require(res.Name+":view"), ctrl.ActionList)
+		rg.GET("/detail", a.require(res.Name+":view"), ctrl.ActionDetail)
+		rg.POST("/create", a.require(res.Name+":create"), ctrl.ActionCreate)
+		rg.POST("/update", a.require(res.Name+":update"), ctrl.ActionUpdate)
+		rg.POST("/delete", a.require(res.Name+":delete"), ctrl.ActionDelete)
+		if all, ok := ctrl.(interface{ ActionAll(c *gin.Context) }); ok {
+			rg.GET("/all", a.require(res.Name+":view"), all.ActionAll)
+		}
+		if setValue, ok := ctrl.(interface{ ActionSetValue(c *gin.Context) }); ok {
+			rg.POST("/set-value", a.require(res.Name+":update"), setValue.ActionSetValue)
+		}
+	}
+	return g, nil
+}
+
+// Can 当前用户是否拥有该权限，未配置 RBAC 时已登录即拥有全部权限
+func (a *Admin) Can(c *gin.Context, perm string) bool {
+	user, ok := GetUser(c)
+	if !ok {
+		return false
+	}
+	return a.opts.RBAC == nil || a.opts.RBAC.Can(user.Roles, perm)
+}
+
+// require 权限校验，未配置 RBAC 时不校验
+func (a *Admin) require(perm string) gin.HandlerFunc {
+	if a.opts.RBAC == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return a.opts.RBAC.Require(perm)
+}
+
+// requireUser 认证中间件未写入当前用户时拒绝访问
+func requireUser(c *gin.Context) {
+	if _, ok := GetUser(c); !ok {
+		controller.Base{GinContext: c}.Error(errcode.New(errcode.LoginInvalid, ""))
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+func (a *Admin) me(c *gin.Context) {
+	user, _ := GetUser(c)
+	perms := []string{"*"}
+	if a.opts.RBAC != nil {
+		perms = a.opts.RBAC.Permissions(user.Roles)
+	}
+	controller.Base{GinContext: c}.Success(map[string]any{
+		"user":        user,
+		"permissions": perms,
+	})
+}
+
+func (a *Admin) menu(c *gin.Context) {
+	list := make([]resource, 0, len(a.resources))
+	for _, res := range a.resources {
+		if a.Can(c, res.Name+":view") {
+			list = append(list, res)
+		}
+	}
+	controller.Base{GinContext: c}.Success(list)
+}
+
+func (a *Admin) regionChildren(c *gin.Context) {
+	code := c.Query("code")
+	if code == "" {
+		controller.Base{GinContext: c}.Success(a.opts.Region.Provinces())
+		return
+	}
+	if !a.opts.Region.Exists(code) {
+		controller.Base{GinContext: c}.Error(errcode.New(errcode.NotExist, "行政区划不存在"))
+		return
+	}
+	controller.Base{GinContext: c}.Success(a.opts.Region.Children(code))
+}
+
+func (a *Admin) regionTree(c *gin.Context) {
+	level, err := strconv.Atoi(c.DefaultQuery("level", "0"))
+	if err != nil || level < 0 || level > int(region.LevelDistrict) {
+		controller.Base{GinContext: c}.Failure("level 参数错误", nil, errcode.ParamError)
+		return
+	}
+	controller.Base{GinContext: c}.Success(a.opts.Region.Tree(region.Level(level)))
+}
@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RBAC 基于角色的权限控制，权限标识为 "资源:操作" 形式，如 user:view、user:delete；
+// 授予 "*" 表示全部权限，"user:*" 表示 user 的全部操作。可以并发使用
+//
+// 示例:
+//
+//	rbac := admin.NewRBAC().
+//		Grant("super", "*").
+//		Grant("editor", "article:*", "dict:view")
+//
+//	// 角色权限保存在数据库中时，加载后整体替换
+//	rbac.SetRoles(map[string][]string{"editor": {"article:*"}})
+type RBAC struct {
+	mu    sync.RWMutex
+	roles map[string]map[string]bool
+}
+
+// NewRBAC 创建权限控制
+func NewRBAC() *RBAC {
+	return &RBAC{roles: make(map[string]map[string]bool)}
+}
+
+// Grant 为角色授予权限
+func (r *RBAC) Grant(role string, perms ...string) *RBAC {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.roles[role] == nil {
+		r.roles[role] = make(map[string]bool)
+	}
+	for _, perm := range perms {
+		r.roles[role][perm] = true
+	}
+	return r
+}
+
+// Revoke 撤销角色的权限，不传 perms 时删除角色
+func (r *RBAC) Revoke(role string, perms ...string) *RBAC {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(perms) == 0 {
+		delete(r.roles, role)
+		return r
+	}
+	for _, perm := range perms {
+		delete(r.roles[role], perm)
+	}
+	return r
+}
+
+// SetRoles 使用 角色 => 权限列表 整体替换当前的配置
+func (r *RBAC) SetRoles(roles map[string][]string) {
+	m := make(map[string]map[string]bool, len(roles))
+	for role, perms := range roles {
+		m[role] = make(map[string]bool, len(perms))
+		for _, perm := range perms {
+			m[role][perm] = true
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles = m
+}
+
+// Can 角色列表中是否有任一角色拥有该权限
+func (r *RBAC) Can(roles []string, perm string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	resource, _, _ := strings.Cut(perm, ":")
+	for _, role := range roles {
+		granted := r.roles[role]
+		if granted["*"] || granted[perm] || granted[resource+":*"] {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions 角色列表拥有的全部权限（去重并排序），用于前端控制菜单、按钮的显示
+func (r *RBAC) Permissions(roles []string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set := make(map[string]bool)
+	for _, role := range roles {
+		for perm := range r.roles[role] {
+			set[perm] = true
+		}
+	}
+	perms := make([]string, 0, len(set))
+	for perm := range set {
+		perms = append(perms, perm)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// Require 权限校验中间件，需放在认证中间件之后；未登录返回 errcode.LoginInvalid，无权限返回 errcode.NoPermissionVisit
+func (r *RBAC) Require(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := GetUser(c)
+		if !ok {
+			controller.Base{GinContext: c}.Error(errcode.New(errcode.LoginInvalid, ""))
+			c.Abort()
+			return
+		}
+		if !r.Can(user.Roles, perm) {
+			controller.Base{GinContext: c}.Error(errcode.New(errcode.NoPermissionVisit, "").WithMeta("permission", perm))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
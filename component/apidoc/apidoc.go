@@ -0,0 +1,344 @@
+// Package apidoc 根据注册路由时附带的元数据生成 OpenAPI 3 文档
+//
+// 通过 Router 注册路由的同时记录接口说明、参数及返回结构，参数与返回结构由Go结构体反射生成，
+// 字段名取自 json/form 标签，说明取自 doc 标签或 gorm 标签的 comment，binding:"required" 视为必填。
+// 文档以 JSON 输出，并提供 Swagger UI 与 Redoc 页面（静态资源默认从 CDN 加载，可配置为内网地址）。
+package apidoc
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"html/template"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Options 文档配置
+type Options struct {
+	Title       string   `default:"API"`   // 文档标题
+	Version     string   `default:"1.0.0"` // 接口版本
+	Description string   // 文档说明，支持 Markdown
+	Servers     []string // 服务地址，如 https://api.example.com
+
+	SecuritySchemes map[string]any // 认证方式，如 {"token": {"type": "apiKey", "in": "header", "name": "Authorization"}}
+	Security        []string       // 默认对所有接口生效的认证方式名称
+
+	SwaggerUIURL string `default:"https://unpkg.com/swagger-ui-dist@5"`                              // Swagger UI 静态资源目录
+	RedocURL     string `default:"https://cdn.jsdelivr.net/npm/redoc@2/bundles/redoc.standalone.js"` // Redoc 脚本地址
+}
+
+// Operation 接口元数据
+type Operation struct {
+	Summary     string   // 接口名称
+	Description string   // 接口说明
+	Tags        []string // 分组
+	OperationID string   // 唯一标识，为空时由方法及路径生成
+
+	Query    any  // 查询参数结构体，字段名取自 form 标签
+	Body     any  // 请求体结构体（application/json），字段名取自 json 标签
+	Response any  // 返回的 data 结构，会包装在 {code, message, data} 中
+	Paged    bool // 是否为分页接口，返回中包含 total
+
+	Security   []string // 认证方式名称，不为空时覆盖 Options.Security
+	NoSecurity bool     // 是否为公开接口
+	Deprecated bool     // 是否已废弃
+}
+
+// route 已注册的接口
+type route struct {
+	method string
+	path   string
+	op     Operation
+}
+
+// Doc 接口文档
+//
+// 示例:
+//
+//	doc := apidoc.New(apidoc.Options{Title: "管理后台接口"})
+//	api := doc.Router(r.Group("/api"))
+//	api.GET("/user/detail", apidoc.Operation{
+//		Summary:  "用户详情",
+//		Tags:     []string{"用户"},
+//		Query:    UserDetailQuery{},
+//		Response: User{},
+//	}, userController.Detail)
+//	doc.RegisterRoutes(r.Group("/docs")) // /docs/openapi.json、/docs/swagger、/docs/redoc
+type Doc struct {
+	opt Options
+
+	mu     sync.RWMutex
+	routes []route
+	spec   []byte // 已生成的文档，注册新接口后清空
+}
+
+// New 创建接口文档
+func New(opts ...Options) *Doc {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	opt.SwaggerUIURL = strings.TrimSuffix(opt.SwaggerUIURL, "/")
+	return &Doc{opt: opt}
+}
+
+// Add 登记接口，path 使用 gin 的路由格式，如 /user/:id
+func (d *Doc) Add(method, path string, op Operation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.routes = append(d.routes, route{method: strings.ToUpper(method), path: path, op: op})
+	d.spec = nil
+}
+
+var pathParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Spec 生成 OpenAPI 3 文档
+func (d *Doc) Spec() map[string]any {
+	d.mu.RLock()
+	routes := append([]route(nil), d.routes...)
+	d.mu.RUnlock()
+
+	s := newSchemas()
+	paths := make(map[string]map[string]any)
+	tags := make([]string, 0)
+	tagSeen := make(map[string]bool)
+
+	for _, r := range routes {
+		openPath := pathParam.ReplaceAllString(r.path, "{$1}")
+		op := map[string]any{
+			"summary":     r.op.Summary,
+			"operationId": r.op.OperationID,
+			"responses":   map[string]any{"200": d.response(s, r.op)},
+		}
+		if op["operationId"] == "" {
+			op["operationId"] = operationID(r.method, r.path)
+		}
+		if r.op.Description != "" {
+			op["description"] = r.op.Description
+		}
+		if len(r.op.Tags) > 0 {
+			op["tags"] = r.op.Tags
+			for _, tag := range r.op.Tags {
+				if !tagSeen[tag] {
+					tagSeen[tag] = true
+					tags = append(tags, tag)
+				}
+			}
+		}
+		if r.op.Deprecated {
+			op["deprecated"] = true
+		}
+		if params := parameters(s, r.path, r.op.Query); len(params) > 0 {
+			op["parameters"] = params
+		}
+		if body := s.of(r.op.Body); body != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": body}},
+			}
+		}
+		if r.op.NoSecurity {
+			op["security"] = []any{}
+		} else if len(r.op.Security) > 0 {
+			op["security"] = security(r.op.Security)
+		}
+
+		if paths[openPath] == nil {
+			paths[openPath] = make(map[string]any)
+		}
+		paths[openPath][strings.ToLower(r.method)] = op
+	}
+
+	info := map[string]any{"title": d.opt.Title, "version": d.opt.Version}
+	if d.opt.Description != "" {
+		info["description"] = d.opt.Description
+	}
+	spec := map[string]any{
+		"openapi":    "3.0.3",
+		"info":       info,
+		"paths":      paths,
+		"components": map[string]any{"schemas": s.components},
+	}
+	if len(d.opt.Servers) > 0 {
+		servers := make([]map[string]string, 0, len(d.opt.Servers))
+		for _, url := range d.opt.Servers {
+			servers = append(servers, map[string]string{"url": url})
+		}
+		spec["servers"] = servers
+	}
+	if len(d.opt.SecuritySchemes) > 0 {
+		spec["components"].(map[string]any)["securitySchemes"] = d.opt.SecuritySchemes
+	}
+	if len(d.opt.Security) > 0 {
+		spec["security"] = security(d.opt.Security)
+	}
+	if len(tags) > 0 {
+		sort.Strings(tags)
+		list := make([]map[string]string, 0, len(tags))
+		for _, tag := range tags {
+			list = append(list, map[string]string{"name": tag})
+		}
+		spec["tags"] = list
+	}
+	return spec
+}
+
+// JSON 生成 JSON 格式的文档，结果会缓存至下次注册接口
+func (d *Doc) JSON() ([]byte, error) {
+	d.mu.RLock()
+	cached := d.spec
+	d.mu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	data, err := json.Marshal(d.Spec())
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	d.spec = data
+	d.mu.Unlock()
+	return data, nil
+}
+
+// response 生成统一格式的返回结构
+func (d *Doc) response(s *schemas, op Operation) map[string]any {
+	properties := map[string]any{
+		"code":    map[string]any{"type": "integer", "example": 200},
+		"message": map[string]any{"type": "string", "example": "success"},
+	}
+	if data := s.of(op.Response); data != nil {
+		properties["data"] = data
+	}
+	if op.Paged {
+		properties["total"] = map[string]any{"type": "integer", "description": "总条数"}
+	}
+	return map[string]any{
+		"description": "code 为 200 时表示成功，其他值为错误码",
+		"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{
+			"type":       "object",
+			"properties": properties,
+			"required":   []string{"code", "message"},
+		}}},
+	}
+}
+
+// parameters 生成路径参数及查询参数
+func parameters(s *schemas, path string, query any) []map[string]any {
+	params := make([]map[string]any, 0)
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		params = append(params, map[string]any{
+			"name": m[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	if query == nil {
+		return params
+	}
+	s.fields(typeOf(query), func(f field) {
+		param := map[string]any{"name": f.Name, "in": "query", "schema": s.schema(f.Type)}
+		if f.Required {
+			param["required"] = true
+		}
+		if f.Description != "" {
+			param["description"] = f.Description
+		}
+		if f.Example != "" {
+			param["example"] = f.Example
+		}
+		params = append(params, param)
+	}, "form")
+	return params
+}
+
+func security(names []string) []map[string][]string {
+	list := make([]map[string][]string, 0, len(names))
+	for _, name := range names {
+		list = append(list, map[string][]string{name: {}})
+	}
+	return list
+}
+
+// operationID 由方法及路径生成，如 GET /user/:id → get_user_id
+func operationID(method, path string) string {
+	id := strings.ToLower(method) + "_" + strings.Trim(invalidName.ReplaceAllString(path, "_"), "_")
+	return strings.NewReplacer("-", "_", ".", "_").Replace(id)
+}
+
+// ----- 文档页面 ----- /
+
+// RegisterRoutes 注册文档路由：
+//   - GET /openapi.json OpenAPI 3 文档
+//   - GET /swagger      Swagger UI 页面
+//   - GET /redoc        Redoc 页面
+func (d *Doc) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/openapi.json", d.SpecHandler)
+	r.GET("/swagger", d.SwaggerHandler)
+	r.GET("/redoc", d.RedocHandler)
+}
+
+// SpecHandler 输出 OpenAPI 3 文档
+func (d *Doc) SpecHandler(c *gin.Context) {
+	data, err := d.JSON()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+var swaggerTemplate = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.AssetURL}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.AssetURL}}/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: "{{.SpecURL}}", dom_id: "#swagger-ui", deepLinking: true, persistAuthorization: true});
+</script>
+</body>
+</html>`))
+
+var redocTemplate = template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>body { margin: 0; padding: 0; }</style>
+</head>
+<body>
+<redoc spec-url="{{.SpecURL}}"></redoc>
+<script src="{{.AssetURL}}"></script>
+</body>
+</html>`))
+
+// SwaggerHandler 输出 Swagger UI 页面，需与 SpecHandler 注册在同一目录下
+func (d *Doc) SwaggerHandler(c *gin.Context) {
+	d.page(c, swaggerTemplate, d.opt.SwaggerUIURL)
+}
+
+// RedocHandler 输出 Redoc 页面，需与 SpecHandler 注册在同一目录下
+func (d *Doc) RedocHandler(c *gin.Context) {
+	d.page(c, redocTemplate, d.opt.RedocURL)
+}
+
+func (d *Doc) page(c *gin.Context, tpl *template.Template, assetURL string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	_ = tpl.Execute(c.Writer, map[string]string{
+		"Title":    d.opt.Title,
+		"AssetURL": assetURL,
+		"SpecURL":  "openapi.json",
+	})
+}
@@ -0,0 +1,217 @@
+package apidoc
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/trait/crud"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Router 注册路由的同时登记接口文档
+type Router struct {
+	doc   *Doc
+	group *gin.RouterGroup
+	tags  []string
+}
+
+// Router 包装路由分组，tags 为分组内接口的默认分组
+func (d *Doc) Router(group *gin.RouterGroup, tags ...string) *Router {
+	return &Router{doc: d, group: group, tags: tags}
+}
+
+// Group 创建子分组，tags 不为空时替换默认分组
+func (r *Router) Group(relativePath string, tags []string, handlers ...gin.HandlerFunc) *Router {
+	if len(tags) == 0 {
+		tags = r.tags
+	}
+	return &Router{doc: r.doc, group: r.group.Group(relativePath, handlers...), tags: tags}
+}
+
+// Use 添加中间件
+func (r *Router) Use(middleware ...gin.HandlerFunc) *Router {
+	r.group.Use(middleware...)
+	return r
+}
+
+// Gin 获取原始的路由分组
+func (r *Router) Gin() *gin.RouterGroup {
+	return r.group
+}
+
+// Handle 注册路由并登记接口文档
+func (r *Router) Handle(method, relativePath string, op Operation, handlers ...gin.HandlerFunc) *Router {
+	r.group.Handle(method, relativePath, handlers...)
+	if len(op.Tags) == 0 {
+		op.Tags = r.tags
+	}
+	fullPath := path.Join(r.group.BasePath(), relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(fullPath, "/") {
+		fullPath += "/"
+	}
+	r.doc.Add(method, fullPath, op)
+	return r
+}
+
+// GET 注册 GET 路由
+func (r *Router) GET(relativePath string, op Operation, handlers ...gin.HandlerFunc) *Router {
+	return r.Handle(http.MethodGet, relativePath, op, handlers...)
+}
+
+// POST 注册 POST 路由
+func (r *Router) POST(relativePath string, op Operation, handlers ...gin.HandlerFunc) *Router {
+	return r.Handle(http.MethodPost, relativePath, op, handlers...)
+}
+
+// PUT 注册 PUT 路由
+func (r *Router) PUT(relativePath string, op Operation, handlers ...gin.HandlerFunc) *Router {
+	return r.Handle(http.MethodPut, relativePath, op, handlers...)
+}
+
+// PATCH 注册 PATCH 路由
+func (r *Router) PATCH(relativePath string, op Operation, handlers ...gin.HandlerFunc) *Router {
+	return r.Handle(http.MethodPatch, relativePath, op, handlers...)
+}
+
+// DELETE 注册 DELETE 路由
+func (r *Router) DELETE(relativePath string, op Operation, handlers ...gin.HandlerFunc) *Router {
+	return r.Handle(http.MethodDelete, relativePath, op, handlers...)
+}
+
+// ----- CRUD ----- /
+
+// CRUD 接口名称
+const (
+	ActionList     = "list"
+	ActionAll      = "all"
+	ActionDetail   = "detail"
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionSave     = "save"
+	ActionDelete   = "delete"
+	ActionSetValue = "set-value"
+)
+
+// CRUDOptions CRUD 路由配置
+type CRUDOptions struct {
+	Name      string   // 资源名称，用于接口名称，如 用户
+	Actions   []string // 注册的接口，默认全部
+	ListQuery any      // 列表接口的额外查询参数（ListQuery 中自定义的筛选条件）
+}
+
+// CRUD 在 relativePath 下注册 crud.Trait 的标准接口并登记文档，路径为 relativePath + "/" + 接口名称，
+// 参数与返回结构由 Trait 的 Model、ListResultStruct、DetailResultStruct 生成
+//
+// 示例:
+//
+//	user := userController.New()
+//	api.CRUD("/system/user", user.Trait, apidoc.CRUDOptions{Name: "用户", ListQuery: UserListQuery{}})
+func (r *Router) CRUD(relativePath string, t *crud.Trait, opt CRUDOptions) *Router {
+	if len(opt.Actions) == 0 {
+		opt.Actions = []string{ActionList, ActionAll, ActionDetail, ActionCreate, ActionUpdate, ActionSave, ActionDelete, ActionSetValue}
+	}
+	pk := t.PkId
+	if pk == "" {
+		pk = "id"
+	}
+	model := typeOf(t.Model)
+	if opt.Name == "" && model != nil {
+		opt.Name = model.Name()
+	}
+	listItem, detail := model, model
+	if t.ListResultStruct != nil {
+		listItem = typeOf(t.ListResultStruct)
+	}
+	if t.DetailResultStruct != nil {
+		detail = typeOf(t.DetailResultStruct)
+	}
+
+	sub := r.Group(relativePath, []string{opt.Name})
+	for _, action := range opt.Actions {
+		switch action {
+		case ActionList:
+			query := []reflect.StructField{
+				{Name: "Page", Type: reflect.TypeOf(0), Tag: `form:"page" doc:"页码，默认1"`},
+				{Name: "PageSize", Type: reflect.TypeOf(0), Tag: `form:"page_size" doc:"每页条数，默认10，最大1000"`},
+				{Name: "ShowDeleted", Type: reflect.TypeOf(0), Tag: `form:"show_deleted" doc:"是否包含已删除的数据"`},
+			}
+			if extra := typeOf(opt.ListQuery); extra != nil && extra.Kind() == reflect.Struct {
+				for i := 0; i < extra.NumField(); i++ {
+					if f := extra.Field(i); f.IsExported() && !f.Anonymous {
+						query = append(query, reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag})
+					}
+				}
+			}
+			sub.GET("/"+ActionList, Operation{
+				Summary:  opt.Name + "列表",
+				Query:    reflect.StructOf(query),
+				Response: sliceOf(listItem),
+				Paged:    true,
+			}, t.ActionList)
+		case ActionAll:
+			sub.GET("/"+ActionAll, Operation{
+				Summary:  "全部" + opt.Name,
+				Query:    reflect.StructOf([]reflect.StructField{{Name: "ShowDeleted", Type: reflect.TypeOf(0), Tag: `form:"show_deleted" doc:"是否包含已删除的数据"`}}),
+				Response: sliceOf(model),
+			}, t.ActionAll)
+		case ActionDetail:
+			sub.GET("/"+ActionDetail, Operation{
+				Summary:  opt.Name + "详情",
+				Query:    pkStruct(pk, false),
+				Response: detail,
+			}, t.ActionDetail)
+		case ActionCreate:
+			sub.POST("/"+ActionCreate, Operation{Summary: "新增" + opt.Name, Body: model}, t.ActionCreate)
+		case ActionUpdate:
+			sub.POST("/"+ActionUpdate, Operation{Summary: "修改" + opt.Name, Description: pk + " 必填", Body: model}, t.ActionUpdate)
+		case ActionSave:
+			sub.POST("/"+ActionSave, Operation{Summary: "保存" + opt.Name, Description: "传入 " + pk + " 时修改，否则新增", Body: model}, t.ActionSave)
+		case ActionDelete:
+			sub.POST("/"+ActionDelete, Operation{Summary: "删除" + opt.Name, Body: pkStruct(pk+"s", true)}, t.ActionDelete)
+		case ActionSetValue:
+			sub.POST("/"+ActionSetValue, Operation{
+				Summary: "修改" + opt.Name + "的单个字段",
+				Body: reflect.StructOf([]reflect.StructField{
+					{Name: "ID", Type: reflect.TypeOf(uint(0)), Tag: reflect.StructTag(`json:"` + pk + `" binding:"required"`)},
+					{Name: "Field", Type: reflect.TypeOf(""), Tag: `json:"field" binding:"required" doc:"字段名"`},
+					{Name: "Type", Type: reflect.TypeOf(""), Tag: `json:"type" binding:"required" doc:"字段类型"`},
+					{Name: "Value", Type: reflect.TypeOf((*any)(nil)).Elem(), Tag: `json:"value" binding:"required" doc:"字段值"`},
+				}),
+			}, t.ActionSetValue)
+		}
+	}
+	return r
+}
+
+// pkStruct 只包含主键的参数结构，plural 为 true 时为主键数组
+func pkStruct(name string, plural bool) reflect.Type {
+	typ := reflect.TypeOf(uint(0))
+	tag := `form:"` + name + `" json:"` + name + `" binding:"required"`
+	if plural {
+		typ = reflect.TypeOf([]uint{})
+	}
+	return reflect.StructOf([]reflect.StructField{{Name: "ID", Type: typ, Tag: reflect.StructTag(tag)}})
+}
+
+// typeOf 获取值的类型，去掉指针，v 为 reflect.Type 时直接返回
+func typeOf(v any) reflect.Type {
+	if v == nil {
+		return nil
+	}
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func sliceOf(t reflect.Type) any {
+	if t == nil {
+		return nil
+	}
+	return reflect.SliceOf(t)
+}
@@ -0,0 +1,202 @@
+package apidoc
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	invalidName   = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// schemas 由Go类型生成 JSON Schema，具名结构体放入 components.schemas 并通过 $ref 引用
+type schemas struct {
+	components map[string]map[string]any
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: make(map[string]map[string]any), names: make(map[reflect.Type]string)}
+}
+
+// of 生成值对应的 Schema，v 为 nil 时返回 nil
+func (s *schemas) of(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	if t, ok := v.(reflect.Type); ok {
+		return s.schema(t)
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "" && !t.Implements(marshalerType) && !reflect.PointerTo(t).Implements(marshalerType):
+		return map[string]any{"$ref": "#/components/schemas/" + s.component(t)}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+			// 自定义序列化的类型无法推断结构
+			return map[string]any{}
+		}
+		return s.object(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// component 注册具名结构体，返回组件名称
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := invalidName.ReplaceAllString(t.Name(), "_")
+	if _, exists := s.components[name]; exists {
+		name = invalidName.ReplaceAllString(t.String(), "_")
+	}
+	s.names[t] = name
+	s.components[name] = map[string]any{} // 占位，避免递归引用时重复生成
+	s.components[name] = s.object(t)
+	return name
+}
+
+// object 生成结构体的 Schema，匿名嵌入的结构体字段展开到当前层级
+func (s *schemas) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := make([]string, 0)
+	s.fields(t, func(f field) {
+		prop := s.schema(f.Type)
+		if f.Description != "" || f.Example != "" {
+			// $ref 的同级属性会被忽略，需要包装一层
+			if _, ok := prop["$ref"]; ok {
+				prop = map[string]any{"allOf": []any{prop}}
+			}
+			if f.Description != "" {
+				prop["description"] = f.Description
+			}
+			if f.Example != "" {
+				prop["example"] = f.Example
+			}
+		}
+		properties[f.Name] = prop
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}, "json")
+
+	obj := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	return obj
+}
+
+// field 结构体字段的文档信息
+type field struct {
+	Name        string
+	Type        reflect.Type
+	Required    bool
+	Description string
+	Example     string
+}
+
+// fields 遍历结构体的导出字段，tagName 为取字段名的标签（json 或 form）
+func (s *schemas) fields(t reflect.Type, fn func(f field), tagName string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(tagName)
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, fn, tagName)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fn(field{
+			Name:        name,
+			Type:        sf.Type,
+			Required:    isRequired(sf.Tag),
+			Description: description(sf.Tag),
+			Example:     sf.Tag.Get("example"),
+		})
+	}
+}
+
+// isRequired binding 或 validate 标签包含 required
+func isRequired(tag reflect.StructTag) bool {
+	for _, key := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(tag.Get(key), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// description 字段说明，优先使用 doc 标签，其次使用 gorm 标签的 comment
+func description(tag reflect.StructTag) string {
+	if doc := tag.Get("doc"); doc != "" {
+		return doc
+	}
+	for _, part := range strings.Split(tag.Get("gorm"), ";") {
+		if comment, ok := strings.CutPrefix(strings.TrimSpace(part), "comment:"); ok {
+			return comment
+		}
+	}
+	return ""
+}
@@ -0,0 +1,432 @@
+// Package archive zip、tar.gz 的压缩与解压
+//
+// 解压时会拒绝绝对路径、包含 .. 的路径及指向目标目录之外的软链接，防止路径穿越；
+// 可通过 Options.MaxBytes、MaxFiles 限制解压后的总大小及文件数，防止压缩炸弹。
+// 远程存储（attachment/remote）的接口以 []byte 传输，ToRemote、FromRemote 会在内存中缓冲整个压缩包。
+package archive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/attachment/remote"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 错误
+var (
+	ErrUnsafePath  = errors.New("archive: 不安全的路径")
+	ErrTooLarge    = errors.New("archive: 解压后的大小超过限制")
+	ErrTooMany     = errors.New("archive: 文件数量超过限制")
+	ErrUnsupported = errors.New("archive: 不支持的压缩格式")
+)
+
+// 压缩格式
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+// Progress 进度
+type Progress struct {
+	Name       string // 当前处理的文件（压缩包内路径）
+	Files      int    // 已处理的文件数
+	Bytes      int64  // 已处理的字节数（未压缩）
+	TotalFiles int    // 总文件数，未知时为0
+	TotalBytes int64  // 总字节数（未压缩），未知时为0
+}
+
+// Options 压缩及解压配置
+type Options struct {
+	Level    int                    // 压缩级别1-9，默认使用 flate 的默认级别
+	Exclude  []string               // 压缩时排除的文件，path.Match 语法，匹配相对路径或文件名
+	Prefix   string                 // 压缩时在压缩包内添加的顶层目录
+	Progress func(p Progress)       // 每处理完一个文件调用一次
+	Filter   func(name string) bool // 解压时返回 false 的文件被跳过，name 为压缩包内路径
+
+	Overwrite bool  // 解压时是否覆盖已存在的文件，为 false 时遇到已存在的文件返回错误
+	MaxBytes  int64 // 解压后的总大小上限，0表示不限制
+	MaxFiles  int   // 解压的文件数上限，0表示不限制
+}
+
+func getOptions(opts []Options) Options {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return Options{}
+}
+
+// Entry 压缩包中的一个文件，用于将非本地文件（如导出时生成的内容）直接写入压缩包
+type Entry struct {
+	Name    string                        // 压缩包内路径，使用 / 分隔
+	Open    func() (io.ReadCloser, error) // 打开内容，为 nil 时表示目录
+	Size    int64                         // 大小，仅用于进度统计
+	Mode    fs.FileMode                   // 权限，默认 0644（目录 0755）
+	ModTime time.Time                     // 修改时间，默认当前时间
+}
+
+// BytesEntry 内容为 data 的文件
+func BytesEntry(name string, data []byte) Entry {
+	return Entry{
+		Name: name,
+		Size: int64(len(data)),
+		Open: func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil },
+	}
+}
+
+// FormatOf 根据文件名判断压缩格式，无法识别时返回空字符串
+func FormatOf(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz
+	default:
+		return ""
+	}
+}
+
+// Compress 按 dst 的扩展名将 src（文件或目录）压缩为 zip 或 tar.gz 文件
+func Compress(dst, src string, opts ...Options) error {
+	switch FormatOf(dst) {
+	case FormatZip:
+		return ZipFile(dst, src, opts...)
+	case FormatTarGz:
+		return TarGzFile(dst, src, opts...)
+	default:
+		return ErrUnsupported
+	}
+}
+
+// Extract 按 src 的扩展名解压 zip 或 tar.gz 文件到 dst 目录
+func Extract(src, dst string, opts ...Options) error {
+	switch FormatOf(src) {
+	case FormatZip:
+		return UnzipFile(src, dst, opts...)
+	case FormatTarGz:
+		return UnTarGzFile(src, dst, opts...)
+	default:
+		return ErrUnsupported
+	}
+}
+
+// ToRemote 压缩后上传到远程存储，格式由 remotePath 的扩展名决定
+//
+// 示例:
+//
+//	client, _ := remote.NewClient(remote.TypeOSS, remote.OSSConfig(conf.OSS))
+//	err := archive.ToRemote(ctx, client, "export/orders-20240101.zip", []archive.Entry{
+//		archive.BytesEntry("orders.csv", csvData),
+//		archive.BytesEntry("readme.txt", []byte("...")),
+//	})
+func ToRemote(ctx context.Context, client remote.Client, remotePath string, entries []Entry, opts ...Options) error {
+	var buf bytes.Buffer
+	var err error
+	switch FormatOf(remotePath) {
+	case FormatZip:
+		err = ZipEntries(&buf, entries, opts...)
+	case FormatTarGz:
+		err = TarGzEntries(&buf, entries, opts...)
+	default:
+		err = ErrUnsupported
+	}
+	if err != nil {
+		return err
+	}
+	return client.Upload(ctx, remotePath, buf.Bytes())
+}
+
+// FromRemote 从远程存储下载压缩包并解压到 dst 目录，格式由 remotePath 的扩展名决定
+func FromRemote(ctx context.Context, client remote.Client, remotePath, dst string, opts ...Options) error {
+	format := FormatOf(remotePath)
+	if format == "" {
+		return ErrUnsupported
+	}
+	data, err := client.Download(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+	if format == FormatZip {
+		return Unzip(bytes.NewReader(data), int64(len(data)), dst, opts...)
+	}
+	return UnTarGz(bytes.NewReader(data), dst, opts...)
+}
+
+// ----- 内部方法 ----- /
+
+// collect 遍历本地文件生成压缩条目，src 为文件时只包含该文件；skip 为要跳过的文件（压缩包自身）
+func collect(src string, opt Options, skip string) ([]Entry, error) {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []Entry{fileEntry(src, path.Join(opt.Prefix, filepath.Base(src)), info)}, nil
+	}
+
+	entries := make([]Entry, 0)
+	err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if abs, _ := filepath.Abs(p); abs == skip && skip != "" {
+			return nil
+		}
+		if excluded(rel, opt.Exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0 {
+			return nil // 忽略设备文件、管道等
+		}
+		entries = append(entries, fileEntry(p, path.Join(opt.Prefix, rel), info))
+		return nil
+	})
+	return entries, err
+}
+
+// fileEntry 本地文件对应的压缩条目，软链接的内容为链接目标
+func fileEntry(p, name string, info fs.FileInfo) Entry {
+	entry := Entry{Name: name, Size: info.Size(), Mode: info.Mode(), ModTime: info.ModTime()}
+	switch {
+	case info.IsDir():
+	case info.Mode()&fs.ModeSymlink != 0:
+		entry.Open = func() (io.ReadCloser, error) {
+			link, err := os.Readlink(p)
+			return io.NopCloser(strings.NewReader(link)), err
+		}
+	default:
+		entry.Open = func() (io.ReadCloser, error) { return os.Open(p) }
+	}
+	return entry
+}
+
+// normalize 补全条目的默认值
+func (e Entry) normalize() Entry {
+	e.Name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(e.Name)), "/")
+	if e.Mode&fs.ModePerm == 0 {
+		if e.Open == nil {
+			e.Mode |= 0o755
+		} else {
+			e.Mode |= 0o644
+		}
+	}
+	if e.Open == nil {
+		e.Mode |= fs.ModeDir
+	}
+	if e.ModTime.IsZero() {
+		e.ModTime = time.Now()
+	}
+	return e
+}
+
+func excluded(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// safeJoin 将压缩包内路径拼接到目标目录，拒绝绝对路径及跳出目标目录的路径
+func safeJoin(dst, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+		}
+	}
+	return filepath.Join(dst, filepath.FromSlash(name)), nil
+}
+
+// extractor 解压时的公共逻辑：路径校验、数量及大小限制、进度
+type extractor struct {
+	dst      string
+	opt      Options
+	progress Progress
+}
+
+func newExtractor(dst string, opt Options) (*extractor, error) {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return nil, err
+	}
+	// 使用解析软链接后的真实路径，便于检查解压路径中的软链接
+	real, err := filepath.EvalSymlinks(dst)
+	if err == nil {
+		real, err = filepath.Abs(real)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &extractor{dst: real, opt: opt}, nil
+}
+
+// target 校验并返回条目的目标路径，返回空字符串表示跳过
+func (x *extractor) target(name string) (string, error) {
+	target, err := safeJoin(x.dst, name)
+	if err != nil || target == x.dst {
+		return "", err
+	}
+	if x.opt.Filter != nil && !x.opt.Filter(name) {
+		return "", nil
+	}
+	// 父目录中不能有指向目标目录之外的软链接，无法解析时按不安全处理
+	if parent, err := realDir(filepath.Dir(target)); err != nil || !within(x.dst, parent) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+	return target, nil
+}
+
+// realDir 解析目录路径中的软链接，目录尚未创建时解析已存在的上级目录后拼接其余部分
+func realDir(dir string) (string, error) {
+	rest := ""
+	for {
+		real, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(real, rest), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		// 路径本身存在却无法解析，说明是悬空的软链接
+		if _, lstatErr := os.Lstat(dir); lstatErr == nil {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", err
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+		dir = parent
+	}
+}
+
+// file 写入普通文件
+func (x *extractor) file(name, target string, mode fs.FileMode, r io.Reader) error {
+	if x.opt.MaxFiles > 0 && x.progress.Files >= x.opt.MaxFiles {
+		return ErrTooMany
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if !x.opt.Overwrite {
+		flag |= os.O_EXCL
+	} else if info, err := os.Lstat(target); err == nil && !info.Mode().IsRegular() {
+		if err = os.RemoveAll(target); err != nil {
+			return err
+		}
+	}
+	out, err := os.OpenFile(target, flag, mode.Perm()|0o200)
+	if err != nil {
+		return err
+	}
+
+	if x.opt.MaxBytes > 0 {
+		r = io.LimitReader(r, x.opt.MaxBytes-x.progress.Bytes+1)
+	}
+	n, err := io.Copy(out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	x.progress.Bytes += n
+	if err == nil && x.opt.MaxBytes > 0 && x.progress.Bytes > x.opt.MaxBytes {
+		err = ErrTooLarge
+	}
+	if err != nil {
+		_ = os.Remove(target)
+		return err
+	}
+	x.done(name)
+	return nil
+}
+
+// symlink 创建软链接，链接目标必须位于目标目录内
+//
+// 软链接不能创建在已解压的软链接之下，否则按字面路径校验的链接目标与实际指向不一致；
+// 链接目标清理后 .. 只会出现在开头，保证其只沿真实的上级目录回溯。
+func (x *extractor) symlink(name, target, link string) error {
+	parent, err := realDir(filepath.Dir(target))
+	if err != nil || parent != filepath.Dir(target) {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+	link = filepath.Clean(filepath.FromSlash(link))
+	if filepath.IsAbs(link) || !within(x.dst, filepath.Join(parent, link)) {
+		return fmt.Errorf("%w: %s -> %s", ErrUnsafePath, name, link)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(target); err == nil {
+		if !x.opt.Overwrite {
+			return fmt.Errorf("archive: 文件已存在: %s", name)
+		}
+		if err = os.RemoveAll(target); err != nil {
+			return err
+		}
+	}
+	if err := os.Symlink(link, target); err != nil {
+		return err
+	}
+	x.done(name)
+	return nil
+}
+
+func (x *extractor) done(name string) {
+	x.progress.Name = name
+	x.progress.Files++
+	if x.opt.Progress != nil {
+		x.opt.Progress(x.progress)
+	}
+}
+
+// within target 是否位于 dir 内（或与 dir 相同）
+func within(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// writeProgress 压缩时的进度
+func writeProgress(entries []Entry, opt Options) func(name string, n int64) {
+	p := Progress{}
+	for _, e := range entries {
+		if e.Open != nil {
+			p.TotalFiles++
+			p.TotalBytes += e.Size
+		}
+	}
+	return func(name string, n int64) {
+		p.Name = name
+		p.Files++
+		p.Bytes += n
+		if opt.Progress != nil {
+			opt.Progress(p)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testEntry 构造压缩包的条目，link 不为空时为软链接
+type testEntry struct {
+	name string
+	body string
+	link string
+}
+
+func buildZip(t *testing.T, entries ...testEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		body := e.body
+		if e.link != "" {
+			header.SetMode(fs.ModeSymlink | 0o777)
+			body = e.link
+		} else {
+			header.SetMode(0o644)
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTarGz(t *testing.T, entries ...testEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.link != "" {
+			header = &tar.Header{Name: e.name, Mode: 0o777, Linkname: e.link, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(e.body))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// extractBoth 分别以 zip、tar.gz 格式解压同样的条目
+func extractBoth(t *testing.T, opt Options, entries ...testEntry) map[string]error {
+	t.Helper()
+	results := make(map[string]error, 2)
+	zipData := buildZip(t, entries...)
+	results[FormatZip] = Unzip(bytes.NewReader(zipData), int64(len(zipData)), filepath.Join(t.TempDir(), "dst"), opt)
+	results[FormatTarGz] = UnTarGz(bytes.NewReader(buildTarGz(t, entries...)), filepath.Join(t.TempDir(), "dst"), opt)
+	return results
+}
+
+func TestExtractRejectsUnsafePaths(t *testing.T) {
+	cases := map[string][]testEntry{
+		"parent directory":      {{name: "../evil.txt", body: "x"}},
+		"nested parent":         {{name: "a/../../evil.txt", body: "x"}},
+		"backslash parent":      {{name: "a\\..\\..\\evil.txt", body: "x"}},
+		"absolute path":         {{name: "/tmp/evil.txt", body: "x"}},
+		"absolute symlink":      {{name: "link", link: "/etc"}},
+		"symlink outside":       {{name: "link", link: "../outside"}},
+		"write through symlink": {{name: "inner/x", body: "x"}, {name: "up", link: "inner/../.."}, {name: "up/evil.txt", body: "x"}},
+		"symlink in symlink":    {{name: "x", link: "."}, {name: "x/y", link: ".."}},
+		"dangling symlink":      {{name: "d", link: "missing"}, {name: "d/evil.txt", body: "x"}},
+	}
+	for name, entries := range cases {
+		for format, err := range extractBoth(t, Options{}, entries...) {
+			if !errors.Is(err, ErrUnsafePath) {
+				t.Errorf("%s (%s): err = %v, want ErrUnsafePath", name, format, err)
+			}
+		}
+	}
+}
+
+// 第二个软链接按字面路径位于目标目录内，实际创建在 dst/y 并指向 dst 的上级目录
+func TestExtractSymlinkThroughSymlink(t *testing.T) {
+	data := buildTarGz(t, testEntry{name: "x", link: "."}, testEntry{name: "x/y", link: ".."})
+	dst := t.TempDir()
+	if err := UnTarGz(bytes.NewReader(data), dst); !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("err = %v, want ErrUnsafePath", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dst, "y")); !os.IsNotExist(err) {
+		t.Fatalf("symlink escaping the destination was created: %v", err)
+	}
+}
+
+// 链接目标中软链接之后的 .. 按清理后的路径保存，不会沿软链接的目标回溯
+func TestExtractSymlinkTargetCleaned(t *testing.T) {
+	data := buildTarGz(t, testEntry{name: "sub/a.txt", body: "a"}, testEntry{name: "x", link: "sub"}, testEntry{name: "z", link: "x/.."})
+	dst := t.TempDir()
+	if err := UnTarGz(bytes.NewReader(data), dst); err != nil {
+		t.Fatal(err)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "z")); err != nil || link != "." {
+		t.Fatalf("link = %q, %v; want .", link, err)
+	}
+}
+
+func TestExtractSymlinkInside(t *testing.T) {
+	data := buildTarGz(t, testEntry{name: "dir/a.txt", body: "a"}, testEntry{name: "latest", link: "dir"})
+	dst := t.TempDir()
+	if err := UnTarGz(bytes.NewReader(data), dst); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dst, "latest", "a.txt"))
+	if err != nil || string(content) != "a" {
+		t.Fatalf("content = %q, %v", content, err)
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	entries := []testEntry{{name: "a.txt", body: "0123456789"}, {name: "b.txt", body: "0123456789"}}
+	for format, err := range extractBoth(t, Options{MaxBytes: 15}, entries...) {
+		if !errors.Is(err, ErrTooLarge) {
+			t.Errorf("MaxBytes (%s): err = %v, want ErrTooLarge", format, err)
+		}
+	}
+	for format, err := range extractBoth(t, Options{MaxFiles: 1}, entries...) {
+		if !errors.Is(err, ErrTooMany) {
+			t.Errorf("MaxFiles (%s): err = %v, want ErrTooMany", format, err)
+		}
+	}
+	for format, err := range extractBoth(t, Options{MaxBytes: 20, MaxFiles: 2}, entries...) {
+		if err != nil {
+			t.Errorf("within limits (%s): err = %v", format, err)
+		}
+	}
+}
+
+func TestExtractOverwrite(t *testing.T) {
+	data := buildZip(t, testEntry{name: "a.txt", body: "new"})
+	dst := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dst, "a.txt"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unzip(bytes.NewReader(data), int64(len(data)), dst); err == nil {
+		t.Fatal("existing file should not be overwritten by default")
+	}
+	if err := Unzip(bytes.NewReader(data), int64(len(data)), dst, Options{Overwrite: true}); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dst, "a.txt")); string(content) != "new" {
+		t.Fatalf("content = %q, want new", content)
+	}
+}
+
+func TestCompressExtractRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{"a.txt": "a", "sub/b.txt": "b", "sub/skip.log": "log"}
+	for name, body := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, ext := range []string{".zip", ".tar.gz"} {
+		out := filepath.Join(t.TempDir(), "backup"+ext)
+		var progress []Progress
+		if err := Compress(out, src, Options{Exclude: []string{"*.log"}, Prefix: "site"}); err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		dst := t.TempDir()
+		err := Extract(out, dst, Options{Progress: func(p Progress) { progress = append(progress, p) }})
+		if err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		for name, want := range map[string]string{"site/a.txt": "a", "site/sub/b.txt": "b"} {
+			if content, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name))); err != nil || string(content) != want {
+				t.Errorf("%s: %s = %q, %v", ext, name, content, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dst, "site", "sub", "skip.log")); !os.IsNotExist(err) {
+			t.Errorf("%s: excluded file was archived", ext)
+		}
+		if len(progress) != 2 || progress[1].Files != 2 || progress[1].Bytes != 2 {
+			t.Errorf("%s: progress = %+v", ext, progress)
+		}
+	}
+}
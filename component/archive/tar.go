@@ -0,0 +1,183 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// TarGz 将 src（文件或目录）打包压缩为 tar.gz 写入 w，目录下的文件以相对路径保存
+func TarGz(w io.Writer, src string, opts ...Options) error {
+	opt := getOptions(opts)
+	entries, err := collect(src, opt, "")
+	if err != nil {
+		return err
+	}
+	return TarGzEntries(w, entries, opt)
+}
+
+// TarGzFile 将 src（文件或目录）打包压缩为 tar.gz 文件 dst，失败时删除不完整的 dst
+func TarGzFile(dst, src string, opts ...Options) error {
+	opt := getOptions(opts)
+	return writeFile(dst, func(w io.Writer, skip string) error {
+		entries, err := collect(src, opt, skip)
+		if err != nil {
+			return err
+		}
+		return TarGzEntries(w, entries, opt)
+	})
+}
+
+// TarGzEntries 将条目打包压缩为 tar.gz 写入 w
+func TarGzEntries(w io.Writer, entries []Entry, opts ...Options) error {
+	opt := getOptions(opts)
+	level := gzip.DefaultCompression
+	if opt.Level != 0 {
+		level = opt.Level
+	}
+	gw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gw)
+
+	progress := writeProgress(entries, opt)
+	for _, entry := range entries {
+		entry = entry.normalize()
+		header := &tar.Header{
+			Name:    entry.Name,
+			Mode:    int64(entry.Mode.Perm()),
+			ModTime: entry.ModTime,
+			Format:  tar.FormatPAX,
+		}
+		switch {
+		case entry.Open == nil:
+			header.Typeflag, header.Name = tar.TypeDir, entry.Name+"/"
+			if err = tw.WriteHeader(header); err != nil {
+				return err
+			}
+			continue
+		case entry.Mode&os.ModeSymlink != 0:
+			rc, err := entry.Open()
+			if err != nil {
+				return err
+			}
+			link, err := io.ReadAll(rc)
+			_ = rc.Close()
+			if err != nil {
+				return err
+			}
+			header.Typeflag, header.Linkname = tar.TypeSymlink, string(link)
+			if err = tw.WriteHeader(header); err != nil {
+				return err
+			}
+			progress(entry.Name, 0)
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		size, r, err := sized(rc)
+		if err != nil {
+			_ = rc.Close()
+			return err
+		}
+		header.Size = size
+		if err = tw.WriteHeader(header); err != nil {
+			_ = rc.Close()
+			return err
+		}
+		n, err := io.Copy(tw, r)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+		progress(entry.Name, n)
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// UnTarGz 解压 tar.gz 到 dst 目录，流式读取，Progress 中的总数未知
+func UnTarGz(r io.Reader, dst string, opts ...Options) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = gr.Close()
+	}()
+	x, err := newExtractor(dst, getOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := x.target(header.Name)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o755)
+		case tar.TypeReg:
+			if x.opt.MaxBytes > 0 && x.progress.Bytes+header.Size > x.opt.MaxBytes {
+				return ErrTooLarge
+			}
+			err = x.file(header.Name, target, os.FileMode(header.Mode), tr)
+		case tar.TypeSymlink:
+			err = x.symlink(header.Name, target, header.Linkname)
+		case tar.TypeXGlobalHeader:
+		default: // 硬链接、设备文件等不解压
+			err = fmt.Errorf("%w: %s", ErrUnsafePath, header.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// UnTarGzFile 解压 tar.gz 文件 src 到 dst 目录
+func UnTarGzFile(src, dst string, opts ...Options) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return UnTarGz(f, dst, opts...)
+}
+
+// sized tar 需要预先写入大小：本地文件直接读取大小，其余内容先读取到内存
+func sized(rc io.ReadCloser) (int64, io.Reader, error) {
+	if f, ok := rc.(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return 0, nil, err
+		}
+		return info.Size(), io.LimitReader(f, info.Size()), nil
+	}
+	data, err := io.ReadAll(rc)
+	return int64(len(data)), bytes.NewReader(data), err
+}
@@ -0,0 +1,183 @@
+package archive
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Zip 将 src（文件或目录）压缩为 zip 写入 w，目录下的文件以相对路径保存
+func Zip(w io.Writer, src string, opts ...Options) error {
+	opt := getOptions(opts)
+	entries, err := collect(src, opt, "")
+	if err != nil {
+		return err
+	}
+	return ZipEntries(w, entries, opt)
+}
+
+// ZipFile 将 src（文件或目录）压缩为 zip 文件 dst，失败时删除不完整的 dst
+func ZipFile(dst, src string, opts ...Options) error {
+	opt := getOptions(opts)
+	return writeFile(dst, func(w io.Writer, skip string) error {
+		entries, err := collect(src, opt, skip)
+		if err != nil {
+			return err
+		}
+		return ZipEntries(w, entries, opt)
+	})
+}
+
+// ZipEntries 将条目压缩为 zip 写入 w
+func ZipEntries(w io.Writer, entries []Entry, opts ...Options) error {
+	opt := getOptions(opts)
+	zw := zip.NewWriter(w)
+	if opt.Level != 0 {
+		zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, opt.Level)
+		})
+	}
+
+	progress := writeProgress(entries, opt)
+	for _, entry := range entries {
+		entry = entry.normalize()
+		header := &zip.FileHeader{Name: entry.Name, Method: zip.Deflate, Modified: entry.ModTime}
+		header.SetMode(entry.Mode)
+		if entry.Open == nil {
+			header.Name += "/"
+			header.Method = zip.Store
+			if _, err := zw.CreateHeader(header); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		n, err := copyEntry(fw, entry)
+		if err != nil {
+			return err
+		}
+		progress(entry.Name, n)
+	}
+	return zw.Close()
+}
+
+// Unzip 解压 zip 到 dst 目录
+func Unzip(r io.ReaderAt, size int64, dst string, opts ...Options) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	x, err := newExtractor(dst, getOptions(opts))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if !f.Mode().IsDir() {
+			x.progress.TotalFiles++
+			x.progress.TotalBytes += int64(f.UncompressedSize64)
+		}
+	}
+	if x.opt.MaxFiles > 0 && x.progress.TotalFiles > x.opt.MaxFiles {
+		return ErrTooMany
+	}
+
+	for _, f := range zr.File {
+		target, err := x.target(f.Name)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			continue
+		}
+		if err = unzipFile(x, f, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnzipFile 解压 zip 文件 src 到 dst 目录
+func UnzipFile(src, dst string, opts ...Options) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return Unzip(f, info.Size(), dst, opts...)
+}
+
+func unzipFile(x *extractor, f *zip.File, target string) error {
+	mode := f.Mode()
+	if mode.IsDir() {
+		return os.MkdirAll(target, 0o755)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	if mode&fs.ModeSymlink != 0 {
+		link, err := io.ReadAll(io.LimitReader(rc, 4096))
+		if err != nil {
+			return err
+		}
+		return x.symlink(f.Name, target, string(link))
+	}
+	if !mode.IsRegular() {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, f.Name)
+	}
+	return x.file(f.Name, target, mode, rc)
+}
+
+// copyEntry 复制条目内容
+func copyEntry(w io.Writer, entry Entry) (int64, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	return io.Copy(w, rc)
+}
+
+// writeFile 创建文件 dst 并写入，失败时删除；skip 为 dst 的绝对路径，压缩目录包含 dst 时用于跳过自身
+func writeFile(dst string, write func(w io.Writer, skip string) error) (err error) {
+	if err = os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(dst)
+		}
+	}()
+	return write(f, abs)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jcbowen/jcbaseGo"
 	"github.com/jcbowen/jcbaseGo/component/helper"
+	imagehelper "github.com/jcbowen/jcbaseGo/component/helper/image"
 	"image"
 	_ "image/gif"  // 导入 GIF 支持
 	_ "image/jpeg" // 导入 JPEG 支持
@@ -41,6 +42,8 @@ type Attachment struct {
 	Width          int    // 图片宽
 	Height         int    // 图片高
 
+	ConvertedAttachment string // 转码后的图片相对路径，未转码时为空，原图已是目标格式时与 FileAttachment 相同
+
 	saveDir    string                   // 文件保存目录
 	errors     []error                  // 错误信息列表
 	beforeSave func(a *Attachment) bool // 保存前的回调函数，可选
@@ -50,10 +53,13 @@ type Attachment struct {
 type Options struct {
 	Group string // 附件组，默认不分组（分组会文件类型目录前多一级分组目录）
 
-	FileData interface{} // 文件数据，支持 base64 字符串、*multipart.FileHeader、[]byte
-	FileType string      // 文件类型，默认为 image
-	MaxSize  int64       // 最大文件大小
-	AllowExt []string    // 允许的文件扩展名
+	FileData interface{}     // 文件数据，支持 base64 字符串、*multipart.FileHeader、[]byte
+	FileType string          // 文件类型，默认为 image
+	MaxSize  int64           // 最大文件大小
+	AllowExt []string        // 允许的文件扩展名
+	Scanner  Scanner         // 安全扫描器，为空时使用 RegisterScanner 为附件组注册的扫描器
+	StripGPS bool            // 图片是否清除EXIF中的GPS位置及XMP元数据（保留方向等其他信息），MD5按清除后的内容计算
+	Convert  *ConvertOptions // 图片转码为 WebP/AVIF，为空时不转码
 }
 
 // typeInfo 附件类型信息
@@ -295,18 +301,35 @@ func (a *Attachment) Save() *Attachment {
 		// 处理 []byte 数据
 		srcFile = bytes.NewReader(v)
 		a.FileSize = int64(len(v))
-		// 需要确定文件扩展名
-		if len(a.Opt.AllowExt) == 1 {
+		// 需要确定文件扩展名，FetchRemote 已根据响应确定扩展名时不再处理
+		if a.FileExt == "" {
+			if len(a.Opt.AllowExt) != 1 {
+				a.addError(fmt.Errorf("无法确定文件扩展名，请在 Options 中指定 AllowExt"))
+				return a
+			}
 			a.FileExt = a.Opt.AllowExt[0]
-		} else {
-			a.addError(fmt.Errorf("无法确定文件扩展名，请在 Options 中指定 AllowExt"))
-			return a
 		}
 	default:
 		a.addError(fmt.Errorf("不支持的文件数据类型: %T", v))
 		return a
 	}
 
+	// 清除图片中的位置信息
+	if a.FileType == "image" && a.Opt.StripGPS {
+		if _, err = srcFile.Seek(0, io.SeekStart); err != nil {
+			a.addError(fmt.Errorf("无法重置文件指针: %v", err))
+			return a
+		}
+		data, err := io.ReadAll(srcFile)
+		if err != nil {
+			a.addError(fmt.Errorf("读取文件内容失败：%v", err))
+			return a
+		}
+		data = imagehelper.StripGPS(data)
+		srcFile = bytes.NewReader(data)
+		a.FileSize = int64(len(data))
+	}
+
 	// 计算文件 MD5
 	if _, err := srcFile.Seek(0, io.SeekStart); err != nil {
 		a.addError(fmt.Errorf("无法重置文件指针: %v", err))
@@ -345,6 +368,27 @@ func (a *Attachment) Save() *Attachment {
 		return a
 	}
 
+	// 安全扫描
+	scanner := a.Opt.Scanner
+	if scanner == nil {
+		scanner = getScanner(a.Opt.Group)
+	}
+	if scanner != nil {
+		data, err := io.ReadAll(srcFile)
+		if err != nil {
+			a.addError(fmt.Errorf("读取文件内容失败：%v", err))
+			return a
+		}
+		if err = scanner.ScanBefore(data); err != nil {
+			a.addError(err)
+			return a
+		}
+		if _, err = srcFile.Seek(0, io.SeekStart); err != nil {
+			a.addError(fmt.Errorf("无法重置文件指针: %v", err))
+			return a
+		}
+	}
+
 	// 如果是图片，应当获取宽高
 	if a.FileType == "image" {
 		img, _, err := image.Decode(srcFile)
@@ -362,6 +406,44 @@ func (a *Attachment) Save() *Attachment {
 			a.addError(fmt.Errorf("无法重置文件指针: %v", err))
 			return a
 		}
+		// EXIF方向为旋转90度或270度时，显示的宽高与像素宽高相反
+		if head, err := io.ReadAll(io.LimitReader(srcFile, 128*1024)); err == nil &&
+			imagehelper.Orientation(head) >= imagehelper.OrientationTranspose {
+			a.Width, a.Height = a.Height, a.Width
+		}
+		if _, err = srcFile.Seek(0, io.SeekStart); err != nil {
+			a.addError(fmt.Errorf("无法重置文件指针: %v", err))
+			return a
+		}
+	}
+
+	// 图片转码，不保留原图时以转码后的图片替换原图
+	var converted []byte
+	var convertedExt string
+	if a.FileType == "image" && a.Opt.Convert != nil && a.Opt.Convert.Format != "" {
+		data, err := io.ReadAll(srcFile)
+		if err != nil {
+			a.addError(fmt.Errorf("读取文件内容失败：%v", err))
+			return a
+		}
+		convertedExt, converted, err = a.convertImage(data)
+		if err != nil {
+			log.Println("图片转码失败，保存原图: ", err)
+		}
+		if !a.Opt.Convert.KeepOriginal {
+			if converted != nil && int64(len(converted)) < a.FileSize {
+				srcFile = bytes.NewReader(converted)
+				a.FileExt = convertedExt
+				a.FileSize = int64(len(converted))
+				sum := md5.Sum(converted)
+				a.FileMD5 = hex.EncodeToString(sum[:])
+			}
+			converted = nil
+		}
+		if _, err = srcFile.Seek(0, io.SeekStart); err != nil {
+			a.addError(fmt.Errorf("无法重置文件指针: %v", err))
+			return a
+		}
 	}
 
 	// 提前创建文件目录，避免后续操作报错
@@ -406,6 +488,20 @@ func (a *Attachment) Save() *Attachment {
 		a.FileAttachment = fullDstFilePath[index+len(a.BaseConfig.LocalDir+"/"):]
 	}
 
+	// 转码后的图片与原图同名，扩展名不同
+	if a.FileAttachment != "" && convertedExt != "" {
+		if a.FileExt == convertedExt {
+			a.ConvertedAttachment = a.FileAttachment
+		} else if converted != nil {
+			convertedPath := strings.TrimSuffix(fullDstFilePath, a.FileExt) + convertedExt
+			if err = os.WriteFile(convertedPath, converted, 0o644); err != nil {
+				log.Println("保存转码图片失败: ", err)
+			} else {
+				a.ConvertedAttachment = strings.TrimSuffix(a.FileAttachment, a.FileExt) + convertedExt
+			}
+		}
+	}
+
 	return a
 }
 
@@ -424,35 +520,42 @@ func (a *Attachment) parseBase64Data(base64Data string) ([]byte, error) {
 		return nil, err
 	}
 
-	// 自定义 MIME 类型到文件扩展名的映射表
-	var extMap = map[string]string{
-		"application/x-jpg": ".jpg",
-		"image/jpg":         ".jpg",
-		"image/jpeg":        ".jpg",
-		"image/png":         ".png",
-		"image/gif":         ".gif",
-		"video/mp4":         ".mp4",
-		"video/mpeg4":       ".mp4",
-		"video/x-ms-wmv":    ".wmv",
-		"audio/mpeg":        ".mp3",
-		"audio/mp4":         ".mp4",
-		"audio/x-ms-wma":    ".wma",
-	}
-
-	var ok bool
-	a.FileExt, ok = extMap[mediaType]
-	if !ok {
-		// 使用 MIME 类型解析库提供的扩展名作为后备选项
-		exts, err := mime.ExtensionsByType(mediaType)
-		if err != nil || len(exts) == 0 {
-			return nil, fmt.Errorf("无法获取 MIME 类型的扩展名: %s", mediaType)
-		}
-		a.FileExt = exts[0] // 使用 mime 提供的第一个扩展名
+	a.FileExt = extByMIME(mediaType)
+	if a.FileExt == "" {
+		return nil, fmt.Errorf("无法获取 MIME 类型的扩展名: %s", mediaType)
 	}
 
 	return decodedData, nil
 }
 
+// mimeExts 自定义 MIME 类型到文件扩展名的映射表
+var mimeExts = map[string]string{
+	"application/x-jpg": ".jpg",
+	"image/jpg":         ".jpg",
+	"image/jpeg":        ".jpg",
+	"image/png":         ".png",
+	"image/gif":         ".gif",
+	"video/mp4":         ".mp4",
+	"video/mpeg4":       ".mp4",
+	"video/x-ms-wmv":    ".wmv",
+	"audio/mpeg":        ".mp3",
+	"audio/mp4":         ".mp4",
+	"audio/x-ms-wma":    ".wma",
+}
+
+// extByMIME 根据 MIME 类型获取文件扩展名，无法识别时返回空字符串
+func extByMIME(mediaType string) string {
+	if ext, ok := mimeExts[mediaType]; ok {
+		return ext
+	}
+	// 使用 MIME 类型解析库提供的扩展名作为后备选项
+	exts, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(exts) == 0 {
+		return ""
+	}
+	return exts[0] // 使用 mime 提供的第一个扩展名
+}
+
 // fileRandomName 生成随机文件名，确保文件名在指定目录下是唯一的
 func (a *Attachment) fileRandomName(dir string) (string, string, error) {
 	var filename, fullDstFile string
@@ -0,0 +1,136 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConvertOptions 图片转码配置
+type ConvertOptions struct {
+	Format       string // 目标格式，webp 或 avif，需先通过 RegisterEncoder 注册对应的编码器
+	Quality      int    // 质量，1~100，默认80
+	KeepOriginal bool   // 是否保留原图，保留时 ConvertedAttachment 为转码后的图片，不保留时只保存转码后的图片（未变小时保存原图）
+}
+
+// ImageEncoder 图片编码器，将原图编码为目标格式
+type ImageEncoder interface {
+	Encode(src []byte, quality int) ([]byte, error)
+}
+
+// ImageEncoderFunc 函数形式的编码器
+type ImageEncoderFunc func(src []byte, quality int) ([]byte, error)
+
+func (f ImageEncoderFunc) Encode(src []byte, quality int) ([]byte, error) {
+	return f(src, quality)
+}
+
+// CommandEncoder 调用命令行工具编码，参数中的 {quality}、{input}、{output} 替换为质量、输入文件及输出文件路径
+type CommandEncoder struct {
+	Path    string        // 命令名称或路径
+	Args    []string      // 命令参数
+	Timeout time.Duration // 超时时间，默认30秒
+}
+
+// 常用的命令行编码器，需安装 libwebp（cwebp）、libavif（avifenc）
+var (
+	WebPCommand = &CommandEncoder{Path: "cwebp", Args: []string{"-quiet", "-metadata", "icc", "-q", "{quality}", "{input}", "-o", "{output}"}}
+	AVIFCommand = &CommandEncoder{Path: "avifenc", Args: []string{"-q", "{quality}", "{input}", "{output}"}}
+)
+
+func (e *CommandEncoder) Encode(src []byte, quality int) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "attachment-convert-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	input, output := filepath.Join(dir, "input"), filepath.Join(dir, "output")
+	if err = os.WriteFile(input, src, 0o600); err != nil {
+		return nil, err
+	}
+
+	replacer := strings.NewReplacer("{quality}", strconv.Itoa(quality), "{input}", input, "{output}", output)
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = replacer.Replace(arg)
+	}
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Path, args...)
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %v %s", e.Path, err, strings.TrimSpace(stderr.String()))
+	}
+	return os.ReadFile(output)
+}
+
+var (
+	encoderMu sync.RWMutex
+	encoders  = map[string]ImageEncoder{}
+)
+
+// RegisterEncoder 注册图片格式的编码器，encoder 为 nil 时取消注册
+//
+// 示例:
+//
+//	attachment.RegisterEncoder("webp", attachment.WebPCommand)
+//	a.Upload(&attachment.Options{FileData: file, Convert: &attachment.ConvertOptions{Format: "webp", Quality: 75, KeepOriginal: true}}).Save()
+//	original, webp := a.ToMedia(a.FileAttachment), a.ToMedia(a.ConvertedAttachment)
+func RegisterEncoder(format string, encoder ImageEncoder) {
+	encoderMu.Lock()
+	defer encoderMu.Unlock()
+	format = strings.ToLower(format)
+	if encoder == nil {
+		delete(encoders, format)
+		return
+	}
+	encoders[format] = encoder
+}
+
+// getEncoder 获取图片格式的编码器
+func getEncoder(format string) ImageEncoder {
+	encoderMu.RLock()
+	defer encoderMu.RUnlock()
+	return encoders[strings.ToLower(format)]
+}
+
+// convertImage 按配置转码图片，返回转码后的扩展名及内容；无需转码（已是目标格式、GIF动图）时返回空内容
+func (a *Attachment) convertImage(src []byte) (string, []byte, error) {
+	conf := a.Opt.Convert
+	format := strings.ToLower(strings.TrimPrefix(conf.Format, "."))
+	ext := "." + format
+	if a.FileExt == ext || a.FileExt == ".gif" {
+		return ext, nil, nil
+	}
+	encoder := getEncoder(format)
+	if encoder == nil {
+		return ext, nil, fmt.Errorf("未注册 %s 格式的编码器", format)
+	}
+	quality := conf.Quality
+	if quality <= 0 || quality > 100 {
+		quality = 80
+	}
+	data, err := encoder.Encode(src, quality)
+	if err != nil {
+		return ext, nil, err
+	}
+	if len(data) == 0 {
+		return ext, nil, fmt.Errorf("%s 编码结果为空", format)
+	}
+	return ext, data, nil
+}
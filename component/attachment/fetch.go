@@ -0,0 +1,197 @@
+package attachment
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// FetchOptions 远程文件下载配置
+type FetchOptions struct {
+	Timeout      time.Duration // 下载超时时间，默认30秒
+	MaxRedirects int           // 最多跟随的跳转次数，默认3，小于0时不跟随跳转
+	AllowMIME    []string      // 允许的 MIME 类型，支持 image/* 形式的通配，图片类型默认为 image/*
+	Header       http.Header   // 请求头，如 Referer、User-Agent
+	AllowPrivate bool          // 是否允许下载内网、本机地址，默认禁止，避免被用于访问内网服务
+	Client       *http.Client  // 自定义客户端，设置后忽略 Timeout、MaxRedirects、AllowPrivate
+}
+
+// errPrivateAddress 禁止访问的内网地址
+var errPrivateAddress = errors.New("不允许访问内网地址")
+
+// FetchRemote 下载远程文件并按 opt 保存，大小、扩展名、安全扫描等校验与上传一致；
+// 文件大小超过 opt.MaxSize 时在下载过程中中止，扩展名根据响应的 MIME 类型确定，无法确定时使用 URL 中的扩展名
+//
+// 示例:
+//
+//	a := attachment.New(c, &conf.Attachment).FetchRemote(mediaURL, &attachment.Options{FileType: "image", Group: "wechat"})
+//	if a.HasError() {
+//		return a.Error()
+//	}
+//	url := a.ToMedia(a.FileAttachment)
+func (a *Attachment) FetchRemote(rawURL string, opt *Options, fetchOpts ...FetchOptions) *Attachment {
+	a.Upload(opt)
+	if a.HasError() {
+		return a
+	}
+	var fetchOpt FetchOptions
+	if len(fetchOpts) > 0 {
+		fetchOpt = fetchOpts[0]
+	}
+	if len(fetchOpt.AllowMIME) == 0 && a.FileType == "image" {
+		fetchOpt.AllowMIME = []string{"image/*"}
+	}
+
+	data, mediaType, err := fetch(rawURL, a.Opt.MaxSize, fetchOpt)
+	if err != nil {
+		a.addError(err)
+		return a
+	}
+	if len(fetchOpt.AllowMIME) > 0 && !matchMIME(mediaType, fetchOpt.AllowMIME) {
+		a.addError(fmt.Errorf("不支持的文件类型【%s】", mediaType))
+		return a
+	}
+
+	a.FileExt = extByMIME(mediaType)
+	if a.FileExt == "" || mediaType == "application/octet-stream" {
+		if u, err := url.Parse(rawURL); err == nil && path.Ext(u.Path) != "" {
+			a.FileExt = strings.ToLower(path.Ext(u.Path))
+		}
+	}
+	if a.FileExt == "" {
+		a.addError(fmt.Errorf("无法确定文件类型: %s", mediaType))
+		return a
+	}
+
+	a.Opt.FileData = data
+	return a.Save()
+}
+
+// fetch 下载文件，返回内容及 MIME 类型，maxSize 大于0时限制下载大小
+func fetch(rawURL string, maxSize int64, opt FetchOptions) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, "", fmt.Errorf("无效的文件地址: %s", rawURL)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("无效的文件地址: %v", err)
+	}
+	for key, values := range opt.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := fetchClient(opt).Do(req)
+	if err != nil {
+		if errors.Is(err, errPrivateAddress) {
+			return nil, "", errPrivateAddress
+		}
+		return nil, "", fmt.Errorf("下载文件失败: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("下载文件失败: HTTP %d", resp.StatusCode)
+	}
+
+	tooLarge := fmt.Errorf("文件大小不能超出[%s]", (&Attachment{}).formatFileSize(maxSize))
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return nil, "", tooLarge
+	}
+	reader := io.Reader(resp.Body)
+	if maxSize > 0 {
+		reader = io.LimitReader(resp.Body, maxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("下载文件失败: %v", err)
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return nil, "", tooLarge
+	}
+	if len(data) == 0 {
+		return nil, "", fmt.Errorf("下载文件失败: 文件为空")
+	}
+
+	// 响应未声明类型或为通用二进制类型时根据内容识别
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		if sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data)); sniffed != "" {
+			mediaType = sniffed
+		}
+	}
+	return data, strings.ToLower(mediaType), nil
+}
+
+// fetchClient 下载使用的客户端，限制超时、跳转次数，并在建立连接时检查解析后的地址，跳转及DNS重绑定同样受限
+func fetchClient(opt FetchOptions) *http.Client {
+	if opt.Client != nil {
+		return opt.Client
+	}
+	timeout := opt.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	maxRedirects := opt.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = 3
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !opt.AllowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // 经代理访问时无法检查目标地址
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("跳转次数超过%d次", max(maxRedirects, 0))
+			}
+			return nil
+		},
+	}
+}
+
+// isPrivateIP 是否为内网、本机、链路本地等不允许访问的地址
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// matchMIME MIME 类型是否在允许列表中，支持 image/* 形式的通配
+func matchMIME(mediaType string, allow []string) bool {
+	for _, pattern := range allow {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == mediaType || pattern == "*/*" ||
+			(strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package attachment
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AllGroups 注册扫描器时表示对所有未单独注册的附件组生效
+const AllGroups = "*"
+
+// Scanner 上传文件的安全扫描器，在文件写入存储前调用，返回错误时拒绝保存
+type Scanner interface {
+	ScanBefore(data []byte) error
+}
+
+// ScannerFunc 函数形式的扫描器
+type ScannerFunc func(data []byte) error
+
+func (f ScannerFunc) ScanBefore(data []byte) error {
+	return f(data)
+}
+
+// VirusError 检测到病毒或恶意文件
+type VirusError struct {
+	Signature string // 病毒特征名称
+}
+
+func (e *VirusError) Error() string {
+	return fmt.Sprintf("文件包含病毒或恶意代码（%s）", e.Signature)
+}
+
+var (
+	scannerMu sync.RWMutex
+	scanners  = map[string]Scanner{}
+)
+
+// RegisterScanner 为附件组注册扫描器，group 为 AllGroups 时对所有未单独注册的组生效，scanner 为 nil 时取消注册
+// Options.Scanner 不为空时优先使用 Options.Scanner
+//
+// 示例:
+//
+//	clam := &attachment.ClamAV{Network: "unix", Address: "/var/run/clamav/clamd.ctl"}
+//	attachment.RegisterScanner(attachment.AllGroups, clam)
+//	attachment.RegisterScanner("avatar", attachment.NopScanner) // 头像组不扫描
+func RegisterScanner(group string, scanner Scanner) {
+	scannerMu.Lock()
+	defer scannerMu.Unlock()
+	if scanner == nil {
+		delete(scanners, group)
+		return
+	}
+	scanners[group] = scanner
+}
+
+// NopScanner 不做任何检查的扫描器，用于让个别附件组跳过 AllGroups 的扫描器
+var NopScanner Scanner = ScannerFunc(func([]byte) error { return nil })
+
+// getScanner 获取附件组的扫描器
+func getScanner(group string) Scanner {
+	scannerMu.RLock()
+	defer scannerMu.RUnlock()
+	if s, ok := scanners[group]; ok {
+		return s
+	}
+	return scanners[AllGroups]
+}
+
+// ClamAV 通过 clamd 的 INSTREAM 命令扫描文件
+type ClamAV struct {
+	Network   string        // 连接方式，unix 或 tcp，默认 tcp
+	Address   string        // clamd 地址，如 /var/run/clamav/clamd.ctl 或 127.0.0.1:3310
+	Timeout   time.Duration // 单次扫描超时，默认30秒
+	ChunkSize int           // 每次发送的数据块大小，默认64KB
+	FailOpen  bool          // clamd 不可用时是否放行，默认拒绝上传
+}
+
+// ScanBefore 扫描文件数据，发现病毒时返回 *VirusError
+func (c *ClamAV) ScanBefore(data []byte) error {
+	reply, err := c.instream(data)
+	if err != nil {
+		if c.FailOpen {
+			log.Println("clamd 扫描失败，已放行: ", err)
+			return nil
+		}
+		return fmt.Errorf("文件安全扫描失败: %v", err)
+	}
+
+	// 响应格式：stream: OK、stream: <signature> FOUND、<message> ERROR
+	reply = strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case reply == "OK":
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &VirusError{Signature: strings.TrimSuffix(reply, " FOUND")}
+	default:
+		if c.FailOpen {
+			log.Println("clamd 扫描失败，已放行: ", reply)
+			return nil
+		}
+		return fmt.Errorf("文件安全扫描失败: %s", reply)
+	}
+}
+
+// Ping 检查 clamd 是否可用
+func (c *ClamAV) Ping() error {
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte("zPING\x00")); err != nil {
+		return err
+	}
+	reply, err := readReply(conn)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("clamd 响应异常: %s", reply)
+	}
+	return nil
+}
+
+// instream 发送数据并读取扫描结果
+func (c *ClamAV) instream(data []byte) (string, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	w := bufio.NewWriter(conn)
+	if _, err = w.WriteString("zINSTREAM\x00"); err != nil {
+		return "", err
+	}
+	chunkSize := c.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 64 * 1024
+	}
+	size := make([]byte, 4)
+	for len(data) > 0 {
+		n := min(chunkSize, len(data))
+		binary.BigEndian.PutUint32(size, uint32(n))
+		if _, err = w.Write(size); err != nil {
+			return "", err
+		}
+		if _, err = w.Write(data[:n]); err != nil {
+			return "", err
+		}
+		data = data[n:]
+	}
+	// 长度为0的数据块表示结束
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err = w.Write(size); err != nil {
+		return "", err
+	}
+	if err = w.Flush(); err != nil {
+		return "", err
+	}
+	return readReply(conn)
+}
+
+func (c *ClamAV) dial() (net.Conn, error) {
+	if c.Address == "" {
+		return nil, errors.New("未配置 clamd 地址")
+	}
+	network := c.Network
+	if network == "" {
+		network = "tcp"
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	conn, err := net.DialTimeout(network, c.Address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	return conn, nil
+}
+
+// readReply 读取以 \0 结尾的响应
+func readReply(r io.Reader) (string, error) {
+	reply, err := bufio.NewReader(r).ReadBytes(0)
+	if err != nil && !(errors.Is(err, io.EOF) && len(reply) > 0) {
+		return "", err
+	}
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
@@ -0,0 +1,200 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Cmd 单次命令执行的配置，每次调用单独指定工作目录、环境变量等，可在多个goroutine中并发使用
+type Cmd struct {
+	Name     string              // 命令名称或路径
+	Args     []string            // 命令参数
+	Dir      string              // 工作目录，为空时为当前进程的工作目录
+	Env      []string            // 额外的环境变量，格式为 KEY=VALUE，同名时覆盖继承的变量
+	ClearEnv bool                // 不继承当前进程的环境变量，只使用Env
+	Timeout  time.Duration       // 超时时间，超时后进程会被杀死，小于等于0表示不限制（仍受ctx控制）
+	Stdin    io.Reader           // 标准输入
+	OnStdout func(line string)   // 逐行回调标准输出，适用于长时间运行的命令；设置后结果中不再保存标准输出，除非 KeepOutput
+	OnStderr func(line string)   // 逐行回调标准错误，同 OnStdout
+	Prepare  func(cmd *exec.Cmd) // 启动前对 exec.Cmd 做额外设置，如 SysProcAttr
+
+	KeepOutput bool // 设置了逐行回调时仍在结果中保存对应的输出
+	MaxOutput  int  // 结果中 Stdout、Stderr、Output 各自保存的最大字节数，超出部分丢弃并设置 Result.Truncated；默认10MB，小于0时不限制
+}
+
+// 输出的限制
+const (
+	defaultMaxOutput = 10 << 20 // 结果中保存的输出默认最大字节数
+	maxLineLength    = 64 << 10 // 逐行回调时单行的最大字节数，超出时按该长度分段回调
+)
+
+// Result 命令执行结果
+type Result struct {
+	Stdout    string        `json:"stdout"`
+	Stderr    string        `json:"stderr"`
+	Output    string        `json:"output"` // 按输出顺序混合的stdout+stderr
+	ExitCode  int           `json:"exit_code"`
+	Duration  time.Duration `json:"duration"`
+	TimedOut  bool          `json:"timed_out"`
+	Truncated bool          `json:"truncated"` // 输出超过 MaxOutput 被截断
+}
+
+// RunContext 在ctx控制下执行命令，stdout与stderr分开捕获
+//
+// 命令以非0状态码退出时返回结果及 *exec.ExitError；需要设置工作目录、环境变量、超时或流式输出时使用 Cmd
+//
+// 示例:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	res, err := command.RunContext(ctx, "git", "status")
+//
+//	res, err = (&command.Cmd{
+//	    Name:     "ffmpeg",
+//	    Args:     []string{"-i", "in.mp4", "out.webm"},
+//	    Dir:      "/data/video",
+//	    Timeout:  10 * time.Minute,
+//	    OnStderr: func(line string) { log.Println(line) },
+//	}).Run(ctx)
+func RunContext(ctx context.Context, name string, args ...string) (*Result, error) {
+	return (&Cmd{Name: name, Args: args}).Run(ctx)
+}
+
+// Run 执行命令，始终返回非nil的结果
+func (c *Cmd) Run(ctx context.Context) (*Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	if c.ClearEnv {
+		cmd.Env = append([]string{}, c.Env...)
+	} else if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdin = c.Stdin
+	// 进程被杀死后，子进程可能仍占用输出管道，最多再等待1秒
+	cmd.WaitDelay = time.Second
+
+	maxOutput := c.MaxOutput
+	if maxOutput == 0 {
+		maxOutput = defaultMaxOutput
+	}
+	var mu sync.Mutex
+	stdout, stderr := &limitedBuffer{max: maxOutput}, &limitedBuffer{max: maxOutput}
+	combined := &limitedBuffer{max: maxOutput}
+	outWriter := &lineWriter{mu: &mu, buf: stdout, combined: combined, onLine: c.OnStdout}
+	errWriter := &lineWriter{mu: &mu, buf: stderr, combined: combined, onLine: c.OnStderr}
+	// 流式输出时默认不保存，避免长时间运行的命令占用内存
+	if c.OnStdout != nil && !c.KeepOutput {
+		outWriter.buf = nil
+	}
+	if c.OnStderr != nil && !c.KeepOutput {
+		errWriter.buf = nil
+	}
+	cmd.Stdout = outWriter
+	cmd.Stderr = errWriter
+	if c.Prepare != nil {
+		c.Prepare(cmd)
+	}
+
+	start := time.Now()
+	err := cmd.Run()
+	outWriter.flush()
+	errWriter.flush()
+
+	result := &Result{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Output:    combined.String(),
+		ExitCode:  -1,
+		Duration:  time.Since(start),
+		Truncated: stdout.truncated || stderr.truncated || combined.truncated,
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		result.TimedOut = errors.Is(ctxErr, context.DeadlineExceeded)
+		return result, fmt.Errorf("command: %s 执行中止: %w", c.Name, ctxErr)
+	}
+	return result, err
+}
+
+// limitedBuffer 超过最大字节数后丢弃写入的缓冲，max小于0时不限制
+type limitedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.max >= 0 && b.Len()+n > b.max {
+		b.truncated = true
+		p = p[:max(b.max-b.Len(), 0)]
+	}
+	_, _ = b.Buffer.Write(p)
+	return n, nil
+}
+
+// lineWriter 写入缓冲（buf为nil时不保存），并按行回调
+type lineWriter struct {
+	mu       *sync.Mutex
+	buf      *limitedBuffer
+	combined *limitedBuffer
+	onLine   func(line string)
+	pending  []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	if w.buf != nil {
+		w.mu.Lock()
+		w.buf.Write(p)
+		w.combined.Write(p)
+		w.mu.Unlock()
+	}
+
+	if w.onLine == nil {
+		return len(p), nil
+	}
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.onLine(string(bytes.TrimSuffix(w.pending[:i], []byte("\r"))))
+		w.pending = w.pending[i+1:]
+	}
+	// 没有换行符的输出（如进度条）超过单行最大长度时分段回调，避免无限增长
+	for len(w.pending) >= maxLineLength {
+		w.onLine(string(w.pending[:maxLineLength]))
+		w.pending = w.pending[maxLineLength:]
+	}
+	if len(w.pending) == 0 {
+		w.pending = nil
+	}
+	return len(p), nil
+}
+
+// flush 回调最后一行没有换行符的输出
+func (w *lineWriter) flush() {
+	if w.onLine != nil && len(w.pending) > 0 {
+		w.onLine(string(w.pending))
+		w.pending = nil
+	}
+}
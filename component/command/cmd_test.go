@@ -0,0 +1,72 @@
+package command
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCmdRunCapturesOutput(t *testing.T) {
+	res, err := (&Cmd{Name: "sh", Args: []string{"-c", "echo out; echo err >&2; exit 3"}}).Run(context.Background())
+	if err == nil {
+		t.Fatal("non-zero exit should return an error")
+	}
+	if res.Stdout != "out\n" || res.Stderr != "err\n" || res.ExitCode != 3 || res.Truncated {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if !strings.Contains(res.Output, "out\n") || !strings.Contains(res.Output, "err\n") {
+		t.Fatalf("combined output = %q", res.Output)
+	}
+}
+
+func TestCmdRunStreamingSkipsBuffering(t *testing.T) {
+	var mu sync.Mutex
+	var lines []string
+	onLine := func(line string) {
+		mu.Lock()
+		lines = append(lines, line)
+		mu.Unlock()
+	}
+	res, err := (&Cmd{Name: "sh", Args: []string{"-c", "echo a; echo b; printf c"}, OnStdout: onLine}).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(lines, ",") != "a,b,c" {
+		t.Fatalf("lines = %q", lines)
+	}
+	if res.Stdout != "" || res.Output != "" {
+		t.Fatalf("streamed output should not be buffered: %+v", res)
+	}
+
+	lines = nil
+	res, err = (&Cmd{Name: "sh", Args: []string{"-c", "echo a"}, OnStdout: onLine, KeepOutput: true}).Run(context.Background())
+	if err != nil || res.Stdout != "a\n" || len(lines) != 1 {
+		t.Fatalf("KeepOutput: res = %+v, lines = %q, err = %v", res, lines, err)
+	}
+}
+
+func TestCmdRunMaxOutput(t *testing.T) {
+	res, err := (&Cmd{Name: "sh", Args: []string{"-c", "printf 0123456789"}, MaxOutput: 4}).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stdout != "0123" || res.Output != "0123" || !res.Truncated {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestLineWriterSplitsLongLines(t *testing.T) {
+	var lines []string
+	w := &lineWriter{onLine: func(line string) { lines = append(lines, line) }}
+	long := strings.Repeat("x", maxLineLength*2+10)
+	_, _ = w.Write([]byte(long))
+	if len(lines) != 2 || len(w.pending) != 10 {
+		t.Fatalf("lines = %d, pending = %d; want 2 full lines and 10 pending bytes", len(lines), len(w.pending))
+	}
+	_, _ = w.Write([]byte("y\n"))
+	w.flush()
+	if len(lines) != 3 || lines[2] != strings.Repeat("x", 10)+"y" {
+		t.Fatalf("last line = %q", lines[len(lines)-1])
+	}
+}
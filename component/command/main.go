@@ -1,22 +1,22 @@
 package command
 
 import (
-	"os/exec"
+	"context"
 )
 
 // CmdPath 命令运行路径（绝对/相对路径）
+//
+// Deprecated: CmdPath 为全局变量，多个goroutine同时切换目录时会相互影响，请使用 Cmd.Dir 为每次调用单独指定工作目录
 var CmdPath string
 
 // Run 执行cmd命令的封装
+//
+// 返回混合的stdout+stderr输出，需要超时控制、分开捕获输出或单独指定工作目录时使用 RunContext / Cmd
 func Run(name string, arg ...string) (string, error) {
 	if name == "cd" {
 		CmdPath = arg[0]
 		return "", nil
 	}
-	cmd := exec.Command(name, arg...)
-	if len(CmdPath) > 0 {
-		cmd.Dir = CmdPath
-	}
-	out, err := cmd.CombinedOutput() // 混合输出stdout+stderr
-	return string(out), err
+	res, err := (&Cmd{Name: name, Args: arg, Dir: CmdPath}).Run(context.Background())
+	return res.Output, err
 }
@@ -0,0 +1,419 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 重启策略
+const (
+	RestartAlways    = "always"     // 退出后总是重启
+	RestartOnFailure = "on-failure" // 非0状态码退出或健康检查失败时重启
+	RestartNever     = "never"      // 不重启
+)
+
+// 进程状态
+const (
+	StateStarting = "starting" // 启动中
+	StateRunning  = "running"  // 运行中
+	StateBackoff  = "backoff"  // 等待重启
+	StateExited   = "exited"   // 已退出，按重启策略不再重启
+	StateStopped  = "stopped"  // 已手动停止
+	StateFatal    = "fatal"    // 连续重启次数超过上限，不再重启
+)
+
+// ErrProcessExists 同名进程已存在
+var ErrProcessExists = errors.New("command: process already exists")
+
+// ErrProcessNotFound 进程不存在
+var ErrProcessNotFound = errors.New("command: process not found")
+
+// Process 受监管的进程配置
+type Process struct {
+	Name string // 进程名称，在同一个Supervisor中唯一
+	Cmd  Cmd    // 启动命令，Cmd.Timeout 与 Cmd.Stdin 不生效，输出只通过 OnStdout/OnStderr 回调
+
+	Restart        string                          `default:"on-failure"` // 重启策略 always/on-failure/never
+	MaxRestarts    int                             // 连续重启次数上限，超过后进入fatal状态，0表示不限制
+	BackoffMin     time.Duration                   // 首次重启前的等待时间，默认1秒，之后每次翻倍
+	BackoffMax     time.Duration                   // 重启等待时间上限，默认1分钟
+	StableAfter    time.Duration                   // 运行超过该时间视为稳定，重置退避时间与连续重启次数，默认10秒
+	StopTimeout    time.Duration                   // 停止时先发送中断信号，超时后强制杀死，默认10秒
+	Health         func(ctx context.Context) error // 健康检查，为nil时不检查
+	HealthInterval time.Duration                   // 健康检查间隔，默认10秒
+	HealthTimeout  time.Duration                   // 单次健康检查超时时间，默认5秒
+	HealthFailures int                             `default:"3"` // 连续失败多少次后重启进程
+}
+
+// ProcessStatus 进程状态
+type ProcessStatus struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	PID       int       `json:"pid"`
+	Restarts  int       `json:"restarts"`   // 累计重启次数
+	Healthy   bool      `json:"healthy"`    // 最近一次健康检查是否通过，未配置健康检查时与是否运行一致
+	StartedAt time.Time `json:"started_at"` // 最近一次启动时间
+	ExitedAt  time.Time `json:"exited_at"`  // 最近一次退出时间
+	ExitCode  int       `json:"exit_code"`  // 最近一次退出的状态码
+	LastError string    `json:"last_error"` // 最近一次错误
+}
+
+// Supervisor 进程监管，负责启动子进程，并在退出或健康检查失败时按策略重启
+//
+// 示例:
+//
+//	sup := command.NewSupervisor()
+//	sup.OnEvent = func(status command.ProcessStatus) {
+//	    log.Printf("%s %s pid=%d err=%s", status.Name, status.State, status.PID, status.LastError)
+//	}
+//	_ = sup.Add(command.Process{
+//	    Name:    "php-worker",
+//	    Cmd:     command.Cmd{Name: "php", Args: []string{"worker.php"}, Dir: "./runtime"},
+//	    Restart: command.RestartAlways,
+//	    Health:  command.HTTPProbe("http://127.0.0.1:9000/health"),
+//	})
+//	defer sup.StopAll()
+type Supervisor struct {
+	OnEvent func(status ProcessStatus) // 进程状态变化时回调，可用于记录日志或上报调试器
+
+	mu    sync.Mutex
+	procs map[string]*supervised
+}
+
+// supervised 受监管的单个进程
+type supervised struct {
+	sup    *Supervisor
+	conf   Process
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	status ProcessStatus
+}
+
+// NewSupervisor 创建进程监管
+func NewSupervisor() *Supervisor {
+	return &Supervisor{procs: make(map[string]*supervised)}
+}
+
+// Add 添加并启动进程
+func (s *Supervisor) Add(p Process) error {
+	if p.Name == "" || p.Cmd.Name == "" {
+		return errors.New("command: process name and command are required")
+	}
+	_ = helper.CheckAndSetDefault(&p)
+	setDuration(&p.BackoffMin, time.Second)
+	setDuration(&p.BackoffMax, time.Minute)
+	setDuration(&p.StableAfter, 10*time.Second)
+	setDuration(&p.StopTimeout, 10*time.Second)
+	setDuration(&p.HealthInterval, 10*time.Second)
+	setDuration(&p.HealthTimeout, 5*time.Second)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.procs[p.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrProcessExists, p.Name)
+	}
+	sp := s.start(p, 0)
+	s.procs[p.Name] = sp
+	return nil
+}
+
+// Stop 停止进程并从监管中移除
+func (s *Supervisor) Stop(name string) error {
+	s.mu.Lock()
+	sp, ok := s.procs[name]
+	delete(s.procs, name)
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrProcessNotFound, name)
+	}
+	sp.stop()
+	return nil
+}
+
+// Restart 停止并重新启动进程，累计重启次数保留
+func (s *Supervisor) Restart(name string) error {
+	s.mu.Lock()
+	sp, ok := s.procs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrProcessNotFound, name)
+	}
+	sp.stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 停止期间进程可能已被移除
+	if s.procs[name] != sp {
+		return fmt.Errorf("%w: %s", ErrProcessNotFound, name)
+	}
+	s.procs[name] = s.start(sp.conf, sp.snapshot().Restarts+1)
+	return nil
+}
+
+// StopAll 停止所有进程
+func (s *Supervisor) StopAll() {
+	s.mu.Lock()
+	procs := s.procs
+	s.procs = make(map[string]*supervised)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, sp := range procs {
+		wg.Add(1)
+		go func(sp *supervised) {
+			defer wg.Done()
+			sp.stop()
+		}(sp)
+	}
+	wg.Wait()
+}
+
+// Status 获取单个进程的状态
+func (s *Supervisor) Status(name string) (ProcessStatus, bool) {
+	s.mu.Lock()
+	sp, ok := s.procs[name]
+	s.mu.Unlock()
+	if !ok {
+		return ProcessStatus{}, false
+	}
+	return sp.snapshot(), true
+}
+
+// Statuses 获取所有进程的状态，按名称排序
+func (s *Supervisor) Statuses() []ProcessStatus {
+	s.mu.Lock()
+	list := make([]ProcessStatus, 0, len(s.procs))
+	for _, sp := range s.procs {
+		list = append(list, sp.snapshot())
+	}
+	s.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// HTTPProbe 返回一个HTTP健康检查，状态码为2xx/3xx时视为健康
+func HTTPProbe(url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("health check %s: status %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// start 启动监管协程
+func (s *Supervisor) start(p Process, restarts int) *supervised {
+	ctx, cancel := context.WithCancel(context.Background())
+	sp := &supervised{
+		sup:    s,
+		conf:   p,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		status: ProcessStatus{Name: p.Name, State: StateStarting, Restarts: restarts},
+	}
+	go sp.loop()
+	return sp
+}
+
+// loop 启动进程，退出后按策略重启
+func (sp *supervised) loop() {
+	defer close(sp.done)
+	backoff := sp.conf.BackoffMin
+	consecutive := 0
+	for {
+		started := time.Now()
+		exitCode, unhealthy, err := sp.runOnce()
+
+		if sp.ctx.Err() != nil {
+			sp.update(func(st *ProcessStatus) {
+				st.State, st.PID, st.Healthy = StateStopped, 0, false
+			})
+			return
+		}
+
+		failed := exitCode != 0 || unhealthy || err != nil
+		next := StateBackoff
+		if sp.conf.Restart == RestartNever || (sp.conf.Restart != RestartAlways && !failed) {
+			next = StateExited
+		} else {
+			if time.Since(started) >= sp.conf.StableAfter {
+				backoff, consecutive = sp.conf.BackoffMin, 0
+			}
+			consecutive++
+			if sp.conf.MaxRestarts > 0 && consecutive > sp.conf.MaxRestarts {
+				next = StateFatal
+			}
+		}
+
+		sp.update(func(st *ProcessStatus) {
+			st.State, st.PID, st.Healthy, st.ExitCode, st.ExitedAt = next, 0, false, exitCode, time.Now()
+			if err != nil {
+				st.LastError = err.Error()
+			}
+			if next == StateFatal {
+				st.LastError = fmt.Sprintf("连续重启超过%d次: %s", sp.conf.MaxRestarts, st.LastError)
+			}
+		})
+		if next != StateBackoff {
+			return
+		}
+
+		select {
+		case <-sp.ctx.Done():
+			sp.update(func(st *ProcessStatus) { st.State = StateStopped })
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, sp.conf.BackoffMax)
+		sp.update(func(st *ProcessStatus) {
+			st.State = StateStarting
+			st.Restarts++
+		})
+	}
+}
+
+// runOnce 启动一次进程并等待退出，返回状态码、是否因健康检查失败被重启
+func (sp *supervised) runOnce() (exitCode int, unhealthy bool, err error) {
+	ctx, cancel := context.WithCancel(sp.ctx)
+	defer cancel()
+
+	c := sp.conf.Cmd
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	if c.ClearEnv {
+		cmd.Env = append([]string{}, c.Env...)
+	} else if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	stdout := &lineWriter{onLine: c.OnStdout}
+	stderr := &lineWriter{onLine: c.OnStderr}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// 先发送中断信号，让进程有机会优雅退出，超时后强制杀死
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = sp.conf.StopTimeout
+	if c.Prepare != nil {
+		c.Prepare(cmd)
+	}
+
+	if err = cmd.Start(); err != nil {
+		return -1, false, err
+	}
+	sp.update(func(st *ProcessStatus) {
+		st.State, st.PID, st.StartedAt = StateRunning, cmd.Process.Pid, time.Now()
+		st.Healthy = sp.conf.Health == nil
+	})
+
+	var wg sync.WaitGroup
+	if sp.conf.Health != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sp.probe(ctx) {
+				unhealthy = true
+				cancel()
+			}
+		}()
+	}
+
+	err = cmd.Wait()
+	cancel()
+	wg.Wait()
+	stdout.flush()
+	stderr.flush()
+
+	exitCode = -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	if unhealthy {
+		err = errors.New("健康检查失败，已重启")
+	}
+	return exitCode, unhealthy, err
+}
+
+// probe 定期执行健康检查，连续失败达到上限时返回true
+func (sp *supervised) probe(ctx context.Context) bool {
+	ticker := time.NewTicker(sp.conf.HealthInterval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, sp.conf.HealthTimeout)
+		err := sp.conf.Health(probeCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return false
+		}
+		if err == nil {
+			failures = 0
+			sp.update(func(st *ProcessStatus) { st.Healthy = true })
+			continue
+		}
+		failures++
+		sp.update(func(st *ProcessStatus) {
+			st.Healthy = false
+			st.LastError = "健康检查失败: " + err.Error()
+		})
+		if failures >= sp.conf.HealthFailures {
+			return true
+		}
+	}
+}
+
+// stop 停止进程并等待监管协程退出
+func (sp *supervised) stop() {
+	sp.cancel()
+	<-sp.done
+}
+
+// update 修改状态并触发回调
+func (sp *supervised) update(fn func(st *ProcessStatus)) {
+	sp.mu.Lock()
+	fn(&sp.status)
+	status := sp.status
+	sp.mu.Unlock()
+	if sp.sup.OnEvent != nil {
+		sp.sup.OnEvent(status)
+	}
+}
+
+// snapshot 获取当前状态
+func (sp *supervised) snapshot() ProcessStatus {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return sp.status
+}
+
+// setDuration 为未设置的时间配置设置默认值
+func setDuration(d *time.Duration, def time.Duration) {
+	if *d <= 0 {
+		*d = def
+	}
+}
@@ -0,0 +1,480 @@
+package dbbackup
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/attachment/remote"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/security"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gzipExt    = ".gz"
+	encryptExt = ".enc"
+	timeLayout = "20060102-150405"
+)
+
+// ErrRunning 上一次备份尚未结束
+var ErrRunning = errors.New("dbbackup: 备份正在进行中")
+
+// File 备份文件
+type File struct {
+	Name    string    `json:"name"` // 文件名
+	Path    string    `json:"path"` // 本地路径或远程存储路径
+	Size    int64     `json:"size"`
+	Time    time.Time `json:"time"`   // 备份时间
+	Remote  bool      `json:"remote"` // 是否为远程存储中的文件
+	Encrypt bool      `json:"encrypt"`
+}
+
+// Backup 数据库备份，导出后按配置压缩、加密，保存到本地目录并可上传到远程存储，超出保留数量的旧备份自动删除
+type Backup struct {
+	Conf    jcbaseGo.DBBackupStruct
+	OnError func(err error) // 定时备份失败时的回调，默认输出日志
+
+	source  Source
+	remote  remote.Client
+	keyRing *security.KeyRing
+
+	running sync.Mutex
+	mu      sync.Mutex
+	stop    chan struct{}
+}
+
+// New 创建数据库备份
+//
+// 示例:
+//
+//	backup := dbbackup.New(conf.DBBackup, dbbackup.MySQL(conf.Db)).
+//		SetRemote(ossClient).
+//		SetKeyRing(ring)
+//	backup.Start() // 按 Interval 定时备份
+//
+//	file, err := backup.Run(ctx) // 立即备份一次
+func New(conf jcbaseGo.DBBackupStruct, source Source) *Backup {
+	_ = helper.CheckAndSetDefault(&conf)
+	if s, ok := source.(*MySQLSource); ok {
+		s.Mysqldump = defaultString(s.Mysqldump, conf.MysqldumpPath)
+		s.Mysql = defaultString(s.Mysql, conf.MysqlPath)
+	}
+	return &Backup{Conf: conf, source: source}
+}
+
+// SetRemote 设置远程存储，备份完成后上传
+func (b *Backup) SetRemote(client remote.Client) *Backup {
+	b.remote = client
+	return b
+}
+
+// SetKeyRing 设置加密使用的密钥环，设置后备份文件加密保存
+// 加密在内存中进行，适用于百MB级以内的数据库
+func (b *Backup) SetKeyRing(ring *security.KeyRing) *Backup {
+	b.keyRing = ring
+	return b
+}
+
+// Run 执行一次备份，返回最终保存的备份文件（上传远程存储且不保留本地文件时为远程文件）
+func (b *Backup) Run(ctx context.Context) (File, error) {
+	if !b.running.TryLock() {
+		return File{}, ErrRunning
+	}
+	defer b.running.Unlock()
+
+	if err := os.MkdirAll(b.Conf.Dir, 0o755); err != nil {
+		return File{}, err
+	}
+
+	now := time.Now()
+	name := b.source.Name() + "-" + now.Format(timeLayout) + b.source.Ext()
+	dumpPath := filepath.Join(b.Conf.Dir, name)
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	if err := b.source.Dump(ctx, dumpPath); err != nil {
+		_ = os.Remove(dumpPath)
+		return File{}, err
+	}
+
+	finalPath, err := b.pack(dumpPath)
+	if err != nil {
+		return File{}, err
+	}
+	file, err := localFile(finalPath)
+	if err != nil {
+		return File{}, err
+	}
+
+	if b.remote != nil {
+		data, err := os.ReadFile(finalPath)
+		if err != nil {
+			return file, err
+		}
+		remotePath := path.Join(b.Conf.RemoteDir, file.Name)
+		if err = b.remote.Upload(ctx, remotePath, data); err != nil {
+			return file, err
+		}
+		if !b.Conf.KeepLocal {
+			_ = os.Remove(finalPath)
+			file.Path, file.Remote = remotePath, true
+		}
+		if err = b.rotateRemote(ctx); err != nil {
+			return file, err
+		}
+	}
+	return file, b.rotateLocal()
+}
+
+// pack 按配置压缩、加密导出的文件，返回最终文件路径
+func (b *Backup) pack(dumpPath string) (string, error) {
+	current := dumpPath
+	if b.Conf.Compress {
+		if err := gzipFile(current, current+gzipExt); err != nil {
+			_ = os.Remove(current)
+			return "", err
+		}
+		_ = os.Remove(current)
+		current += gzipExt
+	}
+	if b.keyRing != nil {
+		data, err := os.ReadFile(current)
+		if err != nil {
+			return "", err
+		}
+		cipherText, err := b.keyRing.Encrypt(data)
+		if err == nil {
+			err = os.WriteFile(current+encryptExt, []byte(cipherText), 0o600)
+		}
+		_ = os.Remove(current)
+		if err != nil {
+			return "", err
+		}
+		current += encryptExt
+	}
+	return current, nil
+}
+
+// Start 按配置的间隔定时备份，重复调用无效
+func (b *Backup) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stop != nil || b.Conf.Interval <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	b.stop = stop
+	go func() {
+		ticker := time.NewTicker(time.Duration(b.Conf.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := b.Run(context.Background()); err != nil {
+					if b.OnError != nil {
+						b.OnError(err)
+					} else {
+						log.Printf("dbbackup: %s 备份失败: %v", b.source.Name(), err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止定时备份
+func (b *Backup) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stop != nil {
+		close(b.stop)
+		b.stop = nil
+	}
+}
+
+// List 列出本地备份文件，按时间倒序
+func (b *Backup) List() ([]File, error) {
+	entries, err := os.ReadDir(b.Conf.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []File{}, nil
+		}
+		return nil, err
+	}
+	files := make([]File, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || !b.isBackup(entry.Name()) {
+			continue
+		}
+		file, err := localFile(filepath.Join(b.Conf.Dir, entry.Name()))
+		if err == nil {
+			files = append(files, file)
+		}
+	}
+	sortFiles(files)
+	return files, nil
+}
+
+// ListRemote 列出远程存储中的备份文件，按时间倒序
+func (b *Backup) ListRemote(ctx context.Context) ([]File, error) {
+	if b.remote == nil {
+		return nil, fmt.Errorf("dbbackup: 未设置远程存储")
+	}
+	files := make([]File, 0)
+	opt := remote.ListOptions{Prefix: b.Conf.RemoteDir}
+	for {
+		res, err := b.remote.List(ctx, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range res.Files {
+			name := path.Base(info.Name)
+			if info.IsDir || !b.isBackup(name) {
+				continue
+			}
+			files = append(files, File{
+				Name:    name,
+				Path:    path.Join(b.Conf.RemoteDir, name),
+				Size:    info.Size,
+				Time:    parseTime(name, info.ModTime),
+				Remote:  true,
+				Encrypt: strings.HasSuffix(name, encryptExt),
+			})
+		}
+		if !res.IsTruncated || res.NextMarker == "" {
+			break
+		}
+		opt.Marker = res.NextMarker
+	}
+	sortFiles(files)
+	return files, nil
+}
+
+// Restore 从本地备份文件恢复，文件名中的 .gz、.enc 后缀决定是否解压、解密
+func (b *Backup) Restore(ctx context.Context, file string) error {
+	if !b.running.TryLock() {
+		return ErrRunning
+	}
+	defer b.running.Unlock()
+
+	dumpPath, cleanup, err := b.unpack(file)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return b.source.Restore(ctx, dumpPath)
+}
+
+// RestoreRemote 下载远程存储中的备份文件并恢复，remotePath 为 ListRemote 返回的 Path
+func (b *Backup) RestoreRemote(ctx context.Context, remotePath string) error {
+	if b.remote == nil {
+		return fmt.Errorf("dbbackup: 未设置远程存储")
+	}
+	data, err := b.remote.Download(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp("", "dbbackup")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	local := filepath.Join(tmp, path.Base(remotePath))
+	if err = os.WriteFile(local, data, 0o600); err != nil {
+		return err
+	}
+	return b.Restore(ctx, local)
+}
+
+// unpack 解密、解压备份文件到临时目录，返回导出文件路径及清理函数
+func (b *Backup) unpack(file string) (string, func(), error) {
+	tmp, err := os.MkdirTemp("", "dbbackup")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(tmp) }
+
+	current := file
+	name := filepath.Base(file)
+	if strings.HasSuffix(name, encryptExt) {
+		if b.keyRing == nil {
+			cleanup()
+			return "", nil, fmt.Errorf("dbbackup: %s 已加密，需要先调用 SetKeyRing", name)
+		}
+		data, err := os.ReadFile(current)
+		if err == nil {
+			data, err = b.keyRing.Decrypt(string(data))
+		}
+		name = strings.TrimSuffix(name, encryptExt)
+		current = filepath.Join(tmp, name)
+		if err == nil {
+			err = os.WriteFile(current, data, 0o600)
+		}
+		if err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+	if strings.HasSuffix(name, gzipExt) {
+		name = strings.TrimSuffix(name, gzipExt)
+		dst := filepath.Join(tmp, name)
+		if err = gunzipFile(current, dst); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+		current = dst
+	}
+	return current, cleanup, nil
+}
+
+// rotateLocal 删除超出保留数量的本地备份
+func (b *Backup) rotateLocal() error {
+	if b.Conf.Keep <= 0 {
+		return nil
+	}
+	files, err := b.List()
+	if err != nil || len(files) <= b.Conf.Keep {
+		return err
+	}
+	for _, file := range files[b.Conf.Keep:] {
+		if err = os.Remove(file.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotateRemote 删除超出保留数量的远程备份
+func (b *Backup) rotateRemote(ctx context.Context) error {
+	if b.Conf.Keep <= 0 {
+		return nil
+	}
+	files, err := b.ListRemote(ctx)
+	if err != nil || len(files) <= b.Conf.Keep {
+		return err
+	}
+	for _, file := range files[b.Conf.Keep:] {
+		if err = b.remote.Delete(ctx, file.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isBackup 判断文件名是否为当前数据源的备份文件
+func (b *Backup) isBackup(name string) bool {
+	prefix := b.source.Name() + "-"
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	base := strings.TrimSuffix(strings.TrimSuffix(name, encryptExt), gzipExt)
+	stamp := strings.TrimSuffix(strings.TrimPrefix(base, prefix), b.source.Ext())
+	_, err := time.ParseInLocation(timeLayout, stamp, time.Local)
+	return err == nil
+}
+
+func (b *Backup) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if b.Conf.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(b.Conf.Timeout)*time.Second)
+}
+
+// localFile 获取本地备份文件信息
+func localFile(p string) (File, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return File{}, err
+	}
+	return File{
+		Name:    info.Name(),
+		Path:    p,
+		Size:    info.Size(),
+		Time:    parseTime(info.Name(), info.ModTime()),
+		Encrypt: strings.HasSuffix(info.Name(), encryptExt),
+	}, nil
+}
+
+// parseTime 从文件名中解析备份时间，解析失败时返回 fallback
+func parseTime(name string, fallback time.Time) time.Time {
+	i := strings.LastIndex(name, "-")
+	if i < 0 || i < 8 {
+		return fallback
+	}
+	stamp := name[i-8:]
+	if len(stamp) < len(timeLayout) {
+		return fallback
+	}
+	t, err := time.ParseInLocation(timeLayout, stamp[:len(timeLayout)], time.Local)
+	if err != nil {
+		return fallback
+	}
+	return t
+}
+
+func sortFiles(files []File) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Time.After(files[j].Time)
+	})
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err = io.Copy(zw, in); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
+
+func gunzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, zr); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
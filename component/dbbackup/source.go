@@ -0,0 +1,170 @@
+package dbbackup
+
+import (
+	"context"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/command"
+	"gorm.io/gorm"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source 备份的数据源
+type Source interface {
+	Name() string                                  // 数据源名称，作为备份文件名的前缀
+	Ext() string                                   // 导出文件的扩展名，如 .sql
+	Dump(ctx context.Context, dst string) error    // 导出到本地文件
+	Restore(ctx context.Context, src string) error // 从导出的文件恢复
+}
+
+// MySQLSource 通过 mysqldump 导出、mysql 命令恢复MySQL数据库，需要服务器上安装MySQL客户端
+type MySQLSource struct {
+	Conf      jcbaseGo.DbStruct
+	Mysqldump string   // mysqldump 命令路径，为空时使用备份配置中的 MysqldumpPath
+	Mysql     string   // mysql 命令路径，为空时使用备份配置中的 MysqlPath
+	Args      []string // mysqldump 的额外参数，如 --ignore-table=db.log
+}
+
+// MySQL 根据数据库配置创建MySQL数据源
+//
+// 示例:
+//
+//	backup := dbbackup.New(conf.DBBackup, dbbackup.MySQL(conf.Db))
+func MySQL(conf jcbaseGo.DbStruct) *MySQLSource {
+	return &MySQLSource{Conf: conf}
+}
+
+// Name 实现 Source 接口
+func (s *MySQLSource) Name() string {
+	return s.Conf.Dbname
+}
+
+// Ext 实现 Source 接口
+func (s *MySQLSource) Ext() string {
+	return ".sql"
+}
+
+// Dump 实现 Source 接口，使用 --single-transaction 导出，InnoDB表备份期间不锁表
+func (s *MySQLSource) Dump(ctx context.Context, dst string) error {
+	args := append(s.connArgs(),
+		"--single-transaction",
+		"--quick",
+		"--routines",
+		"--triggers",
+		"--events",
+		"--result-file="+dst,
+	)
+	args = append(args, s.Args...)
+	args = append(args, s.Conf.Dbname)
+	return s.run(ctx, defaultString(s.Mysqldump, "mysqldump"), args, nil)
+}
+
+// Restore 实现 Source 接口
+func (s *MySQLSource) Restore(ctx context.Context, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return s.run(ctx, defaultString(s.Mysql, "mysql"), append(s.connArgs(), s.Conf.Dbname), f)
+}
+
+// connArgs 连接参数，密码通过环境变量传递，避免出现在进程列表中
+func (s *MySQLSource) connArgs() []string {
+	args := []string{"--user=" + s.Conf.Username}
+	if s.Conf.Protocol == "unix" {
+		args = append(args, "--socket="+s.Conf.Host)
+	} else {
+		args = append(args, "--host="+s.Conf.Host, "--port="+s.Conf.Port)
+	}
+	if s.Conf.Charset != "" {
+		args = append(args, "--default-character-set="+s.Conf.Charset)
+	}
+	return args
+}
+
+func (s *MySQLSource) run(ctx context.Context, name string, args []string, stdin io.Reader) error {
+	res, err := (&command.Cmd{
+		Name:  name,
+		Args:  args,
+		Env:   []string{"MYSQL_PWD=" + s.Conf.Password},
+		Stdin: stdin,
+	}).Run(ctx)
+	if err != nil {
+		return fmt.Errorf("dbbackup: %s 执行失败: %w %s", filepath.Base(name), err, strings.TrimSpace(res.Stderr))
+	}
+	return nil
+}
+
+// SQLiteSource SQLite数据源
+type SQLiteSource struct {
+	File string   // 数据库文件
+	DB   *gorm.DB // 数据库连接，设置后使用 VACUUM INTO 导出一致的快照，否则直接复制文件
+}
+
+// SQLite 创建SQLite数据源，db 可以为nil
+//
+// 示例:
+//
+//	backup := dbbackup.New(conf.DBBackup, dbbackup.SQLite(conf.SqlLite.DbFile, db))
+func SQLite(file string, db *gorm.DB) *SQLiteSource {
+	return &SQLiteSource{File: file, DB: db}
+}
+
+// Name 实现 Source 接口
+func (s *SQLiteSource) Name() string {
+	return strings.TrimSuffix(filepath.Base(s.File), filepath.Ext(s.File))
+}
+
+// Ext 实现 Source 接口
+func (s *SQLiteSource) Ext() string {
+	return ".db"
+}
+
+// Dump 实现 Source 接口
+func (s *SQLiteSource) Dump(ctx context.Context, dst string) error {
+	if s.DB != nil {
+		return s.DB.WithContext(ctx).Exec("VACUUM INTO ?", dst).Error
+	}
+	return copyFile(s.File, dst)
+}
+
+// Restore 实现 Source 接口，直接替换数据库文件，恢复前需关闭所有使用该文件的连接
+func (s *SQLiteSource) Restore(_ context.Context, src string) error {
+	tmp := s.File + ".restore"
+	if err := copyFile(src, tmp); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		_ = os.Remove(s.File + suffix)
+	}
+	return os.Rename(tmp, s.File)
+}
+
+// copyFile 复制文件
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,397 @@
+// Package dict 存储在数据库中的字典及系统设置
+//
+// 数据按分组（group）组织，同一分组内以 key 区分，既可以作为系统设置读取单个值，
+// 也可以整组读取作为下拉选项。读取时按分组缓存，通过本组件修改后自动清除对应分组的缓存。
+package dict
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 状态
+const (
+	StatusDisabled = 0
+	StatusEnabled  = 1
+)
+
+// ErrNotFound 字典项不存在
+var ErrNotFound = errors.New("dict: 字典项不存在")
+
+// Item 字典项
+type Item struct {
+	ID        uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Group     string    `gorm:"column:group;size:64;not null;uniqueIndex:uk_group_key,priority:1;comment:分组" json:"group"`
+	Key       string    `gorm:"column:key;size:128;not null;uniqueIndex:uk_group_key,priority:2;comment:键" json:"key"`
+	Value     string    `gorm:"column:value;type:text;comment:值" json:"value"`
+	Label     string    `gorm:"column:label;size:128;default:'';comment:显示名称" json:"label"`
+	Sort      int       `gorm:"column:sort;default:0;comment:排序，越小越靠前" json:"sort"`
+	Status    int       `gorm:"column:status;default:1;comment:状态 0禁用 1启用" json:"status"`
+	Remark    string    `gorm:"column:remark;size:255;default:'';comment:备注" json:"remark"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// Options 配置
+type Options struct {
+	Table       string        `default:"dict"` // 表名（含前缀）
+	AutoMigrate bool          // 是否自动创建数据表
+	CacheTTL    time.Duration // 分组缓存的有效期，0表示直到修改或调用 Invalidate；多实例部署时建议设置
+}
+
+// Dict 字典
+//
+// 示例:
+//
+//	d := dict.New(db.GetDb(), dict.Options{AutoMigrate: true, CacheTTL: time.Minute})
+//	_ = d.Set(ctx, "site", "title", "我的网站")
+//	title := d.GetString("site", "title", "默认标题")
+//	size := d.GetInt("upload", "max_size", 10)
+//	options := d.Options("gender") // 下拉选项
+type Dict struct {
+	db  *gorm.DB
+	opt Options
+
+	mu    sync.RWMutex
+	cache map[string]*group
+}
+
+// group 缓存的分组
+type group struct {
+	items    []Item // 已启用的字典项，按 sort、id 排序
+	byKey    map[string]Item
+	loadedAt time.Time
+}
+
+// New 创建字典
+func New(db *gorm.DB, opts ...Options) *Dict {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+
+	d := &Dict{db: db, opt: opt, cache: make(map[string]*group)}
+	if opt.AutoMigrate {
+		_ = d.table(context.Background()).AutoMigrate(&Item{})
+	}
+	return d
+}
+
+// table 数据表查询
+func (d *Dict) table(ctx context.Context) *gorm.DB {
+	return d.db.WithContext(ctx).Table(d.opt.Table)
+}
+
+// load 获取分组，缓存不存在或过期时从数据库加载
+func (d *Dict) load(name string) *group {
+	d.mu.RLock()
+	g, ok := d.cache[name]
+	d.mu.RUnlock()
+	if ok && (d.opt.CacheTTL <= 0 || time.Since(g.loadedAt) < d.opt.CacheTTL) {
+		return g
+	}
+
+	items := make([]Item, 0)
+	err := d.table(context.Background()).
+		Where(clause.Eq{Column: clause.Column{Name: "group"}, Value: name}).
+		Where("status = ?", StatusEnabled).
+		Order("sort, id").
+		Find(&items).Error
+	if err != nil {
+		// 加载失败时继续使用旧缓存
+		if ok {
+			return g
+		}
+		return &group{byKey: map[string]Item{}}
+	}
+
+	g = &group{items: items, byKey: make(map[string]Item, len(items)), loadedAt: time.Now()}
+	for _, item := range items {
+		g.byKey[item.Key] = item
+	}
+	d.mu.Lock()
+	d.cache[name] = g
+	d.mu.Unlock()
+	return g
+}
+
+// Invalidate 清除分组缓存，不传分组时清除全部
+func (d *Dict) Invalidate(groups ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(groups) == 0 {
+		d.cache = make(map[string]*group)
+		return
+	}
+	for _, name := range groups {
+		delete(d.cache, name)
+	}
+}
+
+// ----- 读取 ----- /
+
+// Get 获取已启用的字典项的值
+func (d *Dict) Get(group, key string) (string, bool) {
+	item, ok := d.load(group).byKey[key]
+	return item.Value, ok
+}
+
+// Item 获取已启用的字典项
+func (d *Dict) Item(group, key string) (Item, bool) {
+	item, ok := d.load(group).byKey[key]
+	return item, ok
+}
+
+// GetString 获取字符串，不存在时返回默认值
+func (d *Dict) GetString(group, key string, def ...string) string {
+	if v, ok := d.Get(group, key); ok {
+		return v
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return ""
+}
+
+// GetInt 获取整数，不存在或无法转换时返回默认值
+func (d *Dict) GetInt(group, key string, def ...int) int {
+	if v, ok := d.Get(group, key); ok {
+		if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return i
+		}
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return 0
+}
+
+// GetInt64 获取64位整数，不存在或无法转换时返回默认值
+func (d *Dict) GetInt64(group, key string, def ...int64) int64 {
+	if v, ok := d.Get(group, key); ok {
+		if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return i
+		}
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return 0
+}
+
+// GetFloat 获取浮点数，不存在或无法转换时返回默认值
+func (d *Dict) GetFloat(group, key string, def ...float64) float64 {
+	if v, ok := d.Get(group, key); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f
+		}
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return 0
+}
+
+// GetBool 获取布尔值，1/true/yes/on 为 true，0/false/no/off 为 false，其他值返回默认值
+func (d *Dict) GetBool(group, key string, def ...bool) bool {
+	if v, ok := d.Get(group, key); ok {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "1", "true", "yes", "on":
+			return true
+		case "0", "false", "no", "off":
+			return false
+		}
+	}
+	return len(def) > 0 && def[0]
+}
+
+// GetDuration 获取时长，值为 time.ParseDuration 格式（如 30s、2h），纯数字按秒处理
+func (d *Dict) GetDuration(group, key string, def ...time.Duration) time.Duration {
+	if v, ok := d.Get(group, key); ok {
+		v = strings.TrimSpace(v)
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if duration, err := time.ParseDuration(v); err == nil {
+			return duration
+		}
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return 0
+}
+
+// GetJSON 将JSON格式的值解析到 v，不存在时返回 ErrNotFound
+func (d *Dict) GetJSON(group, key string, v any) error {
+	raw, ok := d.Get(group, key)
+	if !ok {
+		return ErrNotFound
+	}
+	return json.Unmarshal([]byte(raw), v)
+}
+
+// Items 获取分组内已启用的字典项，按 sort、id 排序
+func (d *Dict) Items(group string) []Item {
+	return append([]Item(nil), d.load(group).items...)
+}
+
+// Map 获取分组内已启用的字典项，键 => 值
+func (d *Dict) Map(group string) map[string]string {
+	items := d.load(group).items
+	m := make(map[string]string, len(items))
+	for _, item := range items {
+		m[item.Key] = item.Value
+	}
+	return m
+}
+
+// Option 下拉选项
+type Option struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Options 获取分组的下拉选项，Label 为空时使用 Key
+func (d *Dict) Options(group string) []Option {
+	items := d.load(group).items
+	options := make([]Option, 0, len(items))
+	for _, item := range items {
+		label := item.Label
+		if label == "" {
+			label = item.Key
+		}
+		options = append(options, Option{Label: label, Value: item.Value})
+	}
+	return options
+}
+
+// ----- 修改 ----- /
+
+// Set 设置字典项的值，不存在时创建，label 可选；非字符串的值转换为字符串，map、切片、结构体保存为JSON
+func (d *Dict) Set(ctx context.Context, group, key string, value any, label ...string) error {
+	str, err := toString(value)
+	if err != nil {
+		return err
+	}
+	item := Item{Group: group, Key: key, Value: str, Status: StatusEnabled}
+	updates := []string{"value", "updated_at"}
+	if len(label) > 0 {
+		item.Label = label[0]
+		updates = append(updates, "label")
+	}
+	err = d.table(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns(updates),
+	}).Create(&item).Error
+	d.Invalidate(group)
+	return err
+}
+
+// SetMany 批量设置同一分组的值，在事务中执行
+func (d *Dict) SetMany(ctx context.Context, group string, values map[string]any) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		opt := d.opt
+		opt.AutoMigrate = false
+		txDict := New(tx, opt)
+		for _, key := range keys {
+			if err := txDict.Set(ctx, group, key, values[key]); err != nil {
+				return fmt.Errorf("dict: %s.%s: %w", group, key, err)
+			}
+		}
+		return nil
+	})
+	d.Invalidate(group)
+	return err
+}
+
+// Save 保存字典项，ID为0时新建，否则按ID更新全部字段
+func (d *Dict) Save(ctx context.Context, item *Item) error {
+	var oldGroup string
+	if item.ID > 0 {
+		var old Item
+		if err := d.table(ctx).Where("id = ?", item.ID).Take(&old).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		oldGroup = old.Group
+		item.CreatedAt = old.CreatedAt
+	}
+	err := d.table(ctx).Save(item).Error
+	d.Invalidate(item.Group)
+	if oldGroup != "" && oldGroup != item.Group {
+		d.Invalidate(oldGroup)
+	}
+	return err
+}
+
+// Delete 删除字典项
+func (d *Dict) Delete(ctx context.Context, group, key string) error {
+	err := d.table(ctx).
+		Where(clause.Eq{Column: clause.Column{Name: "group"}, Value: group}).
+		Where(clause.Eq{Column: clause.Column{Name: "key"}, Value: key}).
+		Delete(&Item{}).Error
+	d.Invalidate(group)
+	return err
+}
+
+// DeleteByID 按ID删除字典项
+func (d *Dict) DeleteByID(ctx context.Context, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	groups := make([]string, 0)
+	if err := d.table(ctx).Where("id IN ?", ids).Distinct().Pluck("group", &groups).Error; err != nil {
+		return err
+	}
+	err := d.table(ctx).Where("id IN ?", ids).Delete(&Item{}).Error
+	if len(groups) > 0 {
+		d.Invalidate(groups...)
+	}
+	return err
+}
+
+// toString 将值转换为保存的字符串
+func toString(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Duration:
+		return v.String(), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
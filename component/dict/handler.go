@@ -0,0 +1,165 @@
+package dict
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"gorm.io/gorm/clause"
+)
+
+// Handler 字典管理接口，需自行在路由分组上添加登录、权限等中间件
+type Handler struct {
+	dict *Dict
+}
+
+// Handler 获取字典管理接口
+//
+// 示例:
+//
+//	admin := r.Group("/admin/dict", middleware.LoginRequired())
+//	d.Handler().RegisterRoutes(admin)
+func (d *Dict) Handler() *Handler {
+	return &Handler{dict: d}
+}
+
+// RegisterRoutes 注册路由：
+//   - GET  /list    分页列表，参数 group、keyword、page、page_size
+//   - GET  /groups  全部分组
+//   - GET  /options 分组的下拉选项，参数 group
+//   - POST /save    新建或修改（传入id时为修改）
+//   - POST /delete  删除，参数 ids
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/list", h.List)
+	r.GET("/groups", h.Groups)
+	r.GET("/options", h.Options)
+	r.POST("/save", h.Save)
+	r.POST("/delete", h.Delete)
+}
+
+// List 分页列表，包含已禁用的字典项
+func (h *Handler) List(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		Group    string `form:"group"`
+		Keyword  string `form:"keyword"`
+		Page     int    `form:"page"`
+		PageSize int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	page, pageSize := max(form.Page, 1), form.PageSize
+	if pageSize < 1 {
+		pageSize = 10
+	} else if pageSize > 1000 {
+		pageSize = 1000
+	}
+
+	query := h.dict.table(c.Request.Context())
+	if form.Group != "" {
+		query = query.Where(clause.Eq{Column: clause.Column{Name: "group"}, Value: form.Group})
+	}
+	if form.Keyword != "" {
+		like := "%" + form.Keyword + "%"
+		query = query.Where(clause.Or(
+			clause.Like{Column: clause.Column{Name: "key"}, Value: like},
+			clause.Like{Column: clause.Column{Name: "label"}, Value: like},
+			clause.Like{Column: clause.Column{Name: "remark"}, Value: like},
+		))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	list := make([]Item, 0)
+	if err := query.Order(clause.OrderByColumn{Column: clause.Column{Name: "group"}}).Order("sort, id").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Success(jcbaseGo.ListData{List: list, Total: int(total), Page: page, PageSize: pageSize})
+}
+
+// Groups 全部分组
+func (h *Handler) Groups(c *gin.Context) {
+	groups := make([]string, 0)
+	err := h.dict.table(c.Request.Context()).Distinct().Order(clause.OrderByColumn{Column: clause.Column{Name: "group"}}).Pluck("group", &groups).Error
+	if err != nil {
+		controller.Base{GinContext: c}.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	controller.Base{GinContext: c}.Success(groups)
+}
+
+// Options 分组的下拉选项（仅已启用）
+func (h *Handler) Options(c *gin.Context) {
+	group := c.Query("group")
+	if group == "" {
+		controller.Base{GinContext: c}.Failure("group不能为空", nil, errcode.ParamMissing)
+		return
+	}
+	controller.Base{GinContext: c}.Success(h.dict.Options(group))
+}
+
+// Save 新建或修改
+func (h *Handler) Save(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		ID     uint   `json:"id" form:"id"`
+		Group  string `json:"group" form:"group" binding:"required,max=64"`
+		Key    string `json:"key" form:"key" binding:"required,max=128"`
+		Value  string `json:"value" form:"value"`
+		Label  string `json:"label" form:"label" binding:"max=128"`
+		Sort   int    `json:"sort" form:"sort"`
+		Status *int   `json:"status" form:"status"` // 未传入时为启用
+		Remark string `json:"remark" form:"remark" binding:"max=255"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	item := Item{
+		ID:     form.ID,
+		Group:  form.Group,
+		Key:    form.Key,
+		Value:  form.Value,
+		Label:  form.Label,
+		Sort:   form.Sort,
+		Status: StatusEnabled,
+		Remark: form.Remark,
+	}
+	if form.Status != nil && *form.Status == StatusDisabled {
+		item.Status = StatusDisabled
+	}
+	if err := h.dict.Save(c.Request.Context(), &item); err != nil {
+		if err == ErrNotFound {
+			base.Failure("字典项不存在或已被删除", nil, errcode.NotExist)
+			return
+		}
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessChange, "success", item)
+}
+
+// Delete 删除
+func (h *Handler) Delete(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		IDs []uint `json:"ids" form:"ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.dict.DeleteByID(c.Request.Context(), form.IDs...); err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessDelete, "success")
+}
@@ -0,0 +1,188 @@
+// Package enum 带名称及显示文本的枚举定义，用于替代状态、类型等字段中的魔法数字
+//
+// 枚举可以在代码中定义，也可以从数据库字典表加载；字段使用 Value[D] 类型后，
+// 可直接读写数据库、通过 binding:"enum" 校验取值，并在控制器输出时自动附加 <字段名>_label 显示文本。
+package enum
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Item 枚举项
+type Item struct {
+	Code  int    `json:"code"`  // 存储的值
+	Name  string `json:"name"`  // 标识，如 pending，用于代码中引用
+	Label string `json:"label"` // 显示文本，可以是翻译键
+}
+
+// Enum 枚举定义，创建后只读，可并发使用
+type Enum struct {
+	name   string
+	items  []Item
+	byCode map[int]Item
+	byName map[string]Item
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*Enum)
+)
+
+// New 创建枚举并注册，同名枚举会被覆盖，Code 重复时 panic
+//
+// 示例:
+//
+//	var OrderStatus = enum.New("order_status",
+//		enum.Item{Code: 0, Name: "pending", Label: "待支付"},
+//		enum.Item{Code: 1, Name: "paid", Label: "已支付"},
+//		enum.Item{Code: -1, Name: "closed", Label: "order.status.closed"}, // 翻译键
+//	)
+func New(name string, items ...Item) *Enum {
+	e := &Enum{
+		name:   name,
+		items:  make([]Item, 0, len(items)),
+		byCode: make(map[int]Item, len(items)),
+		byName: make(map[string]Item, len(items)),
+	}
+	for _, item := range items {
+		if _, ok := e.byCode[item.Code]; ok {
+			panic(fmt.Sprintf("enum: %s 的值 %d 重复", name, item.Code))
+		}
+		if item.Label == "" {
+			item.Label = item.Name
+		}
+		e.items = append(e.items, item)
+		e.byCode[item.Code] = item
+		if item.Name != "" {
+			e.byName[item.Name] = item
+		}
+	}
+
+	registryMu.Lock()
+	registry[name] = e
+	registryMu.Unlock()
+	return e
+}
+
+// FromQuery 从数据库字典表加载枚举并注册，查询结果需包含 code、name、label 列（可用别名）
+//
+// 示例:
+//
+//	e, err := enum.FromQuery("gender", db.Table("dict_item").
+//		Select("value AS code, `key` AS name, title AS label").
+//		Where("dict = ?", "gender").Order("sort"))
+func FromQuery(name string, query *gorm.DB) (*Enum, error) {
+	items := make([]Item, 0)
+	if err := query.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool, len(items))
+	for _, item := range items {
+		if seen[item.Code] {
+			return nil, fmt.Errorf("enum: %s 的值 %d 重复", name, item.Code)
+		}
+		seen[item.Code] = true
+	}
+	return New(name, items...), nil
+}
+
+// Get 获取已注册的枚举
+func Get(name string) (*Enum, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[name]
+	return e, ok
+}
+
+// Names 获取已注册的枚举名称
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name 枚举名称
+func (e *Enum) Name() string {
+	return e.name
+}
+
+// Items 全部枚举项，按定义顺序排列
+func (e *Enum) Items() []Item {
+	return append([]Item(nil), e.items...)
+}
+
+// Codes 全部枚举值
+func (e *Enum) Codes() []int {
+	codes := make([]int, 0, len(e.items))
+	for _, item := range e.items {
+		codes = append(codes, item.Code)
+	}
+	return codes
+}
+
+// Valid 判断值是否有效
+func (e *Enum) Valid(code int) bool {
+	_, ok := e.byCode[code]
+	return ok
+}
+
+// Item 按值获取枚举项
+func (e *Enum) Item(code int) (Item, bool) {
+	item, ok := e.byCode[code]
+	return item, ok
+}
+
+// ByName 按标识获取枚举项
+func (e *Enum) ByName(name string) (Item, bool) {
+	item, ok := e.byName[name]
+	return item, ok
+}
+
+// Code 按标识获取值，标识不存在时 panic，用于代码中引用枚举值
+//
+// 示例:
+//
+//	db.Where("status = ?", OrderStatus.Code("paid"))
+func (e *Enum) Code(name string) int {
+	item, ok := e.byName[name]
+	if !ok {
+		panic(fmt.Sprintf("enum: %s 中不存在 %s", e.name, name))
+	}
+	return item.Code
+}
+
+// Label 获取显示文本，translate 可选，用于翻译显示文本；值不存在时返回值本身
+func (e *Enum) Label(code int, translate ...func(string) string) string {
+	item, ok := e.byCode[code]
+	if !ok {
+		return strconv.Itoa(code)
+	}
+	if len(translate) > 0 && translate[0] != nil {
+		return translate[0](item.Label)
+	}
+	return item.Label
+}
+
+// Options 下拉选项，translate 可选，用于翻译显示文本
+//
+// 示例:
+//
+//	c.Success(OrderStatus.Options(func(s string) string { return i18n.Translate(c.GinContext, s) }))
+func (e *Enum) Options(translate ...func(string) string) []Item {
+	items := e.Items()
+	if len(translate) > 0 && translate[0] != nil {
+		for i := range items {
+			items[i].Label = translate[0](items[i].Label)
+		}
+	}
+	return items
+}
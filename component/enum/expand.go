@@ -0,0 +1,181 @@
+package enum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	labelerType   = reflect.TypeOf((*Labeler)(nil)).Elem()
+	containsCache sync.Map // reflect.Type => bool
+)
+
+// Contains 判断类型中是否包含枚举字段（含嵌套的结构体、切片、map）
+func Contains(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if v, ok := containsCache.Load(t); ok {
+		return v.(bool)
+	}
+	result := contains(t, make(map[reflect.Type]bool))
+	containsCache.Store(t, result)
+	return result
+}
+
+func contains(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t.Implements(labelerType) {
+		return true
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return contains(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if (field.IsExported() || field.Anonymous) && contains(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Expand 将数据转换为 map/[]any，并为每个枚举字段附加 <JSON字段名>_label 显示文本
+// 不包含枚举字段时原样返回；translate 可选，用于翻译显示文本
+//
+// 控制器的 Success/Result 会自动调用，一般无需手动使用
+//
+// 示例:
+//
+//	data := enum.Expand(order, nil)
+//	// {"id": 1, "status": 1, "status_label": "已支付"}
+func Expand(data any, translate func(string) string) any {
+	if data == nil || !Contains(reflect.TypeOf(data)) {
+		return data
+	}
+	return expand(reflect.ValueOf(data), translate)
+}
+
+func expand(rv reflect.Value, translate func(string) string) any {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return expand(rv.Elem(), translate)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		if !Contains(rv.Type()) {
+			return rv.Interface()
+		}
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = expand(rv.Index(i), translate)
+		}
+		return list
+	case reflect.Map:
+		if rv.IsNil() || !Contains(rv.Type()) {
+			return rv.Interface()
+		}
+		m := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = expand(iter.Value(), translate)
+		}
+		return m
+	case reflect.Struct:
+		if !Contains(rv.Type()) || rv.Type().Implements(labelerType) {
+			return rv.Interface()
+		}
+		m, ok := toMap(rv.Interface())
+		if !ok {
+			return rv.Interface()
+		}
+		expandFields(rv, m, translate)
+		return m
+	default:
+		return rv.Interface()
+	}
+}
+
+// expandFields 按JSON字段名为结构体中的枚举字段附加显示文本，匿名嵌入的结构体展开到同一层
+func expandFields(rv reflect.Value, m map[string]any, translate func(string) string) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		fv := rv.Field(i)
+
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && !fv.Type().Implements(labelerType) {
+				expandFields(fv, m, translate)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := m[name]; !ok {
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			continue
+		}
+		if labeler, ok := fv.Interface().(Labeler); ok {
+			m[name+"_label"] = labeler.EnumLabel(translate)
+			continue
+		}
+		if Contains(field.Type) {
+			m[name] = expand(fv, translate)
+		}
+	}
+}
+
+// jsonName 解析JSON字段名，skip 为 true 表示不输出
+func jsonName(field reflect.StructField) (name string, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	return name, false
+}
+
+// toMap 通过JSON将结构体转换为map，保持与直接输出JSON一致
+func toMap(v any) (map[string]any, bool) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var m map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if decoder.Decode(&m) != nil || m == nil {
+		return nil, false
+	}
+	return m, true
+}
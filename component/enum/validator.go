@@ -0,0 +1,62 @@
+package enum
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strconv"
+)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = RegisterValidation(v)
+	}
+}
+
+// RegisterValidation 注册 enum 校验规则，gin 默认的校验器已自动注册
+//
+// Value 类型的字段直接使用 binding:"enum"；int、string 等普通字段需指定枚举名称，如 binding:"enum=order_status"，
+// string 字段可以是枚举值或标识。零值同样会被校验，允许为空时配合 omitempty 使用
+//
+// 示例:
+//
+//	type OrderForm struct {
+//		Status enum.Value[OrderStatus] `json:"status" binding:"enum"`
+//		Type   int                     `json:"type" binding:"omitempty,enum=order_type"`
+//	}
+func RegisterValidation(v *validator.Validate) error {
+	return v.RegisterValidation("enum", validateEnum)
+}
+
+// validateEnum 校验字段值是否为有效的枚举值
+func validateEnum(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if param := fl.Param(); param != "" {
+		e, ok := Get(param)
+		if !ok {
+			return false
+		}
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return e.Valid(int(field.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return e.Valid(int(field.Uint()))
+		case reflect.String:
+			if _, ok := e.ByName(field.String()); ok {
+				return true
+			}
+			code, err := strconv.Atoi(field.String())
+			return err == nil && e.Valid(code)
+		default:
+			return false
+		}
+	}
+
+	if !field.CanInterface() {
+		return false
+	}
+	if valid, ok := field.Interface().(interface{ Valid() bool }); ok {
+		return valid.Valid()
+	}
+	return false
+}
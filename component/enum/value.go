@@ -0,0 +1,128 @@
+package enum
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+)
+
+// Definition 枚举类型标记，用于将 Value 字段关联到枚举定义
+type Definition interface {
+	Enum() *Enum
+}
+
+// Labeler 可输出显示文本的值，Value 实现了该接口
+type Labeler interface {
+	EnumLabel(translate func(string) string) string
+}
+
+// Value 关联枚举定义的字段类型，数据库及JSON中均存储为数值
+//
+// 示例:
+//
+//	var orderStatus = enum.New("order_status", ...)
+//
+//	type OrderStatus struct{}
+//
+//	func (OrderStatus) Enum() *enum.Enum { return orderStatus }
+//
+//	type Order struct {
+//		Status enum.Value[OrderStatus] `gorm:"column:status" json:"status" binding:"enum"`
+//	}
+//
+//	if order.Status.Is("paid") { ... }
+//	// 控制器输出：{"status": 1, "status_label": "已支付"}
+type Value[D Definition] int
+
+// Def 获取关联的枚举定义
+func (v Value[D]) Def() *Enum {
+	var d D
+	return d.Enum()
+}
+
+// Int 获取数值
+func (v Value[D]) Int() int {
+	return int(v)
+}
+
+// Valid 判断值是否有效
+func (v Value[D]) Valid() bool {
+	return v.Def().Valid(int(v))
+}
+
+// Is 判断是否为指定标识的枚举项
+func (v Value[D]) Is(name string) bool {
+	item, ok := v.Def().ByName(name)
+	return ok && item.Code == int(v)
+}
+
+// Name 获取标识
+func (v Value[D]) Name() string {
+	item, _ := v.Def().Item(int(v))
+	return item.Name
+}
+
+// Label 获取显示文本
+func (v Value[D]) Label() string {
+	return v.Def().Label(int(v))
+}
+
+// EnumLabel 实现 Labeler 接口
+func (v Value[D]) EnumLabel(translate func(string) string) string {
+	return v.Def().Label(int(v), translate)
+}
+
+// String 实现 fmt.Stringer 接口，输出显示文本
+func (v Value[D]) String() string {
+	return v.Label()
+}
+
+// MarshalJSON 实现 json.Marshaler 接口，输出数值
+func (v Value[D]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(v))
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口，支持数值、数字字符串及标识
+func (v *Value[D]) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if name, ok := raw.(string); ok {
+		if item, ok := v.Def().ByName(name); ok {
+			*v = Value[D](item.Code)
+			return nil
+		}
+	}
+	if raw == nil {
+		*v = 0
+		return nil
+	}
+	*v = Value[D](helper.Convert{Value: raw}.ToInt())
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口
+func (v Value[D]) Value() (driver.Value, error) {
+	return int64(v), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (v *Value[D]) Scan(value any) error {
+	switch val := value.(type) {
+	case nil:
+		*v = 0
+	case int64:
+		*v = Value[D](val)
+	case []byte:
+		*v = Value[D](helper.Convert{Value: string(val)}.ToInt())
+	default:
+		*v = Value[D](helper.Convert{Value: val}.ToInt())
+	}
+	return nil
+}
+
+// GormDataType 自动迁移时使用的字段类型
+func (Value[D]) GormDataType() string {
+	return "int"
+}
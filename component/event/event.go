@@ -0,0 +1,237 @@
+// Package event 进程内事件总线
+//
+// 业务代码在附件保存、用户创建等节点发布事件，监听方按需注册同步或异步处理函数，两者互不依赖。
+// 同步处理函数在 Emit 中按优先级依次执行，错误合并后返回给发布方；
+// 异步处理函数提交到总线的协程池（async.Pool）执行，错误及panic交给 Options.OnError。
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/helper/async"
+	"log"
+	"sort"
+	"sync"
+)
+
+// ErrPayloadType 载荷类型与事件定义的类型不一致
+var ErrPayloadType = errors.New("event: payload type mismatch")
+
+// Handler 处理函数，payload 为 Emit 传入的原始值
+type Handler func(ctx context.Context, payload any) error
+
+// HandlerOptions 处理函数配置
+type HandlerOptions struct {
+	Async    bool // 是否异步执行
+	Priority int  // 优先级，越大越先执行，相同优先级按注册顺序
+}
+
+// Options 总线配置
+type Options struct {
+	Workers   int `default:"4"`   // 异步处理的协程数
+	QueueSize int `default:"256"` // 异步任务队列长度，队列已满时 Emit 阻塞等待
+	// OnError 异步处理函数返回错误或panic时的回调，默认输出到日志
+	OnError func(event string, err error)
+}
+
+// Bus 事件总线
+//
+// 示例:
+//
+//	bus := event.New()
+//	bus.Register("attachment.saved", func(ctx context.Context, payload any) error {
+//		return thumbnail(payload.(attachment.Result))
+//	}, event.HandlerOptions{Async: true})
+//	err := bus.Emit(ctx, "attachment.saved", result)
+type Bus struct {
+	opt Options
+
+	mu       sync.RWMutex
+	handlers map[string][]*entry
+	seq      int
+
+	poolOnce sync.Once
+	pool     *async.Pool
+	closed   bool
+}
+
+// entry 已注册的处理函数
+type entry struct {
+	id      int
+	handler Handler
+	opt     HandlerOptions
+}
+
+// New 创建事件总线
+func New(opts ...Options) *Bus {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	return &Bus{opt: opt, handlers: make(map[string][]*entry)}
+}
+
+var defaultBus = New()
+
+// Default 获取默认的事件总线
+func Default() *Bus {
+	return defaultBus
+}
+
+// Register 注册处理函数，返回取消注册的函数
+func (b *Bus) Register(event string, handler Handler, opts ...HandlerOptions) (unregister func()) {
+	var opt HandlerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	e := &entry{id: b.seq, handler: handler, opt: opt}
+	list := append(b.handlers[event], e)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].opt.Priority > list[j].opt.Priority
+	})
+	b.handlers[event] = list
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		list := b.handlers[event]
+		for i, item := range list {
+			if item.id == e.id {
+				b.handlers[event] = append(list[:i:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(b.handlers[event]) == 0 {
+			delete(b.handlers, event)
+		}
+	}
+}
+
+// Has 事件是否有处理函数
+func (b *Bus) Has(event string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.handlers[event]) > 0
+}
+
+// Emit 发布事件：同步处理函数依次执行（其中的panic会转换为错误），全部执行完后返回合并的错误；
+// 异步处理函数在协程池中执行，使用不随 ctx 取消的上下文，不影响返回值
+func (b *Bus) Emit(ctx context.Context, event string, payload any) error {
+	b.mu.RLock()
+	list := b.handlers[event]
+	closed := b.closed
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, e := range list {
+		if e.opt.Async {
+			if closed {
+				errs = append(errs, fmt.Errorf("event: %s: %w", event, async.ErrPoolClosed))
+				continue
+			}
+			b.submit(ctx, event, e.handler, payload)
+			continue
+		}
+		handler := e.handler
+		if err := async.Safe(func() error { return handler(ctx, payload) }); err != nil {
+			errs = append(errs, fmt.Errorf("event: %s: %w", event, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// submit 提交异步处理
+func (b *Bus) submit(ctx context.Context, event string, handler Handler, payload any) {
+	b.poolOnce.Do(func() {
+		b.pool = async.NewPool(b.opt.Workers, b.opt.QueueSize)
+	})
+	if b.pool == nil { // 已关闭
+		b.onError(event, async.ErrPoolClosed)
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	err := b.pool.Submit(func() {
+		if err := async.Safe(func() error { return handler(ctx, payload) }); err != nil {
+			b.onError(event, err)
+		}
+	})
+	if err != nil {
+		b.onError(event, err)
+	}
+}
+
+func (b *Bus) onError(event string, err error) {
+	if b.opt.OnError != nil {
+		b.opt.OnError(event, err)
+		return
+	}
+	log.Printf("event: 异步处理 %s 失败: %v", event, err)
+}
+
+// Close 停止接收异步任务，并等待已提交的异步处理执行完毕，关闭后同步处理函数仍可正常执行
+func (b *Bus) Close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.poolOnce.Do(func() {})
+	if b.pool != nil {
+		b.pool.Close()
+	}
+}
+
+// ----- 类型化事件 ----- /
+
+// Event 类型化的事件定义，载荷类型在编译期检查
+//
+// 示例:
+//
+//	var UserCreated = event.Define[User]("user.created")
+//
+//	// 监听方
+//	UserCreated.Register(func(ctx context.Context, u User) error {
+//		return mailer.SendWelcome(u.Email)
+//	}, event.HandlerOptions{Async: true})
+//
+//	// 发布方
+//	err := UserCreated.Emit(ctx, user)
+type Event[T any] struct {
+	name string
+	bus  *Bus
+}
+
+// Define 定义事件，bus 为空时使用默认总线
+func Define[T any](name string, bus ...*Bus) Event[T] {
+	b := defaultBus
+	if len(bus) > 0 && bus[0] != nil {
+		b = bus[0]
+	}
+	return Event[T]{name: name, bus: b}
+}
+
+// Name 事件名称
+func (e Event[T]) Name() string {
+	return e.name
+}
+
+// Register 注册处理函数，返回取消注册的函数；通过 Bus.Emit 发布了其他类型的载荷时返回 ErrPayloadType
+func (e Event[T]) Register(handler func(ctx context.Context, payload T) error, opts ...HandlerOptions) (unregister func()) {
+	return e.bus.Register(e.name, func(ctx context.Context, payload any) error {
+		value, ok := payload.(T)
+		if !ok {
+			return fmt.Errorf("%w: want %T, got %T", ErrPayloadType, value, payload)
+		}
+		return handler(ctx, value)
+	}, opts...)
+}
+
+// Emit 发布事件
+func (e Event[T]) Emit(ctx context.Context, payload T) error {
+	return e.bus.Emit(ctx, e.name, payload)
+}
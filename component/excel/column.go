@@ -0,0 +1,280 @@
+package excel
+
+import (
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeFormat 时间类型字段默认的导出/导入格式
+const DefaultTimeFormat = "2006-01-02 15:04:05"
+
+// Column 列定义
+//
+// 通过结构体字段的 excel 标签定义，格式为 `excel:"标题,选项=值,..."`，标签为 "-" 时忽略该字段，
+// 没有 excel 标签的字段不会导出，可选项：
+//   - width 列宽（字符数），如 width=20
+//   - time 时间格式，如 time=2006-01-02
+//   - enum 枚举映射，导出时将值转换为文本，导入时反向转换，如 enum=1:启用|0:禁用
+//   - required 导入时不能为空
+//
+// 示例:
+//
+//	type User struct {
+//	    ID        uint      `excel:"ID,width=8"`
+//	    Username  string    `excel:"用户名,width=20,required"`
+//	    Status    int       `excel:"状态,enum=1:启用|0:禁用"`
+//	    CreatedAt time.Time `excel:"注册时间,width=20,time=2006-01-02 15:04"`
+//	    Password  string    `excel:"-"`
+//	}
+type Column struct {
+	Field      string            // 结构体字段名，为空时通过 Value 取值
+	Title      string            // 列标题
+	Width      float64           // 列宽，0表示默认宽度
+	TimeFormat string            // 时间格式
+	Enum       map[string]string // 枚举映射，值 => 文本
+	Required   bool              // 导入时不能为空
+
+	// Value 自定义取值，参数为当前行数据，优先于 Field
+	Value func(row any) any
+
+	index []int
+}
+
+// Columns 根据结构体的 excel 标签解析列定义，model 可以是结构体、结构体指针或结构体切片
+func Columns(model any) []Column {
+	t := reflect.TypeOf(model)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return parseColumns(t, nil)
+}
+
+// parseColumns 递归解析结构体字段，支持匿名嵌入的结构体
+func parseColumns(t reflect.Type, parent []int) []Column {
+	var columns []Column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+		tag, ok := field.Tag.Lookup("excel")
+		if !ok {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if field.Anonymous && ft.Kind() == reflect.Struct {
+				columns = append(columns, parseColumns(ft, index)...)
+			}
+			continue
+		}
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		column := parseTag(tag)
+		column.Field = field.Name
+		if column.Title == "" {
+			column.Title = field.Name
+		}
+		column.index = index
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// parseTag 解析 excel 标签
+func parseTag(tag string) Column {
+	var column Column
+	parts := strings.Split(tag, ",")
+	column.Title = strings.TrimSpace(parts[0])
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "width":
+			column.Width, _ = strconv.ParseFloat(value, 64)
+		case "time":
+			column.TimeFormat = value
+		case "enum":
+			column.Enum = make(map[string]string)
+			for _, item := range strings.Split(value, "|") {
+				k, v, _ := strings.Cut(item, ":")
+				column.Enum[k] = v
+			}
+		case "required":
+			column.Required = true
+		}
+	}
+	return column
+}
+
+// fieldValue 获取行数据中该列的值
+func (col *Column) fieldValue(row reflect.Value) (reflect.Value, bool) {
+	for row.Kind() == reflect.Ptr {
+		if row.IsNil() {
+			return reflect.Value{}, false
+		}
+		row = row.Elem()
+	}
+	if row.Kind() == reflect.Map {
+		v := row.MapIndex(reflect.ValueOf(col.Field))
+		return v, v.IsValid()
+	}
+	if row.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	if col.index == nil {
+		f, ok := row.Type().FieldByName(col.Field)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		col.index = f.Index
+	}
+	v, err := row.FieldByIndexErr(col.index)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// cellValue 获取导出时单元格的值
+func (col *Column) cellValue(row any) any {
+	var value any
+	if col.Value != nil {
+		value = col.Value(row)
+	} else {
+		v, ok := col.fieldValue(reflect.ValueOf(row))
+		if !ok {
+			return nil
+		}
+		value = v.Interface()
+	}
+	return col.format(value)
+}
+
+// format 转换导出值，处理枚举、时间及指针
+func (col *Column) format(value any) any {
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+		value = v.Interface()
+	}
+	if col.Enum != nil {
+		if text, ok := col.Enum[fmt.Sprint(value)]; ok {
+			return text
+		}
+	}
+	switch val := value.(type) {
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		layout := col.TimeFormat
+		if layout == "" {
+			layout = DefaultTimeFormat
+		}
+		return val.Format(layout)
+	case fmt.Stringer:
+		return val.String()
+	}
+	return value
+}
+
+// setValue 将导入的文本设置到结构体字段
+func (col *Column) setValue(field reflect.Value, text string) error {
+	if col.Enum != nil {
+		for k, v := range col.Enum {
+			if v == text {
+				text = k
+				break
+			}
+		}
+	}
+	if text == "" {
+		return nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := col.setValue(ptr.Elem(), text); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := parseTime(text, col.TimeFormat)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	conv := helper.Convert{Value: text}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		b, err := conv.ToBoolE()
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("%s 不是有效的整数", text)
+		}
+		field.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s 不是有效的非负整数", text)
+		}
+		field.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("%s 不是有效的数字", text)
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("不支持的字段类型: %s", field.Type())
+	}
+	return nil
+}
+
+// parseTime 解析时间，支持指定格式、常见格式及Excel日期序列号
+func parseTime(text, layout string) (time.Time, error) {
+	layouts := []string{DefaultTimeFormat, "2006-01-02 15:04", "2006-01-02", "2006/01/02 15:04:05", "2006/01/02", time.RFC3339}
+	if layout != "" {
+		layouts = append([]string{layout}, layouts...)
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, text, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(text, 64); err == nil {
+		return SerialToTime(serial), nil
+	}
+	return time.Time{}, fmt.Errorf("%s 不是有效的时间", text)
+}
+
+// SerialToTime 将Excel日期序列号转换为时间（1900日期系统）
+func SerialToTime(serial float64) time.Time {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.Local)
+	days := int(serial)
+	seconds := math.Round((serial - float64(days)) * 86400)
+	return epoch.AddDate(0, 0, days).Add(time.Duration(seconds) * time.Second)
+}
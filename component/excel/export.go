@@ -0,0 +1,77 @@
+package excel
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// ExportQuery 分批查询并写入当前工作表，适用于大数据量导出，内存占用仅与 batchSize 有关
+// model 为查询结果的结构体（或其指针），batchSize 小于等于0时为1000
+//
+// 示例:
+//
+//	query := db.Model(&User{}).Where("status = ?", 1).Order("id")
+//	err := excel.Download(c, "用户列表.xlsx", func(w *excel.Writer) error {
+//	    if err := w.NewSheet("用户", excel.Columns(User{})); err != nil {
+//	        return err
+//	    }
+//	    return excel.ExportQuery(w, query, User{}, 2000)
+//	})
+func ExportQuery(w *Writer, query *gorm.DB, model any, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	t := reflect.TypeOf(model)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if w.buf == nil {
+		if err := w.NewSheet("Sheet1", Columns(model)); err != nil {
+			return err
+		}
+	}
+
+	batch := reflect.New(reflect.SliceOf(t))
+	var writeErr error
+	result := query.FindInBatches(batch.Interface(), batchSize, func(tx *gorm.DB, _ int) error {
+		if writeErr = w.WriteAll(batch.Elem().Interface()); writeErr != nil {
+			return writeErr
+		}
+		return nil
+	})
+	if writeErr != nil {
+		return writeErr
+	}
+	return result.Error
+}
+
+// Download 以附件形式流式输出XLSX，fn 中写入数据，完成后自动关闭 Writer
+// 开始写入后响应头已发送，出错时只能中断输出并记录日志
+func Download(c *gin.Context, filename string, fn func(w *Writer) error, opts ...Options) error {
+	if !strings.HasSuffix(strings.ToLower(filename), ".xlsx") {
+		filename += ".xlsx"
+	}
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`,
+		strings.ReplaceAll(url.QueryEscape(filename), "+", "%20"), url.PathEscape(filename)))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	w := NewWriter(c.Writer, opts...)
+	err := fn(w)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Printf("excel: 导出 %s 失败: %v", filename, err)
+		_ = c.Error(err)
+		c.Abort()
+	}
+	return err
+}
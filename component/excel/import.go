@@ -0,0 +1,248 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// ErrStop 在导入回调中返回时立即停止导入，不记录为失败行
+var ErrStop = errors.New("excel: 停止导入")
+
+// ImportOptions 导入配置
+type ImportOptions struct {
+	Sheet       string   // 工作表名称，为空时读取第一个工作表
+	HeaderRow   int      // 表头所在行号，默认为1，之前的行会被忽略
+	Columns     []Column // 列定义，为空时根据结构体的 excel 标签解析
+	StopOnError bool     // 遇到错误行时是否停止导入
+	MaxErrors   int      // 最多记录的错误行数，超出时停止导入，默认100
+}
+
+// RowError 行错误
+type RowError struct {
+	Row    int    `json:"row"`    // 行号
+	Column string `json:"column"` // 列标题，行级错误时为空
+	Err    error  `json:"-"`
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("第%d行: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("第%d行[%s]: %v", e.Row, e.Column, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Total   int        `json:"total"`   // 数据行数
+	Success int        `json:"success"` // 成功行数
+	Failed  int        `json:"failed"`  // 失败行数
+	Errors  []RowError `json:"errors"`  // 错误详情
+}
+
+// Messages 获取所有错误信息，便于直接返回给前端
+func (r *ImportResult) Messages() []string {
+	messages := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		messages[i] = e.Error()
+	}
+	return messages
+}
+
+// Import 从数据源导入数据，表头按列标题（或字段名）匹配，每行转换为 T 后调用 fn
+// fn 用于行级校验及保存，返回错误时该行记为失败，返回 ErrStop 时停止导入
+//
+// 示例:
+//
+//	r, _ := excel.OpenFile("./users.xlsx")
+//	defer r.Close()
+//	result, err := excel.Import(r, func(row int, user *User) error {
+//	    if !validator.IsMobile(user.Mobile) {
+//	        return errors.New("手机号格式不正确")
+//	    }
+//	    return db.Create(user).Error
+//	})
+func Import[T any](src Source, fn func(row int, item *T) error, opts ...ImportOptions) (*ImportResult, error) {
+	var opt ImportOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.HeaderRow <= 0 {
+		opt.HeaderRow = 1
+	}
+	if opt.MaxErrors <= 0 {
+		opt.MaxErrors = 100
+	}
+	if opt.Columns == nil {
+		var zero T
+		opt.Columns = Columns(zero)
+	}
+	if len(opt.Columns) == 0 {
+		return nil, errors.New("excel: 没有可导入的列，请检查结构体的 excel 标签")
+	}
+
+	result := &ImportResult{}
+	var mapping []int // 单元格索引 => 列定义索引
+	err := src.Each(opt.Sheet, func(row int, cells []string) error {
+		if row < opt.HeaderRow {
+			return nil
+		}
+		if mapping == nil {
+			var err error
+			if mapping, err = mapHeader(cells, opt.Columns); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		result.Total++
+		var item T
+		rowErrs := fillRow(reflect.ValueOf(&item).Elem(), row, cells, mapping, opt.Columns)
+		if len(rowErrs) == 0 {
+			if err := fn(row, &item); err != nil {
+				if errors.Is(err, ErrStop) {
+					result.Total--
+					return ErrStop
+				}
+				rowErrs = append(rowErrs, RowError{Row: row, Err: err})
+			}
+		}
+		if len(rowErrs) == 0 {
+			result.Success++
+			return nil
+		}
+
+		result.Failed++
+		for _, e := range rowErrs {
+			if len(result.Errors) >= opt.MaxErrors {
+				return fmt.Errorf("excel: 错误行过多，已停止导入")
+			}
+			result.Errors = append(result.Errors, e)
+		}
+		if opt.StopOnError {
+			return ErrStop
+		}
+		return nil
+	})
+	if errors.Is(err, ErrStop) {
+		err = nil
+	}
+	if err == nil && mapping == nil {
+		err = errors.New("excel: 文件中没有表头")
+	}
+	return result, err
+}
+
+// ImportFile 根据扩展名从 .xlsx 或 .csv 文件导入
+func ImportFile[T any](filename string, fn func(row int, item *T) error, opts ...ImportOptions) (*ImportResult, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return Import(CSV(f), fn, opts...)
+	case ".xlsx":
+		r, err := OpenFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return Import(r, fn, opts...)
+	default:
+		return nil, fmt.Errorf("excel: 不支持的文件格式 %s，仅支持 xlsx、csv", filepath.Ext(filename))
+	}
+}
+
+// ImportUpload 从上传的 .xlsx 或 .csv 文件导入，field 为表单字段名
+func ImportUpload[T any](c *gin.Context, field string, fn func(row int, item *T) error, opts ...ImportOptions) (*ImportResult, error) {
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".csv":
+		return Import(CSV(f), fn, opts...)
+	case ".xlsx":
+		r, err := OpenReader(f, header.Size)
+		if err != nil {
+			return nil, err
+		}
+		return Import(r, fn, opts...)
+	default:
+		return nil, fmt.Errorf("excel: 不支持的文件格式 %s，仅支持 xlsx、csv", filepath.Ext(header.Filename))
+	}
+}
+
+// mapHeader 根据表头匹配列定义，缺少必填列时返回错误
+func mapHeader(header []string, columns []Column) ([]int, error) {
+	mapping := make([]int, len(header))
+	found := make([]bool, len(columns))
+	for i, title := range header {
+		mapping[i] = -1
+		title = strings.TrimSpace(title)
+		for j, col := range columns {
+			if !found[j] && (title == col.Title || strings.EqualFold(title, col.Field)) {
+				mapping[i], found[j] = j, true
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for j, col := range columns {
+		if col.Required && !found[j] {
+			missing = append(missing, col.Title)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("excel: 缺少必填列 %s", strings.Join(missing, "、"))
+	}
+	return mapping, nil
+}
+
+// fillRow 将一行数据填充到结构体
+func fillRow(item reflect.Value, row int, cells []string, mapping []int, columns []Column) []RowError {
+	var errs []RowError
+	filled := make([]bool, len(columns))
+	for i, text := range cells {
+		if i >= len(mapping) || mapping[i] < 0 {
+			continue
+		}
+		col := &columns[mapping[i]]
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		filled[mapping[i]] = true
+		field, ok := col.fieldValue(item)
+		if !ok || !field.CanSet() {
+			continue
+		}
+		if err := col.setValue(field, text); err != nil {
+			errs = append(errs, RowError{Row: row, Column: col.Title, Err: err})
+		}
+	}
+	for j, col := range columns {
+		if col.Required && !filled[j] {
+			errs = append(errs, RowError{Row: row, Column: col.Title, Err: errors.New("不能为空")})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,392 @@
+package excel
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RowFunc 逐行读取的回调，row 为行号（从1开始），cells 为该行的单元格文本，返回错误时停止读取
+type RowFunc func(row int, cells []string) error
+
+// Source 可逐行读取的数据源
+type Source interface {
+	// Each 逐行读取工作表，sheet 为空时读取第一个工作表，CSV忽略该参数
+	Each(sheet string, fn RowFunc) error
+}
+
+// Reader XLSX读取器，逐行解析工作表，不会将整个工作表加载到内存
+//
+// 示例:
+//
+//	r, err := excel.OpenFile("./users.xlsx")
+//	if err != nil {
+//	    return err
+//	}
+//	defer r.Close()
+//	err = r.Each("", func(row int, cells []string) error {
+//	    fmt.Println(row, cells)
+//	    return nil
+//	})
+type Reader struct {
+	zr     *zip.Reader
+	closer io.Closer
+	shared []string
+	sheets []sheetInfo
+}
+
+type sheetInfo struct {
+	name string
+	path string
+}
+
+// OpenFile 打开XLSX文件
+func OpenFile(filename string) (*Reader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	r, err := OpenReader(f, info.Size())
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+// OpenReader 从 io.ReaderAt 读取XLSX，如上传的 multipart.File
+func OpenReader(ra io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, fmt.Errorf("excel: 不是有效的xlsx文件: %w", err)
+	}
+	r := &Reader{zr: zr}
+	if err = r.loadSheets(); err != nil {
+		return nil, err
+	}
+	if err = r.loadSharedStrings(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Sheets 获取所有工作表名称
+func (r *Reader) Sheets() []string {
+	names := make([]string, len(r.sheets))
+	for i, s := range r.sheets {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Close 关闭文件
+func (r *Reader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// Each 逐行读取工作表，sheet 为空时读取第一个工作表，空行会被跳过
+func (r *Reader) Each(sheet string, fn RowFunc) error {
+	var target *sheetInfo
+	for i := range r.sheets {
+		if sheet == "" || r.sheets[i].name == sheet {
+			target = &r.sheets[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("excel: 工作表 %s 不存在", sheet)
+	}
+
+	f, err := r.open(target.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := xml.NewDecoder(bufio.NewReader(f))
+	var (
+		cells    []string
+		rowNum   int
+		col      int
+		cellType string
+		text     strings.Builder
+		inValue  bool
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				cells = nil
+				rowNum++
+				if v := attr(t, "r"); v != "" {
+					rowNum, _ = strconv.Atoi(v)
+				}
+			case "c":
+				col = len(cells)
+				if ref := attr(t, "r"); ref != "" {
+					col = columnIndex(ref)
+				}
+				cellType = attr(t, "t")
+				text.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.CharData:
+			if inValue {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				for len(cells) <= col {
+					cells = append(cells, "")
+				}
+				cells[col] = r.cellText(cellType, text.String())
+			case "row":
+				if !isEmptyRow(cells) {
+					if err := fn(rowNum, cells); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// cellText 根据单元格类型获取文本
+func (r *Reader) cellText(cellType, value string) string {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(value)
+		if err == nil && i >= 0 && i < len(r.shared) {
+			return r.shared[i]
+		}
+		return ""
+	case "b":
+		if value == "1" {
+			return "true"
+		}
+		return "false"
+	}
+	return value
+}
+
+// loadSheets 读取工作表列表
+func (r *Reader) loadSheets() error {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := r.decode("xl/workbook.xml", &workbook); err != nil {
+		return err
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := r.decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return err
+	}
+
+	for _, s := range workbook.Sheets {
+		for _, rel := range rels.Relationships {
+			if rel.ID != s.ID {
+				continue
+			}
+			target := rel.Target
+			if strings.HasPrefix(target, "/") {
+				target = strings.TrimPrefix(target, "/")
+			} else {
+				target = path.Join("xl", target)
+			}
+			r.sheets = append(r.sheets, sheetInfo{name: s.Name, path: target})
+		}
+	}
+	if len(r.sheets) == 0 {
+		return errors.New("excel: 文件中没有工作表")
+	}
+	return nil
+}
+
+// loadSharedStrings 读取共享字符串表
+func (r *Reader) loadSharedStrings() error {
+	f, err := r.open("xl/sharedStrings.xml")
+	if err != nil {
+		// 没有共享字符串表是合法的
+		return nil
+	}
+	defer f.Close()
+
+	decoder := xml.NewDecoder(bufio.NewReader(f))
+	var (
+		text    strings.Builder
+		inText  bool
+		inPhone bool
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				text.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				inPhone = true
+			}
+		case xml.CharData:
+			if inText && !inPhone {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "rPh":
+				inPhone = false
+			case "si":
+				r.shared = append(r.shared, text.String())
+			}
+		}
+	}
+}
+
+func (r *Reader) open(name string) (io.ReadCloser, error) {
+	for _, f := range r.zr.File {
+		if strings.EqualFold(f.Name, name) {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("excel: 文件中缺少 %s", name)
+}
+
+func (r *Reader) decode(name string, v any) error {
+	f, err := r.open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return xml.NewDecoder(f).Decode(v)
+}
+
+// csvSource CSV数据源
+type csvSource struct {
+	r io.Reader
+}
+
+// CSV 创建CSV数据源，自动去除UTF-8 BOM，非UTF-8编码时按GB18030（兼容GBK）解码
+func CSV(r io.Reader) Source {
+	return csvSource{r: r}
+}
+
+// Each 逐行读取CSV，空行会被跳过
+func (s csvSource) Each(_ string, fn RowFunc) error {
+	br := bufio.NewReaderSize(s.r, 64*1024)
+	peek, _ := br.Peek(4096)
+	var reader io.Reader = br
+	if bytes.HasPrefix(peek, []byte("\xEF\xBB\xBF")) {
+		_, _ = br.Discard(3)
+	} else if !validUTF8Prefix(peek) {
+		reader = simplifiedchinese.GB18030.NewDecoder().Reader(br)
+	}
+
+	cr := csv.NewReader(reader)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	row := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row++
+		if isEmptyRow(record) {
+			continue
+		}
+		if err = fn(row, record); err != nil {
+			return err
+		}
+	}
+}
+
+// validUTF8Prefix 判断数据是否为UTF-8编码，忽略末尾被截断的字符
+func validUTF8Prefix(data []byte) bool {
+	for i := 0; i < 3 && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	return utf8.Valid(data)
+}
+
+// columnIndex 将单元格引用转换为列索引（从0开始），如 B3 => 1
+func columnIndex(ref string) int {
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		n = n*26 + int(c-'A'+1)
+	}
+	return n - 1
+}
+
+func attr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func isEmptyRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
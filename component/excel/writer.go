@@ -0,0 +1,391 @@
+package excel
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxRows 单个工作表的最大行数
+const MaxRows = 1048576
+
+// maxCellLength 单元格最大字符数
+const maxCellLength = 32767
+
+// Options 导出配置
+type Options struct {
+	MaxRows      int    // 单个工作表的最大行数（含表头），超出时自动续写到新的工作表，默认 MaxRows
+	HeaderFill   string // 表头背景色，RGB十六进制，默认 F2F2F2
+	FreezeHeader bool   // 是否冻结表头
+}
+
+// Writer 流式XLSX写入器，数据逐行写入压缩包，内存占用与数据量无关
+//
+// 示例:
+//
+//	w := excel.NewWriter(file, excel.Options{FreezeHeader: true})
+//	_ = w.NewSheet("用户", excel.Columns(User{}))
+//	for _, user := range users {
+//	    _ = w.Write(user)
+//	}
+//	err := w.Close()
+type Writer struct {
+	opts Options
+	zw   *zip.Writer
+
+	sheets  []string
+	columns []Column
+	buf     *bufio.Writer
+	name    string // 当前工作表的基础名称，续写时追加序号
+	part    int    // 当前工作表的续写序号
+	rows    int    // 当前工作表已写入的行数
+	err     error
+	closed  bool
+}
+
+// NewWriter 创建流式XLSX写入器，写入完成后必须调用 Close
+func NewWriter(w io.Writer, opts ...Options) *Writer {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxRows <= 1 || opt.MaxRows > MaxRows {
+		opt.MaxRows = MaxRows
+	}
+	if opt.HeaderFill == "" {
+		opt.HeaderFill = "F2F2F2"
+	}
+	return &Writer{opts: opt, zw: zip.NewWriter(w)}
+}
+
+// NewSheet 开始新的工作表并写入表头，columns 为空时不写表头，通过 Write 写入的行需为 []any
+func (w *Writer) NewSheet(name string, columns []Column) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return errors.New("excel: writer已关闭")
+	}
+	w.name, w.part, w.columns = name, 1, columns
+	return w.startSheet(name)
+}
+
+// Write 写入一行，row 可以是结构体、结构体指针、map（按列的 Field 取值）或 []any（按顺序写入）
+func (w *Writer) Write(row any) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.buf == nil {
+		if err := w.NewSheet("Sheet1", Columns(row)); err != nil {
+			return err
+		}
+	}
+
+	var values []any
+	if list, ok := row.([]any); ok {
+		values = list
+	} else {
+		values = make([]any, len(w.columns))
+		for i := range w.columns {
+			values[i] = w.columns[i].cellValue(row)
+		}
+	}
+
+	if w.rows >= w.opts.MaxRows {
+		w.part++
+		if err := w.startSheet(fmt.Sprintf("%s(%d)", w.name, w.part)); err != nil {
+			return err
+		}
+	}
+	return w.writeRow(values, 0)
+}
+
+// WriteAll 写入切片中的所有行
+func (w *Writer) WriteAll(rows any) error {
+	v := reflect.ValueOf(rows)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("excel: WriteAll 需要切片，实际为 %T", rows)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := w.Write(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 结束当前工作表并写入工作簿信息
+func (w *Writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		_ = w.zw.Close()
+		return w.err
+	}
+	if len(w.sheets) == 0 {
+		if err := w.startSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", w.workbook()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+		{"xl/styles.xml", fmt.Sprintf(stylesXML, w.opts.HeaderFill)},
+	}
+	for _, f := range files {
+		fw, err := w.create(f.name)
+		if err == nil {
+			_, err = io.WriteString(fw, f.content)
+		}
+		if err != nil {
+			w.err = err
+			_ = w.zw.Close()
+			return err
+		}
+	}
+	w.err = w.zw.Close()
+	return w.err
+}
+
+// startSheet 结束上一个工作表并开始新的工作表
+func (w *Writer) startSheet(name string) error {
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	name = w.uniqueSheetName(name)
+	fw, err := w.create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(w.sheets)+1))
+	if err != nil {
+		w.err = err
+		return err
+	}
+	w.sheets = append(w.sheets, name)
+	w.buf = bufio.NewWriterSize(fw, 64*1024)
+	w.rows = 0
+
+	w.buf.WriteString(xml.Header)
+	w.buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	if w.opts.FreezeHeader && len(w.columns) > 0 {
+		w.buf.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	var cols strings.Builder
+	for i, col := range w.columns {
+		if col.Width > 0 {
+			_, _ = fmt.Fprintf(&cols, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(col.Width, 'f', -1, 64))
+		}
+	}
+	if cols.Len() > 0 {
+		w.buf.WriteString("<cols>" + cols.String() + "</cols>")
+	}
+	w.buf.WriteString("<sheetData>")
+
+	if len(w.columns) > 0 {
+		header := make([]any, len(w.columns))
+		for i, col := range w.columns {
+			header[i] = col.Title
+		}
+		return w.writeRow(header, 1)
+	}
+	return nil
+}
+
+// create 在压缩包中创建文件
+func (w *Writer) create(name string) (io.Writer, error) {
+	return w.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+}
+
+// endSheet 结束当前工作表
+func (w *Writer) endSheet() error {
+	if w.buf == nil {
+		return nil
+	}
+	w.buf.WriteString("</sheetData></worksheet>")
+	err := w.buf.Flush()
+	w.buf = nil
+	if err != nil {
+		w.err = err
+	}
+	return err
+}
+
+// writeRow 写入一行，style 为单元格样式索引
+func (w *Writer) writeRow(values []any, style int) error {
+	w.rows++
+	_, _ = fmt.Fprintf(w.buf, `<row r="%d">`, w.rows)
+	for i, value := range values {
+		w.writeCell(ColumnName(i+1)+strconv.Itoa(w.rows), value, style)
+	}
+	if _, err := w.buf.WriteString("</row>"); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// writeCell 写入单元格
+func (w *Writer) writeCell(ref string, value any, style int) {
+	styleAttr := ""
+	if style > 0 {
+		styleAttr = ` s="` + strconv.Itoa(style) + `"`
+	}
+
+	switch v := value.(type) {
+	case nil:
+		if style > 0 {
+			_, _ = fmt.Fprintf(w.buf, `<c r="%s"%s/>`, ref, styleAttr)
+		}
+		return
+	case bool:
+		b := "0"
+		if v {
+			b = "1"
+		}
+		_, _ = fmt.Fprintf(w.buf, `<c r="%s"%s t="b"><v>%s</v></c>`, ref, styleAttr, b)
+		return
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		_, _ = fmt.Fprintf(w.buf, `<c r="%s"%s><v>%d</v></c>`, ref, styleAttr, v)
+		return
+	case float32:
+		_, _ = fmt.Fprintf(w.buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, strconv.FormatFloat(float64(v), 'f', -1, 32))
+		return
+	case float64:
+		_, _ = fmt.Fprintf(w.buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, strconv.FormatFloat(v, 'f', -1, 64))
+		return
+	case time.Time:
+		value = v.Format(DefaultTimeFormat)
+	}
+
+	text := fmt.Sprint(value)
+	if utf8.RuneCountInString(text) > maxCellLength {
+		text = string([]rune(text)[:maxCellLength])
+	}
+	_, _ = fmt.Fprintf(w.buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, styleAttr)
+	_ = xml.EscapeText(w.buf, []byte(text))
+	w.buf.WriteString("</t></is></c>")
+}
+
+// uniqueSheetName 处理工作表名称中的非法字符、长度及重名
+func (w *Writer) uniqueSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet"
+	}
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+
+	candidate := name
+	for i := 2; ; i++ {
+		exists := false
+		for _, s := range w.sheets {
+			if strings.EqualFold(s, candidate) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			return candidate
+		}
+		suffix := fmt.Sprintf("_%d", i)
+		r := []rune(name)
+		if len(r)+len(suffix) > 31 {
+			r = r[:31-len(suffix)]
+		}
+		candidate = string(r) + suffix
+	}
+}
+
+func (w *Writer) contentTypes() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	sb.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	sb.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range w.sheets {
+		_, _ = fmt.Fprintf(&sb, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+func (w *Writer) workbook() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range w.sheets {
+		sb.WriteString(`<sheet name="`)
+		_ = xml.EscapeText(&sb, []byte(name))
+		_, _ = fmt.Fprintf(&sb, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+	}
+	sb.WriteString(`</sheets></workbook>`)
+	return sb.String()
+}
+
+func (w *Writer) workbookRels() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.sheets {
+		_, _ = fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	_, _ = fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.sheets)+1)
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+// ColumnName 将列序号（从1开始）转换为列名，如 1 => A、27 => AA
+func ColumnName(n int) string {
+	var name []byte
+	for n > 0 {
+		n--
+		name = append([]byte{byte('A' + n%26)}, name...)
+		n /= 26
+	}
+	return string(name)
+}
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// stylesXML 样式表，0为默认样式，1为表头样式（加粗、背景色、边框）
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FF%s"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="2"><border><left/><right/><top/><bottom/><diagonal/></border>` +
+	`<border><left style="thin"><color auto="1"/></left><right style="thin"><color auto="1"/></right><top style="thin"><color auto="1"/></top><bottom style="thin"><color auto="1"/></bottom><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1"/></cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,175 @@
+package geoip
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// 数据库类型
+const (
+	DriverIP2Region = "ip2region"
+	DriverGeoLite2  = "geolite2"
+)
+
+// ErrInvalidIP IP地址格式错误
+var ErrInvalidIP = errors.New("geoip: IP地址格式错误")
+
+// Location IP归属地，查询不到的字段为空
+type Location struct {
+	IP       string `json:"ip"`
+	Country  string `json:"country"`
+	Province string `json:"province"`
+	City     string `json:"city"`
+	ISP      string `json:"isp,omitempty"` // 运营商，仅 ip2region 提供
+}
+
+// String 返回以空格分隔的归属地，如 "中国 广东省 深圳市"，省市同名（直辖市）时只保留一个
+func (l Location) String() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{l.Country, l.Province, l.City} {
+		if part != "" && (len(parts) == 0 || parts[len(parts)-1] != part) {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// searcher 数据库查询接口
+type searcher interface {
+	search(ip net.IP) (Location, error)
+	close() error
+}
+
+// Locator IP归属地查询器，数据库一次性加载到内存，并发安全
+//
+// 示例:
+//
+//	locator, err := geoip.New(jcbaseGo.GeoIPStruct{DBPath: "./data/ip2region.xdb"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	loc, _ := locator.Lookup("119.29.29.29")
+//	fmt.Println(loc.String()) // 中国 广东省 深圳市
+type Locator struct {
+	Conf jcbaseGo.GeoIPStruct
+
+	searcher searcher
+	cache    *cache
+}
+
+// New 加载数据库并创建查询器
+func New(conf jcbaseGo.GeoIPStruct) (*Locator, error) {
+	_ = helper.CheckAndSetDefault(&conf)
+
+	driver := strings.ToLower(conf.Driver)
+	if driver == "" {
+		switch strings.ToLower(filepath.Ext(conf.DBPath)) {
+		case ".mmdb":
+			driver = DriverGeoLite2
+		default:
+			driver = DriverIP2Region
+		}
+	}
+
+	var (
+		s   searcher
+		err error
+	)
+	switch driver {
+	case DriverIP2Region:
+		s, err = openIP2Region(conf.DBPath)
+	case DriverGeoLite2:
+		s, err = openGeoLite2(conf.DBPath, conf.Language)
+	default:
+		return nil, fmt.Errorf("geoip: 不支持的数据库类型 %s", conf.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Locator{Conf: conf, searcher: s}
+	if conf.CacheSize > 0 {
+		l.cache = newCache(conf.CacheSize)
+	}
+	return l, nil
+}
+
+// Lookup 查询IP归属地，内网及保留地址返回空的归属地
+func (l *Locator) Lookup(ip string) (Location, error) {
+	ip = strings.TrimSpace(ip)
+	if l.cache != nil {
+		if loc, ok := l.cache.get(ip); ok {
+			return loc, nil
+		}
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{IP: ip}, ErrInvalidIP
+	}
+	loc, err := l.searcher.search(parsed)
+	if err != nil {
+		return Location{IP: ip}, err
+	}
+	loc.IP = ip
+
+	if l.cache != nil {
+		l.cache.add(ip, loc)
+	}
+	return loc, nil
+}
+
+// Close 释放数据库
+func (l *Locator) Close() error {
+	return l.searcher.close()
+}
+
+// cache 定长的LRU缓存
+type cache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key string
+	loc Location
+}
+
+func newCache(size int) *cache {
+	return &cache{size: size, ll: list.New(), items: make(map[string]*list.Element, size)}
+}
+
+func (c *cache) get(key string) (Location, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*cacheEntry).loc, true
+	}
+	return Location{}, false
+}
+
+func (c *cache) add(key string, loc Location) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*cacheEntry).loc = loc
+		return
+	}
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, loc: loc})
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,61 @@
+package geoip
+
+import (
+	"fmt"
+	"github.com/oschwald/maxminddb-golang"
+	"net"
+)
+
+// geoLite2 基于 MaxMind GeoLite2/GeoIP2 City 数据库的查询，支持IPv4及IPv6
+type geoLite2 struct {
+	reader   *maxminddb.Reader
+	language string
+}
+
+// geoLite2Record 数据库记录中需要的字段
+type geoLite2Record struct {
+	Country struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+func openGeoLite2(path, language string) (*geoLite2, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: 读取数据库失败: %w", err)
+	}
+	return &geoLite2{reader: reader, language: language}, nil
+}
+
+func (g *geoLite2) search(ip net.IP) (Location, error) {
+	var record geoLite2Record
+	if err := g.reader.Lookup(ip, &record); err != nil {
+		return Location{}, err
+	}
+	loc := Location{
+		Country: g.name(record.Country.Names),
+		City:    g.name(record.City.Names),
+	}
+	if len(record.Subdivisions) > 0 {
+		loc.Province = g.name(record.Subdivisions[0].Names)
+	}
+	return loc, nil
+}
+
+func (g *geoLite2) close() error {
+	return g.reader.Close()
+}
+
+// name 获取配置语言的地名，缺少时使用英文
+func (g *geoLite2) name(names map[string]string) string {
+	if name, ok := names[g.language]; ok {
+		return name
+	}
+	return names["en"]
+}
@@ -0,0 +1,62 @@
+package geoip
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/middleware"
+	"sync/atomic"
+)
+
+// LocationKey 在gin上下文中保存归属地的键名
+const LocationKey = "GeoLocation"
+
+var defaultLocator atomic.Pointer[Locator]
+
+// Default 获取默认查询器，未设置时为nil
+func Default() *Locator {
+	return defaultLocator.Load()
+}
+
+// SetDefault 设置默认查询器，供 Lookup 及 FromContext 使用
+func SetDefault(l *Locator) {
+	defaultLocator.Store(l)
+}
+
+// Lookup 使用默认查询器查询IP归属地，未设置默认查询器时返回空的归属地
+func Lookup(ip string) Location {
+	l := Default()
+	if l == nil {
+		return Location{IP: ip}
+	}
+	loc, _ := l.Lookup(ip)
+	return loc
+}
+
+// Middleware 查询客户端IP的归属地并保存到gin上下文中，IP通过 middleware.GetRealIP 获取
+// 需要使用CDN时请先注册 RealIP 中间件
+//
+// 示例:
+//
+//	r.Use(middleware.Base{}.RealIP(true), locator.Middleware())
+//	r.GET("/login", func(c *gin.Context) {
+//	    loc := geoip.FromContext(c)
+//	    log.Printf("登录地点: %s", loc.String())
+//	})
+func (l *Locator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loc, _ := l.Lookup(middleware.GetRealIP(c))
+		c.Set(LocationKey, loc)
+		c.Next()
+	}
+}
+
+// FromContext 获取请求的归属地，未使用中间件时使用默认查询器按需查询
+func FromContext(c *gin.Context) Location {
+	if v, ok := c.Get(LocationKey); ok {
+		if loc, ok := v.(Location); ok {
+			return loc
+		}
+	}
+	loc := Lookup(middleware.GetRealIP(c))
+	c.Set(LocationKey, loc)
+	return loc
+}
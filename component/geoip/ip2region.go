@@ -0,0 +1,91 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// ip2region xdb 文件结构：256字节头部 + 256*256 的向量索引（每项8字节）+ 数据区 + 段索引（每项14字节）
+const (
+	xdbHeaderLength       = 256
+	xdbVectorIndexCols    = 256
+	xdbVectorIndexSize    = 8
+	xdbSegmentIndexSize   = 14
+	xdbVectorIndexLength  = xdbVectorIndexCols * xdbVectorIndexCols * xdbVectorIndexSize
+	xdbMinimumContentSize = xdbHeaderLength + xdbVectorIndexLength
+)
+
+// ip2region 基于 ip2region xdb 格式的查询，仅支持IPv4
+type ip2region struct {
+	content []byte
+}
+
+// openIP2Region 将 xdb 文件完整加载到内存
+func openIP2Region(path string) (*ip2region, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: 读取数据库失败: %w", err)
+	}
+	if len(content) < xdbMinimumContentSize {
+		return nil, errors.New("geoip: 无效的 ip2region 数据库")
+	}
+	return &ip2region{content: content}, nil
+}
+
+func (r *ip2region) search(ip net.IP) (Location, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return Location{}, nil
+	}
+	value := binary.BigEndian.Uint32(ip4)
+
+	offset := xdbHeaderLength + (int(ip4[0])*xdbVectorIndexCols+int(ip4[1]))*xdbVectorIndexSize
+	sPtr := int(binary.LittleEndian.Uint32(r.content[offset:]))
+	ePtr := int(binary.LittleEndian.Uint32(r.content[offset+4:]))
+
+	low, high := 0, (ePtr-sPtr)/xdbSegmentIndexSize
+	for low <= high {
+		mid := (low + high) / 2
+		p := sPtr + mid*xdbSegmentIndexSize
+		if p+xdbSegmentIndexSize > len(r.content) {
+			return Location{}, errors.New("geoip: ip2region 数据库已损坏")
+		}
+		start := binary.LittleEndian.Uint32(r.content[p:])
+		end := binary.LittleEndian.Uint32(r.content[p+4:])
+		switch {
+		case value < start:
+			high = mid - 1
+		case value > end:
+			low = mid + 1
+		default:
+			dataLen := int(binary.LittleEndian.Uint16(r.content[p+8:]))
+			dataPtr := int(binary.LittleEndian.Uint32(r.content[p+10:]))
+			if dataPtr+dataLen > len(r.content) {
+				return Location{}, errors.New("geoip: ip2region 数据库已损坏")
+			}
+			return parseRegion(string(r.content[dataPtr : dataPtr+dataLen])), nil
+		}
+	}
+	return Location{}, nil
+}
+
+func (r *ip2region) close() error {
+	r.content = nil
+	return nil
+}
+
+// parseRegion 解析 "国家|区域|省份|城市|ISP" 格式的地区信息，0 表示未知
+func parseRegion(region string) Location {
+	fields := strings.Split(region, "|")
+	get := func(i int) string {
+		if i < len(fields) && fields[i] != "0" {
+			return fields[i]
+		}
+		return ""
+	}
+	return Location{Country: get(0), Province: get(2), City: get(3), ISP: get(4)}
+}
@@ -0,0 +1,310 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"github.com/jcbowen/jcbaseGo/middleware"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestIDMetadata 请求ID的元数据键名，与 HTTP 的 X-Request-Id 对应
+const RequestIDMetadata = "x-request-id"
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	clientIPKey
+)
+
+// RequestID 获取当前调用的请求ID
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// ClientIP 获取当前调用的客户端IP
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// OutgoingContext 将当前调用的请求ID传递给下游的 gRPC 调用
+func OutgoingContext(ctx context.Context) context.Context {
+	if id := RequestID(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, RequestIDMetadata, id)
+	}
+	return ctx
+}
+
+// AuthFunc 认证函数，返回的 context 会传递给后续的处理函数（可在其中保存用户信息）；
+// 返回的错误不是 gRPC status 时按 codes.Unauthenticated 处理
+type AuthFunc func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// ----- 拦截器 ----- /
+
+// contextInterceptor 设置请求ID及客户端IP
+func contextInterceptor(useCDN bool) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		return handler(withRequestContext(ctx, useCDN), req)
+	}
+}
+
+func contextStreamInterceptor(useCDN bool) grpclib.StreamServerInterceptor {
+	return func(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withRequestContext(ss.Context(), useCDN)})
+	}
+}
+
+// withRequestContext 沿用或生成请求ID，并通过响应头返回给客户端
+func withRequestContext(ctx context.Context, useCDN bool) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := first(md, RequestIDMetadata)
+	if requestID == "" || len(requestID) > 64 {
+		requestID = strconv.FormatInt(time.Now().UnixNano(), 36) + helper.Random(10)
+	}
+	_ = grpclib.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, requestID))
+
+	clientIP := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		clientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			clientIP = host
+		}
+	}
+	// 与 middleware.RealIP 一致，使用CDN或代理时从 x-forwarded-for、x-real-ip 中读取
+	if useCDN {
+		if forwarded := first(md, "x-forwarded-for"); forwarded != "" {
+			clientIP = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		} else if realIP := first(md, "x-real-ip"); realIP != "" {
+			clientIP = realIP
+		}
+	}
+
+	ctx = context.WithValue(ctx, requestIDKey, requestID)
+	return context.WithValue(ctx, clientIPKey, clientIP)
+}
+
+// recoveryInterceptor 捕获panic，返回 codes.Internal 并调用 middleware 注册的panic报告钩子
+func recoveryInterceptor() grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = handlePanic(ctx, info.FullMethod, recovered)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func recoveryStreamInterceptor() grpclib.StreamServerInterceptor {
+	return func(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = handlePanic(ss.Context(), info.FullMethod, recovered)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func handlePanic(ctx context.Context, method string, recovered any) error {
+	stack := string(debug.Stack())
+	report := middleware.PanicReport{
+		Time:      time.Now().Format("2006-01-02 15:04:05"),
+		RequestID: RequestID(ctx),
+		ClientIP:  ClientIP(ctx),
+		Method:    "GRPC",
+		URL:       method,
+		Error:     fmt.Sprint(recovered),
+		Stack:     stack,
+	}
+	log.Printf("[Recovery] grpc panic recovered: %s\n%s", report.Error, stack)
+	middleware.ReportPanic(report)
+	return status.Error(codes.Internal, "服务器内部错误")
+}
+
+// authInterceptor 认证，skip 中的方法跳过认证
+func authInterceptor(auth AuthFunc, skip map[string]bool) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		if skip[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		newCtx, err := auth(ctx, info.FullMethod)
+		if err != nil {
+			return nil, authError(err)
+		}
+		return handler(newCtx, req)
+	}
+}
+
+func authStreamInterceptor(auth AuthFunc, skip map[string]bool) grpclib.StreamServerInterceptor {
+	return func(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		if skip[info.FullMethod] {
+			return handler(srv, ss)
+		}
+		newCtx, err := auth(ss.Context(), info.FullMethod)
+		if err != nil {
+			return authError(err)
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: newCtx})
+	}
+}
+
+func authError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Unauthenticated, err.Error())
+}
+
+// errorInterceptor 将 errcode.AppError 转换为 gRPC status
+func errorInterceptor() grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, ToStatus(err)
+	}
+}
+
+func errorStreamInterceptor() grpclib.StreamServerInterceptor {
+	return func(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		return ToStatus(handler(srv, ss))
+	}
+}
+
+// ToStatus 将错误转换为 gRPC status：已是 status 的原样返回，errcode.AppError 按其HTTP状态码映射，
+// 其余错误返回 codes.Unknown 且不输出原始错误信息
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var appErr *errcode.AppError
+	if !errors.As(err, &appErr) {
+		return status.Error(codes.Unknown, errcode.Text(errcode.Unknown))
+	}
+	return status.Error(httpToCode(appErr.HTTPStatus()), appErr.Message)
+}
+
+// httpToCode HTTP状态码对应的 gRPC 状态码
+func httpToCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	default:
+		if httpStatus >= 500 {
+			return codes.Internal
+		}
+		return codes.FailedPrecondition
+	}
+}
+
+// AccessLogEntry gRPC 访问日志条目
+type AccessLogEntry struct {
+	Time      string  `json:"time"`       // 请求时间
+	RequestID string  `json:"request_id"` // 请求ID
+	ClientIP  string  `json:"client_ip"`  // 客户端IP
+	Method    string  `json:"method"`     // 完整方法名，如 /user.v1.UserService/Get
+	Code      string  `json:"code"`       // gRPC 状态码
+	Latency   float64 `json:"latency_ms"` // 耗时，单位毫秒
+	UserAgent string  `json:"user_agent"` // UA
+	Error     string  `json:"error,omitempty"`
+}
+
+// accessLogInterceptor 输出JSON格式的访问日志，格式与 middleware.AccessLog 相近
+func accessLogInterceptor(writer io.Writer) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		writeAccessLog(writer, ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func accessLogStreamInterceptor(writer io.Writer) grpclib.StreamServerInterceptor {
+	return func(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		writeAccessLog(writer, ss.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
+
+func writeAccessLog(writer io.Writer, ctx context.Context, method string, start time.Time, err error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	entry := AccessLogEntry{
+		Time:      start.Format("2006-01-02 15:04:05"),
+		RequestID: RequestID(ctx),
+		ClientIP:  ClientIP(ctx),
+		Method:    method,
+		Code:      status.Code(err).String(),
+		Latency:   float64(time.Since(start).Microseconds()) / 1000,
+		UserAgent: first(md, "user-agent"),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Println("访问日志序列化失败:", err)
+		return
+	}
+	if _, err = fmt.Fprintln(writer, string(line)); err != nil {
+		log.Println("访问日志写入失败:", err)
+	}
+}
+
+// wrappedStream 替换流的 context
+type wrappedStream struct {
+	grpclib.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+// first 获取元数据的第一个值
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,241 @@
+// Package grpc gRPC 服务封装
+//
+// 内置与 gin 中间件对应的拦截器：请求ID、真实IP、访问日志、panic恢复（复用 middleware 注册的报告钩子）、
+// errcode.AppError 到 gRPC 状态码的转换及认证；自动注册标准健康检查服务，并支持优雅关闭。
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/health"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"io"
+	"log"
+	"net"
+	"time"
+)
+
+// Options 服务配置
+type Options struct {
+	Addr     string // 监听地址，默认 :9090
+	CertPath string // 证书路径，与 KeyPath 同时配置时启用TLS
+	KeyPath  string // 私钥路径
+
+	UseCDN     bool             // 是否从 x-forwarded-for、x-real-ip 元数据读取客户端IP（经过网关或代理时开启）
+	AccessLog  io.Writer        // 访问日志输出，为nil时不记录
+	Auth       AuthFunc         // 认证函数，为nil时不认证
+	AuthSkip   []string         // 跳过认证的完整方法名，健康检查及反射服务始终跳过
+	Health     *health.Registry // 健康检查注册表，设置后标准健康检查服务按其就绪检查结果返回状态
+	Reflection bool             // 是否注册反射服务（供 grpcurl 等工具使用）
+
+	ShutdownTimeout time.Duration // 优雅关闭的最长等待时间，默认10秒，超时后强制关闭
+
+	UnaryInterceptors  []grpclib.UnaryServerInterceptor  // 追加在内置拦截器之后
+	StreamInterceptors []grpclib.StreamServerInterceptor // 追加在内置拦截器之后
+	ServerOptions      []grpclib.ServerOption            // 其他 gRPC 服务参数
+}
+
+// Server gRPC 服务，实现了 grpc.ServiceRegistrar，可直接传给生成代码的 RegisterXxxServer
+//
+// 示例:
+//
+//	srv, err := grpc.New(grpc.Options{
+//		Addr:      ":9090",
+//		AccessLog: os.Stdout,
+//		Auth: func(ctx context.Context, method string) (context.Context, error) {
+//			uid, err := checkToken(ctx)
+//			return context.WithValue(ctx, uidKey, uid), err
+//		},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	userpb.RegisterUserServiceServer(srv, &userService{})
+//	go func() { _ = srv.Run() }()
+//	// 收到退出信号后
+//	_ = srv.Shutdown(context.Background())
+type Server struct {
+	opt    Options
+	server *grpclib.Server
+	health *grpchealth.Server // 未设置 Options.Health 时使用的状态健康检查服务
+}
+
+// New 创建 gRPC 服务
+func New(opts ...Options) (*Server, error) {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Addr == "" {
+		opt.Addr = ":9090"
+	}
+	if opt.ShutdownTimeout <= 0 {
+		opt.ShutdownTimeout = 10 * time.Second
+	}
+
+	unary := []grpclib.UnaryServerInterceptor{contextInterceptor(opt.UseCDN)}
+	stream := []grpclib.StreamServerInterceptor{contextStreamInterceptor(opt.UseCDN)}
+	if opt.AccessLog != nil {
+		unary = append(unary, accessLogInterceptor(opt.AccessLog))
+		stream = append(stream, accessLogStreamInterceptor(opt.AccessLog))
+	}
+	unary = append(unary, errorInterceptor(), recoveryInterceptor())
+	stream = append(stream, errorStreamInterceptor(), recoveryStreamInterceptor())
+	if opt.Auth != nil {
+		skip := map[string]bool{
+			healthpb.Health_Check_FullMethodName:                             true,
+			healthpb.Health_Watch_FullMethodName:                             true,
+			"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      true,
+			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": true,
+		}
+		for _, method := range opt.AuthSkip {
+			skip[method] = true
+		}
+		unary = append(unary, authInterceptor(opt.Auth, skip))
+		stream = append(stream, authStreamInterceptor(opt.Auth, skip))
+	}
+	unary = append(unary, opt.UnaryInterceptors...)
+	stream = append(stream, opt.StreamInterceptors...)
+
+	serverOpts := []grpclib.ServerOption{
+		grpclib.ChainUnaryInterceptor(unary...),
+		grpclib.ChainStreamInterceptor(stream...),
+	}
+	if opt.CertPath != "" && opt.KeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(opt.CertPath, opt.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("加载证书和私钥失败: %v", err)
+		}
+		serverOpts = append(serverOpts, grpclib.Creds(credentials.NewTLS(&tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{cert},
+		})))
+	}
+	serverOpts = append(serverOpts, opt.ServerOptions...)
+
+	s := &Server{opt: opt, server: grpclib.NewServer(serverOpts...)}
+	if opt.Health != nil {
+		healthpb.RegisterHealthServer(s.server, &registryHealth{registry: opt.Health})
+	} else {
+		s.health = grpchealth.NewServer()
+		healthpb.RegisterHealthServer(s.server, s.health)
+	}
+	if opt.Reflection {
+		reflection.Register(s.server)
+	}
+	return s, nil
+}
+
+// RegisterService 注册服务，实现 grpc.ServiceRegistrar
+func (s *Server) RegisterService(desc *grpclib.ServiceDesc, impl any) {
+	s.server.RegisterService(desc, impl)
+}
+
+// GRPC 获取原始的 grpc.Server
+func (s *Server) GRPC() *grpclib.Server {
+	return s.server
+}
+
+// SetServingStatus 设置服务的健康状态，service 为空表示整体状态；设置了 Options.Health 时无效
+func (s *Server) SetServingStatus(service string, serving bool) {
+	if s.health == nil {
+		return
+	}
+	st := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		st = healthpb.HealthCheckResponse_SERVING
+	}
+	s.health.SetServingStatus(service, st)
+}
+
+// Run 监听 Options.Addr 并启动服务，阻塞直到服务关闭，调用 Shutdown 关闭时返回nil
+func (s *Server) Run() error {
+	lis, err := net.Listen("tcp", s.opt.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(lis)
+}
+
+// Serve 在指定的监听器上启动服务
+func (s *Server) Serve(lis net.Listener) error {
+	log.Printf("grpc服务启动，监听地址：%s\n", lis.Addr())
+	err := s.server.Serve(lis)
+	if errors.Is(err, grpclib.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown 优雅关闭：先将健康状态设为不可用，再等待进行中的调用完成，超过 ShutdownTimeout 或 ctx 结束时强制关闭
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.health != nil {
+		s.health.Shutdown()
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.opt.ShutdownTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// registryHealth 基于 health.Registry 的标准健康检查服务，只支持整体状态（service 为空）
+type registryHealth struct {
+	healthpb.UnimplementedHealthServer
+	registry *health.Registry
+}
+
+func (h *registryHealth) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.GetService() != "" {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: h.status(ctx)}, nil
+}
+
+// Watch 每5秒检查一次，状态变化时推送
+func (h *registryHealth) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if req.GetService() != "" {
+		return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVICE_UNKNOWN})
+	}
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		if st := h.status(stream.Context()); st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *registryHealth) status(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	if h.registry.Check(ctx).Status == health.StatusUp {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
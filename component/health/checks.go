@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"net"
+	"time"
+)
+
+// DB 数据库连接检查，适用于mysql及sqlite
+func DB(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		if db == nil {
+			return errors.New("数据库未连接")
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Redis redis连接检查
+func Redis(client *redis.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		if client == nil {
+			return errors.New("redis未连接")
+		}
+		return client.Ping(ctx).Err()
+	}
+}
+
+// TCP 检查地址是否可以建立TCP连接
+func TCP(addr string) CheckFunc {
+	return func(ctx context.Context) error {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// DiskSpace 检查path所在磁盘的可用空间不少于minFree字节
+func DiskSpace(path string, minFree uint64) CheckFunc {
+	return func(ctx context.Context) error {
+		free, err := diskFree(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("磁盘可用空间不足: %d MB < %d MB", free>>20, minFree>>20)
+		}
+		return nil
+	}
+}
+
+// QueueDepth 检查队列积压数量不超过max，depth为获取当前积压数量的函数
+func QueueDepth(depth func(ctx context.Context) (int64, error), max int64) CheckFunc {
+	return func(ctx context.Context) error {
+		n, err := depth(ctx)
+		if err != nil {
+			return err
+		}
+		if n > max {
+			return fmt.Errorf("队列积压过多: %d > %d", n, max)
+		}
+		return nil
+	}
+}
+
+// Heartbeat 检查最近一次心跳时间未超过maxAge，适用于后台任务、消费者等
+func Heartbeat(last func() time.Time, maxAge time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		t := last()
+		if t.IsZero() {
+			return errors.New("尚未收到心跳")
+		}
+		if age := time.Since(t); age > maxAge {
+			return fmt.Errorf("心跳超时: %s 前", age.Round(time.Second))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !windows
+
+package health
+
+import (
+	"errors"
+)
+
+// diskFree 当前平台暂不支持获取磁盘可用空间
+func diskFree(path string) (uint64, error) {
+	return 0, errors.New("当前平台不支持磁盘空间检查")
+}
@@ -0,0 +1,222 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 检查状态
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckFunc 检查函数，返回nil表示正常
+type CheckFunc func(ctx context.Context) error
+
+// Options 检查配置
+type Options struct {
+	Timeout  time.Duration // 单次检查超时时间，默认3秒
+	CacheTTL time.Duration // 结果缓存时间，避免探针频繁访问依赖，默认5秒，小于0表示不缓存
+	Optional bool          // 可选依赖，失败时只报告，不影响整体状态
+	Liveness bool          // 是否同时作为存活检查（/healthz），默认只参与就绪检查（/readyz）
+}
+
+// Result 单项检查结果
+type Result struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+	Optional  bool      `json:"optional,omitempty"`
+}
+
+// Report 检查报告
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Registry 检查项注册表，并发安全
+//
+// 示例:
+//
+//	h := health.New()
+//	h.Register("mysql", health.DB(mysqlDb.GetDb()))
+//	h.Register("redis", health.Redis(redisInstance.Client))
+//	h.Register("disk", health.DiskSpace("/", 1<<30), health.Options{Optional: true})
+//	h.Register("queue", health.QueueDepth(queueLen, 10000))
+//	h.Routes(r) // GET /healthz、GET /readyz
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+// check 已注册的检查项
+type check struct {
+	name string
+	fn   CheckFunc
+	opt  Options
+
+	mu     sync.Mutex
+	result Result
+	cached bool
+}
+
+// New 创建检查项注册表
+func New() *Registry {
+	return &Registry{checks: make(map[string]*check)}
+}
+
+// Register 注册检查项，同名时替换
+func (r *Registry) Register(name string, fn CheckFunc, opts ...Options) *Registry {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 3 * time.Second
+	}
+	if opt.CacheTTL == 0 {
+		opt.CacheTTL = 5 * time.Second
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = &check{name: name, fn: fn, opt: opt}
+	return r
+}
+
+// Unregister 移除检查项
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// Check 并发执行所有检查项
+func (r *Registry) Check(ctx context.Context) Report {
+	return r.run(ctx, false)
+}
+
+// Liveness 只执行标记为 Liveness 的检查项
+func (r *Registry) Liveness(ctx context.Context) Report {
+	return r.run(ctx, true)
+}
+
+// Routes 注册 GET /healthz 与 GET /readyz，状态为down时返回503
+func (r *Registry) Routes(router gin.IRouter) {
+	router.GET("/healthz", r.HealthzHandler())
+	router.GET("/readyz", r.ReadyzHandler())
+}
+
+// HealthzHandler 存活检查，只执行标记为 Liveness 的检查项，没有时总是返回up
+func (r *Registry) HealthzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respond(c, r.Liveness(c.Request.Context()))
+	}
+}
+
+// ReadyzHandler 就绪检查，执行所有检查项
+func (r *Registry) ReadyzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respond(c, r.Check(c.Request.Context()))
+	}
+}
+
+// respond 输出报告，?verbose=0 时只输出状态
+func respond(c *gin.Context, report Report) {
+	code := http.StatusOK
+	if report.Status != StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	if c.Query("verbose") == "0" {
+		c.JSON(code, gin.H{"status": report.Status})
+		return
+	}
+	c.JSON(code, report)
+}
+
+// run 执行检查
+func (r *Registry) run(ctx context.Context, livenessOnly bool) Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if !livenessOnly || c.opt.Liveness {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusUp && !c.opt.Optional {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run 执行单个检查，缓存有效时直接返回缓存
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached && c.opt.CacheTTL > 0 && time.Since(c.result.CheckedAt) < c.opt.CacheTTL {
+		return c.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.opt.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := safeCall(ctx, c.fn)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	result := Result{Status: StatusUp, Duration: time.Since(start).String(), CheckedAt: start, Optional: c.opt.Optional}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	c.result, c.cached = result, true
+	return result
+}
+
+// safeCall 执行检查函数，超时或panic时返回错误
+func safeCall(ctx context.Context, fn CheckFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("检查超时")
+	}
+}
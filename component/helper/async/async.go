@@ -0,0 +1,339 @@
+// Package async 并发工具：有界协程池、并发遍历、错误组、重试及超时控制，
+// 所有任务中的panic都会被捕获并转换为 PanicError，不会导致进程崩溃
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed 协程池已关闭
+var ErrPoolClosed = errors.New("async: pool closed")
+
+// ErrPoolFull 协程池任务队列已满
+var ErrPoolFull = errors.New("async: pool queue full")
+
+// PanicError 任务panic时转换成的错误
+type PanicError struct {
+	Value any    // panic的值
+	Stack string // 堆栈信息
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("async: panic: %v", e.Value)
+}
+
+// Safe 执行fn并将panic转换为 PanicError
+func Safe(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: string(debug.Stack())}
+		}
+	}()
+	return fn()
+}
+
+// Go 在新的协程中执行fn，panic会被捕获并交给onPanic处理（可为nil）
+func Go(fn func(), onPanic ...func(err *PanicError)) {
+	go func() {
+		err := Safe(func() error {
+			fn()
+			return nil
+		})
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) && len(onPanic) > 0 && onPanic[0] != nil {
+			onPanic[0](panicErr)
+		}
+	}()
+}
+
+// ----- 协程池 -----/
+
+// Pool 固定数量工作协程的协程池
+type Pool struct {
+	tasks   chan func()
+	wg      sync.WaitGroup
+	onPanic func(err *PanicError)
+
+	mu     sync.RWMutex
+	closed bool
+	once   sync.Once
+}
+
+// NewPool 创建协程池
+//
+// 参数:
+//   - workers (必需): 工作协程数量，小于1时为1
+//   - queueSize (可选): 任务队列长度，默认等于workers
+//
+// 示例:
+//
+//	pool := async.NewPool(10)
+//	for _, user := range users {
+//	    user := user
+//	    _ = pool.Submit(func() { sendMail(user) })
+//	}
+//	pool.Close() // 等待已提交的任务执行完毕
+func NewPool(workers int, queueSize ...int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	size := workers
+	if len(queueSize) > 0 && queueSize[0] >= 0 {
+		size = queueSize[0]
+	}
+
+	p := &Pool{tasks: make(chan func(), size)}
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// OnPanic 设置任务panic时的回调
+func (p *Pool) OnPanic(fn func(err *PanicError)) *Pool {
+	p.onPanic = fn
+	return p
+}
+
+// Submit 提交任务，队列已满时阻塞等待
+func (p *Pool) Submit(task func()) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.wg.Add(1)
+	p.tasks <- task
+	return nil
+}
+
+// TrySubmit 提交任务，队列已满时立即返回 ErrPoolFull
+func (p *Pool) TrySubmit(task func()) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.wg.Add(1)
+	select {
+	case p.tasks <- task:
+		return nil
+	default:
+		p.wg.Done()
+		return ErrPoolFull
+	}
+}
+
+// Wait 等待当前已提交的任务执行完毕
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// Close 停止接收新任务，并等待已提交的任务执行完毕
+func (p *Pool) Close() {
+	p.once.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+
+		p.wg.Wait()
+		close(p.tasks)
+	})
+}
+
+// worker 工作协程
+func (p *Pool) worker() {
+	for task := range p.tasks {
+		err := Safe(func() error {
+			task()
+			return nil
+		})
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) && p.onPanic != nil {
+			p.onPanic(panicErr)
+		}
+		p.wg.Done()
+	}
+}
+
+// ----- 错误组 -----/
+
+// Group 一组并发任务，任一任务出错时取消其余任务的上下文，Wait 返回第一个错误
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	errOnce sync.Once
+	err     error
+}
+
+// NewGroup 创建错误组
+//
+// 参数:
+//   - ctx (必需): 父上下文
+//   - limit (可选): 最大并发数，默认不限制
+func NewGroup(ctx context.Context, limit ...int) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{ctx: ctx, cancel: cancel}
+	if len(limit) > 0 && limit[0] > 0 {
+		g.sem = make(chan struct{}, limit[0])
+	}
+	return g
+}
+
+// Context 组内任务共享的上下文
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go 启动一个任务，达到并发上限时阻塞等待
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			if g.sem != nil {
+				<-g.sem
+			}
+			g.wg.Done()
+		}()
+		if err := Safe(func() error { return fn(g.ctx) }); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Wait 等待所有任务结束，返回第一个错误
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// ForEach 以指定并发数遍历切片，任一元素处理出错时取消其余任务并返回该错误
+//
+// 示例:
+//
+//	err := async.ForEach(ctx, rows, 8, func(ctx context.Context, row Row, i int) error {
+//	    return importRow(ctx, row)
+//	})
+func ForEach[T any](ctx context.Context, items []T, concurrency int, fn func(ctx context.Context, item T, index int) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	g := NewGroup(ctx, concurrency)
+	for i, item := range items {
+		if g.ctx.Err() != nil {
+			break
+		}
+		i, item := i, item
+		g.Go(func(ctx context.Context) error {
+			return fn(ctx, item, i)
+		})
+	}
+	return g.Wait()
+}
+
+// ----- 重试与超时 -----/
+
+// RetryOptions 重试配置
+type RetryOptions struct {
+	Attempts   int                          // 最多执行次数（含首次），默认3次
+	Delay      time.Duration                // 首次重试前的等待时间，默认100毫秒
+	MaxDelay   time.Duration                // 最长等待时间，默认10秒
+	Multiplier float64                      // 每次重试等待时间的倍数，默认2
+	Jitter     bool                         // 是否在等待时间上增加随机抖动
+	RetryIf    func(err error) bool         // 判断错误是否需要重试，默认除上下文取消外都重试
+	OnRetry    func(attempt int, err error) // 每次重试前的回调
+}
+
+// Retry 按退避策略重试执行fn，直到成功、达到最大次数或上下文结束，返回最后一次的错误
+//
+// 示例:
+//
+//	err := async.Retry(ctx, func(attempt int) error {
+//	    return callRemote()
+//	}, async.RetryOptions{Attempts: 5, Jitter: true})
+func Retry(ctx context.Context, fn func(attempt int) error, opts ...RetryOptions) error {
+	var opt RetryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Attempts < 1 {
+		opt.Attempts = 3
+	}
+	if opt.Delay <= 0 {
+		opt.Delay = 100 * time.Millisecond
+	}
+	if opt.MaxDelay <= 0 {
+		opt.MaxDelay = 10 * time.Second
+	}
+	if opt.Multiplier < 1 {
+		opt.Multiplier = 2
+	}
+
+	delay := opt.Delay
+	var err error
+	for attempt := 1; attempt <= opt.Attempts; attempt++ {
+		if err = Safe(func() error { return fn(attempt) }); err == nil {
+			return nil
+		}
+		if attempt == opt.Attempts || errors.Is(err, context.Canceled) {
+			break
+		}
+		if opt.RetryIf != nil && !opt.RetryIf(err) {
+			break
+		}
+		if opt.OnRetry != nil {
+			opt.OnRetry(attempt, err)
+		}
+
+		wait := delay
+		if opt.Jitter {
+			wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		delay = time.Duration(float64(delay) * opt.Multiplier)
+		if delay > opt.MaxDelay {
+			delay = opt.MaxDelay
+		}
+	}
+	return err
+}
+
+// Timeout 在限定时间内执行fn，超时返回 context.DeadlineExceeded，
+// fn 应当监听传入的ctx尽快退出，否则会在后台继续执行直到结束
+func Timeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Safe(func() error { return fn(ctx) })
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,137 @@
+// Package cache 进程内的键值缓存，用作各组件未配置外部缓存（如 redis.CacheOpt）时的默认实现
+//
+// 值统一转换为字符串保存，支持过期时间；写入时每分钟最多清理一次过期条目，
+// 条目数达到上限时 Set 淘汰最早过期的条目，SetNX 返回 ErrFull，避免淘汰未过期的一次性标记（如 nonce）。
+package cache
+
+import (
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries 默认的条目数上限
+const DefaultMaxEntries = 100000
+
+// ErrFull 条目数已达上限且没有过期条目可清理
+var ErrFull = errors.New("cache: memory cache is full")
+
+// Memory 进程内缓存，并发安全
+type Memory struct {
+	mu         sync.Mutex
+	items      map[string]item
+	maxEntries int
+	sweepAt    time.Time
+}
+
+type item struct {
+	value    string
+	expireAt time.Time
+}
+
+func (i item) expired(now time.Time) bool {
+	return !i.expireAt.IsZero() && now.After(i.expireAt)
+}
+
+// NewMemory 创建进程内缓存，maxEntries 为条目数上限，不传或小于等于0时为 DefaultMaxEntries
+func NewMemory(maxEntries ...int) *Memory {
+	m := &Memory{items: make(map[string]item), maxEntries: DefaultMaxEntries}
+	if len(maxEntries) > 0 && maxEntries[0] > 0 {
+		m.maxEntries = maxEntries[0]
+	}
+	return m
+}
+
+// Set 设置键值，expire 不传或小于等于0时永不过期
+func (m *Memory) Set(key string, value interface{}, expire ...time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if _, ok := m.items[key]; !ok && !m.reserve(now) {
+		m.evict()
+	}
+	m.items[key] = newItem(value, now, expire...)
+	return nil
+}
+
+// SetNX 键不存在或已过期时设置键值，返回是否设置成功
+func (m *Memory) SetNX(key string, value interface{}, expire time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if old, ok := m.items[key]; ok && !old.expired(now) {
+		return false, nil
+	}
+	if _, ok := m.items[key]; !ok && !m.reserve(now) {
+		return false, ErrFull
+	}
+	m.items[key] = newItem(value, now, expire)
+	return true, nil
+}
+
+// GetString 获取字符串值，键不存在或已过期时返回空字符串
+func (m *Memory) GetString(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i, ok := m.items[key]
+	if !ok {
+		return "", nil
+	}
+	if i.expired(time.Now()) {
+		delete(m.items, key)
+		return "", nil
+	}
+	return i.value, nil
+}
+
+// Del 删除键
+func (m *Memory) Del(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+	return nil
+}
+
+// Len 当前条目数（含尚未清理的过期条目）
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.items)
+}
+
+func newItem(value interface{}, now time.Time, expire ...time.Duration) item {
+	i := item{value: helper.Convert{Value: value}.ToString()}
+	if len(expire) > 0 && expire[0] > 0 {
+		i.expireAt = now.Add(expire[0])
+	}
+	return i
+}
+
+// reserve 为新条目预留位置：按时清理过期条目，已满时再强制清理一次，返回是否还有空位
+func (m *Memory) reserve(now time.Time) bool {
+	full := len(m.items) >= m.maxEntries
+	if full || !now.Before(m.sweepAt) {
+		m.sweepAt = now.Add(time.Minute)
+		for k, i := range m.items {
+			if i.expired(now) {
+				delete(m.items, k)
+			}
+		}
+	}
+	return len(m.items) < m.maxEntries
+}
+
+// evict 淘汰最早过期的条目，永不过期的条目最后淘汰
+func (m *Memory) evict() {
+	victim, found := "", false
+	var expireAt time.Time
+	for k, i := range m.items {
+		if !found || (!i.expireAt.IsZero() && (expireAt.IsZero() || i.expireAt.Before(expireAt))) {
+			victim, expireAt, found = k, i.expireAt, true
+		}
+	}
+	if found {
+		delete(m.items, victim)
+	}
+}
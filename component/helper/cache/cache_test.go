@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMemoryExpire(t *testing.T) {
+	m := NewMemory()
+	_ = m.Set("a", 1, 20*time.Millisecond)
+	_ = m.Set("b", "forever")
+	if v, _ := m.GetString("a"); v != "1" {
+		t.Fatalf("a = %q, want 1", v)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if v, _ := m.GetString("a"); v != "" {
+		t.Fatalf("expired a = %q", v)
+	}
+	if v, _ := m.GetString("b"); v != "forever" {
+		t.Fatalf("b = %q", v)
+	}
+	_ = m.Del("b")
+	if v, _ := m.GetString("b"); v != "" {
+		t.Fatalf("deleted b = %q", v)
+	}
+}
+
+func TestMemorySetNX(t *testing.T) {
+	m := NewMemory()
+	if ok, _ := m.SetNX("k", "1", 20*time.Millisecond); !ok {
+		t.Fatal("first SetNX should succeed")
+	}
+	if ok, _ := m.SetNX("k", "1", 20*time.Millisecond); ok {
+		t.Fatal("SetNX on an existing key should fail")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if ok, _ := m.SetNX("k", "1", time.Minute); !ok {
+		t.Fatal("SetNX on an expired key should succeed")
+	}
+}
+
+func TestMemoryMaxEntries(t *testing.T) {
+	m := NewMemory(3)
+	_ = m.Set("forever", "x")
+	for i := 0; i < 10; i++ {
+		_ = m.Set(strconv.Itoa(i), i, time.Duration(i+1)*time.Minute)
+	}
+	if m.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", m.Len())
+	}
+	// 淘汰最早过期的条目，保留永不过期的条目及最晚过期的条目
+	for _, key := range []string{"forever", "8", "9"} {
+		if v, _ := m.GetString(key); v == "" {
+			t.Errorf("%s should be kept", key)
+		}
+	}
+
+	// SetNX 不淘汰未过期的条目
+	if ok, err := m.SetNX("nonce", "1", time.Minute); ok || !errors.Is(err, ErrFull) {
+		t.Fatalf("SetNX on a full cache = %v, %v; want ErrFull", ok, err)
+	}
+	short := NewMemory(1)
+	_, _ = short.SetNX("old", "1", 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if ok, err := short.SetNX("new", "1", time.Minute); !ok || err != nil {
+		t.Fatalf("SetNX after expiry = %v, %v; want expired entries to be cleared", ok, err)
+	}
+}
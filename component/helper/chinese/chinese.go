@@ -0,0 +1,102 @@
+// Package chinese 中文文本处理：汉字转拼音、简繁转换及按显示宽度截断
+//
+// 拼音及简繁对照数据来自 Unicode Unihan 数据库，覆盖 CJK 基本区及扩展A区，编译时嵌入，首次使用时加载。
+// 多音字取最常用的读音，简繁转换按字进行，不处理词语级别的差异（如 发→發/髮）。
+package chinese
+
+import (
+	"bufio"
+	"embed"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed data/*.txt
+var dataFS embed.FS
+
+var (
+	loadOnce    sync.Once
+	pinyinTable map[rune]string // 汉字 → 带声调的拼音
+	traditional map[rune]rune   // 简体 → 繁体
+	simplified  map[rune]rune   // 繁体 → 简体
+)
+
+// load 加载嵌入的对照数据
+func load() {
+	loadOnce.Do(func() {
+		pinyinTable = make(map[rune]string, 27000)
+		eachLine("data/pinyin.txt", func(line string) {
+			reading, chars, ok := strings.Cut(line, "\t")
+			if !ok {
+				return
+			}
+			for _, r := range chars {
+				pinyinTable[r] = reading
+			}
+		})
+
+		traditional = make(map[rune]rune, 2600)
+		simplified = make(map[rune]rune, 2900)
+		loadPairs("data/s2t.txt", traditional)
+		loadPairs("data/t2s.txt", simplified)
+	})
+}
+
+// loadPairs 加载每行两个字的对照表
+func loadPairs(name string, table map[rune]rune) {
+	eachLine(name, func(line string) {
+		runes := []rune(line)
+		if len(runes) == 2 {
+			table[runes[0]] = runes[1]
+		}
+	})
+}
+
+func eachLine(name string, fn func(line string)) {
+	f, err := dataFS.Open(name)
+	if err != nil {
+		panic("chinese: 缺少内置数据 " + name)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+}
+
+// IsHan 判断是否为汉字
+func IsHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// ContainsHan 判断字符串中是否包含汉字
+func ContainsHan(s string) bool {
+	for _, r := range s {
+		if IsHan(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToTraditional 简体转繁体
+func ToTraditional(s string) string {
+	load()
+	return mapRunes(s, traditional)
+}
+
+// ToSimplified 繁体转简体
+func ToSimplified(s string) string {
+	load()
+	return mapRunes(s, simplified)
+}
+
+func mapRunes(s string, table map[rune]rune) string {
+	return strings.Map(func(r rune) rune {
+		if v, ok := table[r]; ok {
+			return v
+		}
+		return r
+	}, s)
+}
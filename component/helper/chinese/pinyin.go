@@ -0,0 +1,149 @@
+package chinese
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Style 拼音风格
+type Style int
+
+const (
+	Tone       Style = iota // 带声调符号，如 zhōng guó
+	ToneNumber              // 声调数字置于末尾，如 zhong1 guo2，轻声不加数字，ü 写作 v
+	Normal                  // 不带声调，如 zhong guo，ü 写作 v
+)
+
+// toneMarks 带声调的韵母 → 不带声调的字母及声调
+var toneMarks = map[rune]struct {
+	base rune
+	tone int
+}{
+	'ā': {'a', 1}, 'á': {'a', 2}, 'ǎ': {'a', 3}, 'à': {'a', 4},
+	'ē': {'e', 1}, 'é': {'e', 2}, 'ě': {'e', 3}, 'è': {'e', 4},
+	'ī': {'i', 1}, 'í': {'i', 2}, 'ǐ': {'i', 3}, 'ì': {'i', 4},
+	'ō': {'o', 1}, 'ó': {'o', 2}, 'ǒ': {'o', 3}, 'ò': {'o', 4},
+	'ū': {'u', 1}, 'ú': {'u', 2}, 'ǔ': {'u', 3}, 'ù': {'u', 4},
+	'ǖ': {'v', 1}, 'ǘ': {'v', 2}, 'ǚ': {'v', 3}, 'ǜ': {'v', 4},
+	'ḿ': {'m', 2}, 'ń': {'n', 2}, 'ň': {'n', 3},
+	'ü': {'v', 0},
+}
+
+// Pinyin 将字符串转换为拼音，每个汉字对应一个元素，连续的字母数字作为一个元素原样保留，其余字符忽略
+//
+// 示例:
+//
+//	chinese.Pinyin("重庆iPhone 15", chinese.Tone)       // [zhòng qìng iPhone 15]
+//	chinese.Pinyin("重庆iPhone 15", chinese.ToneNumber) // [zhong4 qing4 iPhone 15]
+func Pinyin(s string, style Style) []string {
+	load()
+	var (
+		result []string
+		word   strings.Builder
+	)
+	flush := func() {
+		if word.Len() > 0 {
+			result = append(result, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		if reading, ok := pinyinTable[r]; ok {
+			flush()
+			result = append(result, formatPinyin(reading, style))
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+			continue
+		}
+		flush()
+	}
+	flush()
+	return result
+}
+
+// PinyinString 将字符串转换为拼音并以 sep 连接
+func PinyinString(s, sep string, style Style) string {
+	return strings.Join(Pinyin(s, style), sep)
+}
+
+// Initials 获取拼音首字母，非汉字的字母数字原样保留，结果为小写，用于搜索关键字
+//
+// 示例:
+//
+//	chinese.Initials("中华人民共和国") // zhrmghg
+//	chinese.Initials("小米SU7")      // xmsu7
+func Initials(s string) string {
+	load()
+	var b strings.Builder
+	for _, r := range s {
+		if reading, ok := pinyinTable[r]; ok {
+			b.WriteRune(unicode.ToLower([]rune(formatPinyin(reading, Normal))[0]))
+		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// Slug 转换为由小写字母、数字及短横线组成的别名，用于URL
+//
+// 示例:
+//
+//	chinese.Slug("Go 语言入门") // go-yu-yan-ru-men
+func Slug(s string) string {
+	words := Pinyin(s, Normal)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "-")
+}
+
+// SortKey 获取按拼音排序的键，汉字转为带声调数字的拼音，其余字符小写后原样保留
+func SortKey(s string) string {
+	load()
+	var b strings.Builder
+	for _, r := range s {
+		if reading, ok := pinyinTable[r]; ok {
+			b.WriteString(formatPinyin(reading, ToneNumber))
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// Less 按拼音比较两个字符串，用于 sort.Slice 等排序
+//
+// 示例:
+//
+//	sort.Slice(names, func(i, j int) bool { return chinese.Less(names[i], names[j]) })
+func Less(a, b string) bool {
+	return SortKey(a) < SortKey(b)
+}
+
+// formatPinyin 将带声调符号的拼音转换为指定风格
+func formatPinyin(reading string, style Style) string {
+	if style == Tone {
+		return reading
+	}
+	var b strings.Builder
+	tone := 0
+	for _, r := range reading {
+		if mark, ok := toneMarks[r]; ok {
+			b.WriteRune(mark.base)
+			if mark.tone > 0 {
+				tone = mark.tone
+			}
+			continue
+		}
+		b.WriteRune(r)
+	}
+	if style == ToneNumber && tone > 0 {
+		b.WriteString(strconv.Itoa(tone))
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package chinese
+
+import (
+	"golang.org/x/text/width"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RuneWidth 获取字符的显示宽度，汉字、全角字符为2，组合字符及控制字符为0，其余为1
+func RuneWidth(r rune) int {
+	if r < utf8.RuneSelf {
+		if r < 0x20 || r == 0x7f {
+			return 0
+		}
+		return 1
+	}
+	if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// Width 获取字符串的显示宽度，一个汉字等于两个英文字符
+func Width(s string) int {
+	n := 0
+	for _, r := range s {
+		n += RuneWidth(r)
+	}
+	return n
+}
+
+// Truncate 按显示宽度截断字符串，结果（含 suffix）不超过 maxWidth，不会截断半个字符
+//
+// 示例:
+//
+//	chinese.Truncate("中文标题English", 10, "...") // 中文标...
+//	chinese.Truncate("短标题", 10, "...")          // 短标题
+func Truncate(s string, maxWidth int, suffix string) string {
+	if Width(s) <= maxWidth {
+		return s
+	}
+	limit := maxWidth - Width(suffix)
+	if limit <= 0 {
+		return truncateWidth(suffix, maxWidth)
+	}
+	return truncateWidth(s, limit) + suffix
+}
+
+// TruncateRunes 按字符数截断字符串，一个汉字算一个字符，结果（含 suffix）不超过 maxRunes 个字符
+func TruncateRunes(s string, maxRunes int, suffix string) string {
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	limit := maxRunes - utf8.RuneCountInString(suffix)
+	if limit <= 0 {
+		return string([]rune(suffix)[:max(maxRunes, 0)])
+	}
+	return string([]rune(s)[:limit]) + suffix
+}
+
+// truncateWidth 截取不超过指定显示宽度的前缀
+func truncateWidth(s string, maxWidth int) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		w := RuneWidth(r)
+		if n+w > maxWidth {
+			break
+		}
+		n += w
+		b.WriteRune(r)
+	}
+	return b.String()
+}
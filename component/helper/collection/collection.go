@@ -0,0 +1,230 @@
+// Package collection 基于泛型的切片操作函数，不依赖反射，适合在热点路径中替代 helper.InArray 等函数
+package collection
+
+// Contains 判断切片中是否包含指定元素
+func Contains[T comparable](items []T, target T) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexOf 返回元素在切片中首次出现的下标，不存在时返回-1
+func IndexOf[T comparable](items []T, target T) int {
+	for i, item := range items {
+		if item == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// Filter 返回满足条件的元素组成的新切片
+func Filter[T any](items []T, predicate func(item T, index int) bool) []T {
+	result := make([]T, 0, len(items))
+	for i, item := range items {
+		if predicate(item, i) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Map 将每个元素转换后组成新切片
+func Map[T any, R any](items []T, mapper func(item T, index int) R) []R {
+	result := make([]R, len(items))
+	for i, item := range items {
+		result[i] = mapper(item, i)
+	}
+	return result
+}
+
+// Reduce 将切片归约为单个值
+func Reduce[T any, R any](items []T, reducer func(acc R, item T, index int) R, initial R) R {
+	acc := initial
+	for i, item := range items {
+		acc = reducer(acc, item, i)
+	}
+	return acc
+}
+
+// ForEach 遍历切片
+func ForEach[T any](items []T, fn func(item T, index int)) {
+	for i, item := range items {
+		fn(item, i)
+	}
+}
+
+// Find 返回第一个满足条件的元素
+func Find[T any](items []T, predicate func(item T) bool) (T, bool) {
+	for _, item := range items {
+		if predicate(item) {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Some 是否至少有一个元素满足条件
+func Some[T any](items []T, predicate func(item T) bool) bool {
+	_, ok := Find(items, predicate)
+	return ok
+}
+
+// Every 是否所有元素都满足条件
+func Every[T any](items []T, predicate func(item T) bool) bool {
+	for _, item := range items {
+		if !predicate(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Unique 去重，保留元素首次出现的顺序
+func Unique[T comparable](items []T) []T {
+	seen := make(map[T]struct{}, len(items))
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		result = append(result, item)
+	}
+	return result
+}
+
+// UniqueBy 按指定键去重，保留元素首次出现的顺序
+func UniqueBy[T any, K comparable](items []T, key func(item T) K) []T {
+	seen := make(map[K]struct{}, len(items))
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		k := key(item)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		result = append(result, item)
+	}
+	return result
+}
+
+// GroupBy 按指定键分组
+func GroupBy[T any, K comparable](items []T, key func(item T) K) map[K][]T {
+	result := make(map[K][]T)
+	for _, item := range items {
+		k := key(item)
+		result[k] = append(result[k], item)
+	}
+	return result
+}
+
+// KeyBy 按指定键转换为map，键重复时后面的元素覆盖前面的
+func KeyBy[T any, K comparable](items []T, key func(item T) K) map[K]T {
+	result := make(map[K]T, len(items))
+	for _, item := range items {
+		result[key(item)] = item
+	}
+	return result
+}
+
+// Pluck 提取每个元素的指定字段
+func Pluck[T any, R any](items []T, field func(item T) R) []R {
+	result := make([]R, len(items))
+	for i, item := range items {
+		result[i] = field(item)
+	}
+	return result
+}
+
+// Chunk 按指定大小分块，size小于1时返回nil
+func Chunk[T any](items []T, size int) [][]T {
+	if size < 1 {
+		return nil
+	}
+	result := make([][]T, 0, (len(items)+size-1)/size)
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		result = append(result, items[start:end:end])
+	}
+	return result
+}
+
+// Intersect 返回同时存在于所有切片中的元素（已去重，顺序以第一个切片为准）
+func Intersect[T comparable](items []T, others ...[]T) []T {
+	result := Unique(items)
+	for _, other := range others {
+		set := toSet(other)
+		result = Filter(result, func(item T, _ int) bool {
+			_, ok := set[item]
+			return ok
+		})
+	}
+	return result
+}
+
+// Diff 返回存在于items但不存在于任何others中的元素（已去重，顺序以items为准）
+func Diff[T comparable](items []T, others ...[]T) []T {
+	exclude := make(map[T]struct{})
+	for _, other := range others {
+		for _, item := range other {
+			exclude[item] = struct{}{}
+		}
+	}
+	return Filter(Unique(items), func(item T, _ int) bool {
+		_, ok := exclude[item]
+		return !ok
+	})
+}
+
+// Union 合并多个切片并去重
+func Union[T comparable](slices ...[]T) []T {
+	var all []T
+	for _, s := range slices {
+		all = append(all, s...)
+	}
+	return Unique(all)
+}
+
+// Reverse 返回倒序的新切片
+func Reverse[T any](items []T) []T {
+	result := make([]T, len(items))
+	for i, item := range items {
+		result[len(items)-1-i] = item
+	}
+	return result
+}
+
+// Keys 返回map的所有键（无序）
+func Keys[K comparable, V any](m map[K]V) []K {
+	result := make([]K, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
+
+// Values 返回map的所有值（无序）
+func Values[K comparable, V any](m map[K]V) []V {
+	result := make([]V, 0, len(m))
+	for _, v := range m {
+		result = append(result, v)
+	}
+	return result
+}
+
+// toSet 将切片转换为集合
+func toSet[T comparable](items []T) map[T]struct{} {
+	set := make(map[T]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
@@ -2,11 +2,14 @@ package helper
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"math"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 type Convert struct {
@@ -304,3 +307,370 @@ func (c Convert) ToFloat64() float64 {
 		return 0
 	}
 }
+
+// ----- 带错误返回的转换，转换失败时返回错误而不是静默返回零值 -----/
+
+// ToStringE 将变量转为字符串，无法序列化时返回错误
+func (c Convert) ToStringE() (string, error) {
+	switch v := c.Value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return c.ToString(), nil
+	default:
+		newValue, err := json.Marshal(c.Value)
+		if err != nil {
+			return "", err
+		}
+		return string(newValue), nil
+	}
+}
+
+// ToInt64E 将变量转为int64，字符串无法解析、浮点数包含小数部分或超出范围时返回错误
+func (c Convert) ToInt64E() (int64, error) {
+	switch v := c.Value.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case int, int8, int16, int32, int64:
+		return reflect.ValueOf(v).Int(), nil
+	case uint, uint8, uint16, uint32, uint64:
+		u := reflect.ValueOf(v).Uint()
+		if u > uint64(math.MaxInt64) {
+			return 0, fmt.Errorf("%d 超出int64范围", u)
+		}
+		return int64(u), nil
+	case float32, float64:
+		f := reflect.ValueOf(v).Float()
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("%v 不是整数", f)
+		}
+		if f > float64(math.MaxInt64) || f < float64(math.MinInt64) {
+			return 0, fmt.Errorf("%v 超出int64范围", f)
+		}
+		return int64(f), nil
+	case json.Number:
+		return v.Int64()
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, nil
+		}
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无法将 %q 转换为整数", v)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("无法将 %T 转换为整数", c.Value)
+	}
+}
+
+// ToIntE 将变量转为int，规则同 ToInt64E
+func (c Convert) ToIntE() (int, error) {
+	i, err := c.ToInt64E()
+	if err != nil {
+		return 0, err
+	}
+	if i > math.MaxInt || i < math.MinInt {
+		return 0, fmt.Errorf("%d 超出int范围", i)
+	}
+	return int(i), nil
+}
+
+// ToUint64E 将变量转为uint64，负数时返回错误
+func (c Convert) ToUint64E() (uint64, error) {
+	switch v := c.Value.(type) {
+	case uint, uint8, uint16, uint32, uint64:
+		return reflect.ValueOf(v).Uint(), nil
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, nil
+		}
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无法将 %q 转换为无符号整数", v)
+		}
+		return u, nil
+	}
+
+	i, err := c.ToInt64E()
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("%d 不能转换为无符号整数", i)
+	}
+	return uint64(i), nil
+}
+
+// ToFloat64E 将变量转为float64
+func (c Convert) ToFloat64E() (float64, error) {
+	switch v := c.Value.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case float32, float64:
+		return reflect.ValueOf(v).Float(), nil
+	case int, int8, int16, int32, int64:
+		return float64(reflect.ValueOf(v).Int()), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return float64(reflect.ValueOf(v).Uint()), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无法将 %q 转换为浮点数", v)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("无法将 %T 转换为浮点数", c.Value)
+	}
+}
+
+// ToBoolE 将变量转为bool，字符串支持 1/0、true/false、yes/no、on/off
+func (c Convert) ToBoolE() (bool, error) {
+	switch v := c.Value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case int, int8, int16, int32, int64:
+		return reflect.ValueOf(v).Int() != 0, nil
+	case uint, uint8, uint16, uint32, uint64:
+		return reflect.ValueOf(v).Uint() != 0, nil
+	case float32, float64:
+		return reflect.ValueOf(v).Float() != 0, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "0", "false", "no", "off":
+			return false, nil
+		case "1", "true", "yes", "on":
+			return true, nil
+		}
+		return false, fmt.Errorf("无法将 %q 转换为布尔值", v)
+	default:
+		return false, fmt.Errorf("无法将 %T 转换为布尔值", c.Value)
+	}
+}
+
+// ----- 切片、map及结构体的转换 -----/
+
+// ToStringSlice 将变量转为[]string，失败时返回nil
+func (c Convert) ToStringSlice() []string {
+	result, err := c.ToStringSliceE()
+	if err != nil {
+		log.Println("Error converting to []string:", err)
+	}
+	return result
+}
+
+// ToStringSliceE 将变量转为[]string
+//
+// 支持任意切片/数组（元素按 ToStringE 转换）、JSON数组字符串，普通字符串按单个元素处理
+func (c Convert) ToStringSliceE() ([]string, error) {
+	switch v := c.Value.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "[") {
+			var items []any
+			if err := json.Unmarshal([]byte(v), &items); err != nil {
+				return nil, err
+			}
+			return Convert{Value: items}.ToStringSliceE()
+		}
+		return []string{v}, nil
+	}
+
+	rv := reflect.ValueOf(c.Value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		s, err := c.ToStringE()
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+
+	result := make([]string, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		s, err := Convert{Value: rv.Index(i).Interface()}.ToStringE()
+		if err != nil {
+			return nil, fmt.Errorf("第%d个元素: %v", i, err)
+		}
+		result[i] = s
+	}
+	return result, nil
+}
+
+// ToIntSlice 将变量转为[]int，失败时返回nil
+func (c Convert) ToIntSlice() []int {
+	result, err := c.ToIntSliceE()
+	if err != nil {
+		log.Println("Error converting to []int:", err)
+		return nil
+	}
+	return result
+}
+
+// ToIntSliceE 将变量转为[]int
+//
+// 支持任意切片/数组（元素按 ToIntE 转换）、JSON数组字符串及逗号分隔的字符串，如 "1,2,3"
+func (c Convert) ToIntSliceE() ([]int, error) {
+	switch v := c.Value.(type) {
+	case nil:
+		return nil, nil
+	case []int:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return []int{}, nil
+		}
+		if strings.HasPrefix(s, "[") {
+			var items []any
+			if err := json.Unmarshal([]byte(s), &items); err != nil {
+				return nil, err
+			}
+			return Convert{Value: items}.ToIntSliceE()
+		}
+		parts := strings.Split(s, ",")
+		result := make([]int, len(parts))
+		for i, part := range parts {
+			n, err := Convert{Value: part}.ToIntE()
+			if err != nil {
+				return nil, err
+			}
+			result[i] = n
+		}
+		return result, nil
+	}
+
+	rv := reflect.ValueOf(c.Value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		n, err := c.ToIntE()
+		if err != nil {
+			return nil, err
+		}
+		return []int{n}, nil
+	}
+
+	result := make([]int, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		n, err := Convert{Value: rv.Index(i).Interface()}.ToIntE()
+		if err != nil {
+			return nil, fmt.Errorf("第%d个元素: %v", i, err)
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+// ToMapStringAny 将变量转为map[string]any，失败时返回nil
+func (c Convert) ToMapStringAny() map[string]any {
+	result, err := c.ToMapStringAnyE()
+	if err != nil {
+		log.Println("Error converting to map[string]any:", err)
+		return nil
+	}
+	return result
+}
+
+// ToMapStringAnyE 将变量转为map[string]any
+//
+// 支持任意键类型的map（键按 ToStringE 转换）、JSON对象字符串，结构体按json标签转换
+func (c Convert) ToMapStringAnyE() (map[string]any, error) {
+	switch v := c.Value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return v, nil
+	case string:
+		result := make(map[string]any)
+		if err := json.Unmarshal([]byte(v), &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case []byte:
+		result := make(map[string]any)
+		if err := json.Unmarshal(v, &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(c.Value))
+	switch rv.Kind() {
+	case reflect.Map:
+		result := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := Convert{Value: iter.Key().Interface()}.ToStringE()
+			if err != nil {
+				return nil, err
+			}
+			result[key] = iter.Value().Interface()
+		}
+		return result, nil
+	case reflect.Struct:
+		data, err := json.Marshal(c.Value)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]any)
+		if err = json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("无法将 %T 转换为map", c.Value)
+	}
+}
+
+// ToStruct 将变量（map、结构体、JSON字符串等）按json标签转换到target中，target必须是指针
+func (c Convert) ToStruct(target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("target 必须是非空指针")
+	}
+
+	var data []byte
+	switch v := c.Value.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(c.Value); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, target)
+}
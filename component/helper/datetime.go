@@ -0,0 +1,275 @@
+package helper
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 常用时间格式
+const (
+	DateTimeLayout = "2006-01-02 15:04:05" // 模型中约定的日期时间格式
+	DateLayout     = "2006-01-02"          // 日期格式
+	TimeLayout     = "15:04:05"            // 时间格式
+)
+
+// parseLayouts ParseDateTime 依次尝试的格式
+var parseLayouts = []string{
+	DateTimeLayout,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	DateLayout,
+	"2006/01/02",
+	"20060102150405",
+	"20060102",
+}
+
+// ParseDateTime 解析时间字符串，支持常见的多种格式，纯数字（10位秒/13位毫秒）按时间戳解析
+//
+// 参数:
+//   - value (必需): 时间字符串
+//   - loc (可选): 字符串不含时区时使用的时区，默认为本地时区
+func ParseDateTime(value string, loc ...*time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("时间字符串不能为空")
+	}
+
+	location := time.Local
+	if len(loc) > 0 && loc[0] != nil {
+		location = loc[0]
+	}
+
+	// 时间戳
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil && (len(value) == 10 || len(value) == 13) {
+		if len(value) == 13 {
+			return time.UnixMilli(ts).In(location), nil
+		}
+		return time.Unix(ts, 0).In(location), nil
+	}
+
+	for _, layout := range parseLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析的时间格式: %s", value)
+}
+
+// FormatDateTime 格式化为 2006-01-02 15:04:05，零值返回空字符串
+func FormatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(DateTimeLayout)
+}
+
+// ToTimezone 将时间转换到指定时区，如 Asia/Shanghai、UTC
+func ToTimezone(t time.Time, name string) (time.Time, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return t, err
+	}
+	return t.In(loc), nil
+}
+
+// StartOfDay 当天开始时间
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay 当天结束时间
+func EndOfDay(t time.Time) time.Time {
+	return StartOfDay(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartOfWeek 本周开始时间（周一为一周的第一天）
+func StartOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return StartOfDay(t).AddDate(0, 0, -offset)
+}
+
+// EndOfWeek 本周结束时间
+func EndOfWeek(t time.Time) time.Time {
+	return StartOfWeek(t).AddDate(0, 0, 7).Add(-time.Nanosecond)
+}
+
+// StartOfMonth 本月开始时间
+func StartOfMonth(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfMonth 本月结束时间
+func EndOfMonth(t time.Time) time.Time {
+	return StartOfMonth(t).AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// HumanizeDuration 将时长转换为易读的中文描述，如 1天2小时3分钟
+func HumanizeDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	if d < time.Second {
+		return fmt.Sprintf("%d毫秒", d.Milliseconds())
+	}
+
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{24 * time.Hour, "天"},
+		{time.Hour, "小时"},
+		{time.Minute, "分钟"},
+		{time.Second, "秒"},
+	}
+
+	var sb strings.Builder
+	for _, u := range units {
+		if n := d / u.unit; n > 0 {
+			sb.WriteString(fmt.Sprintf("%d%s", n, u.name))
+			d -= n * u.unit
+		}
+	}
+	return sb.String()
+}
+
+// TimeAgo 将时间转换为相对当前时间的描述，如 刚刚、3分钟前、2天后
+func TimeAgo(t time.Time) string {
+	d := time.Since(t)
+	suffix := "前"
+	if d < 0 {
+		d = -d
+		suffix = "后"
+	}
+
+	switch {
+	case d < time.Minute:
+		return "刚刚"
+	case d < time.Hour:
+		return fmt.Sprintf("%d分钟%s", d/time.Minute, suffix)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d小时%s", d/time.Hour, suffix)
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%d天%s", d/(24*time.Hour), suffix)
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%d个月%s", d/(30*24*time.Hour), suffix)
+	default:
+		return fmt.Sprintf("%d年%s", d/(365*24*time.Hour), suffix)
+	}
+}
+
+// DateTime 统一字符串与time.Time的日期时间类型
+//
+// 数据库中读写为DATETIME（零值写入NULL），JSON中序列化为 2006-01-02 15:04:05（零值为空字符串），
+// 反序列化时兼容 ParseDateTime 支持的所有格式及时间戳
+type DateTime struct {
+	time.Time
+}
+
+// NewDateTime 创建DateTime
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{Time: t}
+}
+
+// NowDateTime 当前时间
+func NowDateTime() DateTime {
+	return DateTime{Time: time.Now()}
+}
+
+// String 格式化为 2006-01-02 15:04:05
+func (d DateTime) String() string {
+	return FormatDateTime(d.Time)
+}
+
+// Format 按指定格式输出，零值返回空字符串
+func (d DateTime) Format(layout string) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Time.Format(layout)
+}
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	str := strings.TrimSpace(string(data))
+	if str == "null" {
+		d.Time = time.Time{}
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(str); err == nil {
+		str = unquoted
+	}
+	// 兼容数据库零值
+	if str == "" || strings.HasPrefix(str, "0000-00-00") {
+		d.Time = time.Time{}
+		return nil
+	}
+
+	t, err := ParseDateTime(str)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口
+func (d DateTime) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.Time, nil
+}
+
+// GormDataType 自动迁移时使用的字段类型
+func (DateTime) GormDataType() string {
+	return "datetime"
+}
+
+// Scan 实现 sql.Scanner 接口
+func (d *DateTime) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		d.Time = time.Time{}
+	case time.Time:
+		d.Time = v
+	case int64:
+		d.Time = time.Unix(v, 0)
+	case []byte:
+		return d.scanString(string(v))
+	case string:
+		return d.scanString(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为DateTime", value)
+	}
+	return nil
+}
+
+// scanString 解析数据库中以字符串存储的时间
+func (d *DateTime) scanString(str string) error {
+	if str == "" || strings.HasPrefix(str, "0000-00-00") {
+		d.Time = time.Time{}
+		return nil
+	}
+	t, err := ParseDateTime(str)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
@@ -241,7 +241,7 @@ func (fh *File) CopyFile(targetPath string, overwrite bool, copyAttr bool) error
 	if !fh.Exists() {
 		return errors.New("file not exists")
 	}
-	if fh.Exists() {
+	if _, err := os.Lstat(targetPath); err == nil {
 		if overwrite {
 			err := os.Remove(targetPath)
 			if err != nil {
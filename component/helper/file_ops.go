@@ -0,0 +1,429 @@
+package helper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CopyDir 递归复制目录到 targetDir，保留文件权限，软链接按链接本身复制；
+// overwrite 为 false 时跳过已存在的文件
+func (fh *File) CopyDir(targetDir string, overwrite bool) error {
+	srcInfo, err := os.Stat(fh.Path)
+	if err != nil {
+		return err
+	}
+	if !srcInfo.IsDir() {
+		return errors.New("不是目录，路径：" + fh.Path)
+	}
+	if inside(fh.Path, targetDir) {
+		return errors.New("目标目录不能位于源目录内，路径：" + targetDir)
+	}
+
+	return filepath.WalkDir(fh.Path, func(src string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(fh.Path, src)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(targetDir, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(dst, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			if _, err = os.Lstat(dst); err == nil {
+				if !overwrite {
+					return nil
+				}
+				if err = os.Remove(dst); err != nil {
+					return err
+				}
+			}
+			link, err := os.Readlink(src)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, dst)
+		case info.Mode().IsRegular():
+			if _, err = os.Stat(dst); err == nil && !overwrite {
+				return nil
+			}
+			return copyRegular(src, dst, info.Mode().Perm())
+		default: // 忽略设备文件、管道等
+			return nil
+		}
+	})
+}
+
+// MoveDir 移动目录到 targetDir，优先使用 rename，跨设备时复制后删除源目录；
+// 目标目录已存在时合并，overwrite 决定是否覆盖已存在的文件
+func (fh *File) MoveDir(targetDir string, overwrite bool) error {
+	if !fh.IsDir() {
+		return errors.New("不是目录，路径：" + fh.Path)
+	}
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		if err = os.MkdirAll(filepath.Dir(targetDir), fh.Perm); err != nil {
+			return err
+		}
+		if err = os.Rename(fh.Path, targetDir); err == nil {
+			return nil
+		}
+	}
+	if err := fh.CopyDir(targetDir, overwrite); err != nil {
+		return err
+	}
+	return os.RemoveAll(fh.Path)
+}
+
+// Glob 在目录下递归查找匹配的文件，返回包含 fh.Path 前缀的路径
+//
+// pattern 为相对于 fh.Path 的路径，使用 / 分隔，每一级的语法与 path.Match 相同，
+// 另外 ** 匹配任意层级（包括0层）的目录
+//
+// 示例:
+//
+//	files, err := helper.NewFile(&helper.File{Path: "./runtime"}).Glob("**/*.log")
+//	files, err := helper.NewFile(&helper.File{Path: "./static"}).Glob("img/**/thumb_*.jpg")
+func (fh *File) Glob(pattern string) ([]string, error) {
+	patterns := strings.Split(strings.Trim(filepath.ToSlash(pattern), "/"), "/")
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	matches := make([]string, 0)
+	err := filepath.WalkDir(fh.Path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(fh.Path, p)
+		if err != nil {
+			return err
+		}
+		if matchSegments(patterns, strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// Size 文件大小，目录时为目录下所有文件的大小之和（不跟随软链接）
+func (fh *File) Size() (int64, error) {
+	info, err := os.Stat(fh.Path)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		return info.Size(), nil
+	}
+
+	var size int64
+	err = filepath.WalkDir(fh.Path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// MD5 计算文件的MD5，返回小写十六进制字符串
+func (fh *File) MD5() (string, error) {
+	return fh.checksum(md5.New())
+}
+
+// SHA256 计算文件的SHA256，返回小写十六进制字符串
+func (fh *File) SHA256() (string, error) {
+	return fh.checksum(sha256.New())
+}
+
+func (fh *File) checksum(h hash.Hash) (string, error) {
+	f, err := os.Open(fh.Path)
+	if err != nil {
+		return "", err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteAtomic 原子写入：先写入同目录下的临时文件并同步到磁盘，再重命名为目标文件，
+// 读取方不会读到写了一半的内容；文件已存在时保留原权限，否则使用 Perm
+func (fh *File) WriteAtomic(content []byte) error {
+	return fh.WriteAtomicFrom(bytes.NewReader(content))
+}
+
+// WriteAtomicFrom 从 reader 读取内容原子写入，见 WriteAtomic
+func (fh *File) WriteAtomicFrom(r io.Reader) (err error) {
+	if _, err = fh.DirExists(true); err != nil {
+		return err
+	}
+	perm := fh.Perm
+	if info, statErr := os.Stat(fh.Path); statErr == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fh.Path), "."+filepath.Base(fh.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fh.Path)
+}
+
+// TailOptions Tail 的配置
+type TailOptions struct {
+	Lines        int           // 开始时输出文件末尾的行数，0表示只输出之后新增的内容，小于0表示从头输出
+	Follow       bool          // 是否持续跟踪新增内容，直到 ctx 结束或 handler 返回错误
+	PollInterval time.Duration // 跟踪时检查新内容的间隔，默认500毫秒
+}
+
+// Tail 读取文件末尾的内容，每行（不含换行符）调用一次 handler，handler 返回错误时停止并返回该错误；
+// 跟踪时文件被截断或轮转（被重命名后重新创建）会从新文件的开头继续读取
+//
+// 示例:
+//
+//	err := helper.NewFile(&helper.File{Path: "./runtime/app.log"}).Tail(ctx, func(line string) error {
+//		fmt.Println(line)
+//		return nil
+//	}, helper.TailOptions{Lines: 100, Follow: true})
+func (fh *File) Tail(ctx context.Context, handler func(line string) error, opts ...TailOptions) error {
+	var opt TailOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.PollInterval <= 0 {
+		opt.PollInterval = 500 * time.Millisecond
+	}
+
+	f, err := os.Open(fh.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	offset, err := tailOffset(f, opt.Lines)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	var partial []byte // 尚未读到换行符的内容
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		partial = append(partial, chunk...)
+		offset += int64(len(chunk))
+		if err == nil {
+			if err = handler(strings.TrimRight(string(partial), "\r\n")); err != nil {
+				return err
+			}
+			partial = partial[:0]
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if !opt.Follow {
+			if len(partial) > 0 {
+				return handler(string(partial))
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opt.PollInterval):
+		}
+
+		// 检查截断及轮转
+		cur, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		latest, err := os.Stat(fh.Path)
+		switch {
+		case err == nil && !os.SameFile(cur, latest):
+			// 读完旧文件剩余的内容后切换到新文件
+			rest, err := io.ReadAll(reader)
+			if err != nil {
+				return err
+			}
+			if rest = append(partial, rest...); len(rest) > 0 {
+				for _, line := range strings.Split(strings.TrimSuffix(string(rest), "\n"), "\n") {
+					if err = handler(strings.TrimRight(line, "\r")); err != nil {
+						return err
+					}
+				}
+			}
+			nf, err := os.Open(fh.Path)
+			if err != nil {
+				return err
+			}
+			_ = f.Close()
+			f, offset, partial = nf, 0, partial[:0]
+			reader.Reset(f)
+		case cur.Size() < offset:
+			if _, err = f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			offset, partial = 0, partial[:0]
+			reader.Reset(f)
+		}
+	}
+}
+
+// tailOffset 最后 lines 行的起始位置
+func tailOffset(f *os.File, lines int) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if lines < 0 {
+		return 0, nil
+	}
+	if lines == 0 || size == 0 {
+		return size, nil
+	}
+
+	const chunkSize = 8192
+	buf := make([]byte, chunkSize)
+	pos, count := size, 0
+	// 忽略末尾的换行符
+	if _, err = f.ReadAt(buf[:1], size-1); err != nil {
+		return 0, err
+	}
+	if buf[0] == '\n' {
+		pos--
+	}
+	for pos > 0 {
+		n := min(int64(chunkSize), pos)
+		pos -= n
+		if _, err = f.ReadAt(buf[:n], pos); err != nil {
+			return 0, err
+		}
+		for i := n - 1; i >= 0; i-- {
+			if buf[i] == '\n' {
+				if count++; count == lines {
+					return pos + i + 1, nil
+				}
+			}
+		}
+	}
+	return 0, nil
+}
+
+// matchSegments 按路径的每一级匹配，** 匹配任意层级
+func matchSegments(patterns, parts []string) bool {
+	if len(patterns) == 0 {
+		return len(parts) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(patterns[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(patterns[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(patterns[1:], parts[1:])
+}
+
+// copyRegular 复制普通文件
+func copyRegular(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(dst, perm)
+}
+
+// inside target 是否位于 dir 内（或与 dir 相同）
+func inside(dir, target string) bool {
+	absDir, err1 := filepath.Abs(dir)
+	absTarget, err2 := filepath.Abs(target)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absTarget)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,254 @@
+// Package id 主键及唯一标识生成：雪花ID、UUID(v4/v7)及NanoID
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MachineIDEnv 读取默认机器ID的环境变量名
+const MachineIDEnv = "jc_machine_id"
+
+// 雪花ID各部分位数
+const (
+	machineBits  = 10
+	sequenceBits = 12
+
+	MaxMachineID = 1<<machineBits - 1 // 最大机器ID
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// Epoch 雪花ID的起始时间（2024-01-01 00:00:00 UTC），单位毫秒
+var Epoch int64 = 1704067200000
+
+// ErrClockBackwards 系统时钟回拨
+var ErrClockBackwards = errors.New("id: clock moved backwards")
+
+// Snowflake 雪花ID生成器，并发安全
+//
+// 结构: 1位符号位 + 41位毫秒时间戳 + 10位机器ID + 12位序列号
+type Snowflake struct {
+	mu        sync.Mutex
+	machineID int64
+	lastTime  int64
+	sequence  int64
+}
+
+// SnowflakeParts 解析后的雪花ID
+type SnowflakeParts struct {
+	Time      time.Time
+	MachineID int64
+	Sequence  int64
+}
+
+// NewSnowflake 创建雪花ID生成器
+//
+// 参数:
+//   - machineID (必需): 机器ID，取值范围 0-1023，多实例部署时必须各不相同
+func NewSnowflake(machineID int64) (*Snowflake, error) {
+	if machineID < 0 || machineID > MaxMachineID {
+		return nil, fmt.Errorf("id: machine id must be between 0 and %d", MaxMachineID)
+	}
+	return &Snowflake{machineID: machineID}, nil
+}
+
+// Generate 生成下一个ID，时钟回拨超过1秒时返回 ErrClockBackwards
+func (s *Snowflake) Generate() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	if now < s.lastTime {
+		// 小幅回拨时等待时钟追上
+		if s.lastTime-now > 1000 {
+			return 0, ErrClockBackwards
+		}
+		time.Sleep(time.Duration(s.lastTime-now) * time.Millisecond)
+		now = time.Now().UnixMilli()
+	}
+
+	if now == s.lastTime {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			// 当前毫秒序列号用尽，等待下一毫秒
+			for now <= s.lastTime {
+				time.Sleep(100 * time.Microsecond)
+				now = time.Now().UnixMilli()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastTime = now
+
+	return (now-Epoch)<<(machineBits+sequenceBits) | s.machineID<<sequenceBits | s.sequence, nil
+}
+
+// Next 生成下一个ID，出错时panic
+func (s *Snowflake) Next() int64 {
+	id, err := s.Generate()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NextString 生成下一个ID的字符串形式，便于前端JS处理超过53位的整数
+func (s *Snowflake) NextString() string {
+	return strconv.FormatInt(s.Next(), 10)
+}
+
+// ParseSnowflake 解析雪花ID中的时间、机器ID及序列号
+func ParseSnowflake(id int64) SnowflakeParts {
+	return SnowflakeParts{
+		Time:      time.UnixMilli(id>>(machineBits+sequenceBits) + Epoch),
+		MachineID: id >> sequenceBits & MaxMachineID,
+		Sequence:  id & maxSequence,
+	}
+}
+
+var (
+	defaultSnowflake     *Snowflake
+	defaultSnowflakeOnce sync.Once
+)
+
+// SetMachineID 设置默认生成器的机器ID，需在首次生成ID前调用
+func SetMachineID(machineID int64) error {
+	s, err := NewSnowflake(machineID)
+	if err != nil {
+		return err
+	}
+	defaultSnowflakeOnce.Do(func() {})
+	defaultSnowflake = s
+	return nil
+}
+
+// SnowflakeID 使用默认生成器生成雪花ID
+//
+// 默认机器ID依次取自 SetMachineID、环境变量 jc_machine_id、主机名哈希
+//
+// 示例:
+//
+//	type Order struct {
+//	    ID int64 `gorm:"column:id;primaryKey;autoIncrement:false" json:"id,string"`
+//	}
+//
+//	func (o *Order) BeforeCreate(tx *gorm.DB) error {
+//	    if o.ID == 0 {
+//	        o.ID = id.SnowflakeID()
+//	    }
+//	    return nil
+//	}
+func SnowflakeID() int64 {
+	defaultSnowflakeOnce.Do(func() {
+		defaultSnowflake, _ = NewSnowflake(defaultMachineID())
+	})
+	return defaultSnowflake.Next()
+}
+
+// defaultMachineID 获取默认机器ID
+func defaultMachineID() int64 {
+	if value := os.Getenv(MachineIDEnv); value != "" {
+		if machineID, err := strconv.ParseInt(value, 10, 64); err == nil && machineID >= 0 && machineID <= MaxMachineID {
+			return machineID
+		}
+	}
+	hostname, _ := os.Hostname()
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(hostname))
+	_, _ = h.Write([]byte(strconv.Itoa(os.Getpid())))
+	return int64(h.Sum32()) & MaxMachineID
+}
+
+// ----- UUID -----/
+
+// UUIDv4 生成随机UUID（版本4）
+func UUIDv4() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+// UUIDv7 生成按时间排序的UUID（版本7），适合用作数据库主键以减少索引页分裂
+func UUIDv7() string {
+	var b [16]byte
+	_, _ = rand.Read(b[6:])
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16|uint64(binary.BigEndian.Uint16(b[6:8])))
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+// formatUUID 格式化为 8-4-4-4-12 形式
+func formatUUID(b [16]byte) string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf)
+}
+
+// ----- NanoID -----/
+
+// NanoIDAlphabet NanoID默认字符集（URL安全）
+const NanoIDAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// NanoID 生成指定长度（默认21位）的URL安全随机ID
+func NanoID(size ...int) string {
+	length := 21
+	if len(size) > 0 && size[0] > 0 {
+		length = size[0]
+	}
+	id, _ := NanoIDWithAlphabet(NanoIDAlphabet, length)
+	return id
+}
+
+// NanoIDWithAlphabet 使用自定义字符集生成随机ID，字符集长度需在 1-256 之间
+func NanoIDWithAlphabet(alphabet string, size int) (string, error) {
+	chars := []rune(alphabet)
+	if len(chars) == 0 || len(chars) > 256 {
+		return "", errors.New("id: alphabet length must be between 1 and 256")
+	}
+	if size <= 0 {
+		return "", errors.New("id: size must be positive")
+	}
+
+	// 使用掩码拒绝采样，避免取模带来的分布偏差
+	mask := 1
+	for mask < len(chars)-1 {
+		mask = mask<<1 | 1
+	}
+	step := size*mask*8/5/len(chars) + 1
+
+	result := make([]rune, 0, size)
+	buf := make([]byte, step)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if index := int(b) & mask; index < len(chars) {
+				result = append(result, chars[index])
+				if len(result) == size {
+					return string(result), nil
+				}
+			}
+		}
+	}
+}
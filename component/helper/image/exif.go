@@ -0,0 +1,423 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrNoExif 文件中没有EXIF数据
+var ErrNoExif = errors.New("image: 没有EXIF数据")
+
+// 方向，EXIF Orientation 的取值
+const (
+	OrientationNormal     = 1 // 正常
+	OrientationFlipH      = 2 // 水平翻转
+	OrientationRotate180  = 3 // 旋转180度
+	OrientationFlipV      = 4 // 垂直翻转
+	OrientationTranspose  = 5 // 沿左上-右下对角线翻转
+	OrientationRotate90   = 6 // 需顺时针旋转90度
+	OrientationTransverse = 7 // 沿右上-左下对角线翻转
+	OrientationRotate270  = 8 // 需顺时针旋转270度
+)
+
+const (
+	exifHeader = "Exif\x00\x00"
+	xmpHeader  = "http://ns.adobe.com/xap/1.0/\x00"
+)
+
+// EXIF 标签
+const (
+	tagOrientation  uint16 = 0x0112
+	tagMake         uint16 = 0x010F
+	tagModel        uint16 = 0x0110
+	tagDateTime     uint16 = 0x0132
+	tagExifIFD      uint16 = 0x8769
+	tagGPSIFD       uint16 = 0x8825
+	tagDateTimeOrig uint16 = 0x9003
+	tagGPSLatRef    uint16 = 0x0001
+	tagGPSLat       uint16 = 0x0002
+	tagGPSLngRef    uint16 = 0x0003
+	tagGPSLng       uint16 = 0x0004
+	tagGPSAltRef    uint16 = 0x0005
+	tagGPSAlt       uint16 = 0x0006
+)
+
+// GPS 拍摄位置
+type GPS struct {
+	Latitude  float64 `json:"latitude"`  // 纬度，南纬为负
+	Longitude float64 `json:"longitude"` // 经度，西经为负
+	Altitude  float64 `json:"altitude"`  // 海拔，单位米
+}
+
+// Exif 常用的EXIF信息
+type Exif struct {
+	Orientation int       `json:"orientation"` // 方向，1-8，未设置时为1
+	Make        string    `json:"make"`        // 相机厂商
+	Model       string    `json:"model"`       // 相机型号
+	DateTime    time.Time `json:"date_time"`   // 拍摄时间（优先使用 DateTimeOriginal），按本地时区解析
+	GPS         *GPS      `json:"gps"`         // 拍摄位置，没有时为nil
+}
+
+// ReadExif 读取 JPEG（APP1）或 PNG（eXIf）中的EXIF信息，没有时返回 ErrNoExif
+func ReadExif(data []byte) (*Exif, error) {
+	raw := findExif(data)
+	if raw == nil {
+		return nil, ErrNoExif
+	}
+	t, err := parseTIFF(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Exif{Orientation: OrientationNormal}
+	ifd0 := t.ifd(t.first)
+	if v, ok := ifd0[tagOrientation]; ok {
+		if o := int(t.uint(v)); o >= OrientationNormal && o <= OrientationRotate270 {
+			info.Orientation = o
+		}
+	}
+	info.Make = t.string(ifd0[tagMake])
+	info.Model = t.string(ifd0[tagModel])
+	dateTime := t.string(ifd0[tagDateTime])
+	if v, ok := ifd0[tagExifIFD]; ok {
+		if original := t.string(t.ifd(t.uint(v))[tagDateTimeOrig]); original != "" {
+			dateTime = original
+		}
+	}
+	if dateTime != "" {
+		info.DateTime, _ = time.ParseInLocation("2006:01:02 15:04:05", dateTime, time.Local)
+	}
+
+	if v, ok := ifd0[tagGPSIFD]; ok {
+		gps := t.ifd(t.uint(v))
+		lat, latOK := t.degrees(gps[tagGPSLat])
+		lng, lngOK := t.degrees(gps[tagGPSLng])
+		if latOK && lngOK {
+			if strings.HasPrefix(t.string(gps[tagGPSLatRef]), "S") {
+				lat = -lat
+			}
+			if strings.HasPrefix(t.string(gps[tagGPSLngRef]), "W") {
+				lng = -lng
+			}
+			info.GPS = &GPS{Latitude: lat, Longitude: lng}
+			if alt := t.rationals(gps[tagGPSAlt]); len(alt) > 0 {
+				info.GPS.Altitude = alt[0]
+				if ref, ok := gps[tagGPSAltRef]; ok && t.uint(ref) == 1 {
+					info.GPS.Altitude = -alt[0]
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+// Orientation 读取EXIF中的方向，没有时返回1
+func Orientation(data []byte) int {
+	info, err := ReadExif(data)
+	if err != nil {
+		return OrientationNormal
+	}
+	return info.Orientation
+}
+
+// StripGPS 清除EXIF中的GPS信息并删除XMP元数据（其中也可能包含位置），保留方向等其他EXIF信息，
+// 返回新的文件内容；支持 JPEG 及 PNG，其他格式原样返回
+func StripGPS(data []byte) []byte {
+	out := bytes.Clone(data)
+	if raw := findExif(out); raw != nil {
+		if t, err := parseTIFF(raw); err == nil {
+			if v, ok := t.ifd(t.first)[tagGPSIFD]; ok {
+				t.clearIFD(t.uint(v))
+			}
+		}
+	}
+	if isJPEG(out) {
+		return removeSegments(out, func(marker byte, payload []byte) bool {
+			return marker == 0xE1 && bytes.HasPrefix(payload, []byte(xmpHeader))
+		})
+	}
+	if isPNG(out) {
+		updateCRC(out, "eXIf")
+		return removeChunks(out, func(typ string, payload []byte) bool {
+			return typ == "iTXt" && bytes.HasPrefix(payload, []byte("XML:com.adobe.xmp\x00"))
+		})
+	}
+	return out
+}
+
+// StripExif 删除全部EXIF及XMP元数据，支持 JPEG 及 PNG，其他格式原样返回；
+// 注意删除后方向信息也会丢失，需要时先使用 AutoRotate 旋转图片
+func StripExif(data []byte) []byte {
+	if isJPEG(data) {
+		return removeSegments(data, func(marker byte, payload []byte) bool {
+			return marker == 0xE1 && (bytes.HasPrefix(payload, []byte(exifHeader)) || bytes.HasPrefix(payload, []byte(xmpHeader)))
+		})
+	}
+	if isPNG(data) {
+		return removeChunks(data, func(typ string, payload []byte) bool {
+			return typ == "eXIf" || (typ == "iTXt" && bytes.HasPrefix(payload, []byte("XML:com.adobe.xmp\x00")))
+		})
+	}
+	return bytes.Clone(data)
+}
+
+// ----- 文件结构 ----- /
+
+func isJPEG(data []byte) bool {
+	return len(data) > 3 && data[0] == 0xFF && data[1] == 0xD8
+}
+
+func isPNG(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n"))
+}
+
+// findExif 返回文件中TIFF格式的EXIF数据（与原数据共享内存）
+func findExif(data []byte) []byte {
+	var raw []byte
+	switch {
+	case isJPEG(data):
+		eachSegment(data, func(marker byte, payload []byte) bool {
+			if marker == 0xE1 && bytes.HasPrefix(payload, []byte(exifHeader)) {
+				raw = payload[len(exifHeader):]
+				return false
+			}
+			return true
+		})
+	case isPNG(data):
+		eachChunk(data, func(typ string, payload []byte) bool {
+			if typ == "eXIf" {
+				raw = payload
+				return false
+			}
+			return true
+		})
+	}
+	return raw
+}
+
+// eachSegment 遍历JPEG图像数据之前的段，fn 返回 false 时停止
+func eachSegment(data []byte, fn func(marker byte, payload []byte) bool) {
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 { // 图像数据开始或结束
+			return
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return
+		}
+		if !fn(marker, data[pos+4:pos+2+length]) {
+			return
+		}
+		pos += 2 + length
+	}
+}
+
+// removeSegments 删除JPEG中 remove 返回 true 的段
+func removeSegments(data []byte, remove func(marker byte, payload []byte) bool) []byte {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	pos := 2
+	eachSegment(data, func(marker byte, payload []byte) bool {
+		end := pos + 4 + len(payload)
+		if !remove(marker, payload) {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+		return true
+	})
+	return append(out, data[pos:]...)
+}
+
+// eachChunk 遍历PNG的数据块，fn 返回 false 时停止
+func eachChunk(data []byte, fn func(typ string, payload []byte) bool) {
+	pos := 8
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		if length < 0 || pos+12+length > len(data) {
+			return
+		}
+		typ := string(data[pos+4 : pos+8])
+		if !fn(typ, data[pos+8:pos+8+length]) || typ == "IEND" {
+			return
+		}
+		pos += 12 + length
+	}
+}
+
+// removeChunks 删除PNG中 remove 返回 true 的数据块
+func removeChunks(data []byte, remove func(typ string, payload []byte) bool) []byte {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:8]...)
+	pos := 8
+	eachChunk(data, func(typ string, payload []byte) bool {
+		end := pos + 12 + len(payload)
+		if !remove(typ, payload) {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+		return true
+	})
+	return append(out, data[pos:]...)
+}
+
+// updateCRC 重新计算PNG数据块的校验值
+func updateCRC(data []byte, chunkType string) {
+	pos := 8
+	eachChunk(data, func(typ string, payload []byte) bool {
+		end := pos + 8 + len(payload)
+		if typ == chunkType {
+			binary.BigEndian.PutUint32(data[end:], crc32.ChecksumIEEE(data[pos+4:end]))
+		}
+		pos = end + 4
+		return true
+	})
+}
+
+// ----- TIFF 解析 ----- /
+
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+	first uint32 // 第一个IFD的偏移
+}
+
+// entry IFD中的一项
+type entry struct {
+	offset uint32 // 该项在数据中的偏移
+	typ    uint16
+	count  uint32
+}
+
+// 各数据类型的字节数
+var typeSize = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+func parseTIFF(data []byte) (*tiff, error) {
+	if len(data) < 8 {
+		return nil, ErrNoExif
+	}
+	t := &tiff{data: data}
+	switch string(data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, errors.New("image: EXIF数据格式错误")
+	}
+	if t.order.Uint16(data[2:]) != 42 {
+		return nil, errors.New("image: EXIF数据格式错误")
+	}
+	t.first = t.order.Uint32(data[4:])
+	return t, nil
+}
+
+// ifd 读取IFD的所有项，偏移越界时返回空
+func (t *tiff) ifd(offset uint32) map[uint16]entry {
+	entries := make(map[uint16]entry)
+	if offset < 8 || uint64(offset)+2 > uint64(len(t.data)) {
+		return entries
+	}
+	n := uint32(t.order.Uint16(t.data[offset:]))
+	for i := uint32(0); i < n; i++ {
+		pos := offset + 2 + i*12
+		if uint64(pos)+12 > uint64(len(t.data)) {
+			break
+		}
+		entries[t.order.Uint16(t.data[pos:])] = entry{
+			offset: pos,
+			typ:    t.order.Uint16(t.data[pos+2:]),
+			count:  t.order.Uint32(t.data[pos+4:]),
+		}
+	}
+	return entries
+}
+
+// value 项的值所在的数据，超过4字节时存放在偏移处
+func (t *tiff) value(e entry) []byte {
+	size, ok := typeSize[e.typ]
+	if !ok || e.offset == 0 {
+		return nil
+	}
+	total := uint64(size) * uint64(e.count)
+	start := uint64(e.offset) + 8
+	if total > 4 {
+		start = uint64(t.order.Uint32(t.data[e.offset+8:]))
+	}
+	if start+total > uint64(len(t.data)) {
+		return nil
+	}
+	return t.data[start : start+total]
+}
+
+func (t *tiff) uint(e entry) uint32 {
+	v := t.value(e)
+	switch {
+	case len(v) >= 4 && (e.typ == 4 || e.typ == 9):
+		return t.order.Uint32(v)
+	case len(v) >= 2 && (e.typ == 3 || e.typ == 8):
+		return uint32(t.order.Uint16(v))
+	case len(v) >= 1:
+		return uint32(v[0])
+	default:
+		return 0
+	}
+}
+
+func (t *tiff) string(e entry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(t.value(e)), "\x00"))
+}
+
+func (t *tiff) rationals(e entry) []float64 {
+	if e.typ != 5 && e.typ != 10 {
+		return nil
+	}
+	v := t.value(e)
+	list := make([]float64, 0, len(v)/8)
+	for i := 0; i+8 <= len(v); i += 8 {
+		num, den := float64(t.order.Uint32(v[i:])), float64(t.order.Uint32(v[i+4:]))
+		if e.typ == 10 {
+			num, den = float64(int32(t.order.Uint32(v[i:]))), float64(int32(t.order.Uint32(v[i+4:])))
+		}
+		if den == 0 {
+			list = append(list, 0)
+			continue
+		}
+		list = append(list, num/den)
+	}
+	return list
+}
+
+// degrees 度、分、秒转换为度
+func (t *tiff) degrees(e entry) (float64, bool) {
+	v := t.rationals(e)
+	if len(v) < 3 {
+		return 0, false
+	}
+	d := v[0] + v[1]/60 + v[2]/3600
+	return math.Round(d*1e7) / 1e7, true
+}
+
+// clearIFD 清空IFD：将各项的值及项本身置零，并把项数设为0
+func (t *tiff) clearIFD(offset uint32) {
+	for _, e := range t.ifd(offset) {
+		if size := uint64(typeSize[e.typ]) * uint64(e.count); size > 4 {
+			if v := t.value(e); v != nil {
+				clear(v)
+			}
+		}
+		clear(t.data[e.offset : e.offset+12])
+	}
+	if offset >= 8 && uint64(offset)+2 <= uint64(len(t.data)) {
+		t.order.PutUint16(t.data[offset:], 0)
+	}
+}
@@ -0,0 +1,139 @@
+// Package image 图片工具：尺寸、EXIF（方向、拍摄时间、GPS）读取，按方向自动旋转，
+// 清除GPS等隐私信息及主色提取
+//
+// 包名与标准库 image 相同，同时使用时需设置别名：
+//
+//	import imagehelper "github.com/jcbowen/jcbaseGo/component/helper/image"
+package image
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // 导入 GIF 支持
+	_ "image/jpeg" // 导入 JPEG 支持
+	_ "image/png"  // 导入 PNG 支持
+)
+
+// Info 图片基本信息
+type Info struct {
+	Width       int    `json:"width"`       // 显示宽度（已按方向交换宽高）
+	Height      int    `json:"height"`      // 显示高度
+	Format      string `json:"format"`      // 格式，如 jpeg、png、gif
+	Orientation int    `json:"orientation"` // EXIF 方向，没有时为1
+	Exif        *Exif  `json:"exif"`        // EXIF信息，没有时为nil
+}
+
+// Inspect 读取图片的尺寸、格式及EXIF信息，只解析文件头，不解码像素
+func Inspect(data []byte) (*Info, error) {
+	conf, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{Width: conf.Width, Height: conf.Height, Format: format, Orientation: OrientationNormal}
+	if exif, err := ReadExif(data); err == nil {
+		info.Exif, info.Orientation = exif, exif.Orientation
+	}
+	if info.Orientation >= OrientationTranspose {
+		info.Width, info.Height = info.Height, info.Width
+	}
+	return info, nil
+}
+
+// Decode 解码图片并按EXIF方向旋转为正常显示的方向
+func Decode(data []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return AutoRotate(img, Orientation(data)), format, nil
+}
+
+// AutoRotate 按EXIF方向旋转、翻转图片，orientation 为1或无效值时原样返回
+func AutoRotate(img image.Image, orientation int) image.Image {
+	if orientation <= OrientationNormal || orientation > OrientationRotate270 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= OrientationTranspose {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case OrientationFlipH:
+				dx, dy = w-1-x, y
+			case OrientationRotate180:
+				dx, dy = w-1-x, h-1-y
+			case OrientationFlipV:
+				dx, dy = x, h-1-y
+			case OrientationTranspose:
+				dx, dy = y, x
+			case OrientationRotate90:
+				dx, dy = h-1-y, x
+			case OrientationTransverse:
+				dx, dy = h-1-y, w-1-x
+			case OrientationRotate270:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// DominantColor 图片的主色：将图片缩小采样后按颜色分组，返回像素最多的一组的平均色，忽略透明像素
+func DominantColor(img image.Image) color.RGBA {
+	const samples = 64 // 每边采样点数
+	b := img.Bounds()
+	stepX, stepY := max(b.Dx()/samples, 1), max(b.Dy()/samples, 1)
+
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[uint16]*bucket)
+	var best *bucket
+	for y := b.Min.Y; y < b.Max.Y; y += stepY {
+		for x := b.Min.X; x < b.Max.X; x += stepX {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			// 每个通道取高4位分组
+			key := uint16(c.R>>4)<<8 | uint16(c.G>>4)<<4 | uint16(c.B>>4)
+			bk := buckets[key]
+			if bk == nil {
+				bk = &bucket{}
+				buckets[key] = bk
+			}
+			bk.count++
+			bk.r += int(c.R)
+			bk.g += int(c.G)
+			bk.b += int(c.B)
+			if best == nil || bk.count > best.count {
+				best = bk
+			}
+		}
+	}
+	if best == nil {
+		return color.RGBA{}
+	}
+	return color.RGBA{
+		R: uint8(best.r / best.count),
+		G: uint8(best.g / best.count),
+		B: uint8(best.b / best.count),
+		A: 255,
+	}
+}
+
+// Hex 颜色的十六进制表示，如 #1a2b3c
+func Hex(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,187 @@
+package helper
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MapDiffItem 差异项
+type MapDiffItem struct {
+	Type string `json:"type"` // added/removed/changed
+	Old  any    `json:"old"`  // 旧值，新增时为nil
+	New  any    `json:"new"`  // 新值，删除时为nil
+}
+
+// MapMergeDeep 深度合并多个map，返回新的map，不会修改传入的map
+//
+// 后面的map覆盖前面的，两边都是map时递归合并，其余类型（包括切片）直接覆盖
+//
+// 示例:
+//
+//	merged := MapMergeDeep(defaults, userConfig)
+func MapMergeDeep(maps ...map[string]any) map[string]any {
+	result := make(map[string]any)
+	for _, m := range maps {
+		for key, value := range m {
+			srcMap, srcIsMap := value.(map[string]any)
+			dstMap, dstIsMap := result[key].(map[string]any)
+			switch {
+			case srcIsMap && dstIsMap:
+				result[key] = MapMergeDeep(dstMap, srcMap)
+			case srcIsMap:
+				result[key] = MapMergeDeep(srcMap)
+			default:
+				result[key] = value
+			}
+		}
+	}
+	return result
+}
+
+// MapDiff 深度比较两个map，返回以点分路径（如 a.b.c）为键的差异项
+//
+// 示例:
+//
+//	diff := MapDiff(before, after)
+//	// map[string]MapDiffItem{"user.name": {Type: "changed", Old: "a", New: "b"}}
+func MapDiff(oldMap, newMap map[string]any) map[string]MapDiffItem {
+	diff := make(map[string]MapDiffItem)
+	mapDiff("", oldMap, newMap, diff)
+	return diff
+}
+
+// mapDiff 递归比较
+func mapDiff(prefix string, oldMap, newMap map[string]any, diff map[string]MapDiffItem) {
+	for key, oldValue := range oldMap {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		newValue, exists := newMap[key]
+		if !exists {
+			diff[path] = MapDiffItem{Type: "removed", Old: oldValue}
+			continue
+		}
+
+		oldNested, oldIsMap := oldValue.(map[string]any)
+		newNested, newIsMap := newValue.(map[string]any)
+		if oldIsMap && newIsMap {
+			mapDiff(path, oldNested, newNested, diff)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			diff[path] = MapDiffItem{Type: "changed", Old: oldValue, New: newValue}
+		}
+	}
+
+	for key, newValue := range newMap {
+		if _, exists := oldMap[key]; exists {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		diff[path] = MapDiffItem{Type: "added", New: newValue}
+	}
+}
+
+// MapGetPath 按点分路径获取值，数字段表示切片下标，支持嵌套的map、切片及结构体（字段名或json标签）
+//
+// 示例:
+//
+//	value, ok := MapGetPath(data, "items.0.name")
+func MapGetPath(data any, path string) (any, bool) {
+	if path == "" {
+		return data, data != nil
+	}
+
+	current := reflect.ValueOf(data)
+	for _, segment := range strings.Split(path, ".") {
+		for current.IsValid() && (current.Kind() == reflect.Interface || current.Kind() == reflect.Ptr) {
+			if current.IsNil() {
+				return nil, false
+			}
+			current = current.Elem()
+		}
+		if !current.IsValid() {
+			return nil, false
+		}
+
+		switch current.Kind() {
+		case reflect.Map:
+			if current.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			current = current.MapIndex(reflect.ValueOf(segment).Convert(current.Type().Key()))
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= current.Len() {
+				return nil, false
+			}
+			current = current.Index(index)
+		case reflect.Struct:
+			field := current.FieldByName(segment)
+			if !field.IsValid() {
+				if name := GetFieldNameByJSONTag(current.Type(), segment); name != "" {
+					field = current.FieldByName(name)
+				}
+			}
+			current = field
+		default:
+			return nil, false
+		}
+
+		if !current.IsValid() {
+			return nil, false
+		}
+	}
+
+	if !current.CanInterface() {
+		return nil, false
+	}
+	return current.Interface(), true
+}
+
+// MapSetPath 按点分路径设置值，中间不存在的层级会自动创建为map
+//
+// 示例:
+//
+//	MapSetPath(data, "user.profile.nickname", "jc")
+func MapSetPath(data map[string]any, path string, value any) {
+	segments := strings.Split(path, ".")
+	current := data
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := current[segment].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			current[segment] = next
+		}
+		current = next
+	}
+	current[segments[len(segments)-1]] = value
+}
+
+// MapFlatten 将嵌套map展开为以点分路径为键的单层map
+func MapFlatten(data map[string]any) map[string]any {
+	result := make(map[string]any)
+	mapFlatten("", data, result)
+	return result
+}
+
+// mapFlatten 递归展开
+func mapFlatten(prefix string, data map[string]any, result map[string]any) {
+	for key, value := range data {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			mapFlatten(path, nested, result)
+			continue
+		}
+		result[path] = value
+	}
+}
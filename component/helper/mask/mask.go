@@ -0,0 +1,136 @@
+// Package mask 个人信息脱敏：手机号、身份证号、邮箱、银行卡号及姓名
+//
+// 各函数按中文场景常用的规则保留首尾若干字符，其余替换为掩码字符，可通过 Options 调整；
+// 按字符（rune）处理，不会截断中文。
+package mask
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Options 脱敏规则，字段为零值时使用各函数的默认值
+type Options struct {
+	Head  int    // 保留开头的字符数
+	Tail  int    // 保留结尾的字符数
+	Char  string // 掩码字符，默认 *
+	Fixed int    // 掩码固定显示的个数，用于隐藏原始长度，0表示与被遮盖的字符数相同
+	Group int    // 每隔多少个字符插入一个空格（仅银行卡号），0表示不分组
+}
+
+// Mask 保留首尾 head、tail 个字符，其余替换为掩码；字符数不足时优先减少保留的字符，保证至少遮盖一个字符
+func Mask(s string, head, tail int, opts ...Options) string {
+	opt := Options{Head: head, Tail: tail}
+	if len(opts) > 0 {
+		opt.Char, opt.Fixed = opts[0].Char, opts[0].Fixed
+	}
+	return apply(s, opt)
+}
+
+// MaskPhone 手机号，默认保留前3位后4位：138****5678；带国际区号（+86 等）时区号原样保留
+func MaskPhone(phone string, opts ...Options) string {
+	prefix := ""
+	if strings.HasPrefix(phone, "+") {
+		if i := strings.IndexAny(phone, " -"); i > 0 {
+			prefix, phone = phone[:i+1], phone[i+1:]
+		}
+	}
+	return prefix + apply(phone, merge(Options{Head: 3, Tail: 4}, opts))
+}
+
+// MaskIDCard 身份证号，默认保留前3位后4位：110***********123X
+func MaskIDCard(idCard string, opts ...Options) string {
+	return apply(idCard, merge(Options{Head: 3, Tail: 4}, opts))
+}
+
+// MaskEmail 邮箱，只遮盖@前的部分，默认保留前3个字符（不超过一半）：abc****@example.com；
+// 不是邮箱格式时按 Mask(s, 1, 1) 处理
+func MaskEmail(email string, opts ...Options) string {
+	i := strings.LastIndex(email, "@")
+	if i <= 0 {
+		return apply(email, merge(Options{Head: 1, Tail: 1}, opts))
+	}
+	opt := merge(Options{Head: 3}, opts)
+	local := email[:i]
+	// 使用默认规则时保留的字符不超过一半
+	if n := utf8.RuneCountInString(local); (len(opts) == 0 || opts[0].Head == 0 && opts[0].Tail == 0) && opt.Head > n/2 {
+		opt.Head = max(n/2, 1)
+	}
+	return apply(local, opt) + email[i:]
+}
+
+// MaskBankCard 银行卡号，默认保留前6位后4位：622202******1234；
+// 设置 Group 时按位数分组：Options{Head: 4, Tail: 4, Group: 4} 得到 6222 **** **** 1234
+func MaskBankCard(card string, opts ...Options) string {
+	card = strings.NewReplacer(" ", "", "-", "").Replace(card)
+	opt := merge(Options{Head: 6, Tail: 4}, opts)
+	masked := apply(card, opt)
+	if opt.Group <= 0 {
+		return masked
+	}
+	runes := []rune(masked)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && i%opt.Group == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MaskName 姓名，默认只保留姓：张*、欧阳**（识别常见复姓）；Options{Head: 1, Tail: 1} 可保留最后一个字：张*丰
+func MaskName(name string, opts ...Options) string {
+	name = strings.TrimSpace(name)
+	def := Options{Head: 1}
+	if runes := []rune(name); len(runes) > 2 && compoundSurnames[string(runes[:2])] {
+		def.Head = 2
+	}
+	return apply(name, merge(def, opts))
+}
+
+// compoundSurnames 常见复姓
+var compoundSurnames = map[string]bool{
+	"欧阳": true, "司马": true, "上官": true, "诸葛": true, "东方": true, "皇甫": true, "尉迟": true, "公孙": true,
+	"慕容": true, "长孙": true, "宇文": true, "司徒": true, "司空": true, "夏侯": true, "轩辕": true, "令狐": true,
+	"钟离": true, "闻人": true, "澹台": true, "公羊": true, "赫连": true, "南宫": true, "独孤": true, "端木": true,
+	"西门": true, "呼延": true, "百里": true, "东郭": true, "拓跋": true, "太史": true, "申屠": true, "濮阳": true,
+}
+
+// merge 使用 opts 中的非零值覆盖默认规则
+func merge(def Options, opts []Options) Options {
+	if len(opts) == 0 {
+		return def
+	}
+	opt := opts[0]
+	if opt.Head == 0 && opt.Tail == 0 {
+		opt.Head, opt.Tail = def.Head, def.Tail
+	}
+	return opt
+}
+
+// apply 按规则替换
+func apply(s string, opt Options) string {
+	if s == "" {
+		return ""
+	}
+	if opt.Char == "" {
+		opt.Char = "*"
+	}
+	runes := []rune(s)
+	n := len(runes)
+	head, tail := max(opt.Head, 0), max(opt.Tail, 0)
+	for head+tail >= n && (head > 0 || tail > 0) {
+		if tail > 0 {
+			tail--
+		} else {
+			head--
+		}
+	}
+
+	count := n - head - tail
+	if opt.Fixed > 0 {
+		count = opt.Fixed
+	}
+	return string(runes[:head]) + strings.Repeat(opt.Char, count) + string(runes[n-tail:])
+}
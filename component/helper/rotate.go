@@ -0,0 +1,166 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateWriter 支持按大小、按天切割的文件写入器，并发安全
+//
+// 切割后的旧文件命名为 name-20060102150405.ext，超出 MaxBackups 的旧文件会被删除
+type RotateWriter struct {
+	Filename   string      // 日志文件路径
+	MaxSize    int64       // 单个文件最大字节数，0 表示不按大小切割
+	MaxBackups int         // 最多保留的旧文件数量，0 表示全部保留
+	Daily      bool        // 是否按天切割
+	Perm       os.FileMode // 文件权限，默认为 0644
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openDate string
+}
+
+// NewRotateWriter 创建一个新的切割文件写入器
+func NewRotateWriter(filename string, maxSize int64, maxBackups int, daily bool) *RotateWriter {
+	return &RotateWriter{
+		Filename:   filename,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		Daily:      daily,
+	}
+}
+
+// Write 写入数据，必要时先进行切割
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.needRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即切割当前文件
+func (w *RotateWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate()
+}
+
+// Close 关闭当前文件
+func (w *RotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open 打开（或创建）日志文件
+func (w *RotateWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.Filename), 0755); err != nil {
+		return fmt.Errorf("创建日志目录失败: %v", err)
+	}
+
+	perm := w.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	file, err := os.OpenFile(w.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, perm)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	w.file = file
+	w.size = info.Size()
+	w.openDate = info.ModTime().Format("2006-01-02")
+	if info.Size() == 0 {
+		w.openDate = time.Now().Format("2006-01-02")
+	}
+	return nil
+}
+
+// needRotate 判断写入前是否需要切割
+func (w *RotateWriter) needRotate(writeLen int64) bool {
+	if w.MaxSize > 0 && w.size > 0 && w.size+writeLen > w.MaxSize {
+		return true
+	}
+	if w.Daily && w.openDate != time.Now().Format("2006-01-02") {
+		return true
+	}
+	return false
+}
+
+// rotate 将当前文件重命名为备份文件并重新打开
+func (w *RotateWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+
+	if _, err := os.Stat(w.Filename); err == nil {
+		ext := filepath.Ext(w.Filename)
+		prefix := strings.TrimSuffix(w.Filename, ext)
+		backupName := fmt.Sprintf("%s-%s%s", prefix, time.Now().Format("20060102150405"), ext)
+		// 同一秒内多次切割时追加序号，避免覆盖
+		for i := 1; NewFile(&File{Path: backupName}).Exists(); i++ {
+			backupName = fmt.Sprintf("%s-%s.%d%s", prefix, time.Now().Format("20060102150405"), i, ext)
+		}
+		if err = os.Rename(w.Filename, backupName); err != nil {
+			return fmt.Errorf("切割日志文件失败: %v", err)
+		}
+	}
+
+	w.removeOldBackups()
+
+	return w.open()
+}
+
+// removeOldBackups 删除超出保留数量的旧文件
+func (w *RotateWriter) removeOldBackups() {
+	if w.MaxBackups <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(w.Filename)
+	prefix := strings.TrimSuffix(w.Filename, ext)
+	matches, err := filepath.Glob(prefix + "-*" + ext)
+	if err != nil || len(matches) <= w.MaxBackups {
+		return
+	}
+
+	// 文件名中包含时间，按名称排序即为按时间排序
+	sort.Strings(matches)
+	for _, oldFile := range matches[:len(matches)-w.MaxBackups] {
+		_ = os.Remove(oldFile)
+	}
+}
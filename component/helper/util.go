@@ -276,39 +276,78 @@ func InArray(val interface{}, array interface{}) (exists bool) {
 }
 
 // StructToMap 通过reflect将结构体转换为map
+//
+// useJsonTag 为 true 时使用json标签作为键名（忽略 omitempty 等选项，标签为“-”的字段不输出），
+// 未导出的字段会被忽略，匿名嵌入且没有标签的结构体字段会被展开到上一层
 func StructToMap(obj interface{}, useJsonTag bool) map[string]interface{} {
+	result := make(map[string]interface{})
+
 	objValue := reflect.ValueOf(obj)
-	if objValue.Kind() == reflect.Ptr {
+	for objValue.Kind() == reflect.Ptr {
+		if objValue.IsNil() {
+			return result
+		}
 		objValue = objValue.Elem()
 	}
+	if objValue.Kind() != reflect.Struct {
+		return result
+	}
 
 	objType := objValue.Type()
-
-	result := make(map[string]interface{})
 	for i := 0; i < objType.NumField(); i++ {
 		field := objType.Field(i)
-		fieldValue := objValue.Field(i).Interface()
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := objValue.Field(i)
+
 		fieldName := field.Name
 		if useJsonTag {
 			jsonTag := field.Tag.Get("json")
-			if jsonTag != "" {
-				fieldName = jsonTag
+			name := strings.Split(jsonTag, ",")[0]
+			if jsonTag == "-" {
+				continue
+			}
+			if name == "" && field.Anonymous && reflect.Indirect(fieldValue).Kind() == reflect.Struct {
+				for key, value := range StructToMap(fieldValue.Interface(), useJsonTag) {
+					if _, exists := result[key]; !exists {
+						result[key] = value
+					}
+				}
+				continue
+			}
+			if name != "" {
+				fieldName = name
 			} else {
 				log.Println("StructToMap: json tag not found in struct field:", field.Name)
 				fieldName = strings.ToLower(fieldName)
 			}
 		}
-		result[fieldName] = fieldValue
+		result[fieldName] = fieldValue.Interface()
 	}
 
 	return result
 }
 
 // MapToStruct 通过reflect将map转换为结构体
+//
+// 键名优先匹配字段名，其次匹配json标签；值的类型与字段不一致时会尝试转换，
+// 嵌套的map会递归转换为结构体（或结构体指针），[]any 会逐个元素转换为字段的切片类型
 func MapToStruct(mapData interface{}, obj interface{}) {
-	objValue := reflect.ValueOf(obj).Elem()
+	objValue := reflect.ValueOf(obj)
+	if objValue.Kind() != reflect.Ptr || objValue.Elem().Kind() != reflect.Struct {
+		log.Println("MapToStruct: obj 必须是结构体指针")
+		return
+	}
+	objValue = objValue.Elem()
+
+	data, ok := mapData.(map[string]interface{})
+	if !ok {
+		log.Println("MapToStruct: mapData 必须是 map[string]interface{}")
+		return
+	}
 
-	for key, value := range mapData.(map[string]interface{}) {
+	for key, value := range data {
 		field := objValue.FieldByName(key)
 		if !field.IsValid() {
 			// 如果结构体中不存在这个字段，则尝试匹配 JSON 标记
@@ -320,6 +359,9 @@ func MapToStruct(mapData interface{}, obj interface{}) {
 			}
 			field = objValue.FieldByName(fieldName)
 		}
+		if !field.CanSet() {
+			continue
+		}
 
 		// 将 map 中的值转换为对应的类型，并设置到结构体字段中
 		if !setFieldValue(field, value) {
@@ -335,15 +377,67 @@ func setFieldValue(field reflect.Value, value interface{}) bool {
 		return false
 	}
 
-	if fieldValue.Type().ConvertibleTo(field.Type()) {
-		convertedValue := fieldValue.Convert(field.Type())
-		field.Set(convertedValue)
+	if fieldValue.Type().AssignableTo(field.Type()) {
+		field.Set(fieldValue)
 		return true
 	}
 
-	if field.Kind() == reflect.Struct && fieldValue.Kind() == reflect.Map {
-		// 如果字段是结构体，并且值是一个 map，则递归调用 MapToStruct 函数
-		MapToStruct(value.(map[string]interface{}), field.Addr().Interface()) // 传递值的指针
+	switch field.Kind() {
+	case reflect.Ptr:
+		// 为指针字段分配内存后递归设置
+		elem := reflect.New(field.Type().Elem())
+		if !setFieldValue(elem.Elem(), value) {
+			return false
+		}
+		field.Set(elem)
+		return true
+	case reflect.Struct:
+		if nested, ok := value.(map[string]interface{}); ok {
+			// 如果字段是结构体，并且值是一个 map，则递归调用 MapToStruct 函数
+			MapToStruct(nested, field.Addr().Interface()) // 传递值的指针
+			return true
+		}
+	case reflect.Slice:
+		if fieldValue.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(field.Type(), fieldValue.Len(), fieldValue.Len())
+			for i := 0; i < fieldValue.Len(); i++ {
+				if !setFieldValue(slice.Index(i), fieldValue.Index(i).Interface()) {
+					return false
+				}
+			}
+			field.Set(slice)
+			return true
+		}
+	case reflect.String:
+		if fieldValue.Kind() != reflect.String {
+			field.SetString(Convert{Value: value}.ToString())
+			return true
+		}
+	case reflect.Bool:
+		if fieldValue.Kind() != reflect.Bool {
+			field.SetBool(Convert{Value: value}.ToBool())
+			return true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fieldValue.Kind() == reflect.String {
+			field.SetInt(Convert{Value: value}.ToInt64())
+			return true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if fieldValue.Kind() == reflect.String {
+			field.SetUint(Convert{Value: value}.ToUint64())
+			return true
+		}
+	case reflect.Float32, reflect.Float64:
+		if fieldValue.Kind() == reflect.String {
+			field.SetFloat(Convert{Value: value}.ToFloat64())
+			return true
+		}
+	}
+
+	if fieldValue.Type().ConvertibleTo(field.Type()) {
+		convertedValue := fieldValue.Convert(field.Type())
+		field.Set(convertedValue)
 		return true
 	}
 
@@ -442,6 +536,19 @@ func GetFieldNameByJSONTag(objType reflect.Type, jsonKey string) string {
 			}
 		}
 	}
+	// 匿名嵌入的结构体字段会被提升，可直接通过字段名访问
+	for i := 0; i < objType.NumField(); i++ {
+		field := objType.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" && fieldType.Kind() == reflect.Struct {
+			if name := GetFieldNameByJSONTag(fieldType, jsonKey); name != "" {
+				return name
+			}
+		}
+	}
 	return ""
 }
 
@@ -0,0 +1,130 @@
+package helper
+
+import (
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ConfigViolation 配置项不符合约束的说明
+type ConfigViolation struct {
+	Field   string // 配置项路径，按json名称以“.”连接，如 mysql.port
+	Tag     string // 未通过的约束，如 required、min、oneof
+	Param   string // 约束参数
+	Value   any    // 配置项的值
+	Message string // 提示信息
+}
+
+// ConfigError 配置校验错误，包含全部不符合约束的配置项
+type ConfigError struct {
+	Violations []ConfigViolation
+}
+
+func (e *ConfigError) Error() string {
+	lines := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		lines = append(lines, v.Field+": "+v.Message)
+	}
+	return "配置校验失败:\n" + strings.Join(lines, "\n")
+}
+
+// configMessages 约束的提示信息
+var configMessages = map[string]string{
+	"required": "不能为空",
+	"min":      "长度或数值不能小于{param}",
+	"max":      "长度或数值不能大于{param}",
+	"oneof":    "必须是[{param}]中的一个",
+	"numeric":  "必须是数字",
+	"email":    "必须是有效的邮箱地址",
+	"url":      "必须是有效的URL",
+	"hostname": "必须是有效的主机名",
+	"dir":      "目录不存在",
+	"file":     "文件不存在",
+}
+
+var (
+	configValidator     *validator.Validate
+	configValidatorOnce sync.Once
+)
+
+// CheckValidateAndSetDefault 设置默认值（同 CheckAndSetDefault）后按 validate 标签校验结构体，
+// 返回 *ConfigError，包含全部不符合约束的配置项，用于启动时发现配置错误
+//
+// 示例:
+//
+//	type Config struct {
+//		Mysql jcbaseGo.DbStruct `json:"mysql"`
+//		Limit int              `json:"limit" default:"20" validate:"min=1,max=100"`
+//	}
+//	if err := helper.CheckValidateAndSetDefault(&conf); err != nil {
+//		log.Fatal(err)
+//	}
+func CheckValidateAndSetDefault(i interface{}) error {
+	if err := CheckAndSetDefault(i); err != nil {
+		return err
+	}
+	return ValidateConfig(i)
+}
+
+// ValidateConfig 按 validate 标签校验结构体，不设置默认值，非结构体时直接返回nil
+func ValidateConfig(i interface{}) error {
+	val := reflect.ValueOf(i)
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := getConfigValidator().Struct(val.Interface())
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+	configErr := &ConfigError{Violations: make([]ConfigViolation, 0, len(fieldErrors))}
+	for _, fe := range fieldErrors {
+		field := fe.Namespace()
+		// 去掉根结构体名称
+		if index := strings.Index(field, "."); index >= 0 {
+			field = field[index+1:]
+		}
+		message, ok := configMessages[fe.Tag()]
+		if !ok {
+			message = "不符合约束 " + fe.Tag()
+			if fe.Param() != "" {
+				message += "=" + fe.Param()
+			}
+		}
+		configErr.Violations = append(configErr.Violations, ConfigViolation{
+			Field:   field,
+			Tag:     fe.Tag(),
+			Param:   fe.Param(),
+			Value:   fe.Value(),
+			Message: strings.ReplaceAll(message, "{param}", fe.Param()),
+		})
+	}
+	return configErr
+}
+
+// getConfigValidator 配置校验器，字段路径使用json名称
+func getConfigValidator() *validator.Validate {
+	configValidatorOnce.Do(func() {
+		configValidator = validator.New()
+		configValidator.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			switch name {
+			case "-":
+				return ""
+			case "":
+				return field.Name
+			}
+			return name
+		})
+	})
+	return configValidator
+}
@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	stateClosed   = iota // 关闭：正常放行
+	stateOpen            // 打开：直接拒绝
+	stateHalfOpen        // 半开：放行一个试探请求
+)
+
+// breaker 按主机维度的熔断器
+type breaker struct {
+	threshold int
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow 判断请求是否可以放行
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.timeout {
+			return false
+		}
+		b.state = stateHalfOpen
+		b.probing = true
+		return true
+	case stateHalfOpen:
+		// 半开状态下只允许一个试探请求
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// report 上报请求结果
+func (b *breaker) report(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = stateClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
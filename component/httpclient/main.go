@@ -0,0 +1,211 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，请求被直接拒绝
+var ErrCircuitOpen = errors.New("httpclient: circuit breaker is open")
+
+// Options 客户端配置
+type Options struct {
+	BaseURL string            // 基础地址，请求地址为相对路径时会拼接在其后
+	Headers map[string]string // 每个请求默认携带的请求头
+	Timeout time.Duration     // 单次请求超时时间，默认30秒
+
+	MaxRetries   int                                  // 最大重试次数，默认不重试
+	RetryWait    time.Duration                        // 首次重试等待时间，之后按指数退避，默认200毫秒
+	RetryMaxWait time.Duration                        // 最长重试等待时间，默认5秒
+	RetryIf      func(resp *Response, err error) bool // 自定义是否重试，默认网络错误、429及5xx时重试
+
+	BreakerThreshold int           // 连续失败多少次后打开熔断器，0表示不启用熔断
+	BreakerTimeout   time.Duration // 熔断器打开后多久进入半开状态尝试恢复，默认30秒
+
+	LogBodyLimit int                 `default:"4096"` // 日志中记录的请求/响应体最大字节数
+	Logger       func(record Record) // 请求日志回调，每次尝试（含重试）都会调用一次
+
+	Transport http.RoundTripper // 自定义底层传输，默认为 http.DefaultTransport
+}
+
+// Record 单次请求的日志记录，可转发到日志或调试器中
+type Record struct {
+	Method         string        `json:"method"`
+	URL            string        `json:"url"`
+	RequestHeader  http.Header   `json:"request_header"`
+	RequestBody    string        `json:"request_body"`
+	StatusCode     int           `json:"status_code"`
+	ResponseHeader http.Header   `json:"response_header"`
+	ResponseBody   string        `json:"response_body"`
+	Attempt        int           `json:"attempt"` // 第几次尝试，从1开始
+	Duration       time.Duration `json:"duration"`
+	Error          string        `json:"error"`
+}
+
+// Handler 发送请求的处理函数
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware 请求中间件，可用于签名、注入链路ID、统计等
+type Middleware func(next Handler) Handler
+
+// Client http客户端，并发安全
+type Client struct {
+	Opt Options
+
+	httpClient  *http.Client
+	middlewares []Middleware
+
+	breakersMu sync.Mutex
+	breakers   map[string]*breaker
+}
+
+// New 创建一个新的http客户端
+//
+// 示例:
+//
+//	client := httpclient.New(httpclient.Options{BaseURL: "http://user-service", MaxRetries: 2})
+//	var result jcbaseGo.Result
+//	resp, err := client.R().SetJSON(map[string]any{"id": 1}).Post("/user/detail")
+//	if err == nil {
+//	    err = resp.JSON(&result)
+//	}
+func New(opts ...Options) *Client {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.Timeout <= 0 {
+		opt.Timeout = 30 * time.Second
+	}
+	if opt.RetryWait <= 0 {
+		opt.RetryWait = 200 * time.Millisecond
+	}
+	if opt.RetryMaxWait < opt.RetryWait {
+		opt.RetryMaxWait = 5 * time.Second
+	}
+	if opt.BreakerTimeout <= 0 {
+		opt.BreakerTimeout = 30 * time.Second
+	}
+	if opt.Transport == nil {
+		opt.Transport = http.DefaultTransport
+	}
+
+	return &Client{
+		Opt: opt,
+		httpClient: &http.Client{
+			Transport: opt.Transport,
+		},
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// Use 添加请求中间件，按添加顺序由外到内执行
+func (c *Client) Use(middlewares ...Middleware) *Client {
+	c.middlewares = append(c.middlewares, middlewares...)
+	return c
+}
+
+// R 创建一个新的请求
+func (c *Client) R() *Request {
+	r := &Request{
+		client:  c,
+		ctx:     context.Background(),
+		header:  make(http.Header),
+		query:   make(map[string][]string),
+		retries: -1,
+	}
+	for key, value := range c.Opt.Headers {
+		r.header.Set(key, value)
+	}
+	return r
+}
+
+// Get 发送GET请求
+func (c *Client) Get(url string) (*Response, error) {
+	return c.R().Get(url)
+}
+
+// PostJSON 以JSON格式发送POST请求
+func (c *Client) PostJSON(url string, body any) (*Response, error) {
+	return c.R().SetJSON(body).Post(url)
+}
+
+// PostForm 以表单格式发送POST请求
+func (c *Client) PostForm(url string, form map[string]string) (*Response, error) {
+	return c.R().SetForm(form).Post(url)
+}
+
+// buildURL 拼接基础地址
+func (c *Client) buildURL(url string) string {
+	if c.Opt.BaseURL == "" || strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return url
+	}
+	return strings.TrimRight(c.Opt.BaseURL, "/") + "/" + strings.TrimLeft(url, "/")
+}
+
+// handler 组装中间件链
+func (c *Client) handler() Handler {
+	h := Handler(c.httpClient.Do)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
+
+// breaker 获取指定主机的熔断器
+func (c *Client) breaker(host string) *breaker {
+	if c.Opt.BreakerThreshold <= 0 {
+		return nil
+	}
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+	b, ok := c.breakers[host]
+	if !ok {
+		b = &breaker{threshold: c.Opt.BreakerThreshold, timeout: c.Opt.BreakerTimeout}
+		c.breakers[host] = b
+	}
+	return b
+}
+
+// shouldRetry 判断是否需要重试
+func (c *Client) shouldRetry(resp *Response, err error) bool {
+	if c.Opt.RetryIf != nil {
+		return c.Opt.RetryIf(resp, err)
+	}
+	if err != nil {
+		// 主动取消或超出调用方的截止时间时不再重试
+		if errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+			return false
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "EOF")
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff 计算第n次重试前的等待时间（指数退避+随机抖动）
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.Opt.RetryWait << uint(attempt-1)
+	if wait <= 0 || wait > c.Opt.RetryMaxWait {
+		wait = c.Opt.RetryMaxWait
+	}
+	// 在 [wait/2, wait] 之间随机，避免多个客户端同时重试
+	half := int64(wait / 2)
+	if half > 0 {
+		wait = time.Duration(half + rand.Int63n(half+1))
+	}
+	return wait
+}
+
+// isFailure 判断响应是否计入熔断失败次数
+func isFailure(resp *Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
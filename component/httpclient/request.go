@@ -0,0 +1,339 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// multipartFile 待上传的文件
+type multipartFile struct {
+	field    string
+	filename string
+	reader   io.Reader
+	path     string
+}
+
+// Request 请求构造器，非并发安全，每次请求应通过 Client.R 重新创建
+type Request struct {
+	client *Client
+	ctx    context.Context
+	header http.Header
+	query  url.Values
+
+	body        []byte
+	contentType string
+	form        url.Values
+	files       []multipartFile
+	err         error
+
+	timeout time.Duration
+	retries int
+}
+
+// SetContext 设置请求上下文
+func (r *Request) SetContext(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
+// SetHeader 设置请求头
+func (r *Request) SetHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// SetHeaders 批量设置请求头
+func (r *Request) SetHeaders(headers map[string]string) *Request {
+	for key, value := range headers {
+		r.header.Set(key, value)
+	}
+	return r
+}
+
+// SetQuery 设置查询参数
+func (r *Request) SetQuery(key, value string) *Request {
+	r.query.Set(key, value)
+	return r
+}
+
+// SetQueryParams 批量设置查询参数
+func (r *Request) SetQueryParams(params map[string]string) *Request {
+	for key, value := range params {
+		r.query.Set(key, value)
+	}
+	return r
+}
+
+// SetJSON 设置JSON请求体
+func (r *Request) SetJSON(body any) *Request {
+	data, err := json.Marshal(body)
+	if err != nil {
+		r.err = fmt.Errorf("httpclient: 序列化JSON失败: %v", err)
+		return r
+	}
+	r.body = data
+	r.contentType = "application/json"
+	return r
+}
+
+// SetForm 设置表单请求体，与 SetFile 同时使用时作为multipart的普通字段
+func (r *Request) SetForm(form map[string]string) *Request {
+	if r.form == nil {
+		r.form = make(url.Values)
+	}
+	for key, value := range form {
+		r.form.Set(key, value)
+	}
+	return r
+}
+
+// SetFile 添加要上传的本地文件，请求将以multipart格式发送
+func (r *Request) SetFile(field, path string) *Request {
+	r.files = append(r.files, multipartFile{field: field, filename: filepath.Base(path), path: path})
+	return r
+}
+
+// SetFileReader 添加要上传的文件内容，请求将以multipart格式发送
+func (r *Request) SetFileReader(field, filename string, reader io.Reader) *Request {
+	r.files = append(r.files, multipartFile{field: field, filename: filename, reader: reader})
+	return r
+}
+
+// SetBody 设置原始请求体
+func (r *Request) SetBody(body []byte, contentType string) *Request {
+	r.body = body
+	r.contentType = contentType
+	return r
+}
+
+// SetTimeout 设置本次请求的单次超时时间，覆盖客户端配置
+func (r *Request) SetTimeout(timeout time.Duration) *Request {
+	r.timeout = timeout
+	return r
+}
+
+// SetRetries 设置本次请求的最大重试次数，覆盖客户端配置
+func (r *Request) SetRetries(retries int) *Request {
+	r.retries = retries
+	return r
+}
+
+// Get 发送GET请求
+func (r *Request) Get(url string) (*Response, error) {
+	return r.Do(http.MethodGet, url)
+}
+
+// Post 发送POST请求
+func (r *Request) Post(url string) (*Response, error) {
+	return r.Do(http.MethodPost, url)
+}
+
+// Put 发送PUT请求
+func (r *Request) Put(url string) (*Response, error) {
+	return r.Do(http.MethodPut, url)
+}
+
+// Patch 发送PATCH请求
+func (r *Request) Patch(url string) (*Response, error) {
+	return r.Do(http.MethodPatch, url)
+}
+
+// Delete 发送DELETE请求
+func (r *Request) Delete(url string) (*Response, error) {
+	return r.Do(http.MethodDelete, url)
+}
+
+// Do 发送请求，按配置进行重试及熔断
+func (r *Request) Do(method, rawURL string) (*Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := r.prepareBody(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(r.client.buildURL(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("httpclient: 请求地址错误: %v", err)
+	}
+	if len(r.query) > 0 {
+		query := u.Query()
+		for key, values := range r.query {
+			query[key] = values
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	maxRetries := r.client.Opt.MaxRetries
+	if r.retries >= 0 {
+		maxRetries = r.retries
+	}
+	breaker := r.client.breaker(u.Host)
+	handler := r.client.handler()
+
+	var (
+		resp *Response
+		last error
+	)
+	for attempt := 1; attempt <= maxRetries+1; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(r.client.backoff(attempt - 1)):
+			case <-r.ctx.Done():
+				return resp, r.ctx.Err()
+			}
+		}
+
+		if breaker != nil && !breaker.allow() {
+			return nil, ErrCircuitOpen
+		}
+
+		resp, last = r.send(handler, method, u.String(), attempt)
+		if breaker != nil {
+			breaker.report(!isFailure(resp, last))
+		}
+		if resp != nil {
+			resp.Attempts = attempt
+		}
+
+		if !r.client.shouldRetry(resp, last) {
+			break
+		}
+	}
+
+	return resp, last
+}
+
+// send 发送一次请求
+func (r *Request) send(handler Handler, method, rawURL string, attempt int) (*Response, error) {
+	timeout := r.client.Opt.Timeout
+	if r.timeout > 0 {
+		timeout = r.timeout
+	}
+	ctx, cancel := context.WithTimeout(r.ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(r.body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.header.Clone()
+	if r.contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+
+	start := time.Now()
+	httpResp, err := handler(req)
+
+	var resp *Response
+	if err == nil {
+		var body []byte
+		body, err = io.ReadAll(httpResp.Body)
+		_ = httpResp.Body.Close()
+		resp = &Response{
+			StatusCode: httpResp.StatusCode,
+			Header:     httpResp.Header,
+			Body:       body,
+			Raw:        httpResp,
+		}
+	}
+	duration := time.Since(start)
+	if resp != nil {
+		resp.Duration = duration
+	}
+
+	r.log(req, resp, err, attempt, duration)
+
+	return resp, err
+}
+
+// prepareBody 构造表单或multipart请求体
+func (r *Request) prepareBody() error {
+	if len(r.files) == 0 {
+		if r.form != nil && r.body == nil {
+			r.body = []byte(r.form.Encode())
+			r.contentType = "application/x-www-form-urlencoded"
+		}
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	for key, values := range r.form {
+		for _, value := range values {
+			if err := writer.WriteField(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range r.files {
+		reader := f.reader
+		if reader == nil {
+			file, err := os.Open(f.path)
+			if err != nil {
+				return fmt.Errorf("httpclient: 打开上传文件失败: %v", err)
+			}
+			reader = file
+			defer file.Close()
+		}
+		part, err := writer.CreateFormFile(f.field, f.filename)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(part, reader); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	// 请求体已缓存在内存中，重试时可以重复发送
+	r.files = nil
+	r.body = buf.Bytes()
+	r.contentType = writer.FormDataContentType()
+	return nil
+}
+
+// log 调用日志回调
+func (r *Request) log(req *http.Request, resp *Response, err error, attempt int, duration time.Duration) {
+	if r.client.Opt.Logger == nil {
+		return
+	}
+
+	limit := r.client.Opt.LogBodyLimit
+	record := Record{
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		RequestHeader: req.Header,
+		RequestBody:   truncate(r.body, limit),
+		Attempt:       attempt,
+		Duration:      duration,
+	}
+	if resp != nil {
+		record.StatusCode = resp.StatusCode
+		record.ResponseHeader = resp.Header
+		record.ResponseBody = truncate(resp.Body, limit)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	r.client.Opt.Logger(record)
+}
+
+// truncate 截断过长的内容
+func truncate(data []byte, limit int) string {
+	if limit > 0 && len(data) > limit {
+		return string(data[:limit]) + "...(truncated)"
+	}
+	return string(data)
+}
@@ -0,0 +1,32 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Response 响应结果，响应体已完整读取
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration // 最后一次请求耗时
+	Attempts   int           // 总共尝试的次数
+	Raw        *http.Response
+}
+
+// IsSuccess 状态码是否为2xx
+func (r *Response) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// String 以字符串形式返回响应体
+func (r *Response) String() string {
+	return string(r.Body)
+}
+
+// JSON 将响应体解析到v中
+func (r *Response) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
@@ -0,0 +1,127 @@
+package i18n
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/middleware"
+	"sync/atomic"
+)
+
+// 在gin上下文中保存的键名
+const (
+	LocaleKey = "I18nLocale" // 当前请求协商出的语言
+	BundleKey = "I18nBundle" // 当前请求使用的翻译包
+)
+
+var defaultBundle atomic.Pointer[Bundle]
+
+func init() {
+	conf := jcbaseGo.I18nStruct{}
+	_ = helper.CheckAndSetDefault(&conf)
+	b := &Bundle{Conf: conf, messages: make(map[string]map[string]message)}
+	b.rebuild()
+	defaultBundle.Store(b)
+}
+
+// Default 获取默认翻译包，未设置时为不含任何翻译的空翻译包
+func Default() *Bundle {
+	return defaultBundle.Load()
+}
+
+// SetDefault 设置默认翻译包
+func SetDefault(b *Bundle) {
+	defaultBundle.Store(b)
+}
+
+// Negotiate 协商请求的语言，依次读取 GET/POST参数、cookie、Accept-Language 请求头
+func (b *Bundle) Negotiate(c *gin.Context) string {
+	key := b.Conf.QueryKey
+	var candidates []string
+	if key != "" {
+		if v, ok := middleware.GetGPC(c).Get(key); ok {
+			candidates = append(candidates, fmt.Sprint(v))
+		} else if v := c.Query(key); v != "" {
+			candidates = append(candidates, v)
+		} else if v := c.PostForm(key); v != "" {
+			candidates = append(candidates, v)
+		}
+	}
+	if b.Conf.CookieKey != "" {
+		if v, err := c.Cookie(b.Conf.CookieKey); err == nil && v != "" {
+			candidates = append(candidates, v)
+		}
+	}
+	candidates = append(candidates, c.GetHeader("Accept-Language"))
+
+	// 逐个匹配，确保显式指定的语言优先于 Accept-Language
+	for _, candidate := range candidates {
+		if locale, ok := b.match(candidate); ok {
+			return locale
+		}
+	}
+	return b.Conf.DefaultLocale
+}
+
+// Middleware 协商请求语言并保存到gin上下文中，同时输出 Content-Language 响应头
+// 使用该中间件后，controller.Base 的响应提示信息、message 提示页会自动翻译
+func (b *Bundle) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := b.Negotiate(c)
+		c.Set(LocaleKey, locale)
+		c.Set(BundleKey, b)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
+
+// FromContext 获取请求使用的翻译包，未使用中间件时为默认翻译包
+func FromContext(c *gin.Context) *Bundle {
+	if c != nil {
+		if v, ok := c.Get(BundleKey); ok {
+			if b, ok := v.(*Bundle); ok {
+				return b
+			}
+		}
+	}
+	return Default()
+}
+
+// Locale 获取请求的语言，未使用中间件时实时协商
+func Locale(c *gin.Context) string {
+	if c == nil {
+		return Default().Conf.DefaultLocale
+	}
+	if locale := c.GetString(LocaleKey); locale != "" {
+		return locale
+	}
+	return FromContext(c).Negotiate(c)
+}
+
+// T 按请求的语言翻译
+func T(c *gin.Context, key string, data ...Data) string {
+	return FromContext(c).T(Locale(c), key, data...)
+}
+
+// TN 按请求的语言翻译复数形式
+func TN(c *gin.Context, key string, count int, data ...Data) string {
+	return FromContext(c).TN(Locale(c), key, count, data...)
+}
+
+// Translate 翻译响应提示信息，仅在请求使用了 Middleware 时翻译，否则原样返回
+// 供 controller.Base、message 等组件在输出前调用，提示信息可直接使用翻译键
+func Translate(c *gin.Context, message string) string {
+	if c == nil || message == "" {
+		return message
+	}
+	v, ok := c.Get(BundleKey)
+	if !ok {
+		return message
+	}
+	b, ok := v.(*Bundle)
+	if !ok {
+		return message
+	}
+	return b.T(Locale(c), message)
+}
@@ -0,0 +1,329 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/text/language"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Bundle 翻译包，保存所有语言的翻译，并发安全
+//
+// 翻译文件为JSON或TOML格式，文件名（去掉扩展名）为语言标识，支持嵌套，嵌套的键以"."连接：
+//
+//	// zh-CN.json
+//	{"user": {"login_success": "欢迎回来，{name}", "apples": {"other": "{count} 个苹果"}}}
+//	# en.toml
+//	[user]
+//	login_success = "Welcome back, {name}"
+//	[user.apples]
+//	one = "{count} apple"
+//	other = "{count} apples"
+//
+// 仅包含复数类别（zero/one/two/few/many/other）且包含 other 的对象视为复数翻译，使用 TN 翻译。
+type Bundle struct {
+	Conf jcbaseGo.I18nStruct
+
+	mu       sync.RWMutex
+	messages map[string]map[string]message // locale => key => message
+	tags     []language.Tag
+	matcher  language.Matcher
+}
+
+// message 单条翻译，普通翻译只有 other
+type message map[string]string
+
+// New 创建翻译包，配置的翻译目录存在时自动加载
+//
+// 示例:
+//
+//	bundle := i18n.New(jcbaseGo.I18nStruct{Dir: "./i18n"})
+//	i18n.SetDefault(bundle)
+//	r.Use(bundle.Middleware())
+//	r.GET("/login", func(c *gin.Context) {
+//	    c.String(200, i18n.T(c, "user.login_success", i18n.Data{"name": "jcbowen"}))
+//	})
+func New(conf jcbaseGo.I18nStruct) *Bundle {
+	_ = helper.CheckAndSetDefault(&conf)
+	b := &Bundle{Conf: conf, messages: make(map[string]map[string]message)}
+	b.rebuild()
+	if info, err := os.Stat(conf.Dir); err == nil && info.IsDir() {
+		if err := b.LoadDir(conf.Dir); err != nil {
+			log.Println("i18n: 加载翻译文件失败:", err)
+		}
+	}
+	return b
+}
+
+// LoadDir 加载目录下所有 .json 及 .toml 翻译文件
+func (b *Bundle) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".toml":
+			if err := b.LoadFile(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadFile 加载翻译文件，文件名为语言标识，如 zh-CN.json；也支持 active.en.toml 形式，取最后一段作为语言标识
+func (b *Bundle) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	ext := filepath.Ext(path)
+	name := strings.TrimSuffix(filepath.Base(path), ext)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if err = b.Load(name, data, strings.TrimPrefix(strings.ToLower(ext), ".")); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Load 加载翻译内容，format 为 json 或 toml
+func (b *Bundle) Load(locale string, data []byte, format string) error {
+	var raw map[string]any
+	var err error
+	switch format {
+	case "json":
+		err = json.Unmarshal(data, &raw)
+	case "toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("不支持的翻译文件格式: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	b.Add(locale, raw)
+	return nil
+}
+
+// Add 添加翻译，messages 可以嵌套，已存在的键会被覆盖
+func (b *Bundle) Add(locale string, messages map[string]any) {
+	tag, err := language.Parse(locale)
+	if err == nil {
+		locale = tag.String()
+	}
+
+	flat := make(map[string]message)
+	flatten("", messages, flat)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.messages[locale] == nil {
+		b.messages[locale] = make(map[string]message)
+	}
+	for k, v := range flat {
+		b.messages[locale][k] = v
+	}
+	b.rebuildLocked()
+}
+
+// Locales 获取已加载的语言
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Has 判断指定语言（含回退语言）是否存在该翻译
+func (b *Bundle) Has(locale, key string) bool {
+	_, ok := b.lookup(locale, key)
+	return ok
+}
+
+// T 翻译，data 中的值会替换翻译中的 {name} 占位符，缺少翻译时原样返回key
+func (b *Bundle) T(locale, key string, data ...Data) string {
+	msg, ok := b.lookup(locale, key)
+	if !ok {
+		return format(key, data)
+	}
+	return format(msg["other"], data)
+}
+
+// TN 按数量翻译复数形式，count 可通过 {count} 占位符使用
+func (b *Bundle) TN(locale, key string, count int, data ...Data) string {
+	vars := Data{"count": count}
+	for _, d := range data {
+		for k, v := range d {
+			vars[k] = v
+		}
+	}
+
+	msg, ok := b.lookup(locale, key)
+	if !ok {
+		return format(key, []Data{vars})
+	}
+	text, ok := msg[PluralCategory(locale, count)]
+	if !ok {
+		text = msg["other"]
+	}
+	return format(text, []Data{vars})
+}
+
+// Match 从候选语言中匹配已加载的语言，候选语言可以是 Accept-Language 格式，无法匹配时返回默认语言
+func (b *Bundle) Match(candidates ...string) string {
+	if locale, ok := b.match(candidates...); ok {
+		return locale
+	}
+	return b.Conf.DefaultLocale
+}
+
+// match 从候选语言中匹配已加载的语言
+func (b *Bundle) match(candidates ...string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var tags []language.Tag
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		parsed, _, err := language.ParseAcceptLanguage(candidate)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, parsed...)
+	}
+	if len(tags) == 0 || len(b.tags) == 0 {
+		return "", false
+	}
+	_, index, confidence := b.matcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return b.tags[index].String(), true
+}
+
+// lookup 查找翻译，依次尝试 指定语言、基础语言（如 en-US => en）、默认语言
+func (b *Bundle) lookup(locale, key string) (message, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range b.fallbacks(locale) {
+		if msg, ok := b.messages[l][key]; ok {
+			return msg, true
+		}
+	}
+	return nil, false
+}
+
+// fallbacks 获取语言的回退顺序
+func (b *Bundle) fallbacks(locale string) []string {
+	var locales []string
+	if tag, err := language.Parse(locale); err == nil {
+		locales = append(locales, tag.String())
+		if base, confidence := tag.Base(); confidence != language.No && base.String() != tag.String() {
+			locales = append(locales, base.String())
+		}
+	} else if locale != "" {
+		locales = append(locales, locale)
+	}
+	return append(locales, b.Conf.DefaultLocale)
+}
+
+// rebuild 重建语言匹配器
+func (b *Bundle) rebuild() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rebuildLocked()
+}
+
+func (b *Bundle) rebuildLocked() {
+	// 默认语言排在第一位，作为匹配失败时的结果
+	b.tags = b.tags[:0]
+	if tag, err := language.Parse(b.Conf.DefaultLocale); err == nil {
+		b.tags = append(b.tags, tag)
+	}
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		if tag, err := language.Parse(locale); err == nil && tag.String() != b.Conf.DefaultLocale {
+			b.tags = append(b.tags, tag)
+		}
+	}
+	b.matcher = language.NewMatcher(b.tags)
+}
+
+// flatten 将嵌套的翻译展开为以"."连接的键
+func flatten(prefix string, raw map[string]any, out map[string]message) {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case string:
+			out[key] = message{"other": val}
+		case map[string]any:
+			if plural, ok := toPlural(val); ok {
+				out[key] = plural
+			} else {
+				flatten(key, val, out)
+			}
+		default:
+			out[key] = message{"other": fmt.Sprint(val)}
+		}
+	}
+}
+
+// toPlural 判断是否为复数翻译
+func toPlural(raw map[string]any) (message, bool) {
+	if _, ok := raw["other"]; !ok {
+		return nil, false
+	}
+	msg := make(message, len(raw))
+	for k, v := range raw {
+		text, ok := v.(string)
+		if !ok || !isPluralCategory(k) {
+			return nil, false
+		}
+		msg[k] = text
+	}
+	return msg, true
+}
+
+// Data 翻译占位符数据
+type Data map[string]any
+
+// format 替换 {name} 占位符
+func format(text string, data []Data) string {
+	if len(data) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, 8)
+	for _, d := range data {
+		for k, v := range d {
+			pairs = append(pairs, "{"+k+"}", fmt.Sprint(v))
+		}
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
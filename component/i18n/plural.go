@@ -0,0 +1,134 @@
+package i18n
+
+import (
+	"golang.org/x/text/language"
+	"sync"
+)
+
+// 复数类别，参见 CLDR 复数规则
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// PluralRule 复数规则，根据数量返回复数类别
+type PluralRule func(n int) string
+
+var (
+	pluralRulesMu sync.RWMutex
+	pluralRules   = map[string]PluralRule{
+		"zh": pluralOther, "ja": pluralOther, "ko": pluralOther, "vi": pluralOther, "th": pluralOther, "id": pluralOther, "ms": pluralOther,
+		"en": pluralOneOther, "de": pluralOneOther, "nl": pluralOneOther, "sv": pluralOneOther, "da": pluralOneOther, "no": pluralOneOther,
+		"nb": pluralOneOther, "fi": pluralOneOther, "it": pluralOneOther, "es": pluralOneOther, "el": pluralOneOther, "tr": pluralOneOther,
+		"fr": pluralFrench, "pt": pluralFrench,
+		"ru": pluralSlavic, "uk": pluralSlavic, "be": pluralSlavic,
+		"pl": pluralPolish,
+		"ar": pluralArabic,
+	}
+)
+
+// RegisterPluralRule 注册或覆盖语言的复数规则，lang 为基础语言，如 en、zh
+func RegisterPluralRule(lang string, rule PluralRule) {
+	pluralRulesMu.Lock()
+	defer pluralRulesMu.Unlock()
+	pluralRules[lang] = rule
+}
+
+// PluralCategory 获取语言在指定数量下的复数类别，未知语言按英语规则处理
+func PluralCategory(locale string, n int) string {
+	pluralRulesMu.RLock()
+	rule, ok := pluralRules[baseLanguage(locale)]
+	pluralRulesMu.RUnlock()
+	if !ok {
+		rule = pluralOneOther
+	}
+	return rule(n)
+}
+
+// baseLanguage 获取基础语言，如 zh-CN => zh
+func baseLanguage(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return locale
+	}
+	base, _ := tag.Base()
+	return base.String()
+}
+
+func isPluralCategory(category string) bool {
+	switch category {
+	case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		return true
+	}
+	return false
+}
+
+func pluralOther(int) string {
+	return PluralOther
+}
+
+func pluralOneOther(n int) string {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralFrench(n int) string {
+	if n == 0 || n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralSlavic(n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralPolish(n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch mod10, mod100 := n%10, n%100; {
+	case n == 1:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralArabic(n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch mod100 := n % 100; {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case mod100 >= 3 && mod100 <= 10:
+		return PluralFew
+	case mod100 >= 11:
+		return PluralMany
+	default:
+		return PluralOther
+	}
+}
@@ -0,0 +1,151 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"strings"
+)
+
+// validationMessages 内置的校验提示，可在翻译文件中通过 validation.<tag> 覆盖
+var validationMessages = map[string]map[string]string{
+	"zh": {
+		"default":  "{field}格式不正确",
+		"required": "{field}不能为空",
+		"min":      "{field}长度或数值不能小于{param}",
+		"max":      "{field}长度或数值不能大于{param}",
+		"len":      "{field}长度必须为{param}",
+		"eq":       "{field}必须等于{param}",
+		"ne":       "{field}不能等于{param}",
+		"gt":       "{field}必须大于{param}",
+		"gte":      "{field}必须大于或等于{param}",
+		"lt":       "{field}必须小于{param}",
+		"lte":      "{field}必须小于或等于{param}",
+		"oneof":    "{field}必须是[{param}]中的一个",
+		"email":    "{field}必须是有效的邮箱地址",
+		"url":      "{field}必须是有效的URL",
+		"numeric":  "{field}必须是数字",
+		"number":   "{field}必须是数字",
+		"alpha":    "{field}只能包含字母",
+		"alphanum": "{field}只能包含字母和数字",
+		"ip":       "{field}必须是有效的IP地址",
+		"datetime": "{field}必须符合{param}格式",
+		"eqfield":  "{field}必须等于{param}",
+		"enum":     "{field}不是有效的选项",
+		"bankcard": "{field}不是有效的银行卡号",
+		"plate":    "{field}不是有效的车牌号",
+		"uscc":     "{field}不是有效的统一社会信用代码",
+		"passport": "{field}不是有效的护照号码",
+	},
+	"en": {
+		"default":  "{field} is invalid",
+		"required": "{field} is required",
+		"min":      "{field} must be at least {param}",
+		"max":      "{field} must be at most {param}",
+		"len":      "{field} must be {param} in length",
+		"eq":       "{field} must be equal to {param}",
+		"ne":       "{field} must not be equal to {param}",
+		"gt":       "{field} must be greater than {param}",
+		"gte":      "{field} must be greater than or equal to {param}",
+		"lt":       "{field} must be less than {param}",
+		"lte":      "{field} must be less than or equal to {param}",
+		"oneof":    "{field} must be one of [{param}]",
+		"email":    "{field} must be a valid email address",
+		"url":      "{field} must be a valid URL",
+		"numeric":  "{field} must be numeric",
+		"number":   "{field} must be a number",
+		"alpha":    "{field} can only contain letters",
+		"alphanum": "{field} can only contain letters and numbers",
+		"ip":       "{field} must be a valid IP address",
+		"datetime": "{field} must match the format {param}",
+		"eqfield":  "{field} must be equal to {param}",
+		"enum":     "{field} is not a valid option",
+		"bankcard": "{field} is not a valid bank card number",
+		"plate":    "{field} is not a valid license plate number",
+		"uscc":     "{field} is not a valid unified social credit code",
+		"passport": "{field} is not a valid passport number",
+	},
+}
+
+// ValidationErrors 按请求的语言翻译 validator 的校验错误，返回字段名 => 提示信息
+//
+// 提示信息依次查找翻译键 validation.<结构体名>.<字段名>.<tag>、validation.<tag>、内置提示；
+// 字段名通过翻译键 fields.<字段名> 翻译，可使用 {field}、{param}、{value} 占位符
+//
+// 示例:
+//
+//	if err := c.ShouldBind(&form); err != nil {
+//	    c.JSON(200, jcbaseGo.Result{Code: errcode.ParamError, Message: i18n.ValidationMessage(c, err)})
+//	    return
+//	}
+func ValidationErrors(c *gin.Context, err error) map[string]string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	b, locale := FromContext(c), Locale(c)
+	result := make(map[string]string, len(validationErrs))
+	for _, fe := range validationErrs {
+		result[fe.Field()] = b.validationMessage(locale, fe)
+	}
+	return result
+}
+
+// ValidationMessage 按请求的语言翻译校验错误，多个错误以"；"连接
+// 非校验错误时返回 err.Error()，err为nil时返回空字符串
+func ValidationMessage(c *gin.Context, err error) string {
+	if err == nil {
+		return ""
+	}
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err.Error()
+	}
+
+	b, locale := FromContext(c), Locale(c)
+	messages := make([]string, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		messages = append(messages, b.validationMessage(locale, fe))
+	}
+	sep := "; "
+	if baseLanguage(locale) == "zh" {
+		sep = "；"
+	}
+	return strings.Join(messages, sep)
+}
+
+// validationMessage 翻译单个校验错误
+func (b *Bundle) validationMessage(locale string, fe validator.FieldError) string {
+	data := Data{
+		"field": b.fieldName(locale, fe.Field()),
+		"param": fe.Param(),
+		"value": fmt.Sprint(fe.Value()),
+	}
+
+	keys := []string{"validation." + fe.StructNamespace() + "." + fe.Tag(), "validation." + fe.Tag()}
+	for _, key := range keys {
+		if b.Has(locale, key) {
+			return b.T(locale, key, data)
+		}
+	}
+
+	messages, ok := validationMessages[baseLanguage(locale)]
+	if !ok {
+		messages = validationMessages["en"]
+	}
+	text, ok := messages[fe.Tag()]
+	if !ok {
+		text = messages["default"]
+	}
+	return format(text, []Data{data})
+}
+
+// fieldName 翻译字段名
+func (b *Bundle) fieldName(locale, field string) string {
+	if key := "fields." + field; b.Has(locale, key) {
+		return b.T(locale, key)
+	}
+	return field
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/excel"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+)
+
+// Handler 导入接口，需自行在路由分组上添加登录、权限等中间件
+type Handler[T any] struct {
+	importer *Importer[T]
+}
+
+// Handler 获取导入接口
+//
+// 示例:
+//
+//	admin := r.Group("/admin/user/import", middleware.LoginRequired())
+//	users.Handler().RegisterRoutes(admin)
+func (i *Importer[T]) Handler() *Handler[T] {
+	return &Handler[T]{importer: i}
+}
+
+// RegisterRoutes 注册路由：
+//   - GET  /template  下载导入模板（仅包含表头的 xlsx）
+//   - POST /upload    上传文件（表单字段 file），返回导入令牌、错误行及预览数据
+//   - POST /commit    提交导入，参数 token
+//   - POST /discard   放弃导入，参数 token
+func (h *Handler[T]) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/template", h.Template)
+	r.POST("/upload", h.Upload)
+	r.POST("/commit", h.Commit)
+	r.POST("/discard", h.Discard)
+}
+
+type tokenForm struct {
+	Token string `json:"token" form:"token" binding:"required,max=64"`
+}
+
+// Template 下载导入模板
+func (h *Handler[T]) Template(c *gin.Context) {
+	_ = excel.Download(c, h.importer.opt.Name+"导入模板.xlsx", func(w *excel.Writer) error {
+		return w.NewSheet(h.importer.opt.Name, h.importer.Columns())
+	})
+}
+
+// Upload 上传文件并预览
+func (h *Handler[T]) Upload(c *gin.Context) {
+	preview, err := h.importer.Stage(c, "file")
+	if err != nil {
+		controller.Base{GinContext: c}.Error(err)
+		return
+	}
+	controller.Base{GinContext: c}.Success(preview)
+}
+
+// Commit 提交导入，全部回滚时返回失败及逐行的错误信息
+func (h *Handler[T]) Commit(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form tokenForm
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	result, err := h.importer.Commit(c, form.Token)
+	if err != nil {
+		base.Error(err)
+		return
+	}
+	if !result.Committed {
+		base.Failure("导入失败，数据已全部回滚", result, errcode.ParamInvalid)
+		return
+	}
+	base.Result(errcode.SuccessChange, "success", result)
+}
+
+// Discard 放弃导入
+func (h *Handler[T]) Discard(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form tokenForm
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.importer.Discard(form.Token); err != nil {
+		base.Error(err)
+		return
+	}
+	base.Result(errcode.SuccessDelete, "success")
+}
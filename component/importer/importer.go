@@ -0,0 +1,420 @@
+// Package importer 数据导入：上传文件、解析并校验后预览错误，确认后在事务中提交，适用于管理后台的批量导入
+//
+// 流程分为三步：
+//  1. Stage 通过 attachment 保存上传的 .xlsx/.csv 文件，解析并校验全部数据行，返回导入令牌及预览；
+//  2. 前端展示错误行及部分数据，由用户确认；
+//  3. Commit 重新解析并校验（数据库中的数据可能已变化），在同一事务中保存，返回逐行的失败原因。
+//
+// 默认任一行失败时回滚整个事务；开启 PartialCommit 后跳过失败的行，保存失败的行回滚到保存点后继续。
+// 导入令牌默认保存在进程内存中，多实例部署时应传入共享的 Cache，并保证附件目录为共享存储。
+// 上传后未提交也未放弃的文件在令牌过期后不会自动删除，需定期清理附件组目录。
+package importer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/attachment"
+	"github.com/jcbowen/jcbaseGo/component/excel"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/helper/cache"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 错误
+var (
+	ErrExpired  = errcode.New(errcode.NotExist, "导入批次不存在或已过期，请重新上传")
+	ErrFileType = errcode.New(errcode.IllegalFormat, "仅支持 xlsx、csv 格式的文件")
+)
+
+// errRollback 全部回滚时用于结束事务
+var errRollback = errors.New("importer: rollback")
+
+// Cache 导入令牌缓存，多实例部署时应使用共享缓存（如 redis.CacheOpt）
+type Cache interface {
+	Set(key string, value interface{}, args ...time.Duration) error
+	GetString(key string) (string, error)
+	Del(key string) error
+}
+
+// Options 配置
+type Options[T any] struct {
+	Name       string                     `default:"数据"` // 导入的数据名称，用于模板文件名
+	Attachment *jcbaseGo.AttachmentStruct // 附件配置，为空时使用默认配置
+	Group      string                     `default:"import"` // 附件组
+	MaxSize    int64                      // 上传文件最大字节数，默认使用办公文件的限制
+
+	Import      excel.ImportOptions // 解析配置：工作表、表头行、列定义及最多记录的错误数
+	MaxRows     int                 `default:"10000"` // 最大数据行数
+	PreviewRows int                 `default:"20"`    // 预览返回的有效数据行数
+	TTL         time.Duration       // 导入令牌有效期，默认30分钟
+	Cache       Cache               // 导入令牌缓存，为空时使用进程内缓存
+
+	// PartialCommit 是否允许部分提交，为 true 时跳过失败的行并提交其余数据，默认任一行失败时全部回滚
+	PartialCommit bool
+
+	// Validate 自定义校验，在结构体 binding 标签校验通过后调用，预览及提交时均会执行
+	Validate func(ctx context.Context, row int, item *T) error
+	// Save 保存单行数据，为空时使用 tx.Create；tx 为导入事务，需使用 tx 执行数据库操作。
+	// 返回 errcode 错误时其提示信息作为该行的错误信息，其他错误记录日志并提示"数据保存失败"
+	Save  func(tx *gorm.DB, row int, item *T) error
+	Table string // 默认保存时使用的表名，为空时使用模型的表名
+}
+
+// RowError 行错误
+type RowError struct {
+	Row     int    `json:"row"`     // 行号
+	Column  string `json:"column"`  // 列标题，行级错误时为空
+	Message string `json:"message"` // 错误信息
+}
+
+// Preview 预览结果
+type Preview[T any] struct {
+	Token     string     `json:"token"`     // 导入令牌，提交或放弃时传入
+	FileName  string     `json:"file_name"` // 上传的文件名
+	Total     int        `json:"total"`     // 数据行数
+	Valid     int        `json:"valid"`     // 校验通过的行数
+	Invalid   int        `json:"invalid"`   // 校验失败的行数
+	Truncated bool       `json:"truncated"` // 错误行过多，已停止解析，仅包含部分错误
+	Errors    []RowError `json:"errors"`    // 错误详情
+	Rows      []T        `json:"rows"`      // 前 PreviewRows 条有效数据
+}
+
+// Result 提交结果
+type Result struct {
+	Committed bool       `json:"committed"` // 是否已提交，全部回滚时为 false
+	Truncated bool       `json:"truncated"` // 错误行过多，已停止解析并全部回滚
+	Total     int        `json:"total"`     // 数据行数
+	Success   int        `json:"success"`   // 已保存的行数，全部回滚时为0
+	Failed    int        `json:"failed"`    // 失败的行数
+	Errors    []RowError `json:"errors"`    // 错误详情
+}
+
+// Importer 数据导入
+//
+// 示例:
+//
+//	type UserRow struct {
+//	    Username string `excel:"用户名,required" binding:"min=3,max=32"`
+//	    Mobile   string `excel:"手机号" binding:"omitempty,len=11"`
+//	    Status   int    `excel:"状态,enum=1:启用|0:禁用"`
+//	}
+//
+//	users := importer.New[UserRow](db.GetDb(), importer.Options[UserRow]{
+//	    Name: "用户",
+//	    Save: func(tx *gorm.DB, row int, item *UserRow) error {
+//	        return tx.Create(&User{Username: item.Username, Mobile: item.Mobile, Status: item.Status}).Error
+//	    },
+//	})
+//	users.Handler().RegisterRoutes(admin.Group("/user/import"))
+type Importer[T any] struct {
+	db      *gorm.DB
+	opt     Options[T]
+	columns []excel.Column
+	cache   Cache
+	claimMu sync.Mutex
+}
+
+// New 创建数据导入
+func New[T any](db *gorm.DB, opts ...Options[T]) *Importer[T] {
+	var opt Options[T]
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.Attachment == nil {
+		opt.Attachment = &jcbaseGo.AttachmentStruct{}
+	}
+	if opt.TTL <= 0 {
+		opt.TTL = 30 * time.Minute
+	}
+	if opt.Import.MaxErrors <= 0 {
+		opt.Import.MaxErrors = 100
+	}
+	if opt.Import.Columns == nil {
+		var zero T
+		opt.Import.Columns = excel.Columns(zero)
+	}
+	opt.Import.StopOnError = false
+
+	i := &Importer[T]{
+		db:      db,
+		opt:     opt,
+		columns: opt.Import.Columns,
+		cache:   opt.Cache,
+	}
+	if i.cache == nil {
+		i.cache = cache.NewMemory()
+	}
+	return i
+}
+
+// Stage 保存上传的文件（field 为表单字段名），解析并校验全部数据行，返回导入令牌及预览
+func (i *Importer[T]) Stage(c *gin.Context, field string) (*Preview[T], error) {
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, errcode.New(errcode.ParamError, "请上传导入文件")
+	}
+	if ext := strings.ToLower(filepath.Ext(header.Filename)); ext != ".xlsx" && ext != ".csv" {
+		return nil, ErrFileType
+	}
+	att := attachment.New(c, i.opt.Attachment).Upload(&attachment.Options{
+		Group:    i.opt.Group,
+		FileData: header,
+		FileType: "office",
+		MaxSize:  i.opt.MaxSize,
+		AllowExt: []string{".xlsx", ".csv"},
+	}).Save()
+	if att.HasError() {
+		return nil, errcode.Wrap(att.Error(), errcode.ParamError, att.Error().Error())
+	}
+	path := filepath.Join(att.BaseConfig.LocalDir, att.FileAttachment)
+
+	preview, err := i.check(c, path)
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	preview.Token = randomHex(16)
+	preview.FileName = header.Filename
+	if err = i.cache.Set(i.cacheKey(preview.Token), path, i.opt.TTL); err != nil {
+		_ = os.Remove(path)
+		return nil, errcode.Wrap(err, errcode.SystemBusy, "")
+	}
+	return preview, nil
+}
+
+// Commit 提交导入，重新解析并校验后在事务中保存
+//
+// 返回错误表示导入未执行（令牌无效、文件损坏或数据库错误）；数据行失败时通过 Result 返回，
+// 未开启 PartialCommit 时 Result.Committed 为 false，令牌仍然有效，可修正数据库中的冲突数据后重新提交
+func (i *Importer[T]) Commit(ctx context.Context, token string) (*Result, error) {
+	path, err := i.claim(token)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	saveFailed := false // 未开启部分提交时，首个保存失败的行之后只校验不保存
+	err = i.db.WithContext(dbContext(ctx)).Transaction(func(tx *gorm.DB) error {
+		parsed, err := excel.ImportFile(path, func(row int, item *T) error {
+			if err := i.validate(ctx, row, item); err != nil {
+				return err
+			}
+			if !i.opt.PartialCommit {
+				if saveFailed {
+					return nil
+				}
+				if err := i.save(tx, row, item); err != nil {
+					saveFailed = true
+					return err
+				}
+				return nil
+			}
+			// 保存失败时回滚到保存点，避免部分数据库（如PostgreSQL）中事务整体失效
+			if err := tx.SavePoint("importer_row").Error; err != nil {
+				return err
+			}
+			if err := i.save(tx, row, item); err != nil {
+				if rbErr := tx.RollbackTo("importer_row").Error; rbErr != nil {
+					return rbErr
+				}
+				return err
+			}
+			return nil
+		}, i.opt.Import)
+		if parsed != nil {
+			result.Total, result.Success, result.Failed = parsed.Total, parsed.Success, parsed.Failed
+			result.Errors = i.rowErrors(ctx, parsed.Errors)
+		}
+		if err != nil {
+			if parsed != nil && len(parsed.Errors) >= i.opt.Import.MaxErrors {
+				result.Truncated = true
+				return errRollback // 错误行过多时已停止解析，其余数据行未处理，全部回滚
+			}
+			return err
+		}
+		if result.Failed > 0 && !i.opt.PartialCommit {
+			return errRollback
+		}
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		result.Committed = true
+		i.remove(token, path)
+		return result, nil
+	case errors.Is(err, errRollback):
+		result.Success = 0
+		i.restore(token, path)
+		return result, nil
+	default:
+		i.restore(token, path)
+		return nil, errcode.Wrap(err, errcode.SystemBusy, "")
+	}
+}
+
+// Discard 放弃导入，删除暂存的文件
+func (i *Importer[T]) Discard(token string) error {
+	path, err := i.claim(token)
+	if err != nil {
+		return err
+	}
+	i.remove(token, path)
+	return nil
+}
+
+// Columns 导入的列定义，可用于生成导入模板
+func (i *Importer[T]) Columns() []excel.Column {
+	return i.columns
+}
+
+// ----- 内部方法 ----- /
+
+// check 解析并校验文件，不保存数据
+func (i *Importer[T]) check(ctx context.Context, path string) (*Preview[T], error) {
+	preview := &Preview[T]{Rows: make([]T, 0)}
+	rows, tooMany := 0, false
+	parsed, err := excel.ImportFile(path, func(row int, item *T) error {
+		// 转换失败的行不会进入回调，其数量受 MaxErrors 限制
+		if rows++; rows > i.opt.MaxRows {
+			tooMany = true
+			return excel.ErrStop
+		}
+		if err := i.validate(ctx, row, item); err != nil {
+			return err
+		}
+		if len(preview.Rows) < i.opt.PreviewRows {
+			preview.Rows = append(preview.Rows, *item)
+		}
+		return nil
+	}, i.opt.Import)
+	if tooMany {
+		return nil, errcode.Newf(errcode.IllegalSize, "数据不能超过%d行", i.opt.MaxRows)
+	}
+	if parsed == nil {
+		return nil, errcode.Wrap(err, errcode.IllegalFormat, "文件解析失败: "+errMessage(err))
+	}
+	if err != nil {
+		// 错误行达到上限时停止解析，其余错误（如缺少必填列）视为文件无效
+		if len(parsed.Errors) < i.opt.Import.MaxErrors {
+			return nil, errcode.Wrap(err, errcode.IllegalFormat, errMessage(err))
+		}
+		preview.Truncated = true
+	}
+	preview.Total, preview.Valid, preview.Invalid = parsed.Total, parsed.Success, parsed.Failed
+	preview.Errors = i.rowErrors(ctx, parsed.Errors)
+	return preview, nil
+}
+
+// validate 校验结构体的 binding 标签，通过后执行自定义校验
+func (i *Importer[T]) validate(ctx context.Context, row int, item *T) error {
+	if err := binding.Validator.ValidateStruct(item); err != nil {
+		return err
+	}
+	if i.opt.Validate != nil {
+		return i.opt.Validate(ctx, row, item)
+	}
+	return nil
+}
+
+// save 保存单行数据，数据库错误不直接返回给前端
+func (i *Importer[T]) save(tx *gorm.DB, row int, item *T) error {
+	var err error
+	if i.opt.Save != nil {
+		err = i.opt.Save(tx, row, item)
+	} else {
+		if i.opt.Table != "" {
+			tx = tx.Table(i.opt.Table)
+		}
+		err = tx.Create(item).Error
+	}
+	var appErr *errcode.AppError
+	if err == nil || errors.As(err, &appErr) {
+		return err
+	}
+	log.Printf("importer: 第%d行保存失败: %v", row, err)
+	return errcode.Wrap(err, errcode.SystemBusy, "数据保存失败")
+}
+
+// rowErrors 转换行错误，校验错误按请求的语言翻译
+func (i *Importer[T]) rowErrors(ctx context.Context, errs []excel.RowError) []RowError {
+	c, _ := ctx.(*gin.Context)
+	list := make([]RowError, 0, len(errs))
+	for _, e := range errs {
+		message := i18n.ValidationMessage(c, e.Err)
+		var appErr *errcode.AppError
+		if errors.As(e.Err, &appErr) {
+			message = appErr.Message
+		}
+		list = append(list, RowError{Row: e.Row, Column: e.Column, Message: message})
+	}
+	return list
+}
+
+// claim 取出令牌对应的文件并使令牌失效，防止同一批次被并发提交
+func (i *Importer[T]) claim(token string) (string, error) {
+	if token == "" {
+		return "", ErrExpired
+	}
+	i.claimMu.Lock()
+	defer i.claimMu.Unlock()
+	path, err := i.cache.GetString(i.cacheKey(token))
+	if err != nil {
+		return "", errcode.Wrap(err, errcode.SystemBusy, "")
+	}
+	if path == "" {
+		return "", ErrExpired
+	}
+	if err = i.cache.Del(i.cacheKey(token)); err != nil {
+		return "", errcode.Wrap(err, errcode.SystemBusy, "")
+	}
+	return path, nil
+}
+
+// restore 未提交时恢复令牌，以便重新提交
+func (i *Importer[T]) restore(token, path string) {
+	_ = i.cache.Set(i.cacheKey(token), path, i.opt.TTL)
+}
+
+// remove 删除令牌及暂存的文件
+func (i *Importer[T]) remove(token, path string) {
+	_ = i.cache.Del(i.cacheKey(token))
+	_ = os.Remove(path)
+}
+
+func (i *Importer[T]) cacheKey(token string) string {
+	return "importer:" + i.opt.Group + ":" + token
+}
+
+// dbContext gin.Context 默认不随请求取消，数据库操作使用请求的上下文
+func dbContext(ctx context.Context) context.Context {
+	if c, ok := ctx.(*gin.Context); ok && c.Request != nil {
+		return c.Request.Context()
+	}
+	return ctx
+}
+
+// errMessage 去掉 excel 包错误信息的前缀
+func errMessage(err error) string {
+	return strings.TrimPrefix(err.Error(), "excel: ")
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,58 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Server 阻塞运行的服务，如 server.Instance、grpc.Server，run 返回 http.ErrServerClosed 视为正常关闭
+//
+// 示例:
+//
+//	m.Add(lifecycle.Server("http", srv.Run, srv.Shutdown))
+func Server(name string, run func() error, shutdown func(ctx context.Context) error) Hook {
+	return Hook{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			if err := run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		OnStop: shutdown,
+	}
+}
+
+// Worker 后台任务，如 webhook.Dispatcher、dbbackup.Backup，start 启动后台协程后返回，stop 等待任务结束
+//
+// 示例:
+//
+//	m.Add(lifecycle.Worker("webhook", dispatcher.Start, dispatcher.Stop))
+func Worker(name string, start func(), stop func()) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stop()
+			return nil
+		},
+	}
+}
+
+// Closer 只需在退出时关闭的资源，如数据库、redis 连接
+//
+// 示例:
+//
+//	m.Add(lifecycle.Closer("redis", rdb.Client.Close))
+func Closer(name string, close func() error) Hook {
+	return Hook{
+		Name: name,
+		OnStop: func(ctx context.Context) error {
+			return close()
+		},
+	}
+}
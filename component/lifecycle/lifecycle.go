@@ -0,0 +1,222 @@
+// Package lifecycle 组件生命周期管理：按注册顺序启动服务、队列、定时任务等组件，
+// 收到 SIGINT/SIGTERM 或任一组件异常退出时按相反顺序优雅关闭
+//
+// 示例:
+//
+//	srv := server.New(conf.Server, r)
+//	err := lifecycle.New().
+//		Add(lifecycle.Closer("mysql", func() error { sqlDB, _ := db.Db.DB(); return sqlDB.Close() })).
+//		Add(lifecycle.Worker("webhook", dispatcher.Start, dispatcher.Stop)).
+//		Add(lifecycle.Server("http", srv.Run, srv.Shutdown)).
+//		Run(context.Background())
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/logger"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Hook 组件的启动、运行及停止函数，均可为空
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error // 启动，返回前组件应已就绪，ctx 在 StartTimeout 后取消
+	Run     func(ctx context.Context) error // 阻塞运行（如 http 服务），返回错误时关闭全部组件；ctx 在开始关闭时取消
+	OnStop  func(ctx context.Context) error // 停止，ctx 在 StopTimeout 后取消
+}
+
+// Options 配置
+type Options struct {
+	StartTimeout time.Duration // 每个组件启动的超时时间，默认15秒
+	StopTimeout  time.Duration // 关闭全部组件的超时时间，默认30秒
+	Signals      []os.Signal   // 触发关闭的信号，默认 SIGINT、SIGTERM；关闭过程中再次收到信号时立即退出进程
+}
+
+// Manager 生命周期管理
+type Manager struct {
+	opt   Options
+	hooks []Hook
+
+	mu       sync.Mutex
+	started  bool
+	shutdown chan struct{}
+	once     sync.Once
+}
+
+// New 创建生命周期管理
+func New(opts ...Options) *Manager {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.StartTimeout <= 0 {
+		opt.StartTimeout = 15 * time.Second
+	}
+	if opt.StopTimeout <= 0 {
+		opt.StopTimeout = 30 * time.Second
+	}
+	if opt.Signals == nil {
+		opt.Signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	return &Manager{opt: opt, shutdown: make(chan struct{})}
+}
+
+// Add 注册组件，按注册顺序启动、相反顺序停止，应先注册数据库等被依赖的组件；Run 之后注册的组件不会启动
+func (m *Manager) Add(hooks ...Hook) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.started {
+		m.hooks = append(m.hooks, hooks...)
+	}
+	return m
+}
+
+// Shutdown 通知 Run 开始关闭，可重复调用
+func (m *Manager) Shutdown() {
+	m.once.Do(func() {
+		close(m.shutdown)
+	})
+}
+
+// Run 启动全部组件并阻塞，直到 ctx 结束、收到关闭信号、调用 Shutdown 或组件的 Run 返回错误，之后按相反顺序停止已启动的组件
+// 组件启动失败或异常退出时返回对应错误，停止过程中的错误一并返回
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.started {
+		m.mu.Unlock()
+		return errors.New("lifecycle: 重复调用 Run")
+	}
+	m.started = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	signals := make(chan os.Signal, 2)
+	if len(m.opt.Signals) > 0 {
+		signal.Notify(signals, m.opt.Signals...)
+		defer signal.Stop(signals)
+	}
+
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+	failed := make(chan error, len(hooks))
+	var runners sync.WaitGroup
+
+	// 按顺序启动
+	started := 0
+	var runErr error
+	for _, hook := range hooks {
+		if err := m.start(runCtx, hook); err != nil {
+			runErr = fmt.Errorf("lifecycle: %s 启动失败: %w", hook.Name, err)
+			break
+		}
+		started++
+		if hook.Run != nil {
+			runners.Add(1)
+			go func(hook Hook) {
+				defer runners.Done()
+				if err := hook.Run(runCtx); err != nil && runCtx.Err() == nil {
+					failed <- fmt.Errorf("lifecycle: %s 异常退出: %w", hook.Name, err)
+				}
+			}(hook)
+		}
+	}
+
+	// 等待关闭
+	if runErr == nil {
+		logger.Info("全部组件已启动", "module", "lifecycle", "components", started)
+		select {
+		case <-ctx.Done():
+			logger.Info("开始关闭", "module", "lifecycle", "reason", ctx.Err())
+		case sig := <-signals:
+			logger.Info("开始关闭", "module", "lifecycle", "signal", sig.String())
+		case <-m.shutdown:
+			logger.Info("开始关闭", "module", "lifecycle", "reason", "shutdown")
+		case runErr = <-failed:
+			logger.Error("开始关闭", "module", "lifecycle", "error", runErr)
+		}
+	} else {
+		logger.Error("启动失败，关闭已启动的组件", "module", "lifecycle", "error", runErr)
+	}
+
+	// 关闭过程中再次收到信号时立即退出
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case sig := <-signals:
+			logger.Error("关闭过程中再次收到信号，立即退出", "module", "lifecycle", "signal", sig.String())
+			os.Exit(1)
+		case <-stopped:
+		}
+	}()
+
+	// 按相反顺序停止
+	cancelRun()
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), m.opt.StopTimeout)
+	defer cancelStop()
+	errs := []error{runErr}
+	for i := started - 1; i >= 0; i-- {
+		if err := m.stop(stopCtx, hooks[i]); err != nil {
+			errs = append(errs, fmt.Errorf("lifecycle: %s 停止失败: %w", hooks[i].Name, err))
+		}
+	}
+
+	// 等待 Run 返回，超时后不再等待
+	done := make(chan struct{})
+	go func() {
+		runners.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-stopCtx.Done():
+		select {
+		case <-done:
+		default:
+			errs = append(errs, errors.New("lifecycle: 等待组件退出超时"))
+		}
+	}
+	logger.Info("全部组件已停止", "module", "lifecycle")
+	return errors.Join(errs...)
+}
+
+// start 在超时时间内启动组件
+func (m *Manager) start(ctx context.Context, hook Hook) error {
+	if hook.OnStart == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.opt.StartTimeout)
+	defer cancel()
+	return call(ctx, hook.OnStart)
+}
+
+// stop 停止组件，超时后不再等待
+func (m *Manager) stop(ctx context.Context, hook Hook) error {
+	if hook.OnStop == nil {
+		return nil
+	}
+	begin := time.Now()
+	err := call(ctx, hook.OnStop)
+	logger.Info("组件已停止", "module", "lifecycle", "name", hook.Name, "elapsed", time.Since(begin).String())
+	return err
+}
+
+// call 执行函数，ctx 结束时不再等待并返回 ctx 的错误
+func call(ctx context.Context, fn func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
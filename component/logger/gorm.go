@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"log/slog"
+	"time"
+)
+
+// GormLogger 将gorm的日志输出到结构化日志中
+//
+// 示例:
+//
+//	db := mysql.New(conf.Db)
+//	db.Db.Logger = logger.Default().Gorm(200 * time.Millisecond)
+type GormLogger struct {
+	logger                    *Logger
+	level                     gormlogger.LogLevel
+	SlowThreshold             time.Duration // 慢查询阈值，超过时以warn级别记录，0表示不记录慢查询
+	IgnoreRecordNotFoundError bool          // 是否忽略记录不存在的错误
+}
+
+// Gorm 创建gorm日志适配器，slowThreshold为可选的慢查询阈值，默认200毫秒
+func (l *Logger) Gorm(slowThreshold ...time.Duration) *GormLogger {
+	threshold := 200 * time.Millisecond
+	if len(slowThreshold) > 0 {
+		threshold = slowThreshold[0]
+	}
+	return &GormLogger{
+		logger:                    l.With("module", "gorm"),
+		level:                     gormlogger.Warn,
+		SlowThreshold:             threshold,
+		IgnoreRecordNotFoundError: true,
+	}
+}
+
+// With 返回携带固定字段的gorm日志，如请求ID，使SQL日志能与请求关联
+func (g *GormLogger) With(args ...any) *GormLogger {
+	clone := *g
+	clone.logger = g.logger.With(args...)
+	return &clone
+}
+
+func (g *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *g
+	clone.level = level
+	return &clone
+}
+
+func (g *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= gormlogger.Info {
+		g.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= gormlogger.Warn {
+		g.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= gormlogger.Error {
+		g.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if g.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && g.level >= gormlogger.Error && !(g.IgnoreRecordNotFoundError && errors.Is(err, gorm.ErrRecordNotFound)):
+		sql, rows := fc()
+		g.logger.LogAttrs(ctx, slog.LevelError, "sql error", slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed), slog.String("err", err.Error()))
+	case g.SlowThreshold > 0 && elapsed > g.SlowThreshold && g.level >= gormlogger.Warn:
+		sql, rows := fc()
+		g.logger.LogAttrs(ctx, slog.LevelWarn, "slow sql", slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed), slog.Duration("threshold", g.SlowThreshold))
+	case g.level >= gormlogger.Info:
+		sql, rows := fc()
+		g.logger.LogAttrs(ctx, slog.LevelDebug, "sql", slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed))
+	}
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Logger 结构化日志，基于 log/slog，可通过 Slog 获取底层的 *slog.Logger
+type Logger struct {
+	*slog.Logger
+
+	level  *slog.LevelVar
+	writer io.Writer
+}
+
+var defaultLogger atomic.Pointer[Logger]
+
+func init() {
+	defaultLogger.Store(New(jcbaseGo.LoggerStruct{Format: "console"}))
+}
+
+// New 根据配置创建日志
+//
+// 示例:
+//
+//	l := logger.New(jcbaseGo.LoggerStruct{Output: "both", Level: "debug"})
+//	logger.SetDefault(l)
+//	logger.Info("user login", "uid", 1, "ip", c.ClientIP())
+//	l.With("module", "order").Error("create order failed", "err", err)
+func New(conf jcbaseGo.LoggerStruct) *Logger {
+	_ = helper.CheckAndSetDefault(&conf)
+
+	var writer io.Writer = os.Stdout
+	switch conf.Output {
+	case "file":
+		writer = helper.NewRotateWriter(conf.FilePath, int64(conf.MaxSize)*1024*1024, conf.MaxBackups, conf.Daily)
+	case "both":
+		writer = io.MultiWriter(os.Stdout, helper.NewRotateWriter(conf.FilePath, int64(conf.MaxSize)*1024*1024, conf.MaxBackups, conf.Daily))
+	}
+
+	level := new(slog.LevelVar)
+	level.Set(ParseLevel(conf.Level))
+	opts := &slog.HandlerOptions{Level: level, AddSource: conf.AddSource}
+
+	var handler slog.Handler
+	if conf.Format == "console" {
+		handler = slog.NewTextHandler(writer, opts)
+	} else {
+		handler = slog.NewJSONHandler(writer, opts)
+	}
+	if conf.SampleInitial > 0 {
+		handler = newSamplingHandler(handler, conf.SampleInitial, conf.SampleThereafter)
+	}
+
+	return &Logger{Logger: slog.New(handler), level: level, writer: writer}
+}
+
+// NewWithHandler 使用自定义的 slog.Handler 创建日志
+func NewWithHandler(handler slog.Handler) *Logger {
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelDebug)
+	return &Logger{Logger: slog.New(handler), level: level}
+}
+
+// ParseLevel 解析日志级别，无法识别时为info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// SetLevel 动态调整日志级别
+func (l *Logger) SetLevel(level string) {
+	l.level.Set(ParseLevel(level))
+}
+
+// Level 获取当前日志级别
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// Slog 获取底层的 *slog.Logger
+func (l *Logger) Slog() *slog.Logger {
+	return l.Logger
+}
+
+// With 返回携带固定字段的日志
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{Logger: l.Logger.With(args...), level: l.level, writer: l.writer}
+}
+
+// WithGroup 返回将后续字段放入分组的日志
+func (l *Logger) WithGroup(name string) *Logger {
+	return &Logger{Logger: l.Logger.WithGroup(name), level: l.level, writer: l.writer}
+}
+
+// Close 关闭日志文件
+func (l *Logger) Close() error {
+	if closer, ok := l.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Default 获取默认日志，未设置时为输出到标准输出的console格式
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+// SetDefault 设置默认日志，同时设置为 slog 的默认日志，使 log 包的输出也进入同一个管道
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+	slog.SetDefault(l.Logger)
+}
+
+// Debug 使用默认日志输出debug级别日志
+func Debug(msg string, args ...any) {
+	Default().Log(context.Background(), slog.LevelDebug, msg, args...)
+}
+
+// Info 使用默认日志输出info级别日志
+func Info(msg string, args ...any) {
+	Default().Log(context.Background(), slog.LevelInfo, msg, args...)
+}
+
+// Warn 使用默认日志输出warn级别日志
+func Warn(msg string, args ...any) {
+	Default().Log(context.Background(), slog.LevelWarn, msg, args...)
+}
+
+// Error 使用默认日志输出error级别日志
+func Error(msg string, args ...any) {
+	Default().Log(context.Background(), slog.LevelError, msg, args...)
+}
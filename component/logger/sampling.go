@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// samplingHandler 日志采样，每秒内同一级别、同一消息的前initial条全部记录，之后每thereafter条记录一条
+type samplingHandler struct {
+	slog.Handler
+	initial    int
+	thereafter int
+	state      *samplingState
+}
+
+// samplingState 采样计数，With派生的handler共享同一份计数
+type samplingState struct {
+	mu     sync.Mutex
+	second int64
+	counts map[samplingKey]int
+}
+
+type samplingKey struct {
+	level slog.Level
+	msg   string
+}
+
+func newSamplingHandler(handler slog.Handler, initial, thereafter int) *samplingHandler {
+	return &samplingHandler{
+		Handler:    handler,
+		initial:    initial,
+		thereafter: thereafter,
+		state:      &samplingState{counts: make(map[samplingKey]int)},
+	}
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !h.state.allow(record, h.initial, h.thereafter) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), initial: h.initial, thereafter: h.thereafter, state: h.state}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), initial: h.initial, thereafter: h.thereafter, state: h.state}
+}
+
+// allow 判断本条日志是否需要记录
+func (s *samplingState) allow(record slog.Record, initial, thereafter int) bool {
+	second := record.Time.Unix()
+	if record.Time.IsZero() {
+		second = time.Now().Unix()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if second != s.second {
+		s.second = second
+		clear(s.counts)
+	}
+	key := samplingKey{level: record.Level, msg: record.Message}
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= initial {
+		return true
+	}
+	return thereafter > 0 && (n-initial)%thereafter == 0
+}
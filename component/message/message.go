@@ -0,0 +1,144 @@
+package message
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// 提示类型
+const (
+	TypeSuccess = "success"
+	TypeError   = "error"
+	TypeInfo    = "info"
+)
+
+// PageData 渲染模板时传入的数据
+type PageData struct {
+	Type     string                 // 提示类型 success/error/info
+	Code     int                    // 业务状态码
+	Message  string                 // 提示信息（已翻译）
+	Redirect string                 // 跳转地址
+	Delay    int                    // 自动跳转的等待秒数，小于0表示不自动跳转
+	Data     any                    // 附加数据
+	Theme    jcbaseGo.MessageStruct // 主题配置
+}
+
+// Message 提示页，根据请求返回HTML页面或JSON
+type Message struct {
+	Conf jcbaseGo.MessageStruct
+
+	// Translate 翻译提示信息，参数为请求上下文及原始信息（可为翻译键），默认为 i18n.Translate，为nil时原样输出
+	Translate func(c *gin.Context, message string) string
+
+	tpl *template.Template
+}
+
+// New 创建提示页，配置了 TemplatePath 时使用自定义模板，模板加载失败时回退到内置模板
+//
+// 示例:
+//
+//	msg := message.New(jcbaseGo.MessageStruct{Title: "XX管理后台", PrimaryColor: "#722ed1"})
+//	r.GET("/pay/result", func(c *gin.Context) {
+//	    msg.Success(c, "支付成功", "/order/list")
+//	})
+func New(conf jcbaseGo.MessageStruct) *Message {
+	_ = helper.CheckAndSetDefault(&conf)
+	m := &Message{Conf: conf, Translate: i18n.Translate, tpl: defaultTemplate}
+	if conf.TemplatePath != "" {
+		tpl, err := template.ParseFiles(conf.TemplatePath)
+		if err != nil {
+			log.Printf("message: 加载模板 %s 失败，使用内置模板: %v", conf.TemplatePath, err)
+		} else {
+			m.tpl = tpl
+		}
+	}
+	return m
+}
+
+// SetTemplate 设置自定义模板，模板中可使用 PageData 中的字段
+func (m *Message) SetTemplate(tpl *template.Template) *Message {
+	m.tpl = tpl
+	return m
+}
+
+// Success 输出成功提示，redirect为可选的跳转地址
+func (m *Message) Success(c *gin.Context, message string, redirect ...string) {
+	m.Render(c, TypeSuccess, errcode.Success, message, firstString(redirect), nil)
+}
+
+// Error 输出错误提示，redirect为可选的跳转地址
+func (m *Message) Error(c *gin.Context, message string, redirect ...string) {
+	m.Render(c, TypeError, errcode.BadRequest, message, firstString(redirect), nil)
+}
+
+// Info 输出普通提示，redirect为可选的跳转地址
+func (m *Message) Info(c *gin.Context, message string, redirect ...string) {
+	m.Render(c, TypeInfo, errcode.Success, message, firstString(redirect), nil)
+}
+
+// Render 输出提示
+//
+// 请求期望JSON（Accept包含application/json、XHR请求或以JSON提交）时输出 jcbaseGo.Result，
+// 跳转地址放在 data.redirect 中；否则渲染HTML页面
+func (m *Message) Render(c *gin.Context, typ string, code int, message, redirect string, data any) {
+	if m.Translate != nil {
+		message = m.Translate(c, message)
+	}
+
+	if WantsJSON(c) {
+		if redirect != "" && data == nil {
+			data = map[string]any{"redirect": redirect}
+		}
+		c.JSON(http.StatusOK, jcbaseGo.Result{Code: code, Message: message, Data: data})
+		return
+	}
+
+	page := PageData{
+		Type:     typ,
+		Code:     code,
+		Message:  message,
+		Redirect: redirect,
+		Delay:    m.Conf.RedirectDelay,
+		Data:     data,
+		Theme:    m.Conf,
+	}
+	if m.Translate != nil {
+		page.Theme.Title = m.Translate(c, page.Theme.Title)
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := m.tpl.Execute(c.Writer, page); err != nil {
+		log.Printf("message: 渲染模板失败: %v", err)
+	}
+}
+
+// WantsJSON 判断请求是否期望返回JSON
+func WantsJSON(c *gin.Context) bool {
+	if c.GetHeader("X-Requested-With") == "XMLHttpRequest" {
+		return true
+	}
+	if strings.Contains(c.GetHeader("Content-Type"), "application/json") {
+		return true
+	}
+	// 浏览器的Accept中text/html优先，只接受 */* 时按HTML处理
+	if !strings.Contains(c.GetHeader("Accept"), "json") {
+		return false
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEJSON
+}
+
+// firstString 获取可选参数中的第一个
+func firstString(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
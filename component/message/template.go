@@ -0,0 +1,52 @@
+package message
+
+import (
+	"html/template"
+)
+
+// defaultTemplate 内置提示页模板
+var defaultTemplate = template.Must(template.New("message").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Theme.Title}}</title>
+{{- if and .Redirect (ge .Delay 0)}}
+<meta http-equiv="refresh" content="{{.Delay}};url={{.Redirect}}">
+{{- end}}
+<style>
+body{margin:0;font-family:-apple-system,BlinkMacSystemFont,"Segoe UI","PingFang SC","Microsoft YaHei",sans-serif;background:#f5f6f7;color:#333}
+.box{max-width:480px;margin:12vh auto 0;padding:40px 32px;background:#fff;border-radius:8px;box-shadow:0 2px 12px rgba(0,0,0,.06);text-align:center}
+.logo{max-height:48px;margin-bottom:24px}
+.icon{width:64px;height:64px;line-height:64px;margin:0 auto 20px;border-radius:50%;color:#fff;font-size:36px}
+.success .icon{background:{{.Theme.SuccessColor}}}
+.error .icon{background:{{.Theme.ErrorColor}}}
+.info .icon{background:{{.Theme.PrimaryColor}}}
+.msg{font-size:18px;line-height:1.6;word-break:break-all}
+.tip{margin-top:16px;font-size:13px;color:#999}
+.btn{display:inline-block;margin-top:24px;padding:8px 24px;border-radius:4px;background:{{.Theme.PrimaryColor}};color:#fff;text-decoration:none}
+.footer{margin-top:32px;font-size:12px;color:#bbb;text-align:center}
+</style>
+</head>
+<body>
+<div class="box {{.Type}}">
+{{- if .Theme.LogoURL}}
+<img class="logo" src="{{.Theme.LogoURL}}" alt="{{.Theme.Title}}">
+{{- end}}
+<div class="icon">{{if eq .Type "success"}}&#10003;{{else if eq .Type "error"}}&#10005;{{else}}!{{end}}</div>
+<div class="msg">{{.Message}}</div>
+{{- if .Redirect}}
+{{- if ge .Delay 0}}
+<div class="tip">{{.Delay}} 秒后自动跳转</div>
+{{- end}}
+<a class="btn" href="{{.Redirect}}">立即跳转</a>
+{{- else}}
+<a class="btn" href="javascript:history.back()">返回</a>
+{{- end}}
+</div>
+{{- if .Theme.Footer}}
+<div class="footer">{{.Theme.Footer}}</div>
+{{- end}}
+</body>
+</html>
+`))
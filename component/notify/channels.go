@@ -0,0 +1,205 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"github.com/jcbowen/jcbaseGo/component/mailer"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultClient 机器人及webhook通道默认使用的http客户端
+var defaultClient = httpclient.New(httpclient.Options{Timeout: 10 * time.Second, MaxRetries: 1})
+
+// Mail 邮件通道
+type Mail struct {
+	Conf   jcbaseGo.MailerStruct
+	IsHTML bool // 正文是否为HTML
+}
+
+// NewMail 创建邮件通道
+func NewMail(conf jcbaseGo.MailerStruct, isHTML ...bool) *Mail {
+	return &Mail{Conf: conf, IsHTML: len(isHTML) > 0 && isHTML[0]}
+}
+
+func (m *Mail) Send(_ context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("没有收件人")
+	}
+	email := mailer.New(m.Conf)
+	for _, to := range msg.To {
+		email.AddRecipient(to)
+	}
+	email.SetSubject(msg.Title)
+	email.SetBody(msg.Content, m.IsHTML)
+	return email.Send()
+}
+
+// SMSSender 短信发送接口，由具体的短信服务商实现
+type SMSSender interface {
+	SendSMS(ctx context.Context, phones []string, content string, payload map[string]any) error
+}
+
+// SMS 短信通道
+type SMS struct {
+	Sender SMSSender
+}
+
+// NewSMS 创建短信通道
+func NewSMS(sender SMSSender) *SMS {
+	return &SMS{Sender: sender}
+}
+
+func (s *SMS) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("没有接收短信的手机号")
+	}
+	return s.Sender.SendSMS(ctx, msg.To, msg.Content, msg.Payload)
+}
+
+// Webhook 通用webhook通道，以JSON POST消息
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	Client  *httpclient.Client // 为nil时使用默认客户端
+}
+
+// NewWebhook 创建webhook通道
+func NewWebhook(url string, headers ...map[string]string) *Webhook {
+	w := &Webhook{URL: url}
+	if len(headers) > 0 {
+		w.Headers = headers[0]
+	}
+	return w
+}
+
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	body := map[string]any{
+		"event":   msg.Event,
+		"title":   msg.Title,
+		"content": msg.Content,
+		"to":      msg.To,
+		"payload": msg.Payload,
+		"time":    time.Now().Unix(),
+	}
+	resp, err := client(w.Client).R().SetContext(ctx).SetHeaders(w.Headers).SetJSON(body).Post(w.URL)
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("webhook返回状态码 %d: %s", resp.StatusCode, resp.String())
+	}
+	return nil
+}
+
+// WeCom 企业微信群机器人通道，以markdown格式发送
+type WeCom struct {
+	WebhookURL string
+	Client     *httpclient.Client
+}
+
+// NewWeCom 创建企业微信群机器人通道，webhookURL为机器人的完整地址
+func NewWeCom(webhookURL string) *WeCom {
+	return &WeCom{WebhookURL: webhookURL}
+}
+
+func (w *WeCom) Send(ctx context.Context, msg Message) error {
+	content := msg.Content
+	if msg.Title != "" {
+		content = "**" + msg.Title + "**\n" + content
+	}
+	// markdown消息不支持mentioned_list，通过<@userid>提醒
+	for _, user := range msg.To {
+		content += "\n<@" + user + ">"
+	}
+	body := map[string]any{
+		"msgtype":  "markdown",
+		"markdown": map[string]any{"content": content},
+	}
+	return postBot(ctx, client(w.Client), w.WebhookURL, body)
+}
+
+// DingTalk 钉钉群机器人通道，以markdown格式发送
+type DingTalk struct {
+	WebhookURL string
+	Secret     string // 加签密钥，未开启加签时为空
+	Client     *httpclient.Client
+}
+
+// NewDingTalk 创建钉钉群机器人通道
+func NewDingTalk(webhookURL string, secret ...string) *DingTalk {
+	d := &DingTalk{WebhookURL: webhookURL}
+	if len(secret) > 0 {
+		d.Secret = secret[0]
+	}
+	return d
+}
+
+func (d *DingTalk) Send(ctx context.Context, msg Message) error {
+	title := msg.Title
+	if title == "" {
+		title = msg.Event
+	}
+	text := msg.Content
+	if msg.Title != "" {
+		text = "#### " + msg.Title + "\n" + text
+	}
+	// 被@的手机号需要出现在正文中
+	for _, mobile := range msg.To {
+		text += " @" + mobile
+	}
+	body := map[string]any{
+		"msgtype":  "markdown",
+		"markdown": map[string]any{"title": title, "text": text},
+		"at":       map[string]any{"atMobiles": msg.To},
+	}
+
+	webhookURL := d.WebhookURL
+	if d.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(d.Secret))
+		mac.Write([]byte(timestamp + "\n" + d.Secret))
+		sign := url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		sep := "?"
+		if strings.Contains(webhookURL, "?") {
+			sep = "&"
+		}
+		webhookURL += sep + "timestamp=" + timestamp + "&sign=" + sign
+	}
+	return postBot(ctx, client(d.Client), webhookURL, body)
+}
+
+// postBot 调用机器人接口，企业微信与钉钉均以 errcode 表示结果
+func postBot(ctx context.Context, c *httpclient.Client, webhookURL string, body any) error {
+	resp, err := c.R().SetContext(ctx).SetJSON(body).Post(webhookURL)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err = resp.JSON(&result); err != nil {
+		return fmt.Errorf("机器人返回状态码 %d: %s", resp.StatusCode, resp.String())
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("机器人返回错误 %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// client 获取http客户端
+func client(c *httpclient.Client) *httpclient.Client {
+	if c != nil {
+		return c
+	}
+	return defaultClient
+}
@@ -0,0 +1,302 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// ErrRateLimited 超过通道的发送频率限制，本次通知被丢弃
+var ErrRateLimited = errors.New("notify: rate limited")
+
+// Message 发送到通道的消息
+type Message struct {
+	Event   string         // 事件名称
+	Title   string         // 标题，邮件主题、机器人markdown标题等
+	Content string         // 正文
+	To      []string       // 接收人，邮箱、手机号或机器人@的成员，为空时使用通道的默认接收人
+	Payload map[string]any // 原始数据
+}
+
+// Channel 通知通道
+type Channel interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// ChannelFunc 将函数包装为通道
+type ChannelFunc func(ctx context.Context, msg Message) error
+
+func (f ChannelFunc) Send(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// ChannelOptions 通道配置
+type ChannelOptions struct {
+	// Title、Content 为 text/template 模板，模板数据为 Notify 传入的payload，
+	// 另可通过 .Event 获取事件名；为空时使用payload中的 title/content
+	Title   string
+	Content string
+	// Templates 按事件覆盖 Title/Content，键为事件名
+	Templates map[string]Template
+	To        []string      // 默认接收人
+	RateLimit int           // 每个事件在Interval内最多发送的次数，0表示不限制
+	Interval  time.Duration // 频率限制的时间窗口，默认1分钟
+}
+
+// Template 消息模板
+type Template struct {
+	Title   string
+	Content string
+}
+
+// Hub 通知中心，按事件将通知路由到已注册的通道
+//
+// 示例:
+//
+//	hub := notify.New()
+//	hub.Register("ops", notify.NewWeCom(webhookURL), notify.ChannelOptions{
+//	    Title:     "【{{.Event}}】{{.service}}",
+//	    Content:   "服务 {{.service}} 异常：{{.error}}",
+//	    RateLimit: 5,
+//	})
+//	hub.Register("mail", notify.NewMail(conf.Mailer), notify.ChannelOptions{To: []string{"ops@example.com"}})
+//	hub.Route("service.down", "ops", "mail")
+//	err := hub.Notify(ctx, "service.down", map[string]any{"service": "api", "error": err.Error()})
+type Hub struct {
+	// OnError 异步发送失败时的回调，默认输出到日志
+	OnError func(event, channel string, err error)
+
+	mu       sync.RWMutex
+	channels map[string]*channelEntry
+	routes   map[string][]string
+}
+
+// channelEntry 已注册的通道
+type channelEntry struct {
+	channel Channel
+	opt     ChannelOptions
+	tpl     map[string]*template.Template // 按事件缓存的模板，键为 event + "\x00" + title/content
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// window 频率限制窗口
+type window struct {
+	start time.Time
+	count int
+}
+
+// New 创建通知中心
+func New() *Hub {
+	return &Hub{
+		channels: make(map[string]*channelEntry),
+		routes:   make(map[string][]string),
+	}
+}
+
+// Register 注册通道，同名通道会被替换
+func (h *Hub) Register(name string, channel Channel, opts ...ChannelOptions) *Hub {
+	var opt ChannelOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = time.Minute
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.channels[name] = &channelEntry{
+		channel: channel,
+		opt:     opt,
+		tpl:     make(map[string]*template.Template),
+		windows: make(map[string]*window),
+	}
+	return h
+}
+
+// Route 设置事件发送到哪些通道，event为 "*" 时作为未配置路由的事件的默认路由
+func (h *Hub) Route(event string, channels ...string) *Hub {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.routes[event] = channels
+	return h
+}
+
+// Channels 获取已注册的通道名称
+func (h *Hub) Channels() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.channels))
+	for name := range h.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Notify 将事件发送到路由的所有通道，返回所有通道的错误
+//
+// 未配置路由且没有默认路由时发送到所有通道；超过频率限制的通道返回 ErrRateLimited
+func (h *Hub) Notify(ctx context.Context, event string, payload map[string]any) error {
+	return h.send(ctx, event, payload, nil)
+}
+
+// NotifyTo 将事件发送到指定的通道，忽略路由配置
+func (h *Hub) NotifyTo(ctx context.Context, channels []string, event string, payload map[string]any) error {
+	return h.send(ctx, event, payload, channels)
+}
+
+// NotifyAsync 异步发送，错误交给 OnError 处理
+func (h *Hub) NotifyAsync(event string, payload map[string]any) {
+	go func() {
+		if err := h.Notify(context.Background(), event, payload); err != nil {
+			if h.OnError != nil {
+				h.OnError(event, "", err)
+				return
+			}
+			log.Printf("notify: 发送 %s 失败: %v", event, err)
+		}
+	}()
+}
+
+// send 发送到各通道
+func (h *Hub) send(ctx context.Context, event string, payload map[string]any, names []string) error {
+	h.mu.RLock()
+	if names == nil {
+		var ok bool
+		if names, ok = h.routes[event]; !ok {
+			if names, ok = h.routes["*"]; !ok {
+				for name := range h.channels {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+			}
+		}
+	}
+	entries := make(map[string]*channelEntry, len(names))
+	for _, name := range names {
+		entries[name] = h.channels[name]
+	}
+	h.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, name := range names {
+		entry := entries[name]
+		if entry == nil {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("notify: 通道 %s 未注册", name))
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(name string, entry *channelEntry) {
+			defer wg.Done()
+			if err := entry.send(ctx, event, payload); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("notify: 通道 %s: %w", name, err))
+				mu.Unlock()
+			}
+		}(name, entry)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// send 渲染模板、检查频率后发送
+func (e *channelEntry) send(ctx context.Context, event string, payload map[string]any) error {
+	if !e.allow(event) {
+		return ErrRateLimited
+	}
+
+	title, content := e.opt.Title, e.opt.Content
+	if tpl, ok := e.opt.Templates[event]; ok {
+		if tpl.Title != "" {
+			title = tpl.Title
+		}
+		if tpl.Content != "" {
+			content = tpl.Content
+		}
+	}
+
+	data := make(map[string]any, len(payload)+1)
+	for key, value := range payload {
+		data[key] = value
+	}
+	data["Event"] = event
+
+	msg := Message{Event: event, To: e.opt.To, Payload: payload}
+	var err error
+	if msg.Title, err = e.render(event+"\x00title", title, "title", data); err != nil {
+		return err
+	}
+	if msg.Content, err = e.render(event+"\x00content", content, "content", data); err != nil {
+		return err
+	}
+	if to, ok := payload["to"].([]string); ok && len(to) > 0 {
+		msg.To = to
+	}
+	return e.channel.Send(ctx, msg)
+}
+
+// render 渲染模板，模板为空时取payload中的同名字段
+func (e *channelEntry) render(key, text, field string, data map[string]any) (string, error) {
+	if text == "" {
+		if value, ok := data[field]; ok {
+			return fmt.Sprint(value), nil
+		}
+		return "", nil
+	}
+
+	e.mu.Lock()
+	tpl, ok := e.tpl[key]
+	if !ok {
+		var err error
+		tpl, err = template.New(key).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			e.mu.Unlock()
+			return "", fmt.Errorf("模板解析失败: %v", err)
+		}
+		e.tpl[key] = tpl
+	}
+	e.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("模板渲染失败: %v", err)
+	}
+	// map中不存在的键会输出为 <no value>
+	return strings.ReplaceAll(buf.String(), "<no value>", ""), nil
+}
+
+// allow 检查事件在当前窗口内是否还能发送
+func (e *channelEntry) allow(event string) bool {
+	if e.opt.RateLimit <= 0 {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	w, ok := e.windows[event]
+	if !ok || now.Sub(w.start) >= e.opt.Interval {
+		e.windows[event] = &window{start: now, count: 1}
+		return true
+	}
+	if w.count >= e.opt.RateLimit {
+		return false
+	}
+	w.count++
+	return true
+}
@@ -0,0 +1,326 @@
+// Package oauth 第三方登录：微信、QQ、GitHub 及通用 OIDC
+//
+// 负责生成授权地址（state 防CSRF、支持的平台启用 PKCE）、用授权码换取 access_token，
+// 并将各平台的用户信息统一为 Profile，交由 account 等账号体系完成登录或绑定。
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/helper/cache"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 错误
+var (
+	ErrUnknownProvider = errcode.New(errcode.NotSupported, "不支持的登录方式")
+	ErrInvalidState    = errcode.New(errcode.IllegalAccess, "授权已失效，请重新登录")
+	ErrAuthorization   = errcode.New(errcode.InvalidAuthorizationInformation, "第三方授权失败")
+)
+
+// defaultClient 各平台默认使用的http客户端
+var defaultClient = httpclient.New(httpclient.Options{Timeout: 10 * time.Second, MaxRetries: 1})
+
+// Profile 统一的第三方用户信息
+type Profile struct {
+	Provider string         `json:"provider"` // 平台名称，如 wechat、qq、github
+	OpenID   string         `json:"open_id"`  // 用户在该平台（应用）下的唯一标识
+	UnionID  string         `json:"union_id"` // 同一开放平台下多个应用共用的标识，仅微信、QQ可能有
+	Nickname string         `json:"nickname"` // 昵称
+	Avatar   string         `json:"avatar"`   // 头像地址
+	Email    string         `json:"email"`    // 邮箱，平台未提供或未授权时为空
+	Verified bool           `json:"verified"` // 邮箱是否已验证
+	Gender   int            `json:"gender"`   // 性别 0未知 1男 2女
+	Raw      map[string]any `json:"raw"`      // 平台返回的原始用户信息
+	Token    *Token         `json:"-"`        // 授权凭证
+	Redirect string         `json:"-"`        // 发起授权时传入的登录后跳转地址
+	BindUser string         `json:"-"`        // 绑定流程发起授权的用户标识，登录流程为空
+}
+
+// Token 授权凭证
+type Token struct {
+	AccessToken  string         `json:"access_token"`
+	RefreshToken string         `json:"refresh_token"`
+	TokenType    string         `json:"token_type"`
+	ExpiresIn    int            `json:"expires_in"`
+	Scope        string         `json:"scope"`
+	IDToken      string         `json:"id_token"`
+	Raw          map[string]any `json:"-"`
+
+	openID  string // 微信、QQ、OIDC 在换取token时即可获得用户标识
+	unionID string
+	claims  map[string]any // OIDC id_token 中的声明
+}
+
+// AuthParams 一次授权请求的参数，由 Manager 生成并在回调时原样传回
+type AuthParams struct {
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier"` // PKCE，不支持的平台忽略
+	Nonce        string `json:"nonce"`         // OIDC，防止 id_token 重放
+	Redirect     string `json:"redirect"`      // 登录后跳转地址
+	BindUser     string `json:"bind_user"`     // 绑定流程发起授权的用户标识
+}
+
+// AuthRequest 发起授权的参数
+type AuthRequest struct {
+	Redirect string // 登录后跳转地址，不合法时忽略
+	BindUser string // 绑定流程发起授权的用户标识，回调时应校验与当前登录用户一致；登录流程为空
+}
+
+// Provider 第三方平台
+type Provider interface {
+	// Name 平台名称，作为路由参数及 Profile.Provider
+	Name() string
+	// AuthURL 生成授权地址
+	AuthURL(ctx context.Context, params AuthParams) (string, error)
+	// Exchange 用授权码换取凭证
+	Exchange(ctx context.Context, code string, params AuthParams) (*Token, error)
+	// UserInfo 获取用户信息
+	UserInfo(ctx context.Context, token *Token) (*Profile, error)
+}
+
+// StateCache 授权参数缓存，多实例部署时应使用共享缓存（如 redis.CacheOpt），否则回调可能落到其他实例而失败
+type StateCache interface {
+	Set(key string, value interface{}, args ...time.Duration) error
+	GetString(key string) (string, error)
+	Del(key string) error
+}
+
+// Options 配置
+type Options struct {
+	StateTTL time.Duration // 授权参数有效期，默认10分钟
+	Cache    StateCache    // 授权参数缓存，为空时使用进程内缓存
+
+	// AllowRedirect 校验登录后跳转地址，防止开放重定向；为空时只允许以 / 开头的站内路径
+	AllowRedirect func(redirect string) bool
+
+	CookieName   string // 记录 state 的 Cookie 名称前缀，默认 oauth_state，实际名称后附加平台名称
+	CookieSecure bool   // Cookie 是否仅通过 HTTPS 发送，请求为 HTTPS 时自动启用
+}
+
+// Manager 第三方登录管理
+type Manager struct {
+	opt Options
+
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+// New 创建第三方登录管理
+//
+// 示例:
+//
+//	m := oauth.New(oauth.Options{Cache: redis.NewCache(rds)})
+//	m.Register(oauth.NewGitHub(conf.GitHub), oauth.NewWeChat(conf.WeChat))
+//	oidc, err := oauth.NewOIDC("keycloak", conf.Keycloak)
+//	m.Register(oidc)
+//
+//	r.GET("/oauth/:provider", m.Redirect)
+//	r.GET("/oauth/:provider/callback", func(c *gin.Context) {
+//		profile, err := m.HandleCallback(c)
+//		...
+//	})
+func New(opts ...Options) *Manager {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.StateTTL <= 0 {
+		opt.StateTTL = 10 * time.Minute
+	}
+	if opt.Cache == nil {
+		opt.Cache = cache.NewMemory()
+	}
+	if opt.CookieName == "" {
+		opt.CookieName = "oauth_state"
+	}
+	if opt.AllowRedirect == nil {
+		opt.AllowRedirect = func(redirect string) bool {
+			return strings.HasPrefix(redirect, "/") && !strings.HasPrefix(redirect, "//") && !strings.HasPrefix(redirect, "/\\")
+		}
+	}
+	return &Manager{opt: opt, providers: make(map[string]Provider)}
+}
+
+// Register 注册平台，同名平台后注册的覆盖先注册的
+func (m *Manager) Register(providers ...Provider) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range providers {
+		m.providers[p.Name()] = p
+	}
+	return m
+}
+
+// Provider 获取已注册的平台
+func (m *Manager) Provider(name string) (Provider, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.providers[name]
+	return p, ok
+}
+
+// AuthURL 生成授权地址，同时返回 state；调用方需将 state 与发起授权的浏览器绑定（如 RedirectWith 写入的 Cookie），
+// 回调时将浏览器中的值传给 Callback 校验，否则他人的授权回调地址可被用于登录CSRF或绑定攻击者的第三方账号
+func (m *Manager) AuthURL(ctx context.Context, provider string, req AuthRequest) (authURL, state string, err error) {
+	p, ok := m.Provider(provider)
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+	if req.Redirect != "" && !m.opt.AllowRedirect(req.Redirect) {
+		req.Redirect = ""
+	}
+	params := AuthParams{
+		State:        randomString(24),
+		CodeVerifier: randomString(48),
+		Nonce:        randomString(16),
+		Redirect:     req.Redirect,
+		BindUser:     req.BindUser,
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", "", err
+	}
+	if err = m.opt.Cache.Set(stateKey(provider, params.State), string(data), m.opt.StateTTL); err != nil {
+		return "", "", err
+	}
+	authURL, err = p.AuthURL(ctx, params)
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, params.State, nil
+}
+
+// Callback 校验 state 后用授权码换取凭证并获取用户信息，state 只能使用一次；
+// binding 为发起授权的浏览器中记录的 state 摘要（见 StateBinding），不一致时返回 ErrInvalidState
+func (m *Manager) Callback(ctx context.Context, provider, code, state, binding string) (*Profile, error) {
+	p, ok := m.Provider(provider)
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	if code == "" || state == "" || subtle.ConstantTimeCompare([]byte(binding), []byte(StateBinding(state))) != 1 {
+		return nil, ErrInvalidState
+	}
+	key := stateKey(provider, state)
+	data, err := m.opt.Cache.GetString(key)
+	if err != nil {
+		return nil, err
+	}
+	if data == "" {
+		return nil, ErrInvalidState
+	}
+	_ = m.opt.Cache.Del(key)
+	var params AuthParams
+	if err = json.Unmarshal([]byte(data), &params); err != nil || params.State != state {
+		return nil, ErrInvalidState
+	}
+
+	token, err := p.Exchange(ctx, code, params)
+	if err != nil {
+		return nil, authError(err)
+	}
+	profile, err := p.UserInfo(ctx, token)
+	if err != nil {
+		return nil, authError(err)
+	}
+	profile.Provider, profile.Token = p.Name(), token
+	profile.Redirect, profile.BindUser = params.Redirect, params.BindUser
+	return profile, nil
+}
+
+// Redirect 跳转到授权页面的接口（登录流程），路由需包含 :provider 参数，登录后跳转地址通过 redirect 参数传入
+func (m *Manager) Redirect(c *gin.Context) {
+	m.RedirectWith(c, AuthRequest{Redirect: c.Query("redirect")})
+}
+
+// RedirectWith 按指定参数跳转到授权页面，并将 state 摘要写入 HttpOnly、SameSite=Lax 的 Cookie，路由需包含 :provider 参数
+func (m *Manager) RedirectWith(c *gin.Context, req AuthRequest) {
+	provider := c.Param("provider")
+	authURL, state, err := m.AuthURL(c.Request.Context(), provider, req)
+	if err != nil {
+		controller.Base{GinContext: c}.Error(err)
+		return
+	}
+	m.setStateCookie(c, provider, StateBinding(state), int(m.opt.StateTTL/time.Second))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// HandleCallback 授权回调，读取 RedirectWith 写入的 Cookie 校验 state 后获取用户信息，Cookie 读取后即清除；路由需包含 :provider 参数
+func (m *Manager) HandleCallback(c *gin.Context) (*Profile, error) {
+	provider := c.Param("provider")
+	binding, _ := c.Cookie(m.cookieName(provider))
+	m.setStateCookie(c, provider, "", -1)
+	return m.Callback(c.Request.Context(), provider, c.Query("code"), c.Query("state"), binding)
+}
+
+// StateBinding state 的摘要，记录在发起授权的浏览器中，回调时与 state 比对
+func StateBinding(state string) string {
+	sum := sha256.Sum256([]byte("oauth-state:" + state))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ----- 内部方法 ----- /
+
+func (m *Manager) cookieName(provider string) string {
+	return m.opt.CookieName + "_" + provider
+}
+
+// setStateCookie 写入或清除（maxAge<0）state 摘要，SameSite=Lax 以便第三方平台跳转回来时携带
+func (m *Manager) setStateCookie(c *gin.Context, provider, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     m.cookieName(provider),
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   m.opt.CookieSecure || c.Request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func stateKey(provider, state string) string {
+	return "oauth:state:" + provider + ":" + state
+}
+
+// authError 平台返回的错误统一为 ErrAuthorization，原始错误保留在错误链中
+func authError(err error) error {
+	if _, ok := err.(*errcode.AppError); ok {
+		return err
+	}
+	return errcode.Wrap(err, ErrAuthorization.Code, ErrAuthorization.Message)
+}
+
+// randomString 生成 URL 安全的随机字符串，n 为随机字节数
+func randomString(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// codeChallenge PKCE S256
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// buildURL 拼接查询参数
+func buildURL(base string, query url.Values) string {
+	if strings.Contains(base, "?") {
+		return base + "&" + query.Encode()
+	}
+	return base + "?" + query.Encode()
+}
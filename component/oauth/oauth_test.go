@@ -0,0 +1,156 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// fakeProvider 测试用平台，授权地址携带 state，任意授权码均可换取固定用户
+type fakeProvider struct{}
+
+func (fakeProvider) Name() string { return "fake" }
+
+func (fakeProvider) AuthURL(_ context.Context, params AuthParams) (string, error) {
+	return buildURL("https://example.com/authorize", url.Values{"state": {params.State}}), nil
+}
+
+func (fakeProvider) Exchange(_ context.Context, code string, _ AuthParams) (*Token, error) {
+	return &Token{AccessToken: code}, nil
+}
+
+func (fakeProvider) UserInfo(_ context.Context, token *Token) (*Profile, error) {
+	return &Profile{OpenID: "open-" + token.AccessToken}, nil
+}
+
+func newTestRouter(m *Manager, profiles chan<- *Profile, errs chan<- error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/oauth/:provider", m.Redirect)
+	r.GET("/oauth/:provider/bind", func(c *gin.Context) {
+		m.RedirectWith(c, AuthRequest{BindUser: "42"})
+	})
+	r.GET("/oauth/:provider/callback", func(c *gin.Context) {
+		profile, err := m.HandleCallback(c)
+		if err != nil {
+			errs <- err
+			return
+		}
+		profiles <- profile
+	})
+	return r
+}
+
+// startFlow 发起授权，返回 state 及写入的 Cookie
+func startFlow(t *testing.T, r *gin.Engine, path string) (string, *http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("redirect status = %d", w.Code)
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("cookies = %d, want 1", len(cookies))
+	}
+	cookie := cookies[0]
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("cookie should be HttpOnly and SameSite=Lax: %+v", cookie)
+	}
+	if cookie.Value == location.Query().Get("state") {
+		t.Fatal("cookie should hold the state digest instead of the state")
+	}
+	return location.Query().Get("state"), cookie
+}
+
+func callback(r *gin.Engine, state string, cookie *http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, "/oauth/fake/callback?code=abc&state="+url.QueryEscape(state), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestCallbackRequiresInitiatingBrowser(t *testing.T) {
+	m := New().Register(fakeProvider{})
+	profiles, errs := make(chan *Profile, 1), make(chan error, 1)
+	r := newTestRouter(m, profiles, errs)
+
+	// 攻击者发起授权后将回调地址发给他人，他人的浏览器中没有对应的 Cookie
+	state, attackerCookie := startFlow(t, r, "/oauth/fake")
+	callback(r, state, nil)
+	if err := <-errs; !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("callback without cookie: err = %v, want ErrInvalidState", err)
+	}
+
+	// 他人自己发起的授权留下的 Cookie 同样不能匹配
+	_, victimCookie := startFlow(t, r, "/oauth/fake")
+	callback(r, state, victimCookie)
+	if err := <-errs; !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("callback with another flow's cookie: err = %v, want ErrInvalidState", err)
+	}
+
+	// 发起授权的浏览器可以完成回调
+	callback(r, state, attackerCookie)
+	select {
+	case profile := <-profiles:
+		if profile.OpenID != "open-abc" || profile.Provider != "fake" || profile.BindUser != "" {
+			t.Fatalf("unexpected profile: %+v", profile)
+		}
+	case err := <-errs:
+		t.Fatalf("callback with matching cookie: %v", err)
+	}
+
+	// state 只能使用一次
+	callback(r, state, attackerCookie)
+	if err := <-errs; !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("reused state: err = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestCallbackReturnsBindUser(t *testing.T) {
+	m := New().Register(fakeProvider{})
+	profiles, errs := make(chan *Profile, 1), make(chan error, 1)
+	r := newTestRouter(m, profiles, errs)
+
+	state, cookie := startFlow(t, r, "/oauth/fake/bind")
+	callback(r, state, cookie)
+	select {
+	case profile := <-profiles:
+		if profile.BindUser != "42" {
+			t.Fatalf("BindUser = %q, want 42", profile.BindUser)
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	}
+}
+
+func TestRedirectRejectsOpenRedirect(t *testing.T) {
+	m := New().Register(fakeProvider{})
+	for redirect, want := range map[string]string{
+		"/home":              "/home",
+		"//evil.example.com": "",
+		"https://evil.com":   "",
+		"/\\evil.com":        "",
+	} {
+		_, state, err := m.AuthURL(context.Background(), "fake", AuthRequest{Redirect: redirect})
+		if err != nil {
+			t.Fatal(err)
+		}
+		profile, err := m.Callback(context.Background(), "fake", "abc", state, StateBinding(state))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if profile.Redirect != want {
+			t.Errorf("redirect %q: got %q, want %q", redirect, profile.Redirect, want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDC 通用 OpenID Connect 登录，启用 PKCE 及 nonce，
+// id_token 使用颁发者公布的 JWKS 校验签名（支持 RS256、ES256）及 iss、aud、exp、nonce
+type OIDC struct {
+	Conf   jcbaseGo.OAuthStruct
+	Client *httpclient.Client // 为nil时使用默认客户端
+
+	name      string
+	discovery oidcDiscovery
+
+	keysMu    sync.Mutex
+	keys      map[string]crypto.PublicKey
+	keysFetch time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// NewOIDC 创建 OIDC 登录，通过 Conf.Issuer 的 /.well-known/openid-configuration 获取各端点，
+// name 为平台名称，默认 scope 为 openid profile email
+func NewOIDC(name string, conf jcbaseGo.OAuthStruct, client ...*httpclient.Client) (*OIDC, error) {
+	o := &OIDC{Conf: conf, name: name}
+	if len(client) > 0 {
+		o.Client = client[0]
+	}
+	if conf.Issuer == "" {
+		return nil, errors.New("oauth: OIDC的Issuer不能为空")
+	}
+	endpoint := strings.TrimSuffix(conf.Issuer, "/") + "/.well-known/openid-configuration"
+	if _, err := getJSON(context.Background(), o.Client, endpoint, nil, nil, &o.discovery); err != nil {
+		return nil, err
+	}
+	if o.discovery.AuthorizationEndpoint == "" || o.discovery.TokenEndpoint == "" {
+		return nil, errors.New("oauth: OIDC发现文档缺少授权或token端点")
+	}
+	if strings.TrimSuffix(o.discovery.Issuer, "/") != strings.TrimSuffix(conf.Issuer, "/") {
+		return nil, fmt.Errorf("oauth: OIDC发现文档的issuer(%s)与配置不一致", o.discovery.Issuer)
+	}
+	return o, nil
+}
+
+func (o *OIDC) Name() string {
+	return o.name
+}
+
+func (o *OIDC) AuthURL(_ context.Context, params AuthParams) (string, error) {
+	scopes := o.Conf.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	return buildURL(o.discovery.AuthorizationEndpoint, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.Conf.ClientID},
+		"redirect_uri":          {o.Conf.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {params.State},
+		"nonce":                 {params.Nonce},
+		"code_challenge":        {codeChallenge(params.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}), nil
+}
+
+func (o *OIDC) Exchange(ctx context.Context, code string, params AuthParams) (*Token, error) {
+	token, err := exchangeToken(ctx, o.Client, o.discovery.TokenEndpoint, map[string]string{
+		"client_id":     o.Conf.ClientID,
+		"client_secret": o.Conf.ClientSecret,
+		"code":          code,
+		"redirect_uri":  o.Conf.RedirectURL,
+		"code_verifier": params.CodeVerifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return token, nil
+	}
+	claims, err := o.verifyIDToken(ctx, token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if nonce, _ := claims["nonce"].(string); nonce != params.Nonce {
+		return nil, errors.New("oauth: id_token的nonce不匹配")
+	}
+	token.openID, _ = claims["sub"].(string)
+	token.claims = claims
+	return token, nil
+}
+
+func (o *OIDC) UserInfo(ctx context.Context, token *Token) (*Profile, error) {
+	claims := token.claims
+	if o.discovery.UserinfoEndpoint != "" {
+		info, err := getJSON(ctx, o.Client, o.discovery.UserinfoEndpoint, nil, map[string]string{
+			"Authorization": "Bearer " + token.AccessToken,
+		}, &struct{}{})
+		if err != nil {
+			return nil, err
+		}
+		// userinfo 的 sub 必须与 id_token 一致
+		if sub, _ := info["sub"].(string); sub == "" || (token.openID != "" && sub != token.openID) {
+			return nil, errors.New("oauth: userinfo的sub无效或与id_token不一致")
+		}
+		claims = info
+	}
+	if claims == nil {
+		return nil, errors.New("oauth: 未获取到用户信息")
+	}
+
+	str := func(key string) string {
+		v, _ := claims[key].(string)
+		return v
+	}
+	profile := &Profile{
+		OpenID:   str("sub"),
+		Nickname: str("name"),
+		Avatar:   str("picture"),
+		Email:    str("email"),
+		Raw:      claims,
+	}
+	profile.Verified, _ = claims["email_verified"].(bool)
+	if profile.Nickname == "" {
+		profile.Nickname = str("preferred_username")
+	}
+	switch str("gender") {
+	case "male":
+		profile.Gender = 1
+	case "female":
+		profile.Gender = 2
+	}
+	return profile, nil
+}
+
+// verifyIDToken 校验 id_token 的签名及 iss、aud、exp，返回其中的声明
+func (o *OIDC) verifyIDToken(ctx context.Context, idToken string) (map[string]any, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("oauth: id_token格式错误")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("oauth: id_token签名格式错误")
+	}
+	key, err := o.publicKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if header.Alg != "RS256" {
+			return nil, fmt.Errorf("oauth: 不支持的id_token签名算法 %s", header.Alg)
+		}
+		err = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig)
+	case *ecdsa.PublicKey:
+		if header.Alg != "ES256" || len(sig) != 64 {
+			return nil, fmt.Errorf("oauth: 不支持的id_token签名算法 %s", header.Alg)
+		}
+		if !ecdsa.Verify(k, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+			err = errors.New("ecdsa: verification error")
+		}
+	default:
+		err = errors.New("unsupported key type")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("oauth: id_token签名无效: %v", err)
+	}
+
+	var claims map[string]any
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(o.discovery.Issuer, "/") {
+		return nil, errors.New("oauth: id_token的iss不匹配")
+	}
+	if !audienceContains(claims["aud"], o.Conf.ClientID) {
+		return nil, errors.New("oauth: id_token的aud不匹配")
+	}
+	if exp, _ := claims["exp"].(float64); time.Now().After(time.Unix(int64(exp), 0).Add(time.Minute)) {
+		return nil, errors.New("oauth: id_token已过期")
+	}
+	return claims, nil
+}
+
+// publicKey 获取签名公钥，找不到 kid 时重新拉取 JWKS（密钥轮换），两次拉取至少间隔1分钟
+func (o *OIDC) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	o.keysMu.Lock()
+	defer o.keysMu.Unlock()
+	if key, ok := o.findKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(o.keysFetch) < time.Minute {
+		return nil, errors.New("oauth: 未找到id_token的签名公钥")
+	}
+	o.keysFetch = time.Now()
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if _, err := getJSON(ctx, o.Client, o.discovery.JwksURI, nil, nil, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch {
+		case k.Kty == "RSA":
+			n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+			e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+			if err1 == nil && err2 == nil {
+				keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+			}
+		case k.Kty == "EC" && k.Crv == "P-256":
+			x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+			y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+			if err1 == nil && err2 == nil {
+				keys[k.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			}
+		}
+	}
+	o.keys = keys
+	if key, ok := o.findKey(kid); ok {
+		return key, nil
+	}
+	return nil, errors.New("oauth: 未找到id_token的签名公钥")
+}
+
+// findKey 调用方需持有keysMu；kid 为空且只有一个公钥时使用该公钥
+func (o *OIDC) findKey(kid string) (crypto.PublicKey, bool) {
+	if key, ok := o.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(o.keys) == 1 {
+		for _, key := range o.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errors.New("oauth: id_token格式错误")
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return errors.New("oauth: id_token格式错误")
+	}
+	return nil
+}
+
+// audienceContains aud 可能为字符串或数组
+func audienceContains(aud any, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []any:
+		for _, a := range v {
+			if s, _ := a.(string); s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
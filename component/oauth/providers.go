@@ -0,0 +1,409 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"net/url"
+	"strings"
+)
+
+// ----- 微信 ----- /
+
+// WeChat 微信登录，网站应用使用扫码登录，公众号网页授权使用 NewWeChatMP
+type WeChat struct {
+	Conf   jcbaseGo.OAuthStruct
+	MP     bool               // 是否为公众号网页授权（在微信内打开）
+	Client *httpclient.Client // 为nil时使用默认客户端
+}
+
+// NewWeChat 创建微信开放平台网站应用扫码登录，默认 scope 为 snsapi_login
+func NewWeChat(conf jcbaseGo.OAuthStruct) *WeChat {
+	return &WeChat{Conf: conf}
+}
+
+// NewWeChatMP 创建公众号网页授权登录，默认 scope 为 snsapi_userinfo，平台名称为 wechat_mp
+func NewWeChatMP(conf jcbaseGo.OAuthStruct) *WeChat {
+	return &WeChat{Conf: conf, MP: true}
+}
+
+func (w *WeChat) Name() string {
+	if w.MP {
+		return "wechat_mp"
+	}
+	return "wechat"
+}
+
+func (w *WeChat) AuthURL(_ context.Context, params AuthParams) (string, error) {
+	endpoint, scope := "https://open.weixin.qq.com/connect/qrconnect", "snsapi_login"
+	if w.MP {
+		endpoint, scope = "https://open.weixin.qq.com/connect/oauth2/authorize", "snsapi_userinfo"
+	}
+	if len(w.Conf.Scopes) > 0 {
+		scope = strings.Join(w.Conf.Scopes, ",")
+	}
+	// 微信要求参数按固定顺序排列，不能使用 url.Values 编码
+	return endpoint + "?appid=" + url.QueryEscape(w.Conf.ClientID) +
+		"&redirect_uri=" + url.QueryEscape(w.Conf.RedirectURL) +
+		"&response_type=code&scope=" + url.QueryEscape(scope) +
+		"&state=" + url.QueryEscape(params.State) + "#wechat_redirect", nil
+}
+
+func (w *WeChat) Exchange(ctx context.Context, code string, _ AuthParams) (*Token, error) {
+	var result struct {
+		wechatError
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+		OpenID       string `json:"openid"`
+		Scope        string `json:"scope"`
+		UnionID      string `json:"unionid"`
+	}
+	raw, err := getJSON(ctx, w.Client, "https://api.weixin.qq.com/sns/oauth2/access_token", map[string]string{
+		"appid":      w.Conf.ClientID,
+		"secret":     w.Conf.ClientSecret,
+		"code":       code,
+		"grant_type": "authorization_code",
+	}, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.ErrCode != 0 {
+		return nil, &result.wechatError
+	}
+	return &Token{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
+		Scope:        result.Scope,
+		Raw:          raw,
+		openID:       result.OpenID,
+		unionID:      result.UnionID,
+	}, nil
+}
+
+func (w *WeChat) UserInfo(ctx context.Context, token *Token) (*Profile, error) {
+	profile := &Profile{OpenID: token.openID, UnionID: token.unionID}
+	// snsapi_base 静默授权只能获取openid
+	if token.Scope == "snsapi_base" {
+		return profile, nil
+	}
+	var result struct {
+		wechatError
+		OpenID     string `json:"openid"`
+		Nickname   string `json:"nickname"`
+		Sex        int    `json:"sex"`
+		HeadImgURL string `json:"headimgurl"`
+		UnionID    string `json:"unionid"`
+	}
+	raw, err := getJSON(ctx, w.Client, "https://api.weixin.qq.com/sns/userinfo", map[string]string{
+		"access_token": token.AccessToken,
+		"openid":       token.openID,
+		"lang":         "zh_CN",
+	}, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.ErrCode != 0 {
+		return nil, &result.wechatError
+	}
+	profile.Nickname, profile.Avatar, profile.Gender, profile.Raw = result.Nickname, result.HeadImgURL, result.Sex, raw
+	if result.UnionID != "" {
+		profile.UnionID = result.UnionID
+	}
+	return profile, nil
+}
+
+type wechatError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func (e *wechatError) Error() string {
+	return fmt.Sprintf("wechat oauth error: %d %s", e.ErrCode, e.ErrMsg)
+}
+
+// ----- QQ ----- /
+
+// QQ QQ互联登录
+type QQ struct {
+	Conf    jcbaseGo.OAuthStruct
+	UnionID bool               // 是否获取unionid，需在QQ互联申请权限
+	Client  *httpclient.Client // 为nil时使用默认客户端
+}
+
+// NewQQ 创建QQ登录，默认 scope 为 get_user_info
+func NewQQ(conf jcbaseGo.OAuthStruct) *QQ {
+	return &QQ{Conf: conf}
+}
+
+func (q *QQ) Name() string {
+	return "qq"
+}
+
+func (q *QQ) AuthURL(_ context.Context, params AuthParams) (string, error) {
+	scope := "get_user_info"
+	if len(q.Conf.Scopes) > 0 {
+		scope = strings.Join(q.Conf.Scopes, ",")
+	}
+	return buildURL("https://graph.qq.com/oauth2.0/authorize", url.Values{
+		"response_type": {"code"},
+		"client_id":     {q.Conf.ClientID},
+		"redirect_uri":  {q.Conf.RedirectURL},
+		"state":         {params.State},
+		"scope":         {scope},
+	}), nil
+}
+
+func (q *QQ) Exchange(ctx context.Context, code string, _ AuthParams) (*Token, error) {
+	var result struct {
+		qqError
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    any    `json:"expires_in"` // 可能为字符串
+		RefreshToken string `json:"refresh_token"`
+	}
+	raw, err := getJSON(ctx, q.Client, "https://graph.qq.com/oauth2.0/token", map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     q.Conf.ClientID,
+		"client_secret": q.Conf.ClientSecret,
+		"code":          code,
+		"redirect_uri":  q.Conf.RedirectURL,
+		"fmt":           "json",
+	}, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.failed() {
+		return nil, &result.qqError
+	}
+	token := &Token{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    helper.Convert{Value: result.ExpiresIn}.ToInt(),
+		Raw:          raw,
+	}
+
+	// QQ需单独获取openid
+	var me struct {
+		qqError
+		OpenID  string `json:"openid"`
+		UnionID string `json:"unionid"`
+	}
+	query := map[string]string{"access_token": token.AccessToken, "fmt": "json"}
+	if q.UnionID {
+		query["unionid"] = "1"
+	}
+	if _, err = getJSON(ctx, q.Client, "https://graph.qq.com/oauth2.0/me", query, nil, &me); err != nil {
+		return nil, err
+	}
+	if me.failed() {
+		return nil, &me.qqError
+	}
+	token.openID, token.unionID = me.OpenID, me.UnionID
+	return token, nil
+}
+
+func (q *QQ) UserInfo(ctx context.Context, token *Token) (*Profile, error) {
+	var result struct {
+		Ret          int    `json:"ret"`
+		Msg          string `json:"msg"`
+		Nickname     string `json:"nickname"`
+		Gender       string `json:"gender"`
+		FigureURLQQ2 string `json:"figureurl_qq_2"`
+		FigureURLQQ1 string `json:"figureurl_qq_1"`
+	}
+	raw, err := getJSON(ctx, q.Client, "https://graph.qq.com/user/get_user_info", map[string]string{
+		"access_token":       token.AccessToken,
+		"oauth_consumer_key": q.Conf.ClientID,
+		"openid":             token.openID,
+	}, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Ret != 0 {
+		return nil, fmt.Errorf("qq oauth error: %d %s", result.Ret, result.Msg)
+	}
+	profile := &Profile{
+		OpenID:   token.openID,
+		UnionID:  token.unionID,
+		Nickname: result.Nickname,
+		Avatar:   result.FigureURLQQ2,
+		Raw:      raw,
+	}
+	if profile.Avatar == "" {
+		profile.Avatar = result.FigureURLQQ1
+	}
+	switch result.Gender {
+	case "男":
+		profile.Gender = 1
+	case "女":
+		profile.Gender = 2
+	}
+	return profile, nil
+}
+
+type qqError struct {
+	ErrNo            any    `json:"error"` // 不同接口返回数字或字符串
+	ErrorDescription string `json:"error_description"`
+	Code             int    `json:"code"`
+	Msg              string `json:"msg"`
+}
+
+func (e *qqError) failed() bool {
+	return (e.ErrNo != nil && helper.Convert{Value: e.ErrNo}.ToString() != "0") || e.Code != 0
+}
+
+func (e *qqError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("qq oauth error: %d %s", e.Code, e.Msg)
+	}
+	return fmt.Sprintf("qq oauth error: %v %s", e.ErrNo, e.ErrorDescription)
+}
+
+// ----- GitHub ----- /
+
+// GitHub GitHub登录，启用 PKCE
+type GitHub struct {
+	Conf   jcbaseGo.OAuthStruct
+	Client *httpclient.Client // 为nil时使用默认客户端
+}
+
+// NewGitHub 创建GitHub登录，默认 scope 为 read:user user:email
+func NewGitHub(conf jcbaseGo.OAuthStruct) *GitHub {
+	return &GitHub{Conf: conf}
+}
+
+func (g *GitHub) Name() string {
+	return "github"
+}
+
+func (g *GitHub) AuthURL(_ context.Context, params AuthParams) (string, error) {
+	scopes := g.Conf.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read:user", "user:email"}
+	}
+	return buildURL("https://github.com/login/oauth/authorize", url.Values{
+		"client_id":             {g.Conf.ClientID},
+		"redirect_uri":          {g.Conf.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {params.State},
+		"code_challenge":        {codeChallenge(params.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}), nil
+}
+
+func (g *GitHub) Exchange(ctx context.Context, code string, params AuthParams) (*Token, error) {
+	return exchangeToken(ctx, g.Client, "https://github.com/login/oauth/access_token", map[string]string{
+		"client_id":     g.Conf.ClientID,
+		"client_secret": g.Conf.ClientSecret,
+		"code":          code,
+		"redirect_uri":  g.Conf.RedirectURL,
+		"code_verifier": params.CodeVerifier,
+	})
+}
+
+func (g *GitHub) UserInfo(ctx context.Context, token *Token) (*Profile, error) {
+	headers := map[string]string{
+		"Authorization": "Bearer " + token.AccessToken,
+		"Accept":        "application/vnd.github+json",
+	}
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	raw, err := getJSON(ctx, g.Client, "https://api.github.com/user", nil, headers, &user)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("github oauth error: %v", raw["message"])
+	}
+	profile := &Profile{
+		OpenID:   helper.Convert{Value: user.ID}.ToString(),
+		Nickname: user.Name,
+		Avatar:   user.AvatarURL,
+		Raw:      raw,
+	}
+	if profile.Nickname == "" {
+		profile.Nickname = user.Login
+	}
+
+	// 公开邮箱可能为空或未验证，从邮箱列表中取已验证的主邮箱
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if _, err = getJSON(ctx, g.Client, "https://api.github.com/user/emails", nil, headers, &emails); err == nil {
+		for _, e := range emails {
+			if e.Primary && e.Verified {
+				profile.Email, profile.Verified = e.Email, true
+				break
+			}
+		}
+	}
+	if profile.Email == "" {
+		profile.Email = user.Email
+	}
+	return profile, nil
+}
+
+// ----- 公共方法 ----- /
+
+// getJSON 以GET方式请求并解析JSON响应，同时返回原始数据
+func getJSON(ctx context.Context, client *httpclient.Client, endpoint string, query, headers map[string]string, result any) (map[string]any, error) {
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.R().SetContext(ctx).SetQueryParams(query).SetHeaders(headers).Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return decode(resp, result)
+}
+
+// exchangeToken 标准 OAuth2 授权码换取token
+func exchangeToken(ctx context.Context, client *httpclient.Client, endpoint string, form map[string]string) (*Token, error) {
+	if client == nil {
+		client = defaultClient
+	}
+	for k, v := range form {
+		if v == "" {
+			delete(form, k)
+		}
+	}
+	form["grant_type"] = "authorization_code"
+	resp, err := client.R().SetContext(ctx).SetHeader("Accept", "application/json").SetForm(form).Post(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var token Token
+	raw, err := decode(resp, &token)
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth token error: %v %v", raw["error"], raw["error_description"])
+	}
+	token.Raw = raw
+	return &token, nil
+}
+
+func decode(resp *httpclient.Response, result any) (map[string]any, error) {
+	var raw map[string]any
+	_ = json.Unmarshal(resp.Body, &raw)
+	if err := resp.JSON(result); err != nil {
+		return raw, fmt.Errorf("oauth: 解析响应失败(HTTP %d): %v", resp.StatusCode, err)
+	}
+	if !resp.IsSuccess() && raw == nil {
+		return nil, errors.New("oauth: 请求失败，HTTP状态码 " + helper.Convert{Value: resp.StatusCode}.ToString())
+	}
+	return raw, nil
+}
@@ -0,0 +1,156 @@
+package openapi
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"time"
+)
+
+// Handler 应用管理接口，需自行在路由分组上添加登录、权限等中间件
+type Handler struct {
+	platform *Platform
+}
+
+// Handler 获取应用管理接口
+//
+// 示例:
+//
+//	admin := r.Group("/admin/openapi", middleware.LoginRequired())
+//	platform.Handler().RegisterRoutes(admin)
+func (p *Platform) Handler() *Handler {
+	return &Handler{platform: p}
+}
+
+// RegisterRoutes 注册路由：
+//   - GET  /apps           全部应用，不返回密钥
+//   - POST /create         创建应用，返回 app_key 及 secret（密钥仅在创建及轮换时返回）
+//   - POST /update         修改应用，参数 id
+//   - POST /rotate-secret  轮换密钥，参数 id、grace（旧密钥宽限秒数，0使用默认值，-1立即失效）
+//   - POST /delete         删除应用，参数 ids
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/apps", h.Apps)
+	r.POST("/create", h.Create)
+	r.POST("/update", h.Update)
+	r.POST("/rotate-secret", h.RotateSecret)
+	r.POST("/delete", h.Delete)
+}
+
+type appForm struct {
+	Name      string `json:"name" form:"name" binding:"required,max=64"`
+	Scopes    string `json:"scopes" form:"scopes" binding:"max=1000"`
+	RateLimit int    `json:"rate_limit" form:"rate_limit" binding:"min=0"`
+	AllowIPs  string `json:"allow_ips" form:"allow_ips" binding:"max=1000"`
+	Status    *int   `json:"status" form:"status"` // 未传入时为启用
+	Remark    string `json:"remark" form:"remark" binding:"max=255"`
+}
+
+func (f appForm) app() App {
+	app := App{
+		Name:      f.Name,
+		Scopes:    f.Scopes,
+		RateLimit: f.RateLimit,
+		AllowIPs:  f.AllowIPs,
+		Status:    StatusEnabled,
+		Remark:    f.Remark,
+	}
+	if f.Status != nil && *f.Status == StatusDisabled {
+		app.Status = StatusDisabled
+	}
+	return app
+}
+
+// Apps 全部应用
+func (h *Handler) Apps(c *gin.Context) {
+	list, err := h.platform.Apps(c.Request.Context())
+	if err != nil {
+		controller.Base{GinContext: c}.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	controller.Base{GinContext: c}.Success(list)
+}
+
+// Create 创建应用
+func (h *Handler) Create(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form appForm
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	app, err := h.platform.CreateApp(c.Request.Context(), form.app())
+	if err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessChange, "success", map[string]any{
+		"app":    app,
+		"secret": app.Secret,
+	})
+}
+
+// Update 修改应用
+func (h *Handler) Update(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		ID uint `json:"id" form:"id" binding:"required"`
+		appForm
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	app := form.app()
+	app.ID = form.ID
+	if err := h.platform.UpdateApp(c.Request.Context(), app); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			base.Failure("应用不存在或已被删除", nil, errcode.NotExist)
+			return
+		}
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessChange, "success")
+}
+
+// RotateSecret 轮换密钥
+func (h *Handler) RotateSecret(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		ID    uint `json:"id" form:"id" binding:"required"`
+		Grace int  `json:"grace" form:"grace" binding:"min=-1"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	secret, err := h.platform.RotateSecret(c.Request.Context(), form.ID, time.Duration(form.Grace)*time.Second)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			base.Failure("应用不存在或已被删除", nil, errcode.NotExist)
+			return
+		}
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessChange, "success", map[string]any{"secret": secret})
+}
+
+// Delete 删除应用
+func (h *Handler) Delete(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		IDs []uint `json:"ids" form:"ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.platform.DeleteApp(c.Request.Context(), form.IDs...); err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessDelete, "success")
+}
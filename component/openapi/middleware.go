@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"github.com/jcbowen/jcbaseGo/middleware"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AppContextKey 当前应用在gin上下文中的键名
+const AppContextKey = "OpenAPIApp"
+
+// 校验错误，错误码相同的错误仅提示信息不同，因此不导出
+var (
+	errSignatureMissing = errcode.New(errcode.InvalidSign, "缺少签名参数")
+	errSignatureInvalid = errcode.New(errcode.InvalidSign, "签名错误")
+	errTimestamp        = errcode.New(errcode.InvalidTimestamp, "请求时间戳无效或已过期")
+	errReplay           = errcode.New(errcode.InvalidSign, "重复的请求")
+	errInvalidApp       = errcode.New(errcode.InvalidAuthorizationInformation, "AppKey无效")
+	errAppDisabled      = errcode.New(errcode.DISABLE, "应用已被禁用")
+	errIPNotAllowed     = errcode.New(errcode.IllegalAccess, "IP不在白名单中")
+	errRateLimited      = errcode.New(http.StatusTooManyRequests, "请求过于频繁，请稍后再试")
+	errScope            = errcode.New(errcode.NoPermissionVisit, "应用无权访问该接口")
+)
+
+// GetApp 获取当前请求的应用，需在 Middleware 之后调用
+func GetApp(c *gin.Context) (*App, bool) {
+	v, ok := c.Get(AppContextKey)
+	if !ok {
+		return nil, false
+	}
+	app, ok := v.(*App)
+	return app, ok
+}
+
+// Middleware 开放接口中间件：校验 AppKey、时间戳、nonce、签名、IP白名单及访问频率，
+// 传入 scopes 时还要求应用拥有全部权限范围；通过后可使用 GetApp 获取当前应用
+//
+// 签名使用的路径为服务端收到的请求路径，经反向代理改写路径时需保证与调用方签名时一致
+func (p *Platform) Middleware(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		app, err := p.authenticate(c)
+		if err == nil {
+			err = p.rateLimit(c, app)
+		}
+		if err == nil {
+			for _, scope := range scopes {
+				if !app.HasScope(scope) {
+					err = errcode.New(errScope.Code, errScope.Message).WithMeta("scope", scope)
+					break
+				}
+			}
+		}
+		if err != nil {
+			controller.Base{GinContext: c}.Error(err)
+			c.Abort()
+			return
+		}
+		c.Set(AppContextKey, app)
+		c.Next()
+	}
+}
+
+// RequireScope 权限范围校验中间件，需放在 Middleware 之后
+func (p *Platform) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		app, ok := GetApp(c)
+		if !ok {
+			controller.Base{GinContext: c}.Error(errSignatureMissing)
+			c.Abort()
+			return
+		}
+		if !app.HasScope(scope) {
+			controller.Base{GinContext: c}.Error(errcode.New(errScope.Code, errScope.Message).WithMeta("scope", scope))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticate 校验请求签名，返回请求的应用
+func (p *Platform) authenticate(c *gin.Context) (*App, error) {
+	appKey := c.GetHeader(HeaderAppKey)
+	nonce := c.GetHeader(HeaderNonce)
+	signature, _ := strings.CutPrefix(c.GetHeader(HeaderSignature), "sha256=")
+	timestamp, err := strconv.ParseInt(c.GetHeader(HeaderTimestamp), 10, 64)
+	if appKey == "" || signature == "" || err != nil || len(nonce) < 8 || len(nonce) > 64 {
+		return nil, errSignatureMissing
+	}
+	if diff := time.Since(time.Unix(timestamp, 0)); diff > p.opt.Tolerance || diff < -p.opt.Tolerance {
+		return nil, errTimestamp
+	}
+
+	app, err := p.appByKey(c.Request.Context(), appKey)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, errInvalidApp
+		}
+		return nil, errcode.Wrap(err, errcode.SystemBusy, "")
+	}
+	if app.Status != StatusEnabled {
+		return nil, errAppDisabled
+	}
+	if !ipAllowed(app.AllowIPs, middleware.GetRealIP(c)) {
+		return nil, errIPNotAllowed
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(c.Request.Body, p.opt.MaxBodySize+1))
+		if err != nil {
+			return nil, errcode.Wrap(err, errcode.ParamError, "读取请求体失败")
+		}
+		if int64(len(body)) > p.opt.MaxBodySize {
+			return nil, errcode.New(errcode.IllegalSize, "请求体过大").WithStatus(http.StatusRequestEntityTooLarge)
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	params := SignParams{
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.EscapedPath(),
+		Query:     c.Request.URL.Query(),
+		Body:      body,
+		AppKey:    appKey,
+		Timestamp: timestamp,
+		Nonce:     nonce,
+	}
+	matched := false
+	for _, secret := range app.secrets() {
+		if hmac.Equal([]byte(signature), []byte(Sign(secret, params))) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil, errSignatureInvalid
+	}
+
+	// 签名通过后再记录 nonce，避免伪造的请求占用 nonce；有效期覆盖时间戳允许误差的两端
+	// 原子地占用 nonce，并发的重复请求只有一个能通过；缓存不可用时拒绝请求，不能跳过防重放
+	ok, err := p.nonces.SetNX("openapi:nonce:"+appKey+":"+nonce, "1", 2*p.opt.Tolerance)
+	if err != nil {
+		return nil, errcode.Wrap(err, errcode.SystemBusy, "")
+	}
+	if !ok {
+		return nil, errReplay
+	}
+	return app, nil
+}
+
+// rateLimit 按应用每分钟的请求数限制访问频率，并输出 X-RateLimit-* 响应头
+func (p *Platform) rateLimit(c *gin.Context, app *App) error {
+	if app.RateLimit <= 0 {
+		return nil
+	}
+	count, reset := p.limiter.hit(app.AppKey, time.Minute)
+	c.Header("X-RateLimit-Limit", strconv.Itoa(app.RateLimit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(max(app.RateLimit-count, 0)))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	if count > app.RateLimit {
+		c.Header("Retry-After", strconv.Itoa(max(int(time.Until(reset).Seconds()+0.5), 1)))
+		return errRateLimited
+	}
+	return nil
+}
+
+// ipAllowed IP是否在白名单中，白名单为空时不限制
+func ipAllowed(allowIPs, clientIP string) bool {
+	if strings.TrimSpace(allowIPs) == "" {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, allow := range strings.Split(allowIPs, ",") {
+		allow = strings.TrimSpace(allow)
+		if strings.Contains(allow, "/") {
+			if _, ipNet, err := net.ParseCIDR(allow); err == nil && ipNet.Contains(ip) {
+				return true
+			}
+		} else if allowIP := net.ParseIP(allow); allowIP != nil && allowIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// limiter 进程内的固定窗口计数器
+type limiter struct {
+	mu      sync.Mutex
+	windows map[string]*window
+	sweepAt time.Time
+}
+
+type window struct {
+	count int
+	reset time.Time
+}
+
+func newLimiter() *limiter {
+	return &limiter{windows: make(map[string]*window)}
+}
+
+// hit 计数加1，返回当前窗口的计数及窗口结束时间
+func (l *limiter) hit(key string, size time.Duration) (int, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if !now.Before(l.sweepAt) {
+		l.sweepAt = now.Add(size)
+		for k, w := range l.windows {
+			if !now.Before(w.reset) {
+				delete(l.windows, k)
+			}
+		}
+	}
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.reset) {
+		w = &window{reset: now.Add(size)}
+		l.windows[key] = w
+	}
+	w.count++
+	return w.count, w.reset
+}
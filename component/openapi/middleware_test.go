@@ -0,0 +1,133 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestPlatform(t *testing.T, opt Options) (*Platform, *App) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	opt.AutoMigrate = true
+	p := New(db, opt)
+	app, err := p.CreateApp(context.Background(), App{Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, app
+}
+
+func newTestRouter(p *Platform) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/open/order", p.Middleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func signedRequest(t *testing.T, app *App) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/open/order?id=1", strings.NewReader(`{"amount":1}`))
+	if err := SignRequest(req, app.AppKey, app.Secret); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+// cloneRequest 复制请求头及请求体，模拟被截获后重放的请求
+func cloneRequest(req *http.Request) *http.Request {
+	clone := httptest.NewRequest(req.Method, req.URL.String(), strings.NewReader(`{"amount":1}`))
+	clone.Header = req.Header.Clone()
+	return clone
+}
+
+func TestMiddlewareRejectsConcurrentReplay(t *testing.T) {
+	p, app := newTestPlatform(t, Options{})
+	r := newTestRouter(p)
+	original := signedRequest(t, app)
+
+	const n = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	passed := 0
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, cloneRequest(original))
+			if w.Body.String() == "ok" {
+				mu.Lock()
+				passed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if passed != 1 {
+		t.Fatalf("%d of %d copies of the same signed request passed, want 1", passed, n)
+	}
+}
+
+func TestMiddlewareSignature(t *testing.T) {
+	p, app := newTestPlatform(t, Options{})
+	r := newTestRouter(p)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest(t, app))
+	if w.Body.String() != "ok" {
+		t.Fatalf("signed request rejected: %s", w.Body.String())
+	}
+
+	// 篡改请求体后签名不匹配
+	req := signedRequest(t, app)
+	tampered := httptest.NewRequest(req.Method, req.URL.String(), strings.NewReader(`{"amount":100}`))
+	tampered.Header = req.Header.Clone()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, tampered)
+	if w.Body.String() == "ok" {
+		t.Fatal("tampered request should be rejected")
+	}
+
+	// 过期的时间戳
+	req = signedRequest(t, app)
+	req.Header.Set(HeaderTimestamp, "1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() == "ok" {
+		t.Fatal("expired request should be rejected")
+	}
+}
+
+// failingCache 不可用的 nonce 缓存
+type failingCache struct{}
+
+func (failingCache) SetNX(string, interface{}, time.Duration) (bool, error) {
+	return false, errors.New("cache unavailable")
+}
+
+func TestMiddlewareRejectsWhenNonceCacheFails(t *testing.T) {
+	p, app := newTestPlatform(t, Options{NonceCache: failingCache{}})
+	r := newTestRouter(p)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest(t, app))
+	if w.Body.String() == "ok" {
+		t.Fatal("request should be rejected when replay protection is unavailable")
+	}
+}
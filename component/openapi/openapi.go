@@ -0,0 +1,295 @@
+// Package openapi 开放平台：为合作方签发应用凭证（AppKey/Secret），校验请求签名，并按应用限制访问频率及权限范围
+//
+// 签名算法见 Sign，合作方使用 httpclient 时可直接使用 SignMiddleware。
+// 轮换密钥后旧密钥在宽限期内仍然有效，便于合作方平滑切换。
+// 访问频率计数及防重放的 nonce 默认保存在进程内存中，多实例部署时应传入共享的 NonceCache。
+package openapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/helper/cache"
+	"gorm.io/gorm"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 应用状态
+const (
+	StatusDisabled = 0
+	StatusEnabled  = 1
+)
+
+// ErrNotFound 应用不存在
+var ErrNotFound = errors.New("openapi: 应用不存在")
+
+// App 第三方应用
+type App struct {
+	ID              uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name            string     `gorm:"column:name;size:64;not null;comment:应用名称" json:"name"`
+	AppKey          string     `gorm:"column:app_key;size:64;not null;uniqueIndex;comment:AppKey" json:"app_key"`
+	Secret          string     `gorm:"column:secret;size:128;not null;comment:签名密钥" json:"-"`
+	PrevSecret      string     `gorm:"column:prev_secret;size:128;default:'';comment:轮换前的密钥" json:"-"`
+	PrevExpiresAt   *time.Time `gorm:"column:prev_expires_at;comment:旧密钥失效时间" json:"prev_expires_at"`
+	Scopes          string     `gorm:"column:scopes;size:1000;default:'';comment:权限范围，逗号分隔，*表示全部，order.*表示前缀匹配" json:"scopes"`
+	RateLimit       int        `gorm:"column:rate_limit;default:0;comment:每分钟最大请求数，0表示不限制" json:"rate_limit"`
+	AllowIPs        string     `gorm:"column:allow_ips;size:1000;default:'';comment:IP白名单，逗号分隔，支持CIDR，为空表示不限制" json:"allow_ips"`
+	Status          int        `gorm:"column:status;default:1;comment:状态 0禁用 1启用" json:"status"`
+	Remark          string     `gorm:"column:remark;size:255;default:'';comment:备注" json:"remark"`
+	SecretRotatedAt *time.Time `gorm:"column:secret_rotated_at;comment:最后轮换密钥时间" json:"secret_rotated_at"`
+	CreatedAt       time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// HasScope 应用是否拥有权限范围
+func (a App) HasScope(scope string) bool {
+	for _, pattern := range strings.Split(a.Scopes, ",") {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "*" || pattern == scope:
+			return true
+		case strings.HasSuffix(pattern, ".*") && strings.HasPrefix(scope, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// secrets 当前有效的密钥，旧密钥在宽限期内仍然有效
+func (a App) secrets() []string {
+	secrets := []string{a.Secret}
+	if a.PrevSecret != "" && a.PrevExpiresAt != nil && time.Now().Before(*a.PrevExpiresAt) {
+		secrets = append(secrets, a.PrevSecret)
+	}
+	return secrets
+}
+
+// NonceCache 防重放的 nonce 缓存，多实例部署时应使用共享缓存（如 redis.CacheOpt）
+type NonceCache interface {
+	// SetNX 键不存在时设置键值，需为原子操作，返回是否设置成功
+	SetNX(key string, value interface{}, expire time.Duration) (bool, error)
+}
+
+// Options 配置
+type Options struct {
+	AppTable    string `default:"openapi_app"` // 应用表名（含前缀）
+	AutoMigrate bool   // 是否自动创建数据表
+
+	Tolerance   time.Duration // 请求时间戳允许的误差，同时为 nonce 的有效期，默认5分钟
+	NonceCache  NonceCache    // nonce 缓存，为空时使用进程内缓存
+	MaxBodySize int64         `default:"10485760"` // 参与签名的请求体最大字节数，默认10MB
+	CacheTTL    time.Duration // 应用信息在进程内的缓存时间，默认1分钟；本实例修改应用后立即失效
+	SecretGrace time.Duration // 轮换密钥后旧密钥的默认宽限期，默认24小时
+}
+
+// Platform 开放平台
+//
+// 示例:
+//
+//	platform := openapi.New(db.GetDb(), openapi.Options{AutoMigrate: true, NonceCache: redis.NewCache(rds)})
+//	app, _ := platform.CreateApp(ctx, openapi.App{Name: "合作方A", Scopes: "order.*", RateLimit: 600})
+//	// app.AppKey、app.Secret 交给合作方
+//
+//	api := r.Group("/open", platform.Middleware())
+//	api.GET("/order/detail", platform.RequireScope("order.read"), orderDetail)
+type Platform struct {
+	db  *gorm.DB
+	opt Options
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedApp
+	limiter *limiter
+	nonces  NonceCache
+}
+
+type cachedApp struct {
+	app     *App
+	expires time.Time
+}
+
+// New 创建开放平台
+func New(db *gorm.DB, opts ...Options) *Platform {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.Tolerance <= 0 {
+		opt.Tolerance = 5 * time.Minute
+	}
+	if opt.CacheTTL <= 0 {
+		opt.CacheTTL = time.Minute
+	}
+	if opt.SecretGrace <= 0 {
+		opt.SecretGrace = 24 * time.Hour
+	}
+
+	p := &Platform{
+		db:      db,
+		opt:     opt,
+		cache:   make(map[string]cachedApp),
+		limiter: newLimiter(),
+		nonces:  opt.NonceCache,
+	}
+	if p.nonces == nil {
+		p.nonces = cache.NewMemory()
+	}
+	if opt.AutoMigrate {
+		_ = p.table(context.Background()).AutoMigrate(&App{})
+	}
+	return p
+}
+
+// CreateApp 创建应用，自动生成 AppKey 及 Secret，返回的应用中包含密钥
+func (p *Platform) CreateApp(ctx context.Context, app App) (*App, error) {
+	app.ID = 0
+	app.AppKey = randomHex(8)
+	app.Secret = randomHex(24)
+	app.PrevSecret, app.PrevExpiresAt, app.SecretRotatedAt = "", nil, nil
+	if app.Status != StatusDisabled {
+		app.Status = StatusEnabled
+	}
+	if err := p.table(ctx).Create(&app).Error; err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// UpdateApp 修改应用的名称、权限范围、频率限制、IP白名单、状态及备注，不修改密钥
+func (p *Platform) UpdateApp(ctx context.Context, app App) error {
+	result := p.table(ctx).Where("id = ?", app.ID).Updates(map[string]any{
+		"name":       app.Name,
+		"scopes":     app.Scopes,
+		"rate_limit": app.RateLimit,
+		"allow_ips":  app.AllowIPs,
+		"status":     app.Status,
+		"remark":     app.Remark,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	p.invalidate()
+	return nil
+}
+
+// RotateSecret 生成新的密钥，旧密钥在宽限期内仍然有效，grace 为0时使用 SecretGrace，小于0时旧密钥立即失效
+func (p *Platform) RotateSecret(ctx context.Context, id uint, grace time.Duration) (string, error) {
+	app, err := p.GetApp(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if grace == 0 {
+		grace = p.opt.SecretGrace
+	}
+	now := time.Now()
+	values := map[string]any{
+		"secret":            randomHex(24),
+		"prev_secret":       "",
+		"prev_expires_at":   nil,
+		"secret_rotated_at": now,
+		"updated_at":        now,
+	}
+	if grace > 0 {
+		values["prev_secret"], values["prev_expires_at"] = app.Secret, now.Add(grace)
+	}
+	// 以原密钥为条件更新，避免并发轮换时丢失其中一次生成的密钥
+	result := p.table(ctx).Where("id = ? AND secret = ?", id, app.Secret).Updates(values)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", errors.New("openapi: 密钥已被其他请求轮换，请重试")
+	}
+	p.invalidate()
+	return values["secret"].(string), nil
+}
+
+// DeleteApp 删除应用
+func (p *Platform) DeleteApp(ctx context.Context, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := p.table(ctx).Where("id IN ?", ids).Delete(&App{}).Error; err != nil {
+		return err
+	}
+	p.invalidate()
+	return nil
+}
+
+// GetApp 根据ID获取应用
+func (p *Platform) GetApp(ctx context.Context, id uint) (*App, error) {
+	var app App
+	if err := p.table(ctx).Where("id = ?", id).Take(&app).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &app, nil
+}
+
+// Apps 全部应用，不返回密钥
+func (p *Platform) Apps(ctx context.Context) ([]App, error) {
+	list := make([]App, 0)
+	err := p.table(ctx).Order("id").Find(&list).Error
+	return list, err
+}
+
+// ----- 内部方法 ----- /
+
+func (p *Platform) table(ctx context.Context) *gorm.DB {
+	return p.db.WithContext(ctx).Table(p.opt.AppTable)
+}
+
+// appByKey 根据 AppKey 获取应用，使用进程内缓存；应用不存在时同样缓存，避免无效的 AppKey 反复查询数据库
+func (p *Platform) appByKey(ctx context.Context, appKey string) (*App, error) {
+	p.cacheMu.Lock()
+	cached, ok := p.cache[appKey]
+	p.cacheMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		if cached.app == nil {
+			return nil, ErrNotFound
+		}
+		return cached.app, nil
+	}
+
+	var app App
+	err := p.table(ctx).Where("app_key = ?", appKey).Take(&app).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	entry := cachedApp{expires: time.Now().Add(p.opt.CacheTTL)}
+	if err == nil {
+		entry.app = &app
+	}
+	p.cacheMu.Lock()
+	if len(p.cache) >= 10000 {
+		p.cache = make(map[string]cachedApp) // 防止大量无效 AppKey 占用内存
+	}
+	p.cache[appKey] = entry
+	p.cacheMu.Unlock()
+	if entry.app == nil {
+		return nil, ErrNotFound
+	}
+	return entry.app, nil
+}
+
+func (p *Platform) invalidate() {
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	p.cache = make(map[string]cachedApp)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"github.com/jcbowen/jcbaseGo/component/webhook"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 请求头
+const (
+	HeaderAppKey    = "X-App-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// SignParams 参与签名的请求信息
+type SignParams struct {
+	Method    string     // 请求方法，大写
+	Path      string     // 请求路径（已编码），不含查询参数，如 /open/order/detail
+	Query     url.Values // 查询参数
+	Body      []byte     // 请求体
+	AppKey    string
+	Timestamp int64 // Unix时间戳，秒
+	Nonce     string
+}
+
+// Sign 计算签名，与 webhook.Sign 使用相同的算法：
+//
+//	hex(HMAC-SHA256(secret, timestamp + "." + canonical))
+//
+// canonical 为以下各项以换行符连接：请求方法、路径、按参数名排序后编码的查询参数、AppKey、Nonce、hex(SHA256(请求体))。
+// 请求头 X-Signature 的格式为 sha256=<签名>
+func Sign(secret string, p SignParams) string {
+	bodyHash := sha256.Sum256(p.Body)
+	canonical := strings.Join([]string{
+		strings.ToUpper(p.Method),
+		p.Path,
+		p.Query.Encode(), // Encode 按参数名排序
+		p.AppKey,
+		p.Nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	return webhook.Sign(secret, p.Timestamp, []byte(canonical))
+}
+
+// SignRequest 为请求添加签名相关的请求头，会读取并还原请求体
+func SignRequest(req *http.Request, appKey, secret string) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	p := SignParams{
+		Method:    req.Method,
+		Path:      req.URL.EscapedPath(),
+		Query:     req.URL.Query(),
+		Body:      body,
+		AppKey:    appKey,
+		Timestamp: time.Now().Unix(),
+		Nonce:     randomHex(16),
+	}
+	req.Header.Set(HeaderAppKey, appKey)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(p.Timestamp, 10))
+	req.Header.Set(HeaderNonce, p.Nonce)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(secret, p))
+	return nil
+}
+
+// SignMiddleware httpclient 签名中间件，供调用方使用，每次请求（含重试）都会重新生成时间戳及 nonce
+//
+// 示例:
+//
+//	client := httpclient.New(httpclient.Options{BaseURL: "https://api.example.com"}).
+//		Use(openapi.SignMiddleware(appKey, secret))
+//	resp, err := client.R().SetJSON(body).Post("/open/order/create")
+func SignMiddleware(appKey, secret string) httpclient.Middleware {
+	return func(next httpclient.Handler) httpclient.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := SignRequest(req, appKey, secret); err != nil {
+				return nil, err
+			}
+			return next(req)
+		}
+	}
+}
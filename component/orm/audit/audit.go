@@ -0,0 +1,430 @@
+// Package audit 数据变更记录，通过GORM回调记录新增、更新、删除前后的数据及操作人
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 操作类型
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// beforeKey 更新、删除前的数据在语句中的键名
+const beforeKey = "audit:before"
+
+// Log 变更记录
+type Log struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Table     string    `gorm:"column:table_name;size:64;index:idx_audit_record" json:"table_name"`
+	RecordID  string    `gorm:"column:record_id;size:64;index:idx_audit_record" json:"record_id"`
+	Action    string    `gorm:"column:action;size:16" json:"action"`
+	Operator  string    `gorm:"column:operator;size:64;index" json:"operator"` // 操作人，通过 WithOperator 设置
+	IP        string    `gorm:"column:ip;size:64" json:"ip"`
+	Changed   string    `gorm:"column:changed;type:text" json:"changed"` // 变更的字段，逗号分隔
+	Before    string    `gorm:"column:before;type:text" json:"before"`   // 变更前的数据（JSON），新增时为空
+	After     string    `gorm:"column:after;type:text" json:"after"`     // 变更后的数据（JSON），删除时为空
+	CreatedAt time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+// Auditable 实现该接口的模型开启变更记录
+type Auditable interface {
+	AuditIgnoreFields() []string // 不记录的字段（数据库列名），如密码、令牌，返回nil表示记录全部字段
+}
+
+// Options 变更记录配置
+type Options struct {
+	Table        string   `default:"audit_log"` // 记录表名，会自动加上数据库配置的表前缀
+	AutoMigrate  bool     // 是否自动创建记录表
+	Models       []any    // 无需实现 Auditable 即开启记录的模型
+	IgnoreFields []string // 所有模型都不记录的字段，如 updated_at
+	MaxRows      int      `default:"1000"` // 单条语句最多记录的行数，超出时只记录前 MaxRows 行
+
+	// Operator 获取操作人及IP，默认读取 WithOperator 设置的值
+	Operator func(ctx context.Context) (operator, ip string)
+}
+
+// Recorder 变更记录插件
+//
+// 示例:
+//
+//	db := mysql.New(conf).EnableAudit(audit.Options{AutoMigrate: true, IgnoreFields: []string{"updated_at"}})
+//	r.Use(audit.Middleware(func(c *gin.Context) string { return c.GetString("UserID") }))
+//	// 在请求中使用携带操作人的 context
+//	db.GetDb().WithContext(c.Request.Context()).Model(&user).Updates(data)
+//	logs, _ := db.GetAuditor().History(ctx, &User{}, user.Id)
+type Recorder struct {
+	opt   Options
+	db    *gorm.DB
+	table string
+
+	mu     sync.RWMutex
+	tables map[string]bool // 通过 Models 开启记录的表
+}
+
+// New 创建变更记录插件
+func New(opts ...Options) *Recorder {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.Operator == nil {
+		opt.Operator = operatorFromContext
+	}
+	return &Recorder{opt: opt, tables: make(map[string]bool)}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (r *Recorder) Name() string {
+	return "jcbaseGo:audit"
+}
+
+// Initialize 实现 gorm.Plugin 接口
+func (r *Recorder) Initialize(db *gorm.DB) error {
+	r.db = db
+	r.table = r.opt.Table
+	if ns, ok := db.NamingStrategy.(schema.NamingStrategy); ok {
+		r.table = ns.TablePrefix + r.opt.Table
+	}
+	if r.opt.AutoMigrate {
+		if err := db.Table(r.table).AutoMigrate(&Log{}); err != nil {
+			return err
+		}
+	}
+	if err := r.Register(r.opt.Models...); err != nil {
+		return err
+	}
+
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Register("audit:after_create", r.afterCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("audit:before_update", r.before); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("audit:after_update", r.afterUpdate); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("audit:before_delete", r.before); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("audit:after_delete", r.afterDelete)
+}
+
+// Register 为模型开启变更记录，用于无法修改的模型
+func (r *Recorder) Register(models ...any) error {
+	if len(models) == 0 {
+		return nil
+	}
+	if r.db == nil {
+		r.opt.Models = append(r.opt.Models, models...)
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: r.db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		r.tables[stmt.Schema.Table] = true
+	}
+	return nil
+}
+
+// ignored 获取语句对应模型不记录的字段，模型未开启记录时返回 false
+func (r *Recorder) ignored(db *gorm.DB) (map[string]bool, bool) {
+	stmt := db.Statement
+	if stmt.Schema == nil || stmt.Table == r.table {
+		return nil, false
+	}
+	ignore := make(map[string]bool)
+	for _, field := range r.opt.IgnoreFields {
+		ignore[field] = true
+	}
+
+	model := reflect.New(stmt.Schema.ModelType).Interface()
+	if auditable, ok := model.(Auditable); ok {
+		for _, field := range auditable.AuditIgnoreFields() {
+			ignore[field] = true
+		}
+		return ignore, true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return ignore, r.tables[stmt.Schema.Table]
+}
+
+// before 更新、删除前查询受影响的数据
+func (r *Recorder) before(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if _, ok := r.ignored(db); !ok {
+		return
+	}
+	rows, err := r.find(db, r.conditions(db))
+	if err != nil {
+		log.Printf("audit: 查询 %s 变更前的数据失败: %v", db.Statement.Table, err)
+		return
+	}
+	db.InstanceSet(beforeKey, rows)
+}
+
+func (r *Recorder) afterCreate(db *gorm.DB) {
+	if db.Error != nil || db.RowsAffected == 0 {
+		return
+	}
+	ignore, ok := r.ignored(db)
+	if !ok {
+		return
+	}
+
+	var logs []Log
+	eachModel(db.Statement.ReflectValue, func(rv reflect.Value) {
+		if len(logs) >= r.opt.MaxRows {
+			return
+		}
+		after := make(map[string]any)
+		for _, field := range db.Statement.Schema.Fields {
+			if field.DBName != "" && !ignore[field.DBName] {
+				after[field.DBName], _ = field.ValueOf(db.Statement.Context, rv)
+			}
+		}
+		logs = append(logs, r.newLog(db, ActionCreate, r.recordID(db, after), nil, after, ignore))
+	})
+	r.save(db, logs)
+}
+
+func (r *Recorder) afterUpdate(db *gorm.DB) {
+	before, ok := r.beforeRows(db)
+	if !ok || len(before) == 0 {
+		return
+	}
+	ignore, _ := r.ignored(db)
+
+	// 按主键重新查询变更后的数据
+	pk := primaryKey(db)
+	if pk == "" {
+		return
+	}
+	ids := make([]any, 0, len(before))
+	for _, row := range before {
+		ids = append(ids, row[pk])
+	}
+	after, err := r.find(db, []clause.Expression{clause.IN{Column: clause.Column{Name: pk}, Values: ids}})
+	if err != nil {
+		log.Printf("audit: 查询 %s 变更后的数据失败: %v", db.Statement.Table, err)
+		return
+	}
+	afterByID := make(map[string]map[string]any, len(after))
+	for _, row := range after {
+		afterByID[fmt.Sprint(row[pk])] = row
+	}
+
+	var logs []Log
+	for _, old := range before {
+		id := fmt.Sprint(old[pk])
+		row, found := afterByID[id]
+		if !found {
+			continue
+		}
+		changedBefore, changedAfter := diff(old, row, ignore)
+		if len(changedAfter) == 0 {
+			continue
+		}
+		logs = append(logs, r.newLog(db, ActionUpdate, id, changedBefore, changedAfter, ignore))
+	}
+	r.save(db, logs)
+}
+
+func (r *Recorder) afterDelete(db *gorm.DB) {
+	before, ok := r.beforeRows(db)
+	if !ok || len(before) == 0 {
+		return
+	}
+	ignore, _ := r.ignored(db)
+	pk := primaryKey(db)
+	logs := make([]Log, 0, len(before))
+	for _, row := range before {
+		logs = append(logs, r.newLog(db, ActionDelete, fmt.Sprint(row[pk]), row, nil, ignore))
+	}
+	r.save(db, logs)
+}
+
+// beforeRows 获取 before 中保存的数据
+func (r *Recorder) beforeRows(db *gorm.DB) ([]map[string]any, bool) {
+	if db.Error != nil || db.RowsAffected == 0 {
+		return nil, false
+	}
+	v, ok := db.InstanceGet(beforeKey)
+	if !ok {
+		return nil, false
+	}
+	rows, ok := v.([]map[string]any)
+	return rows, ok
+}
+
+// conditions 获取语句的查询条件，模型中设置了主键时加上主键条件
+func (r *Recorder) conditions(db *gorm.DB) []clause.Expression {
+	var exprs []clause.Expression
+	if where, ok := db.Statement.Clauses["WHERE"]; ok && where.Expression != nil {
+		exprs = append(exprs, where.Expression)
+	}
+	if field := db.Statement.Schema.PrioritizedPrimaryField; field != nil {
+		var ids []any
+		eachModel(db.Statement.ReflectValue, func(rv reflect.Value) {
+			if id, zero := field.ValueOf(db.Statement.Context, rv); !zero {
+				ids = append(ids, id)
+			}
+		})
+		if len(ids) > 0 {
+			exprs = append(exprs, clause.IN{Column: clause.Column{Name: field.DBName}, Values: ids})
+		}
+	}
+	return exprs
+}
+
+// find 在同一连接（事务）中按条件查询原始数据
+func (r *Recorder) find(db *gorm.DB, exprs []clause.Expression) ([]map[string]any, error) {
+	rows := make([]map[string]any, 0)
+	if len(exprs) == 0 {
+		return rows, nil
+	}
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(db.Statement.Table).Clauses(exprs...)
+	if pk := primaryKey(db); pk != "" {
+		tx = tx.Order(pk)
+	}
+	err := tx.Limit(r.opt.MaxRows).Find(&rows).Error
+	return rows, err
+}
+
+// newLog 创建变更记录
+func (r *Recorder) newLog(db *gorm.DB, action, recordID string, before, after map[string]any, ignore map[string]bool) Log {
+	operator, ip := r.opt.Operator(db.Statement.Context)
+	l := Log{
+		Table:     db.Statement.Table,
+		RecordID:  recordID,
+		Action:    action,
+		Operator:  operator,
+		IP:        ip,
+		CreatedAt: time.Now(),
+	}
+	changed := make(map[string]bool)
+	if before != nil {
+		before = filter(before, ignore)
+		l.Before = toJSON(before)
+		for k := range before {
+			changed[k] = true
+		}
+	}
+	if after != nil {
+		after = filter(after, ignore)
+		l.After = toJSON(after)
+		for k := range after {
+			changed[k] = true
+		}
+	}
+	fields := make([]string, 0, len(changed))
+	for k := range changed {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	for i, field := range fields {
+		if i > 0 {
+			l.Changed += ","
+		}
+		l.Changed += field
+	}
+	return l
+}
+
+// save 保存变更记录，失败时只记录日志，不影响业务操作
+func (r *Recorder) save(db *gorm.DB, logs []Log) {
+	if len(logs) == 0 {
+		return
+	}
+	err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(r.table).CreateInBatches(&logs, 100).Error
+	if err != nil {
+		log.Printf("audit: 保存 %s 的变更记录失败: %v", db.Statement.Table, err)
+	}
+}
+
+// recordID 获取记录的主键值
+func (r *Recorder) recordID(db *gorm.DB, row map[string]any) string {
+	if pk := primaryKey(db); pk != "" {
+		return fmt.Sprint(row[pk])
+	}
+	return ""
+}
+
+// primaryKey 获取主键字段名
+func primaryKey(db *gorm.DB) string {
+	if field := db.Statement.Schema.PrioritizedPrimaryField; field != nil {
+		return field.DBName
+	}
+	return ""
+}
+
+// eachModel 遍历语句中的模型，支持切片
+func eachModel(rv reflect.Value, fn func(rv reflect.Value)) {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			item := reflect.Indirect(rv.Index(i))
+			if item.Kind() == reflect.Struct {
+				fn(item)
+			}
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
+
+// diff 比较变更前后的数据，返回发生变化的字段
+func diff(before, after map[string]any, ignore map[string]bool) (map[string]any, map[string]any) {
+	changedBefore, changedAfter := make(map[string]any), make(map[string]any)
+	for k, v := range after {
+		if ignore[k] {
+			continue
+		}
+		if toJSON(before[k]) != toJSON(v) {
+			changedBefore[k] = before[k]
+			changedAfter[k] = v
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+// filter 去掉不记录的字段
+func filter(row map[string]any, ignore map[string]bool) map[string]any {
+	result := make(map[string]any, len(row))
+	for k, v := range row {
+		if !ignore[k] {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func toJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/middleware"
+	"gorm.io/gorm"
+	"time"
+)
+
+type operatorKey struct{}
+
+type actor struct {
+	operator string
+	ip       string
+}
+
+// WithOperator 在 context 中设置操作人及IP，数据库操作使用该 context 时记录到变更记录中
+//
+// 示例:
+//
+//	ctx := audit.WithOperator(context.Background(), "system", "")
+//	db.WithContext(ctx).Delete(&order)
+func WithOperator(ctx context.Context, operator, ip string) context.Context {
+	return context.WithValue(ctx, operatorKey{}, actor{operator: operator, ip: ip})
+}
+
+// operatorFromContext 读取 WithOperator 设置的操作人及IP
+func operatorFromContext(ctx context.Context) (string, string) {
+	if ctx == nil {
+		return "", ""
+	}
+	if a, ok := ctx.Value(operatorKey{}).(actor); ok {
+		return a.operator, a.ip
+	}
+	return "", ""
+}
+
+// Middleware 将操作人及客户端IP写入请求的 context，数据库操作需使用 db.WithContext(c.Request.Context())
+// operator 用于从请求中获取操作人，如读取登录中间件保存的用户ID
+func Middleware(operator func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := WithOperator(c.Request.Context(), operator(c), middleware.GetRealIP(c))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// Filter 变更记录查询条件，为空的条件不参与过滤
+type Filter struct {
+	Table    string // 表名（含前缀）
+	RecordID string
+	Action   string
+	Operator string
+	Start    time.Time
+	End      time.Time
+	Page     int // 页码，默认1
+	PageSize int // 每页条数，默认20，最大1000
+}
+
+// List 分页查询变更记录，按时间倒序排列
+func (r *Recorder) List(ctx context.Context, f Filter) (jcbaseGo.ListData, error) {
+	page, pageSize := max(f.Page, 1), f.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	} else if pageSize > 1000 {
+		pageSize = 1000
+	}
+	listData := jcbaseGo.ListData{Page: page, PageSize: pageSize}
+	if r.db == nil {
+		return listData, fmt.Errorf("audit: 插件未注册")
+	}
+
+	query := r.db.WithContext(ctx).Table(r.table)
+	if f.Table != "" {
+		query = query.Where("table_name = ?", f.Table)
+	}
+	if f.RecordID != "" {
+		query = query.Where("record_id = ?", f.RecordID)
+	}
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.Operator != "" {
+		query = query.Where("operator = ?", f.Operator)
+	}
+	if !f.Start.IsZero() {
+		query = query.Where("created_at >= ?", f.Start)
+	}
+	if !f.End.IsZero() {
+		query = query.Where("created_at <= ?", f.End)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return listData, err
+	}
+	list := make([]Log, 0)
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error
+	listData.List = list
+	listData.Total = int(total)
+	return listData, err
+}
+
+// History 获取单条记录的全部变更记录，按时间正序排列
+//
+// 示例:
+//
+//	logs, err := auditor.History(ctx, &User{}, 10)
+func (r *Recorder) History(ctx context.Context, model any, id any) ([]Log, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("audit: 插件未注册")
+	}
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	list := make([]Log, 0)
+	err := r.db.WithContext(ctx).Table(r.table).
+		Where("table_name = ? AND record_id = ?", stmt.Schema.Table, fmt.Sprint(id)).
+		Order("id").Find(&list).Error
+	return list, err
+}
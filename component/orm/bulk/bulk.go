@@ -0,0 +1,192 @@
+// Package bulk 分批生成多行语句的批量写入、更新、删除
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+)
+
+// 单条语句的占位符上限，超出时自动缩小批次
+const (
+	mysqlMaxPlaceholders  = 65535
+	sqliteMaxPlaceholders = 32766
+)
+
+// Options 批量操作配置
+type Options struct {
+	BatchSize   int  // 每批条数，默认1000，超出数据库单条语句占位符上限时自动缩小
+	SkipHooks   bool // 跳过模型钩子（BeforeCreate等），数据量大时可明显提升速度
+	StopOnError bool // 某一批失败后不再执行后续批次，默认继续执行
+}
+
+// BatchError 单个批次的错误
+type BatchError struct {
+	Batch  int   `json:"batch"`  // 批次序号，从1开始
+	Offset int   `json:"offset"` // 该批第一条数据在原切片中的下标
+	Count  int   `json:"count"`  // 该批条数
+	Err    error `json:"-"`
+}
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("bulk: 第%d批（第%d-%d条）失败: %v", e.Batch, e.Offset+1, e.Offset+e.Count, e.Err)
+}
+
+func (e BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Result 批量操作结果
+type Result struct {
+	Total        int          `json:"total"`         // 待处理的总条数
+	RowsAffected int64        `json:"rows_affected"` // 成功批次的影响行数合计
+	Batches      int          `json:"batches"`       // 已执行的批次数
+	Errors       []BatchError `json:"errors"`        // 失败的批次
+}
+
+// Err 合并所有批次的错误，全部成功时返回nil
+func (r Result) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	errs := make([]error, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		errs = append(errs, e)
+	}
+	return errors.Join(errs...)
+}
+
+// Insert 分批插入，models 为结构体切片（或结构体指针切片），每批生成一条多行 INSERT 语句
+// 各批次独立执行，需要整体回滚时在事务中调用：db.Transaction(func(tx *gorm.DB) error { return bulk.Insert(tx, rows, opt).Err() })
+//
+// 示例:
+//
+//	res := bulk.Insert(db, users, bulk.Options{BatchSize: 2000})
+//	if err := res.Err(); err != nil {
+//		for _, e := range res.Errors {
+//			log.Println(e.Offset, e.Count, e.Err)
+//		}
+//	}
+func Insert(db *gorm.DB, models any, opt Options) Result {
+	return run(db, models, opt, func(tx *gorm.DB, batch any) *gorm.DB {
+		return tx.Create(batch)
+	})
+}
+
+// Upsert 分批插入，唯一键冲突时更新 updateColumns 指定的列
+// conflictColumns 为冲突判断的列（MySQL使用表上的唯一索引，忽略该参数）；updateColumns 为空时更新除冲突列外的全部列
+//
+// 示例:
+//
+//	res := bulk.Upsert(db, stocks, []string{"sku"}, []string{"stock", "updated_at"}, bulk.Options{})
+func Upsert(db *gorm.DB, models any, conflictColumns, updateColumns []string, opt Options) Result {
+	onConflict := clause.OnConflict{}
+	for _, column := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if len(updateColumns) > 0 {
+		onConflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+	} else {
+		onConflict.UpdateAll = true
+	}
+	return run(db, models, opt, func(tx *gorm.DB, batch any) *gorm.DB {
+		return tx.Clauses(onConflict).Create(batch)
+	})
+}
+
+// Delete 按主键分批删除，ids 为主键切片，每批生成一条 DELETE ... WHERE pk IN (...) 语句
+// 模型支持软删除时为软删除
+//
+// 示例:
+//
+//	res := bulk.Delete(db, &User{}, ids, bulk.Options{BatchSize: 5000})
+func Delete(db *gorm.DB, model any, ids any, opt Options) Result {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return Result{Errors: []BatchError{{Batch: 1, Err: err}}}
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return Result{Errors: []BatchError{{Batch: 1, Err: fmt.Errorf("bulk: %s 没有主键", stmt.Schema.Name)}}}
+	}
+	opt.BatchSize = limitBatch(db, opt.BatchSize, 1)
+	return run(db, ids, opt, func(tx *gorm.DB, batch any) *gorm.DB {
+		return tx.Where(clause.IN{Column: clause.Column{Name: field.DBName}, Values: toValues(batch)}).Delete(model)
+	})
+}
+
+// run 将切片按批次切分后逐批执行
+func run(db *gorm.DB, values any, opt Options, exec func(tx *gorm.DB, batch any) *gorm.DB) Result {
+	rv := reflect.ValueOf(values)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice {
+		return Result{Errors: []BatchError{{Batch: 1, Err: fmt.Errorf("bulk: 需要传入切片，实际为 %T", values)}}}
+	}
+
+	result := Result{Total: rv.Len()}
+	if result.Total == 0 {
+		return result
+	}
+	opt.BatchSize = limitBatch(db, opt.BatchSize, columnCount(db, rv))
+
+	tx := db.Session(&gorm.Session{SkipHooks: opt.SkipHooks})
+	for offset := 0; offset < result.Total; offset += opt.BatchSize {
+		end := min(offset+opt.BatchSize, result.Total)
+		result.Batches++
+		res := exec(tx, rv.Slice(offset, end).Interface())
+		if res.Error != nil {
+			result.Errors = append(result.Errors, BatchError{Batch: result.Batches, Offset: offset, Count: end - offset, Err: res.Error})
+			if opt.StopOnError {
+				break
+			}
+			continue
+		}
+		result.RowsAffected += res.RowsAffected
+	}
+	return result
+}
+
+// limitBatch 根据数据库的占位符上限限制每批条数
+func limitBatch(db *gorm.DB, batchSize, columns int) int {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	limit := mysqlMaxPlaceholders
+	if db.Dialector != nil && db.Dialector.Name() == "sqlite" {
+		limit = sqliteMaxPlaceholders
+	}
+	if columns > 0 && batchSize*columns > limit {
+		batchSize = max(limit/columns, 1)
+	}
+	return batchSize
+}
+
+// columnCount 获取切片元素对应模型的列数
+func columnCount(db *gorm.DB, rv reflect.Value) int {
+	t := rv.Type().Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return 0
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(reflect.New(t).Interface()); err != nil {
+		return 0
+	}
+	return len(stmt.Schema.DBNames)
+}
+
+// toValues 将切片转换为 []any
+func toValues(batch any) []any {
+	rv := reflect.ValueOf(batch)
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values
+}
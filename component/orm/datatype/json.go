@@ -0,0 +1,212 @@
+// Package datatype 常用的自定义字段类型，实现 sql.Scanner 与 driver.Valuer，可直接用于模型字段
+package datatype
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"strings"
+)
+
+// JSONField 以JSON格式存储的字段，MySQL下为 JSON 类型，SQLite下为 TEXT 类型
+// 输出JSON时与 T 本身一致，不会多出一层嵌套
+//
+// 示例:
+//
+//	type Goods struct {
+//		jcbaseGo.MysqlBaseModel
+//		Id    uint                               `gorm:"column:id;primaryKey" json:"id"`
+//		Specs datatype.JSONField[[]Spec]         `gorm:"column:specs" json:"specs"`
+//		Extra datatype.JSONField[map[string]any] `gorm:"column:extra" json:"extra"`
+//	}
+//
+//	goods.Specs = datatype.NewJSONField([]Spec{{Name: "颜色", Value: "红"}})
+//	for _, spec := range goods.Specs.Data { ... }
+type JSONField[T any] struct {
+	Data T
+}
+
+// NewJSONField 创建JSON字段
+func NewJSONField[T any](data T) JSONField[T] {
+	return JSONField[T]{Data: data}
+}
+
+// Get 获取数据
+func (j JSONField[T]) Get() T {
+	return j.Data
+}
+
+// Set 设置数据
+func (j *JSONField[T]) Set(data T) {
+	j.Data = data
+}
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (j JSONField[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.Data)
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (j *JSONField[T]) UnmarshalJSON(data []byte) error {
+	var v T
+	if len(bytes.TrimSpace(data)) > 0 && string(bytes.TrimSpace(data)) != "null" {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+	}
+	j.Data = v
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口，零值的map、切片、指针保存为NULL
+func (j JSONField[T]) Value() (driver.Value, error) {
+	data, err := json.Marshal(j.Data)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (j *JSONField[T]) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为JSONField", value)
+	}
+	return j.UnmarshalJSON(data)
+}
+
+// GormDataType 自动迁移时使用的字段类型
+func (JSONField[T]) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType 按数据库返回字段类型
+func (JSONField[T]) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return "JSON"
+	default:
+		return "TEXT"
+	}
+}
+
+// JSONQueryExpression JSON字段的查询条件，按数据库生成 MySQL 的 JSON_* 函数或 SQLite 的 json_* 函数
+//
+// 示例:
+//
+//	db.Where(datatype.JSONQuery("extra").Equals("vip", "level")).Find(&users)
+//	db.Where(datatype.JSONQuery("extra").HasKey("address.city")).Find(&users)
+//	db.Where(datatype.JSONQuery("tags").Contains("new")).Find(&goods)
+type JSONQueryExpression struct {
+	column string
+	op     string
+	path   string
+	value  any
+}
+
+// JSONQuery 创建JSON字段的查询条件
+func JSONQuery(column string) *JSONQueryExpression {
+	return &JSONQueryExpression{column: column}
+}
+
+// Equals 路径上的值等于value，路径如 a.b、tags[0]，可省略开头的 $.
+func (q *JSONQueryExpression) Equals(value any, path string) *JSONQueryExpression {
+	q.op, q.value, q.path = "equals", value, jsonPath(path)
+	return q
+}
+
+// HasKey 存在路径
+func (q *JSONQueryExpression) HasKey(path string) *JSONQueryExpression {
+	q.op, q.path = "has_key", jsonPath(path)
+	return q
+}
+
+// Contains 路径上的数组包含value（标量），路径为空时为字段本身
+func (q *JSONQueryExpression) Contains(value any, path ...string) *JSONQueryExpression {
+	q.op, q.value, q.path = "contains", value, "$"
+	if len(path) > 0 {
+		q.path = jsonPath(path[0])
+	}
+	return q
+}
+
+// Build 实现 clause.Expression 接口
+func (q *JSONQueryExpression) Build(builder clause.Builder) {
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok {
+		return
+	}
+	mysql := stmt.Dialector.Name() == "mysql"
+	column := clause.Column{Name: q.column}
+
+	switch q.op {
+	case "equals":
+		builder.WriteString(map[bool]string{true: "JSON_EXTRACT(", false: "json_extract("}[mysql])
+		builder.WriteQuoted(column)
+		builder.WriteString(", ")
+		builder.AddVar(stmt, q.path)
+		builder.WriteString(") = ")
+		builder.AddVar(stmt, q.value)
+	case "has_key":
+		if mysql {
+			builder.WriteString("JSON_CONTAINS_PATH(")
+			builder.WriteQuoted(column)
+			builder.WriteString(", 'one', ")
+			builder.AddVar(stmt, q.path)
+			builder.WriteString(")")
+		} else {
+			builder.WriteString("json_type(")
+			builder.WriteQuoted(column)
+			builder.WriteString(", ")
+			builder.AddVar(stmt, q.path)
+			builder.WriteString(") IS NOT NULL")
+		}
+	case "contains":
+		if mysql {
+			value, _ := json.Marshal(q.value)
+			builder.WriteString("JSON_CONTAINS(")
+			builder.WriteQuoted(column)
+			builder.WriteString(", ")
+			builder.AddVar(stmt, string(value))
+			builder.WriteString(", ")
+			builder.AddVar(stmt, q.path)
+			builder.WriteString(")")
+		} else {
+			builder.WriteString("EXISTS (SELECT 1 FROM json_each(")
+			builder.WriteQuoted(column)
+			builder.WriteString(", ")
+			builder.AddVar(stmt, q.path)
+			builder.WriteString(") WHERE value = ")
+			builder.AddVar(stmt, q.value)
+			builder.WriteString(")")
+		}
+	}
+}
+
+// jsonPath 补全路径开头的 $
+func jsonPath(path string) string {
+	if path == "" || path == "$" {
+		return "$"
+	}
+	if strings.HasPrefix(path, "$") {
+		return path
+	}
+	if strings.HasPrefix(path, "[") {
+		return "$" + path
+	}
+	return "$." + path
+}
@@ -5,10 +5,16 @@ import (
 	"fmt"
 	"github.com/jcbowen/jcbaseGo"
 	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/logger"
+	"github.com/jcbowen/jcbaseGo/component/orm/audit"
+	"github.com/jcbowen/jcbaseGo/component/orm/bulk"
+	"github.com/jcbowen/jcbaseGo/component/orm/query"
+	"github.com/jcbowen/jcbaseGo/component/orm/sqlstat"
+	"github.com/jcbowen/jcbaseGo/component/orm/stmtcache"
+	"github.com/jcbowen/jcbaseGo/component/orm/timestamp"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
-	"log"
 	"os"
 )
 
@@ -22,6 +28,11 @@ type Instance struct {
 	Db     *gorm.DB
 	debug  bool // 是否开启debug
 	Errors []error
+
+	sqlStat   *sqlstat.Collector // SQL执行统计
+	auditor   *audit.Recorder    // 数据变更记录
+	stmtCache *stmtcache.Cache   // 预处理语句缓存，开启 PrepareStmt 时有效
+	reconnect *reconnector       // 断线重连
 }
 
 // GetDSN 拼接DataSourceName
@@ -37,7 +48,7 @@ func getDSN(dbConfig jcbaseGo.DbStruct) (dsn string) {
 func New(dbConfig jcbaseGo.DbStruct) *Instance {
 	context := &Instance{}
 
-	err := helper.CheckAndSetDefault(&dbConfig)
+	err := helper.CheckValidateAndSetDefault(&dbConfig)
 	jcbaseGo.PanicIfError(err)
 
 	// 判断dbConfig是否为空
@@ -52,9 +63,18 @@ func New(dbConfig jcbaseGo.DbStruct) *Instance {
 			TablePrefix:   dbConfig.TablePrefix,   // 表名前缀，`User`表为`t_users`
 			SingularTable: dbConfig.SingularTable, // 使用单数表名，启用该选项后，`User` 表将是`user`
 		},
+		PrepareStmt: dbConfig.PrepareStmt,
 	})
 	jcbaseGo.PanicIfError(err)
 
+	// 字符串类型的新增、更新时间字段由插件填充
+	jcbaseGo.PanicIfError(db.Use(timestamp.New()))
+
+	if dbConfig.PrepareStmt {
+		context.stmtCache = stmtcache.New(stmtcache.Options{MaxSize: dbConfig.PrepareStmtMaxSize})
+		jcbaseGo.PanicIfError(db.Use(context.stmtCache))
+	}
+
 	context.Dsn = dsn
 	context.Conf = dbConfig
 	context.Db = db
@@ -77,7 +97,7 @@ func (c *Instance) Debug() *Instance {
 // GetDb 获取db
 func (c *Instance) GetDb() *gorm.DB {
 	if c.Db == nil {
-		log.Println("Database connection is nil")
+		logger.Error("database connection is nil", "driver", "mysql", "alias", c.Conf.Alias)
 		return nil
 	}
 	db := c.Db
@@ -87,6 +107,162 @@ func (c *Instance) GetDb() *gorm.DB {
 	return db
 }
 
+// EnableSQLStats 开启SQL执行统计，按归一化语句聚合执行次数、耗时及行数
+//
+// 示例:
+//
+//	db := mysql.New(conf).EnableSQLStats(sqlstat.Options{SlowThreshold: 100 * time.Millisecond})
+//	report := db.GetSlowQueryReport(20)
+func (c *Instance) EnableSQLStats(opts ...sqlstat.Options) *Instance {
+	if c.Db == nil || c.sqlStat != nil {
+		return c
+	}
+	collector := sqlstat.New(opts...)
+	if err := c.Db.Use(collector); err != nil {
+		c.AddError(err)
+		return c
+	}
+	c.sqlStat = collector
+	return c
+}
+
+// GetSlowQueryReport 获取SQL执行统计报告，按总耗时倒序排列，未开启统计时返回nil
+//
+// 参数:
+//   - limit (可选): 最多返回的条数，默认全部返回
+func (c *Instance) GetSlowQueryReport(limit ...int) []sqlstat.Stat {
+	if c.sqlStat == nil {
+		return nil
+	}
+	return c.sqlStat.Report(limit...)
+}
+
+// ResetSQLStats 清空SQL执行统计数据
+func (c *Instance) ResetSQLStats() {
+	if c.sqlStat != nil {
+		c.sqlStat.Reset()
+	}
+}
+
+// EnableAudit 开启数据变更记录，实现了 audit.Auditable 或在 Models 中指定的模型，新增、更新、删除时记录前后数据及操作人
+//
+// 示例:
+//
+//	db := mysql.New(conf).EnableAudit(audit.Options{AutoMigrate: true})
+//	logs, err := db.GetAuditor().History(ctx, &User{}, 10)
+func (c *Instance) EnableAudit(opts ...audit.Options) *Instance {
+	if c.Db == nil || c.auditor != nil {
+		return c
+	}
+	recorder := audit.New(opts...)
+	if err := c.Db.Use(recorder); err != nil {
+		c.AddError(err)
+		return c
+	}
+	c.auditor = recorder
+	return c
+}
+
+// GetAuditor 获取数据变更记录插件，用于查询变更记录，未开启时返回nil
+func (c *Instance) GetAuditor() *audit.Recorder {
+	return c.auditor
+}
+
+// GetPrepareStmtStats 获取预处理语句缓存的统计信息（缓存语句数、命中率等），未开启 PrepareStmt 时返回零值
+func (c *Instance) GetPrepareStmtStats() stmtcache.Stats {
+	if c.stmtCache == nil {
+		return stmtcache.Stats{}
+	}
+	return c.stmtCache.Stats()
+}
+
+// ResetPreparedStmts 清空缓存的预处理语句，如数据库执行表结构变更后
+func (c *Instance) ResetPreparedStmts() {
+	if c.stmtCache != nil {
+		c.stmtCache.Reset()
+	}
+}
+
+// EnableReconnect 开启断线重连：定时检查连接，断开后按指数退避（含随机浮动）重连，
+// 断开期间执行的语句直接返回 ErrUnavailable，连接恢复后调用 OnReconnect
+//
+// 示例:
+//
+//	db := mysql.New(conf).EnableReconnect(mysql.ReconnectOptions{
+//		OnReconnect: func(attempts int, downtime time.Duration) {
+//			log.Printf("数据库已恢复，重连%d次，断开%s", attempts, downtime)
+//		},
+//	})
+func (c *Instance) EnableReconnect(opts ...ReconnectOptions) *Instance {
+	if c.Db == nil || c.reconnect != nil {
+		return c
+	}
+	r := newReconnector(opts...)
+	if err := c.Db.Use(r); err != nil {
+		c.AddError(err)
+		return c
+	}
+	c.reconnect = r
+	return c
+}
+
+// GetConnStatus 获取连接状态，未开启断线重连时返回零值
+func (c *Instance) GetConnStatus() ReconnectStatus {
+	if c.reconnect == nil {
+		return ReconnectStatus{}
+	}
+	return c.reconnect.Status()
+}
+
+// StopReconnect 停止健康检查及断线重连，如服务退出时
+func (c *Instance) StopReconnect() {
+	if c.reconnect != nil {
+		c.reconnect.Stop()
+	}
+}
+
+// BulkInsert 分批插入，每批生成一条多行 INSERT 语句，batchSize 小于等于0时为1000
+// 各批次独立执行，失败的批次记录在返回结果的 Errors 中；需要跳过钩子等更多配置时使用 bulk.Insert
+//
+// 示例:
+//
+//	res := db.BulkInsert(users, 2000)
+//	if err := res.Err(); err != nil {
+//		log.Println(res.Errors)
+//	}
+func (c *Instance) BulkInsert(models any, batchSize int) bulk.Result {
+	return bulk.Insert(c.GetDb(), models, bulk.Options{BatchSize: batchSize})
+}
+
+// BulkUpsert 分批插入，唯一键冲突时更新 updateColumns 指定的列，updateColumns 为空时更新除冲突列外的全部列
+func (c *Instance) BulkUpsert(models any, conflictColumns, updateColumns []string, batchSize int) bulk.Result {
+	return bulk.Upsert(c.GetDb(), models, conflictColumns, updateColumns, bulk.Options{BatchSize: batchSize})
+}
+
+// BulkDelete 按主键分批删除，ids 为主键切片
+//
+// 示例:
+//
+//	res := db.BulkDelete(&User{}, []uint{1, 2, 3}, 1000)
+func (c *Instance) BulkDelete(model any, ids any, batchSize int) bulk.Result {
+	return bulk.Delete(c.GetDb(), model, ids, bulk.Options{BatchSize: batchSize})
+}
+
+// Query 创建原生SQL构建器，支持 :name 命名参数及切片参数自动展开，用于统计、报表类查询
+//
+// 示例:
+//
+//	rows, err := db.Query().
+//		Select("DATE(created_at) AS day, COUNT(*) AS total").
+//		From("order").
+//		NamedWhere("created_at BETWEEN :start_date AND :end_date", query.Params{"start_date": start, "end_date": end}).
+//		Where("status IN ?", []int{1, 2}).
+//		GroupBy("day").
+//		Maps()
+func (c *Instance) Query() *query.Builder {
+	return query.New(c.GetDb())
+}
+
 // GetAllTableName 获取所有表名
 func (c *Instance) GetAllTableName() (tableNames []AllTableName, err error) {
 	// 如果有错误，就不再执行
@@ -0,0 +1,281 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/logger"
+	"gorm.io/gorm"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable 数据库连接断开、等待重连期间执行的语句直接返回该错误
+var ErrUnavailable = errors.New("mysql: 数据库连接不可用，正在重连")
+
+// 连接状态，断开期间（open、half_open）执行的语句直接失败，不再占用连接池等待超时
+const (
+	ConnClosed   = "closed"    // 连接正常
+	ConnOpen     = "open"      // 连接断开，等待下一次重连
+	ConnHalfOpen = "half_open" // 正在尝试重连
+)
+
+// ReconnectOptions 断线重连配置
+type ReconnectOptions struct {
+	CheckInterval  time.Duration                              // 健康检查间隔，默认10秒；语句返回连接类错误时立即检查
+	AttemptTimeout time.Duration                              // 每次检查、重连的超时时间，默认3秒
+	BackoffMin     time.Duration                              // 首次重连前的等待时间，默认1秒，之后每次翻倍
+	BackoffMax     time.Duration                              // 重连等待时间上限，默认1分钟
+	Jitter         float64                                    // 等待时间的随机浮动比例，0~1，默认0.2，避免多个实例同时重连
+	OnDisconnect   func(err error)                            // 检测到连接断开时的回调
+	OnReconnect    func(attempts int, downtime time.Duration) // 恢复连接后的回调，参数为重连次数及断开时长
+}
+
+// ReconnectStatus 连接状态
+type ReconnectStatus struct {
+	State      string    `json:"state"`      // 连接状态，closed、open、half_open
+	Attempts   int       `json:"attempts"`   // 本次断开后已尝试重连的次数
+	DownSince  time.Time `json:"down_since"` // 本次断开的时间，连接正常时为零值
+	LastError  string    `json:"last_error"` // 最近一次检查或重连失败的错误
+	Reconnects int64     `json:"reconnects"` // 累计恢复连接的次数
+}
+
+// reconnector 断线重连插件：定时检查连接，断开后按指数退避重连，断开期间语句直接失败
+type reconnector struct {
+	db      *gorm.DB
+	opt     ReconnectOptions
+	trigger chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	status ReconnectStatus
+}
+
+func newReconnector(opts ...ReconnectOptions) *reconnector {
+	var opt ReconnectOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.CheckInterval <= 0 {
+		opt.CheckInterval = 10 * time.Second
+	}
+	if opt.AttemptTimeout <= 0 {
+		opt.AttemptTimeout = 3 * time.Second
+	}
+	if opt.BackoffMin <= 0 {
+		opt.BackoffMin = time.Second
+	}
+	if opt.BackoffMax < opt.BackoffMin {
+		opt.BackoffMax = max(time.Minute, opt.BackoffMin)
+	}
+	if opt.Jitter <= 0 || opt.Jitter > 1 {
+		opt.Jitter = 0.2
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &reconnector{
+		opt:     opt,
+		trigger: make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		status:  ReconnectStatus{State: ConnClosed},
+	}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (r *reconnector) Name() string {
+	return "jcbaseGo:reconnect"
+}
+
+// Initialize 实现 gorm.Plugin 接口，语句执行前检查连接状态，执行后检查是否为连接类错误
+func (r *reconnector) Initialize(db *gorm.DB) error {
+	r.db = db
+	callback := db.Callback()
+	if err := callback.Create().Before("gorm:create").Register("reconnect:before_create", r.before); err != nil {
+		return err
+	}
+	if err := callback.Create().After("gorm:create").Register("reconnect:after_create", r.after); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("reconnect:before_query", r.before); err != nil {
+		return err
+	}
+	if err := callback.Query().After("gorm:query").Register("reconnect:after_query", r.after); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("reconnect:before_update", r.before); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("reconnect:after_update", r.after); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("reconnect:before_delete", r.before); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:delete").Register("reconnect:after_delete", r.after); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("reconnect:before_row", r.before); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("reconnect:after_row", r.after); err != nil {
+		return err
+	}
+	if err := callback.Raw().Before("gorm:raw").Register("reconnect:before_raw", r.before); err != nil {
+		return err
+	}
+	if err := callback.Raw().After("gorm:raw").Register("reconnect:after_raw", r.after); err != nil {
+		return err
+	}
+	go r.loop()
+	return nil
+}
+
+// Status 获取连接状态
+func (r *reconnector) Status() ReconnectStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
+
+// Stop 停止健康检查及重连
+func (r *reconnector) Stop() {
+	r.cancel()
+	<-r.done
+}
+
+// before 连接断开期间直接失败
+func (r *reconnector) before(db *gorm.DB) {
+	if db.DryRun || db.Error != nil {
+		return
+	}
+	if r.Status().State != ConnClosed {
+		_ = db.AddError(ErrUnavailable)
+	}
+}
+
+// after 语句返回连接类错误时立即检查连接
+func (r *reconnector) after(db *gorm.DB) {
+	if db.Error == nil || errors.Is(db.Error, ErrUnavailable) || !isConnError(db.Error) {
+		return
+	}
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// loop 定时或收到通知时检查连接，断开后进入重连
+func (r *reconnector) loop() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.opt.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.trigger:
+		}
+		if err := r.ping(); err != nil && r.ctx.Err() == nil {
+			r.reconnect(err)
+		}
+	}
+}
+
+// reconnect 按指数退避重连，直到连接恢复或停止
+func (r *reconnector) reconnect(cause error) {
+	since := time.Now()
+	r.update(func(st *ReconnectStatus) {
+		st.State, st.Attempts, st.DownSince, st.LastError = ConnOpen, 0, since, cause.Error()
+	})
+	logger.Error("数据库连接断开", "driver", "mysql", "error", cause)
+	if r.opt.OnDisconnect != nil {
+		r.opt.OnDisconnect(cause)
+	}
+
+	backoff := r.opt.BackoffMin
+	for attempts := 1; ; attempts++ {
+		timer := time.NewTimer(r.jitter(backoff))
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		r.update(func(st *ReconnectStatus) {
+			st.State, st.Attempts = ConnHalfOpen, attempts
+		})
+		if err := r.ping(); err != nil {
+			r.update(func(st *ReconnectStatus) {
+				st.State, st.LastError = ConnOpen, err.Error()
+			})
+			logger.Warn("数据库重连失败", "driver", "mysql", "attempts", attempts, "error", err)
+			backoff = min(backoff*2, r.opt.BackoffMax)
+			continue
+		}
+
+		downtime := time.Since(since)
+		r.update(func(st *ReconnectStatus) {
+			st.State, st.Attempts, st.DownSince, st.LastError = ConnClosed, 0, time.Time{}, ""
+			st.Reconnects++
+		})
+		// 丢弃断开时正在执行的语句发出的检查通知
+		select {
+		case <-r.trigger:
+		default:
+		}
+		logger.Info("数据库连接已恢复", "driver", "mysql", "attempts", attempts, "downtime", downtime.String())
+		if r.opt.OnReconnect != nil {
+			r.opt.OnReconnect(attempts, downtime)
+		}
+		return
+	}
+}
+
+// ping 在超时时间内检查连接，连接池中的失效连接由 database/sql 丢弃并重新建立
+func (r *reconnector) ping() error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(r.ctx, r.opt.AttemptTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// jitter 在等待时间上增加随机浮动
+func (r *reconnector) jitter(d time.Duration) time.Duration {
+	delta := float64(d) * r.opt.Jitter
+	return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+}
+
+func (r *reconnector) update(fn func(st *ReconnectStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.status)
+}
+
+// isConnError 是否为连接断开、网络类错误
+func isConnError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	// 查询超时或被取消不代表连接断开，且 context.DeadlineExceeded 同样实现了 net.Error
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "invalid connection") || strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "broken pipe") || strings.Contains(msg, "server has gone away")
+}
@@ -0,0 +1,26 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestIsConnError(t *testing.T) {
+	cases := map[error]bool{
+		driver.ErrBadConn: true,
+		&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}: true,
+		errors.New("invalid connection"):                                      true,
+		fmt.Errorf("query: %w", context.DeadlineExceeded):                     false,
+		context.Canceled: false,
+		errors.New("Error 1062: Duplicate entry"): false,
+	}
+	for err, want := range cases {
+		if got := isConnError(err); got != want {
+			t.Errorf("isConnError(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
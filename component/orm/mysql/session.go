@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/logger"
+	"github.com/jcbowen/jcbaseGo/middleware"
+	"gorm.io/gorm"
+)
+
+// sessionKey 请求级数据库会话在gin上下文中的键名，按连接别名区分
+func (c *Instance) sessionKey() string {
+	return "jcbaseGo:mysql:session:" + c.Conf.Alias
+}
+
+// Session 获取请求级数据库会话：使用请求的 context（请求取消时中止查询，并传递 audit 等组件写入的信息），
+// 日志为 logger.GormLogger 时绑定请求ID，SQL日志可按 request_id 与访问日志关联
+// 同一请求内多次调用返回同一个会话，需先注册 middleware.Base{}.RequestID() 生成请求ID
+//
+// 示例:
+//
+//	db.Db.Logger = logger.Default().Gorm()
+//	r.Use(middleware.Base{}.RequestID(), db.SessionMiddleware())
+//	r.GET("/user/:id", func(c *gin.Context) {
+//		var user User
+//		err := db.Session(c).First(&user, c.Param("id")).Error
+//	})
+func (c *Instance) Session(ctx *gin.Context) *gorm.DB {
+	if v, ok := ctx.Get(c.sessionKey()); ok {
+		if session, ok := v.(*gorm.DB); ok {
+			return session
+		}
+	}
+	db := c.GetDb()
+	if db == nil {
+		return nil
+	}
+	session := db.WithContext(ctx.Request.Context())
+	if requestID := middleware.GetRequestID(ctx); requestID != "" {
+		if gormLogger, ok := session.Logger.(*logger.GormLogger); ok {
+			session = session.Session(&gorm.Session{Logger: gormLogger.With("request_id", requestID)})
+		}
+	}
+	ctx.Set(c.sessionKey(), session)
+	return session
+}
+
+// SessionMiddleware 在请求开始时创建请求级数据库会话，之后的中间件、控制器通过 Session 获取；
+// 需注册在 RequestID 及 audit.Middleware 等修改请求 context 的中间件之后
+func (c *Instance) SessionMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c.Session(ctx)
+		ctx.Next()
+	}
+}
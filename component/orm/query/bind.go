@@ -0,0 +1,99 @@
+package query
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// bind 将语句中的 ? 及 :name 参数展开为 ? 占位符，返回展开后的语句及按顺序排列的参数
+// 切片参数展开为 (?, ?, ?)，空切片展开为 (NULL)；引号内的内容及 :: 不做处理
+func bind(sql string, args []any, named map[string]any) (string, []any, error) {
+	var (
+		b      strings.Builder
+		values = make([]any, 0, len(args)+len(named))
+		quote  rune
+		next   int
+	)
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if quote != 0 {
+			b.WriteRune(r)
+			if r == '\\' && i+1 < len(runes) {
+				i++
+				b.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			b.WriteRune(r)
+		case r == '?':
+			if next >= len(args) {
+				return "", nil, fmt.Errorf("query: 参数数量不足: %s", sql)
+			}
+			values = appendValue(&b, values, args[next])
+			next++
+		case r == ':' && i+1 < len(runes) && runes[i+1] == ':':
+			// PostgreSQL类型转换等场景的 ::，原样输出
+			b.WriteString("::")
+			i++
+		case r == ':' && i+1 < len(runes) && isNameStart(runes[i+1]) && (i == 0 || !isNamePart(runes[i-1])):
+			j := i + 1
+			for j < len(runes) && isNamePart(runes[j]) {
+				j++
+			}
+			name := string(runes[i+1 : j])
+			value, ok := named[name]
+			if !ok {
+				return "", nil, fmt.Errorf("query: 缺少参数 :%s", name)
+			}
+			values = appendValue(&b, values, value)
+			i = j - 1
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if next < len(args) {
+		return "", nil, fmt.Errorf("query: 参数数量过多: %s", sql)
+	}
+	return b.String(), values, nil
+}
+
+// appendValue 写入占位符并追加参数，切片展开为多个占位符
+func appendValue(b *strings.Builder, values []any, value any) []any {
+	if _, ok := value.(driver.Valuer); !ok {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 || rv.Kind() == reflect.Array {
+			if rv.Len() == 0 {
+				b.WriteString("(NULL)")
+				return values
+			}
+			b.WriteByte('(')
+			for i := 0; i < rv.Len(); i++ {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteByte('?')
+				values = append(values, rv.Index(i).Interface())
+			}
+			b.WriteByte(')')
+			return values
+		}
+	}
+	b.WriteByte('?')
+	return append(values, value)
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isNamePart(r rune) bool {
+	return isNameStart(r) || r >= '0' && r <= '9'
+}
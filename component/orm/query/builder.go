@@ -0,0 +1,290 @@
+// Package query 轻量的原生SQL构建器，用于不适合模型查询的统计、报表类语句
+//
+// 条件中可以使用 ? 或 :name 形式的参数，切片参数自动展开为 IN 列表，参数始终以占位符传递，不会拼接到语句中。
+package query
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// Params 命名参数
+type Params = map[string]any
+
+// part 带参数的语句片段
+type part struct {
+	sql   string
+	args  []any
+	named Params
+}
+
+// Builder 原生SQL构建器，非并发安全，每条语句使用新的 Builder
+//
+// 示例:
+//
+//	rows := make([]Report, 0)
+//	err := query.New(db).
+//		Select("u.id, u.name, COUNT(o.id) AS orders, SUM(o.amount) AS amount").
+//		From("user u").
+//		Join("LEFT JOIN `order` o ON o.user_id = u.id AND o.status IN :status", query.Params{"status": []int{1, 2}}).
+//		NamedWhere("o.created_at BETWEEN :start_date AND :end_date", query.Params{"start_date": start, "end_date": end}).
+//		Where("u.group_id = ?", groupID).
+//		GroupBy("u.id, u.name").
+//		Having("SUM(o.amount) > ?", 0).
+//		OrderBy("amount DESC").
+//		Limit(20).
+//		Scan(&rows)
+type Builder struct {
+	db      *gorm.DB
+	selects string
+	from    part
+	joins   []part
+	wheres  []part
+	groupBy string
+	havings []part
+	orderBy []string
+	limit   int
+	offset  int
+	total   TotalOptions
+}
+
+// New 创建构建器
+func New(db *gorm.DB) *Builder {
+	return &Builder{db: db, selects: "*"}
+}
+
+// Select 设置查询的列
+func (b *Builder) Select(columns string) *Builder {
+	b.selects = columns
+	return b
+}
+
+// From 设置查询的表，可以是子查询，args 可以是 ? 对应的参数或一个 Params
+func (b *Builder) From(table string, args ...any) *Builder {
+	b.from = newPart(table, args)
+	return b
+}
+
+// Join 添加连接，需写完整的连接语句，如 LEFT JOIN order o ON o.user_id = u.id
+func (b *Builder) Join(join string, args ...any) *Builder {
+	b.joins = append(b.joins, newPart(join, args))
+	return b
+}
+
+// Where 添加 AND 条件，使用 ? 占位符，切片参数展开为 (?, ?, ?)，如 Where("id IN ?", ids)
+func (b *Builder) Where(condition string, args ...any) *Builder {
+	b.wheres = append(b.wheres, part{sql: condition, args: args})
+	return b
+}
+
+// NamedWhere 添加 AND 条件，使用 :name 形式的命名参数
+func (b *Builder) NamedWhere(condition string, params Params) *Builder {
+	b.wheres = append(b.wheres, part{sql: condition, named: params})
+	return b
+}
+
+// WhereIf 条件成立时添加 AND 条件，用于可选的筛选项
+func (b *Builder) WhereIf(ok bool, condition string, args ...any) *Builder {
+	if ok {
+		b.Where(condition, args...)
+	}
+	return b
+}
+
+// GroupBy 设置分组
+func (b *Builder) GroupBy(columns string) *Builder {
+	b.groupBy = columns
+	return b
+}
+
+// Having 添加分组后的 AND 条件，args 可以是 ? 对应的参数或一个 Params
+func (b *Builder) Having(condition string, args ...any) *Builder {
+	b.havings = append(b.havings, newPart(condition, args))
+	return b
+}
+
+// OrderBy 添加排序，如 OrderBy("amount DESC")，排序内容不能来自用户输入
+func (b *Builder) OrderBy(order string) *Builder {
+	b.orderBy = append(b.orderBy, order)
+	return b
+}
+
+// Limit 设置返回条数，小于等于0表示不限制
+func (b *Builder) Limit(limit int) *Builder {
+	b.limit = limit
+	return b
+}
+
+// Offset 设置跳过的条数
+func (b *Builder) Offset(offset int) *Builder {
+	b.offset = offset
+	return b
+}
+
+// ToSQL 生成语句及参数
+func (b *Builder) ToSQL() (string, []any, error) {
+	return b.build(false)
+}
+
+// Scan 执行查询，结果写入结构体切片、结构体或 []map[string]any
+func (b *Builder) Scan(dest any) error {
+	sql, args, err := b.build(false)
+	if err != nil {
+		return err
+	}
+	return b.db.Raw(sql, args...).Scan(dest).Error
+}
+
+// Maps 执行查询并以map形式返回结果
+func (b *Builder) Maps() ([]map[string]any, error) {
+	rows := make([]map[string]any, 0)
+	err := b.Scan(&rows)
+	return rows, err
+}
+
+// Count 统计结果条数，忽略排序及分页；有分组时统计分组数
+func (b *Builder) Count() (int64, error) {
+	sql, args, err := b.build(true)
+	if err != nil {
+		return 0, err
+	}
+	return exactTotal(b.db, sql, args)
+}
+
+// WithTotal 设置 Page 统计总数的方式，默认精确统计
+//
+// 示例:
+//
+//	listData, err := query.New(db).Select("*").From("`log`").Where("level = ?", level).
+//		WithTotal(query.TotalOptions{Mode: query.TotalEstimate}).
+//		Page(page, pageSize, &list)
+func (b *Builder) WithTotal(opt TotalOptions) *Builder {
+	b.total = opt
+	return b
+}
+
+// Page 分页查询，page 从1开始，pageSize 默认10，最大1000，总数按 WithTotal 的设置统计
+//
+// 示例:
+//
+//	list := make([]Report, 0)
+//	listData, err := query.New(db).Select("...").From("...").Page(page, pageSize, &list)
+func (b *Builder) Page(page, pageSize int, dest any) (jcbaseGo.ListData, error) {
+	page = max(page, 1)
+	if pageSize < 1 {
+		pageSize = 10
+	} else if pageSize > 1000 {
+		pageSize = 1000
+	}
+	listData := jcbaseGo.ListData{Page: page, PageSize: pageSize, List: dest}
+	sql, args, err := b.build(true)
+	if err != nil {
+		return listData, err
+	}
+	total, err := Total(b.db, sql, args, b.total)
+	if err != nil {
+		return listData, err
+	}
+	listData.Total = int(total)
+
+	limit, offset := b.limit, b.offset
+	b.limit, b.offset = pageSize, (page-1)*pageSize
+	defer func() { b.limit, b.offset = limit, offset }()
+	return listData, b.Scan(dest)
+}
+
+// build 拼接语句，forCount 为 true 时不输出排序及分页
+func (b *Builder) build(forCount bool) (string, []any, error) {
+	if b.from.sql == "" {
+		return "", nil, errors.New("query: 未设置查询的表")
+	}
+	var (
+		sql  strings.Builder
+		args = make([]any, 0)
+	)
+	write := func(prefix string, p part) error {
+		s, values, err := bind(p.sql, p.args, p.named)
+		if err != nil {
+			return err
+		}
+		sql.WriteString(prefix)
+		sql.WriteString(s)
+		args = append(args, values...)
+		return nil
+	}
+
+	sql.WriteString("SELECT " + b.selects)
+	if err := write(" FROM ", b.from); err != nil {
+		return "", nil, err
+	}
+	for _, join := range b.joins {
+		if err := write(" ", join); err != nil {
+			return "", nil, err
+		}
+	}
+	for i, where := range b.wheres {
+		prefix := " AND "
+		if i == 0 {
+			prefix = " WHERE "
+		}
+		if err := write(prefix, part{sql: "(" + where.sql + ")", args: where.args, named: where.named}); err != nil {
+			return "", nil, err
+		}
+	}
+	if b.groupBy != "" {
+		sql.WriteString(" GROUP BY " + b.groupBy)
+	}
+	for i, having := range b.havings {
+		prefix := " AND "
+		if i == 0 {
+			prefix = " HAVING "
+		}
+		if err := write(prefix, part{sql: "(" + having.sql + ")", args: having.args, named: having.named}); err != nil {
+			return "", nil, err
+		}
+	}
+	if forCount {
+		return sql.String(), args, nil
+	}
+	if len(b.orderBy) > 0 {
+		sql.WriteString(" ORDER BY " + strings.Join(b.orderBy, ", "))
+	}
+	if b.limit > 0 {
+		sql.WriteString(fmt.Sprintf(" LIMIT %d", b.limit))
+		if b.offset > 0 {
+			sql.WriteString(fmt.Sprintf(" OFFSET %d", b.offset))
+		}
+	}
+	return sql.String(), args, nil
+}
+
+// Raw 执行带命名参数的完整语句
+//
+// 示例:
+//
+//	err := query.Raw(db, "SELECT * FROM user WHERE id IN :ids AND created_at >= :start", query.Params{
+//		"ids":   ids,
+//		"start": start,
+//	}).Scan(&users).Error
+func Raw(db *gorm.DB, sql string, params Params) *gorm.DB {
+	s, args, err := bind(sql, nil, params)
+	if err != nil {
+		tx := db.Session(&gorm.Session{})
+		_ = tx.AddError(err)
+		return tx
+	}
+	return db.Raw(s, args...)
+}
+
+// newPart 参数为单个 Params 时作为命名参数，否则作为 ? 对应的参数
+func newPart(sql string, args []any) part {
+	if len(args) == 1 {
+		if named, ok := args[0].(Params); ok {
+			return part{sql: sql, named: named}
+		}
+	}
+	return part{sql: sql, args: args}
+}
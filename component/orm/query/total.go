@@ -0,0 +1,148 @@
+package query
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/helper/cache"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// TotalMode 分页时总数的统计方式
+type TotalMode int
+
+const (
+	TotalExact    TotalMode = iota // 执行 COUNT(*) 精确统计，默认
+	TotalNone                      // 不统计总数，ListData.Total 为 -1，前端可根据本页条数是否等于 page_size 判断是否有下一页
+	TotalEstimate                  // 使用 EXPLAIN 估算（仅MySQL），估算值小于 ExactBelow 或其他数据库时精确统计
+	TotalCached                    // 精确统计，并按查询语句及参数缓存总数
+)
+
+// TotalCache 总数缓存
+type TotalCache interface {
+	Set(key string, value interface{}, args ...time.Duration) error
+	GetString(key string) (string, error)
+}
+
+// TotalOptions 总数统计配置
+//
+// 大表上的 COUNT(*) 与查询本身耗时相当，不需要精确总数的列表（如日志、流水）可以不统计、估算或缓存总数。
+type TotalOptions struct {
+	Mode       TotalMode
+	ExactBelow int64         // TotalEstimate 时估算值小于该值则精确统计，默认10000
+	Cache      TotalCache    // TotalCached 时使用的缓存，为空时使用进程内缓存
+	TTL        time.Duration // TotalCached 时的缓存时间，默认1分钟
+}
+
+// Total 按配置统计查询的总数，sql 为不含排序及分页的查询语句，TotalNone 时返回-1
+//
+// 示例:
+//
+//	total, err := query.Total(db, "SELECT * FROM `log` WHERE level = ?", []any{"error"}, query.TotalOptions{Mode: query.TotalEstimate})
+func Total(db *gorm.DB, sql string, args []any, opt TotalOptions) (int64, error) {
+	switch opt.Mode {
+	case TotalNone:
+		return -1, nil
+	case TotalEstimate:
+		threshold := opt.ExactBelow
+		if threshold <= 0 {
+			threshold = 10000
+		}
+		if estimated, ok := estimate(db, sql, args); ok && estimated >= threshold {
+			return estimated, nil
+		}
+	case TotalCached:
+		return cachedTotal(db, sql, args, opt)
+	}
+	return exactTotal(db, sql, args)
+}
+
+// exactTotal 精确统计
+func exactTotal(db *gorm.DB, sql string, args []any) (int64, error) {
+	var total int64
+	err := db.Raw("SELECT COUNT(*) FROM ("+sql+") count_query", args...).Scan(&total).Error
+	return total, err
+}
+
+// cachedTotal 读取缓存的总数，未命中时精确统计并写入缓存
+func cachedTotal(db *gorm.DB, sql string, args []any, opt TotalOptions) (int64, error) {
+	store := opt.Cache
+	if store == nil {
+		store = defaultTotalCache
+	}
+	ttl := opt.TTL
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	data, _ := json.Marshal(args)
+	sum := sha1.Sum([]byte(sql + "\n" + string(data)))
+	key := "query:total:" + hex.EncodeToString(sum[:])
+
+	if value, err := store.GetString(key); err == nil && value != "" {
+		if total, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return total, nil
+		}
+	}
+	total, err := exactTotal(db, sql, args)
+	if err != nil {
+		return 0, err
+	}
+	_ = store.Set(key, strconv.FormatInt(total, 10), ttl)
+	return total, nil
+}
+
+// estimate 使用执行计划估算行数，不支持的数据库或估算失败时返回 false
+func estimate(db *gorm.DB, sql string, args []any) (int64, bool) {
+	if db.Dialector.Name() != "mysql" {
+		return 0, false
+	}
+	// 取驱动表的预估扫描行数及条件过滤比例
+	rows, err := explainRows(db, "EXPLAIN "+sql, args)
+	if err != nil || len(rows) == 0 {
+		return 0, false
+	}
+	estimated := helper.Convert{Value: rows[0]["rows"]}.ToFloat64()
+	if filtered, ok := rows[0]["filtered"]; ok && filtered != nil {
+		estimated = estimated * helper.Convert{Value: filtered}.ToFloat64() / 100
+	}
+	return int64(estimated), true
+}
+
+// explainRows 执行 EXPLAIN 并以 map 形式返回结果，文本类型的值转换为字符串
+func explainRows(db *gorm.DB, sql string, args []any) ([]map[string]any, error) {
+	rows, err := db.Raw(sql, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]any, 0)
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// defaultTotalCache 未指定缓存时使用的进程内缓存
+var defaultTotalCache = cache.NewMemory()
@@ -0,0 +1,53 @@
+package query
+
+import (
+	"github.com/jcbowen/jcbaseGo/component/helper/cache"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"testing"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err = db.Exec("CREATE TABLE log (id INTEGER PRIMARY KEY, level TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Exec("INSERT INTO log (level) VALUES ('error'), ('error'), ('info')").Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestTotal(t *testing.T) {
+	db := newTestDB(t)
+	sql, args := "SELECT * FROM log WHERE level = ?", []any{"error"}
+
+	for mode, want := range map[TotalMode]int64{TotalExact: 2, TotalNone: -1, TotalEstimate: 2} {
+		// sqlite 不支持估算，TotalEstimate 回退为精确统计
+		if total, err := Total(db, sql, args, TotalOptions{Mode: mode}); err != nil || total != want {
+			t.Errorf("mode %d: total = %d, %v; want %d", mode, total, err, want)
+		}
+	}
+
+	store := cache.NewMemory()
+	opt := TotalOptions{Mode: TotalCached, Cache: store}
+	if total, err := Total(db, sql, args, opt); err != nil || total != 2 {
+		t.Fatalf("cached: total = %d, %v", total, err)
+	}
+	if err := db.Exec("INSERT INTO log (level) VALUES ('error')").Error; err != nil {
+		t.Fatal(err)
+	}
+	if total, _ := Total(db, sql, args, opt); total != 2 {
+		t.Fatalf("cached total = %d, want the cached value 2", total)
+	}
+	if total, _ := Total(db, sql, args, TotalOptions{}); total != 3 {
+		t.Fatalf("exact total = %d, want 3", total)
+	}
+}
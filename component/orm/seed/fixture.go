@@ -0,0 +1,157 @@
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// fixtureFile 数据文件的完整格式，文件内容也可以直接是记录数组
+type fixtureFile struct {
+	DependsOn []string         `json:"depends_on" yaml:"depends_on"`
+	Records   []map[string]any `json:"records" yaml:"records"`
+}
+
+// File 使用 YAML/JSON 数据文件创建种子，名称为不含扩展名的文件名
+// 记录按JSON字段名转换为模型后逐条写入，因此会执行模型的钩子及默认值
+//
+// 文件内容可以是记录数组，也可以声明依赖：
+//
+//	depends_on: [user]
+//	records:
+//	  - id: 1
+//	    user_id: 1
+//	    amount: 100
+//
+// 示例:
+//
+//	s.Add(seed.File(&Order{}, "./testdata/order.yml", "user"))
+func File(model any, path string, dependsOn ...string) Seed {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return Seed{
+		Name:      name,
+		DependsOn: dependsOn,
+		Model:     model,
+		Run: func(tx *gorm.DB) error {
+			fixture, err := readFixture(path)
+			if err != nil {
+				return err
+			}
+			return insertRecords(tx, model, fixture.Records)
+		},
+	}
+}
+
+// Records 使用内存中的记录创建种子，记录可以是map或结构体
+//
+// 示例:
+//
+//	s.Add(seed.Records("role", &Role{}, []map[string]any{{"id": 1, "name": "管理员"}}))
+func Records[T any](name string, model any, records []T, dependsOn ...string) Seed {
+	return Seed{
+		Name:      name,
+		DependsOn: dependsOn,
+		Model:     model,
+		Run: func(tx *gorm.DB) error {
+			rows := make([]map[string]any, 0, len(records))
+			for _, record := range records {
+				data, err := json.Marshal(record)
+				if err != nil {
+					return err
+				}
+				var row map[string]any
+				if err = json.Unmarshal(data, &row); err != nil {
+					return err
+				}
+				rows = append(rows, row)
+			}
+			return insertRecords(tx, model, rows)
+		},
+	}
+}
+
+// LoadDir 加载目录下的 .yml/.yaml/.json 数据文件，models 为文件名（不含扩展名）到模型的映射
+// 没有对应模型的文件返回错误，依赖在文件中通过 depends_on 声明
+func (s *Seeder) LoadDir(dir string, models map[string]any) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		model, ok := models[name]
+		if !ok {
+			return fmt.Errorf("seed: 数据文件 %s 没有对应的模型", entry.Name())
+		}
+		fixture, err := readFixture(path)
+		if err != nil {
+			return err
+		}
+		s.Add(File(model, path, fixture.DependsOn...))
+	}
+	return nil
+}
+
+// readFixture 读取数据文件
+func readFixture(path string) (fixtureFile, error) {
+	var fixture fixtureFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fixture, err
+	}
+
+	var raw any
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return fixture, fmt.Errorf("seed: 解析 %s 失败: %w", path, err)
+	}
+
+	// 统一转换为JSON后再解析，兼容两种文件格式
+	if list, ok := raw.([]any); ok {
+		raw = map[string]any{"records": list}
+	}
+	data, err = json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(data, &fixture)
+	}
+	if err != nil {
+		return fixture, fmt.Errorf("seed: 解析 %s 失败: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// insertRecords 将记录转换为模型后逐条写入
+func insertRecords(tx *gorm.DB, model any, records []map[string]any) error {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		row := reflect.New(t)
+		if err = json.Unmarshal(data, row.Interface()); err != nil {
+			return fmt.Errorf("第%d条记录: %w", i+1, err)
+		}
+		if err = tx.Create(row.Interface()).Error; err != nil {
+			return fmt.Errorf("第%d条记录: %w", i+1, err)
+		}
+	}
+	return nil
+}
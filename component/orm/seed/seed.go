@@ -0,0 +1,259 @@
+// Package seed 按依赖顺序写入测试、演示数据
+//
+// 数据可以来自 YAML/JSON 数据文件或Go函数，生产环境下拒绝执行，避免误操作清空或污染线上数据。
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"gorm.io/gorm"
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrProtectedEnv = errors.New("seed: 当前环境禁止写入种子数据")
+	ErrCycle        = errors.New("seed: 依赖关系存在循环")
+)
+
+// Seed 一组种子数据
+type Seed struct {
+	Name      string                  // 名称，唯一
+	DependsOn []string                // 依赖的种子名称，依赖先执行
+	Model     any                     // 数据对应的模型，Truncate/Fresh 时清空其数据表，可为空
+	Run       func(tx *gorm.DB) error // 写入数据，在事务中执行
+}
+
+// Func 使用Go函数创建种子
+//
+// 示例:
+//
+//	seed.Func("admin", func(tx *gorm.DB) error {
+//		return tx.Create(&User{Username: "admin", Password: hash}).Error
+//	}, "role")
+func Func(name string, fn func(tx *gorm.DB) error, dependsOn ...string) Seed {
+	return Seed{Name: name, DependsOn: dependsOn, Run: fn}
+}
+
+// Options 配置
+type Options struct {
+	EnvKey        string   `default:"APP_ENV"` // 读取当前环境的环境变量名，未设置时读取 GIN_MODE
+	Env           string   // 当前环境，为空时读取环境变量
+	ProtectedEnvs []string // 禁止执行的环境，默认 prod、production、release
+	Force         bool     // 忽略环境检查
+}
+
+// Seeder 种子数据管理
+//
+// 示例:
+//
+//	s := seed.New(db.GetDb())
+//	s.Add(seed.Func("role", seedRoles))
+//	_ = s.LoadDir("./testdata/fixtures", map[string]any{"user": &User{}, "order": &Order{}})
+//	err := s.Run(ctx)         // 全部执行
+//	err = s.Run(ctx, "order") // 只执行 order 及其依赖
+//	err = s.Fresh(ctx)        // 清空相关数据表后重新执行
+type Seeder struct {
+	db    *gorm.DB
+	opt   Options
+	seeds map[string]Seed
+	names []string // 添加顺序，无依赖关系的种子按该顺序执行
+}
+
+// New 创建种子数据管理
+func New(db *gorm.DB, opts ...Options) *Seeder {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if len(opt.ProtectedEnvs) == 0 {
+		opt.ProtectedEnvs = []string{"prod", "production", "release"}
+	}
+	return &Seeder{db: db, opt: opt, seeds: make(map[string]Seed)}
+}
+
+// Add 添加种子，同名的种子会被覆盖
+func (s *Seeder) Add(seeds ...Seed) *Seeder {
+	for _, item := range seeds {
+		if _, ok := s.seeds[item.Name]; !ok {
+			s.names = append(s.names, item.Name)
+		}
+		s.seeds[item.Name] = item
+	}
+	return s
+}
+
+// Env 获取当前环境
+func (s *Seeder) Env() string {
+	if s.opt.Env != "" {
+		return s.opt.Env
+	}
+	if env := os.Getenv(s.opt.EnvKey); env != "" {
+		return env
+	}
+	return os.Getenv("GIN_MODE")
+}
+
+// Check 检查当前环境是否允许执行
+func (s *Seeder) Check() error {
+	if s.opt.Force {
+		return nil
+	}
+	env := strings.ToLower(s.Env())
+	for _, protected := range s.opt.ProtectedEnvs {
+		if env == strings.ToLower(protected) {
+			return fmt.Errorf("%w: %s", ErrProtectedEnv, env)
+		}
+	}
+	return nil
+}
+
+// Run 在同一事务中按依赖顺序执行种子，names 为空时执行全部，否则只执行指定的种子及其依赖
+func (s *Seeder) Run(ctx context.Context, names ...string) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+	order, err := s.resolve(names)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, name := range order {
+			item := s.seeds[name]
+			if item.Run == nil {
+				continue
+			}
+			if err := item.Run(tx); err != nil {
+				return fmt.Errorf("seed: %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Fresh 按依赖的逆序清空种子关联的数据表，然后重新执行
+func (s *Seeder) Fresh(ctx context.Context, names ...string) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+	order, err := s.resolve(names)
+	if err != nil {
+		return err
+	}
+	models := make([]any, 0, len(order))
+	for i := len(order) - 1; i >= 0; i-- {
+		if model := s.seeds[order[i]].Model; model != nil {
+			models = append(models, model)
+		}
+	}
+	if err = Truncate(s.db.WithContext(ctx), models...); err != nil {
+		return err
+	}
+	return s.Run(ctx, names...)
+}
+
+// Truncate 清空种子关联的数据表，不做环境检查的版本见包级函数 Truncate
+func (s *Seeder) Truncate(ctx context.Context, models ...any) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+	return Truncate(s.db.WithContext(ctx), models...)
+}
+
+// resolve 计算执行顺序（拓扑排序），依赖不存在或存在循环时返回错误
+func (s *Seeder) resolve(names []string) ([]string, error) {
+	if len(names) == 0 {
+		names = s.names
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(s.seeds))
+	order := make([]string, 0, len(s.seeds))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		item, ok := s.seeds[name]
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("seed: %s 依赖的 %s 不存在", path[len(path)-1], name)
+			}
+			return fmt.Errorf("seed: %s 不存在", name)
+		}
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrCycle, strings.Join(append(path, name), " -> "))
+		}
+		state[name] = visiting
+		for _, dep := range item.DependsOn {
+			if err := visit(dep, append(slices.Clip(path), name)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Truncate 清空模型对应的数据表并重置自增ID，MySQL下临时关闭外键检查
+// 注意：不检查运行环境，请勿在生产环境调用
+func Truncate(db *gorm.DB, models ...any) error {
+	if len(models) == 0 {
+		return nil
+	}
+	tables := make([]string, 0, len(models))
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		tables = append(tables, stmt.Schema.Table)
+	}
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		return db.Connection(func(conn *gorm.DB) error {
+			if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+				return err
+			}
+			defer conn.Exec("SET FOREIGN_KEY_CHECKS = 1")
+			for _, table := range tables {
+				if err := conn.Exec("TRUNCATE TABLE " + conn.Statement.Quote(table)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case "sqlite":
+		return db.Transaction(func(tx *gorm.DB) error {
+			for _, table := range tables {
+				if err := tx.Exec("DELETE FROM " + tx.Statement.Quote(table)).Error; err != nil {
+					return err
+				}
+			}
+			// 未使用 AUTOINCREMENT 的表没有 sqlite_sequence 记录，表本身不存在时忽略错误
+			_ = tx.Exec("DELETE FROM sqlite_sequence WHERE name IN ?", tables).Error
+			return nil
+		})
+	default:
+		for _, table := range tables {
+			if err := db.Exec("DELETE FROM " + db.Statement.Quote(table)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,181 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/orm/sqllite"
+	"gorm.io/gorm"
+	"slices"
+	"testing"
+)
+
+type seedUser struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+type seedOrder struct {
+	ID     uint `json:"id" gorm:"primaryKey"`
+	UserID uint `json:"user_id"`
+	Amount int  `json:"amount"`
+}
+
+// recorder 记录种子的执行顺序
+func recorder(order *[]string, name string, dependsOn ...string) Seed {
+	return Func(name, func(tx *gorm.DB) error {
+		*order = append(*order, name)
+		return nil
+	}, dependsOn...)
+}
+
+func testOptions() Options {
+	return Options{Env: "test"}
+}
+
+func TestRunDependencyOrder(t *testing.T) {
+	db := sqllite.NewTest(t, nil).GetDb()
+	var order []string
+	s := New(db, testOptions()).Add(
+		recorder(&order, "order", "user", "product"),
+		recorder(&order, "user", "role"),
+		recorder(&order, "product"),
+		recorder(&order, "role"),
+		recorder(&order, "log"),
+	)
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"role", "user", "product", "order", "log"}; !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+
+	// 只执行指定的种子及其依赖
+	order = nil
+	if err := s.Run(context.Background(), "user"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"role", "user"}; !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestRunDependencyErrors(t *testing.T) {
+	db := sqllite.NewTest(t, nil).GetDb()
+	var order []string
+
+	cycle := New(db, testOptions()).Add(recorder(&order, "a", "b"), recorder(&order, "b", "c"), recorder(&order, "c", "a"))
+	if err := cycle.Run(context.Background()); !errors.Is(err, ErrCycle) {
+		t.Fatalf("cycle: err = %v, want ErrCycle", err)
+	}
+
+	missing := New(db, testOptions()).Add(recorder(&order, "a", "missing"))
+	if err := missing.Run(context.Background()); err == nil {
+		t.Fatal("missing dependency should fail")
+	}
+	if err := missing.Run(context.Background(), "unknown"); err == nil {
+		t.Fatal("unknown seed should fail")
+	}
+	if len(order) != 0 {
+		t.Fatalf("no seed should run when resolving fails, ran %v", order)
+	}
+}
+
+func TestRunRollsBackOnError(t *testing.T) {
+	db := sqllite.NewTest(t, []any{&seedUser{}}).GetDb()
+	s := New(db, testOptions()).Add(
+		Records("user", &seedUser{}, []seedUser{{ID: 1, Name: "alice"}}),
+		Func("broken", func(tx *gorm.DB) error { return errors.New("boom") }, "user"),
+	)
+	if err := s.Run(context.Background()); err == nil {
+		t.Fatal("failing seed should return an error")
+	}
+	var count int64
+	db.Model(&seedUser{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("count = %d, seeds should run in one transaction", count)
+	}
+}
+
+func TestEnvironmentGuard(t *testing.T) {
+	db := sqllite.NewTest(t, nil).GetDb()
+	for _, env := range []string{"prod", "Production", "release"} {
+		ran := false
+		s := New(db, Options{Env: env}).Add(Func("x", func(tx *gorm.DB) error { ran = true; return nil }))
+		if err := s.Run(context.Background()); !errors.Is(err, ErrProtectedEnv) || ran {
+			t.Errorf("env %s: err = %v, ran = %v; want ErrProtectedEnv", env, err, ran)
+		}
+		if err := s.Fresh(context.Background()); !errors.Is(err, ErrProtectedEnv) {
+			t.Errorf("env %s: Fresh err = %v", env, err)
+		}
+		if err := s.Truncate(context.Background(), &seedUser{}); !errors.Is(err, ErrProtectedEnv) {
+			t.Errorf("env %s: Truncate err = %v", env, err)
+		}
+	}
+
+	// 环境变量
+	t.Setenv("APP_ENV", "production")
+	if err := New(db).Check(); !errors.Is(err, ErrProtectedEnv) {
+		t.Errorf("APP_ENV=production: err = %v", err)
+	}
+	t.Setenv("APP_ENV", "")
+	t.Setenv("GIN_MODE", "release")
+	if err := New(db).Check(); !errors.Is(err, ErrProtectedEnv) {
+		t.Errorf("GIN_MODE=release: err = %v", err)
+	}
+	t.Setenv("GIN_MODE", "debug")
+	if err := New(db).Check(); err != nil {
+		t.Errorf("GIN_MODE=debug: err = %v", err)
+	}
+
+	// 自定义受保护环境及强制执行
+	if err := New(db, Options{Env: "staging", ProtectedEnvs: []string{"staging"}}).Check(); !errors.Is(err, ErrProtectedEnv) {
+		t.Errorf("custom protected env: err = %v", err)
+	}
+	if err := New(db, Options{Env: "prod", Force: true}).Check(); err != nil {
+		t.Errorf("Force: err = %v", err)
+	}
+}
+
+func TestLoadDirAndFresh(t *testing.T) {
+	db := sqllite.NewTest(t, []any{&seedUser{}, &seedOrder{}}).GetDb()
+	s := New(db, testOptions())
+	err := s.LoadDir("./testdata/fixtures", map[string]any{"seed_user": &seedUser{}, "seed_order": &seedOrder{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// seed_order 排序在前，但依赖 seed_user
+	if err = s.Run(context.Background(), "seed_order"); err != nil {
+		t.Fatal(err)
+	}
+	var users, orders int64
+	db.Model(&seedUser{}).Count(&users)
+	db.Model(&seedOrder{}).Count(&orders)
+	if users != 2 || orders != 2 {
+		t.Fatalf("users = %d, orders = %d; want 2 and 2", users, orders)
+	}
+
+	// 再次执行会主键冲突，Fresh 清空后重新写入
+	if err = s.Run(context.Background()); err == nil {
+		t.Fatal("running again should fail on duplicate primary keys")
+	}
+	if err = s.Fresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	db.Model(&seedUser{}).Count(&users)
+	db.Model(&seedOrder{}).Count(&orders)
+	if users != 2 || orders != 2 {
+		t.Fatalf("after Fresh: users = %d, orders = %d; want 2 and 2", users, orders)
+	}
+	var order seedOrder
+	db.First(&order, 2)
+	if order.UserID != 2 || order.Amount != 200 {
+		t.Fatalf("order = %+v", order)
+	}
+}
+
+func TestLoadDirRequiresModel(t *testing.T) {
+	db := sqllite.NewTest(t, nil).GetDb()
+	if err := New(db, testOptions()).LoadDir("./testdata/fixtures", map[string]any{"seed_user": &seedUser{}}); err == nil {
+		t.Fatal("fixture without a model should fail")
+	}
+}
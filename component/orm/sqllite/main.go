@@ -5,12 +5,18 @@ import (
 	"fmt"
 	"github.com/jcbowen/jcbaseGo"
 	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/orm/audit"
+	"github.com/jcbowen/jcbaseGo/component/orm/bulk"
+	"github.com/jcbowen/jcbaseGo/component/orm/query"
+	"github.com/jcbowen/jcbaseGo/component/orm/sqlstat"
+	"github.com/jcbowen/jcbaseGo/component/orm/timestamp"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Instance struct {
@@ -18,6 +24,9 @@ type Instance struct {
 	Db     *gorm.DB
 	debug  bool // 是否开启调试模式
 	Errors []error
+
+	sqlStat *sqlstat.Collector // SQL执行统计
+	auditor *audit.Recorder    // 数据变更记录
 }
 
 // New 获取新的数据库连接
@@ -27,20 +36,23 @@ func New(Conf jcbaseGo.SqlLiteStruct) (i *Instance) {
 	err := helper.CheckAndSetDefault(&Conf)
 	jcbaseGo.PanicIfError(err)
 
-	// 获取dbFile的绝对路径
-	fileNameFull, err := filepath.Abs(Conf.DbFile)
-	jcbaseGo.PanicIfError(err)
-
-	// 检查目录是否存在，如果不存在则创建
-	_, err = helper.NewFile(&helper.File{Path: fileNameFull}).DirExists(true)
-	jcbaseGo.PanicIfError(err)
-
 	// 判断dbConfig是否为空
 	if Conf.DbFile == "" {
 		log.Panic(errors.New("dbConfig is empty"))
 		return
 	}
 
+	// 内存数据库没有文件，无需创建目录
+	if !IsMemory(Conf.DbFile) {
+		// 获取dbFile的绝对路径
+		fileNameFull, err := filepath.Abs(Conf.DbFile)
+		jcbaseGo.PanicIfError(err)
+
+		// 检查目录是否存在，如果不存在则创建
+		_, err = helper.NewFile(&helper.File{Path: fileNameFull}).DirExists(true)
+		jcbaseGo.PanicIfError(err)
+	}
+
 	// 创建数据库连接
 	db, err := gorm.Open(sqlite.Open(Conf.DbFile), &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{
@@ -50,6 +62,16 @@ func New(Conf jcbaseGo.SqlLiteStruct) (i *Instance) {
 	})
 	jcbaseGo.PanicIfError(err)
 
+	// 字符串类型的新增、更新时间字段由插件填充
+	jcbaseGo.PanicIfError(db.Use(timestamp.New()))
+
+	// 未共享缓存的内存数据库每个连接都是独立的，限制为单个连接，避免不同连接看到不同的数据
+	if IsMemory(Conf.DbFile) && !strings.Contains(Conf.DbFile, "cache=shared") {
+		sqlDB, err := db.DB()
+		jcbaseGo.PanicIfError(err)
+		sqlDB.SetMaxOpenConns(1)
+	}
+
 	i.Conf = Conf
 	i.Db = db
 
@@ -73,6 +95,109 @@ func (c *Instance) GetDb() *gorm.DB {
 	return c.Db
 }
 
+// EnableSQLStats 开启SQL执行统计，按归一化语句聚合执行次数、耗时及行数
+//
+// 示例:
+//
+//	db := sqllite.New(conf).EnableSQLStats(sqlstat.Options{SlowThreshold: 100 * time.Millisecond})
+//	report := db.GetSlowQueryReport(20)
+func (c *Instance) EnableSQLStats(opts ...sqlstat.Options) *Instance {
+	if c.Db == nil || c.sqlStat != nil {
+		return c
+	}
+	collector := sqlstat.New(opts...)
+	if err := c.Db.Use(collector); err != nil {
+		c.AddError(err)
+		return c
+	}
+	c.sqlStat = collector
+	return c
+}
+
+// GetSlowQueryReport 获取SQL执行统计报告，按总耗时倒序排列，未开启统计时返回nil
+//
+// 参数:
+//   - limit (可选): 最多返回的条数，默认全部返回
+func (c *Instance) GetSlowQueryReport(limit ...int) []sqlstat.Stat {
+	if c.sqlStat == nil {
+		return nil
+	}
+	return c.sqlStat.Report(limit...)
+}
+
+// ResetSQLStats 清空SQL执行统计数据
+func (c *Instance) ResetSQLStats() {
+	if c.sqlStat != nil {
+		c.sqlStat.Reset()
+	}
+}
+
+// EnableAudit 开启数据变更记录，实现了 audit.Auditable 或在 Models 中指定的模型，新增、更新、删除时记录前后数据及操作人
+//
+// 示例:
+//
+//	db := sqllite.New(conf).EnableAudit(audit.Options{AutoMigrate: true})
+//	logs, err := db.GetAuditor().History(ctx, &User{}, 10)
+func (c *Instance) EnableAudit(opts ...audit.Options) *Instance {
+	if c.Db == nil || c.auditor != nil {
+		return c
+	}
+	recorder := audit.New(opts...)
+	if err := c.Db.Use(recorder); err != nil {
+		c.AddError(err)
+		return c
+	}
+	c.auditor = recorder
+	return c
+}
+
+// GetAuditor 获取数据变更记录插件，用于查询变更记录，未开启时返回nil
+func (c *Instance) GetAuditor() *audit.Recorder {
+	return c.auditor
+}
+
+// BulkInsert 分批插入，每批生成一条多行 INSERT 语句，batchSize 小于等于0时为1000
+// 各批次独立执行，失败的批次记录在返回结果的 Errors 中；需要跳过钩子等更多配置时使用 bulk.Insert
+//
+// 示例:
+//
+//	res := db.BulkInsert(users, 2000)
+//	if err := res.Err(); err != nil {
+//		log.Println(res.Errors)
+//	}
+func (c *Instance) BulkInsert(models any, batchSize int) bulk.Result {
+	return bulk.Insert(c.GetDb(), models, bulk.Options{BatchSize: batchSize})
+}
+
+// BulkUpsert 分批插入，唯一键冲突时更新 updateColumns 指定的列，updateColumns 为空时更新除冲突列外的全部列
+func (c *Instance) BulkUpsert(models any, conflictColumns, updateColumns []string, batchSize int) bulk.Result {
+	return bulk.Upsert(c.GetDb(), models, conflictColumns, updateColumns, bulk.Options{BatchSize: batchSize})
+}
+
+// BulkDelete 按主键分批删除，ids 为主键切片
+//
+// 示例:
+//
+//	res := db.BulkDelete(&User{}, []uint{1, 2, 3}, 1000)
+func (c *Instance) BulkDelete(model any, ids any, batchSize int) bulk.Result {
+	return bulk.Delete(c.GetDb(), model, ids, bulk.Options{BatchSize: batchSize})
+}
+
+// Query 创建原生SQL构建器，支持 :name 命名参数及切片参数自动展开，用于统计、报表类查询
+//
+// 示例:
+//
+//	rows, err := db.Query().
+//		Select("DATE(created_at) AS day, COUNT(*) AS total").
+//		From("order").
+//		NamedWhere("created_at BETWEEN :start_date AND :end_date", query.Params{"start_date": start, "end_date": end}).
+//		Where("status IN ?", []int{1, 2}).
+//		GroupBy("day").
+//		Maps()
+func (c *Instance) Query() *query.Builder {
+	return query.New(c.GetDb())
+}
+
 // GetAllTableName 获取所有表名
 func (c *Instance) GetAllTableName() (tableNames []string, err error) {
 	// 如果有错误，就不再执行
@@ -0,0 +1,68 @@
+package sqllite
+
+import (
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// memorySeq 用于生成互不冲突的内存数据库名称
+var memorySeq atomic.Int64
+
+// IsMemory 判断DbFile是否为内存数据库，支持 ":memory:"、"file::memory:" 及带 mode=memory 参数的URI
+func IsMemory(dbFile string) bool {
+	if dbFile == ":memory:" || strings.HasPrefix(dbFile, "file::memory:") {
+		return true
+	}
+	_, query, ok := strings.Cut(dbFile, "?")
+	return ok && strings.HasPrefix(dbFile, "file:") && strings.Contains("&"+query+"&", "&mode=memory&")
+}
+
+// MemoryDSN 获取命名的共享缓存内存数据库地址，同一进程内使用相同名称的连接访问同一个数据库
+// 所有连接关闭后数据库被销毁
+//
+// 示例:
+//
+//	db := sqllite.New(jcbaseGo.SqlLiteStruct{DbFile: sqllite.MemoryDSN("cache")})
+func MemoryDSN(name string) string {
+	return fmt.Sprintf("file:%s?mode=memory&cache=shared", name)
+}
+
+// NewTest 创建隔离的内存数据库实例并自动迁移模型，测试结束时自动关闭
+// 每次调用使用不同的数据库，测试之间互不影响；conf 可选，用于指定表前缀等，DbFile 会被忽略
+//
+// 示例:
+//
+//	func TestUser(t *testing.T) {
+//		db := sqllite.NewTest(t, []any{&User{}, &Order{}})
+//		db.GetDb().Create(&User{Name: "test"})
+//	}
+func NewTest(tb testing.TB, models []any, conf ...jcbaseGo.SqlLiteStruct) *Instance {
+	tb.Helper()
+
+	var c jcbaseGo.SqlLiteStruct
+	if len(conf) > 0 {
+		c = conf[0]
+	}
+	name := strings.NewReplacer("/", "_", " ", "_", "#", "_", "?", "_").Replace(tb.Name())
+	c.DbFile = MemoryDSN(fmt.Sprintf("%s_%d", name, memorySeq.Add(1)))
+	if c.Alias == "" {
+		c.Alias = "test"
+	}
+
+	i := New(c)
+	sqlDB, err := i.Db.DB()
+	if err != nil {
+		tb.Fatalf("sqllite: 获取连接失败: %v", err)
+	}
+	tb.Cleanup(func() { _ = sqlDB.Close() })
+
+	if len(models) > 0 {
+		if err = i.Db.AutoMigrate(models...); err != nil {
+			tb.Fatalf("sqllite: 迁移模型失败: %v", err)
+		}
+	}
+	return i
+}
@@ -0,0 +1,137 @@
+package sqllite
+
+import (
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type memoryUser struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+func TestIsMemory(t *testing.T) {
+	for dbFile, want := range map[string]bool{
+		":memory:":                           true,
+		"file::memory:":                      true,
+		"file::memory:?cache=shared":         true,
+		"file:test?mode=memory&cache=shared": true,
+		"file:test?cache=shared&mode=memory": true,
+		"./runtime/data.db":                  false,
+		"file:data.db?mode=rwc":              false,
+		"memory.db":                          false,
+	} {
+		if got := IsMemory(dbFile); got != want {
+			t.Errorf("IsMemory(%q) = %v, want %v", dbFile, got, want)
+		}
+	}
+}
+
+// TestNewMemorySingleConnection 未共享缓存的内存数据库每个连接互相独立，必须限制为单个连接
+func TestNewMemorySingleConnection(t *testing.T) {
+	for _, dbFile := range []string{":memory:", "file::memory:"} {
+		t.Run(dbFile, func(t *testing.T) {
+			i := New(jcbaseGo.SqlLiteStruct{DbFile: dbFile, Alias: "memory_test"})
+			sqlDB, err := i.Db.DB()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sqlDB.Close()
+			if max := sqlDB.Stats().MaxOpenConnections; max != 1 {
+				t.Fatalf("MaxOpenConnections = %d, want 1", max)
+			}
+			if err = i.Db.AutoMigrate(&memoryUser{}); err != nil {
+				t.Fatal(err)
+			}
+
+			// 并发访问时所有查询都应看到同一个数据库
+			var wg sync.WaitGroup
+			errs := make(chan error, 20)
+			for n := 0; n < 20; n++ {
+				wg.Add(1)
+				go func(n int) {
+					defer wg.Done()
+					errs <- i.Db.Create(&memoryUser{Name: fmt.Sprint(n)}).Error
+				}(n)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			var count int64
+			i.Db.Model(&memoryUser{}).Count(&count)
+			if count != 20 {
+				t.Fatalf("count = %d, want 20", count)
+			}
+		})
+	}
+}
+
+func TestNewSharedMemoryKeepsPool(t *testing.T) {
+	i := New(jcbaseGo.SqlLiteStruct{DbFile: MemoryDSN(t.Name()), Alias: "memory_test"})
+	sqlDB, _ := i.Db.DB()
+	defer sqlDB.Close()
+	if max := sqlDB.Stats().MaxOpenConnections; max == 1 {
+		t.Fatal("shared cache memory database should not be limited to a single connection")
+	}
+
+	// 相同名称的连接访问同一个数据库
+	other := New(jcbaseGo.SqlLiteStruct{DbFile: MemoryDSN(t.Name()), Alias: "memory_test"})
+	otherDB, _ := other.Db.DB()
+	defer otherDB.Close()
+	if err := i.Db.AutoMigrate(&memoryUser{}); err != nil {
+		t.Fatal(err)
+	}
+	i.Db.Create(&memoryUser{Name: "shared"})
+	var count int64
+	if err := other.Db.Model(&memoryUser{}).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("count = %d, %v; want the row written by the other instance", count, err)
+	}
+}
+
+func TestNewTestIsolated(t *testing.T) {
+	var first *Instance
+	t.Run("first", func(t *testing.T) {
+		first = NewTest(t, []any{&memoryUser{}})
+		first.Db.Create(&memoryUser{Name: "a"})
+	})
+	t.Run("second", func(t *testing.T) {
+		db := NewTest(t, []any{&memoryUser{}})
+		var count int64
+		db.Db.Model(&memoryUser{}).Count(&count)
+		if count != 0 {
+			t.Fatalf("count = %d, databases of different tests should be isolated", count)
+		}
+	})
+
+	// 测试结束后连接被关闭
+	sqlDB, _ := first.Db.DB()
+	if err := sqlDB.Ping(); err == nil {
+		t.Fatal("database of a finished test should be closed")
+	}
+}
+
+func TestNewTestTablePrefix(t *testing.T) {
+	db := NewTest(t, []any{&memoryUser{}}, jcbaseGo.SqlLiteStruct{TablePrefix: "t_", DbFile: "ignored.db"})
+	tables, err := db.GetAllTableName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, table := range tables {
+		found = found || strings.HasPrefix(table, "t_memory_user")
+	}
+	if !found {
+		t.Fatalf("tables = %v, want the prefixed memory_user table", tables)
+	}
+	if _, err = os.Stat("ignored.db"); !os.IsNotExist(err) {
+		t.Fatal("DbFile should be ignored by NewTest")
+	}
+}
@@ -0,0 +1,219 @@
+package sqlstat
+
+import (
+	"gorm.io/gorm"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// startTimeKey 语句开始时间在gorm实例中的键名
+const startTimeKey = "sqlstat:start_time"
+
+// Options 统计配置
+type Options struct {
+	SlowThreshold time.Duration // 慢查询阈值，默认200毫秒
+	MaxStatements int           // 最多统计的不同语句数量，超出后新语句不再统计，默认1000
+}
+
+// Stat 单条归一化语句的统计信息
+type Stat struct {
+	SQL       string        `json:"sql"`        // 归一化后的语句
+	Count     int64         `json:"count"`      // 执行次数
+	SlowCount int64         `json:"slow_count"` // 超过慢查询阈值的次数
+	ErrCount  int64         `json:"err_count"`  // 执行出错的次数
+	TotalTime time.Duration `json:"total_time"` // 总耗时
+	AvgTime   time.Duration `json:"avg_time"`   // 平均耗时
+	MaxTime   time.Duration `json:"max_time"`   // 最大耗时
+	Rows      int64         `json:"rows"`       // 影响/返回的总行数
+	MaxRows   int64         `json:"max_rows"`   // 单次最多影响/返回的行数
+	LastSeen  time.Time     `json:"last_seen"`  // 最后一次执行时间
+}
+
+// Collector 按归一化语句聚合SQL执行情况的gorm插件
+type Collector struct {
+	opt Options
+
+	mu    sync.Mutex
+	stats map[string]*Stat
+}
+
+// New 创建统计插件
+func New(opts ...Options) *Collector {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.SlowThreshold <= 0 {
+		opt.SlowThreshold = 200 * time.Millisecond
+	}
+	if opt.MaxStatements <= 0 {
+		opt.MaxStatements = 1000
+	}
+	return &Collector{opt: opt, stats: make(map[string]*Stat)}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (c *Collector) Name() string {
+	return "jcbaseGo:sqlstat"
+}
+
+// Initialize 实现 gorm.Plugin 接口，在各类语句执行前后注册回调
+func (c *Collector) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().Before("gorm:create").Register("sqlstat:before_create", c.before); err != nil {
+		return err
+	}
+	if err := callback.Create().After("gorm:create").Register("sqlstat:after_create", c.after); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("sqlstat:before_query", c.before); err != nil {
+		return err
+	}
+	if err := callback.Query().After("gorm:query").Register("sqlstat:after_query", c.after); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("sqlstat:before_update", c.before); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("sqlstat:after_update", c.after); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("sqlstat:before_delete", c.before); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:delete").Register("sqlstat:after_delete", c.after); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("sqlstat:before_row", c.before); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("sqlstat:after_row", c.after); err != nil {
+		return err
+	}
+	if err := callback.Raw().Before("gorm:raw").Register("sqlstat:before_raw", c.before); err != nil {
+		return err
+	}
+	return callback.Raw().After("gorm:raw").Register("sqlstat:after_raw", c.after)
+}
+
+// Report 获取统计报告，按总耗时倒序排列
+//
+// 参数:
+//   - limit (可选): 最多返回的条数，默认全部返回
+func (c *Collector) Report(limit ...int) []Stat {
+	c.mu.Lock()
+	report := make([]Stat, 0, len(c.stats))
+	for _, stat := range c.stats {
+		report = append(report, *stat)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].TotalTime > report[j].TotalTime
+	})
+	if len(limit) > 0 && limit[0] > 0 && len(report) > limit[0] {
+		report = report[:limit[0]]
+	}
+	return report
+}
+
+// SlowQueries 获取出现过慢查询的语句，按慢查询次数倒序排列
+func (c *Collector) SlowQueries() []Stat {
+	var slow []Stat
+	for _, stat := range c.Report() {
+		if stat.SlowCount > 0 {
+			slow = append(slow, stat)
+		}
+	}
+	sort.SliceStable(slow, func(i, j int) bool {
+		return slow[i].SlowCount > slow[j].SlowCount
+	})
+	return slow
+}
+
+// Reset 清空统计数据
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = make(map[string]*Stat)
+}
+
+// before 记录语句开始时间
+func (c *Collector) before(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+// after 聚合本次执行的耗时与行数
+func (c *Collector) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(startTimeKey)
+	if !ok {
+		return
+	}
+	start, ok := value.(time.Time)
+	if !ok || db.Statement == nil {
+		return
+	}
+	sql := Normalize(db.Statement.SQL.String())
+	if sql == "" {
+		return
+	}
+	duration := time.Since(start)
+	rows := db.Statement.RowsAffected
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stat, ok := c.stats[sql]
+	if !ok {
+		if len(c.stats) >= c.opt.MaxStatements {
+			return
+		}
+		stat = &Stat{SQL: sql}
+		c.stats[sql] = stat
+	}
+
+	stat.Count++
+	stat.TotalTime += duration
+	stat.AvgTime = stat.TotalTime / time.Duration(stat.Count)
+	if duration > stat.MaxTime {
+		stat.MaxTime = duration
+	}
+	if duration >= c.opt.SlowThreshold {
+		stat.SlowCount++
+	}
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		stat.ErrCount++
+	}
+	if rows > 0 {
+		stat.Rows += rows
+		if rows > stat.MaxRows {
+			stat.MaxRows = rows
+		}
+	}
+	stat.LastSeen = time.Now()
+}
+
+var (
+	stringLiteralRegexp = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	numberLiteralRegexp = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	placeholderInRegexp = regexp.MustCompile(`(?i)\bIN\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	valuesListRegexp    = regexp.MustCompile(`(?i)\bVALUES\s*(\([^()]*\))(?:\s*,\s*\([^()]*\))+`)
+	whitespaceRegexp    = regexp.MustCompile(`\s+`)
+)
+
+// Normalize 将语句中的字面量替换为占位符，使同一语句的不同参数归为一类
+func Normalize(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		return ""
+	}
+	sql = stringLiteralRegexp.ReplaceAllString(sql, "?")
+	sql = numberLiteralRegexp.ReplaceAllString(sql, "?")
+	sql = placeholderInRegexp.ReplaceAllString(sql, "IN (?)")
+	sql = valuesListRegexp.ReplaceAllString(sql, "VALUES $1")
+	sql = whitespaceRegexp.ReplaceAllString(sql, " ")
+	return sql
+}
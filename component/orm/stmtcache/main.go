@@ -0,0 +1,151 @@
+// Package stmtcache 预处理语句缓存：统计 gorm PrepareStmt 缓存的命中率，并限制缓存的语句数量
+//
+// gorm 开启 PrepareStmt 后按语句文本缓存预处理语句且不会淘汰，IN 列表长度不同等动态拼接的语句会使缓存持续增长；
+// MySQL 的预处理语句按连接创建，服务端语句数约为缓存语句数乘以连接数，受 max_prepared_stmt_count（默认16382）限制。
+// 缓存的语句数超过 MaxSize 时清空重建。
+package stmtcache
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"sync"
+	"sync/atomic"
+)
+
+// Options 配置
+type Options struct {
+	MaxSize int // 最多缓存的语句数量，超出时清空缓存，默认1000
+}
+
+// Stats 缓存统计
+type Stats struct {
+	Statements int     `json:"statements"` // 当前缓存的语句数量
+	Executions int64   `json:"executions"` // 执行的语句数
+	Prepares   int64   `json:"prepares"`   // 预处理次数，即未命中缓存的次数
+	Hits       int64   `json:"hits"`       // 命中缓存的次数
+	HitRate    float64 `json:"hit_rate"`   // 命中率，0~1
+	Resets     int64   `json:"resets"`     // 超出数量限制或手动清空的次数
+}
+
+// Cache 预处理语句缓存插件，需在 gorm.Config 中开启 PrepareStmt
+//
+// 示例:
+//
+//	db, _ := gorm.Open(mysql.Open(dsn), &gorm.Config{PrepareStmt: true})
+//	cache := stmtcache.New(stmtcache.Options{MaxSize: 500})
+//	_ = db.Use(cache)
+//	stats := cache.Stats()
+type Cache struct {
+	opt      Options
+	prepared *gorm.PreparedStmtDB
+
+	executions atomic.Int64
+	resets     atomic.Int64
+	mu         sync.Mutex
+	prepares   int64 // 已清空的缓存中累计的预处理次数
+}
+
+// New 创建预处理语句缓存插件
+func New(opts ...Options) *Cache {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxSize <= 0 {
+		opt.MaxSize = 1000
+	}
+	return &Cache{opt: opt}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (c *Cache) Name() string {
+	return "jcbaseGo:stmtcache"
+}
+
+// Initialize 实现 gorm.Plugin 接口，在各类语句执行后统计执行次数并检查缓存数量
+func (c *Cache) Initialize(db *gorm.DB) error {
+	prepared, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		return errors.New("stmtcache: 未开启 PrepareStmt")
+	}
+	c.prepared = prepared
+
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Register("stmtcache:after_create", c.after); err != nil {
+		return err
+	}
+	if err := callback.Query().After("gorm:query").Register("stmtcache:after_query", c.after); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("stmtcache:after_update", c.after); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:delete").Register("stmtcache:after_delete", c.after); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("stmtcache:after_row", c.after); err != nil {
+		return err
+	}
+	return callback.Raw().After("gorm:raw").Register("stmtcache:after_raw", c.after)
+}
+
+// Stats 获取缓存统计
+func (c *Cache) Stats() Stats {
+	stats := Stats{Executions: c.executions.Load(), Resets: c.resets.Load()}
+	if c.prepared == nil {
+		return stats
+	}
+	c.mu.Lock()
+	c.prepared.Mux.RLock()
+	stats.Statements = len(c.prepared.Stmts)
+	stats.Prepares = c.prepares + int64(len(c.prepared.PreparedSQL))
+	c.prepared.Mux.RUnlock()
+	c.mu.Unlock()
+
+	stats.Hits = max(stats.Executions-stats.Prepares, 0)
+	if stats.Executions > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(stats.Executions)
+	}
+	return stats
+}
+
+// Reset 清空缓存的预处理语句，统计数据保留
+func (c *Cache) Reset() {
+	if c.prepared == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
+}
+
+// after 统计执行次数，缓存数量超出限制时清空
+func (c *Cache) after(db *gorm.DB) {
+	if db.DryRun || db.Statement == nil || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	c.executions.Add(1)
+	if c.size() <= c.opt.MaxSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size() > c.opt.MaxSize { // 并发时仅清空一次
+		c.reset()
+	}
+}
+
+func (c *Cache) size() int {
+	c.prepared.Mux.RLock()
+	defer c.prepared.Mux.RUnlock()
+	return len(c.prepared.Stmts)
+}
+
+// reset 清空缓存，调用方需持有 c.mu
+func (c *Cache) reset() {
+	c.prepared.Mux.RLock()
+	c.prepares += int64(len(c.prepared.PreparedSQL))
+	c.prepared.Mux.RUnlock()
+	c.prepared.Reset()
+	c.resets.Add(1)
+}
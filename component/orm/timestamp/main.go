@@ -0,0 +1,169 @@
+// Package timestamp 字符串类型时间字段的自动填充
+//
+// gorm 的 autoCreateTime、autoUpdateTime 仅支持 time.Time 及整数类型，
+// 字符串类型的 created_at、updated_at（如 sqlite 模型约定的 "2006-01-02 15:04:05"）不会被填充。
+// 该插件在新增时填充值为空的新增、更新时间字段，在更新时设置更新时间字段。
+package timestamp
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"time"
+)
+
+// Options 配置
+type Options struct {
+	Layout       string   // 时间格式，默认 2006-01-02 15:04:05
+	CreateFields []string // 新增时填充的字段（字段名或列名），默认 created_at、updated_at
+	UpdateFields []string // 更新时填充的字段（字段名或列名），默认 updated_at
+}
+
+// Plugin 时间字段填充插件，仅处理字符串类型的字段
+//
+// 示例:
+//
+//	_ = db.Use(timestamp.New())
+type Plugin struct {
+	opt Options
+}
+
+// New 创建时间字段填充插件
+func New(opts ...Options) *Plugin {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Layout == "" {
+		opt.Layout = "2006-01-02 15:04:05"
+	}
+	if opt.CreateFields == nil {
+		opt.CreateFields = []string{"created_at", "updated_at"}
+	}
+	if opt.UpdateFields == nil {
+		opt.UpdateFields = []string{"updated_at"}
+	}
+	return &Plugin{opt: opt}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (p *Plugin) Name() string {
+	return "jcbaseGo:timestamp"
+}
+
+// Initialize 实现 gorm.Plugin 接口
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().Before("gorm:create").Register("timestamp:create", p.create); err != nil {
+		return err
+	}
+	return callback.Update().Before("gorm:update").Register("timestamp:update", p.update)
+}
+
+// create 填充值为空的新增时间字段
+func (p *Plugin) create(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil {
+		return
+	}
+	fields := p.lookup(stmt.Schema, p.opt.CreateFields)
+	if len(fields) == 0 {
+		return
+	}
+	now := time.Now().Format(p.opt.Layout)
+
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		fillMap(dest, fields, now)
+		return
+	case []map[string]interface{}:
+		for _, m := range dest {
+			fillMap(m, fields, now)
+		}
+		return
+	}
+
+	rv := stmt.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			p.fillStruct(db, reflect.Indirect(rv.Index(i)), fields, now)
+		}
+	case reflect.Struct:
+		p.fillStruct(db, rv, fields, now)
+	}
+}
+
+// update 设置更新时间字段，UpdateColumn 等跳过钩子的更新不处理，更新内容中已指定的不覆盖
+func (p *Plugin) update(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.SkipHooks {
+		return
+	}
+	fields := p.lookup(stmt.Schema, p.opt.UpdateFields)
+	if len(fields) == 0 {
+		return
+	}
+	now := time.Now().Format(p.opt.Layout)
+
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		fillMap(dest, fields, now)
+		return
+	case []map[string]interface{}:
+		for _, m := range dest {
+			fillMap(m, fields, now)
+		}
+		return
+	}
+
+	// 更新内容为结构体时，gorm 会同时写回模型，模型不可寻址时无法设置
+	if stmt.ReflectValue.Kind() == reflect.Struct && !stmt.ReflectValue.CanAddr() {
+		return
+	}
+	for _, field := range fields {
+		stmt.SetColumn(field.DBName, now, true)
+	}
+}
+
+// lookup 查找字符串类型的时间字段
+func (p *Plugin) lookup(s *schema.Schema, names []string) []*schema.Field {
+	fields := make([]*schema.Field, 0, len(names))
+	for _, name := range names {
+		field := s.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			continue
+		}
+		typ := field.FieldType
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() == reflect.String {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// fillStruct 填充结构体中值为空的字段
+func (p *Plugin) fillStruct(db *gorm.DB, rv reflect.Value, fields []*schema.Field, now string) {
+	if rv.Kind() != reflect.Struct || !rv.CanAddr() {
+		return
+	}
+	for _, field := range fields {
+		if _, isZero := field.ValueOf(db.Statement.Context, rv); isZero {
+			_ = db.AddError(field.Set(db.Statement.Context, rv, now))
+		}
+	}
+}
+
+// fillMap 填充 map 中未指定的字段，键可以是字段名或列名
+func fillMap(m map[string]interface{}, fields []*schema.Field, now string) {
+	for _, field := range fields {
+		_, byName := m[field.Name]
+		_, byColumn := m[field.DBName]
+		if !byName && !byColumn {
+			m[field.DBName] = now
+		}
+	}
+}
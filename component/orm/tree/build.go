@@ -0,0 +1,101 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+)
+
+// BuildOptions 构建嵌套结构的配置，键名为JSON字段名
+type BuildOptions struct {
+	IDKey       string `default:"id"`
+	ParentKey   string `default:"parent_id"`
+	ChildrenKey string `default:"children"`
+	RootID      uint   // 作为根节点的上级ID，默认0；上级不在列表中的节点同样作为根节点
+	KeepEmpty   bool   // 没有子节点时是否输出空的 children 数组，默认不输出
+}
+
+// BuildTree 将扁平的节点列表构建为嵌套结构，保持列表中的先后顺序，节点可以是结构体或map
+//
+// 示例:
+//
+//	menus := make([]Menu, 0)
+//	db.Order("sort, id").Find(&menus)
+//	nested := tree.BuildTree(menus)
+//	// [{"id":1,"name":"系统","children":[{"id":2,"parent_id":1,"name":"用户管理"}]}]
+func BuildTree[T any](nodes []T, opts ...BuildOptions) []map[string]any {
+	var opt BuildOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+
+	items := make([]map[string]any, 0, len(nodes))
+	ids := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		item := toMap(node)
+		if item == nil {
+			continue
+		}
+		items = append(items, item)
+		ids[key(item[opt.IDKey])] = true
+	}
+
+	children := make(map[string][]map[string]any)
+	roots := make([]map[string]any, 0)
+	rootID := key(opt.RootID)
+	for _, item := range items {
+		parent := key(item[opt.ParentKey])
+		if parent == rootID || parent == "" || !ids[parent] {
+			roots = append(roots, item)
+			continue
+		}
+		children[parent] = append(children[parent], item)
+	}
+
+	var attach func(list []map[string]any, depth int)
+	attach = func(list []map[string]any, depth int) {
+		for _, item := range list {
+			sub := children[key(item[opt.IDKey])]
+			if len(sub) > 0 && depth < maxDepth {
+				attach(sub, depth+1)
+				item[opt.ChildrenKey] = sub
+			} else if opt.KeepEmpty {
+				item[opt.ChildrenKey] = make([]map[string]any, 0)
+			}
+		}
+	}
+	attach(roots, 0)
+	return roots
+}
+
+// toMap 将节点转换为map，结构体按JSON字段名转换
+func toMap(node any) map[string]any {
+	if m, ok := node.(map[string]any); ok {
+		copied := make(map[string]any, len(m)+1)
+		for k, v := range m {
+			copied[k] = v
+		}
+		return copied
+	}
+	data, err := json.Marshal(node)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if decoder.Decode(&m) != nil {
+		return nil
+	}
+	return m
+}
+
+// key 将ID统一转换为字符串用于比较
+func key(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
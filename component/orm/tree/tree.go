@@ -0,0 +1,443 @@
+// Package tree 树形结构数据表（菜单、分类、地区等）的查询及维护
+//
+// 同时支持邻接表（仅 parent_id）与路径枚举（parent_id + path）两种存储方式：
+// 模型中存在路径字段时，查询子孙及祖先只需一次查询，否则逐层查询。
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxDepth 逐层查询时的最大层级，避免数据中存在环时无限循环
+const maxDepth = 100
+
+var (
+	ErrNotFound = errors.New("tree: 节点不存在")
+	ErrCycle    = errors.New("tree: 不能移动到自身或其子节点下")
+)
+
+// Node 树形结构的公共字段，嵌入模型中使用，未指定字段类型以兼容 MySQL 及 SQLite
+// Path 为从根节点到上级节点的ID路径，如 /1/5/ 表示上级为5、5的上级为1，根节点为 /
+//
+// 示例:
+//
+//	type Menu struct {
+//	    jcbaseGo.MysqlBaseModel
+//	    Id        uint   `gorm:"column:id;type:INT(11) UNSIGNED;primaryKey;autoIncrement" json:"id"`
+//	    tree.Node
+//	    Name      string `gorm:"column:name;type:VARCHAR(50)" json:"name"`
+//	}
+type Node struct {
+	ParentID uint   `gorm:"column:parent_id;index;default:0;comment:上级ID" json:"parent_id"`
+	Path     string `gorm:"column:path;size:1000;index;default:/;comment:上级ID路径" json:"path"`
+	Level    int    `gorm:"column:level;default:1;comment:层级" json:"level"`
+	Sort     int    `gorm:"column:sort;default:0;comment:排序，越小越靠前" json:"sort"`
+}
+
+// Options 字段配置，字段在模型中不存在时不使用
+type Options struct {
+	IDColumn     string `default:"id"`
+	ParentColumn string `default:"parent_id"`
+	PathColumn   string `default:"path"`  // 路径字段，不存在时按邻接表处理
+	LevelColumn  string `default:"level"` // 层级字段，根节点为1
+	SortColumn   string `default:"sort"`  // 排序字段，同级节点按该字段升序、ID升序排列
+}
+
+// Tree 树形数据表操作
+//
+// 示例:
+//
+//	menus := tree.New[Menu](db)
+//	_ = menus.Create(&Menu{Node: tree.Node{ParentID: 1}, Name: "用户管理"})
+//	children, _ := menus.GetChildren(1)
+//	ancestors, _ := menus.GetAncestors(12)
+//	_ = menus.MoveNode(12, 3)
+//	nested, _ := menus.Tree(0) // 完整的嵌套结构，可直接输出为JSON
+type Tree[T any] struct {
+	db  *gorm.DB
+	opt Options
+
+	schema                  *schema.Schema
+	id, parent, path, level *schema.Field
+	sortColumn              string
+}
+
+// New 创建树形数据表操作，T 为模型类型（非指针）
+func New[T any](db *gorm.DB, opts ...Options) *Tree[T] {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+
+	t := &Tree[T]{db: db, opt: opt}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		_ = db.AddError(fmt.Errorf("tree: 解析模型失败: %w", err))
+		return t
+	}
+	t.schema = stmt.Schema
+	t.id = t.schema.LookUpField(opt.IDColumn)
+	t.parent = t.schema.LookUpField(opt.ParentColumn)
+	t.path = t.schema.LookUpField(opt.PathColumn)
+	t.level = t.schema.LookUpField(opt.LevelColumn)
+	if t.schema.LookUpField(opt.SortColumn) != nil {
+		t.sortColumn = opt.SortColumn
+	}
+	return t
+}
+
+// query 创建新的查询，按排序字段及ID排序
+func (t *Tree[T]) query() *gorm.DB {
+	tx := t.db.Model(new(T))
+	if t.sortColumn != "" {
+		tx = tx.Order(t.sortColumn)
+	}
+	return tx.Order(t.opt.IDColumn)
+}
+
+// check 检查模型是否包含必需的字段
+func (t *Tree[T]) check() error {
+	if t.schema == nil || t.id == nil || t.parent == nil {
+		return fmt.Errorf("tree: 模型缺少 %s 或 %s 字段", t.opt.IDColumn, t.opt.ParentColumn)
+	}
+	return nil
+}
+
+// Get 获取节点
+func (t *Tree[T]) Get(id uint) (*T, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	node := new(T)
+	tx := t.db.Model(new(T)).Where(t.opt.IDColumn+" = ?", id).Limit(1).Find(node)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+	return node, nil
+}
+
+// GetRoots 获取根节点
+func (t *Tree[T]) GetRoots() ([]T, error) {
+	return t.GetChildren(0)
+}
+
+// GetChildren 获取直接子节点，id 为0时获取根节点
+func (t *Tree[T]) GetChildren(id uint) ([]T, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	list := make([]T, 0)
+	err := t.query().Where(t.opt.ParentColumn+" = ?", id).Find(&list).Error
+	return list, err
+}
+
+// GetDescendants 获取所有子孙节点（不含自身），id 为0时获取全部节点
+func (t *Tree[T]) GetDescendants(id uint) ([]T, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	list := make([]T, 0)
+	if id == 0 {
+		return list, t.query().Find(&list).Error
+	}
+
+	if t.path != nil {
+		node, err := t.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		prefix := t.pathOf(node) + strconv.FormatUint(uint64(id), 10) + "/"
+		err = t.query().Where(t.opt.PathColumn+" LIKE ?", prefix+"%").Find(&list).Error
+		return list, err
+	}
+
+	parents := []uint{id}
+	for depth := 0; len(parents) > 0 && depth < maxDepth; depth++ {
+		var level []T
+		if err := t.query().Where(t.opt.ParentColumn+" IN ?", parents).Find(&level).Error; err != nil {
+			return nil, err
+		}
+		parents = parents[:0]
+		for i := range level {
+			parents = append(parents, t.idOf(&level[i]))
+		}
+		list = append(list, level...)
+	}
+	return list, nil
+}
+
+// GetAncestors 获取所有祖先节点，按从根节点到上级节点的顺序排列
+func (t *Tree[T]) GetAncestors(id uint) ([]T, error) {
+	node, err := t.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.path != nil {
+		ids := parsePath(t.pathOf(node))
+		if len(ids) == 0 {
+			return make([]T, 0), nil
+		}
+		var found []T
+		if err = t.db.Model(new(T)).Where(t.opt.IDColumn+" IN ?", ids).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]T, len(found))
+		for i := range found {
+			byID[t.idOf(&found[i])] = found[i]
+		}
+		list := make([]T, 0, len(ids))
+		for _, ancestorID := range ids {
+			if ancestor, ok := byID[ancestorID]; ok {
+				list = append(list, ancestor)
+			}
+		}
+		return list, nil
+	}
+
+	var list []T
+	for parentID, depth := t.parentOf(node), 0; parentID > 0 && depth < maxDepth; depth++ {
+		parent, err := t.Get(parentID)
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append([]T{*parent}, list...)
+		parentID = t.parentOf(parent)
+	}
+	if list == nil {
+		list = make([]T, 0)
+	}
+	return list, nil
+}
+
+// Create 新增节点，根据上级节点自动设置路径及层级
+func (t *Tree[T]) Create(node *T) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	path, level, err := t.positionUnder(t.parentOf(node))
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(node).Elem()
+	if t.path != nil {
+		if err = t.path.Set(t.db.Statement.Context, rv, path); err != nil {
+			return err
+		}
+	}
+	if t.level != nil {
+		if err = t.level.Set(t.db.Statement.Context, rv, level); err != nil {
+			return err
+		}
+	}
+	return t.db.Create(node).Error
+}
+
+// MoveNode 将节点（连同子孙节点）移动到新的上级节点下，parentID 为0时移动为根节点
+func (t *Tree[T]) MoveNode(id, parentID uint) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	if id == parentID {
+		return ErrCycle
+	}
+	node, err := t.Get(id)
+	if err != nil {
+		return err
+	}
+	descendants, err := t.GetDescendants(id)
+	if err != nil {
+		return err
+	}
+	for i := range descendants {
+		if t.idOf(&descendants[i]) == parentID {
+			return ErrCycle
+		}
+	}
+
+	path, level, err := t.positionUnder(parentID)
+	if err != nil {
+		return err
+	}
+	oldPrefix := t.pathOf(node) + strconv.FormatUint(uint64(id), 10) + "/"
+	newPrefix := path + strconv.FormatUint(uint64(id), 10) + "/"
+	levelDelta := level - t.levelOf(node)
+
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		values := map[string]any{t.opt.ParentColumn: parentID}
+		if t.path != nil {
+			values[t.opt.PathColumn] = path
+		}
+		if t.level != nil {
+			values[t.opt.LevelColumn] = level
+		}
+		if err := tx.Model(new(T)).Where(t.opt.IDColumn+" = ?", id).Updates(values).Error; err != nil {
+			return err
+		}
+		if t.path == nil && t.level == nil {
+			return nil
+		}
+
+		for i := range descendants {
+			values := map[string]any{}
+			if t.path != nil {
+				values[t.opt.PathColumn] = newPrefix + strings.TrimPrefix(t.pathOf(&descendants[i]), oldPrefix)
+			}
+			if t.level != nil {
+				values[t.opt.LevelColumn] = t.levelOf(&descendants[i]) + levelDelta
+			}
+			err := tx.Model(new(T)).Where(t.opt.IDColumn+" = ?", t.idOf(&descendants[i])).Updates(values).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Rebuild 根据 parent_id 重新计算所有节点的路径及层级，用于导入数据或修复数据后
+// 上级节点不存在的节点视为根节点
+func (t *Tree[T]) Rebuild() error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	if t.path == nil && t.level == nil {
+		return nil
+	}
+	var all []T
+	if err := t.db.Model(new(T)).Find(&all).Error; err != nil {
+		return err
+	}
+
+	exists := make(map[uint]bool, len(all))
+	children := make(map[uint][]int)
+	for i := range all {
+		exists[t.idOf(&all[i])] = true
+	}
+	for i := range all {
+		parentID := t.parentOf(&all[i])
+		if !exists[parentID] {
+			parentID = 0
+		}
+		children[parentID] = append(children[parentID], i)
+	}
+
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		type position struct {
+			id    uint
+			path  string
+			level int
+		}
+		queue := []position{{id: 0, path: "", level: 0}}
+		for len(queue) > 0 {
+			parent := queue[0]
+			queue = queue[1:]
+			path := "/"
+			if parent.id > 0 {
+				path = parent.path + strconv.FormatUint(uint64(parent.id), 10) + "/"
+			}
+			for _, i := range children[parent.id] {
+				node := &all[i]
+				id := t.idOf(node)
+				values := map[string]any{}
+				if t.path != nil && t.pathOf(node) != path {
+					values[t.opt.PathColumn] = path
+				}
+				if t.level != nil && t.levelOf(node) != parent.level+1 {
+					values[t.opt.LevelColumn] = parent.level + 1
+				}
+				if len(values) > 0 {
+					if err := tx.Model(new(T)).Where(t.opt.IDColumn+" = ?", id).Updates(values).Error; err != nil {
+						return err
+					}
+				}
+				queue = append(queue, position{id: id, path: path, level: parent.level + 1})
+			}
+		}
+		return nil
+	})
+}
+
+// Tree 获取 id 下的子孙节点并构建为嵌套结构，id 为0时构建完整的树
+func (t *Tree[T]) Tree(id uint, opts ...BuildOptions) ([]map[string]any, error) {
+	list, err := t.GetDescendants(id)
+	if err != nil {
+		return nil, err
+	}
+	var opt BuildOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.RootID = id
+	return BuildTree(list, opt), nil
+}
+
+// positionUnder 获取 parentID 下子节点的路径及层级
+func (t *Tree[T]) positionUnder(parentID uint) (string, int, error) {
+	if parentID == 0 {
+		return "/", 1, nil
+	}
+	parent, err := t.Get(parentID)
+	if err != nil {
+		return "", 0, fmt.Errorf("tree: 上级节点 %d 不存在", parentID)
+	}
+	return t.pathOf(parent) + strconv.FormatUint(uint64(parentID), 10) + "/", t.levelOf(parent) + 1, nil
+}
+
+func (t *Tree[T]) idOf(node *T) uint {
+	return t.uintOf(t.id, node)
+}
+
+func (t *Tree[T]) parentOf(node *T) uint {
+	return t.uintOf(t.parent, node)
+}
+
+func (t *Tree[T]) pathOf(node *T) string {
+	if t.path == nil {
+		return ""
+	}
+	v, _ := t.path.ValueOf(t.db.Statement.Context, reflect.ValueOf(node).Elem())
+	path, _ := v.(string)
+	if path == "" {
+		path = "/"
+	}
+	return path
+}
+
+func (t *Tree[T]) levelOf(node *T) int {
+	if t.level == nil {
+		return 0
+	}
+	v, _ := t.level.ValueOf(t.db.Statement.Context, reflect.ValueOf(node).Elem())
+	return helper.Convert{Value: v}.ToInt()
+}
+
+func (t *Tree[T]) uintOf(field *schema.Field, node *T) uint {
+	v, _ := field.ValueOf(t.db.Statement.Context, reflect.ValueOf(node).Elem())
+	return helper.Convert{Value: v}.ToUint()
+}
+
+// parsePath 解析路径中的ID
+func parsePath(path string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if id, err := strconv.ParseUint(part, 10, 64); err == nil && id > 0 {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,326 @@
+package payment
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/helper/collection"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"github.com/jcbowen/jcbaseGo/component/security"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 支付宝网关地址
+const (
+	AlipayGateway        = "https://openapi.alipay.com/gateway.do"
+	AlipaySandboxGateway = "https://openapi-sandbox.dl.alipaydev.com/gateway.do"
+)
+
+// Alipay 支付宝（RSA2签名）
+type Alipay struct {
+	Conf jcbaseGo.AlipayStruct
+	HTTP *httpclient.Client
+
+	privateKey      *rsa.PrivateKey
+	alipayPublicKey *rsa.PublicKey
+}
+
+// NewAlipay 创建支付宝实例
+func NewAlipay(conf jcbaseGo.AlipayStruct) *Alipay {
+	_ = helper.CheckAndSetDefault(&conf)
+
+	privateKey, err := security.ParseRSAPrivateKey(conf.PrivateKey)
+	jcbaseGo.PanicIfError(err)
+	alipayPublicKey, err := security.ParseRSAPublicKey(conf.AlipayPublicKey)
+	jcbaseGo.PanicIfError(err)
+
+	return &Alipay{
+		Conf:            conf,
+		HTTP:            httpclient.New(httpclient.Options{Timeout: 10 * time.Second}),
+		privateKey:      privateKey,
+		alipayPublicKey: alipayPublicKey,
+	}
+}
+
+// Gateway 当前环境的网关地址
+func (a *Alipay) Gateway() string {
+	if a.Conf.Sandbox {
+		return AlipaySandboxGateway
+	}
+	return AlipayGateway
+}
+
+// CreateOrder 下单，支持 page/h5/app/native/jsapi
+func (a *Alipay) CreateOrder(tradeType string, order Order) (*PrepayResult, error) {
+	biz := map[string]any{
+		"out_trade_no": order.OutTradeNo,
+		"total_amount": FenToYuan(order.Amount),
+		"subject":      order.Description,
+	}
+	if order.Attach != "" {
+		biz["passback_params"] = url.QueryEscape(order.Attach)
+	}
+	if !order.ExpireAt.IsZero() {
+		biz["time_expire"] = order.ExpireAt.Format("2006-01-02 15:04:05")
+	}
+
+	switch tradeType {
+	case TradePage:
+		biz["product_code"] = "FAST_INSTANT_TRADE_PAY"
+		payURL, err := a.PageURL("alipay.trade.page.pay", biz)
+		return &PrepayResult{PayURL: payURL}, err
+	case TradeH5:
+		biz["product_code"] = "QUICK_WAP_WAY"
+		payURL, err := a.PageURL("alipay.trade.wap.pay", biz)
+		return &PrepayResult{PayURL: payURL}, err
+	case TradeApp:
+		biz["product_code"] = "QUICK_MSECURITY_PAY"
+		params, err := a.buildParams("alipay.trade.app.pay", biz)
+		if err != nil {
+			return nil, err
+		}
+		return &PrepayResult{OrderStr: params.Encode()}, nil
+	case TradeNative:
+		var result struct {
+			QRCode string `json:"qr_code"`
+		}
+		err := a.Execute("alipay.trade.precreate", biz, &result)
+		return &PrepayResult{CodeURL: result.QRCode}, err
+	case TradeJSAPI:
+		biz["buyer_id"] = order.OpenID
+		biz["product_code"] = "JSAPI_PAY"
+		var result struct {
+			TradeNo string `json:"trade_no"`
+		}
+		err := a.Execute("alipay.trade.create", biz, &result)
+		return &PrepayResult{PrepayID: result.TradeNo}, err
+	default:
+		return nil, fmt.Errorf("payment: 支付宝不支持的支付方式 %s", tradeType)
+	}
+}
+
+// QueryOrder 根据商户订单号查询订单
+func (a *Alipay) QueryOrder(outTradeNo string) (map[string]any, error) {
+	result := make(map[string]any)
+	err := a.Execute("alipay.trade.query", map[string]any{"out_trade_no": outTradeNo}, &result)
+	return result, err
+}
+
+// CloseOrder 关闭订单
+func (a *Alipay) CloseOrder(outTradeNo string) error {
+	return a.Execute("alipay.trade.close", map[string]any{"out_trade_no": outTradeNo}, nil)
+}
+
+// Refund 申请退款
+func (a *Alipay) Refund(req RefundRequest) (*RefundResult, error) {
+	biz := map[string]any{
+		"out_trade_no":   req.OutTradeNo,
+		"out_request_no": req.OutRefundNo,
+		"refund_amount":  FenToYuan(req.Amount),
+	}
+	if req.Reason != "" {
+		biz["refund_reason"] = req.Reason
+	}
+
+	var result struct {
+		TradeNo    string `json:"trade_no"`
+		FundChange string `json:"fund_change"`
+		RefundFee  string `json:"refund_fee"`
+	}
+	if err := a.Execute("alipay.trade.refund", biz, &result); err != nil {
+		return nil, err
+	}
+	status := "PROCESSING"
+	if result.FundChange == "Y" {
+		status = "SUCCESS"
+	}
+	return &RefundResult{RefundID: result.TradeNo, Status: status, Amount: YuanToFen(result.RefundFee)}, nil
+}
+
+// ParseNotify 验签并解析异步通知
+func (a *Alipay) ParseNotify(r *http.Request) (*Notification, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	form := r.PostForm
+	if len(form) == 0 {
+		form = r.Form
+	}
+	if err := a.VerifyParams(form); err != nil {
+		return nil, err
+	}
+	if form.Get("app_id") != a.Conf.AppID {
+		return nil, errors.New("payment: 支付宝通知AppID不匹配")
+	}
+
+	raw := make(map[string]any, len(form))
+	for key := range form {
+		raw[key] = form.Get(key)
+	}
+	attach, _ := url.QueryUnescape(form.Get("passback_params"))
+	status := form.Get("trade_status")
+
+	return &Notification{
+		OutTradeNo: form.Get("out_trade_no"),
+		TradeNo:    form.Get("trade_no"),
+		Amount:     YuanToFen(form.Get("total_amount")),
+		Status:     status,
+		Paid:       status == "TRADE_SUCCESS" || status == "TRADE_FINISHED",
+		Attach:     attach,
+		PaidAt:     form.Get("gmt_payment"),
+		Raw:        raw,
+	}, nil
+}
+
+// NotifySuccess 应答通知处理成功
+func (a *Alipay) NotifySuccess(c *gin.Context) {
+	c.String(http.StatusOK, "success")
+}
+
+// NotifyFail 应答通知处理失败
+func (a *Alipay) NotifyFail(c *gin.Context, _ string) {
+	c.String(http.StatusOK, "fail")
+}
+
+// PageURL 生成需要跳转的支付地址（网页/手机网站支付）
+func (a *Alipay) PageURL(method string, biz map[string]any) (string, error) {
+	params, err := a.buildParams(method, biz)
+	if err != nil {
+		return "", err
+	}
+	return a.Gateway() + "?" + params.Encode(), nil
+}
+
+// Execute 调用支付宝接口，校验应答签名并将业务结果解析到result中
+func (a *Alipay) Execute(method string, biz map[string]any, result any) error {
+	params, err := a.buildParams(method, biz)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.HTTP.R().
+		SetBody([]byte(params.Encode()), "application/x-www-form-urlencoded;charset=utf-8").
+		Post(a.Gateway())
+	if err != nil {
+		return err
+	}
+
+	// 保留原始字节用于验签
+	var envelope map[string]json.RawMessage
+	if err = json.Unmarshal(resp.Body, &envelope); err != nil {
+		return fmt.Errorf("payment: 解析支付宝应答失败: %v", err)
+	}
+	responseKey := strings.ReplaceAll(method, ".", "_") + "_response"
+	content, ok := envelope[responseKey]
+	if !ok {
+		content = envelope["error_response"]
+	}
+
+	var common struct {
+		Code    string `json:"code"`
+		Msg     string `json:"msg"`
+		SubCode string `json:"sub_code"`
+		SubMsg  string `json:"sub_msg"`
+	}
+	if err = json.Unmarshal(content, &common); err != nil {
+		return fmt.Errorf("payment: 解析支付宝应答失败: %v", err)
+	}
+	if common.Code != "10000" {
+		return fmt.Errorf("payment: 支付宝接口错误: %s %s %s %s", common.Code, common.Msg, common.SubCode, common.SubMsg)
+	}
+
+	var sign string
+	_ = json.Unmarshal(envelope["sign"], &sign)
+	if err = a.verify(string(content), sign); err != nil {
+		return err
+	}
+
+	if result != nil {
+		return json.Unmarshal(content, result)
+	}
+	return nil
+}
+
+// VerifyParams 校验通知或同步跳转参数中的签名
+func (a *Alipay) VerifyParams(params url.Values) error {
+	sign := params.Get("sign")
+	if sign == "" {
+		return errors.New("payment: 缺少支付宝签名")
+	}
+	return a.verify(signContent(params, "sign", "sign_type"), sign)
+}
+
+// buildParams 组装公共参数并签名
+func (a *Alipay) buildParams(method string, biz map[string]any) (url.Values, error) {
+	bizContent, err := json.Marshal(biz)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("app_id", a.Conf.AppID)
+	params.Set("method", method)
+	params.Set("format", "JSON")
+	params.Set("charset", "utf-8")
+	params.Set("sign_type", "RSA2")
+	params.Set("timestamp", time.Now().Format("2006-01-02 15:04:05"))
+	params.Set("version", "1.0")
+	params.Set("biz_content", string(bizContent))
+	if a.Conf.NotifyURL != "" {
+		params.Set("notify_url", a.Conf.NotifyURL)
+	}
+	if a.Conf.ReturnURL != "" && (method == "alipay.trade.page.pay" || method == "alipay.trade.wap.pay") {
+		params.Set("return_url", a.Conf.ReturnURL)
+	}
+
+	hashed := sha256.Sum256([]byte(signContent(params, "sign")))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return nil, err
+	}
+	params.Set("sign", base64.StdEncoding.EncodeToString(signature))
+	return params, nil
+}
+
+// verify 使用支付宝公钥验签
+func (a *Alipay) verify(content, sign string) error {
+	sig, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil {
+		return err
+	}
+	hashed := sha256.Sum256([]byte(content))
+	if err = rsa.VerifyPKCS1v15(a.alipayPublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+		return errors.New("payment: 支付宝签名校验失败")
+	}
+	return nil
+}
+
+// signContent 按参数名排序拼接待签名字符串，空值及排除的参数不参与签名
+func signContent(params url.Values, excludes ...string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if params.Get(key) == "" || collection.Contains(excludes, key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+params.Get(key))
+	}
+	return strings.Join(pairs, "&")
+}
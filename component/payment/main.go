@@ -0,0 +1,125 @@
+package payment
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"log"
+	"math"
+	"net/http"
+	"time"
+)
+
+// 支付方式
+const (
+	TradeJSAPI  = "jsapi"  // 公众号/小程序支付（支付宝为JSAPI支付，需要买家ID）
+	TradeNative = "native" // 扫码支付（支付宝为当面付预下单）
+	TradeApp    = "app"    // APP支付
+	TradeH5     = "h5"     // 手机网页支付
+	TradePage   = "page"   // 电脑网站支付，仅支付宝
+)
+
+// Order 下单参数，金额单位统一为分
+type Order struct {
+	OutTradeNo  string    // 商户订单号
+	Description string    // 商品描述
+	Amount      int64     // 订单金额，单位分
+	OpenID      string    // 付款人标识，微信JSAPI为openid，支付宝JSAPI为buyer_id
+	ClientIP    string    // 用户IP，微信H5支付必填
+	Attach      string    // 附加数据，在通知中原样返回
+	ExpireAt    time.Time // 订单失效时间，零值表示使用平台默认值
+}
+
+// PrepayResult 下单结果，不同支付方式返回不同字段
+type PrepayResult struct {
+	PrepayID  string            `json:"prepay_id,omitempty"`  // 微信预支付交易会话标识
+	CodeURL   string            `json:"code_url,omitempty"`   // 扫码支付的二维码链接
+	PayURL    string            `json:"pay_url,omitempty"`    // 需要跳转的支付地址（微信H5、支付宝网页支付）
+	PayParams map[string]string `json:"pay_params,omitempty"` // 前端调起支付所需参数（微信JSAPI/APP）
+	OrderStr  string            `json:"order_str,omitempty"`  // 支付宝APP支付的订单字符串
+}
+
+// RefundRequest 退款参数，金额单位为分
+type RefundRequest struct {
+	OutTradeNo  string // 商户订单号
+	OutRefundNo string // 商户退款单号
+	Amount      int64  // 退款金额
+	Total       int64  // 原订单金额，微信支付必填
+	Reason      string // 退款原因
+}
+
+// RefundResult 退款结果
+type RefundResult struct {
+	RefundID string `json:"refund_id"` // 平台退款单号
+	Status   string `json:"status"`    // 平台返回的退款状态
+	Amount   int64  `json:"amount"`    // 退款金额，单位分
+}
+
+// Notification 已验签的支付结果通知
+type Notification struct {
+	OutTradeNo string         `json:"out_trade_no"` // 商户订单号
+	TradeNo    string         `json:"trade_no"`     // 平台交易号
+	Amount     int64          `json:"amount"`       // 支付金额，单位分
+	Status     string         `json:"status"`       // 平台返回的交易状态
+	Paid       bool           `json:"paid"`         // 是否支付成功
+	Attach     string         `json:"attach"`       // 附加数据
+	PaidAt     string         `json:"paid_at"`      // 支付时间
+	Raw        map[string]any `json:"raw"`          // 通知原始数据（已解密）
+}
+
+// Provider 支付渠道
+type Provider interface {
+	// CreateOrder 下单
+	CreateOrder(tradeType string, order Order) (*PrepayResult, error)
+	// Refund 申请退款
+	Refund(req RefundRequest) (*RefundResult, error)
+	// ParseNotify 验签并解析支付结果通知
+	ParseNotify(r *http.Request) (*Notification, error)
+	// NotifySuccess 输出处理成功的应答
+	NotifySuccess(c *gin.Context)
+	// NotifyFail 输出处理失败的应答，平台会稍后重新通知
+	NotifyFail(c *gin.Context, message string)
+}
+
+// NotifyHandler 返回处理支付结果通知的gin处理器，
+// handle 返回nil时应答成功，否则应答失败等待平台重试，handle 需要自行保证幂等
+//
+// 示例:
+//
+//	r.POST("/notify/wechat", payment.NotifyHandler(wxPay, func(n *payment.Notification) error {
+//	    if !n.Paid {
+//	        return nil
+//	    }
+//	    return orderService.MarkPaid(n.OutTradeNo, n.TradeNo, n.Amount)
+//	}))
+func NotifyHandler(p Provider, handle func(n *Notification) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		notification, err := p.ParseNotify(c.Request)
+		if err != nil {
+			log.Println("支付通知验签失败:", err)
+			p.NotifyFail(c, "invalid notification")
+			return
+		}
+		if err = handle(notification); err != nil {
+			log.Println("支付通知处理失败:", err)
+			p.NotifyFail(c, err.Error())
+			return
+		}
+		p.NotifySuccess(c)
+	}
+}
+
+// FenToYuan 将金额由分转为元（字符串，保留两位小数）
+func FenToYuan(fen int64) string {
+	sign := ""
+	if fen < 0 {
+		sign = "-"
+		fen = -fen
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, fen/100, fen%100)
+}
+
+// YuanToFen 将金额由元（字符串）转为分
+func YuanToFen(yuan string) int64 {
+	return int64(math.Round(helper.Convert{Value: yuan}.ToFloat64() * 100))
+}
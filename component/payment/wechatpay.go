@@ -0,0 +1,356 @@
+package payment
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"github.com/jcbowen/jcbaseGo/component/security"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// WechatPayBaseURL 微信支付v3接口地址
+const WechatPayBaseURL = "https://api.mch.weixin.qq.com"
+
+// WechatPay 微信支付v3
+type WechatPay struct {
+	Conf jcbaseGo.WechatPayStruct
+	HTTP *httpclient.Client
+
+	privateKey        *rsa.PrivateKey
+	platformPublicKey *rsa.PublicKey
+}
+
+// NewWechatPay 创建微信支付实例
+func NewWechatPay(conf jcbaseGo.WechatPayStruct) *WechatPay {
+	_ = helper.CheckAndSetDefault(&conf)
+
+	privateKey, err := security.ParseRSAPrivateKey(conf.PrivateKey)
+	jcbaseGo.PanicIfError(err)
+	platformPublicKey, err := security.ParseRSAPublicKey(conf.PlatformPublicKey)
+	jcbaseGo.PanicIfError(err)
+	if len(conf.APIv3Key) != 32 {
+		jcbaseGo.PanicIfError(errors.New("payment: 微信支付APIv3密钥长度必须为32位"))
+	}
+
+	return &WechatPay{
+		Conf:              conf,
+		HTTP:              httpclient.New(httpclient.Options{BaseURL: WechatPayBaseURL, Timeout: 10 * time.Second}),
+		privateKey:        privateKey,
+		platformPublicKey: platformPublicKey,
+	}
+}
+
+// CreateOrder 下单，支持 jsapi/native/app/h5
+func (w *WechatPay) CreateOrder(tradeType string, order Order) (*PrepayResult, error) {
+	body := map[string]any{
+		"appid":        w.Conf.AppID,
+		"mchid":        w.Conf.MchID,
+		"description":  order.Description,
+		"out_trade_no": order.OutTradeNo,
+		"notify_url":   w.Conf.NotifyURL,
+		"amount":       map[string]any{"total": order.Amount, "currency": "CNY"},
+	}
+	if order.Attach != "" {
+		body["attach"] = order.Attach
+	}
+	if !order.ExpireAt.IsZero() {
+		body["time_expire"] = order.ExpireAt.Format(time.RFC3339)
+	}
+
+	switch tradeType {
+	case TradeJSAPI:
+		body["payer"] = map[string]any{"openid": order.OpenID}
+	case TradeH5:
+		body["scene_info"] = map[string]any{
+			"payer_client_ip": order.ClientIP,
+			"h5_info":         map[string]any{"type": "Wap"},
+		}
+	case TradeNative, TradeApp:
+	default:
+		return nil, fmt.Errorf("payment: 微信支付不支持的支付方式 %s", tradeType)
+	}
+
+	var result struct {
+		PrepayID string `json:"prepay_id"`
+		CodeURL  string `json:"code_url"`
+		H5URL    string `json:"h5_url"`
+	}
+	if err := w.request(http.MethodPost, "/v3/pay/transactions/"+tradeType, body, &result); err != nil {
+		return nil, err
+	}
+
+	prepay := &PrepayResult{PrepayID: result.PrepayID, CodeURL: result.CodeURL, PayURL: result.H5URL}
+	switch tradeType {
+	case TradeJSAPI:
+		params, err := w.JSAPIParams(result.PrepayID)
+		if err != nil {
+			return nil, err
+		}
+		prepay.PayParams = params
+	case TradeApp:
+		params, err := w.AppParams(result.PrepayID)
+		if err != nil {
+			return nil, err
+		}
+		prepay.PayParams = params
+	}
+	return prepay, nil
+}
+
+// JSAPIParams 生成公众号/小程序调起支付的参数
+func (w *WechatPay) JSAPIParams(prepayID string) (map[string]string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := helper.Random(32)
+	pkg := "prepay_id=" + prepayID
+	sign, err := w.sign(w.Conf.AppID + "\n" + timestamp + "\n" + nonce + "\n" + pkg + "\n")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"appId":     w.Conf.AppID,
+		"timeStamp": timestamp,
+		"nonceStr":  nonce,
+		"package":   pkg,
+		"signType":  "RSA",
+		"paySign":   sign,
+	}, nil
+}
+
+// AppParams 生成APP调起支付的参数
+func (w *WechatPay) AppParams(prepayID string) (map[string]string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := helper.Random(32)
+	sign, err := w.sign(w.Conf.AppID + "\n" + timestamp + "\n" + nonce + "\n" + prepayID + "\n")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"appid":     w.Conf.AppID,
+		"partnerid": w.Conf.MchID,
+		"prepayid":  prepayID,
+		"package":   "Sign=WXPay",
+		"noncestr":  nonce,
+		"timestamp": timestamp,
+		"sign":      sign,
+	}, nil
+}
+
+// QueryOrder 根据商户订单号查询订单
+func (w *WechatPay) QueryOrder(outTradeNo string) (map[string]any, error) {
+	result := make(map[string]any)
+	path := "/v3/pay/transactions/out-trade-no/" + url.PathEscape(outTradeNo) + "?mchid=" + url.QueryEscape(w.Conf.MchID)
+	err := w.request(http.MethodGet, path, nil, &result)
+	return result, err
+}
+
+// CloseOrder 关闭订单
+func (w *WechatPay) CloseOrder(outTradeNo string) error {
+	path := "/v3/pay/transactions/out-trade-no/" + url.PathEscape(outTradeNo) + "/close"
+	return w.request(http.MethodPost, path, map[string]any{"mchid": w.Conf.MchID}, nil)
+}
+
+// Refund 申请退款
+func (w *WechatPay) Refund(req RefundRequest) (*RefundResult, error) {
+	body := map[string]any{
+		"out_trade_no":  req.OutTradeNo,
+		"out_refund_no": req.OutRefundNo,
+		"amount":        map[string]any{"refund": req.Amount, "total": req.Total, "currency": "CNY"},
+	}
+	if req.Reason != "" {
+		body["reason"] = req.Reason
+	}
+	if w.Conf.RefundNotifyURL != "" {
+		body["notify_url"] = w.Conf.RefundNotifyURL
+	}
+
+	var result struct {
+		RefundID string `json:"refund_id"`
+		Status   string `json:"status"`
+		Amount   struct {
+			Refund int64 `json:"refund"`
+		} `json:"amount"`
+	}
+	if err := w.request(http.MethodPost, "/v3/refund/domestic/refunds", body, &result); err != nil {
+		return nil, err
+	}
+	return &RefundResult{RefundID: result.RefundID, Status: result.Status, Amount: result.Amount.Refund}, nil
+}
+
+// ParseNotify 验签并解密支付结果通知
+func (w *WechatPay) ParseNotify(r *http.Request) (*Notification, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err = w.verify(r.Header, body); err != nil {
+		return nil, err
+	}
+
+	var envelope struct {
+		EventType string `json:"event_type"`
+		Resource  struct {
+			Ciphertext     string `json:"ciphertext"`
+			Nonce          string `json:"nonce"`
+			AssociatedData string `json:"associated_data"`
+		} `json:"resource"`
+	}
+	if err = json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	plain, err := w.DecryptResource(envelope.Resource.Ciphertext, envelope.Resource.Nonce, envelope.Resource.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	var resource struct {
+		OutTradeNo    string `json:"out_trade_no"`
+		TransactionID string `json:"transaction_id"`
+		TradeState    string `json:"trade_state"`
+		Attach        string `json:"attach"`
+		SuccessTime   string `json:"success_time"`
+		Amount        struct {
+			Total int64 `json:"total"`
+		} `json:"amount"`
+	}
+	if err = json.Unmarshal(plain, &resource); err != nil {
+		return nil, err
+	}
+	raw := make(map[string]any)
+	_ = json.Unmarshal(plain, &raw)
+
+	return &Notification{
+		OutTradeNo: resource.OutTradeNo,
+		TradeNo:    resource.TransactionID,
+		Amount:     resource.Amount.Total,
+		Status:     resource.TradeState,
+		Paid:       resource.TradeState == "SUCCESS",
+		Attach:     resource.Attach,
+		PaidAt:     resource.SuccessTime,
+		Raw:        raw,
+	}, nil
+}
+
+// NotifySuccess 应答通知处理成功
+func (w *WechatPay) NotifySuccess(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"code": "SUCCESS", "message": "成功"})
+}
+
+// NotifyFail 应答通知处理失败
+func (w *WechatPay) NotifyFail(c *gin.Context, message string) {
+	c.JSON(http.StatusInternalServerError, gin.H{"code": "FAIL", "message": message})
+}
+
+// DecryptResource 使用APIv3密钥解密回调通知中的resource（AEAD_AES_256_GCM）
+func (w *WechatPay) DecryptResource(ciphertext, nonce, associatedData string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher([]byte(w.Conf.APIv3Key))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, []byte(nonce), data, []byte(associatedData))
+}
+
+// request 调用微信支付接口
+func (w *WechatPay) request(method, path string, body any, result any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := helper.Random(32)
+	signature, err := w.sign(method + "\n" + path + "\n" + timestamp + "\n" + nonce + "\n" + string(payload) + "\n")
+	if err != nil {
+		return err
+	}
+	authorization := fmt.Sprintf(`WECHATPAY2-SHA256-RSA2048 mchid="%s",nonce_str="%s",signature="%s",timestamp="%s",serial_no="%s"`,
+		w.Conf.MchID, nonce, signature, timestamp, w.Conf.SerialNo)
+
+	req := w.HTTP.R().
+		SetHeader("Authorization", authorization).
+		SetHeader("Accept", "application/json")
+	if payload != nil {
+		req.SetBody(payload, "application/json")
+	}
+	resp, err := req.Do(method, path)
+	if err != nil {
+		return err
+	}
+
+	if !resp.IsSuccess() {
+		var apiErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		_ = resp.JSON(&apiErr)
+		return fmt.Errorf("payment: 微信支付接口错误(%d): %s %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+	}
+	if err = w.verify(resp.Header, resp.Body); err != nil {
+		return err
+	}
+	if result != nil && len(resp.Body) > 0 {
+		return resp.JSON(result)
+	}
+	return nil
+}
+
+// sign 使用商户私钥进行SHA256withRSA签名
+func (w *WechatPay) sign(message string) (string, error) {
+	hashed := sha256.Sum256([]byte(message))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, w.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// verify 使用平台公钥校验应答或通知的签名
+func (w *WechatPay) verify(header http.Header, body []byte) error {
+	timestamp := header.Get("Wechatpay-Timestamp")
+	nonce := header.Get("Wechatpay-Nonce")
+	signature := header.Get("Wechatpay-Signature")
+	if timestamp == "" || nonce == "" || signature == "" {
+		return errors.New("payment: 缺少微信支付签名")
+	}
+
+	// 拒绝超过5分钟的请求，防止重放
+	ts, _ := strconv.ParseInt(timestamp, 10, 64)
+	if diff := time.Now().Unix() - ts; diff > 300 || diff < -300 {
+		return errors.New("payment: 微信支付签名已过期")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return err
+	}
+	hashed := sha256.Sum256([]byte(timestamp + "\n" + nonce + "\n" + string(body) + "\n"))
+	if err = rsa.VerifyPKCS1v15(w.platformPublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+		return errors.New("payment: 微信支付签名校验失败")
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jung-kurt/gofpdf"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// fontFamily 注册的中文字体名称
+const fontFamily = "jcbase-cjk"
+
+// Document 基于结构化数据生成PDF，纯Go实现，不依赖外部程序，适用于发票、对账单、报表等版式固定的文档
+//
+// 示例:
+//
+//	doc := pdf.New(jcbaseGo.PDFStruct{FontPath: "./fonts/NotoSansSC-Regular.ttf"})
+//	doc.Header = "XX科技有限公司"
+//	doc.Title("销售发票").
+//	    KeyValues([][2]string{{"发票号", "INV-0001"}, {"日期", "2024-01-01"}}).
+//	    Table(pdf.Table{
+//	        Columns: []pdf.TableColumn{{Title: "商品", Width: 3}, {Title: "数量", Align: "R"}, {Title: "金额", Align: "R"}},
+//	        Rows:    [][]string{{"键盘", "1", "199.00"}},
+//	        Footer:  []string{"合计", "", "199.00"},
+//	    }).
+//	    Text("备注：请于30日内付款")
+//	err := doc.Save("./runtime/invoice.pdf")
+type Document struct {
+	Conf   jcbaseGo.PDFStruct
+	Header string // 页眉文字，为空时不显示
+	Footer bool   // 是否显示页码
+
+	pdf        *gofpdf.Fpdf
+	family     string
+	translator func(string) string
+	started    bool
+}
+
+// New 创建PDF文档，配置了 FontPath 时注册为中文字体，否则只能输出西文字符
+func New(conf jcbaseGo.PDFStruct) *Document {
+	_ = helper.CheckAndSetDefault(&conf)
+	d := &Document{
+		Conf:   conf,
+		Footer: true,
+		pdf:    gofpdf.New(conf.Orientation, "mm", conf.PageSize, ""),
+		family: "Helvetica",
+	}
+	d.pdf.SetCreator("jcbaseGo", true)
+	d.pdf.SetAutoPageBreak(true, 15)
+	d.pdf.AliasNbPages("{nb}")
+
+	if conf.FontPath != "" {
+		bold := conf.BoldFontPath
+		if bold == "" {
+			bold = conf.FontPath
+		}
+		d.addFont("", conf.FontPath)
+		d.addFont("B", bold)
+		d.family = fontFamily
+	} else {
+		d.translator = d.pdf.UnicodeTranslatorFromDescriptor("")
+	}
+	return d
+}
+
+// Fpdf 获取底层的 gofpdf 实例，用于绘制内置方法不支持的内容
+func (d *Document) Fpdf() *gofpdf.Fpdf {
+	d.start()
+	return d.pdf
+}
+
+// SetTitle 设置文档属性中的标题及作者
+func (d *Document) SetTitle(title, author string) *Document {
+	d.pdf.SetTitle(title, true)
+	if author != "" {
+		d.pdf.SetAuthor(author, true)
+	}
+	return d
+}
+
+// Title 输出居中的大标题
+func (d *Document) Title(text string) *Document {
+	d.start()
+	d.font("B", 18)
+	d.pdf.CellFormat(0, 12, d.text(text), "", 1, "C", false, 0, "")
+	d.pdf.Ln(4)
+	return d
+}
+
+// Heading 输出小标题
+func (d *Document) Heading(text string) *Document {
+	d.start()
+	d.pdf.Ln(2)
+	d.font("B", 13)
+	d.pdf.CellFormat(0, 9, d.text(text), "", 1, "L", false, 0, "")
+	return d
+}
+
+// Text 输出段落，自动换行
+func (d *Document) Text(text string) *Document {
+	d.start()
+	d.font("", 10.5)
+	for _, line := range d.wrap(text, d.contentWidth()) {
+		d.pdf.CellFormat(0, 6, d.text(line), "", 1, "L", false, 0, "")
+	}
+	return d
+}
+
+// KeyValues 输出两列的键值对，如单据的编号、日期、客户信息
+func (d *Document) KeyValues(items [][2]string) *Document {
+	d.start()
+	width := d.contentWidth()
+	keyWidth := 0.0
+	d.font("B", 10.5)
+	for _, item := range items {
+		if w := d.pdf.GetStringWidth(d.text(item[0])) + 6; w > keyWidth {
+			keyWidth = w
+		}
+	}
+	keyWidth = min(keyWidth, width/2)
+
+	for _, item := range items {
+		lines := d.wrapWith("", 10.5, item[1], width-keyWidth)
+		d.font("B", 10.5)
+		d.pdf.CellFormat(keyWidth, 6, d.text(item[0]), "", 0, "L", false, 0, "")
+		d.font("", 10.5)
+		left, _, _, _ := d.pdf.GetMargins()
+		for i, line := range lines {
+			if i > 0 {
+				d.pdf.SetX(left + keyWidth)
+			}
+			d.pdf.CellFormat(0, 6, d.text(line), "", 1, "L", false, 0, "")
+		}
+	}
+	d.pdf.Ln(2)
+	return d
+}
+
+// Image 输出图片，width 为宽度（毫米），0表示原始宽度，支持 jpg、png、gif
+func (d *Document) Image(path string, width float64) *Document {
+	d.start()
+	d.pdf.ImageOptions(path, -1, -1, width, 0, true, gofpdf.ImageOptions{ReadDpi: true}, 0, "")
+	return d
+}
+
+// Space 输出空白，height 为高度（毫米）
+func (d *Document) Space(height float64) *Document {
+	d.start()
+	d.pdf.Ln(height)
+	return d
+}
+
+// PageBreak 换页
+func (d *Document) PageBreak() *Document {
+	d.start()
+	d.pdf.AddPage()
+	return d
+}
+
+// Output 输出PDF，Output、Bytes、Save 只能调用其中一个且只能调用一次
+func (d *Document) Output(w io.Writer) error {
+	d.start()
+	return d.pdf.Output(w)
+}
+
+// Bytes 获取PDF内容
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Save 保存为文件，目录不存在时自动创建
+func (d *Document) Save(filename string) error {
+	if _, err := helper.NewFile(&helper.File{Path: filename}).DirExists(true); err != nil {
+		return err
+	}
+	d.start()
+	return d.pdf.OutputFileAndClose(filename)
+}
+
+// Err 获取生成过程中的错误，如字体文件不存在
+func (d *Document) Err() error {
+	return d.pdf.Error()
+}
+
+// start 添加第一页，页眉页脚需在添加页面前设置
+func (d *Document) start() {
+	if d.started {
+		return
+	}
+	d.started = true
+	d.pdf.SetHeaderFunc(func() {
+		if d.Header == "" {
+			return
+		}
+		d.font("", 9)
+		d.pdf.SetTextColor(128, 128, 128)
+		d.pdf.CellFormat(0, 6, d.text(d.Header), "B", 1, "R", false, 0, "")
+		d.pdf.SetTextColor(0, 0, 0)
+		d.pdf.Ln(4)
+	})
+	d.pdf.SetFooterFunc(func() {
+		if !d.Footer {
+			return
+		}
+		d.pdf.SetY(-12)
+		d.font("", 9)
+		d.pdf.SetTextColor(128, 128, 128)
+		d.pdf.CellFormat(0, 6, fmt.Sprintf("%d / {nb}", d.pdf.PageNo()), "", 0, "C", false, 0, "")
+		d.pdf.SetTextColor(0, 0, 0)
+	})
+	d.pdf.AddPage()
+}
+
+// addFont 注册TTF字体，读取失败时记录到文档错误中
+func (d *Document) addFont(style, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		d.pdf.SetError(fmt.Errorf("pdf: 读取字体文件失败: %w", err))
+		return
+	}
+	d.pdf.AddUTF8FontFromBytes(fontFamily, style, data)
+}
+
+// font 设置字体
+func (d *Document) font(style string, size float64) {
+	d.pdf.SetFont(d.family, style, size)
+}
+
+// text 未配置中文字体时将UTF-8转换为cp1252编码
+func (d *Document) text(s string) string {
+	if d.translator != nil {
+		return d.translator(s)
+	}
+	return s
+}
+
+// contentWidth 获取页面内容区域宽度
+func (d *Document) contentWidth() float64 {
+	pageWidth, _ := d.pdf.GetPageSize()
+	left, _, right, _ := d.pdf.GetMargins()
+	return pageWidth - left - right
+}
+
+// wrapWith 使用指定字体拆分文本
+func (d *Document) wrapWith(style string, size float64, text string, width float64) []string {
+	d.font(style, size)
+	return d.wrap(text, width)
+}
+
+// wrap 按当前字体将文本拆分为不超过指定宽度的多行，西文优先在空格处换行
+func (d *Document) wrap(text string, width float64) []string {
+	width -= 2 * d.pdf.GetCellMargin()
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		runes := []rune(paragraph)
+		if len(runes) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		start, lastSpace := 0, -1
+		lineWidth := 0.0
+		for i := 0; i < len(runes); i++ {
+			if unicode.IsSpace(runes[i]) {
+				lastSpace = i
+			}
+			lineWidth += d.pdf.GetStringWidth(d.text(string(runes[i])))
+			if lineWidth <= width || i == start {
+				continue
+			}
+			end := i
+			if lastSpace > start && runes[i] < unicode.MaxLatin1 && !unicode.IsSpace(runes[i]) {
+				end = lastSpace + 1
+			}
+			lines = append(lines, strings.TrimRight(string(runes[start:end]), " "))
+			start, lastSpace = end, -1
+			lineWidth = 0
+			i = end - 1
+		}
+		lines = append(lines, string(runes[start:]))
+	}
+	return lines
+}
@@ -0,0 +1,154 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/command"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HTMLOptions HTML转PDF的附加选项
+type HTMLOptions struct {
+	MarginTop    string   // 上边距，如 15mm，默认10mm
+	MarginBottom string   // 下边距，默认10mm
+	MarginLeft   string   // 左边距，默认10mm
+	MarginRight  string   // 右边距，默认10mm
+	FooterCenter string   // 页脚居中文字，支持 [page]、[topage] 占位符，如 "[page] / [topage]"
+	Args         []string // 额外的 wkhtmltopdf 参数
+}
+
+// Renderer 通过 wkhtmltopdf 将HTML转换为PDF，适用于版式复杂、需要CSS排版的文档
+//
+// 示例:
+//
+//	r := pdf.NewRenderer(jcbaseGo.PDFStruct{FontPath: "/usr/share/fonts/NotoSansSC-Regular.ttf"})
+//	data, err := r.Template(ctx, "./templates/invoice.html", invoice, pdf.HTMLOptions{FooterCenter: "[page] / [topage]"})
+//	if err != nil {
+//	    return err
+//	}
+//	c.Data(200, "application/pdf", data)
+type Renderer struct {
+	Conf jcbaseGo.PDFStruct
+}
+
+// NewRenderer 创建HTML转PDF渲染器
+func NewRenderer(conf jcbaseGo.PDFStruct) *Renderer {
+	_ = helper.CheckAndSetDefault(&conf)
+	return &Renderer{Conf: conf}
+}
+
+// Template 使用 html/template 渲染模板文件后转换为PDF
+func (r *Renderer) Template(ctx context.Context, filename string, data any, opts ...HTMLOptions) ([]byte, error) {
+	tpl, err := template.ParseFiles(filename)
+	if err != nil {
+		return nil, err
+	}
+	return r.ExecuteTemplate(ctx, tpl, filepath.Base(filename), data, opts...)
+}
+
+// ExecuteTemplate 渲染已解析的模板后转换为PDF
+func (r *Renderer) ExecuteTemplate(ctx context.Context, tpl *template.Template, name string, data any, opts ...HTMLOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return r.HTML(ctx, buf.String(), opts...)
+}
+
+// HTML 将HTML转换为PDF，配置了 FontPath 时自动注入 @font-face 作为默认字体以支持中文
+func (r *Renderer) HTML(ctx context.Context, html string, opts ...HTMLOptions) ([]byte, error) {
+	var opt HTMLOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	out, err := os.CreateTemp("", "jcbase-pdf-*.pdf")
+	if err != nil {
+		return nil, err
+	}
+	outPath := out.Name()
+	_ = out.Close()
+	defer os.Remove(outPath)
+
+	orientation := "Portrait"
+	if strings.EqualFold(r.Conf.Orientation, "L") || strings.EqualFold(r.Conf.Orientation, "Landscape") {
+		orientation = "Landscape"
+	}
+	args := []string{
+		"--quiet",
+		"--encoding", "utf-8",
+		"--page-size", r.Conf.PageSize,
+		"--orientation", orientation,
+		"--margin-top", defaultString(opt.MarginTop, "10mm"),
+		"--margin-bottom", defaultString(opt.MarginBottom, "10mm"),
+		"--margin-left", defaultString(opt.MarginLeft, "10mm"),
+		"--margin-right", defaultString(opt.MarginRight, "10mm"),
+		"--enable-local-file-access",
+	}
+	if opt.FooterCenter != "" {
+		args = append(args, "--footer-center", opt.FooterCenter, "--footer-font-size", "8")
+	}
+	args = append(append(args, opt.Args...), "-", outPath)
+
+	res, err := (&command.Cmd{
+		Name:    r.Conf.Wkhtmltopdf,
+		Args:    args,
+		Stdin:   strings.NewReader(r.injectFont(html)),
+		Timeout: time.Duration(r.Conf.Timeout) * time.Second,
+	}).Run(ctx)
+	if err != nil {
+		if res.TimedOut {
+			return nil, fmt.Errorf("pdf: wkhtmltopdf 执行超时")
+		}
+		if stderr := strings.TrimSpace(res.Stderr); stderr != "" {
+			return nil, fmt.Errorf("pdf: wkhtmltopdf 执行失败: %w: %s", err, stderr)
+		}
+		return nil, fmt.Errorf("pdf: wkhtmltopdf 执行失败: %w", err)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		return nil, errors.New("pdf: wkhtmltopdf 未生成有效的PDF")
+	}
+	return data, nil
+}
+
+// injectFont 注入中文字体的样式
+func (r *Renderer) injectFont(html string) string {
+	if r.Conf.FontPath == "" {
+		return html
+	}
+	fontPath, err := filepath.Abs(r.Conf.FontPath)
+	if err != nil {
+		fontPath = r.Conf.FontPath
+	}
+	style := fmt.Sprintf(`<style>@font-face{font-family:"%s";src:url("file://%s");}`+
+		`html,body{font-family:"%s",sans-serif;}</style>`, fontFamily, filepath.ToSlash(fontPath), fontFamily)
+
+	lower := strings.ToLower(html)
+	if i := strings.Index(lower, "</head>"); i >= 0 {
+		return html[:i] + style + html[i:]
+	}
+	if strings.Contains(lower, "<html") {
+		return style + html
+	}
+	return `<!DOCTYPE html><html><head><meta charset="utf-8">` + style + `</head><body>` + html + `</body></html>`
+}
+
+func defaultString(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
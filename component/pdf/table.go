@@ -0,0 +1,125 @@
+package pdf
+
+// Table 表格
+type Table struct {
+	Columns  []TableColumn
+	Rows     [][]string
+	Footer   []string // 表尾，如合计行，为空时不显示
+	Zebra    bool     // 是否隔行填充背景色
+	FontSize float64  // 字号，默认9
+}
+
+// TableColumn 表格列
+type TableColumn struct {
+	Title string
+	Width float64 // 相对宽度，按比例分配页面宽度，默认1
+	Align string  // 对齐方式，L 左对齐（默认）、C 居中、R 右对齐
+}
+
+// Table 输出表格，单元格内容自动换行，跨页时重复表头
+func (d *Document) Table(t Table) *Document {
+	d.start()
+	if len(t.Columns) == 0 {
+		return d
+	}
+	if t.FontSize <= 0 {
+		t.FontSize = 9
+	}
+
+	weights := make([]float64, len(t.Columns))
+	total := 0.0
+	for i, col := range t.Columns {
+		weights[i] = col.Width
+		if weights[i] <= 0 {
+			weights[i] = 1
+		}
+		total += weights[i]
+	}
+	content := d.contentWidth()
+	widths := make([]float64, len(t.Columns))
+	for i := range weights {
+		widths[i] = content * weights[i] / total
+	}
+
+	titles := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		titles[i] = col.Title
+	}
+	header := func() {
+		d.pdf.SetFillColor(242, 242, 242)
+		d.tableRow(t, widths, titles, "B", true, true)
+	}
+
+	header()
+	for i, row := range t.Rows {
+		if d.pageBreakNeeded(d.rowHeight(t, widths, row, "")) {
+			d.pdf.AddPage()
+			header()
+		}
+		d.pdf.SetFillColor(250, 250, 250)
+		d.tableRow(t, widths, row, "", t.Zebra && i%2 == 1, false)
+	}
+	if len(t.Footer) > 0 {
+		if d.pageBreakNeeded(d.rowHeight(t, widths, t.Footer, "B")) {
+			d.pdf.AddPage()
+			header()
+		}
+		d.pdf.SetFillColor(242, 242, 242)
+		d.tableRow(t, widths, t.Footer, "B", true, false)
+	}
+	d.pdf.Ln(3)
+	return d
+}
+
+// tableRow 输出一行，所有单元格高度一致
+func (d *Document) tableRow(t Table, widths []float64, cells []string, style string, fill, isHeader bool) {
+	const lineHeight = 5.0
+	height := d.rowHeight(t, widths, cells, style)
+	left, _, _, _ := d.pdf.GetMargins()
+	x, y := left, d.pdf.GetY()
+
+	d.font(style, t.FontSize)
+	for i, width := range widths {
+		text := ""
+		if i < len(cells) {
+			text = cells[i]
+		}
+		align := t.Columns[i].Align
+		if isHeader {
+			align = "C"
+		} else if align == "" {
+			align = "L"
+		}
+
+		drawStyle := "D"
+		if fill {
+			drawStyle = "FD"
+		}
+		d.pdf.Rect(x, y, width, height, drawStyle)
+		for j, line := range d.wrap(text, width) {
+			d.pdf.SetXY(x, y+1+float64(j)*lineHeight)
+			d.pdf.CellFormat(width, lineHeight, d.text(line), "", 0, align, false, 0, "")
+		}
+		x += width
+	}
+	d.pdf.SetXY(left, y+height)
+}
+
+// rowHeight 计算行高
+func (d *Document) rowHeight(t Table, widths []float64, cells []string, style string) float64 {
+	d.font(style, t.FontSize)
+	lines := 1
+	for i, width := range widths {
+		if i < len(cells) {
+			lines = max(lines, len(d.wrap(cells[i], width)))
+		}
+	}
+	return float64(lines)*5 + 2
+}
+
+// pageBreakNeeded 判断剩余空间是否不足以容纳指定高度
+func (d *Document) pageBreakNeeded(height float64) bool {
+	_, pageHeight := d.pdf.GetPageSize()
+	_, _, _, bottom := d.pdf.GetMargins()
+	return d.pdf.GetY()+height > pageHeight-bottom
+}
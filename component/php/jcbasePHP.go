@@ -7,6 +7,8 @@ var TmpJcbasePHP = `#!/usr/bin/env php
 
 class Console
 {
+    const VERSION = '0.0.3';
+
     public static $regOpts = [
         'help'    => [
             'short'     => 'h',
@@ -25,6 +27,18 @@ class Console
             'long'      => 'func',
             'desc'      => '要执行的函数名',
             'has_value' => true,
+        ],
+        'worker'  => [
+            'short'     => 'w',
+            'long'      => 'worker',
+            'desc'      => '以常驻进程模式运行，从STDIN逐行读取JSON请求',
+            'has_value' => false,
+        ],
+        'json'    => [
+            'short'     => 'j',
+            'long'      => 'json',
+            'desc'      => '与--func一起使用，从STDIN读取JSON数组作为参数，以JSON格式输出结果',
+            'has_value' => false,
         ]
     ];
 
@@ -65,7 +79,12 @@ class Console
         }
 
         if (isset(self::$opts['v']) || isset(self::$opts['version'])) {
-            self::stdout("Version: 0.0.1");
+            self::stdout("Version: " . self::VERSION);
+            exit();
+        }
+
+        if (isset(self::$opts['w']) || isset(self::$opts['worker'])) {
+            self::worker();
             exit();
         }
 
@@ -77,23 +96,15 @@ class Console
                 return !preg_match('/^(-\w|--\w+)/', $arg);
             });
 
-            // 转换参数中的true/false为bool类型，转换参数中的数字为int类型，转换参数中的json为数组类型
-            self::$args = array_map(function ($arg) {
-                if (in_array($arg, ['true', 'false'])) {
-                    return $arg === 'true';
-                }
-                if (is_numeric($arg)) {
-                    return (int)$arg;
-                }
-                if (preg_match('/^\{.*\}$/', $arg)) {
-                    return @json_decode($arg, true);
-                }
-                return $arg;
-            }, self::$args);
+            self::$args = self::parseArgs(self::$args);
 
             // 执行函数，传入参数，返回结果
             $func = self::$opts['f'] ?? self::$opts['func'];
-            if (function_exists($func)) {
+            if (isset(self::$opts['j']) || isset(self::$opts['json'])) {
+                // 参数以JSON数组的形式从STDIN读入，结果以JSON应答输出
+                $args = @json_decode(stream_get_contents(\STDIN), true);
+                self::stdout(self::encode(self::invoke(['func' => $func, 'args' => (array)$args, 'typed' => true])));
+            } elseif (function_exists($func)) {
                 $result = call_user_func_array($func, self::$args);
                 if (is_array($result))
                     $result = stripslashes(json_encode($result, JSON_UNESCAPED_UNICODE));
@@ -110,6 +121,124 @@ class Console
         self::error("fatal:Function not specified");
     }
 
+    /**
+     * 常驻进程模式：每行一个JSON请求，每行返回一个JSON应答，格式见 invoke
+     */
+    public static function worker()
+    {
+        while (($line = fgets(\STDIN)) !== false) {
+            $line = trim($line);
+            if ($line === '') {
+                continue;
+            }
+
+            $request = @json_decode($line, true);
+            fwrite(\STDOUT, self::encode(self::invoke(is_array($request) ? $request : [])) . "\n");
+            fflush(\STDOUT);
+        }
+    }
+
+    /**
+     * 执行请求 {"func":"name","args":[...],"typed":false}
+     *
+     * 应答 {"output":"...","error":"...","result":...,"exception":{...}}，
+     * typed为true时参数按JSON原样传入，返回值放在result中，output为函数的输出内容；
+     * 否则参数按命令行规则转换，output为输出内容拼接返回值（与命令行模式一致）
+     *
+     * @param array $request
+     * @return array
+     */
+    public static function invoke(array $request): array
+    {
+        $response = ['output' => '', 'error' => ''];
+        $func     = $request['func'] ?? '';
+        $typed    = !empty($request['typed']);
+        if ($func === '') {
+            $response['error'] = 'fatal:Invalid request';
+            return $response;
+        }
+        if ($func === '__ping') {
+            $response['output'] = 'pong';
+            return $response;
+        }
+        if (!function_exists($func)) {
+            $response['error'] = "fatal:Function $func not exists";
+            return $response;
+        }
+
+        $args = array_values((array)($request['args'] ?? []));
+        ob_start();
+        try {
+            $result = call_user_func_array($func, $typed ? $args : self::parseArgs($args));
+            if ($typed) {
+                $response['output'] = ob_get_contents();
+                $response['result'] = $result;
+            } else {
+                if (is_array($result))
+                    $result = stripslashes(json_encode($result, JSON_UNESCAPED_UNICODE));
+                $response['output'] = ob_get_contents() . (string)$result;
+            }
+        } catch (\Throwable $e) {
+            $response['output']    = ob_get_contents();
+            $response['error']     = get_class($e) . ': ' . $e->getMessage();
+            $response['exception'] = [
+                'type'    => get_class($e),
+                'message' => $e->getMessage(),
+                'code'    => $e->getCode(),
+                'file'    => $e->getFile(),
+                'line'    => $e->getLine(),
+                'trace'   => $e->getTraceAsString(),
+            ];
+        }
+        ob_end_clean();
+
+        return $response;
+    }
+
+    /**
+     * 将应答编码为单行JSON，返回值无法编码时转为错误
+     *
+     * @param array $response
+     * @return string
+     */
+    public static function encode(array $response): string
+    {
+        $flags = JSON_UNESCAPED_UNICODE | JSON_INVALID_UTF8_SUBSTITUTE | JSON_PRESERVE_ZERO_FRACTION;
+        $json  = json_encode($response, $flags);
+        if ($json === false) {
+            $json = json_encode([
+                'output' => $response['output'] ?? '',
+                'error'  => 'fatal:json_encode ' . json_last_error_msg(),
+            ], $flags);
+        }
+        return $json;
+    }
+
+    /**
+     * 转换参数中的true/false为bool类型，转换参数中的数字为int类型，转换参数中的json为数组类型
+     *
+     * @param array $args
+     * @return array
+     */
+    public static function parseArgs(array $args): array
+    {
+        return array_map(function ($arg) {
+            if (!is_string($arg)) {
+                return $arg;
+            }
+            if (in_array($arg, ['true', 'false'])) {
+                return $arg === 'true';
+            }
+            if (is_numeric($arg)) {
+                return (int)$arg;
+            }
+            if (preg_match('/^\{.*\}$/', $arg)) {
+                return @json_decode($arg, true);
+            }
+            return $arg;
+        }, array_values($args));
+    }
+
 
     /**
      * Gets input from STDIN and returns a string right-trimmed for EOLs.
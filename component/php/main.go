@@ -1,14 +1,19 @@
 package php
 
 import (
+	"errors"
 	"github.com/jcbowen/jcbaseGo"
 	"github.com/jcbowen/jcbaseGo/component/command"
 	"github.com/jcbowen/jcbaseGo/component/helper"
 	"log"
+	"sync"
 )
 
 type ConfigStruct struct {
 	funcFilePath string
+
+	workersMu sync.RWMutex
+	workers   *workerPool
 }
 
 func New(opt jcbaseGo.Option) *ConfigStruct {
@@ -33,7 +38,22 @@ func New(opt jcbaseGo.Option) *ConfigStruct {
 	return conf
 }
 
+// RunFunc 执行函数文件中的函数，已启动常驻进程池时通过常驻进程执行
 func (c *ConfigStruct) RunFunc(funcName string, args ...string) (string, error) {
+	if pool := c.workerPool(); pool != nil {
+		workerArgs := make([]any, len(args))
+		for i, arg := range args {
+			workerArgs[i] = arg
+		}
+		resp, err := pool.call(funcName, workerArgs, false)
+		if err != nil {
+			return "", err
+		}
+		if resp.Error != "" {
+			return resp.Output, errors.New(resp.Error)
+		}
+		return resp.Output, nil
+	}
 
 	funcName = "--func=" + funcName
 
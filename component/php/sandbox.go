@@ -0,0 +1,146 @@
+package php
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/command"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDisableFunctions 沙箱默认禁用的函数
+var DefaultDisableFunctions = []string{
+	"exec", "passthru", "shell_exec", "system", "proc_open", "popen", "proc_nice", "proc_terminate",
+	"pcntl_exec", "pcntl_fork", "pcntl_signal", "posix_kill", "posix_setuid", "posix_setgid",
+	"dl", "putenv", "ini_set", "ini_alter", "ini_restore", "set_time_limit", "mail",
+	"symlink", "link", "chown", "chgrp", "chmod",
+}
+
+// SandboxOptions 执行脚本时的限制
+type SandboxOptions struct {
+	Binary           string            `default:"php"` // php可执行文件
+	Timeout          time.Duration     // 执行超时时间，默认30秒，超时后进程会被杀死
+	MemoryLimit      string            `default:"128M"` // 内存限制，对应 memory_limit
+	DisableFunctions []string          // 禁用的函数，为nil时使用 DefaultDisableFunctions，传空切片表示不禁用
+	OpenBasedir      string            // 限制可访问的目录，对应 open_basedir
+	Env              map[string]string // 额外的环境变量
+	InheritEnv       bool              // 是否继承当前进程的环境变量，默认只传入PATH及Env
+	Dir              string            // 工作目录，默认为 command.CmdPath
+	Ini              map[string]string // 其他ini配置
+}
+
+// ScriptResult 脚本执行结果
+type ScriptResult struct {
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+	TimedOut bool          `json:"timed_out"`
+}
+
+// RunScript 在沙箱限制下执行php脚本文件，stdout与stderr分开捕获
+//
+// 脚本以非0状态码退出时返回结果及 *exec.ExitError，超时时返回 context.DeadlineExceeded
+//
+// 示例:
+//
+//	res, err := p.RunScript("./scripts/report.php", []string{"--month=2024-01"}, php.SandboxOptions{
+//	    Timeout:     10 * time.Second,
+//	    MemoryLimit: "256M",
+//	    Env:         map[string]string{"APP_ENV": "prod"},
+//	})
+func (c *ConfigStruct) RunScript(path string, args []string, opts ...SandboxOptions) (*ScriptResult, error) {
+	if !helper.NewFile(&helper.File{Path: path}).Exists() {
+		return nil, errors.New("php: 脚本文件不存在: " + path)
+	}
+	cmdArgs := append([]string{"-f", path, "--"}, args...)
+	return runSandbox(cmdArgs, nil, opts...)
+}
+
+// RunCode 在沙箱限制下执行一段php代码，代码可省略开头的 <?php
+func (c *ConfigStruct) RunCode(code string, opts ...SandboxOptions) (*ScriptResult, error) {
+	if !strings.HasPrefix(strings.TrimSpace(code), "<?") {
+		code = "<?php\n" + code
+	}
+	// 不指定脚本文件时php从STDIN读取代码
+	return runSandbox(nil, strings.NewReader(code), opts...)
+}
+
+// runSandbox 组装参数并执行
+func runSandbox(args []string, stdin *strings.Reader, opts ...SandboxOptions) (*ScriptResult, error) {
+	var opt SandboxOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.Timeout <= 0 {
+		opt.Timeout = 30 * time.Second
+	}
+	if opt.DisableFunctions == nil {
+		opt.DisableFunctions = DefaultDisableFunctions
+	}
+
+	ini := map[string]string{
+		"memory_limit":       opt.MemoryLimit,
+		"max_execution_time": strconv.Itoa(int(opt.Timeout.Seconds()) + 1),
+		"display_errors":     "stderr",
+	}
+	if len(opt.DisableFunctions) > 0 {
+		ini["disable_functions"] = strings.Join(opt.DisableFunctions, ",")
+	}
+	if opt.OpenBasedir != "" {
+		ini["open_basedir"] = opt.OpenBasedir
+	}
+	for key, value := range opt.Ini {
+		ini[key] = value
+	}
+	cmdArgs := make([]string, 0, len(ini)*2+len(args))
+	for key, value := range ini {
+		cmdArgs = append(cmdArgs, "-d", key+"="+value)
+	}
+	cmdArgs = append(cmdArgs, args...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opt.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, opt.Binary, cmdArgs...)
+	cmd.Dir = opt.Dir
+	if cmd.Dir == "" && len(command.CmdPath) > 0 {
+		cmd.Dir = command.CmdPath
+	}
+	if opt.InheritEnv {
+		cmd.Env = os.Environ()
+	} else {
+		cmd.Env = []string{"PATH=" + os.Getenv("PATH")}
+	}
+	for key, value := range opt.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	// 进程被杀死后，子进程可能仍占用输出管道，最多再等待1秒
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result := &ScriptResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Duration: time.Since(start),
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		result.TimedOut = true
+		return result, ctx.Err()
+	}
+	return result, err
+}
@@ -0,0 +1,126 @@
+package php
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/command"
+	"os/exec"
+	"reflect"
+	"strings"
+)
+
+// Error PHP函数执行失败（抛出异常、函数不存在等）时返回的错误
+type Error struct {
+	Type    string `json:"type"`    // 异常类名
+	Message string `json:"message"` // 异常信息
+	Code    int    `json:"code"`    // 异常代码
+	File    string `json:"file"`    // 抛出异常的文件
+	Line    int    `json:"line"`    // 抛出异常的行号
+	Trace   string `json:"trace"`   // 调用栈
+	Output  string `json:"-"`       // 异常抛出前函数输出的内容
+}
+
+func (e *Error) Error() string {
+	if e.File == "" {
+		return "php: " + e.Message
+	}
+	return fmt.Sprintf("php: %s: %s in %s:%d", e.Type, e.Message, e.File, e.Line)
+}
+
+// RunFuncInto 以JSON传递参数执行函数，并将返回值解析到result中（为nil时忽略返回值）
+//
+// 参数可以是任意可JSON序列化的值，map与结构体在PHP中为关联数组；
+// PHP抛出异常时返回 *Error，可通过 errors.As 获取异常详情
+//
+// 示例:
+//
+//	var user struct {
+//	    ID   int    `json:"id"`
+//	    Name string `json:"name"`
+//	}
+//	err := p.RunFuncInto(&user, "get_user", 1, map[string]any{"with": []string{"profile"}})
+//	var phpErr *php.Error
+//	if errors.As(err, &phpErr) {
+//	    log.Println(phpErr.Type, phpErr.Message)
+//	}
+func (c *ConfigStruct) RunFuncInto(result any, funcName string, args ...any) error {
+	if args == nil {
+		args = []any{}
+	}
+
+	var (
+		resp *workerResponse
+		err  error
+	)
+	if pool := c.workerPool(); pool != nil {
+		resp, err = pool.call(funcName, args, true)
+	} else {
+		resp, err = c.runJSON(funcName, args)
+	}
+	if err != nil {
+		return err
+	}
+
+	if resp.Exception != nil {
+		resp.Exception.Output = resp.Output
+		return resp.Exception
+	}
+	if resp.Error != "" {
+		return &Error{Message: strings.TrimPrefix(resp.Error, "fatal:"), Output: resp.Output}
+	}
+	return decodeResult(resp.Result, result)
+}
+
+// runJSON 启动php进程，参数通过STDIN以JSON传入
+func (c *ConfigStruct) runJSON(funcName string, args []any) (*workerResponse, error) {
+	input, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("php: 参数序列化失败: %v", err)
+	}
+
+	cmd := exec.Command("php", c.funcFilePath, "--func="+funcName, "--json")
+	if len(command.CmdPath) > 0 {
+		cmd.Dir = command.CmdPath
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var resp workerResponse
+	if err = json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &resp); err != nil {
+		msg := strings.TrimSpace(stderr.String() + "\n" + stdout.String())
+		if runErr != nil {
+			return nil, fmt.Errorf("php: %v: %s", runErr, msg)
+		}
+		if !strings.Contains(stdout.String(), "{") {
+			return nil, fmt.Errorf("php: %s 版本过旧，不支持 --json 参数，请删除后重新生成: %s", c.funcFilePath, msg)
+		}
+		return nil, fmt.Errorf("php: 解析应答失败: %v: %s", err, msg)
+	}
+	return &resp, nil
+}
+
+// decodeResult 将PHP返回值解析到result中，PHP的空数组可解析为空map或结构体
+func decodeResult(raw json.RawMessage, result any) error {
+	if result == nil || len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if string(raw) == "[]" {
+		rv := reflect.ValueOf(result)
+		if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			switch rv.Elem().Kind() {
+			case reflect.Map, reflect.Struct:
+				return nil
+			default:
+			}
+		}
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return errors.New("php: 返回值解析失败: " + err.Error())
+	}
+	return nil
+}
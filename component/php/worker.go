@@ -0,0 +1,324 @@
+package php
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrWorkerStopped 常驻进程池已停止
+var ErrWorkerStopped = errors.New("php: worker pool stopped")
+
+// WorkerOptions 常驻进程配置
+type WorkerOptions struct {
+	Binary         string        `default:"php"`  // php可执行文件
+	PoolSize       int           `default:"2"`    // 常驻进程数量
+	MaxRequests    int           `default:"1000"` // 单个进程处理多少次请求后重启，避免内存泄漏，小于0表示不限制
+	Timeout        time.Duration // 单次调用超时时间，默认30秒，超时的进程会被杀死并重建
+	HealthInterval time.Duration // 健康检查间隔，默认30秒，小于0表示不检查
+}
+
+// workerRequest 发送给常驻进程的请求
+type workerRequest struct {
+	Func  string `json:"func"`
+	Args  []any  `json:"args"`
+	Typed bool   `json:"typed,omitempty"` // 参数按JSON原样传入，返回值放在Result中
+}
+
+// workerResponse 常驻进程的应答
+type workerResponse struct {
+	Output    string          `json:"output"`
+	Error     string          `json:"error"`
+	Result    json.RawMessage `json:"result"`
+	Exception *Error          `json:"exception"`
+}
+
+// workerPool 常驻进程池
+type workerPool struct {
+	opt      WorkerOptions
+	script   string
+	idle     chan *worker
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// worker 单个常驻进程
+type worker struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	stdout   *bufio.Reader
+	stderr   *limitedBuffer
+	requests int
+}
+
+// StartWorkers 启动常驻进程池，之后 RunFunc 会通过常驻进程执行，避免每次调用都启动php进程
+//
+// 常驻进程通过 STDIN/STDOUT 逐行交换JSON，要求函数文件为 0.0.3 及以上版本生成，
+// 旧版本文件不支持 --worker 参数，需要删除后重新生成
+//
+// 示例:
+//
+//	p := php.New(opt)
+//	if err := p.StartWorkers(php.WorkerOptions{PoolSize: 4}); err != nil {
+//	    log.Panic(err)
+//	}
+//	defer p.StopWorkers()
+//	result, err := p.RunFunc("md5", "hello")
+func (c *ConfigStruct) StartWorkers(opts ...WorkerOptions) error {
+	var opt WorkerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.PoolSize < 1 {
+		opt.PoolSize = 1
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 30 * time.Second
+	}
+	if opt.HealthInterval == 0 {
+		opt.HealthInterval = 30 * time.Second
+	}
+
+	content, err := os.ReadFile(c.funcFilePath)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(content), "function invoke") {
+		return fmt.Errorf("php: %s 版本过旧，不支持常驻进程模式，请删除后重新生成", c.funcFilePath)
+	}
+
+	c.StopWorkers()
+
+	pool := &workerPool{
+		opt:    opt,
+		script: c.funcFilePath,
+		idle:   make(chan *worker, opt.PoolSize),
+		stop:   make(chan struct{}),
+	}
+	for i := 0; i < opt.PoolSize; i++ {
+		w, err := pool.spawn()
+		if err != nil {
+			pool.close()
+			return err
+		}
+		pool.idle <- w
+	}
+	if opt.HealthInterval > 0 {
+		go pool.healthCheck()
+	}
+
+	c.workersMu.Lock()
+	c.workers = pool
+	c.workersMu.Unlock()
+	return nil
+}
+
+// StopWorkers 停止常驻进程池，之后 RunFunc 恢复为每次启动php进程
+func (c *ConfigStruct) StopWorkers() {
+	c.workersMu.Lock()
+	pool := c.workers
+	c.workers = nil
+	c.workersMu.Unlock()
+	if pool != nil {
+		pool.close()
+	}
+}
+
+// workerPool 获取当前的常驻进程池
+func (c *ConfigStruct) workerPool() *workerPool {
+	c.workersMu.RLock()
+	defer c.workersMu.RUnlock()
+	return c.workers
+}
+
+// call 通过常驻进程调用函数
+func (p *workerPool) call(funcName string, args []any, typed bool) (*workerResponse, error) {
+	var w *worker
+	select {
+	case w = <-p.idle:
+	case <-p.stop:
+		return nil, ErrWorkerStopped
+	case <-time.After(p.opt.Timeout):
+		return nil, errors.New("php: 等待空闲常驻进程超时")
+	}
+
+	resp, err := p.roundTrip(w, workerRequest{Func: funcName, Args: args, Typed: typed})
+	p.release(w, err)
+	return resp, err
+}
+
+// roundTrip 发送请求并等待应答
+func (p *workerPool) roundTrip(w *worker, req workerRequest) (*workerResponse, error) {
+	if w == nil {
+		return nil, errors.New("php: 常驻进程不可用")
+	}
+	if req.Args == nil {
+		req.Args = []any{}
+	}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	var resp workerResponse
+	go func() {
+		if _, err := w.stdin.Write(append(line, '\n')); err != nil {
+			done <- err
+			return
+		}
+		out, err := w.stdout.ReadBytes('\n')
+		if err != nil {
+			done <- fmt.Errorf("php: 常驻进程异常退出: %v %s", err, strings.TrimSpace(w.stderr.String()))
+			return
+		}
+		done <- json.Unmarshal(out, &resp)
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(p.opt.Timeout):
+		// 进程状态未知，直接杀死，由release重建
+		w.kill()
+		<-done
+		err = fmt.Errorf("php: 调用 %s 超时", req.Func)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.requests++
+	return &resp, nil
+}
+
+// release 归还进程，出错或达到最大请求数时重建
+func (p *workerPool) release(w *worker, callErr error) {
+	select {
+	case <-p.stop:
+		if w != nil {
+			w.kill()
+		}
+		return
+	default:
+	}
+
+	if w == nil || callErr != nil || (p.opt.MaxRequests > 0 && w.requests >= p.opt.MaxRequests) {
+		if w != nil {
+			w.kill()
+		}
+		// 重建失败时放回nil，下次调用时返回错误并再次尝试重建
+		w, _ = p.spawn()
+	}
+	p.idle <- w
+}
+
+// healthCheck 定期检查空闲进程
+func (p *workerPool) healthCheck() {
+	ticker := time.NewTicker(p.opt.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+	check:
+		for i := 0; i < p.opt.PoolSize; i++ {
+			var w *worker
+			select {
+			case w = <-p.idle:
+			default:
+				// 其余进程正在处理请求，说明工作正常
+				break check
+			}
+			resp, err := p.roundTrip(w, workerRequest{Func: "__ping"})
+			if err == nil && resp.Output != "pong" {
+				err = errors.New("php: 常驻进程健康检查失败")
+			}
+			if err == nil {
+				w.requests-- // 健康检查不计入请求数
+			}
+			p.release(w, err)
+		}
+	}
+}
+
+// spawn 启动一个常驻进程
+func (p *workerPool) spawn() (*worker, error) {
+	cmd := exec.Command(p.opt.Binary, p.script, "--worker")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &limitedBuffer{limit: 4096}
+	cmd.Stderr = stderr
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &worker{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout), stderr: stderr}, nil
+}
+
+// close 停止所有进程
+func (p *workerPool) close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		for {
+			select {
+			case w := <-p.idle:
+				if w != nil {
+					w.kill()
+				}
+			default:
+				return
+			}
+		}
+	})
+}
+
+// kill 结束进程
+func (w *worker) kill() {
+	_ = w.stdin.Close()
+	if w.cmd.Process != nil {
+		_ = w.cmd.Process.Kill()
+	}
+	_ = w.cmd.Wait()
+}
+
+// limitedBuffer 并发安全的输出缓冲，只保留前limit个字节
+type limitedBuffer struct {
+	mu    sync.Mutex
+	buf   strings.Builder
+	limit int
+}
+
+func (l *limitedBuffer) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := len(b)
+	if remain := l.limit - l.buf.Len(); remain > 0 {
+		if n > remain {
+			b = b[:remain]
+		}
+		l.buf.Write(b)
+	}
+	return n, nil
+}
+
+func (l *limitedBuffer) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
@@ -0,0 +1,712 @@
+// Package phpserialize 纯Go实现的PHP serialize/unserialize编解码，
+// 用于读写PHP会话、缓存等旧系统数据，无需调用php命令
+//
+// 类型对应关系:
+//
+//	PHP            Go (Unmarshal)                     Go (Marshal)
+//	N;             nil                                nil、nil指针
+//	b:1;           bool                               bool
+//	i:1;           int64                              int*、uint*
+//	d:0.5;         float64                            float*
+//	s:3:"abc";     string                             string、[]byte
+//	a:{...}        []any（键为0..n-1时）或 map[string]any  slice、array、map、struct（按json标签）
+//	O:{...}        *Object                            *Object、Object
+package phpserialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Object PHP对象
+type Object struct {
+	Class      string         `json:"class"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Marshal 将Go值序列化为PHP serialize格式
+//
+// map按键排序输出以保证结果稳定，结构体按json标签（无标签时为字段名）输出为关联数组
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Serialize 同 Marshal，返回字符串
+func Serialize(v any) (string, error) {
+	data, err := Marshal(v)
+	return string(data), err
+}
+
+// Unmarshal 解析PHP serialize格式的数据
+func Unmarshal(data []byte) (any, error) {
+	d := &decoder{data: data}
+	value, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, d.errorf("unexpected trailing data")
+	}
+	return value, nil
+}
+
+// Unserialize 同 Unmarshal，参数为字符串
+func Unserialize(data string) (any, error) {
+	return Unmarshal([]byte(data))
+}
+
+// UnmarshalInto 解析PHP serialize格式的数据到结构体、map或切片中（按json标签匹配）
+//
+// 示例:
+//
+//	var user struct {
+//	    ID   int    `json:"id"`
+//	    Name string `json:"name"`
+//	}
+//	err := phpserialize.UnmarshalInto([]byte(`a:2:{s:2:"id";i:1;s:4:"name";s:2:"jc";}`), &user)
+func UnmarshalInto(data []byte, v any) error {
+	value, err := Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(toJSONCompatible(value))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// UnserializeSession 解析PHP默认会话格式（session.serialize_handler = php）：name|value name|value...
+func UnserializeSession(data string) (map[string]any, error) {
+	result := make(map[string]any)
+	d := &decoder{data: []byte(data)}
+	for d.pos < len(d.data) {
+		sep := bytes.IndexByte(d.data[d.pos:], '|')
+		if sep < 0 {
+			return nil, d.errorf("missing session key separator")
+		}
+		key := string(d.data[d.pos : d.pos+sep])
+		d.pos += sep + 1
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// SerializeSession 序列化为PHP默认会话格式，按键排序输出
+func SerializeSession(data map[string]any) (string, error) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if strings.ContainsAny(key, "|!") {
+			return "", fmt.Errorf("phpserialize: invalid session key %q", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		buf.WriteString(key)
+		buf.WriteByte('|')
+		if err := encode(&buf, reflect.ValueOf(data[key])); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+// ----- 编码 -----/
+
+// encode 递归编码
+func encode(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteString("N;")
+		return nil
+	}
+
+	if v.Type() == reflect.TypeOf(Object{}) {
+		obj := v.Interface().(Object)
+		return encodeObject(buf, &obj)
+	}
+	if v.Type() == reflect.TypeOf(&Object{}) {
+		if v.IsNil() {
+			buf.WriteString("N;")
+			return nil
+		}
+		return encodeObject(buf, v.Interface().(*Object))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("N;")
+			return nil
+		}
+		return encode(buf, v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteString("b:1;")
+		} else {
+			buf.WriteString("b:0;")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString("i:" + strconv.FormatInt(v.Int(), 10) + ";")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteString("i:" + strconv.FormatUint(v.Uint(), 10) + ";")
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString("d:" + formatFloat(v.Float()) + ";")
+	case reflect.String:
+		encodeString(buf, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			encodeString(buf, string(v.Bytes()))
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("N;")
+			return nil
+		}
+		buf.WriteString("a:" + strconv.Itoa(v.Len()) + ":{")
+		for i := 0; i < v.Len(); i++ {
+			buf.WriteString("i:" + strconv.Itoa(i) + ";")
+			if err := encode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("N;")
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		buf.WriteString("a:" + strconv.Itoa(len(keys)) + ":{")
+		for _, key := range keys {
+			if err := encodeKey(buf, key); err != nil {
+				return err
+			}
+			if err := encode(buf, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	case reflect.Struct:
+		fields := structFields(v)
+		buf.WriteString("a:" + strconv.Itoa(len(fields)) + ":{")
+		for _, field := range fields {
+			encodeString(buf, field.name)
+			if err := encode(buf, field.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	default:
+		return fmt.Errorf("phpserialize: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// encodeObject 编码PHP对象
+func encodeObject(buf *bytes.Buffer, obj *Object) error {
+	keys := make([]string, 0, len(obj.Properties))
+	for key := range obj.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("O:" + strconv.Itoa(len(obj.Class)) + ":\"" + obj.Class + "\":" + strconv.Itoa(len(keys)) + ":{")
+	for _, key := range keys {
+		encodeString(buf, key)
+		if err := encode(buf, reflect.ValueOf(obj.Properties[key])); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("}")
+	return nil
+}
+
+// encodeKey 编码数组键，PHP数组键只能是整数或字符串
+func encodeKey(buf *bytes.Buffer, key reflect.Value) error {
+	for key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString("i:" + strconv.FormatInt(key.Int(), 10) + ";")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString("i:" + strconv.FormatUint(key.Uint(), 10) + ";")
+	case reflect.String:
+		// PHP会将十进制整数形式的字符串键转换为整数键
+		if n, err := strconv.ParseInt(key.String(), 10, 64); err == nil && strconv.FormatInt(n, 10) == key.String() {
+			buf.WriteString("i:" + key.String() + ";")
+		} else {
+			encodeString(buf, key.String())
+		}
+	default:
+		return fmt.Errorf("phpserialize: unsupported map key type %s", key.Type())
+	}
+	return nil
+}
+
+// encodeString 编码字符串，长度为字节数
+func encodeString(buf *bytes.Buffer, s string) {
+	buf.WriteString("s:" + strconv.Itoa(len(s)) + ":\"" + s + "\";")
+}
+
+// formatFloat 按PHP的格式输出浮点数
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NAN"
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	}
+	return strconv.FormatFloat(f, 'G', -1, 64)
+}
+
+// field 结构体字段
+type field struct {
+	name  string
+	value reflect.Value
+}
+
+// structFields 获取结构体的导出字段，匿名结构体字段会被展开
+func structFields(v reflect.Value) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		omitEmpty := false
+		if tag := sf.Tag.Get("json"); tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				omitEmpty = omitEmpty || opt == "omitempty"
+			}
+		}
+
+		fv := v.Field(i)
+		if sf.Anonymous && sf.Tag.Get("json") == "" && fv.Kind() == reflect.Struct {
+			fields = append(fields, structFields(fv)...)
+			continue
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		fields = append(fields, field{name: name, value: fv})
+	}
+	return fields
+}
+
+// ----- 解码 -----/
+
+// decoder 解码器
+type decoder struct {
+	data   []byte
+	pos    int
+	values []any // 用于解析 r:/R: 引用，按出现顺序记录的值
+}
+
+// errorf 生成带位置信息的错误
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("phpserialize: "+format+" at offset %d", append(args, d.pos)...)
+}
+
+// decode 解码一个值
+func (d *decoder) decode() (any, error) {
+	if d.pos >= len(d.data) {
+		return nil, d.errorf("unexpected end of data")
+	}
+	typ := d.data[d.pos]
+
+	if typ == 'N' {
+		if err := d.expect("N;"); err != nil {
+			return nil, err
+		}
+		d.values = append(d.values, nil)
+		return nil, nil
+	}
+
+	if err := d.expect(string(typ) + ":"); err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case 'b':
+		raw, err := d.readUntil(';')
+		if err != nil {
+			return nil, err
+		}
+		value := raw == "1"
+		d.values = append(d.values, value)
+		return value, nil
+	case 'i':
+		raw, err := d.readUntil(';')
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, d.errorf("invalid integer %q", raw)
+		}
+		d.values = append(d.values, value)
+		return value, nil
+	case 'd':
+		raw, err := d.readUntil(';')
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseFloat(raw)
+		if err != nil {
+			return nil, d.errorf("invalid float %q", raw)
+		}
+		d.values = append(d.values, value)
+		return value, nil
+	case 's', 'S':
+		value, err := d.readString(typ == 'S')
+		if err != nil {
+			return nil, err
+		}
+		if err = d.expect(";"); err != nil {
+			return nil, err
+		}
+		d.values = append(d.values, value)
+		return value, nil
+	case 'a':
+		return d.decodeArray()
+	case 'O':
+		class, err := d.readString(false)
+		if err != nil {
+			return nil, err
+		}
+		if err = d.expect(":"); err != nil {
+			return nil, err
+		}
+		obj := &Object{Class: class}
+		d.values = append(d.values, obj)
+		entries, err := d.decodeEntries()
+		if err != nil {
+			return nil, err
+		}
+		obj.Properties = make(map[string]any, len(entries))
+		for _, entry := range entries {
+			obj.Properties[propertyName(fmt.Sprint(entry.key))] = entry.value
+		}
+		return obj, nil
+	case 'C':
+		// 实现了Serializable接口的对象，内容由类自行定义，原样保留
+		class, err := d.readString(false)
+		if err != nil {
+			return nil, err
+		}
+		if err = d.expect(":"); err != nil {
+			return nil, err
+		}
+		raw, err := d.readLengthBytes('{', '}')
+		if err != nil {
+			return nil, err
+		}
+		obj := &Object{Class: class, Properties: map[string]any{"__serialized": raw}}
+		d.values = append(d.values, obj)
+		return obj, nil
+	case 'r', 'R':
+		raw, err := d.readUntil(';')
+		if err != nil {
+			return nil, err
+		}
+		index, err := strconv.Atoi(raw)
+		if err != nil || index < 1 || index > len(d.values) {
+			return nil, d.errorf("invalid reference %q", raw)
+		}
+		value := d.values[index-1]
+		if typ == 'r' {
+			d.values = append(d.values, value)
+		}
+		return value, nil
+	default:
+		return nil, d.errorf("unknown type %q", typ)
+	}
+}
+
+// entry 数组元素
+type entry struct {
+	key   any
+	value any
+}
+
+// decodeArray 解码数组，键为0..n-1时返回[]any，否则返回map[string]any
+func (d *decoder) decodeArray() (any, error) {
+	slot := len(d.values)
+	d.values = append(d.values, nil)
+	entries, err := d.decodeEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	isList := true
+	for i, e := range entries {
+		if n, ok := e.key.(int64); !ok || n != int64(i) {
+			isList = false
+			break
+		}
+	}
+
+	var value any
+	if isList {
+		list := make([]any, len(entries))
+		for i, e := range entries {
+			list[i] = e.value
+		}
+		value = list
+	} else {
+		m := make(map[string]any, len(entries))
+		for _, e := range entries {
+			m[fmt.Sprint(e.key)] = e.value
+		}
+		value = m
+	}
+	d.values[slot] = value
+	return value, nil
+}
+
+// decodeEntries 解码 count:{key;value...} 形式的元素列表
+func (d *decoder) decodeEntries() ([]entry, error) {
+	raw, err := d.readUntil(':')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(raw)
+	if err != nil || count < 0 {
+		return nil, d.errorf("invalid element count %q", raw)
+	}
+	if err = d.expect("{"); err != nil {
+		return nil, err
+	}
+
+	entries := make([]entry, 0, count)
+	for i := 0; i < count; i++ {
+		key, err := d.decodeKey()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key: key, value: value})
+	}
+	if err = d.expect("}"); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// decodeKey 解码数组键（键不计入引用序号）
+func (d *decoder) decodeKey() (any, error) {
+	n := len(d.values)
+	key, err := d.decode()
+	d.values = d.values[:n]
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case int64, string:
+		return key, nil
+	default:
+		return nil, d.errorf("invalid array key type %T", key)
+	}
+}
+
+// readString 读取 len:"content" 形式的字符串
+func (d *decoder) readString(escaped bool) (string, error) {
+	raw, err := d.readUntil(':')
+	if err != nil {
+		return "", err
+	}
+	length, err := strconv.Atoi(raw)
+	if err != nil || length < 0 {
+		return "", d.errorf("invalid string length %q", raw)
+	}
+	if err = d.expect("\""); err != nil {
+		return "", err
+	}
+
+	if escaped {
+		// S: 格式中非ASCII字符以 \xx 十六进制转义
+		var sb strings.Builder
+		for sb.Len() < length {
+			if d.pos >= len(d.data) {
+				return "", d.errorf("unexpected end of data")
+			}
+			c := d.data[d.pos]
+			if c == '\\' && d.pos+2 < len(d.data) {
+				b, err := strconv.ParseUint(string(d.data[d.pos+1:d.pos+3]), 16, 8)
+				if err != nil {
+					return "", d.errorf("invalid escape sequence")
+				}
+				sb.WriteByte(byte(b))
+				d.pos += 3
+				continue
+			}
+			sb.WriteByte(c)
+			d.pos++
+		}
+		if err = d.expect("\""); err != nil {
+			return "", err
+		}
+		return sb.String(), nil
+	}
+
+	if d.pos+length > len(d.data) {
+		return "", d.errorf("string length %d out of range", length)
+	}
+	value := string(d.data[d.pos : d.pos+length])
+	d.pos += length
+	if err = d.expect("\""); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// readLengthBytes 读取 len:{content} 形式的内容
+func (d *decoder) readLengthBytes(open, close byte) (string, error) {
+	raw, err := d.readUntil(':')
+	if err != nil {
+		return "", err
+	}
+	length, err := strconv.Atoi(raw)
+	if err != nil || length < 0 {
+		return "", d.errorf("invalid length %q", raw)
+	}
+	if err = d.expect(string(open)); err != nil {
+		return "", err
+	}
+	if d.pos+length > len(d.data) {
+		return "", d.errorf("length %d out of range", length)
+	}
+	value := string(d.data[d.pos : d.pos+length])
+	d.pos += length
+	if err = d.expect(string(close)); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// readUntil 读取到分隔符为止（不含分隔符），并跳过分隔符
+func (d *decoder) readUntil(sep byte) (string, error) {
+	end := bytes.IndexByte(d.data[d.pos:], sep)
+	if end < 0 {
+		return "", d.errorf("expected %q", sep)
+	}
+	value := string(d.data[d.pos : d.pos+end])
+	d.pos += end + 1
+	return value, nil
+}
+
+// expect 校验并跳过指定内容
+func (d *decoder) expect(s string) error {
+	if !bytes.HasPrefix(d.data[d.pos:], []byte(s)) {
+		return d.errorf("expected %q", s)
+	}
+	d.pos += len(s)
+	return nil
+}
+
+// parseFloat 解析PHP浮点数，支持 INF、-INF、NAN
+func parseFloat(raw string) (float64, error) {
+	switch raw {
+	case "INF":
+		return math.Inf(1), nil
+	case "-INF":
+		return math.Inf(-1), nil
+	case "NAN":
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(raw, 64)
+}
+
+// propertyName 去除protected（\0*\0name）及private（\0Class\0name）属性名的前缀
+func propertyName(name string) string {
+	if strings.HasPrefix(name, "\x00") {
+		if i := strings.IndexByte(name[1:], 0); i >= 0 {
+			return name[i+2:]
+		}
+	}
+	return name
+}
+
+// toJSONCompatible 将解码结果转换为可JSON序列化的值
+func toJSONCompatible(value any) any {
+	switch v := value.(type) {
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = toJSONCompatible(item)
+		}
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = toJSONCompatible(item)
+		}
+		return result
+	case *Object:
+		return toJSONCompatible(v.Properties)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// ErrNotArray 值不是PHP数组
+var ErrNotArray = errors.New("phpserialize: value is not an array")
+
+// ToMap 将解码得到的数组统一转换为 map[string]any（列表的键为下标字符串）
+func ToMap(value any) (map[string]any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, nil
+	case []any:
+		m := make(map[string]any, len(v))
+		for i, item := range v {
+			m[strconv.Itoa(i)] = item
+		}
+		return m, nil
+	case *Object:
+		return v.Properties, nil
+	default:
+		return nil, ErrNotArray
+	}
+}
@@ -53,6 +53,18 @@ func (c *CacheOpt) Set(key string, value interface{}, args ...time.Duration) err
 	return c.redis.Set(c.keygen(key), value, expire)
 }
 
+// SetNX 键不存在时设置键值（原子操作），返回是否设置成功。
+//
+// 示例:
+//
+//	ok, err := SetNX(key, "1", time.Minute)
+//	if err == nil && !ok {
+//	    // 键已存在
+//	}
+func (c *CacheOpt) SetNX(key string, value interface{}, expire time.Duration) (bool, error) {
+	return c.redis.SetNX(c.keygen(key), value, expire)
+}
+
 // GetString 根据键值，返回字符串值。
 // 如果键不存在或发生错误，则返回默认值（如果提供）。
 //
@@ -95,22 +95,43 @@ func (i *Instance) Set(key string, value interface{}, args ...time.Duration) err
 		expire = args[0]
 	}
 
-	var jsonString string
-	switch v := value.(type) {
-	case string:
-		jsonString = v
-	default:
-		jsonBytes, err := json.Marshal(value)
-		if err != nil {
-			return err
-		}
-		jsonString = string(jsonBytes)
+	jsonString, err := marshalValue(value)
+	if err != nil {
+		return err
 	}
 
-	err := i.Client.Set(i.Context, key, string(jsonString), expire).Err()
+	err = i.Client.Set(i.Context, key, jsonString, expire).Err()
 	return err
 }
 
+// SetNX 键不存在时设置键值（原子操作），返回是否设置成功；值的处理同 Set，expire 为0时永不过期。
+//
+// 示例:
+//
+//	ok, err := SetNX("lock:order:1", "1", 10*time.Second)
+//	if err == nil && !ok {
+//	    // 键已存在
+//	}
+func (i *Instance) SetNX(key string, value interface{}, expire time.Duration) (bool, error) {
+	jsonString, err := marshalValue(value)
+	if err != nil {
+		return false, err
+	}
+	return i.Client.SetNX(i.Context, key, jsonString, expire).Result()
+}
+
+// marshalValue 字符串原样保存，其他类型转换为 JSON
+func marshalValue(value interface{}) (string, error) {
+	if v, ok := value.(string); ok {
+		return v, nil
+	}
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonBytes), nil
+}
+
 // GetString 根据键值，返回字符串值。
 // 如果键不存在或发生错误，则返回默认值（如果提供）。
 //
@@ -0,0 +1,150 @@
+package region
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+//go:embed data/*.txt
+var dataFS embed.FS
+
+var (
+	defaultOnce sync.Once
+	defaultData atomic.Pointer[Data]
+)
+
+// Default 默认数据，未调用 SetDefault 时为内置的省级数据
+func Default() *Data {
+	defaultOnce.Do(func() {
+		if defaultData.Load() != nil {
+			return
+		}
+		f, err := dataFS.Open("data/province.txt")
+		if err != nil {
+			panic("region: 缺少内置数据 data/province.txt")
+		}
+		defer f.Close()
+		d, err := Parse(f)
+		if err != nil {
+			panic(err)
+		}
+		defaultData.CompareAndSwap(nil, d)
+	})
+	return defaultData.Load()
+}
+
+// SetDefault 替换默认数据，通常在启动时加载完整数据后调用
+//
+// 示例:
+//
+//	d, err := region.LoadFile("./data/region.csv")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	region.SetDefault(d)
+func SetDefault(d *Data) {
+	if d != nil {
+		defaultData.Store(d)
+	}
+}
+
+// LoadFile 从数据文件加载，格式见 Parse
+func LoadFile(path string) (*Data, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse 解析行政区划数据，支持两种格式：
+//   - 文本：每行“代码 名称 [上级代码]”，以制表符、逗号或空格分隔；空行、#开头的行及代码不是数字的行（如表头）被忽略
+//   - JSON：Region 数组，如 [{"code":"110000","name":"北京市"}]
+//
+// 代码可以是6位或国家统计局的12位格式，12位代码只保留县级及以上（后6位为0）的区划
+func Parse(r io.Reader) (*Data, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		regions := make([]Region, 0)
+		if err = json.Unmarshal(trimmed, &regions); err != nil {
+			return nil, fmt.Errorf("region: 解析JSON失败: %w", err)
+		}
+		for i := range regions {
+			regions[i].Code, _ = normalizeCode(regions[i].Code)
+			regions[i].ParentCode, _ = normalizeCode(regions[i].ParentCode)
+		}
+		return NewData(regions)
+	}
+
+	regions := make([]Region, 0, 4000)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == '\t' || r == ',' || r == ' ' || r == '　'
+		})
+		if len(fields) < 2 || !isDigits(fields[0]) {
+			continue
+		}
+		code, ok := normalizeCode(fields[0])
+		if !ok {
+			continue
+		}
+		if !IsCode(code) {
+			return nil, fmt.Errorf("region: 第%d行: %w: %s", line, ErrInvalidCode, fields[0])
+		}
+		item := Region{Code: code, Name: strings.Trim(fields[1], `"`)}
+		if len(fields) > 2 && isDigits(fields[2]) {
+			item.ParentCode, _ = normalizeCode(fields[2])
+		}
+		regions = append(regions, item)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewData(regions)
+}
+
+// normalizeCode 将12位代码转为6位，乡镇及以下级别返回false
+func normalizeCode(code string) (string, bool) {
+	code = strings.Trim(strings.TrimSpace(code), `"`)
+	if len(code) == 12 {
+		if code[6:] != "000000" {
+			return "", false
+		}
+		code = code[:6]
+	}
+	return code, true
+}
+
+func isDigits(s string) bool {
+	s = strings.Trim(s, `"`)
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,313 @@
+// Package region 中国省、市、区县三级行政区划
+//
+// 行政区划代码为6位数字：前2位为省级，前4位为地级，后2位为县级。
+// 内置数据仅包含省级行政区，完整的三级数据通过 LoadFile/Parse 从民政部或国家统计局发布的数据文件加载，
+// 也可以使用 Import 导入数据库后通过 LoadDB 读取。
+package region
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Level 行政区划级别
+type Level int
+
+const (
+	LevelProvince Level = 1 // 省级：省、自治区、直辖市、特别行政区
+	LevelCity     Level = 2 // 地级：地级市、地区、自治州、盟
+	LevelDistrict Level = 3 // 县级：市辖区、县级市、县、自治县、旗
+)
+
+// 校验错误
+var (
+	ErrInvalidCode = errors.New("region: 无效的行政区划代码")
+	ErrNotFound    = errors.New("region: 行政区划不存在")
+	ErrMismatch    = errors.New("region: 行政区划上下级不匹配")
+)
+
+// Region 行政区划
+type Region struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	ParentCode string `json:"parent_code"` // 省级为空
+	Level      Level  `json:"level"`
+}
+
+// Node 树形结构的节点
+type Node struct {
+	Region
+	Children []*Node `json:"children,omitempty"`
+}
+
+// Data 行政区划数据，创建后只读，可以并发使用
+type Data struct {
+	list     []Region            // 按代码排序
+	byCode   map[string]int      // 代码 => list 下标
+	children map[string][]string // 上级代码 => 下级代码，省级的上级代码为空
+	short    map[string]string   // 代码 => 去掉“省”“市”“自治区”等后缀的简称
+}
+
+// NewData 由行政区划列表创建数据，Level、ParentCode 为空时根据代码推算；
+// 县级区划所属的地级代码不存在时（如直辖市的区、省直辖县级市），上级为所属的省级
+func NewData(regions []Region) (*Data, error) {
+	d := &Data{
+		list:     make([]Region, 0, len(regions)),
+		byCode:   make(map[string]int, len(regions)),
+		children: make(map[string][]string),
+		short:    make(map[string]string, len(regions)),
+	}
+
+	known := make(map[string]bool, len(regions))
+	for _, r := range regions {
+		if !IsCode(r.Code) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCode, r.Code)
+		}
+		if known[r.Code] {
+			return nil, fmt.Errorf("region: 重复的行政区划代码 %s", r.Code)
+		}
+		known[r.Code] = true
+	}
+
+	for _, r := range regions {
+		if r.Level == 0 {
+			r.Level = CodeLevel(r.Code)
+		}
+		if r.ParentCode == "" && r.Level > LevelProvince {
+			r.ParentCode = r.Code[:2] + "0000"
+			if city := r.Code[:4] + "00"; r.Level == LevelDistrict && known[city] {
+				r.ParentCode = city
+			}
+		}
+		d.list = append(d.list, r)
+	}
+	sort.Slice(d.list, func(i, j int) bool { return d.list[i].Code < d.list[j].Code })
+
+	for i, r := range d.list {
+		d.byCode[r.Code] = i
+		d.children[r.ParentCode] = append(d.children[r.ParentCode], r.Code)
+		d.short[r.Code] = ShortName(r.Name)
+	}
+	return d, nil
+}
+
+// IsCode 判断是否为6位数字的行政区划代码
+func IsCode(code string) bool {
+	if len(code) != 6 || code == "000000" {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// CodeLevel 根据代码推算级别，代码无效时返回0
+func CodeLevel(code string) Level {
+	switch {
+	case !IsCode(code):
+		return 0
+	case code[2:] == "0000":
+		return LevelProvince
+	case code[4:] == "00":
+		return LevelCity
+	default:
+		return LevelDistrict
+	}
+}
+
+// nameSuffixes 计算简称时去掉的后缀，长的在前
+var nameSuffixes = []string{
+	"维吾尔自治区", "特别行政区", "壮族自治区", "回族自治区", "自治区", "自治州", "自治县", "自治旗",
+	"地区", "林区", "省", "市", "盟", "区", "县", "旗",
+}
+
+// ShortName 去掉“省”“市”“自治区”等后缀的简称，如 广西壮族自治区 → 广西、朝阳区 → 朝阳
+func ShortName(name string) string {
+	name = strings.TrimSpace(name)
+	for _, suffix := range nameSuffixes {
+		if short := strings.TrimSuffix(name, suffix); short != name && short != "" {
+			return short
+		}
+	}
+	return name
+}
+
+// Len 行政区划数量
+func (d *Data) Len() int {
+	return len(d.list)
+}
+
+// All 全部行政区划，按代码排序
+func (d *Data) All() []Region {
+	return append([]Region(nil), d.list...)
+}
+
+// Get 按代码获取行政区划
+func (d *Data) Get(code string) (Region, bool) {
+	i, ok := d.byCode[code]
+	if !ok {
+		return Region{}, false
+	}
+	return d.list[i], true
+}
+
+// Name 获取行政区划名称，不存在时返回空字符串
+func (d *Data) Name(code string) string {
+	r, _ := d.Get(code)
+	return r.Name
+}
+
+// Exists 判断代码是否存在
+func (d *Data) Exists(code string) bool {
+	_, ok := d.byCode[code]
+	return ok
+}
+
+// Provinces 全部省级行政区
+func (d *Data) Provinces() []Region {
+	return d.Children("")
+}
+
+// Children 获取直接下级，code 为空时返回省级
+func (d *Data) Children(code string) []Region {
+	codes := d.children[code]
+	list := make([]Region, 0, len(codes))
+	for _, c := range codes {
+		list = append(list, d.list[d.byCode[c]])
+	}
+	return list
+}
+
+// Path 获取从省级到当前区划的完整路径，代码不存在时返回nil
+func (d *Data) Path(code string) []Region {
+	path := make([]Region, 0, 3)
+	for code != "" {
+		r, ok := d.Get(code)
+		if !ok {
+			return nil
+		}
+		path = append(path, r)
+		code = r.ParentCode
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// FullName 获取完整名称，如 FullName("110105") 返回 北京市朝阳区，sep 为各级之间的分隔符
+func (d *Data) FullName(code string, sep ...string) string {
+	path := d.Path(code)
+	names := make([]string, 0, len(path))
+	for _, r := range path {
+		names = append(names, r.Name)
+	}
+	return strings.Join(names, strings.Join(sep, ""))
+}
+
+// Find 按名称在 parentCode 的直接下级中查找，parentCode 为空时在省级中查找；
+// 优先全称匹配，其次简称匹配，如 “广西”“朝阳”
+func (d *Data) Find(name, parentCode string) (Region, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Region{}, false
+	}
+	codes := d.children[parentCode]
+	for _, c := range codes {
+		if r := d.list[d.byCode[c]]; r.Name == name {
+			return r, true
+		}
+	}
+	short := ShortName(name)
+	for _, c := range codes {
+		if d.short[c] == short {
+			return d.list[d.byCode[c]], true
+		}
+	}
+	return Region{}, false
+}
+
+// FindAll 按名称在全部区划中查找，返回所有全称或简称相同的区划（重名的区县较多，如“朝阳区”）
+func (d *Data) FindAll(name string) []Region {
+	name = strings.TrimSpace(name)
+	short := ShortName(name)
+	list := make([]Region, 0)
+	for _, r := range d.list {
+		if r.Name == name || d.short[r.Code] == short {
+			list = append(list, r)
+		}
+	}
+	return list
+}
+
+// Resolve 按名称逐级解析，如 Resolve("广东省", "深圳市", "南山区")，返回各级区划
+// 直辖市、省直辖县级市可以跳过地级，如 Resolve("北京", "朝阳区")
+func (d *Data) Resolve(names ...string) ([]Region, error) {
+	path := make([]Region, 0, len(names))
+	parent := ""
+	for _, name := range names {
+		r, ok := d.Find(name, parent)
+		if !ok {
+			return path, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		path = append(path, r)
+		parent = r.Code
+	}
+	return path, nil
+}
+
+// Validate 校验各级代码均存在且依次为上下级关系，如 Validate("440000", "440300", "440305")；
+// 空代码视为未选择，其后不能再有代码
+func (d *Data) Validate(codes ...string) error {
+	parent := ""
+	for i, code := range codes {
+		if code == "" {
+			for _, rest := range codes[i+1:] {
+				if rest != "" {
+					return fmt.Errorf("%w: 缺少 %s 的上级", ErrMismatch, rest)
+				}
+			}
+			return nil
+		}
+		if !IsCode(code) {
+			return fmt.Errorf("%w: %s", ErrInvalidCode, code)
+		}
+		r, ok := d.Get(code)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrNotFound, code)
+		}
+		if r.ParentCode != parent {
+			return fmt.Errorf("%w: %s 不属于 %s", ErrMismatch, code, parent)
+		}
+		parent = code
+	}
+	return nil
+}
+
+// Tree 构建树形结构，maxLevel 为最深的级别，0 表示全部
+//
+// 示例:
+//
+//	// 省市两级联动
+//	c.JSON(http.StatusOK, region.Default().Tree(region.LevelCity))
+func (d *Data) Tree(maxLevel Level) []*Node {
+	var build func(parent string) []*Node
+	build = func(parent string) []*Node {
+		codes := d.children[parent]
+		nodes := make([]*Node, 0, len(codes))
+		for _, c := range codes {
+			node := &Node{Region: d.list[d.byCode[c]]}
+			if maxLevel == 0 || node.Level < maxLevel {
+				node.Children = build(c)
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build("")
+}
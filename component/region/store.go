@@ -0,0 +1,107 @@
+package region
+
+import (
+	"context"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/orm/bulk"
+	"gorm.io/gorm"
+)
+
+// Model 行政区划数据表
+type Model struct {
+	Code       string `gorm:"column:code;size:6;primaryKey;comment:行政区划代码" json:"code"`
+	Name       string `gorm:"column:name;size:64;not null;comment:名称" json:"name"`
+	ShortName  string `gorm:"column:short_name;size:64;default:'';comment:简称" json:"short_name"`
+	ParentCode string `gorm:"column:parent_code;size:6;default:'';index;comment:上级代码，省级为空" json:"parent_code"`
+	Level      int    `gorm:"column:level;default:0;comment:级别 1省 2市 3区县" json:"level"`
+}
+
+// ImportOptions 导入配置
+type ImportOptions struct {
+	Table       string `default:"region"` // 表名（含前缀）
+	AutoMigrate bool   // 是否自动创建数据表
+	Prune       bool   // 是否删除数据文件中已不存在的区划（撤销、合并的区县）
+	BatchSize   int    // 每批写入条数，默认1000
+}
+
+// Import 将数据导入数据库，代码已存在时更新名称、上级及级别，在事务中执行
+//
+// 示例:
+//
+//	d, err := region.LoadFile("./data/region.csv")
+//	if err != nil {
+//		return err
+//	}
+//	err = region.Import(ctx, db.GetDb(), d, region.ImportOptions{AutoMigrate: true, Prune: true})
+func Import(ctx context.Context, db *gorm.DB, d *Data, opts ...ImportOptions) error {
+	opt := importOptions(opts)
+	if opt.AutoMigrate {
+		if err := db.WithContext(ctx).Table(opt.Table).AutoMigrate(&Model{}); err != nil {
+			return err
+		}
+	}
+
+	rows := make([]Model, 0, d.Len())
+	codes := make([]string, 0, d.Len())
+	for _, r := range d.list {
+		rows = append(rows, Model{
+			Code:       r.Code,
+			Name:       r.Name,
+			ShortName:  d.short[r.Code],
+			ParentCode: r.ParentCode,
+			Level:      int(r.Level),
+		})
+		codes = append(codes, r.Code)
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tx = tx.Table(opt.Table)
+		bulkOpt := bulk.Options{BatchSize: opt.BatchSize, StopOnError: true}
+		columns := []string{"name", "short_name", "parent_code", "level"}
+		if err := bulk.Upsert(tx, rows, []string{"code"}, columns, bulkOpt).Err(); err != nil {
+			return err
+		}
+		if !opt.Prune {
+			return nil
+		}
+		if len(codes) == 0 {
+			return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&Model{}).Error
+		}
+		return tx.Where("code NOT IN ?", codes).Delete(&Model{}).Error
+	})
+}
+
+// LoadDB 从数据库加载数据
+//
+// 示例:
+//
+//	d, err := region.LoadDB(ctx, db.GetDb())
+//	if err == nil {
+//		region.SetDefault(d)
+//	}
+func LoadDB(ctx context.Context, db *gorm.DB, opts ...ImportOptions) (*Data, error) {
+	opt := importOptions(opts)
+	rows := make([]Model, 0)
+	if err := db.WithContext(ctx).Table(opt.Table).Order("code").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	regions := make([]Region, 0, len(rows))
+	for _, row := range rows {
+		regions = append(regions, Region{
+			Code:       row.Code,
+			Name:       row.Name,
+			ParentCode: row.ParentCode,
+			Level:      Level(row.Level),
+		})
+	}
+	return NewData(regions)
+}
+
+func importOptions(opts []ImportOptions) ImportOptions {
+	var opt ImportOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	return opt
+}
@@ -0,0 +1,69 @@
+package region
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = RegisterValidation(v)
+	}
+}
+
+// levelNames 校验规则参数对应的级别
+var levelNames = map[string]Level{
+	"province": LevelProvince,
+	"city":     LevelCity,
+	"district": LevelDistrict,
+}
+
+// RegisterValidation 注册 region、region_of 校验规则，gin 默认的校验器已自动注册，使用 Default() 的数据校验
+//   - region：代码存在，可指定级别，如 region=province、region=city、region=district
+//   - region_of：代码是指定字段的直接下级，如 region_of=ProvinceCode
+//
+// 零值同样会被校验，允许为空时配合 omitempty 使用
+//
+// 示例:
+//
+//	type AddressForm struct {
+//		ProvinceCode string `json:"province_code" binding:"region=province"`
+//		CityCode     string `json:"city_code" binding:"region=city,region_of=ProvinceCode"`
+//		DistrictCode string `json:"district_code" binding:"omitempty,region_of=CityCode"`
+//	}
+func RegisterValidation(v *validator.Validate) error {
+	if err := v.RegisterValidation("region", validateRegion); err != nil {
+		return err
+	}
+	return v.RegisterValidation("region_of", validateRegionOf)
+}
+
+// validateRegion 校验代码存在且级别匹配
+func validateRegion(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return false
+	}
+	r, ok := Default().Get(fl.Field().String())
+	if !ok {
+		return false
+	}
+	if param := fl.Param(); param != "" {
+		level, ok := levelNames[param]
+		return ok && r.Level == level
+	}
+	return true
+}
+
+// validateRegionOf 校验代码为指定字段的直接下级
+func validateRegionOf(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return false
+	}
+	parent, kind, _, found := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+	if !found || kind != reflect.String {
+		return false
+	}
+	r, ok := Default().Get(fl.Field().String())
+	return ok && r.ParentCode == parent.String()
+}
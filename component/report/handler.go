@@ -0,0 +1,148 @@
+package report
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"strings"
+	"time"
+)
+
+// Handler 报表接口，需自行在路由分组上添加登录、权限等中间件
+type Handler struct {
+	report *Report
+}
+
+// Handler 获取报表接口
+//
+// 示例:
+//
+//	admin := r.Group("/admin/report/sales", middleware.LoginRequired())
+//	sales.Handler().RegisterRoutes(admin)
+func (r *Report) Handler() *Handler {
+	return &Handler{report: r}
+}
+
+// RegisterRoutes 注册路由：
+//   - GET /data     时间序列或维度汇总表，返回 ListData
+//   - GET /summary  时间范围内的指标汇总
+//
+// 查询参数：start、end（日期或日期时间，仅日期时 end 包含当天）、interval（hour/day/week/month/year）、
+// dimensions（维度名称，逗号分隔）、filter[维度名称]（多个值以逗号分隔）、order（前缀 - 表示降序）、page、page_size、fill
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/data", h.Data)
+	r.GET("/summary", h.Summary)
+}
+
+type queryForm struct {
+	Start      string `form:"start" binding:"max=32"`
+	End        string `form:"end" binding:"max=32"`
+	Interval   string `form:"interval" binding:"omitempty,oneof=hour day week month year"`
+	Dimensions string `form:"dimensions" binding:"max=255"`
+	Order      string `form:"order" binding:"max=64"`
+	Page       int    `form:"page" binding:"min=0"`
+	PageSize   int    `form:"page_size" binding:"min=0,max=1000"`
+	Fill       bool   `form:"fill"`
+}
+
+// Data 时间序列或维度汇总表
+func (h *Handler) Data(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	q, err := h.bind(c)
+	if err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	listData, err := h.report.Run(c.Request.Context(), q)
+	if err != nil {
+		base.Error(wrapError(err))
+		return
+	}
+	base.Success(listData)
+}
+
+// Summary 指标汇总
+func (h *Handler) Summary(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	q, err := h.bind(c)
+	if err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	row, err := h.report.Summary(c.Request.Context(), q)
+	if err != nil {
+		base.Error(wrapError(err))
+		return
+	}
+	base.Success(map[string]any(row))
+}
+
+// bind 解析查询参数
+func (h *Handler) bind(c *gin.Context) (Query, error) {
+	var form queryForm
+	if err := c.ShouldBindQuery(&form); err != nil {
+		return Query{}, err
+	}
+	q := Query{
+		Interval: Interval(form.Interval),
+		OrderBy:  form.Order,
+		Page:     form.Page,
+		PageSize: form.PageSize,
+		Fill:     form.Fill,
+	}
+	if form.Start != "" {
+		start, err := helper.ParseDateTime(form.Start)
+		if err != nil {
+			return q, errors.New("开始时间格式不正确")
+		}
+		q.Start = start
+	}
+	if form.End != "" {
+		end, err := helper.ParseDateTime(form.End)
+		if err != nil {
+			return q, errors.New("结束时间格式不正确")
+		}
+		if isDate(form.End) {
+			end = end.AddDate(0, 0, 1) // 仅日期时包含当天
+		}
+		q.End = end
+	}
+	for _, name := range strings.Split(form.Dimensions, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			q.Dimensions = append(q.Dimensions, name)
+		}
+	}
+	if filters := c.QueryMap("filter"); len(filters) > 0 {
+		q.Filters = make(map[string]any, len(filters))
+		for name, value := range filters {
+			if values := strings.Split(value, ","); len(values) > 1 {
+				q.Filters[name] = values
+			} else {
+				q.Filters[name] = value
+			}
+		}
+	}
+	return q, nil
+}
+
+// isDate 是否为仅包含日期的字符串
+func isDate(value string) bool {
+	for _, layout := range []string{helper.DateLayout, "2006/01/02"} {
+		if _, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapError 查询条件错误直接返回，其他错误（如数据库错误）不输出给前端
+func wrapError(err error) error {
+	var appErr *errcode.AppError
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return errcode.Wrap(err, errcode.SystemBusy, "")
+}
@@ -0,0 +1,119 @@
+package report
+
+import (
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"time"
+)
+
+// Interval 时间粒度
+type Interval string
+
+// 时间粒度，时间段的格式分别为 2006-01-02 15:00、2006-01-02、2006-01-02（周一）、2006-01、2006
+const (
+	Hour  Interval = "hour"
+	Day   Interval = "day"
+	Week  Interval = "week"
+	Month Interval = "month"
+	Year  Interval = "year"
+)
+
+// layout 时间段的格式
+func (i Interval) layout() string {
+	switch i {
+	case Hour:
+		return "2006-01-02 15:00"
+	case Day, Week:
+		return "2006-01-02"
+	case Month:
+		return "2006-01"
+	case Year:
+		return "2006"
+	}
+	return ""
+}
+
+// expr 按数据库生成时间段表达式，结果为 layout 格式的文本
+func (i Interval) expr(dialect, field string) (string, error) {
+	if i.layout() == "" {
+		return "", fmt.Errorf("report: 不支持的时间粒度 %s", i)
+	}
+	switch dialect {
+	case "mysql":
+		if i == Week {
+			return "DATE_FORMAT(DATE_SUB(" + field + ", INTERVAL WEEKDAY(" + field + ") DAY), '%Y-%m-%d')", nil
+		}
+		format := map[Interval]string{Hour: "%Y-%m-%d %H:00", Day: "%Y-%m-%d", Month: "%Y-%m", Year: "%Y"}[i]
+		return "DATE_FORMAT(" + field + ", '" + format + "')", nil
+	case "postgres":
+		if i == Week {
+			return "to_char(date_trunc('week', " + field + "), 'YYYY-MM-DD')", nil
+		}
+		format := map[Interval]string{Hour: "YYYY-MM-DD HH24:00", Day: "YYYY-MM-DD", Month: "YYYY-MM", Year: "YYYY"}[i]
+		return "to_char(" + field + ", '" + format + "')", nil
+	case "sqlite":
+		if i == Week {
+			// 先移到本周日（当天为周日时不变），再减6天即为周一
+			return "strftime('%Y-%m-%d', " + field + ", 'weekday 0', '-6 days')", nil
+		}
+		format := map[Interval]string{Hour: "%Y-%m-%d %H:00", Day: "%Y-%m-%d", Month: "%Y-%m", Year: "%Y"}[i]
+		return "strftime('" + format + "', " + field + ")", nil
+	default:
+		return "", fmt.Errorf("report: 数据库 %s 不支持按时间分组", dialect)
+	}
+}
+
+// truncate 时间所在时间段的开始时间
+func (i Interval) truncate(t time.Time) time.Time {
+	switch i {
+	case Hour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case Day:
+		return helper.StartOfDay(t)
+	case Week:
+		return helper.StartOfWeek(t)
+	case Month:
+		return helper.StartOfMonth(t)
+	case Year:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	}
+	return t
+}
+
+// next 下一个时间段的开始时间
+func (i Interval) next(t time.Time) time.Time {
+	switch i {
+	case Hour:
+		return t.Add(time.Hour)
+	case Day:
+		return t.AddDate(0, 0, 1)
+	case Week:
+		return t.AddDate(0, 0, 7)
+	case Month:
+		return t.AddDate(0, 1, 0)
+	case Year:
+		return t.AddDate(1, 0, 0)
+	}
+	return t
+}
+
+// count 时间范围 [start, end) 内的时间段数量，超过 limit 时返回 limit，粒度无效时返回-1
+func (i Interval) count(start, end time.Time, limit int) int {
+	if i.layout() == "" {
+		return -1
+	}
+	n := 0
+	for t := i.truncate(start); t.Before(end) && n < limit; t = i.next(t) {
+		n++
+	}
+	return n
+}
+
+// periods 时间范围 [start, end) 内的全部时间段
+func (i Interval) periods(start, end time.Time) []string {
+	periods := make([]string, 0)
+	for t := i.truncate(start); t.Before(end); t = i.next(t) {
+		periods = append(periods, t.Format(i.layout()))
+	}
+	return periods
+}
@@ -0,0 +1,514 @@
+// Package report 统计报表：声明指标（计数、求和、平均值等）及维度，按时间粒度或维度分组汇总，
+// 以 jcbaseGo.ListData 返回时间序列或维度汇总表，供仪表盘图表使用，无需为每个图表手写SQL
+//
+// 语句通过 query.Builder 生成，时间分组支持 MySQL、PostgreSQL 及 SQLite。
+// 时间段按数据库中保存的时间值计算，应用与数据库时区不一致时需自行统一。
+package report
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/orm/query"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"gorm.io/gorm"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// PeriodKey 时间段在结果中的键名
+const PeriodKey = "period"
+
+// Agg 聚合方式
+type Agg string
+
+// 聚合方式
+const (
+	AggCount         Agg = "count"
+	AggCountDistinct Agg = "count_distinct"
+	AggSum           Agg = "sum"
+	AggAvg           Agg = "avg"
+	AggMax           Agg = "max"
+	AggMin           Agg = "min"
+)
+
+// Metric 指标
+type Metric struct {
+	Name  string // 指标名称，作为结果中的键名，仅允许字母、数字及下划线
+	Agg   Agg    // 聚合方式
+	Field string // 聚合的列或表达式，计数时可为空；不能来自用户输入
+}
+
+// Count 计数指标
+func Count(name string) Metric {
+	return Metric{Name: name, Agg: AggCount}
+}
+
+// CountDistinct 去重计数指标，如 CountDistinct("buyers", "user_id")
+func CountDistinct(name, field string) Metric {
+	return Metric{Name: name, Agg: AggCountDistinct, Field: field}
+}
+
+// Sum 求和指标
+func Sum(name, field string) Metric {
+	return Metric{Name: name, Agg: AggSum, Field: field}
+}
+
+// Avg 平均值指标
+func Avg(name, field string) Metric {
+	return Metric{Name: name, Agg: AggAvg, Field: field}
+}
+
+// Max 最大值指标
+func Max(name, field string) Metric {
+	return Metric{Name: name, Agg: AggMax, Field: field}
+}
+
+// Min 最小值指标
+func Min(name, field string) Metric {
+	return Metric{Name: name, Agg: AggMin, Field: field}
+}
+
+// expr 聚合表达式
+func (m Metric) expr() (string, error) {
+	switch m.Agg {
+	case AggCount:
+		if m.Field == "" {
+			return "COUNT(*)", nil
+		}
+		return "COUNT(" + m.Field + ")", nil
+	case AggCountDistinct, AggSum, AggAvg, AggMax, AggMin:
+		if m.Field == "" {
+			return "", fmt.Errorf("report: 指标 %s 未设置聚合的列", m.Name)
+		}
+		if m.Agg == AggCountDistinct {
+			return "COUNT(DISTINCT " + m.Field + ")", nil
+		}
+		return strings.ToUpper(string(m.Agg)) + "(" + m.Field + ")", nil
+	default:
+		return "", fmt.Errorf("report: 指标 %s 的聚合方式 %s 无效", m.Name, m.Agg)
+	}
+}
+
+// Definition 报表定义
+type Definition struct {
+	Name      string // 报表名称，用于区分缓存，需唯一
+	Table     string // 查询的表，可带别名或为子查询，如 "`order` o"
+	TimeField string // 时间字段，用于时间范围筛选及按时间分组，默认 created_at
+
+	Metrics    []Metric          // 指标
+	Dimensions map[string]string // 可用的维度，名称 => 列或表达式；名称可来自用户输入，表达式不能
+
+	// Scope 固定的连接及筛选条件，如只统计已支付的订单
+	Scope func(b *query.Builder)
+}
+
+// Query 查询条件
+type Query struct {
+	Start    time.Time // 开始时间（含），按 TimeField 筛选，零值表示不限制
+	End      time.Time // 结束时间（不含），零值表示不限制
+	Interval Interval  // 时间粒度，为空时不按时间分组，生成维度汇总表；设置时需指定 Start 及 End
+
+	Dimensions []string       // 分组的维度名称
+	Filters    map[string]any // 维度筛选，键为维度名称，值为切片时使用 IN
+
+	OrderBy  string // 排序的指标、维度名称或 period，前缀 - 表示降序；默认按时间段升序，无时间分组时按第一个指标降序
+	Page     int    // 页码，从1开始
+	PageSize int    // 每页条数，为0时返回全部
+	Fill     bool   // 补齐没有数据的时间段（指标为0），仅在按时间分组、无维度且返回全部时生效
+}
+
+// Row 结果行，键为 period、维度名称及指标名称；指标为数值，没有数据时为0
+type Row map[string]any
+
+// Cache 报表结果缓存
+type Cache interface {
+	Set(key string, value interface{}, args ...time.Duration) error
+	GetString(key string) (string, error)
+}
+
+// Options 配置
+type Options struct {
+	Cache      Cache         // 结果缓存，为空时不缓存
+	CacheTTL   time.Duration // 缓存时间，默认5分钟
+	MaxBuckets int           `default:"2000"` // 按时间分组时最多的时间段数量，防止范围过大
+}
+
+// Report 报表
+//
+// 示例:
+//
+//	sales := report.New(db.GetDb(), report.Definition{
+//	    Name:      "sales",
+//	    Table:     "`order`",
+//	    TimeField: "paid_at",
+//	    Metrics:   []report.Metric{report.Count("orders"), report.Sum("amount", "pay_amount"), report.CountDistinct("buyers", "user_id")},
+//	    Dimensions: map[string]string{"channel": "channel", "province": "province"},
+//	    Scope: func(b *query.Builder) {
+//	        b.Where("status = ?", 1)
+//	    },
+//	}, report.Options{Cache: redis.NewCache(rds)})
+//
+//	// 近30天每日销售额
+//	listData, err := sales.Run(ctx, report.Query{Start: start, End: end, Interval: report.Day, Fill: true})
+//	// 各渠道销售排行
+//	listData, err = sales.Run(ctx, report.Query{Start: start, End: end, Dimensions: []string{"channel"}, OrderBy: "-amount"})
+type Report struct {
+	db  *gorm.DB
+	def Definition
+	opt Options
+	err error // 报表定义的错误，在查询时返回
+}
+
+var nameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// New 创建报表
+func New(db *gorm.DB, def Definition, opts ...Options) *Report {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.CacheTTL <= 0 {
+		opt.CacheTTL = 5 * time.Minute
+	}
+	if def.TimeField == "" {
+		def.TimeField = "created_at"
+	}
+	return &Report{db: db, def: def, opt: opt, err: def.validate()}
+}
+
+// Metrics 报表的指标
+func (r *Report) Metrics() []Metric {
+	return r.def.Metrics
+}
+
+// Run 执行查询，结果的 List 为 []Row
+func (r *Report) Run(ctx context.Context, q Query) (jcbaseGo.ListData, error) {
+	listData := jcbaseGo.ListData{List: make([]Row, 0), Page: max(q.Page, 1), PageSize: q.PageSize}
+	if r.err != nil {
+		return listData, r.err
+	}
+	if err := r.check(q); err != nil {
+		return listData, err
+	}
+
+	key := r.cacheKey("run", q)
+	if r.loadCache(key, &listData) {
+		return listData, nil
+	}
+
+	b, err := r.builder(ctx, q, true)
+	if err != nil {
+		return listData, err
+	}
+	orderBy, err := r.orderBy(q)
+	if err != nil {
+		return listData, err
+	}
+	b.OrderBy(orderBy)
+
+	raw := make([]map[string]any, 0)
+	if q.PageSize > 0 {
+		if listData, err = b.Page(q.Page, q.PageSize, &raw); err != nil {
+			return listData, err
+		}
+	} else if err = b.Scan(&raw); err != nil {
+		return listData, err
+	}
+
+	rows := r.normalize(raw)
+	if q.Fill && q.Interval != "" && len(q.Dimensions) == 0 && q.PageSize <= 0 {
+		rows = r.fill(rows, q)
+	}
+	if q.PageSize <= 0 {
+		listData.Total = len(rows)
+	}
+	listData.List = rows
+	r.saveCache(key, listData)
+	return listData, nil
+}
+
+// Summary 汇总时间范围内的全部指标，不分组，可用于仪表盘的指标卡片
+func (r *Report) Summary(ctx context.Context, q Query) (Row, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	q.Interval, q.Dimensions, q.OrderBy, q.Page, q.PageSize, q.Fill = "", nil, "", 0, 0, false
+	if err := r.check(q); err != nil {
+		return nil, err
+	}
+
+	var row Row
+	key := r.cacheKey("summary", q)
+	if r.loadCache(key, &row) {
+		return row, nil
+	}
+	b, err := r.builder(ctx, q, false)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([]map[string]any, 0, 1)
+	if err = b.Scan(&raw); err != nil {
+		return nil, err
+	}
+	if rows := r.normalize(raw); len(rows) > 0 {
+		row = rows[0]
+	} else {
+		row = r.zeroRow()
+	}
+	r.saveCache(key, row)
+	return row, nil
+}
+
+// ----- 内部方法 ----- /
+
+// validate 检查报表定义
+func (d Definition) validate() error {
+	if d.Name == "" || d.Table == "" {
+		return errors.New("report: 报表名称及查询的表不能为空")
+	}
+	if len(d.Metrics) == 0 {
+		return errors.New("report: 至少需要一个指标")
+	}
+	names := map[string]bool{PeriodKey: true}
+	for _, m := range d.Metrics {
+		if !nameRegexp.MatchString(m.Name) || names[m.Name] {
+			return fmt.Errorf("report: 指标名称 %s 无效或重复", m.Name)
+		}
+		if _, err := m.expr(); err != nil {
+			return err
+		}
+		names[m.Name] = true
+	}
+	for name := range d.Dimensions {
+		if !nameRegexp.MatchString(name) || names[name] {
+			return fmt.Errorf("report: 维度名称 %s 无效或与指标重复", name)
+		}
+	}
+	return nil
+}
+
+// check 检查查询条件，条件无效时返回 errcode.ParamInvalid 错误
+func (r *Report) check(q Query) error {
+	for _, name := range q.Dimensions {
+		if _, ok := r.def.Dimensions[name]; !ok {
+			return errcode.Newf(errcode.ParamInvalid, "不支持的统计维度: %s", name)
+		}
+	}
+	for name := range q.Filters {
+		if _, ok := r.def.Dimensions[name]; !ok {
+			return errcode.Newf(errcode.ParamInvalid, "不支持的统计维度: %s", name)
+		}
+	}
+	if q.Interval == "" {
+		return nil
+	}
+	if q.Start.IsZero() || q.End.IsZero() || !q.Start.Before(q.End) {
+		return errcode.New(errcode.ParamInvalid, "按时间统计时需指定有效的开始及结束时间")
+	}
+	if n := q.Interval.count(q.Start, q.End, r.opt.MaxBuckets+1); n < 0 {
+		return errcode.Newf(errcode.ParamInvalid, "不支持的时间粒度: %s", q.Interval)
+	} else if n > r.opt.MaxBuckets {
+		return errcode.Newf(errcode.ParamInvalid, "时间范围过大，最多统计%d个时间段", r.opt.MaxBuckets)
+	}
+	return nil
+}
+
+// builder 生成查询，grouped 为 false 时不分组
+func (r *Report) builder(ctx context.Context, q Query, grouped bool) (*query.Builder, error) {
+	selects := make([]string, 0, 1+len(q.Dimensions)+len(r.def.Metrics))
+	groups := make([]string, 0, 1+len(q.Dimensions))
+	if grouped && q.Interval != "" {
+		expr, err := q.Interval.expr(r.db.Dialector.Name(), r.def.TimeField)
+		if err != nil {
+			return nil, err
+		}
+		selects = append(selects, expr+" AS "+r.quote(PeriodKey))
+		groups = append(groups, expr)
+	}
+	if grouped {
+		for _, name := range q.Dimensions {
+			expr := r.def.Dimensions[name]
+			selects = append(selects, expr+" AS "+r.quote(name))
+			groups = append(groups, expr)
+		}
+	}
+	for _, m := range r.def.Metrics {
+		expr, _ := m.expr()
+		selects = append(selects, expr+" AS "+r.quote(m.Name))
+	}
+
+	b := query.New(r.db.WithContext(ctx)).Select(strings.Join(selects, ", ")).From(r.def.Table)
+	if r.def.Scope != nil {
+		r.def.Scope(b)
+	}
+	b.WhereIf(!q.Start.IsZero(), r.def.TimeField+" >= ?", q.Start)
+	b.WhereIf(!q.End.IsZero(), r.def.TimeField+" < ?", q.End)
+	for name, value := range q.Filters {
+		if isList(value) {
+			b.Where(r.def.Dimensions[name]+" IN ?", value)
+		} else {
+			b.Where(r.def.Dimensions[name]+" = ?", value)
+		}
+	}
+	if len(groups) > 0 {
+		b.GroupBy(strings.Join(groups, ", "))
+	}
+	return b, nil
+}
+
+// orderBy 排序，仅允许按结果中的列排序
+func (r *Report) orderBy(q Query) (string, error) {
+	name, desc := strings.CutPrefix(q.OrderBy, "-")
+	if name == "" {
+		if q.Interval != "" {
+			return r.quote(PeriodKey), nil
+		}
+		return r.quote(r.def.Metrics[0].Name) + " DESC", nil
+	}
+	valid := name == PeriodKey && q.Interval != ""
+	for _, dim := range q.Dimensions {
+		valid = valid || dim == name
+	}
+	for _, m := range r.def.Metrics {
+		valid = valid || m.Name == name
+	}
+	if !valid {
+		return "", errcode.Newf(errcode.ParamInvalid, "不支持的排序字段: %s", name)
+	}
+	if desc {
+		return r.quote(name) + " DESC", nil
+	}
+	return r.quote(name), nil
+}
+
+// normalize 统一结果的类型：文本类型的数值（如MySQL的DECIMAL）转换为数值，空指标转换为0
+func (r *Report) normalize(raw []map[string]any) []Row {
+	rows := make([]Row, 0, len(raw))
+	for _, item := range raw {
+		row := make(Row, len(item))
+		for k, v := range item {
+			if p, ok := v.(*any); ok && p != nil {
+				v = *p // gorm 的 Raw().Scan 写入 map 时值可能为 *interface{}
+			}
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			row[k] = v
+		}
+		for _, m := range r.def.Metrics {
+			row[m.Name] = number(row[m.Name])
+		}
+		if period, ok := row[PeriodKey]; ok {
+			row[PeriodKey] = helper.Convert{Value: period}.ToString()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// fill 补齐没有数据的时间段
+func (r *Report) fill(rows []Row, q Query) []Row {
+	exists := make(map[string]Row, len(rows))
+	for _, row := range rows {
+		exists[helper.Convert{Value: row[PeriodKey]}.ToString()] = row
+	}
+	desc := q.OrderBy == "-"+PeriodKey
+	periods := q.Interval.periods(q.Start, q.End)
+	filled := make([]Row, 0, len(periods))
+	for i := range periods {
+		period := periods[i]
+		if desc {
+			period = periods[len(periods)-1-i]
+		}
+		row, ok := exists[period]
+		if !ok {
+			row = r.zeroRow()
+			row[PeriodKey] = period
+		}
+		filled = append(filled, row)
+	}
+	return filled
+}
+
+func (r *Report) zeroRow() Row {
+	row := make(Row, len(r.def.Metrics)+1)
+	for _, m := range r.def.Metrics {
+		row[m.Name] = 0
+	}
+	return row
+}
+
+func (r *Report) quote(name string) string {
+	var b strings.Builder
+	r.db.Dialector.QuoteTo(&b, name)
+	return b.String()
+}
+
+// cacheKey 缓存键，包含报表名称及查询条件的摘要
+func (r *Report) cacheKey(kind string, q Query) string {
+	if r.opt.Cache == nil {
+		return ""
+	}
+	data, _ := json.Marshal(q)
+	sum := sha1.Sum(data)
+	return "report:" + r.def.Name + ":" + kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// loadCache 读取缓存，命中时返回 true
+func (r *Report) loadCache(key string, dest any) bool {
+	if key == "" {
+		return false
+	}
+	data, err := r.opt.Cache.GetString(key)
+	if err != nil || data == "" {
+		return false
+	}
+	if listData, ok := dest.(*jcbaseGo.ListData); ok {
+		rows := make([]Row, 0)
+		listData.List = &rows
+		if json.Unmarshal([]byte(data), listData) != nil {
+			return false
+		}
+		listData.List = rows
+		return true
+	}
+	return json.Unmarshal([]byte(data), dest) == nil
+}
+
+func (r *Report) saveCache(key string, value any) {
+	if key == "" {
+		return
+	}
+	if data, err := json.Marshal(value); err == nil {
+		_ = r.opt.Cache.Set(key, string(data), r.opt.CacheTTL)
+	}
+}
+
+// number 转换为数值，无法转换时为0
+func number(v any) any {
+	if v == nil {
+		return 0
+	}
+	if s, ok := v.(string); ok {
+		v = strings.TrimSpace(s)
+	}
+	if n, ok := (helper.Convert{Value: v}).ToNumber(); ok {
+		return n
+	}
+	return 0
+}
+
+// isList 是否为切片或数组（不含 []byte）
+func isList(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Array || rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8
+}
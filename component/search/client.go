@@ -0,0 +1,168 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Map JSON对象，用于书写查询DSL
+type Map = map[string]any
+
+// Error Elasticsearch 返回的错误
+type Error struct {
+	Status int    `json:"status"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("search: %d %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// Client Elasticsearch 客户端，通过REST接口访问，兼容 Elasticsearch 7.x/8.x 及 OpenSearch
+//
+// 示例:
+//
+//	client := search.New(jcbaseGo.SearchStruct{Address: "http://127.0.0.1:9200", IndexPrefix: "shop_"})
+//	_ = client.Index(ctx, "goods", "1", goods)
+//	list := make([]Goods, 0)
+//	listData, err := client.FindForPage(ctx, "goods", search.NewQuery().
+//	    Must(search.MultiMatch(keyword, "title^2", "description")).
+//	    Filter(search.Term("status", 1)).
+//	    Sort("_score", "desc").
+//	    Page(page, pageSize), &list)
+type Client struct {
+	Conf jcbaseGo.SearchStruct
+	http *httpclient.Client
+}
+
+// New 创建客户端
+func New(conf jcbaseGo.SearchStruct) *Client {
+	_ = helper.CheckAndSetDefault(&conf)
+	headers := map[string]string{"Accept": "application/json"}
+	if conf.APIKey != "" {
+		headers["Authorization"] = "ApiKey " + conf.APIKey
+	} else if conf.Username != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(conf.Username+":"+conf.Password))
+	}
+	return &Client{
+		Conf: conf,
+		http: httpclient.New(httpclient.Options{
+			BaseURL:    strings.TrimRight(conf.Address, "/"),
+			Headers:    headers,
+			Timeout:    time.Duration(conf.Timeout) * time.Second,
+			MaxRetries: conf.MaxRetries,
+		}),
+	}
+}
+
+// IndexName 获取加上前缀后的索引名
+func (c *Client) IndexName(index string) string {
+	return c.Conf.IndexPrefix + index
+}
+
+// do 发送请求，body 为 []byte 时原样发送（用于 NDJSON），result 不为nil时解析响应
+func (c *Client) do(ctx context.Context, method, path string, body any, result any) (int, error) {
+	req := c.http.R().SetContext(ctx)
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		req.SetBody(b, "application/x-ndjson")
+	default:
+		req.SetJSON(b)
+	}
+	resp, err := req.Do(method, path)
+	if err != nil {
+		return 0, err
+	}
+	if !resp.IsSuccess() {
+		return resp.StatusCode, parseError(resp)
+	}
+	if result != nil {
+		if err = json.NewDecoder(bytes.NewReader(resp.Body)).Decode(result); err != nil {
+			return resp.StatusCode, fmt.Errorf("search: 解析响应失败: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// parseError 解析错误响应
+func parseError(resp *httpclient.Response) error {
+	e := &Error{Status: resp.StatusCode}
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(resp.Body, &body) == nil && len(body.Error) > 0 {
+		var detail struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		}
+		if json.Unmarshal(body.Error, &detail) == nil && detail.Type != "" {
+			e.Type, e.Reason = detail.Type, detail.Reason
+			return e
+		}
+		e.Reason = strings.Trim(string(body.Error), `"`)
+		return e
+	}
+	e.Type = http.StatusText(resp.StatusCode)
+	e.Reason = resp.String()
+	return e
+}
+
+// CreateIndex 创建索引，body 为 settings、mappings 等定义，可为nil
+//
+// 示例:
+//
+//	err := client.CreateIndex(ctx, "goods", search.Map{
+//	    "mappings": search.Map{"properties": search.Map{
+//	        "title":  search.Map{"type": "text", "analyzer": "ik_max_word"},
+//	        "status": search.Map{"type": "integer"},
+//	    }},
+//	})
+func (c *Client) CreateIndex(ctx context.Context, index string, body any) error {
+	if body == nil {
+		body = Map{}
+	}
+	_, err := c.do(ctx, http.MethodPut, "/"+url.PathEscape(c.IndexName(index)), body, nil)
+	return err
+}
+
+// DeleteIndex 删除索引，索引不存在时不返回错误
+func (c *Client) DeleteIndex(ctx context.Context, index string) error {
+	status, err := c.do(ctx, http.MethodDelete, "/"+url.PathEscape(c.IndexName(index)), nil, nil)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// IndexExists 判断索引是否存在
+func (c *Client) IndexExists(ctx context.Context, index string) (bool, error) {
+	resp, err := c.http.R().SetContext(ctx).Do(http.MethodHead, "/"+url.PathEscape(c.IndexName(index)))
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, parseError(resp)
+}
+
+// Refresh 刷新索引，使之前写入的文档立即可被搜索
+func (c *Client) Refresh(ctx context.Context, index string) error {
+	_, err := c.do(ctx, http.MethodPost, "/"+url.PathEscape(c.IndexName(index))+"/_refresh", nil, nil)
+	return err
+}
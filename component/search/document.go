@@ -0,0 +1,146 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 批量操作类型
+const (
+	ActionIndex  = "index"  // 新增或覆盖
+	ActionCreate = "create" // 仅新增，已存在时报错
+	ActionUpdate = "update" // 局部更新，Doc 为需要更新的字段
+	ActionDelete = "delete" // 删除
+)
+
+// BulkItem 批量操作项
+type BulkItem struct {
+	Action string // 操作类型，默认 ActionIndex
+	Index  string // 索引名（不含前缀）
+	ID     string
+	Doc    any
+}
+
+// BulkError 批量操作中部分失败
+type BulkError struct {
+	Failed map[string]*Error // 文档ID → 错误原因
+}
+
+func (e *BulkError) Error() string {
+	for id, err := range e.Failed {
+		return fmt.Sprintf("search: 批量操作有 %d 项失败，如 %s: %v", len(e.Failed), id, err)
+	}
+	return "search: 批量操作失败"
+}
+
+// Index 新增或覆盖文档
+func (c *Client) Index(ctx context.Context, index, id string, doc any) error {
+	_, err := c.do(ctx, http.MethodPut, c.docPath(index, id), doc, nil)
+	return err
+}
+
+// Update 局部更新文档，fields 为需要更新的字段
+func (c *Client) Update(ctx context.Context, index, id string, fields any) error {
+	path := "/" + url.PathEscape(c.IndexName(index)) + "/_update/" + url.PathEscape(id)
+	_, err := c.do(ctx, http.MethodPost, path, Map{"doc": fields}, nil)
+	return err
+}
+
+// Get 获取文档并解析到 dest 中，文档不存在时返回 false
+func (c *Client) Get(ctx context.Context, index, id string, dest any) (bool, error) {
+	var result struct {
+		Found  bool            `json:"found"`
+		Source json.RawMessage `json:"_source"`
+	}
+	status, err := c.do(ctx, http.MethodGet, c.docPath(index, id), nil, &result)
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil || !result.Found {
+		return false, err
+	}
+	return true, json.Unmarshal(result.Source, dest)
+}
+
+// Delete 删除文档，文档不存在时不返回错误
+func (c *Client) Delete(ctx context.Context, index, id string) error {
+	status, err := c.do(ctx, http.MethodDelete, c.docPath(index, id), nil, nil)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Bulk 批量写入，部分失败时返回 *BulkError
+func (c *Client) Bulk(ctx context.Context, items ...BulkItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, item := range items {
+		action := item.Action
+		if action == "" {
+			action = ActionIndex
+		}
+		meta := Map{"_index": c.IndexName(item.Index)}
+		if item.ID != "" {
+			meta["_id"] = item.ID
+		}
+		if err := enc.Encode(Map{action: meta}); err != nil {
+			return err
+		}
+		switch action {
+		case ActionDelete:
+			continue
+		case ActionUpdate:
+			if err := enc.Encode(Map{"doc": item.Doc}); err != nil {
+				return err
+			}
+		default:
+			if err := enc.Encode(item.Doc); err != nil {
+				return err
+			}
+		}
+	}
+
+	var result struct {
+		Errors bool                `json:"errors"`
+		Items  []map[string]bulkOp `json:"items"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/_bulk", buf.Bytes(), &result); err != nil {
+		return err
+	}
+	if !result.Errors {
+		return nil
+	}
+	failed := make(map[string]*Error)
+	for _, item := range result.Items {
+		for _, op := range item {
+			if op.Error != nil {
+				op.Error.Status = op.Status
+				failed[op.ID] = op.Error
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &BulkError{Failed: failed}
+}
+
+// bulkOp 批量操作的单项结果
+type bulkOp struct {
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  *Error `json:"error"`
+}
+
+func (c *Client) docPath(index, id string) string {
+	return "/" + url.PathEscape(c.IndexName(index)) + "/_doc/" + url.PathEscape(strings.TrimSpace(id))
+}
@@ -0,0 +1,296 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jcbowen/jcbaseGo"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// Query 搜索条件构造器，最终生成 bool 查询
+type Query struct {
+	must, filter, should, mustNot []any
+	minimumShouldMatch            any
+	sort                          []any
+	page, pageSize                int
+	source                        []string
+	highlight                     []string
+	aggs                          Map
+}
+
+// NewQuery 创建搜索条件
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Must 必须满足且参与评分的条件
+func (q *Query) Must(clauses ...any) *Query {
+	q.must = append(q.must, clauses...)
+	return q
+}
+
+// Filter 必须满足但不参与评分的条件，可被缓存，适合状态、时间范围等精确过滤
+func (q *Query) Filter(clauses ...any) *Query {
+	q.filter = append(q.filter, clauses...)
+	return q
+}
+
+// Should 满足任一即可的条件，与 Must/Filter 同时使用时仅影响评分，除非设置 MinimumShouldMatch
+func (q *Query) Should(clauses ...any) *Query {
+	q.should = append(q.should, clauses...)
+	return q
+}
+
+// MustNot 必须不满足的条件
+func (q *Query) MustNot(clauses ...any) *Query {
+	q.mustNot = append(q.mustNot, clauses...)
+	return q
+}
+
+// MinimumShouldMatch 设置 Should 条件最少需要满足的数量，如 1 或 "75%"
+func (q *Query) MinimumShouldMatch(value any) *Query {
+	q.minimumShouldMatch = value
+	return q
+}
+
+// Sort 添加排序，order 为 asc 或 desc
+func (q *Query) Sort(field, order string) *Query {
+	q.sort = append(q.sort, Map{field: Map{"order": order}})
+	return q
+}
+
+// Page 设置分页，page 从1开始
+func (q *Query) Page(page, pageSize int) *Query {
+	q.page, q.pageSize = page, pageSize
+	return q
+}
+
+// Source 指定返回的字段
+func (q *Query) Source(fields ...string) *Query {
+	q.source = fields
+	return q
+}
+
+// Highlight 指定需要高亮的字段，高亮结果在 Hit.Highlight 中
+func (q *Query) Highlight(fields ...string) *Query {
+	q.highlight = append(q.highlight, fields...)
+	return q
+}
+
+// Agg 添加聚合，结果在 SearchResult.Aggregations 中
+//
+// 示例:
+//
+//	q.Agg("by_brand", search.Map{"terms": search.Map{"field": "brand_id", "size": 20}})
+func (q *Query) Agg(name string, agg any) *Query {
+	if q.aggs == nil {
+		q.aggs = Map{}
+	}
+	q.aggs[name] = agg
+	return q
+}
+
+// pagination 获取分页参数，默认第1页、每页10条，每页最多1000条
+func (q *Query) pagination() (page, pageSize int) {
+	page, pageSize = max(q.page, 1), q.pageSize
+	if pageSize < 1 {
+		pageSize = 10
+	} else if pageSize > 1000 {
+		pageSize = 1000
+	}
+	return
+}
+
+// Build 生成请求体
+func (q *Query) Build() Map {
+	boolQuery := Map{}
+	for name, clauses := range map[string][]any{"must": q.must, "filter": q.filter, "should": q.should, "must_not": q.mustNot} {
+		if len(clauses) > 0 {
+			boolQuery[name] = clauses
+		}
+	}
+	if q.minimumShouldMatch != nil {
+		boolQuery["minimum_should_match"] = q.minimumShouldMatch
+	}
+
+	page, pageSize := q.pagination()
+	body := Map{
+		"from":             (page - 1) * pageSize,
+		"size":             pageSize,
+		"track_total_hits": true,
+	}
+	if len(boolQuery) > 0 {
+		body["query"] = Map{"bool": boolQuery}
+	} else {
+		body["query"] = Map{"match_all": Map{}}
+	}
+	if len(q.sort) > 0 {
+		body["sort"] = q.sort
+	}
+	if q.source != nil {
+		body["_source"] = q.source
+	}
+	if len(q.highlight) > 0 {
+		fields := Map{}
+		for _, field := range q.highlight {
+			fields[field] = Map{}
+		}
+		body["highlight"] = Map{"fields": fields}
+	}
+	if len(q.aggs) > 0 {
+		body["aggs"] = q.aggs
+	}
+	return body
+}
+
+// Match 全文匹配
+func Match(field string, value any) Map {
+	return Map{"match": Map{field: value}}
+}
+
+// MatchPhrase 短语匹配，词语需按顺序相邻出现
+func MatchPhrase(field string, value any) Map {
+	return Map{"match_phrase": Map{field: value}}
+}
+
+// MultiMatch 多字段全文匹配，字段可使用 ^ 指定权重，如 "title^2"
+func MultiMatch(query string, fields ...string) Map {
+	return Map{"multi_match": Map{"query": query, "fields": fields}}
+}
+
+// Term 精确匹配
+func Term(field string, value any) Map {
+	return Map{"term": Map{field: value}}
+}
+
+// Terms 精确匹配任一值
+func Terms(field string, values ...any) Map {
+	return Map{"terms": Map{field: values}}
+}
+
+// Range 范围查询，gte、lte 为 nil 时表示不限制
+func Range(field string, gte, lte any) Map {
+	cond := Map{}
+	if gte != nil {
+		cond["gte"] = gte
+	}
+	if lte != nil {
+		cond["lte"] = lte
+	}
+	return Map{"range": Map{field: cond}}
+}
+
+// Exists 字段存在且不为空
+func Exists(field string) Map {
+	return Map{"exists": Map{"field": field}}
+}
+
+// Prefix 前缀匹配
+func Prefix(field, value string) Map {
+	return Map{"prefix": Map{field: value}}
+}
+
+// Hit 单条搜索结果
+type Hit struct {
+	Index     string              `json:"_index"`
+	ID        string              `json:"_id"`
+	Score     float64             `json:"_score"`
+	Source    json.RawMessage     `json:"_source"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Total        int64                      `json:"total"`
+	MaxScore     float64                    `json:"max_score"`
+	Hits         []Hit                      `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations,omitempty"`
+}
+
+// Decode 将所有结果的 _source 解析到 list 中，list 为切片指针
+func (r *SearchResult) Decode(list any) error {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return errors.New("search: list 必须为切片指针")
+	}
+	slice := v.Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, len(r.Hits)))
+	for _, hit := range r.Hits {
+		item := reflect.New(slice.Type().Elem())
+		if err := json.Unmarshal(hit.Source, item.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+	return nil
+}
+
+// Search 搜索，q 为nil时返回第一页的全部文档
+func (c *Client) Search(ctx context.Context, index string, q *Query) (*SearchResult, error) {
+	if q == nil {
+		q = NewQuery()
+	}
+	var resp struct {
+		Hits struct {
+			Total    json.RawMessage `json:"total"`
+			MaxScore *float64        `json:"max_score"`
+			Hits     []Hit           `json:"hits"`
+		} `json:"hits"`
+		Aggregations map[string]json.RawMessage `json:"aggregations"`
+	}
+	path := "/" + url.PathEscape(c.IndexName(index)) + "/_search"
+	if _, err := c.do(ctx, http.MethodPost, path, q.Build(), &resp); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{Hits: resp.Hits.Hits, Aggregations: resp.Aggregations}
+	if resp.Hits.MaxScore != nil {
+		result.MaxScore = *resp.Hits.MaxScore
+	}
+	// 7.x 起 total 为对象，6.x 为数字
+	var total struct {
+		Value int64 `json:"value"`
+	}
+	if json.Unmarshal(resp.Hits.Total, &total) != nil {
+		_ = json.Unmarshal(resp.Hits.Total, &total.Value)
+	}
+	result.Total = total.Value
+	return result, nil
+}
+
+// FindForPage 分页搜索并将结果解析到 list（切片指针）中，返回与 crud 列表接口一致的 jcbaseGo.ListData
+func (c *Client) FindForPage(ctx context.Context, index string, q *Query, list any) (jcbaseGo.ListData, error) {
+	if q == nil {
+		q = NewQuery()
+	}
+	page, pageSize := q.pagination()
+	listData := jcbaseGo.ListData{Page: page, PageSize: pageSize}
+
+	result, err := c.Search(ctx, index, q)
+	if err != nil {
+		return listData, err
+	}
+	if err = result.Decode(list); err != nil {
+		return listData, err
+	}
+	listData.List = reflect.ValueOf(list).Elem().Interface()
+	listData.Total = int(result.Total)
+	return listData, nil
+}
+
+// Count 统计满足条件的文档数量
+func (c *Client) Count(ctx context.Context, index string, q *Query) (int64, error) {
+	body := Map{}
+	if q != nil {
+		body["query"] = q.Build()["query"]
+	}
+	var resp struct {
+		Count int64 `json:"count"`
+	}
+	path := "/" + url.PathEscape(c.IndexName(index)) + "/_count"
+	_, err := c.do(ctx, http.MethodPost, path, body, &resp)
+	return resp.Count, err
+}
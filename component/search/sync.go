@@ -0,0 +1,178 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"reflect"
+)
+
+// Document 需要同步到搜索引擎的模型
+type Document interface {
+	SearchIndex() string // 索引名（不含前缀）
+}
+
+// DocumentBody 自定义写入搜索引擎的内容，未实现时写入模型本身
+type DocumentBody interface {
+	SearchDocument() any
+}
+
+// Plugin 获取GORM插件，模型实现 Document 接口后，新增、更新、删除时自动同步到搜索引擎
+// 文档ID为模型的主键，更新后按主键重新查询完整记录写入，查询不到（如软删除）时删除文档
+// 仅处理能确定主键的操作：如 db.Model(&User{}).Where(...).Updates(...) 这类批量更新不会同步，需自行调用 IndexQuery
+// 同步失败只记录日志，不影响数据库操作；同步在回调中执行，事务回滚时不会撤销
+//
+// 示例:
+//
+//	_ = db.Use(client.Plugin())
+func (c *Client) Plugin() gorm.Plugin {
+	return &syncPlugin{client: c}
+}
+
+// syncPlugin 在写操作后同步文档
+type syncPlugin struct {
+	client *Client
+}
+
+// Name 实现 gorm.Plugin 接口
+func (p *syncPlugin) Name() string {
+	return "jcbaseGo:search"
+}
+
+// Initialize 实现 gorm.Plugin 接口
+func (p *syncPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Register("search:after_create", p.afterSave); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("search:after_update", p.afterSave); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("search:after_delete", p.afterDelete)
+}
+
+// afterSave 新增、更新后重新查询记录并写入
+func (p *syncPlugin) afterSave(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || db.RowsAffected == 0 {
+		return
+	}
+	p.each(db, func(doc Document, id any) {
+		fresh := reflect.New(reflect.TypeOf(doc).Elem())
+		tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Limit(1).Find(fresh.Interface(), id)
+		if tx.Error != nil {
+			log.Printf("search: 查询 %s(%v) 失败: %v", doc.SearchIndex(), id, tx.Error)
+			return
+		}
+		if tx.RowsAffected == 0 {
+			p.delete(db, doc, id)
+			return
+		}
+		var body any = fresh.Interface()
+		if b, ok := body.(DocumentBody); ok {
+			body = b.SearchDocument()
+		}
+		if err := p.client.Index(db.Statement.Context, doc.SearchIndex(), fmt.Sprint(id), body); err != nil {
+			log.Printf("search: 同步 %s(%v) 失败: %v", doc.SearchIndex(), id, err)
+		}
+	})
+}
+
+// afterDelete 删除后同步删除文档
+func (p *syncPlugin) afterDelete(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || db.RowsAffected == 0 {
+		return
+	}
+	p.each(db, func(doc Document, id any) {
+		p.delete(db, doc, id)
+	})
+}
+
+func (p *syncPlugin) delete(db *gorm.DB, doc Document, id any) {
+	if err := p.client.Delete(db.Statement.Context, doc.SearchIndex(), fmt.Sprint(id)); err != nil {
+		log.Printf("search: 删除 %s(%v) 失败: %v", doc.SearchIndex(), id, err)
+	}
+}
+
+// each 遍历语句涉及的模型（支持切片），跳过未实现 Document 或主键为零值的记录
+func (p *syncPlugin) each(db *gorm.DB, fn func(doc Document, id any)) {
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return
+	}
+	ctx := db.Statement.Context
+	visit := func(rv reflect.Value) {
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return
+			}
+			rv = rv.Elem()
+		}
+		if !rv.CanAddr() {
+			return
+		}
+		doc, ok := rv.Addr().Interface().(Document)
+		if !ok {
+			return
+		}
+		if id, zero := field.ValueOf(ctx, rv); !zero {
+			fn(doc, id)
+		}
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			visit(rv.Index(i))
+		}
+	case reflect.Struct:
+		visit(rv)
+	}
+}
+
+// IndexQuery 分批查询并批量写入搜索引擎，用于初始化索引或重建索引
+// model 为实现了 Document 的模型（或其指针），batchSize 小于等于0时为500
+//
+// 示例:
+//
+//	err := client.IndexQuery(ctx, db.Model(&Goods{}).Where("status = ?", 1), Goods{}, 1000)
+func (c *Client) IndexQuery(ctx context.Context, query *gorm.DB, model Document, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	t := reflect.TypeOf(model)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(reflect.New(t).Interface()); err != nil {
+		return err
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return fmt.Errorf("search: %s 没有主键", stmt.Schema.Name)
+	}
+
+	batch := reflect.New(reflect.SliceOf(t))
+	var bulkErr error
+	result := query.FindInBatches(batch.Interface(), batchSize, func(tx *gorm.DB, _ int) error {
+		rows := batch.Elem()
+		items := make([]BulkItem, 0, rows.Len())
+		for i := 0; i < rows.Len(); i++ {
+			row := rows.Index(i)
+			id, _ := field.ValueOf(ctx, row)
+			var body any = row.Addr().Interface()
+			if b, ok := body.(DocumentBody); ok {
+				body = b.SearchDocument()
+			}
+			items = append(items, BulkItem{Index: model.SearchIndex(), ID: fmt.Sprint(id), Doc: body})
+		}
+		bulkErr = c.Bulk(ctx, items...)
+		return bulkErr
+	})
+	if bulkErr != nil {
+		return bulkErr
+	}
+	return result.Error
+}
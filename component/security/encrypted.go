@@ -0,0 +1,222 @@
+package security
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrNoKeyRing is returned when an encrypted field is used before its key ring is registered
+var ErrNoKeyRing = errors.New("key ring not registered")
+
+var (
+	keyRingMu sync.RWMutex
+	keyRings  = make(map[string]*KeyRing)
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+}
+
+// SetDefaultKeyRing sets the key ring used by EncryptedString and the "encrypted" serializer
+//
+// Example:
+//
+//	ring := security.NewKeyRing(security.KeyRingSM4)
+//	_ = ring.LoadKeys(map[string]string{"2024": "base64:" + os.Getenv("PII_KEY")}, "2024")
+//	security.SetDefaultKeyRing(ring)
+func SetDefaultKeyRing(ring *KeyRing) {
+	RegisterKeyRing("", ring)
+}
+
+// RegisterKeyRing registers a named key ring, fields select it with the keyring tag setting
+//
+// Example:
+//
+//	security.RegisterKeyRing("idcard", idCardRing)
+//
+//	type User struct {
+//		Mobile string `gorm:"size:255;serializer:encrypted"`                // default key ring
+//		IDCard string `gorm:"size:255;serializer:encrypted;keyring:idcard"` // named key ring
+//	}
+func RegisterKeyRing(name string, ring *KeyRing) {
+	keyRingMu.Lock()
+	defer keyRingMu.Unlock()
+	if ring == nil {
+		delete(keyRings, name)
+		return
+	}
+	keyRings[name] = ring
+}
+
+// GetKeyRing returns a registered key ring, an empty name returns the default key ring
+func GetKeyRing(name string) (*KeyRing, error) {
+	keyRingMu.RLock()
+	defer keyRingMu.RUnlock()
+	ring, ok := keyRings[name]
+	if !ok {
+		if name == "" {
+			return nil, ErrNoKeyRing
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoKeyRing, name)
+	}
+	return ring, nil
+}
+
+// IsEncrypted reports whether s has the form of a key ring ciphertext. The key version is not
+// checked, so ciphertexts of removed keys are still recognized and fail to decrypt with
+// ErrUnknownKeyVersion instead of being taken as plaintext and encrypted again.
+func (k *KeyRing) IsEncrypted(s string) bool {
+	_, _, _, err := splitKeyRingCipher(s)
+	return err == nil
+}
+
+// EncryptedString is a string that is encrypted with the default key ring when written
+// to the database and decrypted when read. Empty strings are stored as is.
+//
+// Values that do not have the ciphertext form (e.g. rows written before encryption was
+// enabled) are read as plaintext and encrypted on the next save, so existing columns can be
+// migrated gradually. Ciphertexts of a key version missing from the ring fail with
+// ErrUnknownKeyVersion. Since every encryption uses a random data key, encrypted columns
+// cannot be used in WHERE conditions; store a hash (see SM3 / HMAC) alongside for lookups.
+//
+// Example:
+//
+//	type Customer struct {
+//		jcbaseGo.MysqlBaseModel
+//		Mobile security.EncryptedString `gorm:"column:mobile;size:255"`
+//	}
+type EncryptedString string
+
+// String returns the plaintext
+func (s EncryptedString) String() string {
+	return string(s)
+}
+
+// Value implements driver.Valuer
+func (s EncryptedString) Value() (driver.Value, error) {
+	ring, err := GetKeyRing("")
+	if err != nil {
+		return nil, err
+	}
+	return encryptValue(ring, string(s))
+}
+
+// Scan implements sql.Scanner
+func (s *EncryptedString) Scan(src any) error {
+	raw, err := scanString(src)
+	if err != nil || raw == "" {
+		*s = EncryptedString(raw)
+		return err
+	}
+	ring, err := GetKeyRing("")
+	if err != nil {
+		return err
+	}
+	plaintext, err := decryptValue(ring, raw)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+// EncryptedSerializer is a gorm serializer registered as "encrypted" for string and *string fields.
+// The key ring is selected by the keyring tag setting, the default key ring is used when omitted.
+type EncryptedSerializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		raw, err := scanString(dbValue)
+		if err != nil {
+			return err
+		}
+		if raw != "" {
+			ring, err := GetKeyRing(fieldKeyRing(field))
+			if err != nil {
+				return err
+			}
+			if raw, err = decryptValue(ring, raw); err != nil {
+				return fmt.Errorf("decrypt field %s: %w", field.Name, err)
+			}
+		}
+		switch field.FieldType.Kind() {
+		case reflect.String:
+			fieldValue.Elem().SetString(raw)
+		case reflect.Ptr:
+			if field.FieldType.Elem().Kind() != reflect.String {
+				return fmt.Errorf("invalid field type %s for EncryptedSerializer, only string supported", field.FieldType)
+			}
+			ptr := reflect.New(field.FieldType.Elem())
+			ptr.Elem().SetString(raw)
+			fieldValue.Elem().Set(ptr)
+		default:
+			return fmt.Errorf("invalid field type %s for EncryptedSerializer, only string supported", field.FieldType)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value implements schema.SerializerInterface
+func (EncryptedSerializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue any) (any, error) {
+	var plaintext string
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = v
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		plaintext = *v
+	default:
+		return nil, fmt.Errorf("invalid field type %T for EncryptedSerializer, only string supported", fieldValue)
+	}
+	ring, err := GetKeyRing(fieldKeyRing(field))
+	if err != nil {
+		return nil, err
+	}
+	return encryptValue(ring, plaintext)
+}
+
+// fieldKeyRing returns the key ring name from the keyring tag setting
+func fieldKeyRing(field *schema.Field) string {
+	return strings.TrimSpace(field.TagSettings["KEYRING"])
+}
+
+// encryptValue encrypts a non-empty plaintext
+func encryptValue(ring *KeyRing, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	return ring.EncryptString(plaintext)
+}
+
+// decryptValue decrypts a ciphertext, values that do not have the ciphertext form are returned as is
+func decryptValue(ring *KeyRing, raw string) (string, error) {
+	if !ring.IsEncrypted(raw) {
+		return raw, nil
+	}
+	return ring.DecryptString(raw)
+}
+
+// scanString converts a database value to string
+func scanString(src any) (string, error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("unsupported encrypted value type %T", src)
+	}
+}
@@ -0,0 +1,66 @@
+package security
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestKeyRing(t *testing.T, versions ...string) *KeyRing {
+	t.Helper()
+	ring := NewKeyRing(KeyRingAES)
+	for i, version := range versions {
+		key := make([]byte, 32)
+		key[0] = byte(i + 1)
+		if err := ring.Rotate(version, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ring
+}
+
+func TestDecryptValuePlaintextPassThrough(t *testing.T) {
+	ring := newTestKeyRing(t, "v1")
+	for _, raw := range []string{"13800138000", "a:b:c", "v1:abc:def", "http://example.com:8080/path"} {
+		got, err := decryptValue(ring, raw)
+		if err != nil || got != raw {
+			t.Errorf("decryptValue(%q) = %q, %v; want plaintext returned as is", raw, got, err)
+		}
+	}
+}
+
+func TestDecryptValueUnknownKeyVersion(t *testing.T) {
+	ring := newTestKeyRing(t, "v1", "v2")
+	cipherText, err := newTestKeyRing(t, "v1").EncryptString("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ring.RemoveKey("v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ring.IsEncrypted(cipherText) {
+		t.Fatal("ciphertext of a removed key version should still be recognized")
+	}
+	// 不能当作明文返回，否则下次保存时会被再次加密
+	if got, err := decryptValue(ring, cipherText); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Fatalf("decryptValue = %q, %v; want ErrUnknownKeyVersion", got, err)
+	}
+}
+
+func TestDecryptValueRoundTrip(t *testing.T) {
+	ring := newTestKeyRing(t, "v1")
+	cipherText, err := encryptValue(ring, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ring.Rotate("v2", make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := decryptValue(ring, cipherText)
+	if err != nil || got != "secret" {
+		t.Fatalf("decryptValue = %q, %v", got, err)
+	}
+	if empty, err := encryptValue(ring, ""); err != nil || empty != "" {
+		t.Fatalf("empty plaintext should be stored as is: %q, %v", empty, err)
+	}
+}
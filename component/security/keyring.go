@@ -0,0 +1,331 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/tjfoc/gmsm/sm4"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KeyRing algorithms
+const (
+	KeyRingAES = "AES"
+	KeyRingSM4 = "SM4"
+)
+
+// ErrUnknownKeyVersion is returned when a ciphertext references a key version that is not in the ring
+var ErrUnknownKeyVersion = errors.New("unknown key version")
+
+// KeyRing manages versioned master keys and encrypts data with envelope encryption.
+//
+// Every Encrypt call generates a random data key, encrypts the data with it (GCM) and
+// wraps the data key with the current master key. The ciphertext has the form
+//
+//	<version>:<base64 wrapped data key>:<base64 nonce+payload>
+//
+// so data encrypted with any known version can still be decrypted after rotation,
+// and Rewrap can move old ciphertexts to the current key by re-wrapping only the data key.
+type KeyRing struct {
+	algorithm string
+
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	current string
+}
+
+// NewKeyRing creates a key ring for AES (16/24/32 byte keys) or SM4 (16 byte keys)
+//
+// Example:
+//
+//	ring := security.NewKeyRing(security.KeyRingAES)
+//	_ = ring.AddKey("2023", oldKey)
+//	_ = ring.Rotate("2024", newKey) // new data uses 2024, 2023 data is still readable
+//	cipherText, _ := ring.EncryptString("secret")
+func NewKeyRing(algorithm string) *KeyRing {
+	algorithm = strings.ToUpper(algorithm)
+	if algorithm == "" {
+		algorithm = KeyRingAES
+	}
+	return &KeyRing{algorithm: algorithm, keys: make(map[string][]byte)}
+}
+
+// AddKey adds a master key, the first added key becomes the current key
+func (k *KeyRing) AddKey(version string, key []byte) error {
+	if version == "" || strings.Contains(version, ":") {
+		return errors.New("key version must be non-empty and must not contain ':'")
+	}
+	if _, err := k.newBlock(key); err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[version] = append([]byte(nil), key...)
+	if k.current == "" {
+		k.current = version
+	}
+	return nil
+}
+
+// LoadKeys adds keys from a version => key map and sets the current version.
+// Keys prefixed with "base64:" are base64 decoded, other keys are used as raw bytes; the encoding
+// must be explicit because a base64 encoded 16 or 24 byte key is itself a valid 24 or 32 byte raw key.
+//
+// Example:
+//
+//	_ = ring.LoadKeys(map[string]string{"2023": "0123456789abcdef", "2024": "base64:" + os.Getenv("PII_KEY")}, "2024")
+func (k *KeyRing) LoadKeys(keys map[string]string, current string) error {
+	for version, key := range keys {
+		raw := []byte(key)
+		if encoded, ok := strings.CutPrefix(key, "base64:"); ok {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return fmt.Errorf("key %s: invalid base64: %v", version, err)
+			}
+			raw = decoded
+		}
+		if err := k.AddKey(version, raw); err != nil {
+			return fmt.Errorf("key %s: %v (base64 encoded keys need the \"base64:\" prefix)", version, err)
+		}
+	}
+	if current != "" {
+		return k.SetCurrent(current)
+	}
+	return nil
+}
+
+// SetCurrent sets the key version used for new encryptions
+func (k *KeyRing) SetCurrent(version string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[version]; !ok {
+		return ErrUnknownKeyVersion
+	}
+	k.current = version
+	return nil
+}
+
+// Rotate adds a new key and makes it current
+func (k *KeyRing) Rotate(version string, key []byte) error {
+	if err := k.AddKey(version, key); err != nil {
+		return err
+	}
+	return k.SetCurrent(version)
+}
+
+// RemoveKey removes a retired key, the current key cannot be removed
+func (k *KeyRing) RemoveKey(version string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if version == k.current {
+		return errors.New("cannot remove the current key")
+	}
+	delete(k.keys, version)
+	return nil
+}
+
+// Current returns the current key version
+func (k *KeyRing) Current() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// Versions returns all key versions in the ring
+func (k *KeyRing) Versions() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	versions := make([]string, 0, len(k.keys))
+	for version := range k.keys {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Encrypt encrypts data with a fresh data key wrapped by the current master key
+func (k *KeyRing) Encrypt(plaintext []byte) (string, error) {
+	version, masterKey, err := k.currentKey()
+	if err != nil {
+		return "", err
+	}
+
+	dataKey := make([]byte, 16)
+	if k.algorithm == KeyRingAES {
+		dataKey = make([]byte, 32)
+	}
+	if _, err = rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	wrapped, err := k.seal(masterKey, dataKey, []byte(version))
+	if err != nil {
+		return "", err
+	}
+	payload, err := k.seal(dataKey, plaintext, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return version + ":" + base64.RawURLEncoding.EncodeToString(wrapped) + ":" + base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt with any known key version
+func (k *KeyRing) Decrypt(cipherText string) ([]byte, error) {
+	version, wrapped, payload, err := splitKeyRingCipher(cipherText)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := k.unwrap(version, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return k.open(dataKey, payload, nil)
+}
+
+// EncryptString encrypts a string
+func (k *KeyRing) EncryptString(plaintext string) (string, error) {
+	return k.Encrypt([]byte(plaintext))
+}
+
+// DecryptString decrypts to a string
+func (k *KeyRing) DecryptString(cipherText string) (string, error) {
+	plaintext, err := k.Decrypt(cipherText)
+	return string(plaintext), err
+}
+
+// KeyVersion returns the key version of a ciphertext
+func (k *KeyRing) KeyVersion(cipherText string) string {
+	version, _, _ := strings.Cut(cipherText, ":")
+	return version
+}
+
+// NeedsRewrap reports whether the ciphertext was encrypted with a key other than the current one
+func (k *KeyRing) NeedsRewrap(cipherText string) bool {
+	return k.KeyVersion(cipherText) != k.Current()
+}
+
+// Rewrap re-wraps the data key of a ciphertext with the current master key, the payload is left untouched
+func (k *KeyRing) Rewrap(cipherText string) (string, error) {
+	version, wrapped, payload, err := splitKeyRingCipher(cipherText)
+	if err != nil {
+		return "", err
+	}
+	currentVersion, masterKey, err := k.currentKey()
+	if err != nil {
+		return "", err
+	}
+	if version == currentVersion {
+		return cipherText, nil
+	}
+
+	dataKey, err := k.unwrap(version, wrapped)
+	if err != nil {
+		return "", err
+	}
+	rewrapped, err := k.seal(masterKey, dataKey, []byte(currentVersion))
+	if err != nil {
+		return "", err
+	}
+	return currentVersion + ":" + base64.RawURLEncoding.EncodeToString(rewrapped) + ":" + base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// currentKey returns the current version and master key
+func (k *KeyRing) currentKey() (string, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.current == "" {
+		return "", nil, errors.New("key ring is empty")
+	}
+	return k.current, k.keys[k.current], nil
+}
+
+// unwrap decrypts a wrapped data key with the given master key version
+func (k *KeyRing) unwrap(version string, wrapped []byte) ([]byte, error) {
+	k.mu.RLock()
+	masterKey, ok := k.keys[version]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKeyVersion
+	}
+	return k.open(masterKey, wrapped, []byte(version))
+}
+
+// seal encrypts with GCM and prepends the nonce
+func (k *KeyRing) seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := k.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts data produced by seal
+func (k *KeyRing) open(key, data, additionalData []byte) ([]byte, error) {
+	aead, err := k.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData)
+}
+
+// newAEAD creates a GCM cipher for the key
+func (k *KeyRing) newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := k.newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newBlock creates the block cipher of the configured algorithm
+func (k *KeyRing) newBlock(key []byte) (cipher.Block, error) {
+	switch k.algorithm {
+	case KeyRingAES:
+		if err := validateKey(string(key)); err != nil {
+			return nil, err
+		}
+		return aes.NewCipher(key)
+	case KeyRingSM4:
+		if len(key) != sm4.BlockSize {
+			return nil, fmt.Errorf("key length must be 16 bytes; got key len (%d)", len(key))
+		}
+		return sm4.NewCipher(key)
+	default:
+		return nil, fmt.Errorf("unsupported key ring algorithm: %s", k.algorithm)
+	}
+}
+
+// minSealedLen is the length of a GCM nonce plus tag, the shortest output of seal
+const minSealedLen = 12 + 16
+
+// errInvalidKeyRingCipher is returned when a value does not have the key ring ciphertext form
+var errInvalidKeyRingCipher = errors.New("invalid key ring ciphertext")
+
+// splitKeyRingCipher splits a ciphertext into version, wrapped data key and payload
+func splitKeyRingCipher(cipherText string) (version string, wrapped, payload []byte, err error) {
+	parts := strings.Split(cipherText, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, errInvalidKeyRingCipher
+	}
+	if wrapped, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil || len(wrapped) <= minSealedLen {
+		return "", nil, nil, errInvalidKeyRingCipher
+	}
+	if payload, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil || len(payload) < minSealedLen {
+		return "", nil, nil, errInvalidKeyRingCipher
+	}
+	return parts[0], wrapped, payload, nil
+}
@@ -0,0 +1,77 @@
+package security
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestKeyRingLoadKeysEncoding(t *testing.T) {
+	key16 := []byte("0123456789abcdef")
+	key24 := []byte("0123456789abcdef01234567")
+	ring := NewKeyRing(KeyRingAES)
+	err := ring.LoadKeys(map[string]string{
+		"raw":  string(key16),
+		"b16":  "base64:" + base64.StdEncoding.EncodeToString(key16), // 24 个字符，同时是合法的 AES-192 原始密钥
+		"b24":  "base64:" + base64.StdEncoding.EncodeToString(key24), // 32 个字符，同时是合法的 AES-256 原始密钥
+		"rawb": base64.StdEncoding.EncodeToString(key16),             // 无前缀时按原始密钥使用
+	}, "b16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for version, want := range map[string][]byte{
+		"raw":  key16,
+		"b16":  key16,
+		"b24":  key24,
+		"rawb": []byte(base64.StdEncoding.EncodeToString(key16)),
+	} {
+		if got := ring.keys[version]; !bytes.Equal(got, want) {
+			t.Errorf("key %s = %q, want %q", version, got, want)
+		}
+	}
+	if ring.Current() != "b16" {
+		t.Errorf("current = %s", ring.Current())
+	}
+}
+
+func TestKeyRingLoadKeysErrors(t *testing.T) {
+	for name, keys := range map[string]map[string]string{
+		"invalid base64":       {"v1": "base64:***"},
+		"unprefixed base64":    {"v1": base64.StdEncoding.EncodeToString(make([]byte, 32))},
+		"wrong decoded length": {"v1": "base64:" + base64.StdEncoding.EncodeToString(make([]byte, 10))},
+	} {
+		if err := NewKeyRing(KeyRingAES).LoadKeys(keys, ""); err == nil {
+			t.Errorf("%s: LoadKeys should fail", name)
+		}
+	}
+	if err := NewKeyRing(KeyRingAES).LoadKeys(map[string]string{"v1": "0123456789abcdef"}, "v2"); err != ErrUnknownKeyVersion {
+		t.Errorf("unknown current version: err = %v", err)
+	}
+}
+
+func TestKeyRingRewrap(t *testing.T) {
+	ring := newTestKeyRing(t, "v1")
+	cipherText, err := ring.EncryptString("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ring.Rotate("v2", bytes.Repeat([]byte{9}, 32)); err != nil {
+		t.Fatal(err)
+	}
+	if !ring.NeedsRewrap(cipherText) {
+		t.Fatal("ciphertext of v1 should need rewrap")
+	}
+	rewrapped, err := ring.Rewrap(cipherText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ring.KeyVersion(rewrapped) != "v2" || ring.NeedsRewrap(rewrapped) {
+		t.Fatalf("rewrapped version = %s", ring.KeyVersion(rewrapped))
+	}
+	if err = ring.RemoveKey("v1"); err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := ring.DecryptString(rewrapped); err != nil || plaintext != "secret" {
+		t.Fatalf("decrypt after removing v1 = %q, %v", plaintext, err)
+	}
+}
@@ -1,6 +1,17 @@
 package security
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/validator"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
 
 // PasswordHash generates a hash of the password
 func PasswordHash(password string, cost int) (string, error) {
@@ -13,3 +24,152 @@ func PasswordVerify(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// Password hashing algorithms
+const (
+	PasswordBcrypt   = "bcrypt"
+	PasswordArgon2id = "argon2id"
+)
+
+// ErrPasswordTooWeak is returned by Password.Hash when MinStrength is set and not reached
+var ErrPasswordTooWeak = errors.New("password is too weak")
+
+// argon2id parameter limits, hashes outside them are rejected instead of being computed
+const (
+	maxArgon2Time   = 100     // iterations
+	maxArgon2Memory = 1 << 20 // KiB, 1 GiB
+)
+
+// Password hashes and verifies passwords with bcrypt or argon2id.
+//
+// Verify accepts hashes of both algorithms, so switching Algorithm or raising the cost
+// only requires calling NeedsRehash after a successful login and storing the new hash.
+type Password struct {
+	Algorithm   string `json:"algorithm" default:"bcrypt"` // bcrypt or argon2id
+	Cost        int    `json:"cost" default:"10"`          // bcrypt cost
+	Time        int    `json:"time" default:"3"`           // argon2id iterations
+	Memory      int    `json:"memory" default:"65536"`     // argon2id memory in KiB
+	Threads     int    `json:"threads" default:"2"`        // argon2id parallelism
+	KeyLength   int    `json:"key_length" default:"32"`    // argon2id hash length
+	SaltLength  int    `json:"salt_length" default:"16"`   // argon2id salt length
+	MinStrength int    `json:"min_strength" default:"0"`   // minimum validator.PasswordStrength required by Hash, 0 disables the check
+}
+
+// Hash hashes the password with the configured algorithm
+func (p Password) Hash(password string) (string, error) {
+	_ = helper.CheckAndSetDefault(&p)
+
+	if p.MinStrength > 0 && validator.PasswordStrength(password) < p.MinStrength {
+		return "", ErrPasswordTooWeak
+	}
+
+	switch p.Algorithm {
+	case PasswordBcrypt:
+		return PasswordHash(password, p.Cost)
+	case PasswordArgon2id:
+		if err := checkArgon2Params(p); err != nil {
+			return "", err
+		}
+		if p.SaltLength <= 0 || p.KeyLength <= 0 {
+			return "", errors.New("argon2id salt and key length must be positive")
+		}
+		salt := make([]byte, p.SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, uint32(p.Time), uint32(p.Memory), uint8(p.Threads), uint32(p.KeyLength))
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, p.Memory, p.Time, p.Threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	default:
+		return "", fmt.Errorf("unsupported password algorithm: %s", p.Algorithm)
+	}
+}
+
+// Verify validates the password against a bcrypt or argon2id hash
+func (p Password) Verify(password, hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := parseArgon2Hash(hash)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(password), salt, uint32(params.Time), uint32(params.Memory), uint8(params.Threads), uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+	return PasswordVerify(password, hash)
+}
+
+// NeedsRehash reports whether the hash was created with a different algorithm or weaker parameters
+func (p Password) NeedsRehash(hash string) bool {
+	_ = helper.CheckAndSetDefault(&p)
+
+	switch p.Algorithm {
+	case PasswordBcrypt:
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost < p.Cost
+	case PasswordArgon2id:
+		params, salt, key, err := parseArgon2Hash(hash)
+		if err != nil {
+			return true
+		}
+		return params.Time < p.Time || params.Memory < p.Memory || params.Threads < p.Threads ||
+			len(key) < p.KeyLength || len(salt) < p.SaltLength
+	default:
+		return false
+	}
+}
+
+// Strength returns the validator.PasswordStrength score of the password
+func (p Password) Strength(password string) int {
+	return validator.PasswordStrength(password)
+}
+
+// checkArgon2Params checks the argon2id parameters are within the supported range, argon2.IDKey
+// panics on zero iterations or parallelism and a uint8 parallelism above 255 would wrap to 0
+func checkArgon2Params(p Password) error {
+	if p.Time < 1 || p.Time > maxArgon2Time {
+		return fmt.Errorf("argon2id time must be between 1 and %d", maxArgon2Time)
+	}
+	if p.Threads < 1 || p.Threads > 255 {
+		return errors.New("argon2id parallelism must be between 1 and 255")
+	}
+	if p.Memory < 1 || p.Memory > maxArgon2Memory {
+		return fmt.Errorf("argon2id memory must be between 1 and %d KiB", maxArgon2Memory)
+	}
+	return nil
+}
+
+// parseArgon2Hash parses a hash in the PHC string format: $argon2id$v=19$m=65536,t=3,p=2$salt$key
+func parseArgon2Hash(hash string) (params Password, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordArgon2id {
+		return params, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, err
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, err
+	}
+	if err = checkArgon2Params(params); err != nil {
+		return params, nil, nil, err
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, err
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return params, nil, nil, err
+	}
+	if len(salt) == 0 || len(key) == 0 {
+		return params, nil, nil, errors.New("invalid argon2id hash: empty salt or key")
+	}
+	params.Algorithm = PasswordArgon2id
+	return params, salt, key, nil
+}
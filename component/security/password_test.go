@@ -0,0 +1,74 @@
+package security
+
+import "testing"
+
+func TestPasswordArgon2idRoundTrip(t *testing.T) {
+	p := Password{Algorithm: PasswordArgon2id, Memory: 1024, Time: 1, Threads: 1}
+	hash, err := p.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Verify("correct horse", hash) || p.Verify("wrong", hash) {
+		t.Fatal("argon2id hash should verify only the original password")
+	}
+	if p.NeedsRehash(hash) {
+		t.Fatal("hash created with the same parameters should not need rehash")
+	}
+	if !(Password{Algorithm: PasswordArgon2id, Memory: 2048, Time: 1, Threads: 1}).NeedsRehash(hash) {
+		t.Fatal("hash with less memory should need rehash")
+	}
+}
+
+func TestPasswordVerifyRejectsMalformedArgon2Hash(t *testing.T) {
+	const salt = "c29tZXNhbHRzb21lc2FsdA"
+	const key = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	cases := map[string]string{
+		"zero parallelism":   "$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key,
+		"parallelism wraps":  "$argon2id$v=19$m=1024,t=1,p=256$" + salt + "$" + key,
+		"zero time":          "$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key,
+		"huge memory":        "$argon2id$v=19$m=4294967295,t=1,p=1$" + salt + "$" + key,
+		"negative memory":    "$argon2id$v=19$m=-1,t=1,p=1$" + salt + "$" + key,
+		"empty key":          "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$",
+		"empty salt":         "$argon2id$v=19$m=1024,t=1,p=1$$" + key,
+		"wrong version":      "$argon2id$v=16$m=1024,t=1,p=1$" + salt + "$" + key,
+		"missing segments":   "$argon2id$v=19$m=1024,t=1,p=1",
+		"invalid parameters": "$argon2id$v=19$m=x,t=1,p=1$" + salt + "$" + key,
+	}
+	p := Password{Algorithm: PasswordArgon2id}
+	for name, hash := range cases {
+		t.Run(name, func(t *testing.T) {
+			if p.Verify("password", hash) {
+				t.Fatal("malformed hash should not verify")
+			}
+			if !p.NeedsRehash(hash) {
+				t.Fatal("malformed hash should need rehash")
+			}
+		})
+	}
+}
+
+func TestPasswordHashRejectsInvalidArgon2Config(t *testing.T) {
+	for _, p := range []Password{
+		{Algorithm: PasswordArgon2id, Threads: 256},
+		{Algorithm: PasswordArgon2id, Memory: maxArgon2Memory + 1},
+		{Algorithm: PasswordArgon2id, Time: -1},
+	} {
+		if _, err := p.Hash("password"); err == nil {
+			t.Errorf("Hash with %+v should fail", p)
+		}
+	}
+}
+
+func TestPasswordBcrypt(t *testing.T) {
+	p := Password{Cost: 4}
+	hash, err := p.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Verify("password", hash) || p.Verify("other", hash) {
+		t.Fatal("bcrypt hash should verify only the original password")
+	}
+	if !(Password{Cost: 5}).NeedsRehash(hash) {
+		t.Fatal("hash with a lower cost should need rehash")
+	}
+}
@@ -0,0 +1,343 @@
+package security
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RSA encrypts, decrypts, signs and verifies Text with the given keys.
+//
+// Keys may be PEM content, base64 encoded DER or a path to a PEM file.
+// Public keys may also be given as an X.509 certificate.
+type RSA struct {
+	Text       string `json:"text" default:""`
+	PublicKey  string `json:"public_key" default:""`
+	PrivateKey string `json:"private_key" default:""`
+	Padding    string `json:"padding" default:"OAEP"`       // encryption padding: OAEP or PKCS1v15
+	SignMode   string `json:"sign_mode" default:"PKCS1v15"` // signature scheme: PKCS1v15 or PSS
+	Hash       string `json:"hash" default:"SHA256"`        // SHA1, SHA256 or SHA512
+	Encoding   string `json:"encoding" default:"base64"`    // ciphertext/signature encoding: base64, base64url, hex or raw
+}
+
+// Encrypt encrypts Text with the public key
+func (r RSA) Encrypt(cipherText *string) error {
+	_ = helper.CheckAndSetDefault(&r)
+
+	publicKey, err := ParseRSAPublicKey(r.PublicKey)
+	if err != nil {
+		return err
+	}
+	_, hashNew, err := rsaHash(r.Hash)
+	if err != nil {
+		return err
+	}
+
+	var cipherBytes []byte
+	switch strings.ToUpper(r.Padding) {
+	case "OAEP":
+		cipherBytes, err = rsaChunk([]byte(r.Text), publicKey.Size()-2*hashNew().Size()-2, func(chunk []byte) ([]byte, error) {
+			return rsa.EncryptOAEP(hashNew(), rand.Reader, publicKey, chunk, nil)
+		})
+	case "PKCS1V15":
+		cipherBytes, err = rsaChunk([]byte(r.Text), publicKey.Size()-11, func(chunk []byte) ([]byte, error) {
+			return rsa.EncryptPKCS1v15(rand.Reader, publicKey, chunk)
+		})
+	default:
+		return fmt.Errorf("unsupported RSA padding: %s", r.Padding)
+	}
+	if err != nil {
+		return err
+	}
+
+	*cipherText, err = encodeBytes(cipherBytes, r.Encoding)
+	return err
+}
+
+// Decrypt decrypts Text with the private key
+func (r RSA) Decrypt(plaintext *string) error {
+	_ = helper.CheckAndSetDefault(&r)
+
+	privateKey, err := ParseRSAPrivateKey(r.PrivateKey)
+	if err != nil {
+		return err
+	}
+	_, hashNew, err := rsaHash(r.Hash)
+	if err != nil {
+		return err
+	}
+	cipherBytes, err := decodeBytes(r.Text, r.Encoding)
+	if err != nil {
+		return err
+	}
+
+	var plainBytes []byte
+	switch strings.ToUpper(r.Padding) {
+	case "OAEP":
+		plainBytes, err = rsaChunk(cipherBytes, privateKey.Size(), func(chunk []byte) ([]byte, error) {
+			return rsa.DecryptOAEP(hashNew(), rand.Reader, privateKey, chunk, nil)
+		})
+	case "PKCS1V15":
+		plainBytes, err = rsaChunk(cipherBytes, privateKey.Size(), func(chunk []byte) ([]byte, error) {
+			return rsa.DecryptPKCS1v15(rand.Reader, privateKey, chunk)
+		})
+	default:
+		return fmt.Errorf("unsupported RSA padding: %s", r.Padding)
+	}
+	if err != nil {
+		return err
+	}
+
+	*plaintext = string(plainBytes)
+	return nil
+}
+
+// Sign signs Text with the private key
+func (r RSA) Sign(signature *string) error {
+	_ = helper.CheckAndSetDefault(&r)
+
+	privateKey, err := ParseRSAPrivateKey(r.PrivateKey)
+	if err != nil {
+		return err
+	}
+	hashFunc, hashNew, err := rsaHash(r.Hash)
+	if err != nil {
+		return err
+	}
+	h := hashNew()
+	h.Write([]byte(r.Text))
+	digest := h.Sum(nil)
+
+	var sig []byte
+	switch strings.ToUpper(r.SignMode) {
+	case "PKCS1V15":
+		sig, err = rsa.SignPKCS1v15(rand.Reader, privateKey, hashFunc, digest)
+	case "PSS":
+		sig, err = rsa.SignPSS(rand.Reader, privateKey, hashFunc, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	default:
+		return fmt.Errorf("unsupported RSA sign mode: %s", r.SignMode)
+	}
+	if err != nil {
+		return err
+	}
+
+	*signature, err = encodeBytes(sig, r.Encoding)
+	return err
+}
+
+// Verify checks the signature of Text with the public key
+func (r RSA) Verify(signature string) error {
+	_ = helper.CheckAndSetDefault(&r)
+
+	publicKey, err := ParseRSAPublicKey(r.PublicKey)
+	if err != nil {
+		return err
+	}
+	hashFunc, hashNew, err := rsaHash(r.Hash)
+	if err != nil {
+		return err
+	}
+	sig, err := decodeBytes(signature, r.Encoding)
+	if err != nil {
+		return err
+	}
+	h := hashNew()
+	h.Write([]byte(r.Text))
+	digest := h.Sum(nil)
+
+	switch strings.ToUpper(r.SignMode) {
+	case "PKCS1V15":
+		err = rsa.VerifyPKCS1v15(publicKey, hashFunc, digest, sig)
+	case "PSS":
+		err = rsa.VerifyPSS(publicKey, hashFunc, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+	default:
+		return fmt.Errorf("unsupported RSA sign mode: %s", r.SignMode)
+	}
+	if err != nil {
+		return errors.New("RSA signature verification failed")
+	}
+	return nil
+}
+
+// GenerateRSAKey generates a key pair and returns the PKCS8 private key and PKIX public key in PEM format
+func GenerateRSAKey(bits int) (privatePEM, publicPEM string, err error) {
+	if bits < 2048 {
+		bits = 2048
+	}
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return "", "", err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", "", err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	privatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	return
+}
+
+// SaveRSAKey writes PEM content to the given path, private keys are written with 0600 permission
+func SaveRSAKey(path, pemContent string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if strings.Contains(pemContent, "PRIVATE KEY") {
+		perm = 0600
+	}
+	return os.WriteFile(path, []byte(pemContent), perm)
+}
+
+// ParseRSAPrivateKey parses a PKCS1 or PKCS8 private key from PEM content, base64 encoded DER or a file path
+func ParseRSAPrivateKey(key string) (*rsa.PrivateKey, error) {
+	der, err := readKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if privateKey, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return privateKey, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSA private key: %v", err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return privateKey, nil
+}
+
+// ParseRSAPublicKey parses a certificate, PKIX or PKCS1 public key from PEM content, base64 encoded DER or a file path
+func ParseRSAPublicKey(key string) (*rsa.PublicKey, error) {
+	der, err := readKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		if publicKey, ok := cert.PublicKey.(*rsa.PublicKey); ok {
+			return publicKey, nil
+		}
+		return nil, errors.New("certificate public key is not an RSA key")
+	}
+	if parsed, err := x509.ParsePKIXPublicKey(der); err == nil {
+		if publicKey, ok := parsed.(*rsa.PublicKey); ok {
+			return publicKey, nil
+		}
+		return nil, errors.New("public key is not an RSA key")
+	}
+	publicKey, err := x509.ParsePKCS1PublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSA public key: %v", err)
+	}
+	return publicKey, nil
+}
+
+// readKey reads DER bytes from PEM content, base64 encoded DER or a file path
+func readKey(key string) ([]byte, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+	if !strings.Contains(key, "-----BEGIN") && helper.NewFile(&helper.File{Path: key}).Exists() {
+		content, err := os.ReadFile(key)
+		if err != nil {
+			return nil, err
+		}
+		key = strings.TrimSpace(string(content))
+	}
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		return block.Bytes, nil
+	}
+	return base64.StdEncoding.DecodeString(key)
+}
+
+// rsaChunk processes data in chunks of the given size so that long texts can be encrypted
+func rsaChunk(data []byte, size int, fn func(chunk []byte) ([]byte, error)) ([]byte, error) {
+	if size <= 0 {
+		return nil, errors.New("RSA key is too small for the selected padding")
+	}
+	var result []byte
+	for start := 0; start < len(data) || start == 0; start += size {
+		end := start + size
+		if end > len(data) {
+			end = len(data)
+		}
+		out, err := fn(data[start:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, out...)
+		if end == len(data) {
+			break
+		}
+	}
+	return result, nil
+}
+
+// rsaHash returns the hash function for the given name
+func rsaHash(name string) (crypto.Hash, func() hash.Hash, error) {
+	switch strings.ToUpper(strings.ReplaceAll(name, "-", "")) {
+	case "SHA1":
+		return crypto.SHA1, sha1.New, nil
+	case "SHA256":
+		return crypto.SHA256, sha256.New, nil
+	case "SHA512":
+		return crypto.SHA512, sha512.New, nil
+	default:
+		return 0, nil, fmt.Errorf("unsupported hash: %s", name)
+	}
+}
+
+// encodeBytes encodes binary output with the given encoding
+func encodeBytes(data []byte, encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case "", "base64":
+		return base64.StdEncoding.EncodeToString(data), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	case "hex":
+		return hex.EncodeToString(data), nil
+	case "raw":
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// decodeBytes decodes input with the given encoding
+func decodeBytes(data string, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", "base64":
+		return base64.StdEncoding.DecodeString(data)
+	case "base64url":
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(data, "="))
+	case "hex":
+		return hex.DecodeString(data)
+	case "raw":
+		return []byte(data), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
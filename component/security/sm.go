@@ -0,0 +1,286 @@
+package security
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm3"
+	"github.com/tjfoc/gmsm/sm4"
+	gmx509 "github.com/tjfoc/gmsm/x509"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SM2 encrypts, decrypts, signs and verifies Text with SM2 keys.
+//
+// Keys may be PEM content, a path to a PEM file, or hex (private key D, or public key 04||X||Y).
+type SM2 struct {
+	Text       string `json:"text" default:""`
+	PublicKey  string `json:"public_key" default:""`
+	PrivateKey string `json:"private_key" default:""`
+	Mode       string `json:"mode" default:"C1C3C2"`          // ciphertext layout: C1C3C2 (GM/T 0003-2012) or C1C2C3
+	UID        string `json:"uid" default:"1234567812345678"` // signer id used to compute ZA
+	Encoding   string `json:"encoding" default:"base64"`      // ciphertext/signature encoding: base64, base64url, hex or raw
+}
+
+// sm2Signature is the ASN.1 structure of an SM2 signature
+type sm2Signature struct {
+	R, S *big.Int
+}
+
+// Encrypt encrypts Text with the public key
+func (s SM2) Encrypt(cipherText *string) error {
+	_ = helper.CheckAndSetDefault(&s)
+
+	publicKey, err := ParseSM2PublicKey(s.PublicKey)
+	if err != nil {
+		return err
+	}
+	mode, err := sm2Mode(s.Mode)
+	if err != nil {
+		return err
+	}
+	cipherBytes, err := sm2.Encrypt(publicKey, []byte(s.Text), rand.Reader, mode)
+	if err != nil {
+		return err
+	}
+
+	*cipherText, err = encodeBytes(cipherBytes, s.Encoding)
+	return err
+}
+
+// Decrypt decrypts Text with the private key
+func (s SM2) Decrypt(plaintext *string) error {
+	_ = helper.CheckAndSetDefault(&s)
+
+	privateKey, err := ParseSM2PrivateKey(s.PrivateKey)
+	if err != nil {
+		return err
+	}
+	mode, err := sm2Mode(s.Mode)
+	if err != nil {
+		return err
+	}
+	cipherBytes, err := decodeBytes(s.Text, s.Encoding)
+	if err != nil {
+		return err
+	}
+	plainBytes, err := sm2.Decrypt(privateKey, cipherBytes, mode)
+	if err != nil {
+		return err
+	}
+
+	*plaintext = string(plainBytes)
+	return nil
+}
+
+// Sign signs Text with the private key and returns an ASN.1 DER encoded signature
+func (s SM2) Sign(signature *string) error {
+	_ = helper.CheckAndSetDefault(&s)
+
+	privateKey, err := ParseSM2PrivateKey(s.PrivateKey)
+	if err != nil {
+		return err
+	}
+	r, ss, err := sm2.Sm2Sign(privateKey, []byte(s.Text), []byte(s.UID), rand.Reader)
+	if err != nil {
+		return err
+	}
+	sig, err := asn1.Marshal(sm2Signature{R: r, S: ss})
+	if err != nil {
+		return err
+	}
+
+	*signature, err = encodeBytes(sig, s.Encoding)
+	return err
+}
+
+// Verify checks the ASN.1 DER encoded signature of Text with the public key
+func (s SM2) Verify(signature string) error {
+	_ = helper.CheckAndSetDefault(&s)
+
+	publicKey, err := ParseSM2PublicKey(s.PublicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := decodeBytes(signature, s.Encoding)
+	if err != nil {
+		return err
+	}
+	var parsed sm2Signature
+	if _, err = asn1.Unmarshal(sig, &parsed); err != nil {
+		return fmt.Errorf("invalid SM2 signature: %v", err)
+	}
+	if !sm2.Sm2Verify(publicKey, []byte(s.Text), []byte(s.UID), parsed.R, parsed.S) {
+		return errors.New("SM2 signature verification failed")
+	}
+	return nil
+}
+
+// GenerateSM2Key generates a key pair and returns the PKCS8 private key and PKIX public key in PEM format
+func GenerateSM2Key() (privatePEM, publicPEM string, err error) {
+	privateKey, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	privateBytes, err := gmx509.WritePrivateKeyToPem(privateKey, nil)
+	if err != nil {
+		return "", "", err
+	}
+	publicBytes, err := gmx509.WritePublicKeyToPem(&privateKey.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+	return string(privateBytes), string(publicBytes), nil
+}
+
+var hexPattern = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// ParseSM2PrivateKey parses an SM2 private key from PEM content, a file path or hex
+func ParseSM2PrivateKey(key string) (*sm2.PrivateKey, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+	if len(key) <= 64 && hexPattern.MatchString(key) {
+		return gmx509.ReadPrivateKeyFromHex(key)
+	}
+	content, err := readPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	return gmx509.ReadPrivateKeyFromPem(content, nil)
+}
+
+// ParseSM2PublicKey parses an SM2 public key from PEM content, a file path or hex
+func ParseSM2PublicKey(key string) (*sm2.PublicKey, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+	if hexPattern.MatchString(key) {
+		return gmx509.ReadPublicKeyFromHex(key)
+	}
+	content, err := readPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	return gmx509.ReadPublicKeyFromPem(content)
+}
+
+// readPEM returns PEM content, reading it from a file when key is a path
+func readPEM(key string) ([]byte, error) {
+	if strings.Contains(key, "-----BEGIN") {
+		return []byte(key), nil
+	}
+	if !helper.NewFile(&helper.File{Path: key}).Exists() {
+		return nil, errors.New("key is neither PEM content nor an existing file")
+	}
+	return os.ReadFile(key)
+}
+
+// sm2Mode converts the ciphertext layout name to the gmsm constant
+func sm2Mode(mode string) (int, error) {
+	switch strings.ToUpper(mode) {
+	case "C1C3C2":
+		return sm2.C1C3C2, nil
+	case "C1C2C3":
+		return sm2.C1C2C3, nil
+	default:
+		return 0, fmt.Errorf("unsupported SM2 mode: %s", mode)
+	}
+}
+
+// SM3 computes the SM3 digest of Text, or the HMAC-SM3 when Key is set
+type SM3 struct {
+	Text     string `json:"text" default:""`
+	Key      string `json:"key" default:""`
+	Encoding string `json:"encoding" default:"hex"` // digest encoding: hex, base64, base64url or raw
+}
+
+// Sum computes the digest of Text
+func (s SM3) Sum(digest *string) error {
+	_ = helper.CheckAndSetDefault(&s)
+
+	var sum []byte
+	if s.Key != "" {
+		mac := hmac.New(sm3.New, []byte(s.Key))
+		mac.Write([]byte(s.Text))
+		sum = mac.Sum(nil)
+	} else {
+		sum = sm3.Sm3Sum([]byte(s.Text))
+	}
+
+	var err error
+	*digest, err = encodeBytes(sum, s.Encoding)
+	return err
+}
+
+// SM4 encrypts and decrypts Text with SM4 in CBC mode and PKCS7 padding, mirroring AES
+type SM4 struct {
+	Text     string `json:"text" default:""`
+	Key      string `json:"key" default:"jcbase.sm4_key__"`
+	Iv       string `json:"iv" default:"jcbase.sm4_iv___"`
+	Encoding string `json:"encoding" default:"base64"` // ciphertext encoding: base64, base64url or hex
+}
+
+// Encrypt encrypts the given text using the provided key and iv
+func (s SM4) Encrypt(cipherText *string) error {
+	_ = helper.CheckAndSetDefault(&s)
+
+	block, err := s.block()
+	if err != nil {
+		return err
+	}
+
+	plainText := pkcs7Pad([]byte(s.Text), block.BlockSize())
+	cipherBytes := make([]byte, len(plainText))
+	cipher.NewCBCEncrypter(block, []byte(s.Iv)).CryptBlocks(cipherBytes, plainText)
+
+	*cipherText, err = encodeBytes(cipherBytes, s.Encoding)
+	return err
+}
+
+// Decrypt decrypts the given cipherText using the provided key and iv
+func (s SM4) Decrypt(plaintext *string) error {
+	_ = helper.CheckAndSetDefault(&s)
+
+	block, err := s.block()
+	if err != nil {
+		return err
+	}
+	cipherBytes, err := decodeBytes(s.Text, s.Encoding)
+	if err != nil {
+		return err
+	}
+	if len(cipherBytes) == 0 || len(cipherBytes)%block.BlockSize() != 0 {
+		return errors.New("ciphertext is not a multiple of the block size")
+	}
+
+	cipher.NewCBCDecrypter(block, []byte(s.Iv)).CryptBlocks(cipherBytes, cipherBytes)
+	plainBytes, err := pkcs7Unpad(cipherBytes, block.BlockSize())
+	if err != nil {
+		return err
+	}
+
+	*plaintext = string(plainBytes)
+	return nil
+}
+
+// block validates the key and iv and creates the cipher
+func (s SM4) block() (cipher.Block, error) {
+	if len(s.Key) != sm4.BlockSize {
+		return nil, fmt.Errorf("key length must be 16 bytes; got key len (%d)", len(s.Key))
+	}
+	if len(s.Iv) != sm4.BlockSize {
+		return nil, errors.New("IV length must be 16 bytes")
+	}
+	return sm4.NewCipher([]byte(s.Key))
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Instance struct {
+	Conf    jcbaseGo.ServerStruct
+	Handler http.Handler
+
+	server     *http.Server // 主服务
+	httpServer *http.Server // http跳转及证书验证服务
+}
+
+// New 创建http服务实例
+//
+// 示例:
+//
+//	r := gin.Default()
+//	srv := server.New(conf.Server, r)
+//	if err := srv.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+//	    log.Fatal(err)
+//	}
+func New(conf jcbaseGo.ServerStruct, handler http.Handler) *Instance {
+	err := helper.CheckAndSetDefault(&conf)
+	jcbaseGo.PanicIfError(err)
+
+	return &Instance{
+		Conf:    conf,
+		Handler: handler,
+	}
+}
+
+// IsTLS 是否以https方式启动
+func (i *Instance) IsTLS() bool {
+	return len(i.Conf.AutoCertDomains) > 0 || (i.Conf.SSL.CertPath != "" && i.Conf.SSL.KeyPath != "")
+}
+
+// Run 启动服务，阻塞直到服务关闭
+func (i *Instance) Run() error {
+	i.server = &http.Server{
+		Addr:         i.Conf.Addr,
+		Handler:      i.Handler,
+		ReadTimeout:  time.Duration(i.Conf.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(i.Conf.WriteTimeout) * time.Second,
+		IdleTimeout:  time.Duration(i.Conf.IdleTimeout) * time.Second,
+	}
+
+	if !i.IsTLS() {
+		if i.Conf.H2C && !i.Conf.DisableHTTP2 {
+			i.server.Handler = h2c.NewHandler(i.Handler, &http2.Server{})
+		}
+		log.Printf("http服务启动，监听地址：%s\n", i.Conf.Addr)
+		return i.server.ListenAndServe()
+	}
+
+	tlsConfig, httpHandler, err := i.tlsConfig()
+	if err != nil {
+		return err
+	}
+	i.server.TLSConfig = tlsConfig
+
+	if i.Conf.DisableHTTP2 {
+		// TLSNextProto 不为nil时，net/http 不会自动启用HTTP/2
+		i.server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	} else if err = http2.ConfigureServer(i.server, &http2.Server{}); err != nil {
+		return fmt.Errorf("配置HTTP/2失败: %v", err)
+	}
+
+	// 开启http跳转或自动申请证书时，需要额外监听http端口
+	if httpHandler != nil {
+		i.httpServer = &http.Server{
+			Addr:         i.Conf.HTTPAddr,
+			Handler:      httpHandler,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+		go func() {
+			log.Printf("http服务启动，监听地址：%s\n", i.Conf.HTTPAddr)
+			if err := i.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Println("http服务异常退出:", err)
+			}
+		}()
+	}
+
+	log.Printf("https服务启动，监听地址：%s\n", i.Conf.Addr)
+	// 证书已在TLSConfig中配置，这里无需再传入证书路径
+	return i.server.ListenAndServeTLS("", "")
+}
+
+// Shutdown 优雅关闭服务
+func (i *Instance) Shutdown(ctx context.Context) error {
+	var errs []error
+	if i.httpServer != nil {
+		errs = append(errs, i.httpServer.Shutdown(ctx))
+	}
+	if i.server != nil {
+		errs = append(errs, i.server.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// tlsConfig 生成tls配置，并返回http端口需要使用的处理器（不需要监听http端口时为nil）
+func (i *Instance) tlsConfig() (*tls.Config, http.Handler, error) {
+	var httpHandler http.Handler
+	if i.Conf.HTTPRedirect {
+		httpHandler = http.HandlerFunc(i.redirectToHTTPS)
+	}
+
+	if len(i.Conf.AutoCertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(i.Conf.AutoCertDomains...),
+			Cache:      autocert.DirCache(i.Conf.AutoCertDir),
+			Email:      i.Conf.AutoCertEmail,
+		}
+		// http-01 验证必须通过80端口完成，未开启跳转时其余请求返回404
+		return manager.TLSConfig(), manager.HTTPHandler(httpHandler), nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(i.Conf.SSL.CertPath, i.Conf.SSL.KeyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("加载证书和私钥失败: %v", err)
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}, httpHandler, nil
+}
+
+// redirectToHTTPS 将http请求跳转到https
+func (i *Instance) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	// 非443端口时需要带上端口号
+	if _, port, err := net.SplitHostPort(i.Conf.Addr); err == nil && port != "" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+	target := "https://" + host + r.URL.RequestURI()
+	if strings.ContainsAny(host, "\r\n") {
+		http.Error(w, "invalid host", http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
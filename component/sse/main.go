@@ -0,0 +1,240 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamingKey 流式响应标记在gin上下文中的键名，
+// 访问日志、调试器等记录响应体的组件应据此跳过响应体的记录
+const StreamingKey = "StreamingResponse"
+
+// ErrClosed 客户端已断开连接
+var ErrClosed = errors.New("sse stream closed")
+
+// Event 单条事件
+type Event struct {
+	ID    string // 事件ID，客户端重连时会通过 Last-Event-ID 请求头带回
+	Event string // 事件名称，为空时客户端触发 message 事件
+	Data  any    // 事件数据，string/[]byte 原样输出，其余类型序列化为JSON
+	Retry int    // 客户端重连间隔，单位毫秒，0表示不设置
+}
+
+// Options 流配置
+type Options struct {
+	HeartbeatInterval time.Duration // 心跳间隔，默认15秒，小于0时不发送心跳
+}
+
+// Stream SSE连接
+type Stream struct {
+	GinContext *gin.Context
+
+	flusher   http.Flusher
+	mu        sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New 将当前请求转换为SSE流，并按配置发送心跳
+func New(c *gin.Context, opts ...Options) (*Stream, error) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		return nil, errors.New("当前ResponseWriter不支持流式输出")
+	}
+
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.HeartbeatInterval == 0 {
+		opt.HeartbeatInterval = 15 * time.Second
+	}
+
+	c.Set(StreamingKey, true)
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// 禁止nginx缓冲响应
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	flusher.Flush()
+
+	s := &Stream{
+		GinContext: c,
+		flusher:    flusher,
+		done:       make(chan struct{}),
+	}
+
+	// 客户端断开连接时关闭流
+	go func() {
+		select {
+		case <-c.Request.Context().Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+
+	if opt.HeartbeatInterval > 0 {
+		go s.heartbeat(opt.HeartbeatInterval)
+	}
+
+	return s, nil
+}
+
+// Handler 将处理函数包装为gin处理器，处理函数返回后流即结束
+//
+// 示例:
+//
+//	r.GET("/events", sse.Handler(func(s *sse.Stream) error {
+//	    for msg := range subscribe() {
+//	        if err := s.Send(sse.Event{Event: "message", Data: msg}); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    return nil
+//	}))
+func Handler(fn func(s *Stream) error, opts ...Options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s, err := New(c, opts...)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		defer s.Close()
+
+		if err = fn(s); err != nil && !errors.Is(err, ErrClosed) {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// Send 发送一条事件
+func (s *Stream) Send(e Event) error {
+	payload, err := Format(e)
+	if err != nil {
+		return err
+	}
+	return s.write(payload)
+}
+
+// SendData 发送一条只包含数据的事件
+func (s *Stream) SendData(data any) error {
+	return s.Send(Event{Data: data})
+}
+
+// Heartbeat 发送心跳（注释行），用于保持连接及检测客户端是否断开
+func (s *Stream) Heartbeat() error {
+	return s.write([]byte(": ping\n\n"))
+}
+
+// LastEventID 获取客户端重连时带回的最后一个事件ID
+func (s *Stream) LastEventID() string {
+	return s.GinContext.GetHeader("Last-Event-ID")
+}
+
+// Done 客户端断开或流关闭时关闭的通道
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Closed 流是否已关闭
+func (s *Stream) Closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close 关闭流
+func (s *Stream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// Format 将事件格式化为SSE协议文本
+func Format(e Event) ([]byte, error) {
+	var sb strings.Builder
+
+	if e.ID != "" {
+		sb.WriteString("id: " + stripNewline(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		sb.WriteString("event: " + stripNewline(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		sb.WriteString(fmt.Sprintf("retry: %d\n", e.Retry))
+	}
+
+	var data string
+	switch v := e.Data.(type) {
+	case nil:
+		data = ""
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = string(jsonBytes)
+	}
+
+	// 多行数据需要拆分为多个data字段
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+
+	return []byte(sb.String()), nil
+}
+
+// write 写入并立即刷新
+func (s *Stream) write(payload []byte) error {
+	if s.Closed() {
+		return ErrClosed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.GinContext.Writer.Write(payload); err != nil {
+		s.Close()
+		return ErrClosed
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// heartbeat 定时发送心跳
+func (s *Stream) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Heartbeat(); err != nil {
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// stripNewline 移除字段中的换行，避免破坏协议格式
+func stripNewline(str string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(str)
+}
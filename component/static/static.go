@@ -0,0 +1,259 @@
+// Package static 静态文件服务
+//
+// 支持 embed.FS 及磁盘目录，自动处理 ETag/Last-Modified 条件请求及 Range 请求；
+// 存在同名的 .br/.gz 预压缩文件且客户端支持时直接输出压缩文件；可选开启单页应用（SPA）回退到 index.html。
+package static
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options 配置
+type Options struct {
+	Prefix            string        // 路由前缀，如 /static，请求路径去掉前缀后在文件系统中查找
+	Index             string        `default:"index.html"` // 目录的默认文件
+	SPA               bool          // 文件不存在时是否回退到根目录的 Index（仅无扩展名的路径，如前端路由 /user/1）
+	SPAExclude        []string      // 不回退的路径前缀，如 /api
+	MaxAge            time.Duration // 普通文件的缓存时间，0表示每次协商缓存（no-cache）
+	ImmutablePrefixes []string      // 带内容哈希的文件所在的路径前缀，如 /assets/，缓存一年且标记为 immutable
+	NoPrecompressed   bool          // 是否禁用 .br/.gz 预压缩文件
+}
+
+// Server 静态文件服务
+//
+// 示例:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	sub, _ := fs.Sub(dist, "dist")
+//	s := static.New(sub, static.Options{SPA: true, SPAExclude: []string{"/api"}, ImmutablePrefixes: []string{"/assets/"}})
+//	r.NoRoute(s.Handler())
+//
+//	// 或挂载到指定前缀
+//	static.Dir("./public", static.Options{Prefix: "/static", MaxAge: time.Hour}).Register(r)
+type Server struct {
+	fsys fs.FS
+	opt  Options
+
+	etags sync.Map // 无修改时间的文件（如 embed.FS）按内容计算的ETag，name => string
+}
+
+// New 基于文件系统创建静态文件服务
+func New(fsys fs.FS, opts ...Options) *Server {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	opt.Prefix = "/" + strings.Trim(opt.Prefix, "/")
+	return &Server{fsys: fsys, opt: opt}
+}
+
+// Dir 基于磁盘目录创建静态文件服务
+func Dir(root string, opts ...Options) *Server {
+	return New(os.DirFS(root), opts...)
+}
+
+// Register 在 Prefix 下注册 GET、HEAD 路由
+func (s *Server) Register(r gin.IRoutes) {
+	route := strings.TrimSuffix(s.opt.Prefix, "/") + "/*filepath"
+	r.GET(route, s.Handler())
+	r.HEAD(route, s.Handler())
+}
+
+// Handler 处理静态文件请求，可用于 Register 注册的路由或 r.NoRoute；文件不存在时返回404
+func (s *Server) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.AbortWithStatus(http.StatusMethodNotAllowed)
+			return
+		}
+		urlPath := c.Request.URL.Path
+		if !strings.HasPrefix(urlPath, s.opt.Prefix) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if !s.Serve(c, strings.TrimPrefix(urlPath, s.opt.Prefix)) {
+			c.AbortWithStatus(http.StatusNotFound)
+		}
+	}
+}
+
+// Serve 输出文件系统中的文件，name 为相对路径，文件不存在（且不能回退）时返回false
+func (s *Server) Serve(c *gin.Context, name string) bool {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = s.opt.Index
+	}
+
+	file, ok := s.open(name)
+	if !ok && s.fallback(name) {
+		name = s.opt.Index
+		file, ok = s.open(name)
+	}
+	if !ok {
+		return false
+	}
+	defer file.Close()
+
+	s.serveFile(c, name, file)
+	return true
+}
+
+// open 打开文件，目录时打开其中的 Index
+func (s *Server) open(name string) (fs.File, bool) {
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return nil, false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, false
+	}
+	if !info.IsDir() {
+		return f, true
+	}
+	_ = f.Close()
+	return s.open(path.Join(name, s.opt.Index))
+}
+
+// fallback 是否回退到 Index
+func (s *Server) fallback(name string) bool {
+	if !s.opt.SPA || path.Ext(name) != "" {
+		return false
+	}
+	for _, prefix := range s.opt.SPAExclude {
+		if strings.HasPrefix("/"+name, "/"+strings.Trim(prefix, "/")) {
+			return false
+		}
+	}
+	return true
+}
+
+// serveFile 设置缓存相关的响应头后输出文件，优先输出预压缩文件
+func (s *Server) serveFile(c *gin.Context, name string, file fs.File) {
+	header := c.Writer.Header()
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	}
+	header.Set("Cache-Control", s.cacheControl(name))
+
+	if !s.opt.NoPrecompressed {
+		header.Add("Vary", "Accept-Encoding")
+		accept := c.GetHeader("Accept-Encoding")
+		for _, enc := range []struct{ name, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+			if !acceptsEncoding(accept, enc.name) {
+				continue
+			}
+			compressed, err := s.fsys.Open(name + enc.ext)
+			if err != nil {
+				continue
+			}
+			defer compressed.Close()
+			if info, err := compressed.Stat(); err == nil && !info.IsDir() {
+				header.Set("Content-Encoding", enc.name)
+				s.serveContent(c, name+enc.ext, compressed)
+				return
+			}
+		}
+	}
+	s.serveContent(c, name, file)
+}
+
+// serveContent 计算ETag后交给 http.ServeContent 处理条件请求及 Range 请求
+func (s *Server) serveContent(c *gin.Context, name string, file fs.File) {
+	info, err := file.Stat()
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+
+	etag, err := s.etag(name, info, content)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Writer.Header().Set("ETag", etag)
+	http.ServeContent(c.Writer, c.Request, name, info.ModTime(), content)
+}
+
+// etag 有修改时间时由大小和修改时间生成，否则按内容哈希生成并缓存
+func (s *Server) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano()), nil
+	}
+	if v, ok := s.etags.Load(name); ok {
+		return v.(string), nil
+	}
+	hash := sha1.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(hash.Sum(nil))[:20] + `"`
+	s.etags.Store(name, etag)
+	return etag, nil
+}
+
+// cacheControl 根据文件路径生成 Cache-Control
+func (s *Server) cacheControl(name string) string {
+	if path.Base(name) == s.opt.Index {
+		return "no-cache"
+	}
+	for _, prefix := range s.opt.ImmutablePrefixes {
+		if strings.HasPrefix("/"+name, "/"+strings.TrimPrefix(prefix, "/")) {
+			return "public, max-age=31536000, immutable"
+		}
+	}
+	if s.opt.MaxAge > 0 {
+		return "public, max-age=" + strconv.Itoa(int(s.opt.MaxAge.Seconds()))
+	}
+	return "no-cache"
+}
+
+// acceptsEncoding 判断 Accept-Encoding 是否接受指定编码（q=0 表示不接受）
+func acceptsEncoding(accept, encoding string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(token), encoding) {
+			continue
+		}
+		q := strings.TrimSpace(params)
+		if v, ok := strings.CutPrefix(q, "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil && f == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
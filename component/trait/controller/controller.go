@@ -3,9 +3,13 @@ package controller
 import (
 	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/enum"
 	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
 	"github.com/jcbowen/jcbaseGo/component/security"
 	"github.com/jcbowen/jcbaseGo/errcode"
+	"github.com/jcbowen/jcbaseGo/middleware"
 	"log"
 	"net/http"
 	"reflect"
@@ -93,6 +97,33 @@ func (c Base) Failure(args ...any) {
 	c.Result(code, message, data, additionalParams)
 }
 
+// Error 返回错误的响应
+// 错误链中存在 errcode.AppError 时使用其错误码、提示信息及对应的HTTP状态码，
+// 否则按 errcode.Unknown 处理，原始错误信息不会输出给客户端。
+//
+// 示例:
+//
+//	if err := svc.Create(data); err != nil {
+//		c.Error(err)
+//		return
+//	}
+func (c Base) Error(err error, data ...any) {
+	appErr := errcode.FromError(err)
+	if appErr == nil {
+		appErr = errcode.New(errcode.Unknown, "")
+	}
+	if appErr.HTTPStatus() >= http.StatusInternalServerError {
+		log.Printf("%+v\n", appErr)
+	}
+	_ = c.GinContext.Error(appErr)
+
+	var resultData any
+	if len(data) > 0 {
+		resultData = data[0]
+	}
+	c.GinContext.JSON(appErr.HTTPStatus(), jcbaseGo.Result{Code: appErr.Code, Message: i18n.Translate(c.GinContext, appErr.Message), Data: resultData})
+}
+
 // Result 整理结果输出
 // 这个方法用于统一返回API响应结果。接收状态码、消息以及可选的额外参数，
 // 并根据传入的数据类型对结果进行格式化和处理，最终返回JSON格式的响应。
@@ -107,7 +138,10 @@ func (c Base) Result(code int, msg string, args ...any) {
 	resultMapData := make(map[string]any)
 
 	if len(args) > 0 && !helper.IsEmptyValue(args[0]) {
-		data := args[0]
+		// 包含枚举字段时附加 <字段名>_label 显示文本
+		data := enum.Expand(args[0], func(label string) string {
+			return i18n.Translate(c.GinContext, label)
+		})
 		val := reflect.ValueOf(data)
 
 		// 如果是指针类型，获取指针指向的数据类型
@@ -152,10 +186,10 @@ func (c Base) Result(code int, msg string, args ...any) {
 		resultData = nil
 	}
 
-	// 构建结果map
+	// 构建结果map，使用了 i18n 中间件时提示信息可以是翻译键
 	result := map[string]any{
 		"code":    code,
-		"message": msg,
+		"message": i18n.Translate(c.GinContext, msg),
 		"data":    resultData,
 	}
 
@@ -268,3 +302,8 @@ func (c Base) GetSafeMapGPC(key ...string) (mapData map[string]any) {
 
 	return
 }
+
+// BindGPC 将GPC参数绑定到结构体中，字段标签规则见 middleware.GPC.Bind
+func (c Base) BindGPC(obj any) error {
+	return middleware.BindGPC(c.GinContext, obj)
+}
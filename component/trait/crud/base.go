@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jcbowen/jcbaseGo/component/helper"
 	"github.com/jcbowen/jcbaseGo/component/orm/mysql"
+	"github.com/jcbowen/jcbaseGo/component/orm/query"
 	"github.com/jcbowen/jcbaseGo/component/trait/controller"
 	"log"
 	"reflect"
@@ -13,13 +14,14 @@ import (
 
 type Trait struct {
 	// ----- 基础配置 ----- /
-	PkId               string          `default:"id"` // 数据表主键
-	Model              any             // 模型指针
-	ModelTableAlias    string          // 模型表别名
-	MysqlMain          *mysql.Instance // 数据库实例
-	ListResultStruct   interface{}     // 列表返回结构体
-	DetailResultStruct interface{}     // 详情返回结构体
-	Controller         interface{}     // 控制器
+	PkId               string             `default:"id"` // 数据表主键
+	Model              any                // 模型指针
+	ModelTableAlias    string             // 模型表别名
+	MysqlMain          *mysql.Instance    // 数据库实例
+	ListResultStruct   interface{}        // 列表返回结构体
+	ListTotal          query.TotalOptions // 列表总数的统计方式，默认精确统计，大表可设置为不统计、估算或缓存
+	DetailResultStruct interface{}        // 详情返回结构体
+	Controller         interface{}        // 控制器
 
 	// ----- 初始化时生成 ----- /
 	ModelTableName string   // 模型表名
@@ -184,6 +186,11 @@ func (t *Trait) Result(code int, msg string, args ...any) {
 	t.BaseControllerTrait.Result(code, msg, args...)
 }
 
+// Error 返回错误的响应，见 controller.Base.Error
+func (t *Trait) Error(err error, data ...any) {
+	t.BaseControllerTrait.Error(err, data...)
+}
+
 // BindMapToStruct 将 map 数据绑定到 struct，并处理类型转换
 func (t *Trait) BindMapToStruct(mapData map[string]any, modelValue interface{}) error {
 	val := reflect.ValueOf(modelValue)
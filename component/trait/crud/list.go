@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jcbowen/jcbaseGo"
 	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/orm/query"
 	"github.com/jcbowen/jcbaseGo/errcode"
 	"gorm.io/gorm"
 	"net/http"
@@ -32,14 +33,14 @@ func (t *Trait) ActionList(c *gin.Context) {
 	}
 
 	// 构建查询
-	query := t.MysqlMain.GetDb().Table(t.ModelTableName + tableAlias)
+	q := t.MysqlMain.GetDb().Table(t.ModelTableName + tableAlias)
 
 	if !showDeleted && helper.InArray("deleted_at", t.ModelFields) {
-		query = query.Where(t.TableAlias + "deleted_at IS NULL")
+		q = q.Where(t.TableAlias + "deleted_at IS NULL")
 	}
 
-	callResults := t.callCustomMethod("ListQuery", query)
-	query = callResults[0].(*gorm.DB)
+	callResults := t.callCustomMethod("ListQuery", q)
+	q = callResults[0].(*gorm.DB)
 	if callResults[1] != nil {
 		err := callResults[1].(error)
 		if err != nil {
@@ -49,18 +50,15 @@ func (t *Trait) ActionList(c *gin.Context) {
 	}
 
 	// 获取总数
-	total := int64(0)
-	err := query.
-		Model(reflect.New(reflect.TypeOf(t.Model).Elem()).Interface()).
-		Count(&total).Error
+	total, err := t.listTotal(q)
 	if err != nil {
 		t.Result(http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	// Select不能在Count前，否则会报错
-	// 为了方便，直接传query进去拼接就好
-	query = t.callCustomMethod("ListSelect", query)[0].(*gorm.DB)
+	// 为了方便，直接传q进去拼接就好
+	q = t.callCustomMethod("ListSelect", q)[0].(*gorm.DB)
 
 	// 动态创建模型实例
 	if t.ListResultStruct == nil {
@@ -82,7 +80,7 @@ func (t *Trait) ActionList(c *gin.Context) {
 	sliceType := reflect.SliceOf(resultStructType)
 	results := reflect.New(sliceType).Interface()
 
-	err = query.Order(t.callCustomMethod("ListOrder")[0]).
+	err = q.Order(t.callCustomMethod("ListOrder")[0]).
 		Offset((page - 1) * pageSize).
 		Limit(pageSize).
 		Find(results).Error
@@ -111,6 +109,22 @@ func (t *Trait) ActionList(c *gin.Context) {
 	})
 }
 
+// listTotal 按 ListTotal 的配置统计列表总数
+func (t *Trait) listTotal(db *gorm.DB) (int64, error) {
+	model := reflect.New(reflect.TypeOf(t.Model).Elem()).Interface()
+	if t.ListTotal.Mode == query.TotalExact {
+		total := int64(0)
+		err := db.Model(model).Count(&total).Error
+		return total, err
+	}
+	// 仅生成查询语句，再按配置不统计、估算或缓存总数
+	stmt := db.Session(&gorm.Session{DryRun: true}).Model(model).Find(model).Statement
+	if stmt.Error != nil {
+		return 0, stmt.Error
+	}
+	return query.Total(t.MysqlMain.GetDb(), stmt.SQL.String(), stmt.Vars, t.ListTotal)
+}
+
 func (t *Trait) ListSelect(query *gorm.DB) *gorm.DB {
 	// 默认就是查询*，所以这里就没必要单独写query.Select("*")了
 	return query
@@ -0,0 +1,117 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo/component/command"
+	"os"
+	"strings"
+	"time"
+)
+
+// Hook 升级前后执行的步骤，Command与Func二选一
+type Hook struct {
+	Name            string               // 步骤名称，用于日志
+	Command         string               // 外部命令，在仓库目录中执行（目录不存在时为当前目录）
+	Args            []string             // 命令参数
+	Env             []string             // 额外的环境变量，格式为 KEY=VALUE
+	Func            func(*Context) error // 自定义步骤，如执行数据库迁移
+	Timeout         time.Duration        // 超时时间，默认5分钟
+	ContinueOnError bool                 // 失败时是否继续执行后续步骤，默认中止
+	Always          bool                 // 版本未变化时是否也执行（仅对PostUpgrade有效）
+}
+
+// BuildHook 编译项目，output为输出文件，pkg为要编译的包，默认为当前目录
+func BuildHook(output string, pkg ...string) Hook {
+	args := []string{"build", "-o", output}
+	if len(pkg) > 0 {
+		args = append(args, pkg...)
+	} else {
+		args = append(args, ".")
+	}
+	return Hook{Name: "build", Command: "go", Args: args}
+}
+
+// SystemdRestartHook 通过systemctl重启服务
+func SystemdRestartHook(service string) Hook {
+	return Hook{Name: "restart " + service, Command: "systemctl", Args: []string{"restart", service}}
+}
+
+// SupervisorRestartHook 通过supervisorctl重启程序
+func SupervisorRestartHook(program string) Hook {
+	return Hook{Name: "restart " + program, Command: "supervisorctl", Args: []string{"restart", program}}
+}
+
+// runHooks 依次执行步骤，遇到失败的步骤时中止（ContinueOnError的除外），返回遇到的所有错误
+func (op *Context) runHooks(stage string, hooks []Hook, changed bool) error {
+	var errs []error
+	for i, hook := range hooks {
+		if stage == "post_upgrade" && !changed && !hook.Always {
+			continue
+		}
+		name := hook.Name
+		if name == "" {
+			name = strings.TrimSpace(hook.Command + " " + strings.Join(hook.Args, " "))
+		}
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		err := op.runHook(stage, name, hook)
+		if err == nil {
+			continue
+		}
+		errs = append(errs, err)
+		if !hook.ContinueOnError {
+			op.emit(Event{Step: stage, Level: LevelError, Message: "步骤 " + name + " 失败，中止后续步骤"})
+			break
+		}
+		op.emit(Event{Step: stage, Level: LevelWarn, Message: "步骤 " + name + " 失败，继续执行"})
+	}
+	return errors.Join(errs...)
+}
+
+// runHook 执行单个步骤
+func (op *Context) runHook(stage, name string, hook Hook) error {
+	if hook.Command == "" && hook.Func == nil {
+		return fmt.Errorf("upgrade: 步骤 %s 未设置 Command 或 Func", name)
+	}
+	commandLine := strings.TrimSpace(hook.Command + " " + strings.Join(hook.Args, " "))
+	if op.DryRun {
+		op.emit(Event{Step: stage, Message: "将执行步骤 " + name, Command: commandLine, DryRun: true})
+		return nil
+	}
+
+	op.emit(Event{Step: stage, Message: "开始执行步骤 " + name, Command: commandLine})
+	start := time.Now()
+	if hook.Func != nil {
+		if err := hook.Func(op); err != nil {
+			op.emit(Event{Step: stage, Level: LevelError, Message: "步骤 " + name + " 执行失败: " + err.Error()})
+			return fmt.Errorf("upgrade: 步骤 %s 执行失败: %w", name, err)
+		}
+		op.emit(Event{Step: stage, Message: fmt.Sprintf("步骤 %s 执行成功，耗时 %s", name, time.Since(start).Round(time.Millisecond))})
+		return nil
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	cmd := &command.Cmd{Name: hook.Command, Args: hook.Args, Env: hook.Env, Timeout: timeout}
+	// 首次部署时仓库目录可能还不存在
+	if info, err := os.Stat(op.Conf.Dir); err == nil && info.IsDir() {
+		cmd.Dir = op.Conf.Dir
+	}
+	res, err := cmd.Run(context.Background())
+	output := strings.TrimSpace(res.Output)
+	if res.TimedOut {
+		err = fmt.Errorf("超时(%s)", timeout)
+	}
+	if err != nil {
+		op.emit(Event{Step: stage, Level: LevelError, Message: "步骤 " + name + " 执行失败: " + err.Error(), Command: commandLine, Output: output})
+		return fmt.Errorf("upgrade: 步骤 %s 执行失败: %v: %s", name, err, output)
+	}
+	op.emit(Event{Step: stage, Message: fmt.Sprintf("步骤 %s 执行成功，耗时 %s", name, time.Since(start).Round(time.Millisecond)), Command: commandLine, Output: output})
+	return nil
+}
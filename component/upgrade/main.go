@@ -1,19 +1,58 @@
 package upgrade
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/jcbowen/jcbaseGo"
 	"github.com/jcbowen/jcbaseGo/component/command"
 	"github.com/jcbowen/jcbaseGo/component/helper"
-	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
+// 事件级别
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Event 升级过程中的进度事件
+type Event struct {
+	Step    string    `json:"step"`    // 步骤，如 fetch、checkout、reset
+	Level   string    `json:"level"`   // 级别 info/warn/error
+	Message string    `json:"message"` // 描述
+	Command string    `json:"command"` // 执行的命令
+	Output  string    `json:"output"`  // 命令输出
+	DryRun  bool      `json:"dry_run"` // 是否为演练（命令未实际执行）
+	Time    time.Time `json:"time"`
+}
+
+// Result 升级结果
+type Result struct {
+	From      string   `json:"from"`      // 升级前的版本（commit），新初始化的仓库为空
+	To        string   `json:"to"`        // 升级后的版本（演练时为目标版本）
+	Changes   []string `json:"changes"`   // 变更的文件（git diff --name-status 格式）
+	Changelog []Commit `json:"changelog"` // 两个版本之间的提交记录
+}
+
 type Context struct {
 	Type string // 类型: hard，default
 	Conf jcbaseGo.RepositoryStruct
+	Ref  string // 要部署的tag或commit，为空时部署配置分支的最新版本
+
+	DryRun     bool              // 演练模式，只拉取远程信息并列出将要执行的命令及变更的文件，不修改工作区
+	OnProgress func(event Event) // 进度回调
+	Events     chan<- Event      // 进度通道，与回调同时设置时都会收到事件，通道满时阻塞
+
+	PreUpgrade        []Hook // 升级前执行的步骤，失败时中止升级
+	PostUpgrade       []Hook // 升级（或回滚）后执行的步骤，如编译、数据库迁移、重启服务
+	RollbackOnFailure bool   // PostUpgrade失败时是否自动回滚到升级前的版本
+
+	Result *Result // 最近一次 Do 的结果
 }
 
 func New(option *Context) *Context {
@@ -33,184 +72,321 @@ func (op *Context) Default() *Context {
 	return op
 }
 
-func (op *Context) Do(callBack ...any) {
+// Do 执行升级，失败时返回错误，不会退出进程
+//
+// callBack 的最后一个参数如果是 func()，则在升级成功后执行
+//
+// 示例:
+//
+//	err := upgrade.New(&upgrade.Context{
+//	    Conf: conf.Repository,
+//	    OnProgress: func(e upgrade.Event) {
+//	        log.Println(e.Step, e.Message, e.Output)
+//	    },
+//	}).Do()
+//
+// 升级后编译并重启，失败时自动回滚:
+//
+//	op := upgrade.New(&upgrade.Context{
+//	    Conf: conf.Repository,
+//	    PostUpgrade: []upgrade.Hook{
+//	        upgrade.BuildHook("./bin/app"),
+//	        {Name: "migrate", Func: func(*upgrade.Context) error { return migrate(db) }},
+//	        upgrade.SystemdRestartHook("app"),
+//	    },
+//	    RollbackOnFailure: true,
+//	})
+func (op *Context) Do(callBack ...any) error {
+	op.Result = &Result{}
+	// 暴力模式会移除.git目录，需要提前读取部署记录
+	history := op.History()
+
+	if err := op.runHooks("pre_upgrade", op.PreUpgrade, true); err != nil {
+		op.emit(Event{Step: "done", Level: LevelError, Message: err.Error()})
+		return err
+	}
+
+	var err error
 	switch op.Type {
 	case "hard":
-		hardUpgrade(op.Conf)
+		err = op.hardUpgrade()
 	default:
-		defaultUpgrade(op.Conf)
+		err = op.defaultUpgrade()
+	}
+	if err != nil {
+		op.emit(Event{Step: "done", Level: LevelError, Message: err.Error()})
+		return err
+	}
+
+	if !op.DryRun && op.Result.To != "" && op.Result.To != op.Result.From {
+		history = append(history, Record{
+			From:   op.Result.From,
+			To:     op.Result.To,
+			Branch: op.Conf.Branch,
+			Ref:    op.Ref,
+			Time:   time.Now(),
+		})
+		if err = op.saveHistory(history); err != nil {
+			op.emit(Event{Step: "history", Level: LevelWarn, Message: "保存部署记录失败: " + err.Error()})
+		}
 	}
 
+	changed := op.Result.To != op.Result.From
+	if err = op.runHooks("post_upgrade", op.PostUpgrade, changed); err != nil {
+		if op.RollbackOnFailure && changed && !op.DryRun {
+			op.emit(Event{Step: "rollback", Level: LevelWarn, Message: "升级后步骤失败，开始回滚"})
+			result := op.Result
+			if rollbackErr := op.Rollback(); rollbackErr != nil {
+				err = errors.Join(err, rollbackErr)
+			}
+			op.Result = result
+		}
+		op.emit(Event{Step: "done", Level: LevelError, Message: err.Error()})
+		return err
+	}
+
+	op.emit(Event{Step: "done", Level: LevelInfo, Message: "同步成功"})
 	if len(callBack) > 0 {
 		// callBack的最后一个参数如果是函数，则执行
 		if f, ok := callBack[len(callBack)-1].(func()); ok {
 			f()
 		}
 	}
+	return nil
+}
 
-	os.Exit(0)
+// emit 发送进度事件
+func (op *Context) emit(event Event) {
+	event.Time = time.Now()
+	if event.Level == "" {
+		event.Level = LevelInfo
+	}
+	if op.OnProgress != nil {
+		op.OnProgress(event)
+	}
+	if op.Events != nil {
+		op.Events <- event
+	}
 }
 
-// 检查git目录是否配置/存在
-func checkGitDir(conf jcbaseGo.RepositoryStruct) bool {
-	// 判断是否有配置仓库
-	if conf.RemoteURL == "" {
-		err := errors.New("repository is empty")
-		log.Panic(err)
+// git 在仓库目录执行git命令，mutating为true的命令在演练模式下不会执行
+func (op *Context) git(step string, mutating bool, args ...string) (string, error) {
+	commandLine := "git " + strings.Join(args, " ")
+	if mutating && op.DryRun {
+		op.emit(Event{Step: step, Message: "将执行", Command: commandLine, DryRun: true})
+		return "", nil
 	}
 
-	command.CmdPath = conf.Dir
+	res, err := (&command.Cmd{Name: "git", Args: args, Dir: op.Conf.Dir}).Run(context.Background())
+	output := strings.TrimSpace(res.Output)
+	if err != nil || strings.Contains(output, "fatal:") {
+		if err == nil {
+			err = errors.New(output)
+		}
+		op.emit(Event{Step: step, Level: LevelError, Message: "执行失败", Command: commandLine, Output: output})
+		return output, fmt.Errorf("upgrade: %s 失败: %v: %s", commandLine, err, output)
+	}
+	op.emit(Event{Step: step, Message: "执行成功", Command: commandLine, Output: output})
+	return output, nil
+}
 
-	checkExists, err := helper.NewFile(&helper.File{Path: command.CmdPath}).DirExists(true)
+// revision 获取指定引用的commit
+func (op *Context) revision(ref string) string {
+	out, err := op.gitOutput("rev-parse", "--verify", "--quiet", ref+"^{commit}")
 	if err != nil {
-		fmt.Printf("checkCmdPath:\033[31m%s\033[0m\n", err)
-		os.Exit(1)
+		return ""
 	}
-	if !checkExists {
-		fmt.Printf("checkCmdPath:\033[31m%s\033[0m\n", "命令执行目录不存在，且无法创建")
-		os.Exit(1)
+	return strings.TrimSpace(out)
+}
+
+// gitOutput 执行只读的git命令，返回标准输出，不发送进度事件
+func (op *Context) gitOutput(args ...string) (string, error) {
+	res, err := (&command.Cmd{Name: "git", Args: args, Dir: op.Conf.Dir}).Run(context.Background())
+	return res.Stdout, err
+}
+
+// diff 列出两个版本之间变更的文件
+func (op *Context) diff(from, to string) []string {
+	if from == "" || to == "" || from == to {
+		return nil
 	}
+	out, err := op.gitOutput("diff", "--name-status", from, to)
+	if err != nil {
+		return nil
+	}
+	var changes []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line != "" {
+			changes = append(changes, line)
+		}
+	}
+	return changes
+}
 
-	// 检查是否存在.git目录
-	exist, _ := helper.NewFile(&helper.File{Path: command.CmdPath + ".git/"}).DirExists(false)
+// checkGitDir 检查git目录是否配置/存在
+func (op *Context) checkGitDir() (bool, error) {
+	// 判断是否有配置仓库
+	if op.Conf.RemoteURL == "" {
+		return false, errors.New("upgrade: repository is empty")
+	}
+	if op.Conf.RemoteName == "" {
+		op.Conf.RemoteName = "origin"
+	}
+	if op.Conf.Branch == "" {
+		op.Conf.Branch = "master"
+	}
 
+	// 目录必须以/结尾，否则会被当做文件处理
+	dir := strings.TrimSuffix(op.Conf.Dir, "/") + "/"
+	if _, err := os.Stat(dir); os.IsNotExist(err) && op.DryRun {
+		op.emit(Event{Step: "check", Message: "命令执行目录不存在，将会创建", DryRun: true})
+		return false, nil
+	}
+	if _, err := helper.NewFile(&helper.File{Path: dir}).DirExists(true); err != nil {
+		return false, fmt.Errorf("upgrade: 命令执行目录不存在，且无法创建: %v", err)
+	}
+
+	// 检查是否存在.git目录
+	info, err := os.Stat(filepath.Join(op.Conf.Dir, ".git"))
+	exist := err == nil && info.IsDir()
 	if exist {
-		log.Println("存在.git目录")
+		op.emit(Event{Step: "check", Message: "存在.git目录"})
 	} else {
-		log.Println("不存在.git目录")
+		op.emit(Event{Step: "check", Message: "不存在.git目录"})
 	}
-
-	return exist
+	return exist, nil
 }
 
-// hardUpgrade 暴力模式
-func hardUpgrade(conf jcbaseGo.RepositoryStruct) {
-	if checkGitDir(conf) {
-		fmt.Printf("\033[33m%s\033[0m\n", "存在.git目录，开始移除")
-		err := os.RemoveAll(command.CmdPath + ".git/")
-		if err != nil {
-			fmt.Printf("rmDir:\033[31m%s\033[0m\n", err)
-			os.Exit(1)
+// hardUpgrade 暴力模式：重新初始化仓库并强制检出远程分支
+func (op *Context) hardUpgrade() error {
+	exist, err := op.checkGitDir()
+	if err != nil {
+		return err
+	}
+	if exist {
+		op.Result.From = op.revision("HEAD")
+		if op.DryRun {
+			op.emit(Event{Step: "init", Level: LevelWarn, Message: "将移除.git目录", DryRun: true})
+		} else {
+			op.emit(Event{Step: "init", Level: LevelWarn, Message: "存在.git目录，开始移除"})
+			if err = os.RemoveAll(filepath.Join(op.Conf.Dir, ".git")); err != nil {
+				return fmt.Errorf("upgrade: 移除.git目录失败: %v", err)
+			}
 		}
-		fmt.Printf("\033[32m%s\033[0m\n", "移除成功")
-	}
-
-	result, _ := command.Run("git", "init")
-	fmt.Printf("初始化本地仓库：\n%s\n", result)
-	if strings.Compare(result[:36], "Initialized empty Git repository in") == 0 {
-		fmt.Printf("\033[31m%s\033[0m\n", "初始化本地仓库失败")
-		fmt.Println(result)
-		os.Exit(1)
-	}
-	_, _ = command.Run("touch", "README-TEST.md")
-	_, _ = command.Run("git", "add", "README-TEST.md")
-	result, _ = command.Run("git", "commit", "-m", "test commit")
-	fmt.Printf("提交：\n%s\n", result)
-	_, _ = command.Run("git", "remote", "add", conf.RemoteName, conf.RemoteURL)
-	result, _ = command.Run("git", "fetch", conf.RemoteName)
-	if strings.Contains(result, "fatal:") {
-		fmt.Printf("\033[31m%s\033[0m\n", "拉取远程仓库失败")
-		fmt.Println(result)
-		os.Exit(1)
-	}
-	fmt.Printf("拉取远程仓库：\n%s\n", result)
-	result, _ = command.Run("git", "reset", "--hard", conf.RemoteName+"/"+conf.Branch)
-	fmt.Printf("重置到远程仓库最新版本：\n%s\n", result)
-	if strings.Contains(result, "fatal:") {
-		fmt.Printf("\033[31m%s\033[0m\n", "重置到远程仓库最新版本失败")
-		fmt.Println(result)
-		os.Exit(1)
-	}
-	if conf.Branch != "master" {
-		// 根据远程分支在本地创建一个同名分支，并切换到该分支
-		result, _ = command.Run("git", "checkout", "-t", "remotes/"+conf.RemoteName+"/"+conf.Branch)
-		if strings.Contains(result, "fatal:") {
-			fmt.Printf("\033[31m%s\033[0m\n", "切换分支失败")
-			fmt.Println(result)
-			os.Exit(1)
+	}
+
+	remoteBranch := op.Conf.RemoteName + "/" + op.Conf.Branch
+	if op.DryRun {
+		checkout := []string{"checkout", "-f", "-B", op.Conf.Branch, remoteBranch}
+		if op.Ref != "" {
+			checkout = []string{"checkout", "-f", "--detach", op.Ref}
 		}
-		fmt.Println(result)
-		// 删除master分支
-		result, _ = command.Run("git", "branch", "-D", "master")
-		if strings.Contains(result, "fatal:") {
-			fmt.Printf("\033[31m%s\033[0m\n", "删除master分支失败")
-			fmt.Println(result)
-			os.Exit(1)
+		for _, args := range [][]string{
+			{"init"},
+			{"remote", "add", op.Conf.RemoteName, op.Conf.RemoteURL},
+			{"fetch", "--tags", op.Conf.RemoteName},
+			checkout,
+		} {
+			_, _ = op.git(args[0], true, args...)
 		}
+		return nil
 	}
 
-	// 输出绿色
-	fmt.Printf("\033[32m%s\033[0m\n", "同步成功")
+	if _, err = op.git("init", true, "init"); err != nil {
+		return err
+	}
+	if _, err = op.git("remote", true, "remote", "add", op.Conf.RemoteName, op.Conf.RemoteURL); err != nil {
+		return err
+	}
+	if _, err = op.git("fetch", true, "fetch", "--tags", op.Conf.RemoteName); err != nil {
+		return err
+	}
+	target, err := op.target()
+	if err != nil {
+		return err
+	}
+	if op.Ref != "" {
+		_, err = op.git("checkout", true, "checkout", "-f", "--detach", target)
+	} else {
+		// 根据远程分支在本地创建（或重置）同名分支，并强制检出
+		if _, err = op.git("checkout", true, "checkout", "-f", "-B", op.Conf.Branch, remoteBranch); err == nil {
+			_, _ = op.git("checkout", true, "branch", "--set-upstream-to="+remoteBranch, op.Conf.Branch)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	op.Result.To = target
+	// 旧版本的提交对象已随.git目录移除，只能在远程仍包含旧版本时计算变更
+	op.Result.Changes = op.diff(op.Result.From, target)
+	if op.revision(op.Result.From) != "" {
+		op.Result.Changelog, _ = op.Changelog(op.Result.From, target)
+	}
+	op.emitChanges()
+	return nil
 }
 
-// defaultUpgrade 默认模式
-func defaultUpgrade(conf jcbaseGo.RepositoryStruct) {
+// defaultUpgrade 默认模式：拉取远程仓库，切换到配置的分支并重置到远程最新版本，指定了Ref时检出Ref
+func (op *Context) defaultUpgrade() error {
+	exist, err := op.checkGitDir()
+	if err != nil {
+		return err
+	}
 	// git目录不存在就执行hard模式
-	if !checkGitDir(conf) {
-		fmt.Printf("\033[33m%s\033[0m\n", "不存在.git目录，开始初始化")
-		hardUpgrade(conf)
-		return
-	}
-
-	result, _ := command.Run("git", "fetch", "--all")
-	if strings.Contains(result, "fatal:") {
-		fmt.Printf("\033[31m%s\033[0m\n", "拉取远程仓库失败")
-		fmt.Println(result)
-		os.Exit(1)
-	}
-	fmt.Printf("拉取远程仓库：\n%s\n", result)
-
-	// 获取本地分支
-	result, _ = command.Run("git", "branch")
-	if strings.Contains(result, "fatal:") {
-		fmt.Printf("\033[31m%s\033[0m\n", "获取本地分支失败")
-		fmt.Println(result)
-		os.Exit(1)
-	}
-	fmt.Printf("获取本地分支：\n%s\n", result)
-
-	// 根据git branch返回信息，将本地分支存入数组中(过滤掉空元素、*号)
-	branchArr := strings.Split(result, "\n")
-	branchArr = branchArr[:len(branchArr)-1]
-	currentBranch := ""
-	for i, v := range branchArr {
-		isCurrent := helper.StringStartWith(v, "*")
-		branchArr[i] = strings.Replace(v, "*", "", -1)
-		branchArr[i] = strings.Replace(branchArr[i], " ", "", -1)
-		if isCurrent {
-			currentBranch = branchArr[i]
+	if !exist {
+		op.emit(Event{Step: "init", Level: LevelWarn, Message: "不存在.git目录，开始初始化"})
+		return op.hardUpgrade()
+	}
+
+	op.Result.From = op.revision("HEAD")
+
+	// fetch只更新远程引用，不修改工作区，演练模式下同样执行以便计算变更
+	if _, err = op.git("fetch", false, "fetch", "--all", "--tags"); err != nil {
+		return err
+	}
+
+	target, err := op.target()
+	if err != nil {
+		return err
+	}
+	op.Result.To = target
+	op.Result.Changes = op.diff(op.Result.From, target)
+	op.Result.Changelog, _ = op.Changelog(op.Result.From, target)
+
+	if op.Ref != "" {
+		if _, err = op.git("checkout", true, "checkout", "-f", "--detach", target); err != nil {
+			return err
 		}
+		op.emitChanges()
+		return nil
+	}
+
+	remoteBranch := op.Conf.RemoteName + "/" + op.Conf.Branch
+	currentBranch, err := op.git("branch", false, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
 	}
-	fmt.Println("当前分支：`", currentBranch, "`")
+	op.emit(Event{Step: "branch", Message: "当前分支: " + currentBranch})
 
 	// 如果当前分支不是配置的分支，就切换到配置的分支
-	if currentBranch != conf.Branch {
-		fmt.Println("当前分支不是配置的分支，开始切换到配置的分支")
-		if helper.InArray(conf.Branch, branchArr) {
-			// 如果远程分支存在本地分支，切换到该分支
-			result, _ = command.Run("git", "checkout", conf.Branch)
-			if strings.Contains(result, "fatal:") {
-				fmt.Printf("\033[31m%s\033[0m\n", "切换本地分支失败")
-				fmt.Println(result)
-				os.Exit(1)
-			}
+	if currentBranch != op.Conf.Branch {
+		op.emit(Event{Step: "checkout", Message: "当前分支不是配置的分支，切换到 " + op.Conf.Branch})
+		if op.revision("refs/heads/"+op.Conf.Branch) != "" {
+			_, err = op.git("checkout", true, "checkout", op.Conf.Branch)
 		} else {
 			// 根据远程分支在本地创建一个同名分支，并切换到该分支
-			result, _ = command.Run("git", "checkout", "-t", "remotes/"+conf.RemoteName+"/"+conf.Branch)
-			if strings.Contains(result, "fatal:") {
-				fmt.Printf("\033[31m%s\033[0m\n", "切换远程分支失败")
-				fmt.Println(result)
-				os.Exit(1)
-			}
+			_, err = op.git("checkout", true, "checkout", "-t", "remotes/"+remoteBranch)
+		}
+		if err != nil {
+			return err
 		}
-		fmt.Println(result)
 	}
 
-	result, _ = command.Run("git", "reset", "--hard", conf.RemoteName+"/"+conf.Branch)
-	fmt.Printf("重置到远程仓库最新版本：\n%s\n", result)
-	if strings.Contains(result, "fatal:") {
-		fmt.Printf("\033[31m%s\033[0m\n", "重置到远程仓库最新版本失败")
-		fmt.Println(result)
-		os.Exit(1)
+	if _, err = op.git("reset", true, "reset", "--hard", target); err != nil {
+		return err
 	}
+
+	op.emitChanges()
+	return nil
 }
@@ -0,0 +1,191 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// historyFile 部署记录文件，存放在.git目录中，不会出现在工作区
+const historyFile = "jcbase_upgrade.json"
+
+// maxHistory 最多保留的部署记录数
+const maxHistory = 20
+
+// ErrNoHistory 没有可回滚的部署记录
+var ErrNoHistory = errors.New("upgrade: 没有可回滚的部署记录")
+
+// Record 部署记录
+type Record struct {
+	From   string    `json:"from"`   // 部署前的版本
+	To     string    `json:"to"`     // 部署后的版本
+	Branch string    `json:"branch"` // 部署时配置的分支
+	Ref    string    `json:"ref"`    // 部署时指定的tag/commit
+	Time   time.Time `json:"time"`
+}
+
+// Commit 变更日志中的一次提交
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"time"`
+	Subject string    `json:"subject"`
+}
+
+// History 获取部署记录，最近的在最后
+func (op *Context) History() []Record {
+	content, err := os.ReadFile(filepath.Join(op.Conf.Dir, ".git", historyFile))
+	if err != nil {
+		return nil
+	}
+	var history []Record
+	_ = json.Unmarshal(content, &history)
+	return history
+}
+
+// saveHistory 保存部署记录
+func (op *Context) saveHistory(history []Record) error {
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	content, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(op.Conf.Dir, ".git", historyFile), content, 0644)
+}
+
+// Rollback 回滚到上一次部署前的版本，并移除该部署记录
+//
+// 回滚后处于分离头指针状态，下次执行 Do 时会重新切换到配置的分支或 Ref；
+// 回滚后同样会执行 PostUpgrade 中的步骤，失败时返回错误，不会再次回滚
+func (op *Context) Rollback() error {
+	op.Result = &Result{}
+	history := op.History()
+	if len(history) == 0 || history[len(history)-1].From == "" {
+		op.emit(Event{Step: "rollback", Level: LevelError, Message: ErrNoHistory.Error()})
+		return ErrNoHistory
+	}
+	record := history[len(history)-1]
+
+	op.Result.From = op.revision("HEAD")
+	op.Result.To = record.From
+	if op.Result.From != record.To {
+		op.emit(Event{Step: "rollback", Level: LevelWarn, Message: "当前版本与最近一次部署的版本不一致: " + record.To})
+	}
+
+	// 目标版本不在本地时（如暴力模式重建了仓库）从远程拉取
+	if op.revision(record.From) == "" {
+		if _, err := op.git("fetch", false, "fetch", "--all", "--tags"); err != nil {
+			return err
+		}
+		if op.revision(record.From) == "" {
+			err := fmt.Errorf("upgrade: 版本 %s 不存在，无法回滚", record.From)
+			op.emit(Event{Step: "rollback", Level: LevelError, Message: err.Error()})
+			return err
+		}
+	}
+
+	op.Result.Changes = op.diff(op.Result.From, record.From)
+	// 回滚时列出将被撤销的提交
+	op.Result.Changelog, _ = op.Changelog(record.From, op.Result.From)
+	op.emitChanges()
+
+	if _, err := op.git("rollback", true, "checkout", "-f", "--detach", record.From); err != nil {
+		return err
+	}
+	if op.DryRun {
+		return nil
+	}
+	if err := op.saveHistory(history[:len(history)-1]); err != nil {
+		return fmt.Errorf("upgrade: 保存部署记录失败: %v", err)
+	}
+	if err := op.runHooks("post_upgrade", op.PostUpgrade, true); err != nil {
+		return err
+	}
+	op.emit(Event{Step: "done", Message: "回滚成功"})
+	return nil
+}
+
+// Changelog 获取两个版本之间的提交记录（from不包含，to包含），最新的在前
+func (op *Context) Changelog(from, to string) ([]Commit, error) {
+	if to == "" || from == to {
+		return nil, nil
+	}
+	rangeArg := to
+	if from != "" {
+		rangeArg = from + ".." + to
+	}
+	out, err := op.gitOutput("log", "--pretty=format:%H%x1f%an%x1f%aI%x1f%s", rangeArg)
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: 获取变更日志失败: %v", err)
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, Commit{Hash: fields[0], Author: fields[1], Time: t, Subject: fields[3]})
+	}
+	return commits, nil
+}
+
+// Tags 获取本地已有的tag，按版本号从新到旧排序，需要最新的远程tag时先执行一次 Do 或 DryRun
+func (op *Context) Tags() ([]string, error) {
+	out, err := op.gitOutput("tag", "--list", "--sort=-v:refname")
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: 获取tag失败: %v", err)
+	}
+	var tags []string
+	for _, tag := range strings.Split(strings.TrimSpace(out), "\n") {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// target 解析要部署的版本，指定了Ref时优先使用Ref（tag、commit或远程分支）
+func (op *Context) target() (string, error) {
+	if op.Ref == "" {
+		remoteBranch := op.Conf.RemoteName + "/" + op.Conf.Branch
+		if rev := op.revision(remoteBranch); rev != "" {
+			return rev, nil
+		}
+		return "", fmt.Errorf("upgrade: 远程分支 %s 不存在", remoteBranch)
+	}
+	for _, ref := range []string{"refs/tags/" + op.Ref, op.Conf.RemoteName + "/" + op.Ref, op.Ref} {
+		if rev := op.revision(ref); rev != "" {
+			return rev, nil
+		}
+	}
+	return "", fmt.Errorf("upgrade: 版本 %s 不存在", op.Ref)
+}
+
+// emitChanges 发送变更文件及变更日志事件
+func (op *Context) emitChanges() {
+	if op.Result.From == "" {
+		op.emit(Event{Step: "changes", Message: "首次部署版本 " + op.Result.To, DryRun: op.DryRun})
+		return
+	}
+	if len(op.Result.Changes) == 0 {
+		op.emit(Event{Step: "changes", Message: "已是最新版本", DryRun: op.DryRun})
+		return
+	}
+	op.emit(Event{Step: "changes", Message: fmt.Sprintf("%d 个文件变更", len(op.Result.Changes)), Output: strings.Join(op.Result.Changes, "\n"), DryRun: op.DryRun})
+
+	lines := make([]string, 0, len(op.Result.Changelog))
+	for _, commit := range op.Result.Changelog {
+		lines = append(lines, commit.Hash[:7]+" "+commit.Subject)
+	}
+	if len(lines) > 0 {
+		op.emit(Event{Step: "changelog", Message: fmt.Sprintf("%d 个提交", len(lines)), Output: strings.Join(lines, "\n"), DryRun: op.DryRun})
+	}
+}
@@ -0,0 +1,214 @@
+package validator
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = RegisterValidation(v)
+	}
+}
+
+// RegisterValidation 注册 bankcard、plate、uscc、passport 校验规则，gin 默认的校验器已自动注册；
+// 零值同样会被校验，允许为空时配合 omitempty 使用
+//
+// 示例:
+//
+//	type KYCForm struct {
+//		BankCard string `json:"bank_card" binding:"required,bankcard"`
+//		Plate    string `json:"plate" binding:"omitempty,plate"`
+//		USCC     string `json:"uscc" binding:"required,uscc"`
+//		Passport string `json:"passport" binding:"omitempty,passport"`
+//	}
+func RegisterValidation(v *validator.Validate) error {
+	rules := map[string]func(string) bool{
+		"bankcard": IsBankCard,
+		"plate":    IsChinesePlateNumber,
+		"uscc":     IsUSCC,
+		"passport": IsPassport,
+	}
+	for tag, fn := range rules {
+		err := v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return fn(fl.Field().String())
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ----- 银行卡 ----- /
+
+// 卡组织
+const (
+	CardUnionPay   = "unionpay"
+	CardVisa       = "visa"
+	CardMasterCard = "mastercard"
+	CardAmex       = "amex"
+	CardJCB        = "jcb"
+	CardDiscover   = "discover"
+)
+
+// BankCardBrand 根据卡号前缀（BIN）判断卡组织，无法识别时返回空字符串；卡号中的空格、横线会被忽略
+func BankCardBrand(card string) string {
+	card = normalizeCard(card)
+	prefix := func(n int) int {
+		if len(card) < n {
+			return -1
+		}
+		v := 0
+		for _, c := range card[:n] {
+			v = v*10 + int(c-'0')
+		}
+		return v
+	}
+	switch {
+	case prefix(4) == 6011 || prefix(2) == 65 || (prefix(3) >= 644 && prefix(3) <= 649):
+		return CardDiscover
+	case prefix(2) == 62 || prefix(2) == 81 || prefix(2) == 60 || prefix(2) == 95:
+		// 62、81 为银联号段，60、95 为国内早期发行的借记卡（如 6013 中国银行、9558 工商银行）
+		return CardUnionPay
+	case prefix(1) == 4:
+		return CardVisa
+	case (prefix(2) >= 51 && prefix(2) <= 55) || (prefix(4) >= 2221 && prefix(4) <= 2720):
+		return CardMasterCard
+	case prefix(2) == 34 || prefix(2) == 37:
+		return CardAmex
+	case prefix(4) >= 3528 && prefix(4) <= 3589:
+		return CardJCB
+	default:
+		return ""
+	}
+}
+
+// IsBankCard 检查是否为有效的银行卡号：13-19位数字、可识别的卡组织号段且通过 Luhn 校验；
+// 卡号中的空格、横线会被忽略
+func IsBankCard(card string) bool {
+	card = normalizeCard(card)
+	if len(card) < 13 || len(card) > 19 {
+		return false
+	}
+	for _, c := range card {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	brand := BankCardBrand(card)
+	switch {
+	case brand == "":
+		return false
+	case brand == CardAmex && len(card) != 15:
+		return false
+	case brand == CardUnionPay && len(card) < 16:
+		return false
+	}
+	return Luhn(card)
+}
+
+// Luhn 检查数字串是否通过 Luhn（模10）校验
+func Luhn(number string) bool {
+	if number == "" {
+		return false
+	}
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func normalizeCard(card string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(card)
+}
+
+// ----- 车牌号 ----- /
+
+var (
+	// platePattern 普通车牌：省份简称 + 发牌机关代号 + 5位序号，末位可为 挂、学、警、港、澳、领
+	platePattern = regexp.MustCompile(`^[京津沪渝冀豫云辽黑湘皖鲁新苏浙赣鄂桂甘晋蒙陕吉闽贵粤青藏川宁琼][A-HJ-NP-Z][A-HJ-NP-Z0-9]{4}[A-HJ-NP-Z0-9挂学警港澳领]$`)
+	// newEnergyPattern 新能源车牌：小型车序号首位为 D/A/B/C/E（纯电动）或 F/G/H/J/K（非纯电动），大型车末位为 D/F
+	newEnergyPattern = regexp.MustCompile(`^[京津沪渝冀豫云辽黑湘皖鲁新苏浙赣鄂桂甘晋蒙陕吉闽贵粤青藏川宁琼][A-HJ-NP-Z](?:[A-HJ-K][A-HJ-NP-Z0-9][0-9]{4}|[0-9]{5}[DF])$`)
+	// emergencyPattern 应急救援车牌
+	emergencyPattern = regexp.MustCompile(`^[京津沪渝冀豫云辽黑湘皖鲁新苏浙赣鄂桂甘晋蒙陕吉闽贵粤青藏川宁琼][A-HJ-NP-Z][A-HJ-NP-Z0-9]{4}应急$`)
+)
+
+// IsChinesePlateNumber 检查是否为有效的中国大陆机动车号牌（普通、新能源、挂车、教练、警用、港澳入境及应急车牌），字母需大写
+func IsChinesePlateNumber(plate string) bool {
+	return platePattern.MatchString(plate) || newEnergyPattern.MatchString(plate) || emergencyPattern.MatchString(plate)
+}
+
+// IsNewEnergyPlate 检查是否为新能源车牌
+func IsNewEnergyPlate(plate string) bool {
+	return newEnergyPattern.MatchString(plate)
+}
+
+// ----- 统一社会信用代码 ----- /
+
+// usccChars 统一社会信用代码使用的字符（不含 I、O、Z、S、V），下标即字符的值
+const usccChars = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+
+var usccWeights = [17]int{1, 3, 9, 27, 19, 26, 16, 17, 20, 29, 25, 13, 8, 24, 10, 30, 28}
+
+// IsUSCC 检查是否为有效的统一社会信用代码（GB 32100-2015）：18位，含登记管理部门、机构类别、
+// 行政区划码格式及末位校验码
+func IsUSCC(code string) bool {
+	code = strings.ToUpper(code)
+	if len(code) != 18 {
+		return false
+	}
+	// 第1位登记管理部门代码，第2位机构类别代码，第3-8位行政区划码
+	if !strings.ContainsRune("123456789ANY", rune(code[0])) || !strings.ContainsRune("123456789", rune(code[1])) {
+		return false
+	}
+	for _, c := range code[2:8] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	sum := 0
+	for i := 0; i < 17; i++ {
+		v := strings.IndexByte(usccChars, code[i])
+		if v < 0 {
+			return false
+		}
+		sum += v * usccWeights[i]
+	}
+	check := (31 - sum%31) % 31
+	return code[17] == usccChars[check]
+}
+
+// ----- 护照 ----- /
+
+var (
+	// chinesePassportPattern 中国护照：普通护照 E+8位数字、E+字母+7位数字（E开头号段用完后启用）、
+	// 旧版 G+8位数字，外交、公务、公务普通护照 DE/SE/PE+7位数字
+	chinesePassportPattern = regexp.MustCompile(`^(?:[EG][0-9]{8}|E[A-HJ-NP-Z][0-9]{7}|[DSP]E[0-9]{7})$`)
+	// passportPattern 通用护照号码：5-9位大写字母或数字（ICAO 9303 机读区最长9位）
+	passportPattern = regexp.MustCompile(`^[A-Z0-9]{5,9}$`)
+)
+
+// IsPassport 检查是否为格式有效的护照号码（各国通用规则：5-9位大写字母或数字），中国护照可使用 IsChinesePassport 严格校验
+func IsPassport(passport string) bool {
+	return passportPattern.MatchString(passport)
+}
+
+// IsChinesePassport 检查是否为有效的中国护照号码
+func IsChinesePassport(passport string) bool {
+	return chinesePassportPattern.MatchString(passport)
+}
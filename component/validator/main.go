@@ -154,3 +154,102 @@ func isValidChecksum(idCard string) bool {
 	mod := sum % 11
 	return idCard[17] == checksum[mod]
 }
+
+// 密码强度等级
+const (
+	PasswordVeryWeak = iota
+	PasswordWeak
+	PasswordMedium
+	PasswordStrong
+	PasswordVeryStrong
+)
+
+// commonPasswords 常见弱密码
+var commonPasswords = map[string]struct{}{
+	"123456": {}, "12345678": {}, "123456789": {}, "1234567890": {}, "111111": {}, "000000": {},
+	"password": {}, "passw0rd": {}, "qwerty": {}, "qwerty123": {}, "abc123": {}, "admin": {},
+	"admin123": {}, "iloveyou": {}, "woaini": {}, "5201314": {}, "123123": {}, "a123456": {},
+}
+
+// PasswordStrength 计算密码强度，返回 PasswordVeryWeak(0) 到 PasswordVeryStrong(4)
+//
+// 根据长度、字符种类（小写、大写、数字、符号）评分，常见弱密码、单一重复字符及连续字符会被降级
+func PasswordStrength(password string) int {
+	if password == "" {
+		return PasswordVeryWeak
+	}
+	if _, ok := commonPasswords[strings.ToLower(password)]; ok {
+		return PasswordVeryWeak
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	kinds := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			kinds++
+		}
+	}
+
+	length := len([]rune(password))
+	score := 0
+	switch {
+	case length >= 16:
+		score = 3
+	case length >= 12:
+		score = 2
+	case length >= 8:
+		score = 1
+	}
+	score += kinds - 1
+	if length < 6 {
+		score = 0
+	}
+	if isMonotonous(password) {
+		score -= 2
+	}
+
+	if score < PasswordVeryWeak {
+		return PasswordVeryWeak
+	}
+	if score > PasswordVeryStrong {
+		return PasswordVeryStrong
+	}
+	return score
+}
+
+// IsStrongPassword 检查密码强度是否达到指定等级，默认要求 PasswordMedium
+func IsStrongPassword(password string, minStrength ...int) bool {
+	min := PasswordMedium
+	if len(minStrength) > 0 {
+		min = minStrength[0]
+	}
+	return PasswordStrength(password) >= min
+}
+
+// isMonotonous 判断是否由同一字符重复或连续递增/递减字符组成
+func isMonotonous(s string) bool {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return true
+	}
+	same, asc, desc := true, true, true
+	for i := 1; i < len(runes); i++ {
+		diff := runes[i] - runes[i-1]
+		same = same && diff == 0
+		asc = asc && diff == 1
+		desc = desc && diff == -1
+	}
+	return same || asc || desc
+}
@@ -0,0 +1,264 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 请求头
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// 签名校验错误
+var (
+	ErrSignatureMissing = errors.New("webhook: 缺少签名")
+	ErrSignatureInvalid = errors.New("webhook: 签名错误")
+	ErrSignatureExpired = errors.New("webhook: 签名已过期")
+)
+
+// Sign 计算签名：hex(HMAC-SHA256(secret, timestamp + "." + body))，请求头中的格式为 sha256=<签名>
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 接收方校验签名，tolerance 为允许的时间误差，0表示不校验时间
+//
+// 示例:
+//
+//	body, _ := io.ReadAll(c.Request.Body)
+//	if err := webhook.Verify(secret, c.Request.Header, body, 5*time.Minute); err != nil {
+//		c.AbortWithStatus(http.StatusUnauthorized)
+//		return
+//	}
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	signature, ok := strings.CutPrefix(header.Get(HeaderSignature), "sha256=")
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if !ok || signature == "" || err != nil {
+		return ErrSignatureMissing
+	}
+	if tolerance > 0 {
+		if diff := time.Since(time.Unix(timestamp, 0)); diff > tolerance || diff < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// ----- 后台投递 ----- /
+
+// Start 启动后台投递，重复调用无效
+func (d *Dispatcher) Start() {
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+	if d.running {
+		return
+	}
+	d.running = true
+	d.stop = make(chan struct{})
+
+	jobs := make(chan Delivery)
+	for i := 0; i < max(d.opt.Workers, 1); i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for delivery := range jobs {
+				d.deliver(delivery)
+				d.inflight.Delete(delivery.ID)
+			}
+		}()
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer close(jobs)
+		ticker := time.NewTicker(d.opt.PollInterval)
+		defer ticker.Stop()
+		for {
+			d.poll(jobs)
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Stop 停止后台投递，等待进行中的请求完成
+func (d *Dispatcher) Stop() {
+	d.runMu.Lock()
+	if !d.running {
+		d.runMu.Unlock()
+		return
+	}
+	d.running = false
+	close(d.stop)
+	d.runMu.Unlock()
+	d.wg.Wait()
+}
+
+// poll 取出到期的记录，抢占成功后交给投递协程
+func (d *Dispatcher) poll(jobs chan<- Delivery) {
+	ctx := context.Background()
+	now := time.Now()
+	list := make([]Delivery, 0)
+	err := d.deliveries(ctx).
+		Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+		Order("next_attempt_at").Limit(d.opt.BatchSize).
+		Find(&list).Error
+	if err != nil {
+		log.Println("webhook: 查询待投递记录失败:", err)
+		return
+	}
+
+	for _, delivery := range list {
+		if _, loaded := d.inflight.LoadOrStore(delivery.ID, true); loaded {
+			continue
+		}
+		// 将下次尝试时间延后作为租约，多实例部署时只有一个实例能抢占成功
+		res := d.deliveries(ctx).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.ID, DeliveryPending, now).
+			Update("next_attempt_at", now.Add(2*d.opt.Timeout+time.Minute))
+		if res.Error != nil || res.RowsAffected == 0 {
+			d.inflight.Delete(delivery.ID)
+			continue
+		}
+		select {
+		case jobs <- delivery:
+		case <-d.stop:
+			d.inflight.Delete(delivery.ID)
+			return
+		}
+	}
+}
+
+// deliver 投递一次并记录结果
+func (d *Dispatcher) deliver(delivery Delivery) {
+	ctx := context.Background()
+	attempt := Attempt{DeliveryID: delivery.ID}
+
+	var endpoint Endpoint
+	err := d.endpoints(ctx).Where("id = ?", delivery.EndpointID).Take(&endpoint).Error
+	if err == nil && endpoint.Status != StatusEnabled {
+		err = errors.New("订阅地址已禁用")
+	}
+	if err != nil {
+		attempt.Error = "订阅地址不可用: " + err.Error()
+		d.finish(ctx, delivery, attempt, true)
+		return
+	}
+
+	attempt.URL = endpoint.URL
+	start := time.Now()
+	attempt.StatusCode, attempt.Response, err = d.send(ctx, endpoint, delivery)
+	attempt.Duration = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = truncate(err.Error(), 500)
+	} else if attempt.StatusCode < 200 || attempt.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("HTTP %d", attempt.StatusCode)
+	}
+	d.finish(ctx, delivery, attempt, false)
+}
+
+// send 发送请求，返回状态码及截断的响应内容
+func (d *Dispatcher) send(ctx context.Context, endpoint Endpoint, delivery Delivery) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.opt.Timeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "jcbaseGo-Webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(endpoint.Secret, timestamp, body))
+	}
+
+	resp, err := d.opt.Client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+	return resp.StatusCode, string(respBody), nil
+}
+
+// finish 保存尝试记录并更新投递状态，dead 为 true 时直接标记为死信
+func (d *Dispatcher) finish(ctx context.Context, delivery Delivery, attempt Attempt, dead bool) {
+	if err := d.attempts(ctx).Create(&attempt).Error; err != nil {
+		log.Println("webhook: 保存投递尝试失败:", err)
+	}
+
+	attempts := delivery.Attempts + 1
+	updates := map[string]any{
+		"attempts":   attempts,
+		"last_code":  attempt.StatusCode,
+		"last_error": attempt.Error,
+		"updated_at": time.Now(),
+	}
+	switch {
+	case attempt.Error == "":
+		updates["status"] = DeliverySuccess
+	case dead || attempts >= d.opt.MaxAttempts:
+		updates["status"] = DeliveryDead
+	default:
+		updates["next_attempt_at"] = time.Now().Add(d.backoff(attempts))
+	}
+	if err := d.deliveries(ctx).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		log.Println("webhook: 更新投递记录失败:", err)
+	}
+}
+
+// backoff 第n次失败后的重试间隔，RetryBase × 2^(n-1)，不超过 RetryMax
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.opt.RetryBase
+	for i := 1; i < attempts && wait < d.opt.RetryMax; i++ {
+		wait *= 2
+	}
+	return min(wait, d.opt.RetryMax)
+}
+
+// truncate 按字节截断，不截断多字节字符
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	end := 0
+	for i := range s {
+		if i > n {
+			break
+		}
+		end = i
+	}
+	return s[:end]
+}
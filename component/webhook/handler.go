@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/i18n"
+	"github.com/jcbowen/jcbaseGo/component/trait/controller"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"net/url"
+)
+
+// Handler Webhook 管理接口，需自行在路由分组上添加登录、权限等中间件
+type Handler struct {
+	dispatcher *Dispatcher
+}
+
+// Handler 获取管理接口
+//
+// 示例:
+//
+//	admin := r.Group("/admin/webhook", middleware.LoginRequired())
+//	wh.Handler().RegisterRoutes(admin)
+func (d *Dispatcher) Handler() *Handler {
+	return &Handler{dispatcher: d}
+}
+
+// RegisterRoutes 注册路由：
+//   - GET  /endpoints        全部订阅地址
+//   - POST /endpoint/save    新建或修改订阅地址（传入id时为修改，secret 为空时保留原密钥）
+//   - POST /endpoint/delete  删除订阅地址，参数 ids
+//   - GET  /deliveries       投递记录分页列表，参数 endpoint_id、event、status、page、page_size
+//   - GET  /attempts         投递记录的全部尝试，参数 delivery_id
+//   - POST /redeliver        重新投递，参数 ids
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/endpoints", h.Endpoints)
+	r.POST("/endpoint/save", h.SaveEndpoint)
+	r.POST("/endpoint/delete", h.DeleteEndpoint)
+	r.GET("/deliveries", h.Deliveries)
+	r.GET("/attempts", h.Attempts)
+	r.POST("/redeliver", h.Redeliver)
+}
+
+// Endpoints 全部订阅地址，不返回密钥
+func (h *Handler) Endpoints(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	list, err := h.dispatcher.Endpoints(c.Request.Context())
+	if err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Success(list)
+}
+
+// SaveEndpoint 新建或修改订阅地址
+func (h *Handler) SaveEndpoint(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		ID     uint   `json:"id" form:"id"`
+		Name   string `json:"name" form:"name" binding:"max=64"`
+		URL    string `json:"url" form:"url" binding:"required,url,max=500"`
+		Secret string `json:"secret" form:"secret" binding:"max=128"`
+		Events string `json:"events" form:"events" binding:"max=1000"`
+		Status *int   `json:"status" form:"status"` // 未传入时为启用
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if u, err := url.Parse(form.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		base.Failure("推送地址只支持http、https", nil, errcode.ParamError)
+		return
+	}
+	endpoint := Endpoint{
+		ID:     form.ID,
+		Name:   form.Name,
+		URL:    form.URL,
+		Secret: form.Secret,
+		Events: form.Events,
+		Status: StatusEnabled,
+	}
+	if form.Status != nil && *form.Status == StatusDisabled {
+		endpoint.Status = StatusDisabled
+	}
+	if _, err := h.dispatcher.SaveEndpoint(c.Request.Context(), &endpoint); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			base.Failure("订阅地址不存在或已被删除", nil, errcode.NotExist)
+			return
+		}
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessChange, "success", endpoint)
+}
+
+// DeleteEndpoint 删除订阅地址
+func (h *Handler) DeleteEndpoint(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		IDs []uint `json:"ids" form:"ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.dispatcher.DeleteEndpoint(c.Request.Context(), form.IDs...); err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Result(errcode.SuccessDelete, "success")
+}
+
+// Deliveries 投递记录分页列表，按ID倒序，不返回请求体
+func (h *Handler) Deliveries(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		EndpointID uint   `form:"endpoint_id"`
+		Event      string `form:"event"`
+		Status     string `form:"status" binding:"omitempty,oneof=pending success dead"`
+		Page       int    `form:"page"`
+		PageSize   int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	page, pageSize := max(form.Page, 1), form.PageSize
+	if pageSize < 1 {
+		pageSize = 10
+	} else if pageSize > 1000 {
+		pageSize = 1000
+	}
+
+	query := h.dispatcher.deliveries(c.Request.Context())
+	if form.EndpointID > 0 {
+		query = query.Where("endpoint_id = ?", form.EndpointID)
+	}
+	if form.Event != "" {
+		query = query.Where("event = ?", form.Event)
+	}
+	if form.Status != "" {
+		query = query.Where("status = ?", form.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	list := make([]Delivery, 0)
+	if err := query.Omit("payload").Order("id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Success(jcbaseGo.ListData{List: list, Total: int(total), Page: page, PageSize: pageSize})
+}
+
+// Attempts 投递记录详情及全部尝试
+func (h *Handler) Attempts(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		DeliveryID uint `form:"delivery_id" binding:"required"`
+	}
+	if err := c.ShouldBindQuery(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	ctx := c.Request.Context()
+	var delivery Delivery
+	if err := h.dispatcher.deliveries(ctx).Where("id = ?", form.DeliveryID).Limit(1).Find(&delivery).Error; err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	if delivery.ID == 0 {
+		base.Failure("投递记录不存在或已被删除", nil, errcode.NotExist)
+		return
+	}
+	attempts, err := h.dispatcher.Attempts(ctx, delivery.ID)
+	if err != nil {
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Success(gin.H{"delivery": delivery, "attempts": attempts})
+}
+
+// Redeliver 重新投递
+func (h *Handler) Redeliver(c *gin.Context) {
+	base := controller.Base{GinContext: c}
+	var form struct {
+		IDs []uint `json:"ids" form:"ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		base.Failure(i18n.ValidationMessage(c, err), nil, errcode.ParamError)
+		return
+	}
+	if err := h.dispatcher.Redeliver(c.Request.Context(), form.IDs...); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			base.Failure("投递记录不存在或已被删除", nil, errcode.NotExist)
+			return
+		}
+		base.Error(errcode.Wrap(err, errcode.SystemBusy, ""))
+		return
+	}
+	base.Success(nil)
+}
@@ -0,0 +1,306 @@
+// Package webhook 向外部系统推送事件的 Webhook 分发
+//
+// 事件按订阅的 Endpoint 生成投递记录保存在数据库中，后台按 next_attempt_at 轮询投递，失败后按指数退避重试，
+// 超过最大次数后标记为 dead（死信），可在管理接口中查看每次尝试的结果并手动重新投递。
+// 请求体使用 HMAC-SHA256 签名，接收方可使用 Verify 校验。
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint 状态
+const (
+	StatusDisabled = 0
+	StatusEnabled  = 1
+)
+
+// Delivery 状态
+const (
+	DeliveryPending = "pending" // 等待投递或等待重试
+	DeliverySuccess = "success" // 投递成功
+	DeliveryDead    = "dead"    // 超过最大重试次数，需手动重新投递
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("webhook: 记录不存在")
+
+// Endpoint 订阅地址
+type Endpoint struct {
+	ID        uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"column:name;size:64;default:'';comment:名称" json:"name"`
+	URL       string    `gorm:"column:url;size:500;not null;comment:推送地址" json:"url"`
+	Secret    string    `gorm:"column:secret;size:128;default:'';comment:签名密钥" json:"-"`
+	Events    string    `gorm:"column:events;size:1000;default:'*';comment:订阅的事件，逗号分隔，*表示全部，order.*表示前缀匹配" json:"events"`
+	Status    int       `gorm:"column:status;default:1;comment:状态 0禁用 1启用" json:"status"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// Subscribed 是否订阅了事件
+func (e Endpoint) Subscribed(event string) bool {
+	for _, pattern := range strings.Split(e.Events, ",") {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "*" || pattern == event:
+			return true
+		case strings.HasSuffix(pattern, ".*") && strings.HasPrefix(event, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery 投递记录
+type Delivery struct {
+	ID            uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	EndpointID    uint      `gorm:"column:endpoint_id;not null;index;comment:订阅地址ID" json:"endpoint_id"`
+	Event         string    `gorm:"column:event;size:128;not null;index;comment:事件" json:"event"`
+	Payload       string    `gorm:"column:payload;type:text;comment:请求体" json:"payload"`
+	Status        string    `gorm:"column:status;size:16;not null;index:idx_status_next,priority:1;comment:状态" json:"status"`
+	Attempts      int       `gorm:"column:attempts;default:0;comment:已尝试次数" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at;index:idx_status_next,priority:2;comment:下次尝试时间" json:"next_attempt_at"`
+	LastCode      int       `gorm:"column:last_code;default:0;comment:最后一次的HTTP状态码" json:"last_code"`
+	LastError     string    `gorm:"column:last_error;size:500;default:'';comment:最后一次的错误" json:"last_error"`
+	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// Attempt 单次投递尝试
+type Attempt struct {
+	ID         uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	DeliveryID uint      `gorm:"column:delivery_id;not null;index;comment:投递记录ID" json:"delivery_id"`
+	URL        string    `gorm:"column:url;size:500;comment:推送地址" json:"url"`
+	StatusCode int       `gorm:"column:status_code;default:0;comment:HTTP状态码" json:"status_code"`
+	Error      string    `gorm:"column:error;size:500;default:'';comment:错误信息" json:"error"`
+	Response   string    `gorm:"column:response;type:text;comment:响应内容（截断）" json:"response"`
+	Duration   int64     `gorm:"column:duration;default:0;comment:耗时，毫秒" json:"duration"`
+	CreatedAt  time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// Options 配置
+type Options struct {
+	EndpointTable string `default:"webhook_endpoint"` // 订阅地址表名（含前缀）
+	DeliveryTable string `default:"webhook_delivery"` // 投递记录表名（含前缀）
+	AttemptTable  string `default:"webhook_attempt"`  // 投递尝试表名（含前缀）
+	AutoMigrate   bool   // 是否自动创建数据表
+
+	MaxAttempts  int           `default:"8"` // 最大尝试次数，超过后标记为 dead
+	RetryBase    time.Duration // 首次重试的间隔，之后每次翻倍，默认30秒
+	RetryMax     time.Duration // 重试间隔上限，默认6小时
+	Timeout      time.Duration // 单次请求超时，默认10秒
+	Workers      int           `default:"4"` // 并发投递数
+	PollInterval time.Duration // 轮询待投递记录的间隔，默认5秒
+	BatchSize    int           `default:"100"` // 每次轮询取出的记录数
+	Client       *http.Client  // 自定义 HTTP 客户端
+}
+
+// Dispatcher Webhook 分发器
+//
+// 示例:
+//
+//	wh := webhook.New(db.GetDb(), webhook.Options{AutoMigrate: true})
+//	wh.Start()
+//	defer wh.Stop()
+//	_, _ = wh.SaveEndpoint(ctx, &webhook.Endpoint{URL: "https://partner.example.com/hook", Secret: "s3cret", Events: "order.*"})
+//	_ = wh.Dispatch(ctx, "order.paid", map[string]any{"order_no": "202401010001", "amount": 100})
+type Dispatcher struct {
+	db  *gorm.DB
+	opt Options
+
+	wake     chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	running  bool
+	runMu    sync.Mutex
+	inflight sync.Map // 正在投递的记录ID，避免同一实例重复投递
+}
+
+// New 创建分发器，调用 Start 后开始后台投递
+func New(db *gorm.DB, opts ...Options) *Dispatcher {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.RetryBase <= 0 {
+		opt.RetryBase = 30 * time.Second
+	}
+	if opt.RetryMax <= 0 {
+		opt.RetryMax = 6 * time.Hour
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	if opt.PollInterval <= 0 {
+		opt.PollInterval = 5 * time.Second
+	}
+	if opt.Client == nil {
+		opt.Client = &http.Client{Timeout: opt.Timeout}
+	}
+
+	d := &Dispatcher{db: db, opt: opt, wake: make(chan struct{}, 1)}
+	if opt.AutoMigrate {
+		ctx := context.Background()
+		_ = d.endpoints(ctx).AutoMigrate(&Endpoint{})
+		_ = d.deliveries(ctx).AutoMigrate(&Delivery{})
+		_ = d.attempts(ctx).AutoMigrate(&Attempt{})
+	}
+	return d
+}
+
+func (d *Dispatcher) endpoints(ctx context.Context) *gorm.DB {
+	return d.db.WithContext(ctx).Table(d.opt.EndpointTable)
+}
+
+func (d *Dispatcher) deliveries(ctx context.Context) *gorm.DB {
+	return d.db.WithContext(ctx).Table(d.opt.DeliveryTable)
+}
+
+func (d *Dispatcher) attempts(ctx context.Context) *gorm.DB {
+	return d.db.WithContext(ctx).Table(d.opt.AttemptTable)
+}
+
+// ----- 订阅地址 ----- /
+
+// SaveEndpoint 保存订阅地址，ID为0时新建；Secret 为空时保留原密钥
+func (d *Dispatcher) SaveEndpoint(ctx context.Context, endpoint *Endpoint) (*Endpoint, error) {
+	if endpoint.Events == "" {
+		endpoint.Events = "*"
+	}
+	if endpoint.ID == 0 {
+		return endpoint, d.endpoints(ctx).Create(endpoint).Error
+	}
+
+	var old Endpoint
+	if err := d.endpoints(ctx).Where("id = ?", endpoint.ID).Take(&old).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if endpoint.Secret == "" {
+		endpoint.Secret = old.Secret
+	}
+	endpoint.CreatedAt = old.CreatedAt
+	return endpoint, d.endpoints(ctx).Save(endpoint).Error
+}
+
+// DeleteEndpoint 删除订阅地址，未完成的投递记录不再投递
+func (d *Dispatcher) DeleteEndpoint(ctx context.Context, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.endpoints(ctx).Where("id IN ?", ids).Delete(&Endpoint{}).Error
+}
+
+// Endpoints 全部订阅地址
+func (d *Dispatcher) Endpoints(ctx context.Context) ([]Endpoint, error) {
+	list := make([]Endpoint, 0)
+	err := d.endpoints(ctx).Order("id").Find(&list).Error
+	return list, err
+}
+
+// ----- 分发 ----- /
+
+// Dispatch 为订阅了事件的已启用地址生成投递记录，payload 为 []byte、string 时原样发送，其余类型序列化为JSON；
+// 投递在后台进行，返回时只保证记录已保存
+func (d *Dispatcher) Dispatch(ctx context.Context, event string, payload any) error {
+	var body string
+	switch v := payload.(type) {
+	case []byte:
+		body = string(v)
+	case string:
+		body = v
+	default:
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = string(data)
+	}
+
+	endpoints := make([]Endpoint, 0)
+	if err := d.endpoints(ctx).Where("status = ?", StatusEnabled).Find(&endpoints).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	rows := make([]Delivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if endpoint.Subscribed(event) {
+			rows = append(rows, Delivery{
+				EndpointID:    endpoint.ID,
+				Event:         event,
+				Payload:       body,
+				Status:        DeliveryPending,
+				NextAttemptAt: now,
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := d.deliveries(ctx).Create(&rows).Error; err != nil {
+		return err
+	}
+	d.notify()
+	return nil
+}
+
+// Redeliver 重新投递（包括已成功及死信），重置尝试次数
+func (d *Dispatcher) Redeliver(ctx context.Context, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	res := d.deliveries(ctx).Where("id IN ?", ids).Updates(map[string]any{
+		"status":          DeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"updated_at":      time.Now(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	d.notify()
+	return nil
+}
+
+// Attempts 获取投递记录的全部尝试，按时间倒序
+func (d *Dispatcher) Attempts(ctx context.Context, deliveryID uint) ([]Attempt, error) {
+	list := make([]Attempt, 0)
+	err := d.attempts(ctx).Where("delivery_id = ?", deliveryID).Order("id DESC").Find(&list).Error
+	return list, err
+}
+
+// Purge 删除早于 before 的已成功投递记录及其尝试记录
+func (d *Dispatcher) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ids := make([]uint, 0)
+	err := d.deliveries(ctx).Where("status = ? AND updated_at < ?", DeliverySuccess, before).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	if err = d.attempts(ctx).Where("delivery_id IN ?", ids).Delete(&Attempt{}).Error; err != nil {
+		return 0, err
+	}
+	res := d.deliveries(ctx).Where("id IN ?", ids).Delete(&Delivery{})
+	return res.RowsAffected, res.Error
+}
+
+// notify 唤醒后台轮询
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
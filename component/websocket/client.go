@@ -0,0 +1,183 @@
+package websocket
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 消息类型，与 gorilla/websocket 保持一致
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+// outMessage 待发送的消息
+type outMessage struct {
+	messageType int
+	data        []byte
+}
+
+// Client 单个websocket连接
+type Client struct {
+	ID         string          // 连接ID
+	Hub        *Hub            // 所属hub
+	Conn       *websocket.Conn // 底层连接
+	Request    *http.Request   // 升级连接时的http请求
+	GinContext *gin.Context    // 通过 Hub.Handler 升级时的gin上下文
+
+	send      chan outMessage
+	done      chan struct{}
+	closeOnce sync.Once
+	rooms     map[string]struct{} // 由hub加锁维护
+	values    sync.Map
+}
+
+// Set 在连接上保存自定义数据（如用户ID）
+func (c *Client) Set(key string, value any) {
+	c.values.Store(key, value)
+}
+
+// Get 获取连接上保存的自定义数据
+func (c *Client) Get(key string) (any, bool) {
+	return c.values.Load(key)
+}
+
+// Send 发送文本消息（异步写入，不阻塞调用方）
+func (c *Client) Send(data []byte) error {
+	return c.write(TextMessage, data)
+}
+
+// SendBinary 发送二进制消息
+func (c *Client) SendBinary(data []byte) error {
+	return c.write(BinaryMessage, data)
+}
+
+// SendJSON 将数据序列化为JSON后发送
+func (c *Client) SendJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Send(data)
+}
+
+// Join 加入房间
+func (c *Client) Join(room string) {
+	c.Hub.Join(c, room)
+}
+
+// Leave 离开房间
+func (c *Client) Leave(room string) {
+	c.Hub.Leave(c, room)
+}
+
+// Close 关闭连接
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// Serve 开始收发消息，阻塞直到连接断开
+func (c *Client) Serve() {
+	go c.writePump()
+	err := c.readPump()
+	c.finish(err)
+}
+
+// write 将消息放入发送队列
+func (c *Client) write(messageType int, data []byte) error {
+	select {
+	case <-c.done:
+		return ErrClientClosed
+	default:
+	}
+
+	select {
+	case c.send <- outMessage{messageType: messageType, data: data}:
+		return nil
+	case <-c.done:
+		return ErrClientClosed
+	default:
+		return ErrSendBufferFull
+	}
+}
+
+// readPump 读取消息，并通过pong维持连接存活
+func (c *Client) readPump() error {
+	opt := c.Hub.opt
+	_ = c.Conn.SetReadDeadline(time.Now().Add(opt.PongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(opt.PongWait))
+	})
+
+	if c.Hub.onConnect != nil {
+		c.Hub.onConnect(c)
+	}
+
+	for {
+		messageType, data, err := c.Conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				return nil
+			}
+			select {
+			case <-c.done:
+				// 服务端主动关闭
+				return nil
+			default:
+			}
+			return err
+		}
+		if c.Hub.onMessage != nil {
+			c.Hub.onMessage(c, messageType, data)
+		}
+	}
+}
+
+// writePump 发送队列中的消息，并定时发送ping
+func (c *Client) writePump() {
+	opt := c.Hub.opt
+	ticker := time.NewTicker(opt.PingInterval)
+	defer func() {
+		ticker.Stop()
+		_ = c.Conn.Close()
+	}()
+
+	for {
+		select {
+		case msg := <-c.send:
+			_ = c.Conn.SetWriteDeadline(time.Now().Add(opt.WriteWait))
+			if err := c.Conn.WriteMessage(msg.messageType, msg.data); err != nil {
+				c.Close()
+				return
+			}
+		case <-ticker.C:
+			_ = c.Conn.SetWriteDeadline(time.Now().Add(opt.WriteWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.done:
+			_ = c.Conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(opt.WriteWait))
+			return
+		}
+	}
+}
+
+// finish 连接断开后的清理工作
+func (c *Client) finish(err error) {
+	c.Close()
+	c.Hub.unregister(c)
+
+	if c.Hub.onClose != nil {
+		c.Hub.onClose(c, err)
+	}
+	c.Hub.emit(Event{Type: EventDisconnect, ClientID: c.ID, Err: err})
+}
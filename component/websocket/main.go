@@ -0,0 +1,326 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/helper/collection"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 生命周期事件类型
+const (
+	EventConnect    = "connect"    // 建立连接
+	EventDisconnect = "disconnect" // 断开连接
+	EventJoin       = "join"       // 加入房间
+	EventLeave      = "leave"      // 离开房间
+)
+
+// ErrClientClosed 连接已关闭
+var ErrClientClosed = errors.New("websocket client closed")
+
+// ErrSendBufferFull 发送缓冲区已满（客户端消费过慢）
+var ErrSendBufferFull = errors.New("websocket send buffer full")
+
+// Options hub配置
+type Options struct {
+	ReadBufferSize  int                        `default:"1024"`   // 读缓冲区大小
+	WriteBufferSize int                        `default:"1024"`   // 写缓冲区大小
+	MaxMessageSize  int64                      `default:"524288"` // 单条消息最大字节数
+	SendBufferSize  int                        `default:"256"`    // 每个连接待发送消息的缓冲数量
+	PingInterval    time.Duration              // 发送ping的间隔，默认30秒
+	PongWait        time.Duration              // 等待pong的超时时间，默认60秒，必须大于PingInterval
+	WriteWait       time.Duration              // 写超时时间，默认10秒
+	CheckOrigin     func(r *http.Request) bool // 来源校验，默认只允许同源请求
+}
+
+// Event 连接生命周期事件，可用于记录日志或上报调试器
+type Event struct {
+	Type     string    // 事件类型
+	ClientID string    // 连接ID
+	Room     string    // 房间名，仅 join/leave 事件有值
+	Time     time.Time // 事件时间
+	Err      error     // 断开连接的原因，仅 disconnect 事件有值
+}
+
+// Hub 管理所有websocket连接及房间
+type Hub struct {
+	opt      Options
+	upgrader websocket.Upgrader
+
+	mu      sync.RWMutex
+	clients map[string]*Client
+	rooms   map[string]map[string]*Client
+
+	onConnect func(c *Client)
+	onMessage func(c *Client, messageType int, data []byte)
+	onClose   func(c *Client, err error)
+	onEvent   func(e Event)
+}
+
+// NewHub 创建一个新的hub
+//
+// 示例:
+//
+//	hub := websocket.NewHub()
+//	hub.OnMessage(func(c *websocket.Client, _ int, data []byte) {
+//	    hub.BroadcastToRoom("chat", data)
+//	})
+//	r.GET("/ws", hub.Handler())
+func NewHub(opts ...Options) *Hub {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	_ = helper.CheckAndSetDefault(&opt)
+	if opt.PingInterval <= 0 {
+		opt.PingInterval = 30 * time.Second
+	}
+	if opt.PongWait <= opt.PingInterval {
+		opt.PongWait = opt.PingInterval * 2
+	}
+	if opt.WriteWait <= 0 {
+		opt.WriteWait = 10 * time.Second
+	}
+
+	return &Hub{
+		opt: opt,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  opt.ReadBufferSize,
+			WriteBufferSize: opt.WriteBufferSize,
+			CheckOrigin:     opt.CheckOrigin,
+		},
+		clients: make(map[string]*Client),
+		rooms:   make(map[string]map[string]*Client),
+	}
+}
+
+// OnConnect 设置建立连接后的回调
+func (h *Hub) OnConnect(fn func(c *Client)) *Hub {
+	h.onConnect = fn
+	return h
+}
+
+// OnMessage 设置收到消息后的回调
+func (h *Hub) OnMessage(fn func(c *Client, messageType int, data []byte)) *Hub {
+	h.onMessage = fn
+	return h
+}
+
+// OnClose 设置连接断开后的回调
+func (h *Hub) OnClose(fn func(c *Client, err error)) *Hub {
+	h.onClose = fn
+	return h
+}
+
+// OnEvent 设置生命周期事件回调（连接、断开、加入/离开房间）
+func (h *Hub) OnEvent(fn func(e Event)) *Hub {
+	h.onEvent = fn
+	return h
+}
+
+// Handler 返回用于升级websocket连接的gin处理器
+func (h *Hub) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, err := h.Upgrade(c.Writer, c.Request)
+		if err != nil {
+			// Upgrade 失败时已向客户端输出错误响应
+			log.Println("websocket升级失败:", err)
+			c.Abort()
+			return
+		}
+		client.GinContext = c
+		client.Serve()
+	}
+}
+
+// Upgrade 将http连接升级为websocket连接并注册到hub中，
+// 调用方需要执行 Client.Serve 开始收发消息
+func (h *Hub) Upgrade(w http.ResponseWriter, r *http.Request, responseHeader ...http.Header) (*Client, error) {
+	var header http.Header
+	if len(responseHeader) > 0 {
+		header = responseHeader[0]
+	}
+	conn, err := h.upgrader.Upgrade(w, r, header)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(h.opt.MaxMessageSize)
+
+	client := &Client{
+		ID:      strconv.FormatInt(time.Now().UnixNano(), 36) + helper.Random(8),
+		Hub:     h,
+		Conn:    conn,
+		Request: r,
+		send:    make(chan outMessage, h.opt.SendBufferSize),
+		done:    make(chan struct{}),
+		rooms:   make(map[string]struct{}),
+	}
+
+	h.mu.Lock()
+	h.clients[client.ID] = client
+	h.mu.Unlock()
+
+	h.emit(Event{Type: EventConnect, ClientID: client.ID})
+	return client, nil
+}
+
+// Client 获取指定ID的连接
+func (h *Hub) Client(id string) (*Client, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	client, ok := h.clients[id]
+	return client, ok
+}
+
+// Count 获取当前连接数
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// RoomMembers 获取房间内所有连接ID
+func (h *Hub) RoomMembers(room string) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ids := make([]string, 0, len(h.rooms[room]))
+	for id := range h.rooms[room] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Join 将连接加入房间
+func (h *Hub) Join(c *Client, room string) {
+	h.mu.Lock()
+	if _, ok := h.clients[c.ID]; !ok {
+		h.mu.Unlock()
+		return
+	}
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[string]*Client)
+	}
+	h.rooms[room][c.ID] = c
+	c.rooms[room] = struct{}{}
+	h.mu.Unlock()
+
+	h.emit(Event{Type: EventJoin, ClientID: c.ID, Room: room})
+}
+
+// Leave 将连接移出房间
+func (h *Hub) Leave(c *Client, room string) {
+	h.mu.Lock()
+	if members, ok := h.rooms[room]; ok {
+		delete(members, c.ID)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+	delete(c.rooms, room)
+	h.mu.Unlock()
+
+	h.emit(Event{Type: EventLeave, ClientID: c.ID, Room: room})
+}
+
+// Broadcast 向所有连接广播文本消息
+func (h *Hub) Broadcast(data []byte, excludeIDs ...string) {
+	h.mu.RLock()
+	targets := make([]*Client, 0, len(h.clients))
+	for _, client := range h.clients {
+		targets = append(targets, client)
+	}
+	h.mu.RUnlock()
+
+	h.sendToClients(targets, data, excludeIDs)
+}
+
+// BroadcastToRoom 向房间内所有连接广播文本消息
+func (h *Hub) BroadcastToRoom(room string, data []byte, excludeIDs ...string) {
+	h.mu.RLock()
+	targets := make([]*Client, 0, len(h.rooms[room]))
+	for _, client := range h.rooms[room] {
+		targets = append(targets, client)
+	}
+	h.mu.RUnlock()
+
+	h.sendToClients(targets, data, excludeIDs)
+}
+
+// BroadcastJSON 将数据序列化为JSON后向所有连接广播
+func (h *Hub) BroadcastJSON(v any, excludeIDs ...string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.Broadcast(data, excludeIDs...)
+	return nil
+}
+
+// SendTo 向指定连接发送文本消息
+func (h *Hub) SendTo(clientID string, data []byte) error {
+	client, ok := h.Client(clientID)
+	if !ok {
+		return ErrClientClosed
+	}
+	return client.Send(data)
+}
+
+// Close 关闭所有连接
+func (h *Hub) Close() {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for _, client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		client.Close()
+	}
+}
+
+// sendToClients 向多个连接发送消息，发送失败（缓冲区满）的连接会被关闭
+func (h *Hub) sendToClients(targets []*Client, data []byte, excludeIDs []string) {
+	for _, client := range targets {
+		if len(excludeIDs) > 0 && collection.Contains(excludeIDs, client.ID) {
+			continue
+		}
+		if err := client.Send(data); errors.Is(err, ErrSendBufferFull) {
+			log.Printf("websocket连接 %s 消费过慢，已断开\n", client.ID)
+			client.Close()
+		}
+	}
+}
+
+// unregister 从hub中移除连接
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	delete(h.clients, c.ID)
+	for room := range c.rooms {
+		if members, ok := h.rooms[room]; ok {
+			delete(members, c.ID)
+			if len(members) == 0 {
+				delete(h.rooms, room)
+			}
+		}
+	}
+	h.mu.Unlock()
+}
+
+// emit 触发生命周期事件
+func (h *Hub) emit(e Event) {
+	if h.onEvent == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	h.onEvent(e)
+}
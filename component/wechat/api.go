@@ -0,0 +1,129 @@
+package wechat
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// TemplateData 模板消息字段值
+type TemplateData struct {
+	Value string `json:"value"`
+	Color string `json:"color,omitempty"`
+}
+
+// TemplateMessage 公众号模板消息
+type TemplateMessage struct {
+	ToUser      string                  `json:"touser"`
+	TemplateID  string                  `json:"template_id"`
+	URL         string                  `json:"url,omitempty"`
+	MiniProgram *TemplateMiniProgram    `json:"miniprogram,omitempty"`
+	Data        map[string]TemplateData `json:"data"`
+}
+
+// TemplateMiniProgram 模板消息跳转的小程序
+type TemplateMiniProgram struct {
+	AppID    string `json:"appid"`
+	PagePath string `json:"pagepath,omitempty"`
+}
+
+// SubscribeMessage 小程序订阅消息
+type SubscribeMessage struct {
+	ToUser           string                  `json:"touser"`
+	TemplateID       string                  `json:"template_id"`
+	Page             string                  `json:"page,omitempty"`
+	MiniProgramState string                  `json:"miniprogram_state,omitempty"` // developer/trial/formal
+	Lang             string                  `json:"lang,omitempty"`
+	Data             map[string]TemplateData `json:"data"`
+}
+
+// Session code2session 返回结果
+type Session struct {
+	OpenID     string `json:"openid"`
+	SessionKey string `json:"session_key"`
+	UnionID    string `json:"unionid"`
+}
+
+// SendTemplateMessage 发送公众号模板消息，返回消息ID
+func (i *Instance) SendTemplateMessage(msg TemplateMessage) (int64, error) {
+	var result struct {
+		MsgID int64 `json:"msgid"`
+	}
+	err := i.PostWithToken("/cgi-bin/message/template/send", msg, &result)
+	return result.MsgID, err
+}
+
+// SendSubscribeMessage 发送小程序订阅消息
+func (i *Instance) SendSubscribeMessage(msg SubscribeMessage) error {
+	return i.PostWithToken("/cgi-bin/message/subscribe/send", msg, nil)
+}
+
+// Code2Session 小程序登录凭证校验
+func (i *Instance) Code2Session(code string) (*Session, error) {
+	var result struct {
+		APIError
+		Session
+	}
+	err := i.getJSON("/sns/jscode2session", map[string]string{
+		"appid":      i.Conf.AppID,
+		"secret":     i.Conf.AppSecret,
+		"js_code":    code,
+		"grant_type": "authorization_code",
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.ErrCode != 0 {
+		return nil, &result.APIError
+	}
+	return &result.Session, nil
+}
+
+// DecryptUserData 解密小程序 wx.getUserInfo、手机号等开放数据，并解析到v中
+func (i *Instance) DecryptUserData(sessionKey, encryptedData, iv string, v any) error {
+	key, err := base64.StdEncoding.DecodeString(sessionKey)
+	if err != nil {
+		return err
+	}
+	ivBytes, err := base64.StdEncoding.DecodeString(iv)
+	if err != nil {
+		return err
+	}
+	cipherText, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil {
+		return err
+	}
+	if len(ivBytes) != aes.BlockSize || len(cipherText) == 0 || len(cipherText)%aes.BlockSize != 0 {
+		return errors.New("wechat: 加密数据格式错误")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	plain := make([]byte, len(cipherText))
+	cipher.NewCBCDecrypter(block, ivBytes).CryptBlocks(plain, cipherText)
+
+	padding := int(plain[len(plain)-1])
+	if padding < 1 || padding > aes.BlockSize {
+		return errors.New("wechat: 填充错误")
+	}
+	plain = plain[:len(plain)-padding]
+
+	// 校验数据水印中的AppID
+	var watermark struct {
+		Watermark struct {
+			AppID string `json:"appid"`
+		} `json:"watermark"`
+	}
+	if err = json.Unmarshal(plain, &watermark); err != nil {
+		return err
+	}
+	if watermark.Watermark.AppID != i.Conf.AppID {
+		return errors.New("wechat: AppID不匹配")
+	}
+
+	return json.Unmarshal(plain, v)
+}
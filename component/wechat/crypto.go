@@ -0,0 +1,113 @@
+package wechat
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"sort"
+	"strings"
+)
+
+// 消息加解密使用32字节块大小的PKCS7填充
+const cryptoBlockSize = 32
+
+// CheckSignature 校验服务器推送请求的签名（URL中的signature参数）
+func (i *Instance) CheckSignature(signature, timestamp, nonce string) bool {
+	return signature != "" && signature == Signature(i.Conf.Token, timestamp, nonce)
+}
+
+// CheckMsgSignature 校验加密消息的签名（URL中的msg_signature参数）
+func (i *Instance) CheckMsgSignature(msgSignature, timestamp, nonce, encrypt string) bool {
+	return msgSignature != "" && msgSignature == Signature(i.Conf.Token, timestamp, nonce, encrypt)
+}
+
+// Signature 将参数字典序排序后拼接并计算sha1
+func Signature(params ...string) string {
+	sorted := append([]string{}, params...)
+	sort.Strings(sorted)
+	sum := sha1.Sum([]byte(strings.Join(sorted, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// EncryptMsg 加密消息，返回base64编码的密文
+func (i *Instance) EncryptMsg(plain []byte) (string, error) {
+	key, err := i.aesKey()
+	if err != nil {
+		return "", err
+	}
+
+	// 16字节随机串 + 4字节消息长度(网络字节序) + 消息 + AppID
+	buf := bytes.NewBufferString(helper.Random(16))
+	lenBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(lenBytes, uint32(len(plain)))
+	buf.Write(lenBytes)
+	buf.Write(plain)
+	buf.WriteString(i.Conf.AppID)
+
+	data := buf.Bytes()
+	padding := cryptoBlockSize - len(data)%cryptoBlockSize
+	data = append(data, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	cipherText := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, key[:aes.BlockSize]).CryptBlocks(cipherText, data)
+	return base64.StdEncoding.EncodeToString(cipherText), nil
+}
+
+// DecryptMsg 解密消息，并校验消息中的AppID
+func (i *Instance) DecryptMsg(encrypt string) ([]byte, error) {
+	key, err := i.aesKey()
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText, err := base64.StdEncoding.DecodeString(encrypt)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherText) == 0 || len(cipherText)%aes.BlockSize != 0 {
+		return nil, errors.New("wechat: 密文长度错误")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(cipherText))
+	cipher.NewCBCDecrypter(block, key[:aes.BlockSize]).CryptBlocks(plain, cipherText)
+
+	padding := int(plain[len(plain)-1])
+	if padding < 1 || padding > cryptoBlockSize || padding > len(plain) {
+		return nil, errors.New("wechat: 填充错误")
+	}
+	plain = plain[:len(plain)-padding]
+	if len(plain) < 20 {
+		return nil, errors.New("wechat: 消息长度错误")
+	}
+
+	msgLen := int(binary.BigEndian.Uint32(plain[16:20]))
+	if 20+msgLen > len(plain) {
+		return nil, errors.New("wechat: 消息长度错误")
+	}
+	if appID := string(plain[20+msgLen:]); appID != i.Conf.AppID {
+		return nil, errors.New("wechat: AppID不匹配")
+	}
+	return plain[20 : 20+msgLen], nil
+}
+
+// aesKey 解析EncodingAESKey
+func (i *Instance) aesKey() ([]byte, error) {
+	if len(i.Conf.EncodingAESKey) != 43 {
+		return nil, errors.New("wechat: EncodingAESKey长度必须为43位")
+	}
+	return base64.StdEncoding.DecodeString(i.Conf.EncodingAESKey + "=")
+}
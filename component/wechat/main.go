@@ -0,0 +1,177 @@
+package wechat
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/component/helper/cache"
+	"github.com/jcbowen/jcbaseGo/component/httpclient"
+	"sync"
+	"time"
+)
+
+// APIBaseURL 微信接口地址
+const APIBaseURL = "https://api.weixin.qq.com"
+
+// access_token 失效相关的错误码
+const (
+	ErrCodeInvalidToken = 40001 // access_token 无效
+	ErrCodeTokenExpired = 42001 // access_token 已过期
+)
+
+// TokenCache access_token 缓存，多实例部署时应使用共享缓存（如 redis.CacheOpt），
+// 避免各实例分别刷新导致互相失效
+type TokenCache interface {
+	Set(key string, value interface{}, args ...time.Duration) error
+	GetString(key string) (string, error)
+}
+
+// APIError 微信接口返回的错误
+type APIError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("wechat api error: %d %s", e.ErrCode, e.ErrMsg)
+}
+
+// Instance 微信公众号/小程序实例
+type Instance struct {
+	Conf  jcbaseGo.WechatStruct
+	Cache TokenCache
+	HTTP  *httpclient.Client
+
+	tokenMu sync.Mutex
+}
+
+// New 创建微信实例，未传入缓存时使用进程内缓存
+//
+// 示例:
+//
+//	wx := wechat.New(conf.Wechat, redis.NewCache(rds))
+//	session, err := wx.Code2Session(code)
+func New(conf jcbaseGo.WechatStruct, tokenCache ...TokenCache) *Instance {
+	_ = helper.CheckAndSetDefault(&conf)
+	if conf.AppID == "" {
+		jcbaseGo.PanicIfError(errors.New("wechat: AppID不能为空"))
+	}
+
+	i := &Instance{
+		Conf: conf,
+		HTTP: httpclient.New(httpclient.Options{
+			BaseURL:    APIBaseURL,
+			Timeout:    10 * time.Second,
+			MaxRetries: 1,
+		}),
+	}
+	if len(tokenCache) > 0 && tokenCache[0] != nil {
+		i.Cache = tokenCache[0]
+	} else {
+		i.Cache = cache.NewMemory()
+	}
+	return i
+}
+
+// AccessToken 获取access_token，缓存失效前5分钟会自动刷新
+func (i *Instance) AccessToken() (string, error) {
+	if token, _ := i.Cache.GetString(i.tokenCacheKey()); token != "" {
+		return token, nil
+	}
+
+	i.tokenMu.Lock()
+	defer i.tokenMu.Unlock()
+
+	// 等待锁期间可能已被其他请求刷新
+	if token, _ := i.Cache.GetString(i.tokenCacheKey()); token != "" {
+		return token, nil
+	}
+	return i.refreshAccessToken()
+}
+
+// RefreshAccessToken 强制刷新access_token
+func (i *Instance) RefreshAccessToken() (string, error) {
+	i.tokenMu.Lock()
+	defer i.tokenMu.Unlock()
+	return i.refreshAccessToken()
+}
+
+// refreshAccessToken 请求新的access_token并写入缓存，调用方需持有tokenMu
+func (i *Instance) refreshAccessToken() (string, error) {
+	var result struct {
+		APIError
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err := i.getJSON("/cgi-bin/token", map[string]string{
+		"grant_type": "client_credential",
+		"appid":      i.Conf.AppID,
+		"secret":     i.Conf.AppSecret,
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	if result.ErrCode != 0 {
+		return "", &result.APIError
+	}
+
+	expire := time.Duration(result.ExpiresIn)*time.Second - 5*time.Minute
+	if expire <= 0 {
+		expire = time.Minute
+	}
+	if err = i.Cache.Set(i.tokenCacheKey(), result.AccessToken, expire); err != nil {
+		return "", err
+	}
+	return result.AccessToken, nil
+}
+
+// PostWithToken 携带access_token以JSON格式调用接口，access_token失效时自动刷新后重试一次
+func (i *Instance) PostWithToken(path string, body any, result any) error {
+	for retry := 0; ; retry++ {
+		token, err := i.AccessToken()
+		if err != nil {
+			return err
+		}
+
+		var apiErr APIError
+		resp, err := i.HTTP.R().SetQuery("access_token", token).SetJSON(body).Post(path)
+		if err != nil {
+			return err
+		}
+		if err = resp.JSON(&apiErr); err != nil {
+			return fmt.Errorf("wechat: 解析响应失败: %v", err)
+		}
+
+		if (apiErr.ErrCode == ErrCodeInvalidToken || apiErr.ErrCode == ErrCodeTokenExpired) && retry == 0 {
+			if _, err = i.RefreshAccessToken(); err != nil {
+				return err
+			}
+			continue
+		}
+		if apiErr.ErrCode != 0 {
+			return &apiErr
+		}
+		if result != nil {
+			return resp.JSON(result)
+		}
+		return nil
+	}
+}
+
+// getJSON 以GET方式调用接口并解析JSON响应
+func (i *Instance) getJSON(path string, query map[string]string, result any) error {
+	resp, err := i.HTTP.R().SetQueryParams(query).Get(path)
+	if err != nil {
+		return err
+	}
+	if err = resp.JSON(result); err != nil {
+		return fmt.Errorf("wechat: 解析响应失败: %v", err)
+	}
+	return nil
+}
+
+// tokenCacheKey access_token 缓存键名
+func (i *Instance) tokenCacheKey() string {
+	return "wechat:access_token:" + i.Conf.AppID
+}
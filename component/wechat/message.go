@@ -0,0 +1,162 @@
+package wechat
+
+import (
+	"encoding/xml"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Message 服务器推送的消息/事件
+type Message struct {
+	XMLName      xml.Name `xml:"xml"`
+	ToUserName   string   `xml:"ToUserName"`
+	FromUserName string   `xml:"FromUserName"`
+	CreateTime   int64    `xml:"CreateTime"`
+	MsgType      string   `xml:"MsgType"`
+	MsgId        int64    `xml:"MsgId"`
+
+	Content      string  `xml:"Content"`      // 文本消息
+	PicUrl       string  `xml:"PicUrl"`       // 图片消息
+	MediaId      string  `xml:"MediaId"`      // 图片/语音/视频消息
+	Format       string  `xml:"Format"`       // 语音格式
+	Recognition  string  `xml:"Recognition"`  // 语音识别结果
+	ThumbMediaId string  `xml:"ThumbMediaId"` // 视频缩略图
+	LocationX    float64 `xml:"Location_X"`   // 地理位置纬度
+	LocationY    float64 `xml:"Location_Y"`   // 地理位置经度
+	Scale        int     `xml:"Scale"`        // 地图缩放大小
+	Label        string  `xml:"Label"`        // 地理位置信息
+	Title        string  `xml:"Title"`        // 链接消息
+	Description  string  `xml:"Description"`  // 链接消息
+	Url          string  `xml:"Url"`          // 链接消息
+
+	Event     string  `xml:"Event"`     // 事件类型
+	EventKey  string  `xml:"EventKey"`  // 事件KEY值
+	Ticket    string  `xml:"Ticket"`    // 二维码ticket
+	Latitude  float64 `xml:"Latitude"`  // 上报地理位置纬度
+	Longitude float64 `xml:"Longitude"` // 上报地理位置经度
+	Precision float64 `xml:"Precision"` // 上报地理位置精度
+
+	Raw []byte `xml:"-"` // 消息原文（已解密）
+}
+
+// encryptedEnvelope 安全模式下的消息外层结构
+type encryptedEnvelope struct {
+	XMLName    xml.Name `xml:"xml"`
+	ToUserName string   `xml:"ToUserName"`
+	Encrypt    string   `xml:"Encrypt"`
+}
+
+// encryptedReply 安全模式下的回复结构
+type encryptedReply struct {
+	XMLName      xml.Name `xml:"xml"`
+	Encrypt      CDATA    `xml:"Encrypt"`
+	MsgSignature CDATA    `xml:"MsgSignature"`
+	TimeStamp    string   `xml:"TimeStamp"`
+	Nonce        CDATA    `xml:"Nonce"`
+}
+
+// CDATA 以CDATA形式输出的xml文本
+type CDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// TextReply 文本回复
+type TextReply struct {
+	XMLName      xml.Name `xml:"xml"`
+	ToUserName   CDATA    `xml:"ToUserName"`
+	FromUserName CDATA    `xml:"FromUserName"`
+	CreateTime   int64    `xml:"CreateTime"`
+	MsgType      CDATA    `xml:"MsgType"`
+	Content      CDATA    `xml:"Content"`
+}
+
+// NewTextReply 根据收到的消息构造文本回复
+func NewTextReply(msg *Message, content string) *TextReply {
+	return &TextReply{
+		ToUserName:   CDATA{msg.FromUserName},
+		FromUserName: CDATA{msg.ToUserName},
+		CreateTime:   time.Now().Unix(),
+		MsgType:      CDATA{"text"},
+		Content:      CDATA{content},
+	}
+}
+
+// VerifyURL 处理服务器配置时的URL校验请求，校验通过时原样返回echostr
+func (i *Instance) VerifyURL(c *gin.Context) bool {
+	if !i.CheckSignature(c.Query("signature"), c.Query("timestamp"), c.Query("nonce")) {
+		c.String(http.StatusForbidden, "invalid signature")
+		return false
+	}
+	c.String(http.StatusOK, c.Query("echostr"))
+	return true
+}
+
+// ParseMessage 校验签名并解析推送的消息，安全模式下自动解密
+func (i *Instance) ParseMessage(c *gin.Context) (*Message, error) {
+	timestamp, nonce := c.Query("timestamp"), c.Query("nonce")
+	if !i.CheckSignature(c.Query("signature"), timestamp, nonce) {
+		return nil, errors.New("wechat: 签名校验失败")
+	}
+
+	raw, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Query("encrypt_type") == "aes" {
+		var envelope encryptedEnvelope
+		if err = xml.Unmarshal(raw, &envelope); err != nil {
+			return nil, err
+		}
+		if !i.CheckMsgSignature(c.Query("msg_signature"), timestamp, nonce, envelope.Encrypt) {
+			return nil, errors.New("wechat: 消息签名校验失败")
+		}
+		if raw, err = i.DecryptMsg(envelope.Encrypt); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := &Message{Raw: raw}
+	if err = xml.Unmarshal(raw, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Reply 输出被动回复，安全模式下自动加密；reply为nil时回复success
+func (i *Instance) Reply(c *gin.Context, reply any) error {
+	if reply == nil {
+		c.String(http.StatusOK, "success")
+		return nil
+	}
+
+	data, err := xml.Marshal(reply)
+	if err != nil {
+		return err
+	}
+
+	if c.Query("encrypt_type") == "aes" {
+		encrypt, err := i.EncryptMsg(data)
+		if err != nil {
+			return err
+		}
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		nonce := c.Query("nonce")
+		data, err = xml.Marshal(encryptedReply{
+			Encrypt:      CDATA{encrypt},
+			MsgSignature: CDATA{Signature(i.Conf.Token, timestamp, nonce, encrypt)},
+			TimeStamp:    timestamp,
+			Nonce:        CDATA{nonce},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+	return nil
+}
@@ -0,0 +1,132 @@
+package wordfilter
+
+import (
+	"github.com/jcbowen/jcbaseGo/component/helper/chinese"
+	"golang.org/x/text/width"
+	"unicode"
+)
+
+// automaton Aho-Corasick 自动机，构建后只读，可并发匹配
+type automaton struct {
+	nodes   []node
+	words   []Word
+	lengths []int // 词语参与匹配的字符数
+}
+
+type node struct {
+	next   map[rune]int32
+	fail   int32
+	output []int32 // 以该节点结尾的词（含失败链上的词）在 words 中的下标
+}
+
+// build 构建自动机，相同的词只保留第一个
+func build(words []Word) *automaton {
+	a := &automaton{nodes: []node{{}}}
+	for _, w := range words {
+		key := matchKey(w.Text)
+		if len(key) == 0 {
+			continue
+		}
+
+		cur := int32(0)
+		for _, r := range key {
+			nxt, ok := a.nodes[cur].next[r]
+			if !ok {
+				nxt = int32(len(a.nodes))
+				a.nodes = append(a.nodes, node{})
+				if a.nodes[cur].next == nil {
+					a.nodes[cur].next = make(map[rune]int32)
+				}
+				a.nodes[cur].next[r] = nxt
+			}
+			cur = nxt
+		}
+		if len(a.nodes[cur].output) == 0 {
+			a.nodes[cur].output = []int32{int32(len(a.words))}
+			a.words = append(a.words, w)
+			a.lengths = append(a.lengths, len(key))
+		}
+	}
+
+	// 按层遍历设置失败指针，并合并失败链上的输出
+	queue := make([]int32, 0, len(a.nodes))
+	for _, child := range a.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range a.nodes[cur].next {
+			fail := a.nodes[cur].fail
+			for fail > 0 && a.nodes[fail].next[r] == 0 {
+				fail = a.nodes[fail].fail
+			}
+			if f, ok := a.nodes[fail].next[r]; ok && f != child {
+				a.nodes[child].fail = f
+			}
+			if out := a.nodes[a.nodes[child].fail].output; len(out) > 0 {
+				a.nodes[child].output = append(append([]int32(nil), a.nodes[child].output...), out...)
+			}
+			queue = append(queue, child)
+		}
+	}
+	return a
+}
+
+// match 查找文本中的所有敏感词（可重叠），fn 返回 false 时停止
+// 匹配时忽略大小写、全半角、简繁体差异，并跳过词语中间插入的空白及标点符号
+func (a *automaton) match(text string, fn func(m Match) bool) {
+	if len(a.words) == 0 {
+		return
+	}
+	runes := []rune(text)
+	simplified := []rune(chinese.ToSimplified(text))
+
+	// positions 记录参与匹配的字符在原文中的下标
+	positions := make([]int, 0, len(runes))
+	cur := int32(0)
+	for i, r := range simplified {
+		if r = normalize(r); ignored(r) {
+			continue
+		}
+		positions = append(positions, i)
+		for cur > 0 && a.nodes[cur].next[r] == 0 {
+			cur = a.nodes[cur].fail
+		}
+		cur = a.nodes[cur].next[r]
+
+		for _, idx := range a.nodes[cur].output {
+			w := a.words[idx]
+			start := positions[len(positions)-a.lengths[idx]]
+			m := Match{Word: w.Text, Category: w.Category, Start: start, End: i + 1, Text: string(runes[start : i+1])}
+			if !fn(m) {
+				return
+			}
+		}
+	}
+}
+
+// matchKey 获取词语参与匹配的字符
+func matchKey(s string) []rune {
+	runes := []rune(chinese.ToSimplified(s))
+	key := runes[:0]
+	for _, r := range runes {
+		if r = normalize(r); !ignored(r) {
+			key = append(key, r)
+		}
+	}
+	return key
+}
+
+// normalize 全角转半角并转为小写
+func normalize(r rune) rune {
+	if folded := width.LookupRune(r).Folded(); folded != 0 {
+		r = folded
+	}
+	return unicode.ToLower(r)
+}
+
+// ignored 匹配时跳过的字符：空白、标点及符号
+func ignored(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
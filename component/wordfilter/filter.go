@@ -0,0 +1,209 @@
+package wordfilter
+
+import (
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Word 敏感词
+type Word struct {
+	Text     string `json:"text"`
+	Category string `json:"category,omitempty"` // 分类，如 政治、色情、广告
+}
+
+// Match 匹配结果，Start、End 为原文中的字符（rune）下标，不含 End
+type Match struct {
+	Word     string `json:"word"`     // 词库中的敏感词
+	Category string `json:"category"` // 敏感词分类
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Text     string `json:"text"` // 原文中被匹配的内容，可能包含插入的空白及符号
+}
+
+// Source 词库来源，每次加载时调用
+type Source func() ([]Word, error)
+
+// Filter 敏感词过滤器，基于 Aho-Corasick 自动机，匹配耗时与词库大小无关，并发安全
+// 匹配时忽略大小写、全半角、简繁体差异，并跳过词语中间插入的空白及标点符号，如 "敏 感-词" 能匹配 "敏感词"
+//
+// 示例:
+//
+//	f := wordfilter.New(jcbaseGo.WordFilterStruct{Path: "./data/sensitive", ReloadInterval: 300})
+//	defer f.Close()
+//	_ = f.AddSource(wordfilter.DBSource(db.Table("sensitive_word").Where("status = ?", 1), "word", "category"))
+//
+//	if f.Contains(content) {
+//	    content = f.Replace(content)
+//	}
+type Filter struct {
+	Conf jcbaseGo.WordFilterStruct
+
+	mu      sync.Mutex
+	sources []Source
+	words   []Word // 通过 Add 添加的词
+	ac      atomic.Pointer[automaton]
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// New 创建过滤器，配置的词库路径存在时自动加载，ReloadInterval 大于0时定时重新加载
+func New(conf jcbaseGo.WordFilterStruct) *Filter {
+	_ = helper.CheckAndSetDefault(&conf)
+	f := &Filter{Conf: conf, stop: make(chan struct{})}
+	f.ac.Store(build(nil))
+
+	if conf.Path != "" {
+		if _, err := os.Stat(conf.Path); err == nil {
+			if err = f.AddSource(FileSource(conf.Path)); err != nil {
+				log.Printf("wordfilter: 加载词库 %s 失败: %v", conf.Path, err)
+			}
+		}
+	}
+	if conf.ReloadInterval > 0 {
+		go f.watch(time.Duration(conf.ReloadInterval) * time.Second)
+	}
+	return f
+}
+
+// AddSource 添加词库来源并重新构建
+func (f *Filter) AddSource(src Source) error {
+	f.mu.Lock()
+	f.sources = append(f.sources, src)
+	f.mu.Unlock()
+	return f.Reload()
+}
+
+// Add 添加敏感词，立即生效
+func (f *Filter) Add(words ...Word) {
+	f.mu.Lock()
+	f.words = append(f.words, words...)
+	f.mu.Unlock()
+	_ = f.Reload()
+}
+
+// AddWords 添加同一分类的敏感词
+func (f *Filter) AddWords(category string, words ...string) {
+	list := make([]Word, 0, len(words))
+	for _, w := range words {
+		list = append(list, Word{Text: w, Category: category})
+	}
+	f.Add(list...)
+}
+
+// Reload 从所有来源重新加载词库，任一来源加载失败时保留原有词库
+func (f *Filter) Reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	words := append([]Word(nil), f.words...)
+	for _, src := range f.sources {
+		list, err := src()
+		if err != nil {
+			return err
+		}
+		words = append(words, list...)
+	}
+	f.ac.Store(build(words))
+	return nil
+}
+
+// Len 获取词库中敏感词的数量（去重后）
+func (f *Filter) Len() int {
+	return len(f.ac.Load().words)
+}
+
+// Contains 判断文本中是否包含敏感词
+func (f *Filter) Contains(text string) bool {
+	found := false
+	f.ac.Load().match(text, func(Match) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// Check 检查文本，返回所有匹配结果，按出现位置排序，未命中时返回空切片
+func (f *Filter) Check(text string) []Match {
+	matches := make([]Match, 0)
+	f.ac.Load().match(text, func(m Match) bool {
+		matches = append(matches, m)
+		return true
+	})
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+	return matches
+}
+
+// Categories 获取文本命中的敏感词分类（去重）
+func (f *Filter) Categories(text string) []string {
+	seen := make(map[string]bool)
+	categories := make([]string, 0)
+	for _, m := range f.Check(text) {
+		if !seen[m.Category] {
+			seen[m.Category] = true
+			categories = append(categories, m.Category)
+		}
+	}
+	return categories
+}
+
+// Replace 将文本中的敏感词逐字替换为 ReplaceChar，插入的空白及符号一并替换
+func (f *Filter) Replace(text string) string {
+	return f.ReplaceFunc(text, func(m Match) string {
+		return strings.Repeat(f.Conf.ReplaceChar, len([]rune(m.Text)))
+	})
+}
+
+// ReplaceFunc 使用 fn 的返回值替换文本中的敏感词，重叠的匹配合并后按最先出现的匹配替换
+func (f *Filter) ReplaceFunc(text string, fn func(m Match) string) string {
+	matches := f.Check(text)
+	if len(matches) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.Start < last {
+			continue
+		}
+		b.WriteString(string(runes[last:m.Start]))
+		b.WriteString(fn(m))
+		last = m.End
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String()
+}
+
+// Close 停止自动加载
+func (f *Filter) Close() {
+	f.stopOnce.Do(func() { close(f.stop) })
+}
+
+// watch 定时重新加载词库
+func (f *Filter) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			if err := f.Reload(); err != nil {
+				log.Printf("wordfilter: 重新加载词库失败: %v", err)
+			}
+		}
+	}
+}
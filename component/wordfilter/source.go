@@ -0,0 +1,105 @@
+package wordfilter
+
+import (
+	"bufio"
+	"database/sql"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileSource 从文件或目录加载词库
+// 文件每行一个词，# 开头的行为注释，可使用 "词|分类" 指定分类；未指定分类时使用文件名（不含扩展名）
+// 目录下的 .txt 文件均会加载，不递归子目录
+func FileSource(path string) Source {
+	return func() ([]Word, error) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return readWordFile(path)
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var words []Word
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".txt") {
+				continue
+			}
+			list, err := readWordFile(filepath.Join(path, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, list...)
+		}
+		return words, nil
+	}
+}
+
+// readWordFile 读取词库文件
+func readWordFile(filename string) ([]Word, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	category := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	var words []Word
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		w := Word{Text: line, Category: category}
+		if text, cate, ok := strings.Cut(line, "|"); ok {
+			w.Text = strings.TrimSpace(text)
+			if cate = strings.TrimSpace(cate); cate != "" {
+				w.Category = cate
+			}
+		}
+		words = append(words, w)
+	}
+	return words, scanner.Err()
+}
+
+// DBSource 从数据库加载词库，query 为已指定表及查询条件的查询，categoryColumn 为空时不读取分类
+//
+// 示例:
+//
+//	src := wordfilter.DBSource(db.Table("sensitive_word").Where("deleted_at IS NULL"), "word", "category")
+func DBSource(query *gorm.DB, wordColumn, categoryColumn string) Source {
+	return func() ([]Word, error) {
+		columns := []string{wordColumn}
+		if categoryColumn != "" {
+			columns = append(columns, categoryColumn)
+		}
+		rows, err := query.Session(&gorm.Session{}).Select(columns).Rows()
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var words []Word
+		for rows.Next() {
+			var text, category sql.NullString
+			dest := []any{&text}
+			if categoryColumn != "" {
+				dest = append(dest, &category)
+			}
+			if err = rows.Scan(dest...); err != nil {
+				return nil, err
+			}
+			if text.String != "" {
+				words = append(words, Word{Text: text.String, Category: category.String})
+			}
+		}
+		return words, rows.Err()
+	}
+}
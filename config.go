@@ -17,6 +17,12 @@ import (
 // Config 实例化后配置信息将储存在此全局变量中
 var Config interface{}
 
+// ProfileEnv 指定配置环境的环境变量名称
+const ProfileEnv = "JC_ENV"
+
+// profile 当前配置环境
+var profile string
+
 // New 初始化配置
 func New(opt Option) *Option {
 	if opt.ConfigData != nil {
@@ -35,6 +41,14 @@ func (opt *Option) ConfigToStruct(configStruct interface{}) {
 	helper.MapToStruct(opt.ConfigData, configStruct)
 }
 
+// GetProfile 获取当前配置环境，如 dev、prod，初始化配置前或未指定时返回环境变量 JC_ENV 的值
+func GetProfile() string {
+	if profile != "" {
+		return profile
+	}
+	return strings.TrimSpace(os.Getenv(ProfileEnv))
+}
+
 // GetConfigOption 获取配置选项
 func (opt *Option) GetConfigOption() Option {
 	return *opt
@@ -60,6 +74,8 @@ func (opt *Option) checkConfig() {
 		opt.readConfigFile(fileNameFull)
 		// 配置结构体是有可能更新升级的，所以每次运行之后，应当更新一下配置文件
 		opt.updateConfigFile(fileNameFull, true)
+		// 合并配置环境的配置文件，需在更新配置文件之后，避免环境配置写入主配置文件
+		opt.applyProfile(fileNameFull)
 	case ConfigTypeCommand: // 命令行json
 		// 执行脚本并获取JSON输出
 		cmd := exec.Command("sh", "-c", opt.ConfigSource)
@@ -95,6 +111,11 @@ func (opt *Option) checkConfig() {
 		log.Panic("错误的配置类型")
 	}
 
+	// 校验配置，一次输出全部不符合约束的配置项
+	if err := helper.ValidateConfig(opt.ConfigData); err != nil {
+		log.Panic(err)
+	}
+
 	// 将配置信息写入全局变量
 	Config = opt.ConfigData
 }
@@ -130,6 +151,43 @@ func (opt *Option) initializeConfigWithDefaults() {
 	}
 }
 
+// applyProfile 按配置环境深度合并同目录下的环境配置文件，如 main.json 在 dev 环境下合并 main.dev.json
+// 环境配置文件中只需填写与主配置不同的配置项，对象递归合并，数组及其他类型直接覆盖
+func (opt *Option) applyProfile(fileNameFull string) {
+	if opt.Profile == "" {
+		opt.Profile = strings.TrimSpace(os.Getenv(ProfileEnv))
+	}
+	profile = opt.Profile
+	if opt.Profile == "" {
+		return
+	}
+
+	ext := filepath.Ext(fileNameFull)
+	profileFile := strings.TrimSuffix(fileNameFull, ext) + "." + opt.Profile + ext
+	if !helper.NewFile(&helper.File{Path: profileFile}).Exists() {
+		log.Printf("配置环境 %s 的配置文件不存在，仅使用主配置文件: %s", opt.Profile, profileFile)
+		return
+	}
+	file, err := os.ReadFile(profileFile)
+	if err != nil {
+		log.Fatalf("读取环境配置文件错误: %v", err)
+	}
+	var overlay map[string]any
+	if err = decodeJSON(file, &overlay); err != nil {
+		log.Fatalf("解析环境配置文件错误: %v\n配置文件路径：%s", err, profileFile)
+	}
+
+	data, _ := json.Marshal(opt.ConfigData)
+	var base map[string]any
+	if err = decodeJSON(data, &base); err != nil {
+		log.Fatalf("解析配置信息错误: %v", err)
+	}
+	merged, _ := json.Marshal(helper.MapMergeDeep(base, overlay))
+	if err = json.Unmarshal(merged, &opt.ConfigData); err != nil {
+		log.Fatalf("合并环境配置文件错误: %v\n配置文件路径：%s", err, profileFile)
+	}
+}
+
 func (opt *Option) getConfigFilePath() string {
 	fileNameFull, err := filepath.Abs(opt.ConfigSource)
 	if err != nil {
@@ -166,6 +224,13 @@ func (opt *Option) updateConfigFile(fileNameFull string, overwrite bool) {
 	}
 }
 
+// decodeJSON 解析json，数字保留为 json.Number，避免大整数合并后丢失精度
+func decodeJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
 // formatErrors 将 []error 格式化为单个字符串
 func formatErrors(errs []error) string {
 	var sb strings.Builder
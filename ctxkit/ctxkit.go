@@ -0,0 +1,229 @@
+// Package ctxkit 请求级数据的传递：请求ID、客户端IP、当前用户、租户及日志，
+// 同时写入 gin 上下文及请求的 context.Context，服务层、队列任务等非 HTTP 代码通过 context.Context 读取同一份数据
+//
+// 示例:
+//
+//	r.Use(middleware.Base{}.RequestID(), ctxkit.Middleware())
+//	r.Use(func(c *gin.Context) {
+//		ctxkit.SetUser(c, user)
+//		ctxkit.SetTenant(c, user.TenantID)
+//	})
+//
+//	func (s *OrderService) Create(ctx context.Context, order *Order) error {
+//		user, _ := ctxkit.UserAs[*User](ctx)
+//		ctxkit.Logger(ctx).Info("create order", "uid", user.ID, "tenant", ctxkit.Tenant(ctx))
+//		go s.notify(ctxkit.Detach(ctx), order) // 请求结束后继续使用
+//	}
+//
+//	s.Create(c, order) // *gin.Context 可直接作为 context.Context 传入
+package ctxkit
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/logger"
+	"github.com/jcbowen/jcbaseGo/middleware"
+)
+
+// gin上下文中的键名，请求ID、客户端IP沿用 middleware 写入的键名
+const (
+	RequestIDKey = middleware.RequestIDKey
+	RealIPKey    = "ClientIP"
+	UserKey      = "ctxkit:User"
+	TenantKey    = "ctxkit:Tenant"
+	LoggerKey    = "ctxkit:Logger"
+)
+
+// contextKey context.Context 中的键
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	realIPKey
+	userKey
+	tenantKey
+	loggerKey
+)
+
+// ginKeys context.Context 中的键对应的gin上下文键名
+var ginKeys = map[contextKey]string{
+	requestIDKey: RequestIDKey,
+	realIPKey:    RealIPKey,
+	userKey:      UserKey,
+	tenantKey:    TenantKey,
+	loggerKey:    LoggerKey,
+}
+
+// Middleware 将 middleware.RequestID、RealIP 写入的请求ID、客户端IP及绑定请求ID的日志写入请求的 context，
+// 需注册在 RequestID 之后
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		requestID := middleware.GetRequestID(c)
+		if requestID != "" {
+			ctx = WithRequestID(ctx, requestID)
+		}
+		ctx = WithRealIP(ctx, middleware.GetRealIP(c))
+		if _, ok := c.Get(LoggerKey); !ok && requestID != "" {
+			l := logger.Default().With("request_id", requestID)
+			c.Set(LoggerKey, l)
+			ctx = WithLogger(ctx, l)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// ----- 请求ID ----- /
+
+// WithRequestID 返回携带请求ID的 context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// SetRequestID 将请求ID写入gin上下文及请求的 context
+func SetRequestID(c *gin.Context, requestID string) {
+	set(c, requestIDKey, requestID)
+}
+
+// RequestID 获取请求ID，不存在时返回空字符串
+func RequestID(ctx context.Context) string {
+	v, _ := get[string](ctx, requestIDKey)
+	return v
+}
+
+// ----- 客户端IP ----- /
+
+// WithRealIP 返回携带客户端IP的 context
+func WithRealIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, realIPKey, ip)
+}
+
+// SetRealIP 将客户端IP写入gin上下文及请求的 context
+func SetRealIP(c *gin.Context, ip string) {
+	set(c, realIPKey, ip)
+}
+
+// RealIP 获取客户端IP，不存在时返回空字符串
+func RealIP(ctx context.Context) string {
+	v, _ := get[string](ctx, realIPKey)
+	return v
+}
+
+// ----- 当前用户 ----- /
+
+// WithUser 返回携带当前用户的 context，user 的类型由项目决定
+func WithUser(ctx context.Context, user any) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// SetUser 将当前用户写入gin上下文及请求的 context，由认证中间件在认证通过后调用
+func SetUser(c *gin.Context, user any) {
+	set(c, userKey, user)
+}
+
+// User 获取当前用户
+func User(ctx context.Context) (any, bool) {
+	return get[any](ctx, userKey)
+}
+
+// UserAs 按类型获取当前用户，未登录或类型不一致时返回 false
+//
+// 示例:
+//
+//	user, ok := ctxkit.UserAs[*model.User](ctx)
+func UserAs[T any](ctx context.Context) (T, bool) {
+	return get[T](ctx, userKey)
+}
+
+// ----- 租户 ----- /
+
+// WithTenant 返回携带租户标识的 context
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// SetTenant 将租户标识写入gin上下文及请求的 context
+func SetTenant(c *gin.Context, tenant string) {
+	set(c, tenantKey, tenant)
+}
+
+// Tenant 获取租户标识，不存在时返回空字符串
+func Tenant(ctx context.Context) string {
+	v, _ := get[string](ctx, tenantKey)
+	return v
+}
+
+// ----- 日志 ----- /
+
+// WithLogger 返回携带日志的 context
+func WithLogger(ctx context.Context, l *logger.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// SetLogger 将日志写入gin上下文及请求的 context，如追加了用户ID等字段的日志
+func SetLogger(c *gin.Context, l *logger.Logger) {
+	set(c, loggerKey, l)
+}
+
+// Logger 获取日志，不存在时返回默认日志，有请求ID时附带 request_id 字段
+func Logger(ctx context.Context) *logger.Logger {
+	if l, ok := get[*logger.Logger](ctx, loggerKey); ok && l != nil {
+		return l
+	}
+	if requestID := RequestID(ctx); requestID != "" {
+		return logger.Default().With("request_id", requestID)
+	}
+	return logger.Default()
+}
+
+// ----- 其他 ----- /
+
+// Detach 返回不随请求结束而取消的 context，保留全部请求级数据，用于请求结束后继续执行的协程、队列任务；
+// ctx 为 *gin.Context 时合并gin上下文中的数据
+func Detach(ctx context.Context) context.Context {
+	base := ctx
+	if c, ok := ctx.(*gin.Context); ok {
+		base = context.Background()
+		if c.Request != nil {
+			base = c.Request.Context()
+		}
+		for key, name := range ginKeys {
+			if v, ok := c.Get(name); ok {
+				base = context.WithValue(base, key, v)
+			}
+		}
+	}
+	return context.WithoutCancel(base)
+}
+
+// set 写入gin上下文及请求的 context
+func set(c *gin.Context, key contextKey, value any) {
+	c.Set(ginKeys[key], value)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), key, value))
+	}
+}
+
+// get 读取数据，ctx 为 *gin.Context 时先读取gin上下文，再读取请求的 context
+func get[T any](ctx context.Context, key contextKey) (T, bool) {
+	var zero T
+	if ctx == nil {
+		return zero, false
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		if v, ok := c.Get(ginKeys[key]); ok {
+			if t, ok := v.(T); ok {
+				return t, true
+			}
+		}
+		if c.Request == nil {
+			return zero, false
+		}
+		ctx = c.Request.Context()
+	}
+	if t, ok := ctx.Value(key).(T); ok {
+		return t, true
+	}
+	return zero, false
+}
@@ -0,0 +1,296 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// AppError 携带错误码、提示信息、原始错误、调用堆栈及附加信息的业务错误
+//
+// 示例:
+//
+//	user, err := findUser(id)
+//	if err != nil {
+//		return errcode.Wrap(err, errcode.DatabaseQueryError, "查询用户失败").WithMeta("uid", id)
+//	}
+//	if user == nil {
+//		return errcode.New(errcode.NotExist, "用户不存在")
+//	}
+//
+//	// 控制器中
+//	c.Error(err) // 自动根据错误码映射HTTP状态码并输出统一的响应结构
+type AppError struct {
+	Code    int            // 错误码
+	Message string         // 提示信息，会输出给客户端
+	Status  int            // HTTP状态码，为0时根据错误码自动映射，见 HTTPStatus
+	Cause   error          // 原始错误，不会输出给客户端
+	Meta    map[string]any // 附加信息，用于日志记录，不会输出给客户端
+	stack   []uintptr
+}
+
+// New 创建业务错误，message为空时使用错误码对应的默认提示
+func New(code int, message string) *AppError {
+	return newError(code, message, nil)
+}
+
+// Newf 创建业务错误，支持格式化提示信息
+func Newf(code int, format string, args ...any) *AppError {
+	return newError(code, fmt.Sprintf(format, args...), nil)
+}
+
+// Wrap 包装原始错误，err为nil时返回nil
+func Wrap(err error, code int, message string) *AppError {
+	if err == nil {
+		return nil
+	}
+	return newError(code, message, err)
+}
+
+// Wrapf 包装原始错误，支持格式化提示信息，err为nil时返回nil
+func Wrapf(err error, code int, format string, args ...any) *AppError {
+	if err == nil {
+		return nil
+	}
+	return newError(code, fmt.Sprintf(format, args...), err)
+}
+
+// FromError 将任意错误转换为业务错误
+// 错误链中已有 AppError 时直接返回，否则包装为 Unknown 错误，err为nil时返回nil
+func FromError(err error) *AppError {
+	if err == nil {
+		return nil
+	}
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return newError(Unknown, "", err)
+}
+
+// CodeOf 获取错误的错误码，err为nil时返回 Success，非 AppError 时返回 Unknown
+func CodeOf(err error) int {
+	if err == nil {
+		return Success
+	}
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return Unknown
+}
+
+// IsCode 判断错误链中是否存在指定错误码的 AppError
+func IsCode(err error, code int) bool {
+	for err != nil {
+		var appErr *AppError
+		if !errors.As(err, &appErr) {
+			return false
+		}
+		if appErr.Code == code {
+			return true
+		}
+		err = appErr.Cause
+	}
+	return false
+}
+
+func newError(code int, message string, cause error) *AppError {
+	if message == "" {
+		message = Text(code)
+	}
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	return &AppError{Code: code, Message: message, Cause: cause, stack: pcs[:n]}
+}
+
+// Error 实现error接口，包含原始错误信息
+func (e *AppError) Error() string {
+	if e.Cause == nil {
+		return fmt.Sprintf("[%d] %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("[%d] %s: %v", e.Code, e.Message, e.Cause)
+}
+
+// Unwrap 返回原始错误，支持 errors.Is/errors.As
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// Is 错误码相同即视为同一错误，可用于与预定义的错误比较
+//
+// 示例:
+//
+//	var ErrUserNotFound = errcode.New(errcode.NotExist, "用户不存在")
+//	errors.Is(err, ErrUserNotFound)
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// WithMeta 添加附加信息
+func (e *AppError) WithMeta(key string, value any) *AppError {
+	if e.Meta == nil {
+		e.Meta = make(map[string]any)
+	}
+	e.Meta[key] = value
+	return e
+}
+
+// WithStatus 指定HTTP状态码
+func (e *AppError) WithStatus(status int) *AppError {
+	e.Status = status
+	return e
+}
+
+// WithCause 指定原始错误
+func (e *AppError) WithCause(err error) *AppError {
+	e.Cause = err
+	return e
+}
+
+// HTTPStatus 获取HTTP状态码，未指定时根据错误码映射
+func (e *AppError) HTTPStatus() int {
+	if e.Status > 0 {
+		return e.Status
+	}
+	return HTTPStatus(e.Code)
+}
+
+// Stack 获取创建错误时的调用堆栈
+func (e *AppError) Stack() string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		_, _ = fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// Format 实现fmt.Formatter，%+v 时输出附加信息及调用堆栈
+func (e *AppError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, e.Error())
+			for k, v := range e.Meta {
+				_, _ = fmt.Fprintf(s, "\n%s=%v", k, v)
+			}
+			_, _ = io.WriteString(s, "\n"+e.Stack())
+			return
+		}
+		_, _ = io.WriteString(s, e.Error())
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// HTTPStatus 根据错误码映射HTTP状态码
+func HTTPStatus(code int) int {
+	switch {
+	case code == Success, code >= 2000 && code < 3000:
+		return http.StatusOK
+	case code >= 100 && code < 600:
+		return code
+	}
+
+	switch code {
+	case LoginInvalid, InvalidToken, InvalidSign, InvalidTimestamp, InvalidAuthorizationInformation, IllegalCertificate:
+		return http.StatusUnauthorized
+	case NoPermissionVisit, NoPermissionEdit, IllegalAccess, DISABLE,
+		ExpirationService, ExpirationUpgrade, ExpirationUpdate, ExpirationUse, ExpirationApi:
+		return http.StatusForbidden
+	case NotExist:
+		return http.StatusNotFound
+	case ConflictWithExisting, InoperableState:
+		return http.StatusConflict
+	case ParamError, ParamInvalid, ParamMissing, IllegalFormat, IllegalType, IllegalSize,
+		NoBindPhone, NoBindEmail, NoBindWechat, NoBindWechatMiniProgram, NoBindAlipay, NoSetPassword, NoSetPayPassword,
+		NoPreAction, NoNextAction, IncorrectUsernameOrPassword:
+		return http.StatusBadRequest
+	case NotSupported:
+		return http.StatusNotImplemented
+	case SystemBusy, UnderMaintenance:
+		return http.StatusServiceUnavailable
+	case NetworkConnectionTimeout:
+		return http.StatusGatewayTimeout
+	case NetworkError, NetworkConnectionInterrupt, NetworkConnectionRefused, NetworkConnectionReset:
+		return http.StatusBadGateway
+	}
+
+	switch code / 1000 {
+	case 9002:
+		return http.StatusBadRequest
+	case 9003:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// Text 获取错误码对应的默认提示信息
+func Text(code int) string {
+	if text, ok := texts[code]; ok {
+		return text
+	}
+	if text := http.StatusText(code); text != "" {
+		return text
+	}
+	return "未知错误"
+}
+
+// texts 错误码默认提示信息
+var texts = map[int]string{
+	Success:                     "操作成功",
+	Unknown:                     "未知错误",
+	BadRequest:                  "请求无效",
+	Unauthorized:                "未授权",
+	Forbidden:                   "拒绝访问",
+	NotFound:                    "请求的资源不存在",
+	MethodNotAllowed:            "请求方法不被允许",
+	Conflict:                    "请求冲突",
+	SuccessChange:               "保存成功",
+	SuccessQueue:                "已进入后台队列",
+	SuccessDelete:               "删除成功",
+	SystemBusy:                  "系统繁忙，请稍后再试",
+	UnderMaintenance:            "系统维护中",
+	InvalidConfig:               "无效的配置",
+	NoConfig:                    "没有配置",
+	LostConnection:              "失去连接",
+	InvalidSign:                 "无效的签名",
+	InvalidTimestamp:            "无效的时间戳",
+	LoginInvalid:                "登录失效，请重新登录",
+	InvalidToken:                "无效的token",
+	NoPermissionVisit:           "无权访问",
+	NoPermissionEdit:            "无权编辑",
+	IncorrectUsernameOrPassword: "账号或密码错误",
+	DISABLE:                     "已被禁用",
+	ParamError:                  "参数错误",
+	ParamInvalid:                "无效参数",
+	ParamMissing:                "参数缺失",
+	NotExist:                    "不存在或已被删除",
+	ConflictWithExisting:        "和已有数据冲突",
+	IllegalAccess:               "不合法的访问",
+	IllegalFormat:               "不合法的格式",
+	IllegalType:                 "不合法的类型",
+	IllegalSize:                 "不合法的大小",
+	IllegalCertificate:          "不合法的凭证",
+	ExpirationService:           "服务已到期",
+	InoperableState:             "当前状态不可操作",
+	NotSupported:                "暂不支持",
+	StorageError:                "数据存储错误",
+	DatabaseError:               "数据库错误",
+	NetworkError:                "网络错误",
+	Other:                       "其他错误",
+}
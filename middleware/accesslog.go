@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RequestIDHeader 请求ID的请求/响应头名称
+const RequestIDHeader = "X-Request-Id"
+
+// RequestIDKey 请求ID在gin上下文中的键名，调试器等组件可通过该键名共享请求ID
+const RequestIDKey = "RequestID"
+
+// AccessLogEntry 访问日志条目
+type AccessLogEntry struct {
+	Time      string  `json:"time"`       // 请求时间
+	RequestID string  `json:"request_id"` // 请求ID
+	ClientIP  string  `json:"client_ip"`  // 客户端真实IP
+	Method    string  `json:"method"`     // 请求方法
+	Path      string  `json:"path"`       // 请求路径
+	Query     string  `json:"query"`      // 查询参数
+	Status    int     `json:"status"`     // 响应状态码
+	Bytes     int     `json:"bytes"`      // 响应字节数
+	Latency   float64 `json:"latency_ms"` // 耗时，单位毫秒
+	UserAgent string  `json:"user_agent"` // UA
+	Referer   string  `json:"referer"`    // 来源页面
+	Error     string  `json:"error,omitempty"`
+}
+
+// RequestID 为每个请求生成（或沿用请求头中的）请求ID，
+// 写入gin上下文及响应头，供访问日志、调试器等组件共享
+func (b Base) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ensureRequestID(c)
+		c.Next()
+	}
+}
+
+// GetRequestID 获取当前请求的请求ID
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+// AccessLog 输出JSON格式的访问日志
+//
+// 参数:
+//   - conf (可选): 访问日志配置，不传时输出到标准输出
+//
+// 示例:
+//
+//	r.Use(middleware.Base{}.RealIP(true), middleware.Base{}.AccessLog(jcbaseGo.AccessLogStruct{
+//	    Output:   "file",
+//	    FilePath: "./runtime/log/access.log",
+//	}))
+func (b Base) AccessLog(conf ...jcbaseGo.AccessLogStruct) gin.HandlerFunc {
+	var logConf jcbaseGo.AccessLogStruct
+	if len(conf) > 0 {
+		logConf = conf[0]
+	}
+	_ = helper.CheckAndSetDefault(&logConf)
+
+	var writer io.Writer = os.Stdout
+	if logConf.Output == "file" {
+		writer = helper.NewRotateWriter(logConf.FilePath, int64(logConf.MaxSize)*1024*1024, logConf.MaxBackups, logConf.Daily)
+	}
+
+	return AccessLogWithWriter(writer)
+}
+
+// AccessLogWithWriter 输出JSON格式的访问日志到指定的writer
+func AccessLogWithWriter(writer io.Writer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := ensureRequestID(c)
+
+		c.Next()
+
+		clientIP := c.GetString("ClientIP")
+		if clientIP == "" {
+			clientIP = c.ClientIP()
+		}
+
+		entry := AccessLogEntry{
+			Time:      start.Format("2006-01-02 15:04:05"),
+			RequestID: requestID,
+			ClientIP:  clientIP,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Query:     c.Request.URL.RawQuery,
+			Status:    c.Writer.Status(),
+			Bytes:     max(c.Writer.Size(), 0),
+			Latency:   float64(time.Since(start).Microseconds()) / 1000,
+			UserAgent: c.Request.UserAgent(),
+			Referer:   c.Request.Referer(),
+			Error:     c.Errors.ByType(gin.ErrorTypePrivate).String(),
+		}
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Println("访问日志序列化失败:", err)
+			return
+		}
+		if _, err = fmt.Fprintln(writer, string(line)); err != nil {
+			log.Println("访问日志写入失败:", err)
+		}
+	}
+}
+
+// ensureRequestID 获取请求ID，不存在时生成一个新的
+func ensureRequestID(c *gin.Context) string {
+	if requestID := c.GetString(RequestIDKey); requestID != "" {
+		return requestID
+	}
+
+	requestID := c.GetHeader(RequestIDHeader)
+	if requestID == "" || len(requestID) > 64 {
+		requestID = strconv.FormatInt(time.Now().UnixNano(), 36) + helper.Random(10)
+	}
+
+	c.Set(RequestIDKey, requestID)
+	c.Header(RequestIDHeader, requestID)
+	return requestID
+}
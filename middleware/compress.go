@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var defaultCompressTypes = []string{
+	"text/*", "application/json", "application/javascript", "application/xml", "application/xhtml+xml",
+	"application/rss+xml", "application/atom+xml", "application/problem+json", "image/svg+xml",
+}
+
+// 编码方式
+const (
+	encodingGzip   = "gzip"
+	encodingBrotli = "br"
+)
+
+// Compress 根据请求的 Accept-Encoding 使用 brotli 或 gzip 压缩响应
+//
+// 参数:
+//   - conf (可选): 压缩配置，不传时压缩大于1KB的文本、JSON等响应
+//
+// 响应体先缓冲到 MinSize 再决定是否压缩；已设置 Content-Encoding、状态码为 204/206/304 及 HEAD 请求不压缩，
+// 压缩时会移除 Content-Length 并将 ETag 转为弱校验。处理函数调用 c.Writer.Flush() 时会同步刷新压缩数据，可用于流式输出。
+// 需放在 Recovery、AccessLog 之后，以便记录实际输出的状态码及大小
+//
+// 示例:
+//
+//	r.Use(middleware.Base{}.Compress(jcbaseGo.CompressStruct{
+//	    MinSize:      2048,
+//	    ExcludePaths: []string{"/api/download"},
+//	}))
+func (b Base) Compress(conf ...jcbaseGo.CompressStruct) gin.HandlerFunc {
+	var compressConf jcbaseGo.CompressStruct
+	if len(conf) > 0 {
+		compressConf = conf[0]
+	}
+	_ = helper.CheckAndSetDefault(&compressConf)
+
+	if len(compressConf.ContentTypes) == 0 {
+		compressConf.ContentTypes = defaultCompressTypes
+	}
+	gzipLevel := min(max(compressConf.GzipLevel, gzip.BestSpeed), gzip.BestCompression)
+	brotliLevel := min(max(compressConf.BrotliLevel, brotli.BestSpeed), brotli.BestCompression)
+
+	gzipPool := &sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzipLevel)
+		return w
+	}}
+	brotliPool := &sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotliLevel)
+	}}
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		for _, prefix := range compressConf.ExcludePaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), !compressConf.DisableBrotli)
+		c.Header("Vary", "Accept-Encoding")
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			conf:           &compressConf,
+			gzipPool:       gzipPool,
+			brotliPool:     brotliPool,
+		}
+		c.Writer = cw
+		completed := false
+		defer func() {
+			// 处理函数panic且尚未输出时交由 Recovery 直接输出未压缩的响应
+			if completed || cw.written {
+				cw.close()
+			}
+			if c.Writer == cw {
+				c.Writer = cw.ResponseWriter
+			}
+		}()
+
+		c.Next()
+		completed = true
+	}
+}
+
+// negotiateEncoding 根据 Accept-Encoding 选择编码方式，优先 brotli
+func negotiateEncoding(accept string, allowBrotli bool) string {
+	var gzipQ, brotliQ float64 = -1, -1
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case encodingGzip:
+			gzipQ = q
+		case encodingBrotli:
+			brotliQ = q
+		}
+	}
+	switch {
+	case allowBrotli && brotliQ > 0 && brotliQ >= gzipQ:
+		return encodingBrotli
+	case gzipQ > 0:
+		return encodingGzip
+	default:
+		return ""
+	}
+}
+
+// matchContentType Content-Type 是否在列表中，支持 text/* 形式的通配
+func matchContentType(contentType string, types []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range types {
+		if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter 缓冲响应体直到可以决定是否压缩
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding   string
+	conf       *jcbaseGo.CompressStruct
+	gzipPool   *sync.Pool
+	brotliPool *sync.Pool
+
+	buf     []byte
+	written bool // 处理函数是否已输出
+	decided bool // 是否已决定压缩与否并输出了响应头
+	encoder io.WriteCloser
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.written = true
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.conf.MinSize {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow 立即输出响应头，此时按已缓冲的内容决定是否压缩
+func (w *compressWriter) WriteHeaderNow() {
+	w.written = true
+	if !w.decided {
+		_ = w.decide()
+	}
+}
+
+func (w *compressWriter) Written() bool {
+	return w.written || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// decide 决定是否压缩，输出响应头及已缓冲的内容
+func (w *compressWriter) decide() error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	status := w.ResponseWriter.Status()
+	if len(w.buf) >= w.conf.MinSize && header.Get("Content-Encoding") == "" &&
+		status != http.StatusNoContent && status != http.StatusPartialContent && status != http.StatusNotModified &&
+		matchContentType(header.Get("Content-Type"), w.conf.ContentTypes) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			header.Set("ETag", "W/"+etag)
+		}
+		if w.encoding == encodingBrotli {
+			bw := w.brotliPool.Get().(*brotli.Writer)
+			bw.Reset(w.ResponseWriter)
+			w.encoder = bw
+		} else {
+			gw := w.gzipPool.Get().(*gzip.Writer)
+			gw.Reset(w.ResponseWriter)
+			w.encoder = gw
+		}
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return nil
+	}
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close 输出剩余的缓冲内容并结束压缩
+func (w *compressWriter) close() {
+	if !w.decided && w.written {
+		_ = w.decide()
+	}
+	if w.encoder == nil {
+		return
+	}
+	_ = w.encoder.Close()
+	switch enc := w.encoder.(type) {
+	case *gzip.Writer:
+		enc.Reset(io.Discard)
+		w.gzipPool.Put(enc)
+	case *brotli.Writer:
+		enc.Reset(io.Discard)
+		w.brotliPool.Put(enc)
+	}
+	w.encoder = nil
+}
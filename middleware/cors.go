@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	defaultCorsMethods = []string{"POST", "GET", "OPTIONS", "PUT", "DELETE", "UPDATE"}
+	defaultCorsHeaders = []string{
+		"Authorization", "Content-Length", "X-CSRF-Token", "Token", "session", "X_Requested_With", "Accept", "Origin",
+		"Host", "Connection", "Accept-Encoding", "Accept-Language", "DNT", "X-CustomHeader", "Keep-Alive", "User-Agent",
+		"X-Requested-With", "If-Modified-Since", "Cache-Control", "Content-Type", "Pragma", "Code",
+	}
+	defaultCorsExposeHeaders = []string{
+		"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Cache-Control",
+		"Content-Language", "Content-Type", "Expires", "Last-Modified", "Pragma",
+	}
+)
+
+// Cors 跨域处理
+//
+// 参数:
+//   - conf (可选): 跨域配置，不传时允许所有来源且不允许携带凭证
+//
+// 只有匹配 AllowOrigins 的来源才会输出跨域响应头，不匹配的预检请求将返回403；
+// 开启 AllowCredentials 时，"*" 不会匹配任何来源，避免将任意来源与凭证一并放行。
+//
+// 示例:
+//
+//	r.Use(middleware.Base{}.Cors(jcbaseGo.CorsStruct{
+//	    AllowOrigins:     []string{"https://admin.example.com", "https://*.example.com"},
+//	    AllowCredentials: true,
+//	}))
+func (b Base) Cors(conf ...jcbaseGo.CorsStruct) gin.HandlerFunc {
+	var corsConf jcbaseGo.CorsStruct
+	if len(conf) > 0 {
+		corsConf = conf[0]
+	}
+	_ = helper.CheckAndSetDefault(&corsConf)
+
+	if len(corsConf.AllowOrigins) == 0 {
+		corsConf.AllowOrigins = []string{"*"}
+	}
+	if len(corsConf.AllowMethods) == 0 {
+		corsConf.AllowMethods = defaultCorsMethods
+	}
+	if len(corsConf.AllowHeaders) == 0 {
+		corsConf.AllowHeaders = defaultCorsHeaders
+	}
+	if len(corsConf.ExposeHeaders) == 0 {
+		corsConf.ExposeHeaders = defaultCorsExposeHeaders
+	}
+
+	allowMethods := strings.Join(corsConf.AllowMethods, ", ")
+	allowHeaders := strings.Join(corsConf.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(corsConf.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(corsConf.MaxAge)
+	allowCredentials := strconv.FormatBool(corsConf.AllowCredentials)
+
+	return func(c *gin.Context) {
+		origin := c.Request.Header.Get("Origin")
+		isPreflight := c.Request.Method == http.MethodOptions
+
+		// 非跨域请求直接放行
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		if !isOriginAllowed(origin, corsConf) {
+			if isPreflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Allow-Methods", allowMethods)
+		c.Header("Access-Control-Allow-Headers", allowHeaders)
+		c.Header("Access-Control-Expose-Headers", exposeHeaders)
+		c.Header("Access-Control-Max-Age", maxAge)
+		c.Header("Access-Control-Allow-Credentials", allowCredentials)
+		// 响应内容随Origin变化，避免被缓存服务器错误复用
+		c.Writer.Header().Add("Vary", "Origin")
+
+		// 放行所有OPTIONS方法
+		if isPreflight && !corsConf.OptionsPassThrough {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		// 处理请求
+		c.Next()
+	}
+}
+
+// isOriginAllowed 判断来源是否在允许列表中
+func isOriginAllowed(origin string, conf jcbaseGo.CorsStruct) bool {
+	for _, allowOrigin := range conf.AllowOrigins {
+		if allowOrigin == "*" {
+			if conf.AllowCredentials {
+				// 允许携带凭证时不接受任意来源
+				continue
+			}
+			return true
+		}
+		if strings.EqualFold(allowOrigin, origin) {
+			return true
+		}
+		if strings.Contains(allowOrigin, "*") && matchOriginPattern(allowOrigin, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOriginPattern 按通配规则匹配来源，协议、端口需完全一致，"*" 只匹配主机名中的一级（不含"."）
+func matchOriginPattern(pattern, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" || u.User != nil || u.Opaque != "" || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+	scheme, host, ok := strings.Cut(pattern, "://")
+	if !ok || !strings.EqualFold(scheme, u.Scheme) {
+		return false
+	}
+	// 通配符不能出现在端口中，端口之后也不能有路径
+	port := ""
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host, port = host[:i], host[i+1:]
+	}
+	if strings.ContainsAny(port, "*/") || port != u.Port() {
+		return false
+	}
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(host), `\*`, `[^.]+`) + "$"
+	re, err := regexp.Compile("(?i)" + expr)
+	return err == nil && re.MatchString(u.Hostname())
+}
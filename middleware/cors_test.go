@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/jcbowen/jcbaseGo"
+	"testing"
+)
+
+func TestIsOriginAllowedWildcard(t *testing.T) {
+	conf := jcbaseGo.CorsStruct{AllowOrigins: []string{"https://*.example.com", "http://localhost:*8"}}
+	cases := map[string]bool{
+		"https://a.example.com":               true,
+		"https://A.Example.com":               true,
+		"https://a.b.example.com":             false,
+		"https://a.example.com:8443":          false,
+		"http://a.example.com":                false,
+		"https://.example.com":                false,
+		"https://example.com":                 false,
+		"https://evil.com?.example.com":       false,
+		"https://evil.com#.example.com":       false,
+		"https://evil.com/.example.com":       false,
+		"https://user@a.example.com":          false,
+		"https://a.example.com.evil.com":      false,
+		"https://evilexample.com":             false,
+		"http://localhost:8":                  false, // 端口不能使用通配符
+		"https://a.example.com/path":          false,
+		"https://a-b.example.com":             true,
+		"https://xn--fiq228c.example.com":     true,
+		"javascript://a.example.com%0aalert1": false,
+	}
+	for origin, want := range cases {
+		if got := isOriginAllowed(origin, conf); got != want {
+			t.Errorf("isOriginAllowed(%q) = %v, want %v", origin, got, want)
+		}
+	}
+
+	exact := jcbaseGo.CorsStruct{AllowOrigins: []string{"https://*.example.com:8443"}}
+	if !isOriginAllowed("https://a.example.com:8443", exact) || isOriginAllowed("https://a.example.com", exact) {
+		t.Error("port should be compared exactly")
+	}
+}
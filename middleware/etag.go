@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"bufio"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ETag 为 GET/HEAD 请求的响应生成ETag，并处理 If-None-Match、If-Modified-Since 条件请求，未变化时返回 304
+//
+// 参数:
+//   - conf (可选): ETag配置，不传时为 JSON 响应生成强校验的ETag
+//
+// 只处理状态码为 200 的响应；处理函数已设置 ETag 时沿用，已设置 Last-Modified 时同时支持 If-Modified-Since。
+// 可按路由分组分别使用不同的配置；与 Compress 同时使用时应放在其后，压缩后强校验的ETag会被转为弱校验
+//
+// 示例:
+//
+//	api := r.Group("/api", middleware.Base{}.ETag())
+//	poll := r.Group("/poll", middleware.Base{}.ETag(jcbaseGo.ETagStruct{Weak: true}))
+func (b Base) ETag(conf ...jcbaseGo.ETagStruct) gin.HandlerFunc {
+	var etagConf jcbaseGo.ETagStruct
+	if len(conf) > 0 {
+		etagConf = conf[0]
+	}
+	_ = helper.CheckAndSetDefault(&etagConf)
+
+	if len(etagConf.ContentTypes) == 0 {
+		etagConf.ContentTypes = []string{"application/json"}
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		ew := &etagWriter{ResponseWriter: c.Writer, maxSize: etagConf.MaxSize}
+		c.Writer = ew
+		completed := false
+		defer func() {
+			if c.Writer == ew {
+				c.Writer = ew.ResponseWriter
+			}
+			// 处理函数panic时丢弃已缓冲的内容，交由 Recovery 输出
+			if completed {
+				ew.finish(c.Request, etagConf)
+			}
+		}()
+
+		c.Next()
+		completed = true
+	}
+}
+
+// etagWriter 缓冲响应体用于计算ETag，超过 maxSize 或调用 Flush 时转为直接输出
+type etagWriter struct {
+	gin.ResponseWriter
+	maxSize     int
+	buf         []byte
+	passthrough bool
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	if len(w.buf)+len(data) > w.maxSize {
+		if err := w.flushBuffer(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(data)
+	}
+	w.buf = append(w.buf, data...)
+	return len(data), nil
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *etagWriter) WriteHeaderNow() {
+	_ = w.flushBuffer()
+}
+
+func (w *etagWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *etagWriter) Flush() {
+	_ = w.flushBuffer()
+	w.ResponseWriter.Flush()
+}
+
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.passthrough, w.buf = true, nil
+	return w.ResponseWriter.Hijack()
+}
+
+// flushBuffer 输出已缓冲的内容并转为直接输出
+func (w *etagWriter) flushBuffer() error {
+	if w.passthrough {
+		return nil
+	}
+	w.passthrough = true
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// finish 生成ETag并判断条件请求
+func (w *etagWriter) finish(r *http.Request, conf jcbaseGo.ETagStruct) {
+	if w.passthrough {
+		return
+	}
+	header := w.ResponseWriter.Header()
+	if w.ResponseWriter.Status() != http.StatusOK || len(w.buf) == 0 ||
+		(header.Get("ETag") == "" && !matchContentType(header.Get("Content-Type"), conf.ContentTypes)) {
+		_ = w.flushBuffer()
+		return
+	}
+
+	etag := header.Get("ETag")
+	if etag == "" {
+		etag = makeETag(w.buf, conf.Weak)
+		header.Set("ETag", etag)
+	}
+	if !notModified(r, etag, header.Get("Last-Modified")) {
+		_ = w.flushBuffer()
+		return
+	}
+
+	// 304 响应不包含响应体，移除描述响应体的头部
+	for _, key := range []string{"Content-Type", "Content-Length", "Content-Encoding", "Transfer-Encoding"} {
+		header.Del(key)
+	}
+	w.buf = nil
+	w.ResponseWriter.WriteHeader(http.StatusNotModified)
+	_ = w.flushBuffer()
+}
+
+// makeETag 根据内容生成ETag，格式为 "长度-FNV哈希"
+func makeETag(body []byte, weak bool) string {
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	etag := `"` + strconv.FormatInt(int64(len(body)), 16) + "-" + strconv.FormatUint(h.Sum64(), 16) + `"`
+	if weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// notModified 判断条件请求是否命中，If-None-Match 存在时忽略 If-Modified-Since
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakMatch(candidate, etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// weakMatch 弱比较，忽略 W/ 前缀
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
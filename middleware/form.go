@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"mime/multipart"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parseFormValues 将表单参数解析为map，支持PHP风格的嵌套键名
+//
+// 解析规则（与PHP的$_POST保持一致）：
+//   - name=a                        => {"name": "a"}
+//   - tags[]=a&tags[]=b             => {"tags": []string{"a", "b"}}
+//   - user[name]=a                  => {"user": {"name": "a"}}
+//   - items[0][id]=1&items[1][id]=2 => {"items": []any{{"id": "1"}, {"id": "2"}}}
+//   - items[][id]=1&items[][id]=2   => {"items": []any{{"id": "1"}, {"id": "2"}}}
+//
+// files 为multipart上传的文件，按同样的规则合并到结果中（可为nil）；
+// 键名全部为从0开始的连续数字的嵌套map会被转换为切片
+func parseFormValues(form map[string][]string, files map[string][]*multipart.FileHeader) map[string]any {
+	result := make(map[string]any)
+	// 各容器下一个可用的数字下标，按容器路径记录，避免每次追加元素都遍历容器
+	next := make(map[string]int)
+
+	for _, key := range sortedKeys(form) {
+		values := form[key]
+		if len(values) == 0 {
+			continue
+		}
+
+		segments := parseNestedKey(key)
+		switch {
+		case len(segments) == 1:
+			result[key] = values[0]
+		case len(segments) == 2 && segments[1] == "":
+			// 兼容原有的 key[] 写法，直接输出为字符串切片
+			result[segments[0]] = values
+		case strings.Contains(key, "[]"):
+			// 键名中存在[]时，每个值都生成一个新的元素
+			for _, value := range values {
+				setNestedValue(result, "", segments, value, next)
+			}
+		default:
+			setNestedValue(result, "", segments, values[0], next)
+		}
+	}
+
+	for _, key := range sortedKeys(files) {
+		fileHeaders := files[key]
+		if len(fileHeaders) == 0 {
+			continue
+		}
+
+		segments := parseNestedKey(key)
+		switch {
+		case len(segments) == 1:
+			result[key] = fileHeaders[0]
+		case len(segments) == 2 && segments[1] == "":
+			result[segments[0]] = fileHeaders
+		case strings.Contains(key, "[]"):
+			for _, fileHeader := range fileHeaders {
+				setNestedValue(result, "", segments, fileHeader, next)
+			}
+		default:
+			setNestedValue(result, "", segments, fileHeaders[0], next)
+		}
+	}
+
+	for key, value := range result {
+		result[key] = normalizeNestedValue(value)
+	}
+
+	return result
+}
+
+// sortedKeys 获取排序后的键名，保证带[]的键名生成的下标稳定
+func sortedKeys[T any](data map[string]T) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setNestedValue 按键名路径将值写入嵌套map，空路径段表示追加新元素；
+// path 为容器的路径，next 记录各容器下一个可用的数字下标
+func setNestedValue(container map[string]any, path string, segments []string, value any, next map[string]int) {
+	segment := segments[0]
+	if segment == "" {
+		index, ok := next[path]
+		if !ok {
+			index = nextNestedIndex(container)
+		}
+		segment = strconv.Itoa(index)
+		next[path] = index + 1
+	} else if index, err := strconv.Atoi(segment); err == nil {
+		// 与PHP一致，显式的数字下标同样影响之后追加元素的下标
+		if current, ok := next[path]; ok && index >= current {
+			next[path] = index + 1
+		}
+	}
+
+	if len(segments) == 1 {
+		container[segment] = value
+		return
+	}
+
+	child, ok := container[segment].(map[string]any)
+	if !ok {
+		child = make(map[string]any)
+		container[segment] = child
+		// 新的容器没有元素，下标从0开始
+		next[path+"\x00"+segment] = 0
+	}
+	setNestedValue(child, path+"\x00"+segment, segments[1:], value, next)
+}
+
+// parseNestedKey 将 items[0][id] 形式的键名拆分为 ["items", "0", "id"]，
+// 不合法的键名按原样作为一个整体返回
+func parseNestedKey(key string) []string {
+	start := strings.Index(key, "[")
+	if start <= 0 {
+		return []string{key}
+	}
+
+	segments := []string{key[:start]}
+	rest := key[start:]
+	for len(rest) > 0 {
+		if rest[0] != '[' {
+			// 与PHP一致，忽略最后一个]之后的内容
+			break
+		}
+		end := strings.Index(rest, "]")
+		if end == -1 {
+			return []string{key}
+		}
+		segments = append(segments, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	return segments
+}
+
+// nextNestedIndex 获取map中下一个可用的数字下标
+func nextNestedIndex(container map[string]any) int {
+	next := 0
+	for key := range container {
+		if index, err := strconv.Atoi(key); err == nil && index >= next {
+			next = index + 1
+		}
+	}
+	return next
+}
+
+// normalizeNestedValue 将键名为连续数字的map递归转换为切片
+func normalizeNestedValue(value any) any {
+	mapValue, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+
+	for key, item := range mapValue {
+		mapValue[key] = normalizeNestedValue(item)
+	}
+
+	list := make([]any, len(mapValue))
+	for key, item := range mapValue {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(mapValue) || strconv.Itoa(index) != key {
+			return mapValue
+		}
+		list[index] = item
+	}
+
+	return list
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFormValuesNested(t *testing.T) {
+	got := parseFormValues(map[string][]string{
+		"name":        {"a"},
+		"tags[]":      {"x", "y"},
+		"user[name]":  {"bob"},
+		"items[][id]": {"1", "2"},
+		"rows[0][id]": {"10"},
+		"rows[1][id]": {"11"},
+	}, nil)
+	want := map[string]any{
+		"name":  "a",
+		"tags":  []string{"x", "y"},
+		"user":  map[string]any{"name": "bob"},
+		"items": []any{map[string]any{"id": "1"}, map[string]any{"id": "2"}},
+		"rows":  []any{map[string]any{"id": "10"}, map[string]any{"id": "11"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestParseFormValuesAppendAfterExplicitIndex(t *testing.T) {
+	got := parseFormValues(map[string][]string{
+		"a[list][3]":   {"three"},
+		"a[list][][x]": {"four"},
+	}, nil)
+	list, ok := got["a"].(map[string]any)["list"].(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected result: %#v", got)
+	}
+	if list["3"] != "three" || !reflect.DeepEqual(list["4"], map[string]any{"x": "four"}) {
+		t.Fatalf("list = %#v, want the appended element at index 4", list)
+	}
+}
+
+func TestParseFormValuesManyAppendsIsLinear(t *testing.T) {
+	const n = 100000
+	values := make([]string, n)
+	for i := range values {
+		values[i] = "v"
+	}
+	start := time.Now()
+	got := parseFormValues(map[string][]string{"a[][x]": values}, nil)
+	elapsed := time.Since(start)
+	if items, ok := got["a"].([]any); !ok || len(items) != n {
+		t.Fatalf("items = %d", len(got["a"].([]any)))
+	}
+	// 每次追加都遍历容器时需要数十秒
+	if elapsed > 5*time.Second {
+		t.Fatalf("parsing %d appended elements took %s", n, elapsed)
+	}
+}
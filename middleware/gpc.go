@@ -0,0 +1,289 @@
+package middleware
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"io"
+	"mime/multipart"
+	"reflect"
+	"strings"
+)
+
+// GPC 请求参数集合，key为参数来源（query/header/cookie/data/all）
+type GPC map[string]map[string]any
+
+// GetGPC 从gin上下文中获取SetGPC解析好的参数集合
+func GetGPC(c *gin.Context) GPC {
+	gpcInterface, exists := c.Get("GPC")
+	if !exists {
+		return GPC{}
+	}
+	gpcMap, ok := gpcInterface.(map[string]map[string]any)
+	if !ok {
+		return GPC{}
+	}
+	return GPC(gpcMap)
+}
+
+// BindGPC 将gin上下文中的GPC参数绑定到结构体中
+func BindGPC(c *gin.Context, obj any) error {
+	return GetGPC(c).Bind(obj)
+}
+
+// Bind 将GPC参数绑定到结构体中
+//
+// 字段通过 gpc 标签指定参数名及来源，格式为 `gpc:"name,source"`：
+//   - name 参数名，为空时依次尝试json标签、字段名
+//   - source 参数来源，可选 query/header/cookie/data/all，默认为 all
+//   - `gpc:"-"` 表示忽略该字段
+//
+// 参数值不存在时，使用字段的 default 标签作为默认值；
+// 参数值通过 helper.Convert 转换为字段对应的类型。
+//
+// 示例:
+//
+//	type ListReq struct {
+//	    Page     int    `gpc:"page,query" default:"1"`
+//	    Keyword  string `json:"keyword"`
+//	    Token    string `gpc:"Authorization,header"`
+//	}
+//	var req ListReq
+//	err := middleware.GetGPC(c).Bind(&req)
+func (g GPC) Bind(obj any) error {
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("obj must be a non-nil pointer")
+	}
+	val = val.Elem()
+	if val.Kind() != reflect.Struct {
+		return errors.New("obj must be a pointer to a struct")
+	}
+	return g.bindStruct(val)
+}
+
+// Get 获取指定来源的参数值，source为空时从all中获取
+func (g GPC) Get(key string, source ...string) (any, bool) {
+	src := "all"
+	if len(source) > 0 && source[0] != "" {
+		src = source[0]
+	}
+	data, ok := g[src]
+	if !ok {
+		return nil, false
+	}
+	value, ok := data[key]
+	return value, ok
+}
+
+// bindStruct 遍历结构体字段并赋值
+func (g GPC) bindStruct(val reflect.Value) error {
+	valType := val.Type()
+	for i := 0; i < valType.NumField(); i++ {
+		field := valType.Field(i)
+		fieldVal := val.Field(i)
+		if !fieldVal.CanSet() {
+			continue
+		}
+
+		name, source := parseGPCTag(field)
+		if name == "-" {
+			continue
+		}
+
+		// 匿名嵌套结构体，将其字段视为当前结构体的字段
+		if field.Anonymous && fieldVal.Kind() == reflect.Struct && field.Tag.Get("gpc") == "" {
+			if err := g.bindStruct(fieldVal); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := g.Get(name, source)
+		if !ok || value == nil {
+			defaultValue, hasDefault := field.Tag.Lookup("default")
+			if !hasDefault || !helper.IsEmptyValue(fieldVal.Interface()) {
+				continue
+			}
+			value = defaultValue
+		}
+
+		if err := setGPCValue(fieldVal, value); err != nil {
+			return fmt.Errorf("参数 %s 绑定失败: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// parseGPCTag 解析字段的gpc标签，返回参数名及来源
+func parseGPCTag(field reflect.StructField) (name, source string) {
+	tag := field.Tag.Get("gpc")
+	if tag != "" {
+		parts := strings.Split(tag, ",")
+		name = strings.TrimSpace(parts[0])
+		if len(parts) > 1 {
+			source = strings.TrimSpace(parts[1])
+		}
+	}
+	if name == "" {
+		jsonTag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonTag != "" && jsonTag != "-" {
+			name = jsonTag
+		} else {
+			name = field.Name
+		}
+	}
+	if source == "" {
+		source = "all"
+	}
+	return
+}
+
+// setGPCValue 根据字段类型将参数值转换后赋值
+func setGPCValue(fieldVal reflect.Value, value any) error {
+	// null 值（如 JSON 数组、对象中的 null 元素）设为零值
+	if value == nil {
+		fieldVal.Set(reflect.Zero(fieldVal.Type()))
+		return nil
+	}
+
+	// 类型一致（或可直接赋值）时直接赋值
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(fieldVal.Type()) {
+		fieldVal.Set(rv)
+		return nil
+	}
+
+	switch fieldVal.Kind() {
+	case reflect.Ptr:
+		if _, ok := value.(*multipart.FileHeader); ok {
+			return fmt.Errorf("类型不匹配，期望 %s", fieldVal.Type())
+		}
+		elem := reflect.New(fieldVal.Type().Elem())
+		if err := setGPCValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		fieldVal.Set(elem)
+	case reflect.String:
+		fieldVal.SetString(helper.Convert{Value: value}.ToString())
+	case reflect.Bool:
+		fieldVal.SetBool(helper.Convert{Value: value}.ToBool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fieldVal.SetInt(helper.Convert{Value: value}.ToInt64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fieldVal.SetUint(helper.Convert{Value: value}.ToUint64())
+	case reflect.Float32, reflect.Float64:
+		fieldVal.SetFloat(helper.Convert{Value: value}.ToFloat64())
+	case reflect.Slice:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			// 单个值视为只有一个元素的切片，兼容 ids=1 与 ids[]=1&ids[]=2 两种传参
+			items = reflect.ValueOf([]any{value})
+		}
+		slice := reflect.MakeSlice(fieldVal.Type(), items.Len(), items.Len())
+		for i := 0; i < items.Len(); i++ {
+			if err := setGPCValue(slice.Index(i), items.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		fieldVal.Set(slice)
+	case reflect.Map:
+		mapData, ok := value.(map[string]any)
+		if !ok || fieldVal.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("类型不匹配，期望 %s", fieldVal.Type())
+		}
+		newMap := reflect.MakeMapWithSize(fieldVal.Type(), len(mapData))
+		for k, v := range mapData {
+			elem := reflect.New(fieldVal.Type().Elem()).Elem()
+			if err := setGPCValue(elem, v); err != nil {
+				return err
+			}
+			newMap.SetMapIndex(reflect.ValueOf(k).Convert(fieldVal.Type().Key()), elem)
+		}
+		fieldVal.Set(newMap)
+	case reflect.Struct:
+		mapData, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("类型不匹配，期望 %s", fieldVal.Type())
+		}
+		return GPC{"all": mapData}.bindStruct(fieldVal)
+	case reflect.Interface:
+		fieldVal.Set(rv)
+	default:
+		return fmt.Errorf("不支持的字段类型：%s", fieldVal.Kind())
+	}
+	return nil
+}
+
+// parseXMLToMap 将xml请求体解析为map（如微信消息推送），
+// 根节点下的子节点作为key，存在子节点的节点会被解析为嵌套map，同名节点会被解析为切片
+func parseXMLToMap(r io.Reader) (map[string]any, error) {
+	decoder := xml.NewDecoder(r)
+
+	// 跳过根节点之前的内容
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return map[string]any{}, nil
+			}
+			return nil, err
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			break
+		}
+	}
+
+	root, err := parseXMLNode(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if result, ok := root.(map[string]any); ok {
+		return result, nil
+	}
+	return map[string]any{}, nil
+}
+
+// parseXMLNode 递归解析xml节点，叶子节点返回文本内容，否则返回子节点map
+func parseXMLNode(decoder *xml.Decoder) (any, error) {
+	var (
+		text     strings.Builder
+		children map[string]any
+	)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			child, err := parseXMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if children == nil {
+				children = make(map[string]any)
+			}
+			name := t.Name.Local
+			if existing, ok := children[name]; ok {
+				if list, isList := existing.([]any); isList {
+					children[name] = append(list, child)
+				} else {
+					children[name] = []any{existing, child}
+				}
+			} else {
+				children[name] = child
+			}
+		case xml.EndElement:
+			if children != nil {
+				return children, nil
+			}
+			return strings.TrimSpace(text.String()), nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import "testing"
+
+func TestGPCBindNullElements(t *testing.T) {
+	type request struct {
+		IDs   []int          `json:"ids"`
+		Names map[string]int `json:"names"`
+		Owner *int           `json:"owner"`
+		Tags  []*string      `json:"tags"`
+	}
+	gpc := GPC{"all": {
+		"ids":   []any{float64(1), nil, "3"},
+		"names": map[string]any{"a": float64(1), "b": nil},
+		"owner": nil,
+		"tags":  []any{nil, "x"},
+	}}
+	var req request
+	if err := gpc.Bind(&req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.IDs) != 3 || req.IDs[0] != 1 || req.IDs[1] != 0 || req.IDs[2] != 3 {
+		t.Errorf("IDs = %v, want [1 0 3]", req.IDs)
+	}
+	if req.Names["a"] != 1 || req.Names["b"] != 0 {
+		t.Errorf("Names = %v", req.Names)
+	}
+	if req.Owner != nil {
+		t.Errorf("Owner = %v, want nil", *req.Owner)
+	}
+	if len(req.Tags) != 2 || req.Tags[0] != nil || req.Tags[1] == nil || *req.Tags[1] != "x" {
+		t.Errorf("Tags = %v", req.Tags)
+	}
+}
+
+func TestGPCBindSourceAndDefault(t *testing.T) {
+	type request struct {
+		Page    int    `gpc:"page,query" default:"1"`
+		Size    int    `gpc:"size,query" default:"20"`
+		Token   string `gpc:"Authorization,header"`
+		Keyword string `json:"keyword"`
+		Ignored string `gpc:"-"`
+	}
+	gpc := GPC{
+		"query":  {"size": "50", "keyword": "from-query"},
+		"header": {"Authorization": "Bearer x"},
+		"all":    {"size": "50", "keyword": "go", "Authorization": "Bearer x", "Ignored": "x"},
+	}
+	var req request
+	if err := gpc.Bind(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Page != 1 || req.Size != 50 || req.Token != "Bearer x" || req.Keyword != "go" || req.Ignored != "" {
+		t.Errorf("unexpected binding: %+v", req)
+	}
+}
@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AbortReasonKey 请求被中间件中断的原因在gin上下文中的键名，供访问日志、调试器等组件读取
+const AbortReasonKey = "AbortReason"
+
+// 中断原因
+const (
+	AbortReasonBodyLimit = "body_limit" // 请求体超过大小限制
+	AbortReasonTimeout   = "timeout"    // 请求处理超时
+)
+
+// GetAbortReason 获取请求被中断的原因，未被中断时返回空字符串
+func GetAbortReason(c *gin.Context) string {
+	return c.GetString(AbortReasonKey)
+}
+
+// BodyLimit 限制请求体大小，超过 maxBytes 时中断请求并返回 413
+//
+// Content-Length 超过限制时直接拒绝；未声明长度（分块传输）或声明不实的请求在读取超过限制时返回错误，
+// 此时若处理函数尚未输出响应，同样返回 413。需放在 SetGPC 等读取请求体的中间件之前
+//
+// 示例:
+//
+//	r.Use(middleware.Base{}.BodyLimit(10 << 20)) // 10MB
+func (b Base) BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			abortWithReason(c, AbortReasonBodyLimit, errcode.New(http.StatusRequestEntityTooLarge, "请求体过大").
+				WithMeta("content_length", c.Request.ContentLength).WithMeta("limit", maxBytes))
+			return
+		}
+
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)}
+		c.Request.Body = body
+
+		c.Next()
+
+		if body.exceeded {
+			appErr := errcode.New(http.StatusRequestEntityTooLarge, "请求体过大").WithMeta("limit", maxBytes)
+			if c.Writer.Written() {
+				c.Set(AbortReasonKey, AbortReasonBodyLimit)
+				_ = c.Error(appErr).SetType(gin.ErrorTypePrivate)
+				return
+			}
+			abortWithReason(c, AbortReasonBodyLimit, appErr)
+		}
+	}
+}
+
+// Timeout 限制请求的处理时间，超过 d 时中断请求并返回 503
+//
+// 处理函数在独立的goroutine中执行，请求的 context 会在超时时被取消，
+// 数据库查询、HTTP调用等应使用 c.Request.Context() 以便尽快退出；
+// 超时后处理函数的输出会被丢弃，中间件会等待其返回后再结束请求，panic 会交由外层的 Recovery 处理
+//
+// 示例:
+//
+//	r.Use(middleware.Base{}.Recovery(), middleware.Base{}.Timeout(30*time.Second))
+func (b Base) Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		tw := &timeoutWriter{ResponseWriter: c.Writer, header: c.Writer.Header().Clone()}
+		c.Writer = tw
+
+		done := make(chan struct{})
+		var panicked any
+		go func() {
+			defer func() {
+				panicked = recover()
+				close(done)
+			}()
+			c.Next()
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			timedOut := tw.timeout()
+			<-done
+			if timedOut {
+				c.Writer = tw.ResponseWriter
+				c.Abort()
+				c.Set(AbortReasonKey, AbortReasonTimeout)
+				_ = c.Error(errcode.New(http.StatusServiceUnavailable, "请求处理超时").
+					WithMeta("timeout", d.String())).SetType(gin.ErrorTypePrivate)
+			}
+		}
+		if panicked != nil {
+			panic(panicked)
+		}
+	}
+}
+
+// abortWithReason 记录中断原因并输出统一的错误响应
+func abortWithReason(c *gin.Context, reason string, appErr *errcode.AppError) {
+	c.Set(AbortReasonKey, reason)
+	_ = c.Error(appErr).SetType(gin.ErrorTypePrivate)
+	c.AbortWithStatusJSON(appErr.HTTPStatus(), jcbaseGo.Result{
+		Code:    appErr.Code,
+		Message: appErr.Message,
+	})
+}
+
+// limitedBody 记录请求体是否超过了大小限制
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	n, err := l.ReadCloser.Read(p)
+	if _, ok := err.(*http.MaxBytesError); ok {
+		l.exceeded = true
+	}
+	return n, err
+}
+
+// timeoutWriter 超时后丢弃处理函数的输出；响应头先写入副本，输出时再复制，避免与超时响应并发修改
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.commitHeader()
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.commitHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.commitHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.commitHeader()
+		w.ResponseWriter.Flush()
+	}
+}
+
+// commitHeader 响应头尚未输出时复制到底层的 ResponseWriter
+func (w *timeoutWriter) commitHeader() {
+	if w.ResponseWriter.Written() {
+		return
+	}
+	dst := w.ResponseWriter.Header()
+	for k := range dst {
+		delete(dst, k)
+	}
+	for k, v := range w.header {
+		dst[k] = v
+	}
+}
+
+// timeout 标记为超时并输出超时响应，处理函数已输出响应时返回 false
+func (w *timeoutWriter) timeout() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ResponseWriter.Written() {
+		return false
+	}
+	w.timedOut = true
+
+	dst := w.ResponseWriter.Header()
+	dst.Set("Content-Type", "application/json; charset=utf-8")
+	appErr := errcode.New(http.StatusServiceUnavailable, "请求处理超时")
+	w.ResponseWriter.WriteHeader(appErr.HTTPStatus())
+	body, _ := json.Marshal(jcbaseGo.Result{Code: appErr.Code, Message: appErr.Message})
+	_, _ = w.ResponseWriter.Write(body)
+	return true
+}
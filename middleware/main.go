@@ -1,54 +1,16 @@
 package middleware
 
 import (
-	"fmt"
+	"bytes"
 	"github.com/gin-gonic/gin"
+	"io"
 	"log"
-	"net/http"
 	"strings"
 )
 
 type Base struct {
 }
 
-// Cors 开放所有接口的OPTIONS方法
-func (b Base) Cors() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		method := c.Request.Method               //请求方法
-		origin := c.Request.Header.Get("Origin") //请求头部
-		var headerKeys []string                  // 声明请求头keys
-		for k := range c.Request.Header {
-			headerKeys = append(headerKeys, k)
-		}
-		headerStr := strings.Join(headerKeys, ", ")
-		if headerStr != "" {
-			headerStr = fmt.Sprintf("Access-Control-Allow-Origin, Access-Control-Allow-Headers, %s", headerStr)
-		} else {
-			headerStr = "Access-Control-Allow-Origin, Access-Control-Allow-Headers"
-		}
-		if origin != "" {
-			origin := c.Request.Header.Get("Origin")
-			c.Header("Access-Control-Allow-Origin", origin)                                    // 这是允许访问所有域
-			c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE,UPDATE") //服务器支持的所有跨域请求的方法,为了避免浏览次请求的多次'预检'请求
-			// header的类型
-			c.Header("Access-Control-Allow-Headers", "Authorization, Content-Length, X-CSRF-Token, Token,session,X_Requested_With,Accept, Origin, Host, Connection, Accept-Encoding, Accept-Language,DNT, X-CustomHeader, Keep-Alive, User-Agent, X-Requested-With, If-Modified-Since, Cache-Control, Content-Type, Pragma, Code")
-			// 允许跨域设置 可以返回其他子段
-			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers,Cache-Control,Content-Language,Content-Type,Expires,Last-Modified,Pragma,FooBar") // 跨域关键设置 让浏览器可以解析
-			c.Header("Access-Control-Max-Age", "172800")                                                                                                                                                           // 缓存请求信息 单位为秒
-			c.Header("Access-Control-Allow-Credentials", "false")                                                                                                                                                  // 跨域请求是否需要带cookie信息 默认设置为true
-			c.Set("Content-type", "application/json;charset=utf-8")                                                                                                                                                // 设置返回格式是json
-		}
-
-		//放行所有OPTIONS方法
-		if method == "OPTIONS" {
-			c.JSON(http.StatusOK, "Options Request!")
-		}
-
-		// 处理请求
-		c.Next() // 处理请求
-	}
-}
-
 // RealIP 获取真实IP
 func (b Base) RealIP(useCDN bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -58,6 +20,14 @@ func (b Base) RealIP(useCDN bool) gin.HandlerFunc {
 	}
 }
 
+// GetRealIP 获取客户端真实IP，优先读取 RealIP 中间件保存的结果，未使用该中间件时返回 c.ClientIP()
+func GetRealIP(c *gin.Context) string {
+	if ip := c.GetString("ClientIP"); ip != "" {
+		return ip
+	}
+	return getRealIP(c, false)
+}
+
 // 如果开启了CDN之类的，获取真实IP需要从头部读取
 func getRealIP(c *gin.Context, useCDN bool) (realIP string) {
 	// 从上下文中获取客户端IP
@@ -147,46 +117,23 @@ func (b Base) SetGPC(e *gin.Engine) gin.HandlerFunc {
 		switch c.ContentType() {
 		case "application/json":
 			err = c.ShouldBindJSON(&formDataMap)
+		case "application/xml", "text/xml":
+			// 微信等第三方平台推送的xml消息，解析后还原请求体，便于后续验签、解密
+			var rawBody []byte
+			rawBody, err = io.ReadAll(c.Request.Body)
+			if err == nil {
+				c.Request.Body = io.NopCloser(bytes.NewReader(rawBody))
+				formDataMap, err = parseXMLToMap(bytes.NewReader(rawBody))
+			}
 		case "application/x-www-form-urlencoded":
 			err = c.Request.ParseForm()
 			if err == nil {
-				for key, values := range c.Request.PostForm {
-					if len(values) > 0 {
-						if strings.HasSuffix(key, "[]") {
-							cleanKey := strings.TrimSuffix(key, "[]")
-							formDataMap[cleanKey] = values
-						} else {
-							//if len(values) > 1 {
-							//	formDataMap[key] = values
-							//} else {
-							formDataMap[key] = values[0]
-							//}
-						}
-					}
-				}
+				formDataMap = parseFormValues(c.Request.PostForm, nil)
 			}
 		case "multipart/form-data":
 			err = c.Request.ParseMultipartForm(e.MaxMultipartMemory)
 			if err == nil {
-				for key, values := range c.Request.MultipartForm.Value {
-					if len(values) > 0 {
-						if strings.HasSuffix(key, "[]") {
-							cleanKey := strings.TrimSuffix(key, "[]")
-							formDataMap[cleanKey] = values
-						} else {
-							//if len(values) > 1 {
-							//	formDataMap[key] = values
-							//} else {
-							formDataMap[key] = values[0]
-							//}
-						}
-					}
-				}
-
-				// 处理文件上传字段
-				for key, _ := range c.Request.MultipartForm.File {
-					formDataMap[key], _ = c.FormFile(key)
-				}
+				formDataMap = parseFormValues(c.Request.MultipartForm.Value, c.Request.MultipartForm.File)
 			}
 		default:
 			if c.ContentType() != "" {
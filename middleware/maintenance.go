@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/helper"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Maintenance 维护模式开关，可在运行时通过 Update（配置热更新）、Enable/Disable 或管理接口切换
+type Maintenance struct {
+	mu   sync.RWMutex
+	conf jcbaseGo.MaintenanceStruct
+	ips  []net.IP
+	nets []*net.IPNet
+	page string
+}
+
+// NewMaintenance 创建维护模式开关
+//
+// 示例:
+//
+//	mt, err := middleware.NewMaintenance(conf.Maintenance)
+//	r.Use(middleware.Base{}.Maintenance(mt))
+//
+//	// 配置文件变更后
+//	err = mt.Update(newConf.Maintenance)
+func NewMaintenance(conf ...jcbaseGo.MaintenanceStruct) (*Maintenance, error) {
+	m := &Maintenance{}
+	var c jcbaseGo.MaintenanceStruct
+	if len(conf) > 0 {
+		c = conf[0]
+	}
+	if err := m.Update(c); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Update 使用新的配置替换当前配置，配置有误时保留原配置并返回错误
+func (m *Maintenance) Update(conf jcbaseGo.MaintenanceStruct) error {
+	_ = helper.CheckAndSetDefault(&conf)
+
+	var ips []net.IP
+	var nets []*net.IPNet
+	for _, allow := range conf.AllowIPs {
+		allow = strings.TrimSpace(allow)
+		if strings.Contains(allow, "/") {
+			_, ipNet, err := net.ParseCIDR(allow)
+			if err != nil {
+				return err
+			}
+			nets = append(nets, ipNet)
+			continue
+		}
+		ip := net.ParseIP(allow)
+		if ip == nil {
+			return errors.New("无效的IP地址：" + allow)
+		}
+		ips = append(ips, ip)
+	}
+
+	page := ""
+	if conf.Page != "" {
+		content, err := os.ReadFile(conf.Page)
+		if err != nil {
+			return err
+		}
+		page = string(content)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conf, m.ips, m.nets, m.page = conf, ips, nets, page
+	return nil
+}
+
+// Enable 开启维护模式，message 不为空时替换提示信息
+func (m *Maintenance) Enable(message ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conf.Enabled = true
+	if len(message) > 0 && message[0] != "" {
+		m.conf.Message = message[0]
+	}
+}
+
+// Disable 关闭维护模式
+func (m *Maintenance) Disable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conf.Enabled = false
+}
+
+// Enabled 是否处于维护模式
+func (m *Maintenance) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.conf.Enabled
+}
+
+// Config 当前配置
+func (m *Maintenance) Config() jcbaseGo.MaintenanceStruct {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.conf
+}
+
+// RegisterRoutes 注册维护模式管理接口，需自行在路由分组上添加登录、权限等中间件，
+// 并将该分组的路径加入 AllowPaths，否则开启后将无法通过接口关闭：
+//   - GET  /status  当前状态
+//   - POST /enable  开启，参数 message（可选）
+//   - POST /disable 关闭
+func (m *Maintenance) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/status", m.handleStatus)
+	r.POST("/enable", m.handleEnable)
+	r.POST("/disable", m.handleDisable)
+}
+
+// Maintenance 维护模式中间件，开启后除 AllowIPs、AllowPaths 外的请求返回 503；
+// 浏览器访问（Accept 包含 text/html）且配置了 Page 时返回HTML页面，否则返回JSON
+func (b Base) Maintenance(m *Maintenance) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m.mu.RLock()
+		enabled, allowed := m.conf.Enabled, m.allowed(c)
+		conf, page := m.conf, m.page
+		m.mu.RUnlock()
+		if !enabled || allowed {
+			c.Next()
+			return
+		}
+
+		if conf.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(conf.RetryAfter))
+		}
+		status := errcode.HTTPStatus(errcode.UnderMaintenance)
+		if page != "" && strings.Contains(c.GetHeader("Accept"), "text/html") {
+			c.Data(status, "text/html; charset=utf-8", []byte(strings.ReplaceAll(page, "{message}", conf.Message)))
+			c.Abort()
+			return
+		}
+		c.AbortWithStatusJSON(status, jcbaseGo.Result{
+			Code:    errcode.UnderMaintenance,
+			Message: conf.Message,
+		})
+	}
+}
+
+// allowed 请求是否在白名单中，调用方需持有读锁
+func (m *Maintenance) allowed(c *gin.Context) bool {
+	for _, prefix := range m.conf.AllowPaths {
+		if prefix != "" && strings.HasPrefix(c.Request.URL.Path, prefix) {
+			return true
+		}
+	}
+	if len(m.ips) == 0 && len(m.nets) == 0 {
+		return false
+	}
+	ip := net.ParseIP(GetRealIP(c))
+	if ip == nil {
+		return false
+	}
+	for _, allow := range m.ips {
+		if allow.Equal(ip) {
+			return true
+		}
+	}
+	for _, ipNet := range m.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Maintenance) handleStatus(c *gin.Context) {
+	conf := m.Config()
+	c.JSON(http.StatusOK, jcbaseGo.Result{
+		Code:    errcode.Success,
+		Message: "success",
+		Data: map[string]any{
+			"enabled":     conf.Enabled,
+			"message":     conf.Message,
+			"retry_after": conf.RetryAfter,
+		},
+	})
+}
+
+func (m *Maintenance) handleEnable(c *gin.Context) {
+	var form struct {
+		Message string `json:"message" form:"message"`
+	}
+	if err := c.ShouldBind(&form); err != nil {
+		c.JSON(http.StatusOK, jcbaseGo.Result{Code: errcode.ParamError, Message: err.Error()})
+		return
+	}
+	m.Enable(form.Message)
+	m.handleStatus(c)
+}
+
+func (m *Maintenance) handleDisable(c *gin.Context) {
+	m.Disable()
+	m.handleStatus(c)
+}
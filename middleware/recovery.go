@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jcbowen/jcbaseGo"
+	"github.com/jcbowen/jcbaseGo/component/mailer"
+	"github.com/jcbowen/jcbaseGo/errcode"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// PanicStackKey panic堆栈在gin上下文中的键名，供调试器等组件读取
+const PanicStackKey = "PanicStack"
+
+// PanicReport panic报告内容
+type PanicReport struct {
+	Time      string `json:"time"`       // 发生时间
+	RequestID string `json:"request_id"` // 请求ID
+	ClientIP  string `json:"client_ip"`  // 客户端IP
+	Method    string `json:"method"`     // 请求方法
+	URL       string `json:"url"`        // 请求地址
+	Error     string `json:"error"`      // panic内容
+	Stack     string `json:"stack"`      // 堆栈信息
+}
+
+// PanicReporter panic报告钩子，在独立的goroutine中执行，不会阻塞响应
+type PanicReporter func(report PanicReport)
+
+var (
+	panicReporters   []PanicReporter
+	panicReportersMu sync.RWMutex
+)
+
+// RegisterPanicReporter 注册全局panic报告钩子
+func RegisterPanicReporter(reporters ...PanicReporter) {
+	panicReportersMu.Lock()
+	defer panicReportersMu.Unlock()
+	panicReporters = append(panicReporters, reporters...)
+}
+
+// Recovery 捕获panic，记录堆栈并返回统一的错误响应，同时调用注册的报告钩子
+//
+// 参数:
+//   - reporters (可选): 仅对当前中间件生效的报告钩子，会在全局钩子之后执行
+//
+// 示例:
+//
+//	middleware.RegisterPanicReporter(middleware.MailerPanicReporter(mailer.New(conf.Mailer), "dev@example.com"))
+//	r.Use(middleware.Base{}.Recovery())
+func (b Base) Recovery(reporters ...PanicReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			stack := string(debug.Stack())
+			c.Set(PanicStackKey, stack)
+
+			clientIP := c.GetString("ClientIP")
+			if clientIP == "" {
+				clientIP = c.ClientIP()
+			}
+
+			report := PanicReport{
+				Time:      time.Now().Format("2006-01-02 15:04:05"),
+				RequestID: GetRequestID(c),
+				ClientIP:  clientIP,
+				Method:    c.Request.Method,
+				URL:       c.Request.URL.String(),
+				Error:     fmt.Sprint(recovered),
+				Stack:     stack,
+			}
+			log.Printf("[Recovery] panic recovered: %s\n%s", report.Error, stack)
+
+			panicReportersMu.RLock()
+			allReporters := append(append([]PanicReporter{}, panicReporters...), reporters...)
+			panicReportersMu.RUnlock()
+			for _, reporter := range allReporters {
+				go runPanicReporter(reporter, report)
+			}
+
+			// 客户端已断开连接时无法再输出响应
+			if isBrokenPipe(recovered) {
+				_ = c.Error(fmt.Errorf("%v", recovered))
+				c.Abort()
+				return
+			}
+
+			// panic(errcode.New(...)) 时使用其错误码、提示信息及对应的HTTP状态码
+			var appErr *errcode.AppError
+			if err, ok := recovered.(error); !ok || !errors.As(err, &appErr) {
+				appErr = errcode.New(http.StatusInternalServerError, "服务器内部错误").WithCause(fmt.Errorf("panic: %v", recovered))
+			}
+			_ = c.Error(appErr).SetType(gin.ErrorTypePrivate)
+			c.AbortWithStatusJSON(appErr.HTTPStatus(), jcbaseGo.Result{
+				Code:    appErr.Code,
+				Message: appErr.Message,
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+// MailerPanicReporter 通过邮件发送panic报告
+func MailerPanicReporter(email *mailer.Email, to ...string) PanicReporter {
+	return func(report PanicReport) {
+		// 每次发送复制一份，避免并发修改同一个实例
+		e := *email
+		e.To = append([]string{}, to...)
+		if len(e.To) == 0 {
+			e.To = email.To
+		}
+		e.SetSubject(fmt.Sprintf("[panic] %s %s", report.Method, report.URL))
+		body := fmt.Sprintf("时间：%s\n请求ID：%s\n客户端IP：%s\n请求：%s %s\n错误：%s\n\n%s",
+			report.Time, report.RequestID, report.ClientIP, report.Method, report.URL, report.Error, report.Stack)
+		e.SetBody(body, false)
+		if err := e.Send(); err != nil {
+			log.Println("发送panic报告邮件失败:", err)
+		}
+	}
+}
+
+// WebhookPanicReporter 以JSON格式将panic报告POST到指定地址
+func WebhookPanicReporter(url string) PanicReporter {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(report PanicReport) {
+		body, err := json.Marshal(report)
+		if err != nil {
+			log.Println("panic报告序列化失败:", err)
+			return
+		}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Println("发送panic报告失败:", err)
+			return
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			log.Println("发送panic报告失败，状态码:", resp.StatusCode)
+		}
+	}
+}
+
+// ReportPanic 异步调用全局注册的panic报告钩子，供gRPC等非gin的服务复用
+func ReportPanic(report PanicReport) {
+	panicReportersMu.RLock()
+	reporters := append([]PanicReporter{}, panicReporters...)
+	panicReportersMu.RUnlock()
+	for _, reporter := range reporters {
+		go runPanicReporter(reporter, report)
+	}
+}
+
+// runPanicReporter 执行报告钩子，钩子本身panic时不影响服务
+func runPanicReporter(reporter PanicReporter, report PanicReport) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("panic报告钩子执行异常:", r)
+		}
+	}()
+	reporter(report)
+}
+
+// isBrokenPipe 判断是否为客户端断开连接导致的错误
+func isBrokenPipe(recovered any) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if errors.As(opErr, &syscallErr) {
+		if errors.Is(syscallErr.Err, syscall.EPIPE) || errors.Is(syscallErr.Err, syscall.ECONNRESET) {
+			return true
+		}
+		errMsg := strings.ToLower(syscallErr.Error())
+		return strings.Contains(errMsg, "broken pipe") || strings.Contains(errMsg, "connection reset by peer")
+	}
+	return false
+}
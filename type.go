@@ -15,6 +15,7 @@ type Option struct {
 	ConfigSource string      `json:"config_source" default:"./config/main.json"` // 配置源（json文件/命令行）
 	ConfigData   interface{} `json:"config_data"`                                // 配置信息
 	RuntimePath  string      `json:"runtime_path" default:"/runtime/"`           // 运行缓存目录
+	Profile      string      `json:"profile" default:""`                         // 配置环境，如 dev、prod，为空时读取环境变量 JC_ENV
 }
 
 // SSLStruct ssl配置
@@ -23,20 +24,102 @@ type SSLStruct struct {
 	KeyPath  string `json:"key_path" default:""`
 }
 
+// ServerStruct http服务配置
+type ServerStruct struct {
+	Addr            string    `json:"addr" default:":8080"`                       // 监听地址
+	SSL             SSLStruct `json:"ssl"`                                        // 证书配置，配置后以https方式启动
+	AutoCertDomains []string  `json:"auto_cert_domains"`                          // 自动申请Let's Encrypt证书的域名，配置后忽略SSL证书配置
+	AutoCertEmail   string    `json:"auto_cert_email" default:""`                 // 申请证书时使用的联系邮箱
+	AutoCertDir     string    `json:"auto_cert_dir" default:"./runtime/autocert"` // 自动申请的证书缓存目录
+	HTTPRedirect    bool      `json:"http_redirect" default:"false"`              // 是否开启http跳转https
+	HTTPAddr        string    `json:"http_addr" default:":80"`                    // http跳转（及证书验证）监听地址，仅开启https时有效
+	DisableHTTP2    bool      `json:"disable_http2" default:"false"`              // 是否禁用HTTP/2
+	H2C             bool      `json:"h2c" default:"false"`                        // 未开启https时是否支持明文HTTP/2（h2c）
+	ReadTimeout     int       `json:"read_timeout" default:"60"`                  // 读取超时时间，单位秒
+	WriteTimeout    int       `json:"write_timeout" default:"60"`                 // 写入超时时间，单位秒
+	IdleTimeout     int       `json:"idle_timeout" default:"120"`                 // 空闲连接超时时间，单位秒
+}
+
+// CorsStruct 跨域配置
+type CorsStruct struct {
+	// 允许的来源，支持通配符，如 https://*.example.com，"*" 表示允许所有来源
+	// 注意：开启 AllowCredentials 时，"*" 不会匹配任何来源，必须显式配置
+	AllowOrigins       []string `json:"allow_origins"`
+	AllowMethods       []string `json:"allow_methods"`                        // 允许的请求方法，为空时使用默认列表
+	AllowHeaders       []string `json:"allow_headers"`                        // 允许的请求头，为空时使用默认列表
+	ExposeHeaders      []string `json:"expose_headers"`                       // 允许浏览器读取的响应头，为空时使用默认列表
+	MaxAge             int      `json:"max_age" default:"172800"`             // 预检请求缓存时间，单位为秒
+	AllowCredentials   bool     `json:"allow_credentials" default:"false"`    // 是否允许携带cookie等凭证信息
+	OptionsPassThrough bool     `json:"options_pass_through" default:"false"` // 预检请求是否继续交由后续处理器处理
+}
+
+// AccessLogStruct 访问日志配置
+type AccessLogStruct struct {
+	Output     string `json:"output" default:"stdout"`                      // 输出方式 stdout/file
+	FilePath   string `json:"file_path" default:"./runtime/log/access.log"` // 日志文件路径，仅output为file时有效
+	MaxSize    int    `json:"max_size" default:"100"`                       // 单个日志文件最大大小，单位MB，0表示不按大小切割
+	MaxBackups int    `json:"max_backups" default:"7"`                      // 最多保留的旧日志文件数量，0表示全部保留
+	Daily      bool   `json:"daily" default:"true"`                         // 是否按天切割
+}
+
+// CompressStruct 响应压缩配置
+type CompressStruct struct {
+	GzipLevel     int      `json:"gzip_level" default:"6"`   // gzip压缩级别1-9
+	BrotliLevel   int      `json:"brotli_level" default:"4"` // brotli压缩级别1-11
+	DisableBrotli bool     `json:"disable_brotli"`           // 是否禁用brotli，禁用后只使用gzip
+	MinSize       int      `json:"min_size" default:"1024"`  // 响应体小于该大小（字节）时不压缩
+	ContentTypes  []string `json:"content_types"`            // 压缩的Content-Type，支持 text/* 形式的通配，为空时使用默认列表
+	ExcludePaths  []string `json:"exclude_paths"`            // 不压缩的请求路径前缀
+}
+
+// ETagStruct ETag配置
+type ETagStruct struct {
+	Weak         bool     `json:"weak"`                       // 是否生成弱校验的ETag（W/"..."），内容语义相同但字节不同时仍可命中
+	ContentTypes []string `json:"content_types"`              // 生成ETag的Content-Type，为空时只处理 application/json
+	MaxSize      int      `json:"max_size" default:"1048576"` // 响应体超过该大小（字节）时不再缓冲，直接输出且不生成ETag
+}
+
+// MaintenanceStruct 维护模式配置
+type MaintenanceStruct struct {
+	Enabled    bool     `json:"enabled"`                       // 是否开启维护模式
+	Message    string   `json:"message" default:"系统维护中，请稍后再试"` // 提示信息
+	RetryAfter int      `json:"retry_after"`                   // Retry-After 响应头，单位秒，0表示不输出
+	Page       string   `json:"page"`                          // 浏览器访问时返回的HTML页面文件路径，页面中的 {message} 会被替换为提示信息，为空时返回JSON
+	AllowIPs   []string `json:"allow_ips"`                     // 维护期间允许访问的IP，支持CIDR
+	AllowPaths []string `json:"allow_paths"`                   // 维护期间允许访问的请求路径前缀，如健康检查、维护模式管理接口
+}
+
+// LoggerStruct 应用日志配置
+type LoggerStruct struct {
+	Level            string `json:"level" default:"info"`                      // 日志级别 debug/info/warn/error
+	Format           string `json:"format" default:"json"`                     // 输出格式 json/console
+	Output           string `json:"output" default:"stdout"`                   // 输出方式 stdout/file/both
+	FilePath         string `json:"file_path" default:"./runtime/log/app.log"` // 日志文件路径，output为file/both时有效
+	MaxSize          int    `json:"max_size" default:"100"`                    // 单个日志文件最大大小，单位MB，0表示不按大小切割
+	MaxBackups       int    `json:"max_backups" default:"7"`                   // 最多保留的旧日志文件数量，0表示全部保留
+	Daily            bool   `json:"daily" default:"true"`                      // 是否按天切割
+	AddSource        bool   `json:"add_source" default:"false"`                // 是否记录调用位置
+	SampleInitial    int    `json:"sample_initial" default:"0"`                // 采样：每秒内同一级别同一消息前N条全部记录，0表示不采样
+	SampleThereafter int    `json:"sample_thereafter" default:"0"`             // 采样：超过N条后每M条记录一条，0表示丢弃
+}
+
 // DbStruct 数据库配置
 type DbStruct struct {
-	DriverName    string `json:"driverName" default:"mysql"`   // 驱动类型
-	Protocol      string `json:"protocol" default:"tcp"`       // 协议
-	Host          string `json:"host" default:"localhost"`     // 数据库地址
-	Port          string `json:"port" default:"3306"`          // 数据库端口号
-	Dbname        string `json:"dbname" default:"dbname"`      // 表名称
-	Username      string `json:"username" default:"root"`      // 用户名
-	Password      string `json:"password" default:""`          // 密码
-	Charset       string `json:"charset" default:"utf8mb4"`    // 编码
-	TablePrefix   string `json:"tablePrefix" default:""`       // 表前缀
-	ParseTime     string `json:"parseTime" default:"False"`    // 是否开启时间解析
-	SingularTable bool   `json:"singularTable" default:"true"` // 使用单数表名
-	Alias         string `json:"alias" default:"db"`           // 配置信息别名
+	DriverName    string `json:"driverName" default:"mysql"`                                                 // 驱动类型
+	Protocol      string `json:"protocol" default:"tcp" validate:"omitempty,oneof=tcp unix"`                 // 协议
+	Host          string `json:"host" default:"localhost"`                                                   // 数据库地址
+	Port          string `json:"port" default:"3306" validate:"omitempty,numeric"`                           // 数据库端口号
+	Dbname        string `json:"dbname" default:"dbname"`                                                    // 表名称
+	Username      string `json:"username" default:"root"`                                                    // 用户名
+	Password      string `json:"password" default:""`                                                        // 密码
+	Charset       string `json:"charset" default:"utf8mb4"`                                                  // 编码
+	TablePrefix   string `json:"tablePrefix" default:""`                                                     // 表前缀
+	ParseTime     string `json:"parseTime" default:"False" validate:"omitempty,oneof=True False true false"` // 是否开启时间解析
+	SingularTable bool   `json:"singularTable" default:"true"`                                               // 使用单数表名
+	Alias         string `json:"alias" default:"db"`                                                         // 配置信息别名
+
+	PrepareStmt        bool `json:"prepareStmt" default:"false"`                        // 是否缓存预处理语句，重复执行的语句省去解析开销
+	PrepareStmtMaxSize int  `json:"prepareStmtMaxSize" default:"1000" validate:"min=0"` // 最多缓存的预处理语句数量，超出时清空重建
 }
 
 // SqlLiteStruct sqlite配置
@@ -57,15 +140,15 @@ type RedisStruct struct {
 
 // MailerStruct 发送邮箱配置
 type MailerStruct struct {
-	Host     string `json:"host" default:"smtp.qq.com"`        // 邮箱地址
-	Port     string `json:"port" default:"465"`                // 邮箱端口号
-	Username string `json:"username" default:"example@qq.com"` // 邮箱用户名
-	Password string `json:"password" default:"123456"`         // 邮箱密码
-	From     string `json:"from" default:"example@qq.com"`     // 发件邮箱
-	UseTLS   bool   `json:"useTls" default:"true"`             // 是否使用TLS
-	CertPath string `json:"cert_path" default:""`              // 证书文件路径
-	KeyPath  string `json:"key_path" default:""`               // 私钥文件路径
-	CAPath   string `json:"ca_path" default:""`                // CA证书文件路径
+	Host     string `json:"host" default:"smtp.qq.com"`                               // 邮箱地址
+	Port     string `json:"port" default:"465" validate:"omitempty,numeric"`          // 邮箱端口号
+	Username string `json:"username" default:"example@qq.com"`                        // 邮箱用户名
+	Password string `json:"password" default:"123456"`                                // 邮箱密码
+	From     string `json:"from" default:"example@qq.com" validate:"omitempty,email"` // 发件邮箱
+	UseTLS   bool   `json:"useTls" default:"true"`                                    // 是否使用TLS
+	CertPath string `json:"cert_path" default:""`                                     // 证书文件路径
+	KeyPath  string `json:"key_path" default:""`                                      // 私钥文件路径
+	CAPath   string `json:"ca_path" default:""`                                       // CA证书文件路径
 }
 
 // AttachmentStruct 附件配置
@@ -127,6 +210,114 @@ type SFTPStruct struct {
 	CustomizeVisitDomain string `json:"customize_visit_domain" default:""`
 }
 
+// WechatStruct 微信公众号/小程序配置
+type WechatStruct struct {
+	AppID          string `json:"app_id" default:""`           // AppID
+	AppSecret      string `json:"app_secret" default:""`       // AppSecret
+	Token          string `json:"token" default:""`            // 消息推送令牌
+	EncodingAESKey string `json:"encoding_aes_key" default:""` // 消息加解密密钥，明文模式下可不填
+}
+
+// WechatPayStruct 微信支付v3配置
+type WechatPayStruct struct {
+	AppID             string `json:"app_id" default:""`              // 公众号/小程序/APP的AppID
+	MchID             string `json:"mch_id" default:""`              // 商户号
+	APIv3Key          string `json:"api_v3_key" default:""`          // APIv3密钥，用于解密回调通知
+	SerialNo          string `json:"serial_no" default:""`           // 商户API证书序列号
+	PrivateKey        string `json:"private_key" default:""`         // 商户API私钥，PEM内容或文件路径
+	PlatformPublicKey string `json:"platform_public_key" default:""` // 微信支付平台证书/公钥，PEM内容或文件路径
+	NotifyURL         string `json:"notify_url" default:""`          // 支付结果通知地址
+	RefundNotifyURL   string `json:"refund_notify_url" default:""`   // 退款结果通知地址，为空时不通知
+}
+
+// AlipayStruct 支付宝配置
+type AlipayStruct struct {
+	AppID           string `json:"app_id" default:""`            // 应用ID
+	PrivateKey      string `json:"private_key" default:""`       // 应用私钥，PEM内容、base64内容或文件路径
+	AlipayPublicKey string `json:"alipay_public_key" default:""` // 支付宝公钥，PEM内容、base64内容或文件路径
+	NotifyURL       string `json:"notify_url" default:""`        // 异步通知地址
+	ReturnURL       string `json:"return_url" default:""`        // 网页支付完成后的跳转地址
+	Sandbox         bool   `json:"sandbox" default:"false"`      // 是否使用沙箱环境
+}
+
+// OAuthStruct 第三方登录配置
+type OAuthStruct struct {
+	ClientID     string   `json:"client_id" default:""`     // 应用ID，微信、QQ为AppID
+	ClientSecret string   `json:"client_secret" default:""` // 应用密钥，微信为AppSecret，QQ为AppKey
+	RedirectURL  string   `json:"redirect_url" default:""`  // 授权回调地址，需与开放平台中登记的一致
+	Scopes       []string `json:"scopes"`                   // 申请的权限，为空时使用各平台的默认值
+	Issuer       string   `json:"issuer" default:""`        // OIDC颁发者地址，用于自动发现端点，仅OIDC使用
+}
+
+// MessageStruct 提示页配置
+type MessageStruct struct {
+	Title         string `json:"title" default:"系统提示"`            // 页面标题
+	LogoURL       string `json:"logo_url" default:""`             // 品牌logo地址，为空时不显示
+	PrimaryColor  string `json:"primary_color" default:"#1677ff"` // 主色，用于按钮及链接
+	SuccessColor  string `json:"success_color" default:"#52c41a"` // 成功提示颜色
+	ErrorColor    string `json:"error_color" default:"#ff4d4f"`   // 错误提示颜色
+	Footer        string `json:"footer" default:""`               // 页脚文字，为空时不显示
+	RedirectDelay int    `json:"redirect_delay" default:"3"`      // 有跳转地址时自动跳转的等待秒数，小于0表示不自动跳转
+	TemplatePath  string `json:"template_path" default:""`        // 自定义模板文件路径，为空时使用内置模板
+}
+
+// PDFStruct PDF生成配置
+type PDFStruct struct {
+	FontPath     string `json:"font_path" default:""`              // 中文字体文件路径（TTF），为空时仅支持西文字符
+	BoldFontPath string `json:"bold_font_path" default:""`         // 粗体字体文件路径（TTF），为空时使用 FontPath
+	PageSize     string `json:"page_size" default:"A4"`            // 纸张大小，如 A4、A5、Letter
+	Orientation  string `json:"orientation" default:"P"`           // 纸张方向，P 纵向，L 横向
+	Wkhtmltopdf  string `json:"wkhtmltopdf" default:"wkhtmltopdf"` // wkhtmltopdf 可执行文件路径，用于HTML转PDF
+	Timeout      int    `json:"timeout" default:"60"`              // HTML转PDF的超时时间（秒）
+}
+
+// I18nStruct 多语言配置
+type I18nStruct struct {
+	DefaultLocale string `json:"default_locale" default:"zh-CN"` // 默认语言，无法协商或缺少翻译时使用
+	Dir           string `json:"dir" default:"./i18n"`           // 翻译文件目录，文件名为语言标识，如 zh-CN.json、en.toml
+	QueryKey      string `json:"query_key" default:"lang"`       // 通过GET/POST参数指定语言时的参数名
+	CookieKey     string `json:"cookie_key" default:"lang"`      // 通过cookie指定语言时的cookie名，为空时不读取cookie
+}
+
+// GeoIPStruct IP归属地查询配置
+type GeoIPStruct struct {
+	DBPath    string `json:"db_path" default:"./data/ip2region.xdb"` // 数据库文件路径，支持 ip2region 的 .xdb 及 GeoLite2 的 .mmdb
+	Driver    string `json:"driver" default:""`                      // 数据库类型，ip2region 或 geolite2，为空时根据文件扩展名判断
+	Language  string `json:"language" default:"zh-CN"`               // GeoLite2 地名语言，缺少该语言时使用英文
+	CacheSize int    `json:"cache_size" default:"10000"`             // 查询结果缓存条数，小于0表示不缓存
+}
+
+// WordFilterStruct 敏感词过滤配置
+type WordFilterStruct struct {
+	Path           string `json:"path" default:"./data/sensitive"` // 词库文件或目录，目录下每个文件的文件名（不含扩展名）作为分类，不存在时忽略
+	ReplaceChar    string `json:"replace_char" default:"*"`        // 替换敏感词使用的字符
+	ReloadInterval int    `json:"reload_interval" default:"0"`     // 自动重新加载词库的间隔（秒），0表示不自动加载
+}
+
+// SearchStruct 全文搜索（Elasticsearch）配置
+type SearchStruct struct {
+	Address     string `json:"address" default:"http://127.0.0.1:9200"` // 服务地址
+	Username    string `json:"username" default:""`                     // Basic认证用户名
+	Password    string `json:"password" default:""`                     // Basic认证密码
+	APIKey      string `json:"api_key" default:""`                      // API Key认证，设置后忽略用户名密码
+	IndexPrefix string `json:"index_prefix" default:""`                 // 索引名前缀，用于多个项目或环境共用一个集群
+	Timeout     int    `json:"timeout" default:"10"`                    // 请求超时时间（秒）
+	MaxRetries  int    `json:"max_retries" default:"2"`                 // 网络错误及5xx时的最大重试次数
+}
+
+// DBBackupStruct 数据库备份配置
+type DBBackupStruct struct {
+	Dir           string `json:"dir" default:"./runtime/backup"`     // 本地备份目录
+	Keep          int    `json:"keep" default:"7"`                   // 保留的备份数量（本地、远程分别计算），0表示不清理
+	Interval      int    `json:"interval" default:"86400"`           // 定时备份的间隔（秒），调用 Start 后生效
+	Compress      bool   `json:"compress" default:"true"`            // 是否gzip压缩
+	RemoteDir     string `json:"remote_dir" default:"backup"`        // 上传到远程存储的目录
+	KeepLocal     bool   `json:"keep_local" default:"true"`          // 上传远程存储后是否保留本地文件
+	MysqldumpPath string `json:"mysqldump_path" default:"mysqldump"` // mysqldump 命令路径
+	MysqlPath     string `json:"mysql_path" default:"mysql"`         // mysql 命令路径，恢复时使用
+	Timeout       int    `json:"timeout" default:"3600"`             // 单次备份、恢复命令的超时时间（秒）
+}
+
 // ProjectStruct 项目配置
 type ProjectStruct struct {
 	Name string `json:"name" default:"jcbaseGo"` // 项目名称